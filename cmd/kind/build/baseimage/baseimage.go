@@ -24,8 +24,9 @@ import (
 )
 
 type flagpole struct {
-	Source string
-	Image  string
+	Source           string
+	Image            string
+	ContainerRuntime string
 }
 
 // NewCommand returns a new cobra.Command for building the base image
@@ -51,6 +52,11 @@ func NewCommand() *cobra.Command {
 		base.DefaultImage,
 		"name:tag of the resulting image to be built",
 	)
+	cmd.Flags().StringVar(
+		&flags.ContainerRuntime, "container-runtime",
+		"containerd",
+		"container runtime to install in addition to containerd, one of [containerd, cri-o]",
+	)
 	return cmd
 }
 
@@ -59,6 +65,7 @@ func runE(flags *flagpole) error {
 	ctx := base.NewBuildContext(
 		base.WithImage(flags.Image),
 		base.WithSourceDir(flags.Source),
+		base.WithContainerRuntime(flags.ContainerRuntime),
 	)
 	if err := ctx.Build(); err != nil {
 		return errors.Wrap(err, "build failed")
@@ -22,6 +22,7 @@ import (
 
 	"sigs.k8s.io/kind/cmd/kind/build/baseimage"
 	"sigs.k8s.io/kind/cmd/kind/build/nodeimage"
+	"sigs.k8s.io/kind/cmd/kind/build/prune"
 )
 
 // NewCommand returns a new cobra.Command for building
@@ -30,11 +31,12 @@ func NewCommand() *cobra.Command {
 		Args: cobra.NoArgs,
 		// TODO(bentheelder): more detailed usage
 		Use:   "build",
-		Short: "Build one of [base-image, node-image]",
-		Long:  "Build the base node image (base-image) or the node image (node-image)",
+		Short: "Build one of [base-image, node-image], or prune build leftovers",
+		Long:  "Build the base node image (base-image) or the node image (node-image), or remove what interrupted builds left behind (prune)",
 	}
 	// add subcommands
 	cmd.AddCommand(baseimage.NewCommand())
 	cmd.AddCommand(nodeimage.NewCommand())
+	cmd.AddCommand(prune.NewCommand())
 	return cmd
 }
@@ -19,16 +19,26 @@ package nodeimage
 import (
 	"github.com/spf13/cobra"
 
+	"sigs.k8s.io/kind/pkg/apis/config/defaults"
 	"sigs.k8s.io/kind/pkg/build/node"
 	"sigs.k8s.io/kind/pkg/errors"
 )
 
 type flagpole struct {
-	Source    string
-	BuildType string
-	Image     string
-	BaseImage string
-	KubeRoot  string
+	Source           string
+	BuildType        string
+	Image            string
+	BaseImage        string
+	KubeRoot         string
+	ContainerRuntime string
+	Cache            bool
+	CacheDir         string
+	DebugTools       bool
+	SkipImages       []string
+	StripDebug       bool
+	ExcludeCNI       []string
+	Validate         bool
+	Overlay          string
 }
 
 // NewCommand returns a new cobra.Command for building the node image
@@ -63,16 +73,79 @@ func NewCommand() *cobra.Command {
 		node.DefaultBaseImage,
 		"name:tag of the base image to use for the build",
 	)
+	cmd.Flags().StringVar(
+		&flags.ContainerRuntime, "container-runtime",
+		node.DefaultContainerRuntime,
+		"container runtime to load images into, one of [containerd, cri-o], the base image must contain it",
+	)
+	cmd.Flags().BoolVar(
+		&flags.Cache, "cache",
+		false,
+		"use and populate the build cache, pulled image archives are cached in --cache-dir and build snapshots as "+node.SnapshotRepository+" images",
+	)
+	cmd.Flags().StringVar(
+		&flags.CacheDir, "cache-dir",
+		node.DefaultCacheDir(),
+		"directory to cache pulled image archives in, see --cache",
+	)
+	cmd.Flags().BoolVar(
+		&flags.DebugTools, "debug-tools",
+		false,
+		"install debugging tools (tcpdump, strace, ethtool, conntrack, nsenter ...) into the image, "+
+			"the default image name is then suffixed with "+defaults.DebugImageSuffix,
+	)
+	cmd.Flags().StringSliceVar(
+		&flags.SkipImages, "skip-image",
+		nil,
+		"image or image repository to not preload, nodes will pull it on demand instead, may be repeated",
+	)
+	cmd.Flags().BoolVar(
+		&flags.StripDebug, "strip-debug",
+		false,
+		"strip debug symbols from the kubernetes binaries",
+	)
+	cmd.Flags().StringSliceVar(
+		&flags.ExcludeCNI, "exclude-cni-plugin",
+		nil,
+		"CNI plugin binary to remove from the image, may be repeated",
+	)
+	cmd.Flags().BoolVar(
+		&flags.Validate, "validate",
+		false,
+		"boot the built image once to verify it works, recommended with the image slimming flags",
+	)
+	cmd.Flags().StringVar(
+		&flags.Overlay, "overlay",
+		"",
+		"path to a Dockerfile to apply on top of the built image, it must build FROM ${"+node.OverlayBaseImageArg+"}",
+	)
 	return cmd
 }
 
 func runE(flags *flagpole) error {
+	cacheDir := ""
+	if flags.Cache {
+		cacheDir = flags.CacheDir
+	}
+	// the debug variant is named so that clusters can prefer it
+	image := flags.Image
+	if flags.DebugTools && image == node.DefaultImage {
+		image = defaults.DebugImage(image)
+	}
 	// TODO(bentheelder): make this more configurable
 	ctx, err := node.NewBuildContext(
 		node.WithMode(flags.BuildType),
-		node.WithImage(flags.Image),
+		node.WithImage(image),
 		node.WithBaseImage(flags.BaseImage),
 		node.WithKuberoot(flags.KubeRoot),
+		node.WithContainerRuntime(flags.ContainerRuntime),
+		node.WithCacheDir(cacheDir),
+		node.WithDebugTools(flags.DebugTools),
+		node.WithSkipImages(flags.SkipImages...),
+		node.WithStripDebugSymbols(flags.StripDebug),
+		node.WithExcludeCNIPlugins(flags.ExcludeCNI...),
+		node.WithValidate(flags.Validate),
+		node.WithOverlay(flags.Overlay),
 	)
 	if err != nil {
 		return errors.Wrap(err, "error creating build context")
@@ -0,0 +1,64 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package prune implements the `build prune` command
+package prune
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kind/pkg/build/node"
+)
+
+type flagpole struct {
+	OlderThan time.Duration
+}
+
+// NewCommand returns a new cobra.Command for removing build leftovers
+func NewCommand() *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "prune",
+		Short: "remove containers and untagged images left behind by node image builds",
+		Long: "removes the build containers and untagged build images left behind by interrupted or repeated node image builds\n\n" +
+			"containers of builds still running are kept",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runE(flags)
+		},
+	}
+	cmd.Flags().DurationVar(
+		&flags.OlderThan, "older-than", 0,
+		"only remove build containers and images created longer ago than this, EG 24h",
+	)
+	return cmd
+}
+
+func runE(flags *flagpole) error {
+	result, err := node.Prune(flags.OlderThan)
+	if result != nil {
+		for _, c := range result.Containers {
+			fmt.Printf("Removed build container %s\n", c)
+		}
+		for _, i := range result.Images {
+			fmt.Printf("Removed build image %s\n", i)
+		}
+	}
+	return err
+}
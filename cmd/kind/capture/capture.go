@@ -0,0 +1,138 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package capture implements the `capture` command
+package capture
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+type flagpole struct {
+	Name       string
+	Node       string
+	Filter     string
+	Interface  string
+	Output     string
+	Duration   time.Duration
+	MaxSize    int64
+	MaxPackets int
+}
+
+// NewCommand returns a new cobra.Command for capturing node network traffic
+func NewCommand() *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "capture",
+		Short: "captures network traffic on a node with tcpdump",
+		Long: "captures network traffic on a node with tcpdump for a bounded duration and size, " +
+			"writing it to a pcap file on the host\n\n" +
+			"the node must use a debug node image, see: kind build node-image --debug-tools",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runE(flags)
+		},
+	}
+	cmd.Flags().StringVar(
+		&flags.Name,
+		"name",
+		cluster.DefaultName,
+		"the cluster context name",
+	)
+	cmd.Flags().StringVar(
+		&flags.Node,
+		"node",
+		"",
+		"the node to capture traffic on",
+	)
+	cmd.Flags().StringVar(
+		&flags.Filter,
+		"filter",
+		"",
+		"tcpdump filter expression, EG \"port 6443\"",
+	)
+	cmd.Flags().StringVar(
+		&flags.Interface,
+		"interface",
+		"any",
+		"the node network interface to capture on",
+	)
+	cmd.Flags().StringVarP(
+		&flags.Output,
+		"output",
+		"o",
+		"",
+		"the pcap file to write, - for stdout",
+	)
+	cmd.Flags().DurationVar(
+		&flags.Duration,
+		"duration",
+		cluster.DefaultCaptureDuration,
+		"how long to capture for",
+	)
+	cmd.Flags().Int64Var(
+		&flags.MaxSize,
+		"max-size",
+		cluster.DefaultCaptureMaxBytes>>20,
+		"maximum capture size in MiB, 0 for no limit",
+	)
+	cmd.Flags().IntVar(
+		&flags.MaxPackets,
+		"max-packets",
+		0,
+		"stop after capturing this many packets, 0 for no limit",
+	)
+	return cmd
+}
+
+func runE(flags *flagpole) error {
+	if flags.Node == "" {
+		return errors.New("--node is required")
+	}
+	if flags.Output == "" {
+		return errors.New("--output is required")
+	}
+	var w io.Writer = os.Stdout
+	if flags.Output != "-" {
+		f, err := os.Create(flags.Output)
+		if err != nil {
+			return errors.Wrap(err, "failed to create capture file")
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := cluster.NewProvider().Capture(flags.Name, flags.Node, w,
+		cluster.CaptureFilter(flags.Filter),
+		cluster.CaptureInterface(flags.Interface),
+		cluster.CaptureDuration(flags.Duration),
+		cluster.CaptureMaxBytes(flags.MaxSize<<20),
+		cluster.CaptureMaxPackets(flags.MaxPackets),
+	); err != nil {
+		return err
+	}
+	if flags.Output != "-" {
+		fmt.Fprintf(os.Stderr, "Wrote capture to %s\n", flags.Output)
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package checkpoint implements the `checkpoint` command
+package checkpoint
+
+import (
+	"github.com/spf13/cobra"
+
+	checkpointnode "sigs.k8s.io/kind/cmd/kind/checkpoint/node"
+)
+
+// NewCommand returns a new cobra.Command for checkpointing
+func NewCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "checkpoint",
+		Short: "Checkpoints one of [node] (experimental)",
+		Long:  "Checkpoints one of [node] (experimental)",
+	}
+	cmd.AddCommand(checkpointnode.NewCommand())
+	return cmd
+}
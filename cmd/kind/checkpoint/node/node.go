@@ -0,0 +1,67 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package node implements the `node` command
+package node
+
+import (
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+)
+
+type flagpole struct {
+	Name       string
+	Checkpoint string
+}
+
+// NewCommand returns a new cobra.Command for checkpointing nodes
+func NewCommand() *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.ArbitraryArgs,
+		Use:   "node [NODE...]",
+		Short: "saves the process state of nodes and stops them (experimental)",
+		Long: "saves the process state of the named nodes, or all of the cluster's nodes, " +
+			"using the container runtime's CRIU support and stops them\n\n" +
+			"nodes can be resumed with: kind restore node\n\n" +
+			"this requires CRIU and docker experimental features to be enabled",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runE(flags, args)
+		},
+	}
+	cmd.Flags().StringVar(
+		&flags.Name,
+		"name",
+		cluster.DefaultName,
+		"the cluster context name",
+	)
+	cmd.Flags().StringVar(
+		&flags.Checkpoint,
+		"checkpoint",
+		cluster.DefaultCheckpointName,
+		"the name of the checkpoint, replacing any previous checkpoint of the same name",
+	)
+	return cmd
+}
+
+func runE(flags *flagpole, nodeNames []string) error {
+	return cluster.NewProvider().Checkpoint(
+		flags.Name,
+		cluster.CheckpointName(flags.Checkpoint),
+		cluster.CheckpointNodes(nodeNames...),
+	)
+}
@@ -19,22 +19,38 @@ package cluster
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 
+	"sigs.k8s.io/kind/cmd/kind/internal/metrics"
 	"sigs.k8s.io/kind/pkg/cluster"
 	"sigs.k8s.io/kind/pkg/cluster/create"
 	"sigs.k8s.io/kind/pkg/errors"
 	"sigs.k8s.io/kind/pkg/globals"
+	"sigs.k8s.io/kind/pkg/log"
 )
 
 type flagpole struct {
-	Name      string
-	Config    string
-	ImageName string
-	Retain    bool
-	Wait      time.Duration
+	Name         string
+	Config       string
+	Values       []string
+	ImageName    string
+	Retain       bool
+	Wait         time.Duration
+	WaitMinNodes int
+	PhaseTimeout map[string]string
+	ScanImages   bool
+	Scanner      string
+	ScanSeverity string
+	ScanWarnOnly bool
+	LoadModules  bool
+	FixFirewall  bool
+	FixLimits    bool
+	Protect      bool
+	StreamNodes  bool
+	Metrics      metrics.Flags
 }
 
 // NewCommand returns a new cobra.Command for cluster creation
@@ -51,13 +67,31 @@ func NewCommand() *cobra.Command {
 	}
 	cmd.Flags().StringVar(&flags.Name, "name", cluster.DefaultName, "cluster context name")
 	cmd.Flags().StringVar(&flags.Config, "config", "", "path to a kind config file")
+	cmd.Flags().StringSliceVar(
+		&flags.Values, "values", nil,
+		"render --config as a Go template with the values in these yaml files, later files override earlier ones",
+	)
 	cmd.Flags().StringVar(&flags.ImageName, "image", "", "node docker image to use for booting the cluster")
 	cmd.Flags().BoolVar(&flags.Retain, "retain", false, "retain nodes for debugging when cluster creation fails")
 	cmd.Flags().DurationVar(&flags.Wait, "wait", time.Duration(0), "Wait for control plane node to be ready (default 0s)")
+	cmd.Flags().IntVar(&flags.WaitMinNodes, "wait-min-nodes", 0, "with --wait, also wait for at least this many nodes of any role to be ready, tolerating other NotReady nodes")
+	cmd.Flags().StringToStringVar(&flags.PhaseTimeout, "phase-timeout", nil, "bound waiting within create phases, EG waitforready=2m")
+	cmd.Flags().BoolVar(&flags.ScanImages, "scan-images", false, "scan node images for vulnerabilities before creating nodes")
+	cmd.Flags().StringVar(&flags.Scanner, "scanner", "trivy", "image scanner executable to use with --scan-images")
+	cmd.Flags().StringVar(&flags.ScanSeverity, "scan-severity", "HIGH", "minimum vulnerability severity failing --scan-images, one of [UNKNOWN, LOW, MEDIUM, HIGH, CRITICAL]")
+	cmd.Flags().BoolVar(&flags.ScanWarnOnly, "scan-warn-only", false, "only warn about vulnerabilities found by --scan-images")
+	cmd.Flags().BoolVar(&flags.Protect, "protect", false, "protect the cluster from deletion unless kind delete cluster --force is used")
+	cmd.Flags().BoolVar(&flags.LoadModules, "load-kernel-modules", false, "load kernel modules required by the config that are missing on the host with modprobe (typically requires root)")
+	cmd.Flags().BoolVar(&flags.FixFirewall, "fix-firewall", false, "add host firewall rules allowing traffic on the cluster network, removed on delete (typically requires root)")
+	cmd.Flags().BoolVar(&flags.FixLimits, "fix-host-limits", false, "raise host inotify and open file limits that are too low for the nodes, restored when the last cluster is deleted (typically requires root)")
+	cmd.Flags().BoolVar(&flags.StreamNodes, "stream-node-logs", false, "stream the node container output, EG systemd, while creating the nodes")
+	metrics.AddFlags(cmd, &flags.Metrics)
 	return cmd
 }
 
-func runE(flags *flagpole) error {
+func runE(flags *flagpole) (err error) {
+	recorder := metrics.NewRecorder("create cluster", flags.Name)
+	defer func() { recorder.Export(&flags.Metrics, err) }()
 	provider := cluster.NewProvider()
 
 	// Check if the cluster name already exists
@@ -69,15 +103,44 @@ func runE(flags *flagpole) error {
 		return fmt.Errorf("a cluster with the name %q already exists", flags.Name)
 	}
 
-	// create the cluster
-	fmt.Printf("Creating cluster %q ...\n", flags.Name)
-	if err = provider.Create(
-		flags.Name,
-		create.WithConfigFile(flags.Config),
+	configOption := create.WithConfigFile(flags.Config)
+	if len(flags.Values) > 0 {
+		if flags.Config == "" {
+			return errors.New("--values requires --config")
+		}
+		configOption = create.WithConfigTemplate(flags.Config, flags.Values...)
+	}
+	options := []create.ClusterOption{
+		configOption,
 		create.WithNodeImage(flags.ImageName),
 		create.Retain(flags.Retain),
 		create.WaitForReady(flags.Wait),
-	); err != nil {
+		create.WaitForReadyNodes(flags.WaitMinNodes),
+		create.WithLoadKernelModules(flags.LoadModules),
+		create.WithFixFirewall(flags.FixFirewall),
+		create.WithFixHostLimits(flags.FixLimits),
+		create.Protect(flags.Protect),
+		create.WithPhaseObserver(func(phase string, elapsed time.Duration, _ bool) {
+			recorder.ObservePhase(phase, elapsed)
+		}),
+	}
+	for phase, timeout := range flags.PhaseTimeout {
+		d, err := time.ParseDuration(timeout)
+		if err != nil {
+			return errors.Wrapf(err, "invalid --phase-timeout for %s", phase)
+		}
+		options = append(options, create.WithPhaseTimeout(phase, d))
+	}
+	if flags.ScanImages {
+		options = append(options, create.WithImageScan(flags.Scanner, flags.ScanSeverity, flags.ScanWarnOnly))
+	}
+	if flags.StreamNodes {
+		options = append(options, create.WithNodeConsole(&logWriter{logger: globals.GetLogger()}))
+	}
+
+	// create the cluster
+	fmt.Printf("Creating cluster %q ...\n", flags.Name)
+	if err = provider.Create(flags.Name, options...); err != nil {
 		if errs := errors.Errors(err); errs != nil {
 			for _, problem := range errs {
 				globals.GetLogger().Errorf("%v", problem)
@@ -89,3 +152,14 @@ func runE(flags *flagpole) error {
 
 	return nil
 }
+
+// logWriter writes the node console lines to logger, interleaved with the
+// create progress
+type logWriter struct {
+	logger log.Logger
+}
+
+func (l *logWriter) Write(p []byte) (int, error) {
+	l.logger.V(0).Info(strings.TrimSuffix(string(p), "\n"))
+	return len(p), nil
+}
@@ -18,17 +18,27 @@ limitations under the License.
 package cluster
 
 import (
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"text/tabwriter"
 
 	"github.com/spf13/cobra"
 	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/globals"
 
+	"sigs.k8s.io/kind/cmd/kind/internal/metrics"
 	"sigs.k8s.io/kind/pkg/cluster"
 )
 
 type flagpole struct {
-	Name   string
-	Retain bool
+	Name      string
+	Retain    bool
+	Force     bool
+	Usage     bool
+	UsageFile string
+	Metrics   metrics.Flags
 }
 
 // NewCommand returns a new cobra.Command for cluster creation
@@ -45,14 +55,85 @@ func NewCommand() *cobra.Command {
 		},
 	}
 	cmd.Flags().StringVar(&flags.Name, "name", cluster.DefaultName, "the cluster name")
+	cmd.Flags().BoolVar(&flags.Force, "force", false, "delete the cluster even if it was created with --protect")
+	cmd.Flags().BoolVar(&flags.Usage, "usage", false, "print a summary of the resources used by the cluster before deleting it")
+	cmd.Flags().StringVar(&flags.UsageFile, "usage-file", "", "write a JSON summary of the resources used by the cluster to this file before deleting it")
+	metrics.AddFlags(cmd, &flags.Metrics)
 	return cmd
 }
 
-func runE(flags *flagpole) error {
+func runE(flags *flagpole) (err error) {
+	recorder := metrics.NewRecorder("delete cluster", flags.Name)
+	defer func() { recorder.Export(&flags.Metrics, err) }()
+	provider := cluster.NewProvider()
+	// collect usage first, failing to do so should not block deletion
+	var usage *cluster.Usage
+	if flags.Usage || flags.UsageFile != "" {
+		if err := recorder.Phase("collectusage", func() error {
+			var err error
+			usage, err = provider.Usage(flags.Name)
+			return err
+		}); err != nil {
+			globals.GetLogger().Warnf("WARNING: failed to collect cluster usage: %v", err)
+		}
+	}
 	// Delete the cluster
 	fmt.Printf("Deleting cluster %q ...\n", flags.Name)
-	if err := cluster.NewProvider().Delete(flags.Name); err != nil {
+	if err := recorder.Phase("deletenodes", func() error {
+		return provider.Delete(flags.Name, cluster.ForceDelete(flags.Force))
+	}); err != nil {
 		return errors.Wrap(err, "failed to delete cluster")
 	}
+	if usage == nil {
+		return nil
+	}
+	if flags.UsageFile != "" {
+		raw, err := json.MarshalIndent(usage, "", "  ")
+		if err != nil {
+			return errors.Wrap(err, "failed to encode cluster usage")
+		}
+		if err := ioutil.WriteFile(flags.UsageFile, append(raw, '\n'), 0644); err != nil {
+			return errors.Wrap(err, "failed to write cluster usage")
+		}
+	}
+	if flags.Usage {
+		return printUsage(usage)
+	}
 	return nil
 }
+
+func printUsage(usage *cluster.Usage) error {
+	fmt.Println()
+	fmt.Printf("Lifetime: %s\n", usage.Lifetime)
+	fmt.Printf("Peak Memory: %s\n", formatBytes(usage.PeakMemoryBytes))
+	fmt.Printf("CPU Time: %.1fs\n", usage.CPUSeconds)
+	fmt.Printf("Disk: %s\n", formatBytes(usage.DiskBytes))
+	fmt.Printf("Images: %d\n", len(usage.Images))
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "NAME\tROLE\tPEAK MEMORY\tCPU TIME\tDISK\tIMAGES")
+	for _, n := range usage.Nodes {
+		if n.Error != "" {
+			fmt.Fprintf(w, "%s\t%s\terror: %s\n", n.Name, n.Role, n.Error)
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%.1fs\t%s\t%d\n",
+			n.Name, n.Role, formatBytes(n.PeakMemoryBytes), n.CPUSeconds, formatBytes(n.DiskBytes), len(n.Images),
+		)
+	}
+	return w.Flush()
+}
+
+// formatBytes formats b in the largest binary unit it is at least one of
+func formatBytes(b uint64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%dB", b)
+	}
+	div, exp := uint64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(b)/float64(div), "KMGTPE"[exp])
+}
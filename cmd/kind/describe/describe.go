@@ -0,0 +1,95 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package describe implements the `describe` command
+package describe
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+type flagpole struct {
+	Name   string
+	Output string
+}
+
+// NewCommand returns a new cobra.Command for describing a cluster
+func NewCommand() *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "describe",
+		Short: "prints a machine readable description of a cluster",
+		Long: "prints the cluster endpoints, kubeconfig path, registry URL, published ports and nodes.\n\n" +
+			"the same description is written to descriptor.json in the cluster directory (see kind path) " +
+			"for integrations such as devcontainer features and IDE plugins, " +
+			"when the cluster is created and by kind kubeconfig refresh",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runE(flags)
+		},
+	}
+	cmd.Flags().StringVar(
+		&flags.Name,
+		"name",
+		cluster.DefaultName,
+		"the cluster context name",
+	)
+	cmd.Flags().StringVarP(
+		&flags.Output,
+		"output",
+		"o",
+		"",
+		"output format, one of: json",
+	)
+	return cmd
+}
+
+func runE(flags *flagpole) error {
+	if flags.Output != "" && flags.Output != "json" {
+		return errors.Errorf("unknown output format %q, must be one of: json", flags.Output)
+	}
+	descriptor, err := cluster.NewProvider().Describe(flags.Name)
+	if err != nil {
+		return err
+	}
+	if flags.Output == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(descriptor)
+	}
+
+	fmt.Printf("Name: %s\n", descriptor.Name)
+	fmt.Printf("Kubeconfig: %s\n", descriptor.KubeConfigPath)
+	fmt.Printf("Directory: %s\n", descriptor.Dir)
+	fmt.Printf("API Server: %s\n", descriptor.APIServer)
+	if descriptor.Registry != "" {
+		fmt.Printf("Registry: %s\n", descriptor.Registry)
+	}
+	nodeNames := []string{}
+	for _, n := range descriptor.Nodes {
+		nodeNames = append(nodeNames, n.Name)
+	}
+	fmt.Printf("Nodes: %s\n", strings.Join(nodeNames, ", "))
+	return nil
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package diff implements the `diff` command
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/cluster/create"
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+type flagpole struct {
+	Name      string
+	Config    string
+	Values    []string
+	ImageName string
+	Output    string
+}
+
+// NewCommand returns a new cobra.Command for reporting cluster drift
+func NewCommand() *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "diff",
+		Short: "reports how a cluster drifted from its config",
+		Long: "compares the nodes, node images and port mappings of each node role of the cluster " +
+			"to those in the config, exiting non-zero if they differ.\n\n" +
+			"the mutations kind made to the cluster are recorded in changelog.jsonl in the cluster " +
+			"directory (see kind path) and the kind-changelog ConfigMap in kube-system",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runE(flags)
+		},
+	}
+	cmd.Flags().StringVar(
+		&flags.Name,
+		"name",
+		cluster.DefaultName,
+		"the cluster context name",
+	)
+	cmd.Flags().StringVarP(
+		&flags.Config,
+		"config",
+		"f",
+		"",
+		"path to the kind config file the cluster was created with",
+	)
+	cmd.Flags().StringSliceVar(
+		&flags.Values,
+		"values",
+		nil,
+		"render --config as a Go template with the values in these yaml files, as create cluster does",
+	)
+	cmd.Flags().StringVar(
+		&flags.ImageName,
+		"image",
+		"",
+		"node docker image the cluster was created with, as create cluster --image",
+	)
+	cmd.Flags().StringVarP(
+		&flags.Output,
+		"output",
+		"o",
+		"",
+		"output format, one of: json",
+	)
+	return cmd
+}
+
+func runE(flags *flagpole) error {
+	if flags.Output != "" && flags.Output != "json" {
+		return errors.Errorf("unknown output format %q, must be one of: json", flags.Output)
+	}
+	configOption := create.WithConfigFile(flags.Config)
+	if len(flags.Values) > 0 {
+		if flags.Config == "" {
+			return errors.New("--values requires --config")
+		}
+		configOption = create.WithConfigTemplate(flags.Config, flags.Values...)
+	}
+	diffs, err := cluster.NewProvider().Diff(flags.Name, configOption, create.WithNodeImage(flags.ImageName))
+	if err != nil {
+		return err
+	}
+	if flags.Output == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(diffs); err != nil {
+			return err
+		}
+	} else {
+		for _, d := range diffs {
+			fmt.Println(d.String())
+		}
+	}
+	if len(diffs) > 0 {
+		return errors.Errorf("cluster %q drifted from its config", flags.Name)
+	}
+	return nil
+}
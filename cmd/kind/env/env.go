@@ -0,0 +1,111 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package env implements the `env` command
+package env
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+type flagpole struct {
+	Name  string
+	Shell string
+}
+
+// shells are the supported --shell values
+var shells = []string{"bash", "fish", "powershell"}
+
+// NewCommand returns a new cobra.Command for printing the cluster environment
+func NewCommand() *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "env",
+		Short: "prints shell exports pointing a terminal at a cluster",
+		Long: "prints shell exports of KUBECONFIG, KIND_CLUSTER_NAME, KIND_API_SERVER and, " +
+			"if the cluster has one, KIND_REGISTRY\n\n" +
+			"for bash and other POSIX shells: eval \"$(kind env)\"\n" +
+			"for fish: kind env --shell fish | source\n" +
+			"for PowerShell: kind env --shell powershell | Invoke-Expression",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runE(flags)
+		},
+	}
+	cmd.Flags().StringVar(
+		&flags.Name,
+		"name",
+		cluster.DefaultName,
+		"the cluster context name",
+	)
+	cmd.Flags().StringVar(
+		&flags.Shell,
+		"shell",
+		"bash",
+		"shell syntax of the exports, one of: "+strings.Join(shells, ", "),
+	)
+	return cmd
+}
+
+func runE(flags *flagpole) error {
+	known := false
+	for _, shell := range shells {
+		known = known || flags.Shell == shell
+	}
+	if !known {
+		return errors.Errorf("unknown shell %q, must be one of: %s", flags.Shell, strings.Join(shells, ", "))
+	}
+	descriptor, err := cluster.NewProvider().Describe(flags.Name)
+	if err != nil {
+		return err
+	}
+	vars := [][2]string{
+		{"KUBECONFIG", descriptor.KubeConfigPath},
+		{"KIND_CLUSTER_NAME", descriptor.Name},
+		{"KIND_API_SERVER", "https://" + descriptor.APIServer},
+	}
+	if descriptor.Registry != "" {
+		vars = append(vars, [2]string{"KIND_REGISTRY", descriptor.Registry})
+	}
+	return writeExports(os.Stdout, flags.Shell, vars)
+}
+
+// writeExports writes vars to w as exports in the syntax of shell
+func writeExports(w io.Writer, shell string, vars [][2]string) error {
+	for _, v := range vars {
+		var line string
+		switch shell {
+		case "fish":
+			line = fmt.Sprintf("set -gx %s '%s';", v[0], strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(v[1]))
+		case "powershell":
+			line = fmt.Sprintf("$Env:%s = '%s'", v[0], strings.Replace(v[1], `'`, `''`, -1))
+		default:
+			line = fmt.Sprintf("export %s='%s'", v[0], strings.Replace(v[1], `'`, `'\''`, -1))
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
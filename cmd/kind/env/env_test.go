@@ -0,0 +1,62 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package env
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteExports(t *testing.T) {
+	t.Parallel()
+	vars := [][2]string{
+		{"KUBECONFIG", "/home/o'brien/.kube/config"},
+		{"KIND_CLUSTER_NAME", "kind"},
+	}
+	cases := []struct {
+		Shell    string
+		Expected string
+	}{
+		{
+			Shell: "bash",
+			Expected: `export KUBECONFIG='/home/o'\''brien/.kube/config'
+export KIND_CLUSTER_NAME='kind'
+`,
+		},
+		{
+			Shell: "fish",
+			Expected: `set -gx KUBECONFIG '/home/o\'brien/.kube/config';
+set -gx KIND_CLUSTER_NAME 'kind';
+`,
+		},
+		{
+			Shell: "powershell",
+			Expected: `$Env:KUBECONFIG = '/home/o''brien/.kube/config'
+$Env:KIND_CLUSTER_NAME = 'kind'
+`,
+		},
+	}
+	for _, tc := range cases {
+		var buff bytes.Buffer
+		if err := writeExports(&buff, tc.Shell, vars); err != nil {
+			t.Fatal(err)
+		}
+		if buff.String() != tc.Expected {
+			t.Errorf("%s: expected %q but got %q", tc.Shell, tc.Expected, buff.String())
+		}
+	}
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package etcdctl implements the `etcdctl` command
+package etcdctl
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+)
+
+type flagpole struct {
+	Name string
+	Node string
+}
+
+// NewCommand returns a new cobra.Command for running etcdctl in a cluster
+func NewCommand() *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.ArbitraryArgs,
+		Use:   "etcdctl [flags] -- [etcdctl args...]",
+		Short: "runs etcdctl against the cluster's etcd",
+		Long: "runs etcdctl with the given arguments in the etcd static pod of a control-plane node, " +
+			"with the endpoint and client certificates kubeadm generated preconfigured\n\n" +
+			"EG: kind etcdctl -- get /registry/namespaces --prefix --keys-only",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runE(flags, args)
+		},
+	}
+	// everything after the first argument is passed to etcdctl
+	cmd.Flags().SetInterspersed(false)
+	cmd.Flags().StringVar(
+		&flags.Name,
+		"name",
+		cluster.DefaultName,
+		"the cluster context name",
+	)
+	cmd.Flags().StringVar(
+		&flags.Node,
+		"node",
+		"",
+		"the control-plane node to run etcdctl on, defaults to the bootstrap control-plane node",
+	)
+	return cmd
+}
+
+func runE(flags *flagpole, args []string) error {
+	options := []cluster.EtcdctlOption{
+		cluster.EtcdctlStreams(os.Stdin, os.Stdout, os.Stderr),
+	}
+	if flags.Node != "" {
+		options = append(options, cluster.EtcdctlNode(flags.Node))
+	}
+	return cluster.NewProvider().Etcdctl(flags.Name, args, options...)
+}
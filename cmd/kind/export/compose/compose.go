@@ -0,0 +1,63 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package compose implements the `compose` command
+package compose
+
+import (
+	"io/ioutil"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+type flagpole struct {
+	Name string
+}
+
+// NewCommand returns a new cobra.Command for exporting a compose file
+func NewCommand() *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.MaximumNArgs(1),
+		Use:   "compose [output-file]",
+		Short: "exports a docker compose file describing the node containers to stdout or [output-file]",
+		Long: "exports a docker compose file describing the node containers, their images, mounts, " +
+			"published ports and networks to stdout or [output-file] if specified\n\n" +
+			"kind configures the nodes further when creating the cluster, " +
+			"so the compose file is for inspecting and auditing the containers rather than recreating the cluster",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runE(flags, args)
+		},
+	}
+	cmd.Flags().StringVar(&flags.Name, "name", cluster.DefaultName, "the cluster context name")
+	return cmd
+}
+
+func runE(flags *flagpole, args []string) error {
+	compose, err := cluster.NewProvider().ExportCompose(flags.Name)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		_, err := os.Stdout.Write(compose)
+		return err
+	}
+	return errors.Wrap(ioutil.WriteFile(args[0], compose, 0644), "failed to write compose file")
+}
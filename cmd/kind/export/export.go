@@ -20,6 +20,7 @@ package export
 import (
 	"github.com/spf13/cobra"
 
+	"sigs.k8s.io/kind/cmd/kind/export/compose"
 	"sigs.k8s.io/kind/cmd/kind/export/logs"
 )
 
@@ -29,10 +30,11 @@ func NewCommand() *cobra.Command {
 		Args: cobra.NoArgs,
 		// TODO(bentheelder): more detailed usage
 		Use:   "export",
-		Short: "exports one of [logs]",
-		Long:  "exports one of [logs]",
+		Short: "exports one of [logs, compose]",
+		Long:  "exports one of [logs, compose]",
 	}
 	// add subcommands
+	cmd.AddCommand(compose.NewCommand())
 	cmd.AddCommand(logs.NewCommand())
 	return cmd
 }
@@ -18,16 +18,42 @@ limitations under the License.
 package logs
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/errors"
 	"sigs.k8s.io/kind/pkg/fs"
 )
 
+// nodesFromLabelPrefix prefixes the docker label selector of --nodes-from
+const nodesFromLabelPrefix = "docker-label="
+
 type flagpole struct {
-	Name string
+	Name        string
+	Roles       []string
+	Nodes       []string
+	NodesFrom   string
+	Since       string
+	Until       string
+	Incremental bool
+	Format      string
+	Collectors  []string
+	Concurrency int
+	MapOwner    bool
+	Redact      bool
+	MaxFileSize string
+	HTMLIndex   bool
+	SpoolDir    string
+	Output      string
 }
 
 // NewCommand returns a new cobra.Command for getting the cluster logs
@@ -38,44 +64,199 @@ func NewCommand() *cobra.Command {
 		// TODO(bentheelder): more detailed usage
 		Use:   "logs [output-dir]",
 		Short: "exports logs to a tempdir or [output-dir] if specified",
-		Long:  "exports logs to a tempdir or [output-dir] if specified",
+		Long: "exports logs to [output-dir] if specified, or a directory in the cluster's artifacts, see `kind path`, " +
+			"which is removed along with the cluster\n\n" +
+			"with --format tar.gz the logs are written to a single archive instead, " +
+			"[output-dir] is then the path of the archive",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runE(flags, args)
 		},
 	}
 	cmd.Flags().StringVar(&flags.Name, "name", cluster.DefaultName, "the cluster context name")
+	cmd.Flags().StringSliceVar(&flags.Roles, "role", nil, "only export logs from nodes with these roles, including custom roles")
+	cmd.Flags().StringSliceVar(&flags.Nodes, "nodes", nil, "only export logs from these nodes, EG kind-worker2")
+	cmd.Flags().StringVar(
+		&flags.NodesFrom, "nodes-from", "",
+		"export logs from the containers matching "+nodesFromLabelPrefix+"<key>[=<value>][,...] instead of the cluster's nodes, "+
+			"EG nodes of clusters created by a fork of kind with its own labels",
+	)
+	cmd.Flags().StringVar(&flags.Since, "since", "", "only export logs written since this RFC3339 timestamp or relative duration, EG 30m")
+	cmd.Flags().StringVar(&flags.Until, "until", "", "only export logs written before this RFC3339 timestamp or relative duration, EG 5m")
+	cmd.Flags().BoolVar(&flags.Incremental, "incremental", false, "append to a previous export into [output-dir], only exporting new logs and changed files")
+	cmd.Flags().StringVar(&flags.Format, "format", "dir", "export format, one of [dir, tar.gz]")
+	cmd.Flags().StringVar(
+		&flags.Output, "output", "",
+		"stream a tar.gz of the logs to s3://<bucket>/<key> (aws cli), gs://<bucket>/<object> (gsutil) "+
+			"or http(s)://<url> (PUT, EG a presigned URL) instead of writing to local disk, "+
+			"locations ending in / get a logs.tar.gz",
+	)
+	cmd.Flags().StringSliceVar(
+		&flags.Collectors, "collectors", nil,
+		"only run these collectors, by default all of ["+strings.Join(cluster.LogCollectors(), ", ")+"]",
+	)
+	cmd.Flags().IntVar(&flags.Concurrency, "max-concurrency", 8, "maximum number of commands collecting logs at once, 0 for no limit")
+	cmd.Flags().BoolVar(
+		&flags.MapOwner, "map-ownership", false,
+		"give the exported files to the invoking user, the sudo user if run with sudo, "+
+			"instead of preserving their owner on the nodes when running as root",
+	)
+	cmd.Flags().BoolVar(
+		&flags.Redact, "redact", false,
+		"scrub tokens, certificates, keys and kubeconfig credentials from the exported files, "+
+			"so they can be attached to public issues, compressed files are left out and binary files emptied",
+	)
+	cmd.Flags().StringVar(
+		&flags.MaxFileSize, "max-file-size", "",
+		"truncate exported files larger than this size, EG 100Mi, keeping their head and tail, "+
+			"compressed files larger than this are left out",
+	)
+	cmd.Flags().BoolVar(
+		&flags.HTMLIndex, "html-index", false,
+		"also write an index.html linking the exported files by node, with filtering and search, to browse them in a web browser",
+	)
+	cmd.Flags().StringVar(
+		&flags.SpoolDir, "spool-dir", "",
+		"with --format=tar.gz, keep exported files larger than 1Mi in this directory until they are complete rather than in memory",
+	)
 	return cmd
 }
 
 func runE(flags *flagpole, args []string) error {
-	provider := cluster.NewProvider()
-
-	// Check if the cluster has any running nodes
-	nodes, err := provider.ListNodes(flags.Name)
+	now := time.Now()
+	since, err := parseTime(flags.Since, now)
 	if err != nil {
-		return err
+		return errors.Wrap(err, "invalid --since")
+	}
+	until, err := parseTime(flags.Until, now)
+	if err != nil {
+		return errors.Wrap(err, "invalid --until")
+	}
+	if flags.Incremental && len(args) == 0 {
+		return errors.New("--incremental requires [output-dir]")
+	}
+	if flags.Output != "" {
+		if len(args) > 0 {
+			return errors.New("--output cannot be combined with [output-dir]")
+		}
+		flags.Format = "tar.gz"
 	}
-	if len(nodes) == 0 {
-		return fmt.Errorf("unknown cluster %q", flags.Name)
+	archive := false
+	switch flags.Format {
+	case "dir":
+	case "tar.gz":
+		archive = true
+		if flags.Incremental {
+			return errors.New("--incremental cannot be combined with --format tar.gz")
+		}
+	default:
+		return errors.Errorf("invalid --format %q, must be one of [dir, tar.gz]", flags.Format)
+	}
+
+	provider := cluster.NewProvider()
+
+	// Check if the cluster has any running nodes, unless collecting from
+	// other containers
+	var selector string
+	if flags.NodesFrom != "" {
+		var err error
+		if selector, err = parseNodesFrom(flags.NodesFrom); err != nil {
+			return err
+		}
+	} else {
+		nodes, err := provider.ListNodes(flags.Name)
+		if err != nil {
+			return err
+		}
+		if len(nodes) == 0 {
+			return fmt.Errorf("unknown cluster %q", flags.Name)
+		}
 	}
 
-	// get the optional directory argument, or create a tempdir
+	// get the output location, the optional directory argument, or create
+	// a directory in the cluster's artifacts, or a tempdir for containers
+	// outside of a cluster
 	var dir string
-	if len(args) == 0 {
-		t, err := fs.TempDir("", "")
+	switch {
+	case flags.Output != "":
+		dir = flags.Output
+	case len(args) == 0:
+		var t string
+		var err error
+		if selector != "" {
+			t, err = fs.TempDir("", "")
+		} else {
+			t, err = provider.ArtifactDir(flags.Name, "logs-")
+		}
 		if err != nil {
 			return err
 		}
 		dir = t
-	} else {
+		if archive {
+			dir = filepath.Join(t, "logs.tar.gz")
+		}
+	default:
 		dir = args[0]
 	}
 
+	// abort on interrupt, EG Ctrl-C or a CI timeout, killing the commands
+	// collecting logs
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	go func() {
+		select {
+		case <-signals:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
 	// collect the logs
-	if err := provider.CollectLogs(flags.Name, dir); err != nil {
+	if err := provider.CollectLogs(flags.Name, dir,
+		cluster.CollectLogsContext(ctx),
+		cluster.CollectLogsRoles(flags.Roles...),
+		cluster.CollectLogsNodes(flags.Nodes...),
+		cluster.CollectLogsNodesFrom(selector),
+		cluster.CollectLogsWindow(since, until),
+		cluster.CollectLogsIncremental(flags.Incremental),
+		cluster.CollectLogsArchive(archive),
+		cluster.CollectLogsCollectors(flags.Collectors...),
+		cluster.CollectLogsMaxConcurrency(flags.Concurrency),
+		cluster.CollectLogsMapOwnership(flags.MapOwner),
+		cluster.CollectLogsRedact(flags.Redact),
+		cluster.CollectLogsMaxFileSize(flags.MaxFileSize),
+		cluster.CollectLogsHTMLIndex(flags.HTMLIndex),
+		cluster.CollectLogsSpoolDir(flags.SpoolDir),
+	); err != nil {
 		return err
 	}
 
 	fmt.Println("Exported logs to: " + dir)
 	return nil
 }
+
+// parseTime parses value as either an RFC3339 timestamp or a duration
+// before now, "" is the zero time
+func parseTime(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// parseNodesFrom returns the docker label selector of a --nodes-from value
+func parseNodesFrom(value string) (string, error) {
+	if !strings.HasPrefix(value, nodesFromLabelPrefix) {
+		return "", errors.Errorf("invalid --nodes-from %q, must be %s<selector>", value, nodesFromLabelPrefix)
+	}
+	selector := strings.TrimPrefix(value, nodesFromLabelPrefix)
+	if strings.Trim(selector, ", ") == "" {
+		return "", errors.Errorf("invalid --nodes-from %q, the label selector must not be empty", value)
+	}
+	return selector, nil
+}
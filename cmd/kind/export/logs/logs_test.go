@@ -0,0 +1,69 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logs
+
+import (
+	"testing"
+)
+
+func TestParseNodesFrom(t *testing.T) {
+	cases := []struct {
+		Name      string
+		Value     string
+		Expected  string
+		ExpectErr bool
+	}{
+		{
+			Name:     "label key",
+			Value:    "docker-label=io.x-k8s.kind.cluster",
+			Expected: "io.x-k8s.kind.cluster",
+		},
+		{
+			Name:     "labels",
+			Value:    "docker-label=io.x-k8s.kind.cluster=ci,io.x-k8s.kind.role=worker",
+			Expected: "io.x-k8s.kind.cluster=ci,io.x-k8s.kind.role=worker",
+		},
+		{
+			Name:      "unknown source",
+			Value:     "podman-label=io.x-k8s.kind.cluster",
+			ExpectErr: true,
+		},
+		{
+			Name:      "no prefix",
+			Value:     "io.x-k8s.kind.cluster",
+			ExpectErr: true,
+		},
+		{
+			Name:      "empty selector",
+			Value:     "docker-label=,",
+			ExpectErr: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			result, err := parseNodesFrom(tc.Value)
+			if (err != nil) != tc.ExpectErr {
+				t.Fatalf("parseNodesFrom() error = %v, ExpectErr %v", err, tc.ExpectErr)
+			}
+			if result != tc.Expected {
+				t.Errorf("parseNodesFrom() = %q, expected %q", result, tc.Expected)
+			}
+		})
+	}
+}
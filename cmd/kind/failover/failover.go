@@ -0,0 +1,108 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package failover implements the `failover` command
+package failover
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+type flagpole struct {
+	Name       string
+	KillLeader bool
+	Node       string
+	Timeout    time.Duration
+}
+
+// NewCommand returns a new cobra.Command for control plane failover testing
+func NewCommand() *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "failover",
+		Short: "stops a control-plane node and reports how long the cluster takes to recover",
+		Long: "stops the control-plane node hosting the etcd leader (--kill-leader) or a given node (--node), " +
+			"waits for a new etcd leader and for the API server to be available again, and reports the timings\n\n" +
+			"the cluster must have at least three control-plane nodes so that etcd keeps quorum\n\n" +
+			"stopped nodes can be started again with: kind failover restore",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runE(flags)
+		},
+	}
+	cmd.Flags().StringVar(
+		&flags.Name,
+		"name",
+		cluster.DefaultName,
+		"the cluster context name",
+	)
+	cmd.Flags().BoolVar(
+		&flags.KillLeader,
+		"kill-leader",
+		false,
+		"stop the control-plane node hosting the etcd leader",
+	)
+	cmd.Flags().StringVar(
+		&flags.Node,
+		"node",
+		"",
+		"stop this control-plane node instead of the etcd leader",
+	)
+	cmd.Flags().DurationVar(
+		&flags.Timeout,
+		"timeout",
+		cluster.DefaultFailoverTimeout,
+		"how long to wait for the control plane to recover",
+	)
+	cmd.AddCommand(newRestoreCommand())
+	return cmd
+}
+
+func runE(flags *flagpole) error {
+	if flags.KillLeader == (flags.Node != "") {
+		return errors.New("exactly one of --kill-leader or --node must be set")
+	}
+	options := []cluster.FailoverOption{cluster.FailoverTimeout(flags.Timeout)}
+	if flags.Node != "" {
+		options = append(options, cluster.FailoverNode(flags.Node))
+	}
+	report, err := cluster.NewProvider().Failover(flags.Name, options...)
+	if report != nil {
+		if report.PreviousLeader != "" {
+			fmt.Printf("Stopped control-plane node %s (etcd leader was %s)\n", report.Nodes[0], report.PreviousLeader)
+		} else {
+			fmt.Printf("Stopped control-plane node %s\n", report.Nodes[0])
+		}
+		printRecovery(report)
+	}
+	return err
+}
+
+// printRecovery prints the recovery timings recorded in report
+func printRecovery(report *cluster.FailoverReport) {
+	if report.Leader != "" {
+		fmt.Printf("etcd leader %s elected after %s\n", report.Leader, report.LeaderElected.Round(time.Millisecond))
+	}
+	if report.APIServerAvailable != 0 {
+		fmt.Printf("API server available after %s\n", report.APIServerAvailable.Round(time.Millisecond))
+	}
+}
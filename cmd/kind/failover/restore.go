@@ -0,0 +1,71 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package failover
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+)
+
+type restoreFlagpole struct {
+	Name    string
+	Timeout time.Duration
+}
+
+// newRestoreCommand returns a new cobra.Command for restarting stopped
+// control plane nodes
+func newRestoreCommand() *cobra.Command {
+	flags := &restoreFlagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "restore",
+		Short: "starts stopped control-plane nodes and reports how long they take to rejoin",
+		Long:  "starts stopped control-plane nodes and reports how long they take to rejoin",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRestoreE(flags)
+		},
+	}
+	cmd.Flags().StringVar(
+		&flags.Name,
+		"name",
+		cluster.DefaultName,
+		"the cluster context name",
+	)
+	cmd.Flags().DurationVar(
+		&flags.Timeout,
+		"timeout",
+		cluster.DefaultFailoverTimeout,
+		"how long to wait for the control plane to recover",
+	)
+	return cmd
+}
+
+func runRestoreE(flags *restoreFlagpole) error {
+	report, err := cluster.NewProvider().RestoreFailover(
+		flags.Name, cluster.FailoverTimeout(flags.Timeout),
+	)
+	if report != nil {
+		fmt.Printf("Started control-plane nodes %s\n", strings.Join(report.Nodes, ", "))
+		printRecovery(report)
+	}
+	return err
+}
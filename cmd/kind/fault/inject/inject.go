@@ -0,0 +1,103 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package inject implements the `inject` command
+package inject
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+type flagpole struct {
+	Name      string
+	Node      string
+	Service   string
+	CrashLoop int
+	Downtime  time.Duration
+	Uptime    time.Duration
+}
+
+// NewCommand returns a new cobra.Command for crash looping node services
+func NewCommand() *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "inject",
+		Short: "crash loops a service on a node, then restores it",
+		Long: "repeatedly kills a service on a node, systemd restarts it after --downtime " +
+			"and it is left running for --uptime before the next crash. " +
+			"the service's normal restart behavior is restored afterwards",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runE(flags)
+		},
+	}
+	cmd.Flags().StringVar(
+		&flags.Name,
+		"name",
+		cluster.DefaultName,
+		"the cluster context name",
+	)
+	cmd.Flags().StringVar(
+		&flags.Node,
+		"node",
+		"",
+		"the node to inject the fault into",
+	)
+	cmd.Flags().StringVar(
+		&flags.Service,
+		"service",
+		"kubelet",
+		fmt.Sprintf("the service to crash, one of %s", strings.Join(cluster.FaultServices, ", ")),
+	)
+	cmd.Flags().IntVar(
+		&flags.CrashLoop,
+		"crashloop",
+		1,
+		"how many times to crash the service",
+	)
+	cmd.Flags().DurationVar(
+		&flags.Downtime,
+		"downtime",
+		cluster.DefaultFaultDowntime,
+		"how long the service stays down after each crash",
+	)
+	cmd.Flags().DurationVar(
+		&flags.Uptime,
+		"uptime",
+		cluster.DefaultFaultUptime,
+		"how long the service runs between crashes",
+	)
+	return cmd
+}
+
+func runE(flags *flagpole) error {
+	if flags.Node == "" {
+		return errors.New("--node must be set")
+	}
+	return cluster.NewProvider().InjectFault(
+		flags.Name, flags.Node, flags.Service,
+		cluster.FaultCrashLoop(flags.CrashLoop),
+		cluster.FaultDowntime(flags.Downtime),
+		cluster.FaultUptime(flags.Uptime),
+	)
+}
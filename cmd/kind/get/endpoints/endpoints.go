@@ -0,0 +1,98 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package endpoints implements the `endpoints` command
+package endpoints
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+type flagpole struct {
+	Name   string
+	Output string
+}
+
+// NewCommand returns a new cobra.Command for getting the endpoints of a cluster
+func NewCommand() *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "endpoints",
+		Short: "prints the API server, load balancer and node endpoints of a cluster",
+		Long:  "prints the API server, load balancer and node endpoints of a cluster",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runE(flags)
+		},
+	}
+	cmd.Flags().StringVar(
+		&flags.Name,
+		"name",
+		cluster.DefaultName,
+		"the cluster context name",
+	)
+	cmd.Flags().StringVarP(
+		&flags.Output,
+		"output",
+		"o",
+		"",
+		"output format, one of: json",
+	)
+	return cmd
+}
+
+func runE(flags *flagpole) error {
+	if flags.Output != "" && flags.Output != "json" {
+		return errors.Errorf("unknown output format %q, must be one of: json", flags.Output)
+	}
+	endpoints, err := cluster.NewProvider().Endpoints(flags.Name)
+	if err != nil {
+		return err
+	}
+	if flags.Output == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(endpoints)
+	}
+
+	fmt.Printf("API Server: %s\n", endpoints.APIServer)
+	fmt.Printf("API Server (internal): %s\n", endpoints.APIServerInternal)
+	if endpoints.LoadBalancer != "" {
+		fmt.Printf("Load Balancer: %s\n", endpoints.LoadBalancer)
+	}
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "NAME\tROLE\tIPV4\tIPV6\tPORTS")
+	for _, n := range endpoints.Nodes {
+		ports := ""
+		for i, m := range n.PortMappings {
+			if i > 0 {
+				ports += ","
+			}
+			ports += fmt.Sprintf("%s:%d->%d/%s", m.ListenAddress, m.HostPort, m.ContainerPort, m.Protocol)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", n.Name, n.Role, n.IPv4, n.IPv6, ports)
+	}
+	return w.Flush()
+}
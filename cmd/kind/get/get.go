@@ -21,6 +21,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"sigs.k8s.io/kind/cmd/kind/get/clusters"
+	"sigs.k8s.io/kind/cmd/kind/get/endpoints"
 	"sigs.k8s.io/kind/cmd/kind/get/kubeconfig"
 	"sigs.k8s.io/kind/cmd/kind/get/kubeconfigpath"
 	"sigs.k8s.io/kind/cmd/kind/get/nodes"
@@ -32,13 +33,14 @@ func NewCommand() *cobra.Command {
 		Args: cobra.NoArgs,
 		// TODO(bentheelder): more detailed usage
 		Use:   "get",
-		Short: "Gets one of [clusters, nodes, kubeconfig, kubeconfig-path]",
-		Long:  "Gets one of [clusters, nodes, kubeconfig, kubeconfig-path]",
+		Short: "Gets one of [clusters, nodes, kubeconfig, kubeconfig-path, endpoints]",
+		Long:  "Gets one of [clusters, nodes, kubeconfig, kubeconfig-path, endpoints]",
 	}
 	// add subcommands
 	cmd.AddCommand(clusters.NewCommand())
 	cmd.AddCommand(nodes.NewCommand())
 	cmd.AddCommand(kubeconfig.NewCommand())
 	cmd.AddCommand(kubeconfigpath.NewCommand())
+	cmd.AddCommand(endpoints.NewCommand())
 	return cmd
 }
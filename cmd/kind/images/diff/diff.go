@@ -0,0 +1,100 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package diff implements the `diff` command
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kind/pkg/build/node"
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+type flagpole struct {
+	Output string
+}
+
+// NewCommand returns a new cobra.Command for diffing node images
+func NewCommand() *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.ExactArgs(2),
+		Use:   "diff <from-image> <to-image>",
+		Short: "reports the component and package version changes between two node images",
+		Long: "reports the version changes of the node components (kubelet, container runtime, runc, CNI plugins) " +
+			"and base OS packages between two node images, EG kind images diff kindest/node:v1.19.1 kindest/node:v1.20.2\n\n" +
+			"the images are pulled if necessary and a container is run from each to inspect them",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runE(flags, args)
+		},
+	}
+	cmd.Flags().StringVarP(
+		&flags.Output,
+		"output",
+		"o",
+		"",
+		"output format, one of: json",
+	)
+	return cmd
+}
+
+func runE(flags *flagpole, args []string) error {
+	if flags.Output != "" && flags.Output != "json" {
+		return errors.Errorf("unknown output format %q, must be one of: json", flags.Output)
+	}
+	from, err := node.InspectImage(args[0])
+	if err != nil {
+		return err
+	}
+	to, err := node.InspectImage(args[1])
+	if err != nil {
+		return err
+	}
+	diff := node.DiffImages(from, to)
+	if flags.Output == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(diff)
+	}
+
+	fmt.Println("Components:")
+	printChanges(diff.Components)
+	fmt.Println("Packages:")
+	printChanges(diff.Packages)
+	return nil
+}
+
+// printChanges prints a line per change, marking added and removed entries
+func printChanges(changes []node.VersionChange) {
+	if len(changes) == 0 {
+		fmt.Println("  (no changes)")
+	}
+	for _, c := range changes {
+		switch {
+		case c.From == "":
+			fmt.Printf("  + %s %s\n", c.Name, c.To)
+		case c.To == "":
+			fmt.Printf("  - %s %s\n", c.Name, c.From)
+		default:
+			fmt.Printf("  ~ %s %s -> %s\n", c.Name, c.From, c.To)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package loader implements loading image archives into nodes with progress
+// reporting and verification, shared by the load and serve commands
+package loader
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/globals"
+)
+
+// progressInterval is how often progress is reported for in flight loads
+const progressInterval = 5 * time.Second
+
+// Result describes loading an image archive into one node
+type Result struct {
+	Node string `json:"node"`
+	// Images are the tags (or IDs, for untagged images) loaded
+	Images []string `json:"images"`
+	// Bytes is the size of the archive transferred to the node
+	Bytes int64 `json:"bytes"`
+	// Duration is how long loading took
+	Duration string `json:"duration,omitempty"`
+	// Verified is true if the images were confirmed present after loading
+	Verified bool `json:"verified"`
+	// AlreadyPresent is true if the node already had the images and
+	// nothing was loaded
+	AlreadyPresent bool `json:"alreadyPresent,omitempty"`
+}
+
+// Load loads the image archive at path into each node concurrently,
+// logging progress, then verifies the images are present if verify is set
+func Load(path string, nodeList []nodes.Node, verify bool) ([]Result, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	images, err := archiveImages(path)
+	if err != nil {
+		return nil, err
+	}
+	refs := imageRefs(images)
+
+	// report progress until all loads are done
+	transferred := make([]int64, len(nodeList))
+	finished := make([]int32, len(nodeList))
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(progressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				for i, node := range nodeList {
+					if atomic.LoadInt32(&finished[i]) == 1 {
+						continue
+					}
+					globals.GetLogger().V(0).Infof(
+						"Loading %s into node %q: %s / %s",
+						filepath.Base(path), node.String(),
+						formatBytes(atomic.LoadInt64(&transferred[i])), formatBytes(info.Size()),
+					)
+				}
+			}
+		}
+	}()
+
+	results := make([]Result, len(nodeList))
+	fns := []func() error{}
+	for i, node := range nodeList {
+		i, node := i, node // capture loop variables
+		fns = append(fns, func() error {
+			start := time.Now()
+			f, err := os.Open(path)
+			if err != nil {
+				return errors.Wrap(err, "failed to open image")
+			}
+			defer f.Close()
+			if err := nodeutils.LoadImageArchive(node, &countingReader{r: f, n: &transferred[i]}); err != nil {
+				return errors.Wrapf(err, "failed to load image into node %q", node.String())
+			}
+			results[i] = Result{
+				Node:     node.String(),
+				Images:   refs,
+				Bytes:    atomic.LoadInt64(&transferred[i]),
+				Duration: time.Since(start).Round(time.Millisecond).String(),
+			}
+			if verify {
+				for _, image := range images {
+					for _, ref := range imageRefs([]nodeutils.ArchiveImage{image}) {
+						if err := nodeutils.VerifyImage(node, ref, image.ID); err != nil {
+							return errors.Wrap(err, "failed to verify loaded image")
+						}
+					}
+				}
+				results[i].Verified = true
+			}
+			atomic.StoreInt32(&finished[i], 1)
+			globals.GetLogger().V(0).Infof(
+				"Loaded %s into node %q (%s in %s)",
+				filepath.Base(path), node.String(), formatBytes(results[i].Bytes), results[i].Duration,
+			)
+			return nil
+		})
+	}
+	if err := errors.UntilErrorConcurrent(fns); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// ChangeRecorder records changes to clusters, see cluster.Provider
+type ChangeRecorder interface {
+	RecordChange(name string, change cluster.Change)
+}
+
+// RecordChange records the images loaded by results in the changelog of the
+// cluster name, nodes which already had them are left out
+func RecordChange(provider ChangeRecorder, name string, results []Result) {
+	change := cluster.Change{Action: cluster.ChangeLoadImage}
+	images := []string{}
+	seen := map[string]bool{}
+	for _, r := range results {
+		if r.AlreadyPresent {
+			continue
+		}
+		change.Nodes = append(change.Nodes, r.Node)
+		for _, image := range r.Images {
+			if !seen[image] {
+				seen[image] = true
+				images = append(images, image)
+			}
+		}
+	}
+	if len(change.Nodes) == 0 {
+		return
+	}
+	change.Details = map[string]string{"images": strings.Join(images, ",")}
+	provider.RecordChange(name, change)
+}
+
+// PrintJSON writes results to w as indented JSON
+func PrintJSON(w io.Writer, results []Result) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(results)
+}
+
+// ValidateOutput returns an error if output is not a known output format
+func ValidateOutput(output string) error {
+	if output != "" && output != "json" {
+		return errors.Errorf("unknown output format %q, must be one of: json", output)
+	}
+	return nil
+}
+
+func archiveImages(path string) ([]nodeutils.ArchiveImage, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open image")
+	}
+	defer f.Close()
+	return nodeutils.ArchiveImages(f)
+}
+
+// imageRefs returns the tags of images, or the ID of untagged images
+func imageRefs(images []nodeutils.ArchiveImage) []string {
+	refs := []string{}
+	for _, image := range images {
+		if len(image.RepoTags) == 0 {
+			refs = append(refs, image.ID)
+			continue
+		}
+		refs = append(refs, image.RepoTags...)
+	}
+	return refs
+}
+
+// countingReader counts the bytes read through it into n
+type countingReader struct {
+	r io.Reader
+	n *int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	atomic.AddInt64(c.n, int64(n))
+	return n, err
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,211 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package metrics implements exporting the phase timings of kind commands
+// in the Prometheus text format, to a pushgateway or an OpenMetrics file,
+// shared by the create, delete and load commands
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/globals"
+)
+
+// pushTimeout bounds pushing the metrics to a pushgateway
+const pushTimeout = 30 * time.Second
+
+// Job is the pushgateway job the metrics are grouped under
+const Job = "kind"
+
+// Flags configures where the metrics of a command are exported to
+type Flags struct {
+	// Pushgateway is the URL of a Prometheus pushgateway, if set
+	Pushgateway string
+	// File is the path of an OpenMetrics file, if set
+	File string
+}
+
+// AddFlags adds the flags configuring f to cmd
+func AddFlags(cmd *cobra.Command, f *Flags) {
+	cmd.Flags().StringVar(
+		&f.Pushgateway, "metrics-pushgateway", "",
+		"push the timings of the command and its phases to the Prometheus pushgateway at this URL, "+
+			"grouped by job="+Job+", command and cluster",
+	)
+	cmd.Flags().StringVar(&f.File, "metrics-file", "", "write the timings of the command and its phases to this file in the OpenMetrics format")
+}
+
+// Enabled returns true if the metrics are exported anywhere
+func (f *Flags) Enabled() bool {
+	return f.Pushgateway != "" || f.File != ""
+}
+
+// phase is the timing of one phase of a command
+type phase struct {
+	name    string
+	elapsed time.Duration
+}
+
+// Recorder records the timings of a command and its phases
+type Recorder struct {
+	command string
+	cluster string
+	started time.Time
+
+	mu     sync.Mutex
+	phases []*phase
+}
+
+// NewRecorder returns a Recorder for command, EG "create cluster", on the
+// named cluster, timing the command from now
+func NewRecorder(command, cluster string) *Recorder {
+	return &Recorder{
+		command: command,
+		cluster: cluster,
+		started: time.Now(),
+	}
+}
+
+// ObservePhase records that the named phase took elapsed, phases observed
+// more than once are summed
+// Names are stable identifiers, EG the create phase "kubeadmjoin"
+func (r *Recorder) ObservePhase(name string, elapsed time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, p := range r.phases {
+		if p.name == name {
+			p.elapsed += elapsed
+			return
+		}
+	}
+	r.phases = append(r.phases, &phase{name: name, elapsed: elapsed})
+}
+
+// Phase runs fn recording how long it took as the named phase
+func (r *Recorder) Phase(name string, fn func() error) error {
+	start := time.Now()
+	err := fn()
+	r.ObservePhase(name, time.Since(start))
+	return err
+}
+
+// Export exports the metrics of the command, which failed if err is not
+// nil, as configured by flags
+// Failing to export the metrics is logged rather than failing the command
+func (r *Recorder) Export(flags *Flags, err error) {
+	if !flags.Enabled() {
+		return
+	}
+	end := time.Now()
+	if flags.File != "" {
+		var buf bytes.Buffer
+		r.write(&buf, end, err == nil, true)
+		if werr := ioutil.WriteFile(flags.File, buf.Bytes(), 0644); werr != nil {
+			globals.GetLogger().Warnf("WARNING: failed to write metrics file: %v", werr)
+		}
+	}
+	if flags.Pushgateway != "" {
+		var buf bytes.Buffer
+		r.write(&buf, end, err == nil, false)
+		if perr := r.push(flags.Pushgateway, &buf); perr != nil {
+			globals.GetLogger().Warnf("WARNING: failed to push metrics: %v", perr)
+		}
+	}
+}
+
+// push replaces the metrics of the command's group on the pushgateway at
+// gateway with those read from body
+func (r *Recorder) push(gateway string, body io.Reader) error {
+	u := strings.TrimSuffix(gateway, "/") + "/metrics/job/" + url.PathEscape(Job) +
+		"/command/" + url.PathEscape(r.command) +
+		"/cluster/" + url.PathEscape(r.cluster)
+	ctx, cancel := context.WithTimeout(context.Background(), pushTimeout)
+	defer cancel()
+	req, err := http.NewRequest(http.MethodPut, u, body)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return errors.Errorf("pushgateway returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// write writes the metrics of the command ending at end to w in the
+// Prometheus text format, or OpenMetrics if openMetrics is set
+func (r *Recorder) write(w io.Writer, end time.Time, success, openMetrics bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	labels := fmt.Sprintf(`command="%s",cluster="%s"`, escape(r.command), escape(r.cluster))
+	gauge := func(name, help string) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+	}
+
+	gauge("kind_command_duration_seconds", "How long the kind command took.")
+	fmt.Fprintf(w, "kind_command_duration_seconds{%s} %s\n", labels, seconds(end.Sub(r.started)))
+	gauge("kind_command_success", "Whether the kind command succeeded.")
+	fmt.Fprintf(w, "kind_command_success{%s} %s\n", labels, boolValue(success))
+	gauge("kind_command_completion_timestamp_seconds", "When the kind command completed.")
+	fmt.Fprintf(w, "kind_command_completion_timestamp_seconds{%s} %s\n", labels, fmt.Sprintf("%.3f", float64(end.UnixNano())/1e9))
+	if len(r.phases) > 0 {
+		gauge("kind_phase_duration_seconds", "How long each phase of the kind command took.")
+		for _, p := range r.phases {
+			fmt.Fprintf(w, "kind_phase_duration_seconds{%s,phase=\"%s\"} %s\n",
+				labels, escape(p.name), seconds(p.elapsed),
+			)
+		}
+	}
+	if openMetrics {
+		fmt.Fprint(w, "# EOF\n")
+	}
+}
+
+// escape escapes a label value
+func escape(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+func boolValue(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRecorderWrite(t *testing.T) {
+	t.Parallel()
+	start := time.Unix(1500000000, 0)
+	r := &Recorder{command: "create cluster", cluster: "ci-\"1\"", started: start}
+	r.ObservePhase("provision", 12*time.Second)
+	r.ObservePhase("prepullimages", 3*time.Second)
+	r.ObservePhase("prepullimages", 2*time.Second)
+
+	var buf bytes.Buffer
+	r.write(&buf, start.Add(90500*time.Millisecond), false, true)
+	labels := `command="create cluster",cluster="ci-\"1\""`
+	expected := "# HELP kind_command_duration_seconds How long the kind command took.\n" +
+		"# TYPE kind_command_duration_seconds gauge\n" +
+		"kind_command_duration_seconds{" + labels + "} 90.500\n" +
+		"# HELP kind_command_success Whether the kind command succeeded.\n" +
+		"# TYPE kind_command_success gauge\n" +
+		"kind_command_success{" + labels + "} 0\n" +
+		"# HELP kind_command_completion_timestamp_seconds When the kind command completed.\n" +
+		"# TYPE kind_command_completion_timestamp_seconds gauge\n" +
+		"kind_command_completion_timestamp_seconds{" + labels + "} 1500000090.500\n" +
+		"# HELP kind_phase_duration_seconds How long each phase of the kind command took.\n" +
+		"# TYPE kind_phase_duration_seconds gauge\n" +
+		"kind_phase_duration_seconds{" + labels + ",phase=\"provision\"} 12.000\n" +
+		"kind_phase_duration_seconds{" + labels + ",phase=\"prepullimages\"} 5.000\n" +
+		"# EOF\n"
+	if buf.String() != expected {
+		t.Errorf("expected:\n%s\nbut got:\n%s", expected, buf.String())
+	}
+}
+
+func TestRecorderPush(t *testing.T) {
+	t.Parallel()
+	var method, path, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		raw, _ := ioutil.ReadAll(req.Body)
+		method, path, body = req.Method, req.URL.EscapedPath(), string(raw)
+	}))
+	defer server.Close()
+
+	r := NewRecorder("load docker-image", "kind")
+	if err := r.push(server.URL+"/", bytes.NewBufferString("metrics")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if method != http.MethodPut || path != "/metrics/job/kind/command/load%20docker-image/cluster/kind" || body != "metrics" {
+		t.Errorf("unexpected push: %s %s %q", method, path, body)
+	}
+}
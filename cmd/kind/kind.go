@@ -23,12 +23,31 @@ import (
 	"github.com/spf13/cobra"
 
 	"sigs.k8s.io/kind/cmd/kind/build"
+	"sigs.k8s.io/kind/cmd/kind/capture"
+	"sigs.k8s.io/kind/cmd/kind/checkpoint"
 	"sigs.k8s.io/kind/cmd/kind/completion"
 	"sigs.k8s.io/kind/cmd/kind/create"
 	"sigs.k8s.io/kind/cmd/kind/delete"
+	"sigs.k8s.io/kind/cmd/kind/describe"
+	"sigs.k8s.io/kind/cmd/kind/diff"
+	"sigs.k8s.io/kind/cmd/kind/env"
+	"sigs.k8s.io/kind/cmd/kind/etcdctl"
 	"sigs.k8s.io/kind/cmd/kind/export"
+	"sigs.k8s.io/kind/cmd/kind/failover"
+	"sigs.k8s.io/kind/cmd/kind/fault"
 	"sigs.k8s.io/kind/cmd/kind/get"
+	"sigs.k8s.io/kind/cmd/kind/images"
+	"sigs.k8s.io/kind/cmd/kind/kubeconfig"
 	"sigs.k8s.io/kind/cmd/kind/load"
+	"sigs.k8s.io/kind/cmd/kind/logs"
+	"sigs.k8s.io/kind/cmd/kind/net"
+	"sigs.k8s.io/kind/cmd/kind/path"
+	"sigs.k8s.io/kind/cmd/kind/proxy"
+	"sigs.k8s.io/kind/cmd/kind/restore"
+	"sigs.k8s.io/kind/cmd/kind/serve"
+	"sigs.k8s.io/kind/cmd/kind/set"
+	"sigs.k8s.io/kind/cmd/kind/supervise"
+	"sigs.k8s.io/kind/cmd/kind/upgrade"
 	"sigs.k8s.io/kind/cmd/kind/version"
 	"sigs.k8s.io/kind/pkg/errors"
 	"sigs.k8s.io/kind/pkg/exec"
@@ -80,11 +99,30 @@ func NewCommand() *cobra.Command {
 	)
 	// add all top level subcommands
 	cmd.AddCommand(build.NewCommand())
+	cmd.AddCommand(capture.NewCommand())
+	cmd.AddCommand(checkpoint.NewCommand())
 	cmd.AddCommand(completion.NewCommand())
 	cmd.AddCommand(create.NewCommand())
 	cmd.AddCommand(delete.NewCommand())
+	cmd.AddCommand(describe.NewCommand())
+	cmd.AddCommand(diff.NewCommand())
+	cmd.AddCommand(env.NewCommand())
+	cmd.AddCommand(etcdctl.NewCommand())
 	cmd.AddCommand(export.NewCommand())
+	cmd.AddCommand(failover.NewCommand())
+	cmd.AddCommand(fault.NewCommand())
 	cmd.AddCommand(get.NewCommand())
+	cmd.AddCommand(images.NewCommand())
+	cmd.AddCommand(kubeconfig.NewCommand())
+	cmd.AddCommand(logs.NewCommand())
+	cmd.AddCommand(net.NewCommand())
+	cmd.AddCommand(path.NewCommand())
+	cmd.AddCommand(proxy.NewCommand())
+	cmd.AddCommand(restore.NewCommand())
+	cmd.AddCommand(serve.NewCommand())
+	cmd.AddCommand(set.NewCommand())
+	cmd.AddCommand(supervise.NewCommand())
+	cmd.AddCommand(upgrade.NewCommand())
 	cmd.AddCommand(version.NewCommand())
 	cmd.AddCommand(load.NewCommand())
 	return cmd
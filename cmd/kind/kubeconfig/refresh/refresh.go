@@ -0,0 +1,64 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package refresh implements the `refresh` command
+package refresh
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+)
+
+type flagpole struct {
+	Name string
+}
+
+// NewCommand returns a new cobra.Command for refreshing a cluster kubeconfig
+func NewCommand() *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "refresh",
+		Short: "updates the cluster kubeconfig to the current API server endpoint",
+		Long: "updates the cluster kubeconfig to the current API server endpoint, " +
+			"which changes when the node containers are restarted and get new host ports",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runE(flags)
+		},
+	}
+	cmd.Flags().StringVar(
+		&flags.Name,
+		"name",
+		cluster.DefaultName,
+		"the cluster context name",
+	)
+	return cmd
+}
+
+func runE(flags *flagpole) error {
+	provider := cluster.NewProvider()
+	changed, err := provider.RefreshKubeConfig(flags.Name)
+	if err != nil {
+		return err
+	}
+	if !changed {
+		fmt.Printf("kubeconfig %s is up to date\n", provider.KubeConfigPath(flags.Name))
+	}
+	return nil
+}
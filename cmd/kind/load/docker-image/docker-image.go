@@ -24,18 +24,22 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"sigs.k8s.io/kind/cmd/kind/internal/loader"
+	"sigs.k8s.io/kind/cmd/kind/internal/metrics"
 	"sigs.k8s.io/kind/pkg/cluster"
 	"sigs.k8s.io/kind/pkg/cluster/nodes"
 	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
 	"sigs.k8s.io/kind/pkg/errors"
 	"sigs.k8s.io/kind/pkg/exec"
-	"sigs.k8s.io/kind/pkg/fs"
 	"sigs.k8s.io/kind/pkg/globals"
 )
 
 type flagpole struct {
-	Name  string
-	Nodes []string
+	Name    string
+	Nodes   []string
+	Verify  bool
+	Output  string
+	Metrics metrics.Flags
 }
 
 // NewCommand returns a new cobra.Command for loading an image into a cluster
@@ -67,10 +71,29 @@ func NewCommand() *cobra.Command {
 		nil,
 		"comma separated list of nodes to load images into",
 	)
+	cmd.Flags().BoolVar(
+		&flags.Verify,
+		"verify",
+		true,
+		"verify the image is present on each node with the expected ID after loading",
+	)
+	cmd.Flags().StringVarP(
+		&flags.Output,
+		"output",
+		"o",
+		"",
+		"output format for a summary of what was loaded where, one of: json",
+	)
+	metrics.AddFlags(cmd, &flags.Metrics)
 	return cmd
 }
 
-func runE(flags *flagpole, args []string) error {
+func runE(flags *flagpole, args []string) (err error) {
+	recorder := metrics.NewRecorder("load docker-image", flags.Name)
+	defer func() { recorder.Export(&flags.Metrics, err) }()
+	if err := loader.ValidateOutput(flags.Output); err != nil {
+		return err
+	}
 	provider := cluster.NewProvider()
 
 	// Check that the image exists locally and gets its ID, if not return error
@@ -113,52 +136,58 @@ func runE(flags *flagpole, args []string) error {
 
 	// pick only the nodes that don't have the image
 	selectedNodes := []nodes.Node{}
+	results := []loader.Result{}
 	for _, node := range candidateNodes {
 		id, err := nodeutils.ImageID(node, imageName)
 		if err != nil || id != imageID {
 			selectedNodes = append(selectedNodes, node)
 			globals.GetLogger().V(0).Infof("Image: %q with ID %q not present on node %q", imageName, imageID, node.String())
+			continue
 		}
+		results = append(results, loader.Result{
+			Node:           node.String(),
+			Images:         []string{imageName},
+			Verified:       true,
+			AlreadyPresent: true,
+		})
 	}
 
 	if len(selectedNodes) == 0 {
-		return nil
+		return printResults(flags, results)
 	}
 
 	// Save the image into a tar
-	dir, err := fs.TempDir("", "image-tar")
+	dir, err := provider.TempDir(flags.Name, "image-tar")
 	if err != nil {
 		return errors.Wrap(err, "failed to create tempdir")
 	}
 	defer os.RemoveAll(dir)
 	imageTarPath := filepath.Join(dir, "image.tar")
 
-	err = save(imageName, imageTarPath)
-	if err != nil {
+	if err := recorder.Phase("saveimage", func() error {
+		return save(imageName, imageTarPath)
+	}); err != nil {
 		return err
 	}
 
 	// Load the image on the selected nodes
-	fns := []func() error{}
-	for _, selectedNode := range selectedNodes {
-		selectedNode := selectedNode // capture loop variable
-		fns = append(fns, func() error {
-			return loadImage(imageTarPath, selectedNode)
-		})
+	var loaded []loader.Result
+	if err := recorder.Phase("loadimage", func() error {
+		var err error
+		loaded, err = loader.Load(imageTarPath, selectedNodes, flags.Verify)
+		return err
+	}); err != nil {
+		return err
 	}
-	return errors.UntilErrorConcurrent(fns)
+	loader.RecordChange(provider, flags.Name, loaded)
+	return printResults(flags, append(results, loaded...))
 }
 
-// TODO: we should consider having a cluster method to load images
-
-// loads an image tarball onto a node
-func loadImage(imageTarName string, node nodes.Node) error {
-	f, err := os.Open(imageTarName)
-	if err != nil {
-		return errors.Wrap(err, "failed to open image")
+func printResults(flags *flagpole, results []loader.Result) error {
+	if flags.Output == "json" {
+		return loader.PrintJSON(os.Stdout, results)
 	}
-	defer f.Close()
-	return nodeutils.LoadImageArchive(node, f)
+	return nil
 }
 
 // save saves image to dest, as in `docker save`
@@ -22,16 +22,19 @@ import (
 	"os"
 
 	"github.com/spf13/cobra"
-	"sigs.k8s.io/kind/pkg/errors"
 
+	"sigs.k8s.io/kind/cmd/kind/internal/loader"
+	"sigs.k8s.io/kind/cmd/kind/internal/metrics"
 	"sigs.k8s.io/kind/pkg/cluster"
 	"sigs.k8s.io/kind/pkg/cluster/nodes"
-	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
 )
 
 type flagpole struct {
-	Name  string
-	Nodes []string
+	Name    string
+	Nodes   []string
+	Verify  bool
+	Output  string
+	Metrics metrics.Flags
 }
 
 // NewCommand returns a new cobra.Command for loading an image into a cluster
@@ -63,10 +66,29 @@ func NewCommand() *cobra.Command {
 		nil,
 		"comma separated list of nodes to load images into",
 	)
+	cmd.Flags().BoolVar(
+		&flags.Verify,
+		"verify",
+		true,
+		"verify the images are present on each node with the expected ID after loading",
+	)
+	cmd.Flags().StringVarP(
+		&flags.Output,
+		"output",
+		"o",
+		"",
+		"output format for a summary of what was loaded where, one of: json",
+	)
+	metrics.AddFlags(cmd, &flags.Metrics)
 	return cmd
 }
 
-func runE(flags *flagpole, args []string) error {
+func runE(flags *flagpole, args []string) (err error) {
+	recorder := metrics.NewRecorder("load image-archive", flags.Name)
+	defer func() { recorder.Export(&flags.Metrics, err) }()
+	if err := loader.ValidateOutput(flags.Output); err != nil {
+		return err
+	}
 	provider := cluster.NewProvider()
 
 	// Check if file exists
@@ -107,22 +129,17 @@ func runE(flags *flagpole, args []string) error {
 	}
 
 	// Load the image on the selected nodes
-	fns := []func() error{}
-	for _, selectedNode := range selectedNodes {
-		selectedNode := selectedNode // capture loop variable
-		fns = append(fns, func() error {
-			return loadImage(imageTarPath, selectedNode)
-		})
+	var results []loader.Result
+	if err := recorder.Phase("loadimage", func() error {
+		var err error
+		results, err = loader.Load(imageTarPath, selectedNodes, flags.Verify)
+		return err
+	}); err != nil {
+		return err
 	}
-	return errors.UntilErrorConcurrent(fns)
-}
-
-// loads an image tarball onto a node
-func loadImage(imageTarName string, node nodes.Node) error {
-	f, err := os.Open(imageTarName)
-	if err != nil {
-		return errors.Wrap(err, "failed to open image")
+	loader.RecordChange(provider, flags.Name, results)
+	if flags.Output == "json" {
+		return loader.PrintJSON(os.Stdout, results)
 	}
-	defer f.Close()
-	return nodeutils.LoadImageArchive(node, f)
+	return nil
 }
@@ -0,0 +1,78 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package logs implements the `logs` command
+package logs
+
+import (
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+type flagpole struct {
+	Name   string
+	Roles  []string
+	Units  []string
+	Since  string
+	Follow bool
+	Serial bool
+}
+
+// NewCommand returns a new cobra.Command for streaming the node logs
+func NewCommand() *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "logs",
+		Short: "prints the journal of every node, prefixed with the node name",
+		Long: "prints the journal of every node to stdout, each line prefixed with the node name\n\n" +
+			"with --follow new entries are streamed until interrupted, " +
+			"to export the logs and debug files instead see: kind export logs",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runE(flags)
+		},
+	}
+	cmd.Flags().StringVar(&flags.Name, "name", cluster.DefaultName, "the cluster context name")
+	cmd.Flags().StringSliceVar(&flags.Roles, "role", nil, "only print logs from nodes with these roles, including custom roles")
+	cmd.Flags().StringSliceVarP(&flags.Units, "unit", "u", nil, "only print the journal of these systemd units, EG kubelet")
+	cmd.Flags().StringVar(&flags.Since, "since", "", "only print logs written since this RFC3339 timestamp or relative duration, EG 30m")
+	cmd.Flags().BoolVarP(&flags.Follow, "follow", "f", false, "keep streaming new log entries")
+	cmd.Flags().BoolVar(&flags.Serial, "serial", false, "print the node containers' output, as docker logs does, instead of their journal")
+	return cmd
+}
+
+func runE(flags *flagpole) error {
+	since := time.Time{}
+	if flags.Since != "" {
+		if d, err := time.ParseDuration(flags.Since); err == nil {
+			since = time.Now().Add(-d)
+		} else if since, err = time.Parse(time.RFC3339, flags.Since); err != nil {
+			return errors.Wrap(err, "invalid --since")
+		}
+	}
+	return cluster.NewProvider().StreamLogs(flags.Name, os.Stdout,
+		cluster.StreamLogsRoles(flags.Roles...),
+		cluster.StreamLogsUnits(flags.Units...),
+		cluster.StreamLogsSince(since),
+		cluster.StreamLogsFollow(flags.Follow),
+		cluster.StreamLogsSerial(flags.Serial),
+	)
+}
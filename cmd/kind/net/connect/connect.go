@@ -0,0 +1,69 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package connect implements the `connect` command
+package connect
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+)
+
+type flagpole struct {
+	StubDomains bool
+}
+
+// NewCommand returns a new cobra.Command for connecting two clusters
+func NewCommand() *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.ExactArgs(2),
+		Use:   "connect CLUSTER_A CLUSTER_B",
+		Short: "connects the networks of two clusters so their pods can reach each other",
+		Long: "attaches the nodes of each cluster to the other cluster's network and routes " +
+			"each cluster's pod subnets via its nodes, the clusters must have distinct pod subnets\n\n" +
+			"with --stub-domains each cluster's CoreDNS also resolves the other cluster's services " +
+			"under <cluster>.local, which requires distinct service subnets\n\n" +
+			"routes do not survive node restarts, run connect again after restarting nodes",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runE(flags, args[0], args[1])
+		},
+	}
+	cmd.Flags().BoolVar(
+		&flags.StubDomains,
+		"stub-domains",
+		false,
+		"also configure CoreDNS in each cluster to resolve the other cluster's services",
+	)
+	return cmd
+}
+
+func runE(flags *flagpole, a, b string) error {
+	if err := cluster.NewProvider().ConnectClusters(
+		a, b, cluster.ConnectStubDomains(flags.StubDomains),
+	); err != nil {
+		return err
+	}
+	fmt.Printf("Connected clusters %q and %q\n", a, b)
+	if flags.StubDomains {
+		fmt.Printf("Services of %q resolve under %s in %q\n", a, cluster.StubDomain(a), b)
+		fmt.Printf("Services of %q resolve under %s in %q\n", b, cluster.StubDomain(b), a)
+	}
+	return nil
+}
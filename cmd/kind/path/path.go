@@ -0,0 +1,75 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package path implements the `path` command
+package path
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+type flagpole struct {
+	Name string
+}
+
+// NewCommand returns a new cobra.Command for locating the cluster directory
+func NewCommand() *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "path",
+		Short: "prints the directory kind keeps generated files for a cluster in",
+		Long: `prints the directory kind keeps generated files for a cluster in
+
+The directory is removed when the cluster is deleted, except for the status
+of a cluster deleted because it failed to create, and has the layout:
+
+  status.json          machine readable cluster status
+  kubeconfig           copy of the generated kubeconfig
+  kubeadm/<node>.conf  kubeadm config generated for each node
+  manifests/           manifests kind applied to the cluster
+  artifacts/           files exported from the cluster
+  tmp/                 scratch space for in progress operations`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runE(flags)
+		},
+	}
+	cmd.Flags().StringVar(
+		&flags.Name,
+		"name",
+		cluster.DefaultName,
+		"the cluster context name",
+	)
+	return cmd
+}
+
+func runE(flags *flagpole) error {
+	dir := cluster.NewProvider().Path(flags.Name)
+	if _, err := os.Stat(dir); err != nil {
+		if os.IsNotExist(err) {
+			return errors.Errorf("no directory found for cluster %q", flags.Name)
+		}
+		return err
+	}
+	fmt.Println(dir)
+	return nil
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package proxy implements the `proxy` command
+package proxy
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/globals"
+)
+
+type flagpole struct {
+	Name             string
+	Address          string
+	ReconnectTimeout time.Duration
+}
+
+// NewCommand returns a new cobra.Command for proxying the API server
+func NewCommand() *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "proxy",
+		Short: "serves a stable host address for the cluster API server",
+		Long: "serves a TCP proxy on the host to the cluster API server and points the kubeconfig at it until interrupted.\n\n" +
+			"the published API server port changes when the nodes are started again, EG after Docker Desktop on macOS " +
+			"or Windows restarts, the proxy address does not: the proxy finds the new port whenever the API server is " +
+			"unreachable, holding connections until it is reachable again",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runE(flags)
+		},
+	}
+	cmd.Flags().StringVar(
+		&flags.Name,
+		"name",
+		cluster.DefaultName,
+		"the cluster context name",
+	)
+	cmd.Flags().StringVar(
+		&flags.Address,
+		"address",
+		"127.0.0.1",
+		"host or host:port to listen on, without a port the last used port is reused if free",
+	)
+	cmd.Flags().DurationVar(
+		&flags.ReconnectTimeout,
+		"reconnect-timeout",
+		2*time.Minute,
+		"how long connections wait for an unreachable API server",
+	)
+	return cmd
+}
+
+func runE(flags *flagpole) error {
+	// serve until interrupted, then point the kubeconfig back at the API
+	// server
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	go func() {
+		select {
+		case <-signals:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	provider := cluster.NewProvider()
+	return provider.ProxyAPIServer(ctx, flags.Name,
+		cluster.ProxyAPIServerAddress(flags.Address),
+		cluster.ProxyAPIServerReconnectTimeout(flags.ReconnectTimeout),
+		cluster.ProxyAPIServerStarted(func(address string) {
+			globals.GetLogger().V(0).Infof("Proxying the API server of cluster %q on %s, kubeconfig %s points at it", flags.Name, address, provider.KubeConfigPath(flags.Name))
+		}),
+	)
+}
@@ -0,0 +1,36 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package restore implements the `restore` command
+package restore
+
+import (
+	"github.com/spf13/cobra"
+
+	restorenode "sigs.k8s.io/kind/cmd/kind/restore/node"
+)
+
+// NewCommand returns a new cobra.Command for restoring checkpoints
+func NewCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "restore",
+		Short: "Restores one of [node] from a checkpoint (experimental)",
+		Long:  "Restores one of [node] from a checkpoint (experimental)",
+	}
+	cmd.AddCommand(restorenode.NewCommand())
+	return cmd
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package serve implements the `serve` command
+package serve
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/globals"
+)
+
+// DefaultAddress is the address kind serve listens on by default
+const DefaultAddress = "127.0.0.1:8743"
+
+// TokenEnv may be used to set the API token instead of --token
+const TokenEnv = "KIND_SERVE_TOKEN"
+
+// the response to creating a cluster is only written once it is created,
+// which takes minutes
+const (
+	readTimeout  = time.Minute
+	writeTimeout = 30 * time.Minute
+	idleTimeout  = 2 * time.Minute
+)
+
+type flagpole struct {
+	Address string
+	Token   string
+	DataDir string
+}
+
+// NewCommand returns a new cobra.Command for serving the kind API
+func NewCommand() *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "serve",
+		Short: "Serves cluster management over a local REST API",
+		Long: "Serves creating, listing and deleting clusters, loading images and exporting logs " +
+			"over a local REST API, authenticated with a bearer token.\n\n" +
+			"The token is read from --token or " + TokenEnv + ", if neither is set one is generated and logged.\n\n" +
+			"Image archives and log directories given by clients are relative to --data-dir, " +
+			"they are rejected if it is not set.\n\n" +
+			"Only the REST API is served, there is no gRPC API.\n\n" +
+			"Endpoints:\n" +
+			"  GET    /v1/clusters\n" +
+			"  POST   /v1/clusters                    {\"name\", \"config\", \"image\", \"wait\", \"retain\"}\n" +
+			"  DELETE /v1/clusters/<name>[?force=true]\n" +
+			"  GET    /v1/clusters/<name>/nodes\n" +
+			"  GET    /v1/clusters/<name>/kubeconfig[?internal=true]\n" +
+			"  POST   /v1/clusters/<name>/images      {\"image\" or \"archive\", \"nodes\", \"verify\"}\n" +
+			"  POST   /v1/clusters/<name>/logs        {\"dir\"}",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runE(flags)
+		},
+	}
+	cmd.Flags().StringVar(
+		&flags.Address,
+		"address",
+		DefaultAddress,
+		"the address to listen on",
+	)
+	cmd.Flags().StringVar(
+		&flags.Token,
+		"token",
+		"",
+		"the bearer token clients must authenticate with",
+	)
+	cmd.Flags().StringVar(
+		&flags.DataDir,
+		"data-dir",
+		"",
+		"the directory image archives and log directories given by clients are relative to",
+	)
+	return cmd
+}
+
+func runE(flags *flagpole) error {
+	token := flags.Token
+	if token == "" {
+		token = os.Getenv(TokenEnv)
+	}
+	if token == "" {
+		generated, err := generateToken()
+		if err != nil {
+			return err
+		}
+		token = generated
+		globals.GetLogger().V(0).Infof("Generated API token: %s", token)
+	}
+	globals.GetLogger().V(0).Infof("Serving the kind API on http://%s", flags.Address)
+	server := &http.Server{
+		Addr:              flags.Address,
+		Handler:           newServer(cluster.NewProvider(), token, flags.DataDir),
+		ReadHeaderTimeout: readTimeout,
+		ReadTimeout:       readTimeout,
+		WriteTimeout:      writeTimeout,
+		IdleTimeout:       idleTimeout,
+	}
+	return server.ListenAndServe()
+}
+
+func generateToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.Wrap(err, "failed to generate token")
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,456 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package serve
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/kind/cmd/kind/internal/loader"
+	"sigs.k8s.io/kind/pkg/apis/config/v1alpha3"
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/cluster/create"
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/globals"
+)
+
+// provider is the part of *cluster.Provider the server uses
+type provider interface {
+	loader.ChangeRecorder
+	List() ([]string, error)
+	ListNodes(name string) ([]nodes.Node, error)
+	ListInternalNodes(name string) ([]nodes.Node, error)
+	Create(name string, options ...create.ClusterOption) error
+	Delete(name string, options ...cluster.DeleteOption) error
+	KubeConfig(name string, internal bool) (string, error)
+	KubeConfigPath(name string) string
+	TempDir(name, prefix string) (string, error)
+	ArtifactDir(name, prefix string) (string, error)
+	CollectLogs(name, dir string, options ...cluster.CollectLogsOption) error
+}
+
+// server implements the kind REST API
+type server struct {
+	provider provider
+	token    string
+	// dataDir is the host directory client paths are relative to, clients
+	// may not pass host paths if it is empty
+	dataDir string
+	// load loads an image archive into nodes, see loader.Load
+	load func(archive string, nodeList []nodes.Node, verify bool) ([]loader.Result, error)
+
+	mu sync.Mutex
+	// locks serialize creating and deleting each cluster, by name
+	locks map[string]*sync.Mutex
+}
+
+func newServer(provider provider, token, dataDir string) *server {
+	return &server{
+		provider: provider,
+		token:    token,
+		dataDir:  dataDir,
+		load:     loader.Load,
+		locks:    map[string]*sync.Mutex{},
+	}
+}
+
+// createRequest is the body of POST /v1/clusters
+type createRequest struct {
+	Name   string            `json:"name"`
+	Config *v1alpha3.Cluster `json:"config,omitempty"`
+	Image  string            `json:"image,omitempty"`
+	Wait   string            `json:"wait,omitempty"`
+	Retain bool              `json:"retain,omitempty"`
+}
+
+// loadRequest is the body of POST /v1/clusters/<name>/images
+type loadRequest struct {
+	// Image is the name of an image on the host to load
+	Image string `json:"image,omitempty"`
+	// Archive is the path to an image archive to load, relative to the
+	// data dir of the server
+	Archive string `json:"archive,omitempty"`
+	// Nodes limits loading to the named nodes, the default is all nodes
+	Nodes  []string `json:"nodes,omitempty"`
+	Verify bool     `json:"verify,omitempty"`
+}
+
+// logsRequest is the body of POST /v1/clusters/<name>/logs
+type logsRequest struct {
+	// Dir is where logs are exported to, relative to the data dir of the
+	// server, a directory in the cluster directory by default
+	Dir string `json:"dir,omitempty"`
+}
+
+// ServeHTTP authenticates the request, then routes it by path
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !s.authenticated(r) {
+		writeError(w, http.StatusUnauthorized, errors.New("missing or invalid bearer token"))
+		return
+	}
+	globals.GetLogger().V(1).Infof("%s %s", r.Method, r.URL.Path)
+
+	// paths are /v1/clusters[/<name>[/<resource>]]
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) < 2 || len(parts) > 4 || parts[0] != "v1" || parts[1] != "clusters" {
+		writeError(w, http.StatusNotFound, errors.Errorf("unknown path %s", r.URL.Path))
+		return
+	}
+	switch len(parts) {
+	case 2:
+		s.route(w, r, map[string]http.HandlerFunc{
+			http.MethodGet:  s.listClusters,
+			http.MethodPost: s.createCluster,
+		})
+	case 3:
+		s.route(w, r, map[string]http.HandlerFunc{
+			http.MethodDelete: func(w http.ResponseWriter, r *http.Request) {
+				s.deleteCluster(w, r, parts[2])
+			},
+		})
+	case 4:
+		name := parts[2]
+		switch parts[3] {
+		case "nodes":
+			s.route(w, r, map[string]http.HandlerFunc{
+				http.MethodGet: func(w http.ResponseWriter, r *http.Request) {
+					s.listNodes(w, r, name)
+				},
+			})
+		case "kubeconfig":
+			s.route(w, r, map[string]http.HandlerFunc{
+				http.MethodGet: func(w http.ResponseWriter, r *http.Request) {
+					s.kubeConfig(w, r, name)
+				},
+			})
+		case "images":
+			s.route(w, r, map[string]http.HandlerFunc{
+				http.MethodPost: func(w http.ResponseWriter, r *http.Request) {
+					s.loadImage(w, r, name)
+				},
+			})
+		case "logs":
+			s.route(w, r, map[string]http.HandlerFunc{
+				http.MethodPost: func(w http.ResponseWriter, r *http.Request) {
+					s.exportLogs(w, r, name)
+				},
+			})
+		default:
+			writeError(w, http.StatusNotFound, errors.Errorf("unknown path %s", r.URL.Path))
+		}
+	}
+}
+
+// authenticated returns true if r carries the expected bearer token
+func (s *server) authenticated(r *http.Request) bool {
+	const prefix = "Bearer "
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, prefix) {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(header[len(prefix):]), []byte(s.token)) == 1
+}
+
+// route calls the handler for the request method, if there is one
+func (s *server) route(w http.ResponseWriter, r *http.Request, handlers map[string]http.HandlerFunc) {
+	handler, ok := handlers[r.Method]
+	if !ok {
+		writeError(w, http.StatusMethodNotAllowed, errors.Errorf("method %s not allowed for %s", r.Method, r.URL.Path))
+		return
+	}
+	handler(w, r)
+}
+
+func (s *server) listClusters(w http.ResponseWriter, r *http.Request) {
+	clusters, err := s.provider.List()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string][]string{"clusters": clusters})
+}
+
+func (s *server) createCluster(w http.ResponseWriter, r *http.Request) {
+	req := createRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, errors.Wrap(err, "invalid request body"))
+		return
+	}
+	if req.Name == "" {
+		req.Name = cluster.DefaultName
+	}
+	options := []create.ClusterOption{
+		create.WithNodeImage(req.Image),
+		create.Retain(req.Retain),
+	}
+	if req.Config != nil {
+		options = append(options, create.WithV1Alpha3(req.Config))
+	}
+	if req.Wait != "" {
+		wait, err := time.ParseDuration(req.Wait)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, errors.Wrap(err, "invalid wait"))
+			return
+		}
+		options = append(options, create.WaitForReady(wait))
+	}
+
+	// the check for an existing cluster only holds while creating it
+	unlock := s.lock(req.Name)
+	defer unlock()
+	existing, err := s.provider.ListNodes(req.Name)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if len(existing) != 0 {
+		writeError(w, http.StatusConflict, errors.Errorf("node(s) already exist for a cluster with the name %q", req.Name))
+		return
+	}
+
+	// this blocks until the cluster is created, which may take minutes
+	if err := s.provider.Create(req.Name, options...); err != nil {
+		writeError(w, http.StatusInternalServerError, errors.Wrap(err, "failed to create cluster"))
+		return
+	}
+	writeJSON(w, http.StatusCreated, map[string]string{
+		"name":           req.Name,
+		"kubeconfigPath": s.provider.KubeConfigPath(req.Name),
+	})
+}
+
+func (s *server) deleteCluster(w http.ResponseWriter, r *http.Request, name string) {
+	force := r.URL.Query().Get("force") == "true"
+	unlock := s.lock(name)
+	defer unlock()
+	if err := s.provider.Delete(name, cluster.ForceDelete(force)); err != nil {
+		writeError(w, http.StatusInternalServerError, errors.Wrap(err, "failed to delete cluster"))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *server) listNodes(w http.ResponseWriter, r *http.Request, name string) {
+	nodeList, err := s.nodes(name)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	names := []string{}
+	for _, n := range nodeList {
+		names = append(names, n.String())
+	}
+	writeJSON(w, http.StatusOK, map[string][]string{"nodes": names})
+}
+
+func (s *server) kubeConfig(w http.ResponseWriter, r *http.Request, name string) {
+	internal := r.URL.Query().Get("internal") == "true"
+	kubeConfig, err := s.provider.KubeConfig(name, internal)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/yaml")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(kubeConfig))
+}
+
+func (s *server) loadImage(w http.ResponseWriter, r *http.Request, name string) {
+	req := loadRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, errors.Wrap(err, "invalid request body"))
+		return
+	}
+	if (req.Image == "") == (req.Archive == "") {
+		writeError(w, http.StatusBadRequest, errors.New("exactly one of image or archive must be set"))
+		return
+	}
+
+	nodeList, err := s.selectNodes(name, req.Nodes)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+
+	archive := req.Archive
+	if archive != "" {
+		if archive, err = s.hostPath(archive); err != nil {
+			writeError(w, http.StatusForbidden, err)
+			return
+		}
+	} else {
+		dir, err := s.provider.TempDir(name, "image-tar")
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, errors.Wrap(err, "failed to create tempdir"))
+			return
+		}
+		defer os.RemoveAll(dir)
+		archive = filepath.Join(dir, "image.tar")
+		if err := exec.Command("docker", "save", "-o", archive, req.Image).Run(); err != nil {
+			writeError(w, http.StatusBadRequest, errors.Wrapf(err, "failed to save image %q", req.Image))
+			return
+		}
+	}
+
+	results, err := s.load(archive, nodeList, req.Verify)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	loader.RecordChange(s.provider, name, results)
+	writeJSON(w, http.StatusOK, results)
+}
+
+func (s *server) exportLogs(w http.ResponseWriter, r *http.Request, name string) {
+	req := logsRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, errors.Wrap(err, "invalid request body"))
+		return
+	}
+	if _, err := s.nodes(name); err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	dir := ""
+	if req.Dir != "" {
+		var err error
+		if dir, err = s.hostPath(req.Dir); err != nil {
+			writeError(w, http.StatusForbidden, err)
+			return
+		}
+	} else {
+		var err error
+		if dir, err = s.provider.ArtifactDir(name, "logs-"); err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+	if err := s.provider.CollectLogs(name, dir); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"dir": dir})
+}
+
+// lock locks the cluster name against being created or deleted
+// concurrently, returning the func unlocking it
+func (s *server) lock(name string) (unlock func()) {
+	s.mu.Lock()
+	l, ok := s.locks[name]
+	if !ok {
+		l = &sync.Mutex{}
+		s.locks[name] = l
+	}
+	s.mu.Unlock()
+	l.Lock()
+	return l.Unlock
+}
+
+// hostPath returns the host path of the client path p, which must be
+// relative to the data dir and may not leave it, also through symlinks
+func (s *server) hostPath(p string) (string, error) {
+	if s.dataDir == "" {
+		return "", errors.Errorf("path %q is not allowed, the server has no --data-dir", p)
+	}
+	clean := filepath.Clean(p)
+	if filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", errors.Errorf("path %q must be relative to the data dir", p)
+	}
+	root, err := filepath.EvalSymlinks(s.dataDir)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to resolve the data dir")
+	}
+	// resolve the part of the path that exists, the rest is created
+	// beneath it
+	resolved, rest := filepath.Join(root, clean), ""
+	for {
+		real, err := filepath.EvalSymlinks(resolved)
+		if err == nil {
+			resolved = filepath.Join(real, rest)
+			break
+		}
+		if !os.IsNotExist(err) || resolved == root {
+			return "", errors.Wrapf(err, "failed to resolve path %q", p)
+		}
+		rest = filepath.Join(filepath.Base(resolved), rest)
+		resolved = filepath.Dir(resolved)
+	}
+	if resolved != root && !strings.HasPrefix(resolved, root+string(filepath.Separator)) {
+		return "", errors.Errorf("path %q leaves the data dir", p)
+	}
+	return resolved, nil
+}
+
+// nodes returns the nodes of the named cluster, or an error if it has none
+func (s *server) nodes(name string) ([]nodes.Node, error) {
+	nodeList, err := s.provider.ListNodes(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(nodeList) == 0 {
+		return nil, errors.Errorf("no nodes found for cluster %q", name)
+	}
+	return nodeList, nil
+}
+
+// selectNodes returns the named internal nodes of the cluster, or all of
+// them if names is empty
+func (s *server) selectNodes(name string, names []string) ([]nodes.Node, error) {
+	nodeList, err := s.provider.ListInternalNodes(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(nodeList) == 0 {
+		return nil, errors.Errorf("no nodes found for cluster %q", name)
+	}
+	if len(names) == 0 {
+		return nodeList, nil
+	}
+	nodesByName := map[string]nodes.Node{}
+	for _, n := range nodeList {
+		nodesByName[n.String()] = n
+	}
+	selected := []nodes.Node{}
+	for _, n := range names {
+		node, ok := nodesByName[n]
+		if !ok {
+			return nil, errors.Errorf("unknown node: %q", n)
+		}
+		selected = append(selected, node)
+	}
+	return selected, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		globals.GetLogger().Errorf("failed to write response: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
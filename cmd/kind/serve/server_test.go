@@ -0,0 +1,396 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package serve
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/kind/cmd/kind/internal/loader"
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/cluster/create"
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+func TestServerRejectsRequests(t *testing.T) {
+	cases := []struct {
+		Name           string
+		Method         string
+		Path           string
+		Authorization  string
+		ExpectedStatus int
+	}{
+		{
+			Name:           "missing token",
+			Method:         http.MethodGet,
+			Path:           "/v1/clusters",
+			ExpectedStatus: http.StatusUnauthorized,
+		},
+		{
+			Name:           "wrong token",
+			Method:         http.MethodGet,
+			Path:           "/v1/clusters",
+			Authorization:  "Bearer wrong",
+			ExpectedStatus: http.StatusUnauthorized,
+		},
+		{
+			Name:           "not a bearer token",
+			Method:         http.MethodGet,
+			Path:           "/v1/clusters",
+			Authorization:  "secret",
+			ExpectedStatus: http.StatusUnauthorized,
+		},
+		{
+			Name:           "unknown path",
+			Method:         http.MethodGet,
+			Path:           "/v1/widgets",
+			Authorization:  "Bearer secret",
+			ExpectedStatus: http.StatusNotFound,
+		},
+		{
+			Name:           "unknown cluster resource",
+			Method:         http.MethodGet,
+			Path:           "/v1/clusters/kind/widgets",
+			Authorization:  "Bearer secret",
+			ExpectedStatus: http.StatusNotFound,
+		},
+		{
+			Name:           "method not allowed",
+			Method:         http.MethodPut,
+			Path:           "/v1/clusters/kind",
+			Authorization:  "Bearer secret",
+			ExpectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+	s := newServer(nil, "secret", "")
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			r := httptest.NewRequest(tc.Method, tc.Path, nil)
+			if tc.Authorization != "" {
+				r.Header.Set("Authorization", tc.Authorization)
+			}
+			w := httptest.NewRecorder()
+			s.ServeHTTP(w, r)
+			if w.Code != tc.ExpectedStatus {
+				t.Errorf("%s %s = %d, expected %d", tc.Method, tc.Path, w.Code, tc.ExpectedStatus)
+			}
+		})
+	}
+}
+
+// fakeProvider manages clusters of a single node in memory
+type fakeProvider struct {
+	mu       sync.Mutex
+	clusters map[string]bool
+	// creates counts calls to Create
+	creates int
+	// logsDir is the dir logs were last collected to
+	logsDir string
+}
+
+func newFakeProvider(clusters ...string) *fakeProvider {
+	p := &fakeProvider{clusters: map[string]bool{}}
+	for _, name := range clusters {
+		p.clusters[name] = true
+	}
+	return p
+}
+
+type fakeNode struct {
+	nodes.Node
+	name string
+}
+
+func (n *fakeNode) String() string { return n.name }
+
+func (p *fakeProvider) List() ([]string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	names := []string{}
+	for name := range p.clusters {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func (p *fakeProvider) ListNodes(name string) ([]nodes.Node, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.clusters[name] {
+		return nil, nil
+	}
+	return []nodes.Node{&fakeNode{name: name + "-control-plane"}}, nil
+}
+
+func (p *fakeProvider) ListInternalNodes(name string) ([]nodes.Node, error) {
+	return p.ListNodes(name)
+}
+
+func (p *fakeProvider) Create(name string, options ...create.ClusterOption) error {
+	// leave time for a concurrent request to observe the cluster missing
+	time.Sleep(10 * time.Millisecond)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.creates++
+	p.clusters[name] = true
+	return nil
+}
+
+func (p *fakeProvider) Delete(name string, options ...cluster.DeleteOption) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.clusters[name] {
+		return errors.Errorf("unknown cluster %q", name)
+	}
+	delete(p.clusters, name)
+	return nil
+}
+
+func (p *fakeProvider) KubeConfig(name string, internal bool) (string, error) {
+	return "", nil
+}
+
+func (p *fakeProvider) KubeConfigPath(name string) string {
+	return "/kubeconfig-" + name
+}
+
+func (p *fakeProvider) TempDir(name, prefix string) (string, error) {
+	return ioutil.TempDir("", prefix)
+}
+
+func (p *fakeProvider) ArtifactDir(name, prefix string) (string, error) {
+	return "/artifacts/" + prefix, nil
+}
+
+func (p *fakeProvider) CollectLogs(name, dir string, options ...cluster.CollectLogsOption) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.logsDir = dir
+	return nil
+}
+
+func (p *fakeProvider) RecordChange(name string, change cluster.Change) {}
+
+// do serves an authenticated request with body
+func do(s *server, method, path, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	r.Header.Set("Authorization", "Bearer secret")
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+	return w
+}
+
+func TestCreateCluster(t *testing.T) {
+	t.Parallel()
+	p := newFakeProvider()
+	s := newServer(p, "secret", "")
+	// only one of concurrent requests for the same name creates it
+	codes := make(chan int, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			codes <- do(s, http.MethodPost, "/v1/clusters", `{"name": "kind"}`).Code
+		}()
+	}
+	created, conflicts := 0, 0
+	for i := 0; i < 2; i++ {
+		switch code := <-codes; code {
+		case http.StatusCreated:
+			created++
+		case http.StatusConflict:
+			conflicts++
+		default:
+			t.Errorf("POST /v1/clusters = %d, expected %d or %d", code, http.StatusCreated, http.StatusConflict)
+		}
+	}
+	if created != 1 || conflicts != 1 || p.creates != 1 {
+		t.Errorf("expected one cluster created and one conflict, got %d created, %d conflicts, %d calls", created, conflicts, p.creates)
+	}
+	if w := do(s, http.MethodPost, "/v1/clusters", `{"name": "other", "wait": "soon"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("POST /v1/clusters with an invalid wait = %d, expected %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestDeleteCluster(t *testing.T) {
+	t.Parallel()
+	p := newFakeProvider("kind")
+	s := newServer(p, "secret", "")
+	if w := do(s, http.MethodDelete, "/v1/clusters/kind", ""); w.Code != http.StatusNoContent {
+		t.Errorf("DELETE /v1/clusters/kind = %d, expected %d", w.Code, http.StatusNoContent)
+	}
+	if names, _ := p.List(); len(names) != 0 {
+		t.Errorf("expected the cluster to be deleted, got %v", names)
+	}
+	if w := do(s, http.MethodDelete, "/v1/clusters/kind", ""); w.Code != http.StatusInternalServerError {
+		t.Errorf("DELETE /v1/clusters/kind again = %d, expected %d", w.Code, http.StatusInternalServerError)
+	}
+}
+
+func TestLoadImage(t *testing.T) {
+	t.Parallel()
+	dataDir, err := ioutil.TempDir("", "kind-serve")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dataDir)
+	outside, err := ioutil.TempDir("", "kind-serve-outside")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(outside)
+	if err := os.Symlink(outside, filepath.Join(dataDir, "link")); err != nil {
+		t.Fatal(err)
+	}
+	realDataDir, err := filepath.EvalSymlinks(dataDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		Name            string
+		DataDir         string
+		Body            string
+		ExpectedStatus  int
+		ExpectedArchive string
+	}{
+		{
+			Name:            "archive in the data dir",
+			DataDir:         dataDir,
+			Body:            `{"archive": "images/app.tar"}`,
+			ExpectedStatus:  http.StatusOK,
+			ExpectedArchive: filepath.Join(realDataDir, "images", "app.tar"),
+		},
+		{
+			Name:           "absolute archive",
+			DataDir:        dataDir,
+			Body:           `{"archive": "/etc/shadow"}`,
+			ExpectedStatus: http.StatusForbidden,
+		},
+		{
+			Name:           "archive outside the data dir",
+			DataDir:        dataDir,
+			Body:           `{"archive": "../app.tar"}`,
+			ExpectedStatus: http.StatusForbidden,
+		},
+		{
+			Name:           "archive through a symlink",
+			DataDir:        dataDir,
+			Body:           `{"archive": "link/app.tar"}`,
+			ExpectedStatus: http.StatusForbidden,
+		},
+		{
+			Name:           "archive without a data dir",
+			Body:           `{"archive": "app.tar"}`,
+			ExpectedStatus: http.StatusForbidden,
+		},
+		{
+			Name:           "image and archive",
+			DataDir:        dataDir,
+			Body:           `{"image": "app", "archive": "app.tar"}`,
+			ExpectedStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			s := newServer(newFakeProvider("kind"), "secret", tc.DataDir)
+			loaded := ""
+			s.load = func(archive string, nodeList []nodes.Node, verify bool) ([]loader.Result, error) {
+				loaded = archive
+				return []loader.Result{{Node: nodeList[0].String()}}, nil
+			}
+			w := do(s, http.MethodPost, "/v1/clusters/kind/images", tc.Body)
+			if w.Code != tc.ExpectedStatus {
+				t.Fatalf("POST /v1/clusters/kind/images = %d %s, expected %d", w.Code, w.Body, tc.ExpectedStatus)
+			}
+			if loaded != tc.ExpectedArchive {
+				t.Errorf("loaded %q, expected %q", loaded, tc.ExpectedArchive)
+			}
+		})
+	}
+}
+
+func TestExportLogs(t *testing.T) {
+	t.Parallel()
+	dataDir, err := ioutil.TempDir("", "kind-serve")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dataDir)
+	realDataDir, err := filepath.EvalSymlinks(dataDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		Name           string
+		Cluster        string
+		Body           string
+		ExpectedStatus int
+		ExpectedDir    string
+	}{
+		{
+			Name:           "default dir",
+			Cluster:        "kind",
+			Body:           `{}`,
+			ExpectedStatus: http.StatusOK,
+			ExpectedDir:    "/artifacts/logs-",
+		},
+		{
+			Name:           "dir in the data dir",
+			Cluster:        "kind",
+			Body:           `{"dir": "logs/run-1"}`,
+			ExpectedStatus: http.StatusOK,
+			ExpectedDir:    filepath.Join(realDataDir, "logs", "run-1"),
+		},
+		{
+			Name:           "dir outside the data dir",
+			Cluster:        "kind",
+			Body:           `{"dir": "/tmp/logs"}`,
+			ExpectedStatus: http.StatusForbidden,
+		},
+		{
+			Name:           "unknown cluster",
+			Cluster:        "other",
+			Body:           `{}`,
+			ExpectedStatus: http.StatusNotFound,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			p := newFakeProvider("kind")
+			s := newServer(p, "secret", dataDir)
+			w := do(s, http.MethodPost, "/v1/clusters/"+tc.Cluster+"/logs", tc.Body)
+			if w.Code != tc.ExpectedStatus {
+				t.Fatalf("POST /v1/clusters/%s/logs = %d %s, expected %d", tc.Cluster, w.Code, w.Body, tc.ExpectedStatus)
+			}
+			if p.logsDir != tc.ExpectedDir {
+				t.Errorf("collected logs to %q, expected %q", p.logsDir, tc.ExpectedDir)
+			}
+		})
+	}
+}
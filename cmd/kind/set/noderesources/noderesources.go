@@ -0,0 +1,87 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package noderesources implements the `node-resources` command
+package noderesources
+
+import (
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+type flagpole struct {
+	Name   string
+	Node   string
+	CPUs   float64
+	Memory string
+}
+
+// NewCommand returns a new cobra.Command for changing node resources
+func NewCommand() *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "node-resources",
+		Short: "changes the CPU and memory limits of a running node",
+		Long: "changes the CPU and memory limits of a running node container, " +
+			"reserving the rest of the host capacity for the system in the kubelet so that " +
+			"the node's allocatable resources match, the kubelet is restarted if needed",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runE(flags)
+		},
+	}
+	cmd.Flags().StringVar(
+		&flags.Name,
+		"name",
+		cluster.DefaultName,
+		"the cluster context name",
+	)
+	cmd.Flags().StringVar(
+		&flags.Node,
+		"node",
+		"",
+		"the node to change, the cluster name prefix may be omitted, EG worker2",
+	)
+	cmd.Flags().Float64Var(
+		&flags.CPUs,
+		"cpus",
+		0,
+		"number of CPUs the node may use, may be fractional, EG 1.5",
+	)
+	cmd.Flags().StringVar(
+		&flags.Memory,
+		"memory",
+		"",
+		"memory the node may use, EG 8Gi",
+	)
+	return cmd
+}
+
+func runE(flags *flagpole) error {
+	if flags.Node == "" {
+		return errors.New("--node must be set")
+	}
+	options := []cluster.NodeResourcesOption{}
+	if flags.CPUs != 0 {
+		options = append(options, cluster.NodeCPUs(flags.CPUs))
+	}
+	if flags.Memory != "" {
+		options = append(options, cluster.NodeMemory(flags.Memory))
+	}
+	return cluster.NewProvider().SetNodeResources(flags.Name, flags.Node, options...)
+}
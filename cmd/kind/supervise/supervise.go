@@ -0,0 +1,112 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package supervise implements the `supervise` command
+package supervise
+
+import (
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/globals"
+)
+
+type flagpole struct {
+	Name     string
+	Interval time.Duration
+	Once     bool
+}
+
+// NewCommand returns a new cobra.Command for supervising a cluster
+func NewCommand() *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "supervise",
+		Short: "restarts exited nodes and repairs the cluster after node restarts",
+		Long: "periodically starts any exited node containers, then updates the kubelet to changed node IPs, " +
+			"the load balancer to the current control plane IPs and the kubeconfig to the current API server endpoint.\n\n" +
+			"runs until interrupted unless --once is set",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runE(flags)
+		},
+	}
+	cmd.Flags().StringVar(
+		&flags.Name,
+		"name",
+		cluster.DefaultName,
+		"the cluster context name",
+	)
+	cmd.Flags().DurationVar(
+		&flags.Interval,
+		"interval",
+		30*time.Second,
+		"how often to check the cluster",
+	)
+	cmd.Flags().BoolVar(
+		&flags.Once,
+		"once",
+		false,
+		"check and repair the cluster once, then exit",
+	)
+	return cmd
+}
+
+func runE(flags *flagpole) error {
+	provider := cluster.NewProvider()
+	if flags.Once {
+		return heal(provider, flags.Name)
+	}
+	globals.GetLogger().V(0).Infof("Supervising cluster %q every %s", flags.Name, flags.Interval)
+	for {
+		// transient failures should not stop supervision
+		if err := heal(provider, flags.Name); err != nil {
+			globals.GetLogger().Errorf("failed to heal cluster %q: %v", flags.Name, err)
+		}
+		time.Sleep(flags.Interval)
+	}
+}
+
+// heal runs a single heal pass, logging any repairs made
+func heal(provider *cluster.Provider, name string) error {
+	report, err := provider.Heal(name)
+	logger := globals.GetLogger()
+	if report != nil && len(report.NeedRecreation) > 0 {
+		logger.Warnf(
+			"Control plane nodes %s have a new IP their certificates and static pods do not use, the cluster must be recreated",
+			strings.Join(report.NeedRecreation, ", "),
+		)
+	}
+	if report == nil || !report.Healed() {
+		return err
+	}
+	if len(report.Restarted) > 0 {
+		logger.V(0).Infof("Started exited nodes: %s", strings.Join(report.Restarted, ", "))
+	}
+	if len(report.Reconciled) > 0 {
+		logger.V(0).Infof("Updated kubelet node IP on: %s", strings.Join(report.Reconciled, ", "))
+	}
+	if report.LoadBalancerReconfigured {
+		logger.V(0).Info("Updated load balancer to the current control plane IPs")
+	}
+	if report.KubeConfigRefreshed {
+		logger.V(0).Infof("Updated kubeconfig %s to the current API server endpoint", provider.KubeConfigPath(name))
+	}
+	return err
+}
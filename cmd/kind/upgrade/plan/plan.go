@@ -0,0 +1,73 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package plan implements the `plan` command
+package plan
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+type flagpole struct {
+	Name  string
+	Image string
+}
+
+// NewCommand returns a new cobra.Command for planning cluster upgrades
+func NewCommand() *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "plan",
+		Short: "reports what upgrading the cluster to a node image would do",
+		Long: "stages the kubeadm binary of the new node image on a control plane node " +
+			"and runs kubeadm upgrade plan with it, the cluster is not changed",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runE(flags)
+		},
+	}
+	cmd.Flags().StringVar(
+		&flags.Name,
+		"name",
+		cluster.DefaultName,
+		"the cluster context name",
+	)
+	cmd.Flags().StringVar(
+		&flags.Image,
+		"image",
+		"",
+		"the node image to plan the upgrade to",
+	)
+	return cmd
+}
+
+func runE(flags *flagpole) error {
+	if flags.Image == "" {
+		return errors.New("--image must be set")
+	}
+	plan, err := cluster.NewProvider().PlanUpgrade(flags.Name, flags.Image)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Upgrade plan for cluster %q to %s (Kubernetes %s):\n\n", flags.Name, plan.Image, plan.Version)
+	fmt.Print(plan.Output)
+	return nil
+}
@@ -0,0 +1,38 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package defaults
+
+import (
+	"strings"
+)
+
+// DebugImageSuffix is appended to a node image tag to name its variant
+// with debugging tools preinstalled, see: kind build node-image --debug-tools
+const DebugImageSuffix = "-debug"
+
+// DebugImage returns the name of the debug variant of the node image,
+// EG kindest/node:v1.16.2-debug for kindest/node:v1.16.2
+// Any digest is dropped, as it identifies the image without tools
+func DebugImage(image string) string {
+	image = strings.Split(image, "@")[0]
+	// a colon after the last slash separates the tag, otherwise it is part
+	// of a registry host:port
+	if strings.LastIndex(image, ":") <= strings.LastIndex(image, "/") {
+		image += ":latest"
+	}
+	return image + DebugImageSuffix
+}
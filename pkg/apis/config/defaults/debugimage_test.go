@@ -0,0 +1,59 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package defaults
+
+import (
+	"testing"
+)
+
+func TestDebugImage(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Image    string
+		Expected string
+	}{
+		{
+			Name:     "tagged",
+			Image:    "kindest/node:v1.16.2",
+			Expected: "kindest/node:v1.16.2-debug",
+		},
+		{
+			Name:     "untagged",
+			Image:    "kindest/node",
+			Expected: "kindest/node:latest-debug",
+		},
+		{
+			Name:     "digest",
+			Image:    Image,
+			Expected: "kindest/node:v1.16.2-debug",
+		},
+		{
+			Name:     "registry port",
+			Image:    "localhost:5000/node",
+			Expected: "localhost:5000/node:latest-debug",
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			if result := DebugImage(tc.Image); result != tc.Expected {
+				t.Errorf("DebugImage(%q) = %q, expected %q", tc.Image, result, tc.Expected)
+			}
+		})
+	}
+}
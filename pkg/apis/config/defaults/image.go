@@ -19,3 +19,7 @@ package defaults
 
 // Image is the default for the Config.Image field, aka the default node image.
 const Image = "kindest/node:v1.16.2@sha256:490e066d9b30a50584aa77c20e67edcceb2d97276112200b223dd8a3d718c973"
+
+// RegistryImage is the default for the Config.Image field of registry role
+// nodes, see RegistryRole
+const RegistryImage = "registry:2"
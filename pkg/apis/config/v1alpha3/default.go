@@ -36,6 +36,9 @@ func SetDefaultsCluster(obj *Cluster) {
 		a := &obj.Nodes[i]
 		SetDefaultsNode(a)
 	}
+	if obj.ContainerRuntime == "" {
+		obj.ContainerRuntime = ContainerdRuntime
+	}
 	if obj.Networking.IPFamily == "" {
 		obj.Networking.IPFamily = "ipv4"
 	}
@@ -63,15 +66,67 @@ func SetDefaultsCluster(obj *Cluster) {
 			obj.Networking.ServiceSubnet = "fd00:10:96::/112"
 		}
 	}
+	// default the node-local DNS cache address to the upstream default
+	if obj.DNS != nil && obj.DNS.NodeLocalCache && obj.DNS.NodeLocalCacheIP == "" {
+		obj.DNS.NodeLocalCacheIP = "169.254.20.10"
+		if obj.Networking.IPFamily == "ipv6" {
+			obj.DNS.NodeLocalCacheIP = "fd00::a9fe:140a"
+		}
+	}
+	// default to leaving pod image pull policies as is
+	if obj.ImagePulls != nil && obj.ImagePulls.Policy == "" {
+		obj.ImagePulls.Policy = PullIfNotPresent
+	}
+	// default konnectivity to the grpc proxy protocol
+	if obj.Konnectivity != nil {
+		if obj.Konnectivity.Mode == "" {
+			obj.Konnectivity.Mode = KonnectivityGRPC
+		}
+		if obj.Konnectivity.Version == "" {
+			obj.Konnectivity.Version = "v0.0.16"
+		}
+	}
+	if obj.ControlPlaneMetrics != nil && obj.ControlPlaneMetrics.ListenAddress == "" {
+		obj.ControlPlaneMetrics.ListenAddress = "127.0.0.1"
+	}
+	// default bootstrap secrets to generic secrets in the default namespace
+	for i := range obj.BootstrapSecrets {
+		s := &obj.BootstrapSecrets[i]
+		if s.Namespace == "" {
+			s.Namespace = "default"
+		}
+		if s.Type == "" {
+			s.Type = "Opaque"
+		}
+	}
+	// rotate container logs well before they can fill the node disk
+	if obj.ContainerLogs.MaxSize == "" {
+		obj.ContainerLogs.MaxSize = "10Mi"
+	}
+	if obj.ContainerLogs.MaxFiles == 0 {
+		obj.ContainerLogs.MaxFiles = 3
+	}
 }
 
 // SetDefaultsNode sets uninitialized fields to their default value.
 func SetDefaultsNode(obj *Node) {
+	if obj.Role == "" {
+		obj.Role = ControlPlaneRole
+	}
+
 	if obj.Image == "" {
 		obj.Image = defaults.Image
+		if obj.Role == RegistryRole {
+			obj.Image = defaults.RegistryImage
+		}
 	}
 
-	if obj.Role == "" {
-		obj.Role = ControlPlaneRole
+	for i := range obj.Files {
+		if obj.Files[i].Mode == "" {
+			obj.Files[i].Mode = "0644"
+		}
+		if obj.Files[i].Owner == "" {
+			obj.Files[i].Owner = "root:root"
+		}
 	}
 }
@@ -28,9 +28,132 @@ type Cluster struct {
 
 	/* Advanced fields */
 
+	// Roles defines additional roles nodes may have besides control-plane,
+	// worker and registry, EG infra or storage
+	// Nodes with these roles are joined as workers and then labeled and
+	// tainted, and may be targeted by role wide mounts and patches
+	Roles []CustomRole `yaml:"roles,omitempty" json:"roles,omitempty"`
+
 	// Networking contains cluster wide network settings
 	Networking Networking `yaml:"networking,omitempty" json:"networking,omitempty"`
 
+	// ContainerLogs configures the kubelet's rotation of container logs on
+	// the kubernetes nodes, bounding the node disk used by chatty workloads
+	// and the size of log exports
+	ContainerLogs ContainerLogs `yaml:"containerLogs,omitempty" json:"containerLogs,omitempty"`
+
+	// ContainerRuntime is the CRI implementation run inside the nodes,
+	// one of containerd or cri-o
+	// The node image must contain the selected runtime,
+	// see `kind build base-image --container-runtime`
+	//
+	// Defaults to "containerd"
+	ContainerRuntime ContainerRuntime `yaml:"containerRuntime,omitempty" json:"containerRuntime,omitempty"`
+
+	// KernelModules are host kernel modules required by the cluster,
+	// EG ip_vs or br_netfilter
+	// These are checked before creating any nodes, and optionally loaded,
+	// see `kind create cluster --load-kernel-modules`
+	KernelModules []string `yaml:"kernelModules,omitempty" json:"kernelModules,omitempty"`
+
+	// PreferDebugImages uses the debug variant of each node image if it is
+	// present locally, EG kindest/node:v1.16.2-debug for kindest/node:v1.16.2
+	// See `kind build node-image --debug-tools`
+	PreferDebugImages bool `yaml:"preferDebugImages,omitempty" json:"preferDebugImages,omitempty"`
+
+	// Seed makes the identifiers otherwise left to chance reproducible, node
+	// MAC addresses and machine IDs are derived from it and the node names,
+	// and the nodes are created one at a time so that they are assigned
+	// their addresses in config order
+	// Node and network names are always derived from the cluster name
+	// EG for golden file tests of tools that record the cluster topology
+	Seed string `yaml:"seed,omitempty" json:"seed,omitempty"`
+
+	// ComponentEnv sets environment variables on the kubelet and the
+	// control plane static pods, optionally only on nodes with a given role
+	// EG GOGC or HTTPS_PROXY
+	ComponentEnv []ComponentEnv `yaml:"componentEnv,omitempty" json:"componentEnv,omitempty"`
+
+	// ComponentScheduling sets node selectors, tolerations and resource
+	// requests on the workloads kind installs at create, so that they can
+	// be kept off some nodes without editing them after create
+	// EG for scheduling experiments that must not observe a rollout
+	ComponentScheduling []ComponentScheduling `yaml:"componentScheduling,omitempty" json:"componentScheduling,omitempty"`
+
+	// CRDs are applied in order once the cluster is ready, before the
+	// bootstrap manifests, kind then waits for each CustomResourceDefinition
+	// to be established and each APIService to be available, so operators
+	// in the bootstrap manifests do not race against their own CRDs
+	CRDs []CRDSource `yaml:"crds,omitempty" json:"crds,omitempty"`
+
+	// BootstrapManifests are applied in order once the cluster is ready,
+	// kind then waits for the CRDs and workloads they create to be ready
+	// EG cert-manager or CRDs required by the workloads under test
+	BootstrapManifests []BootstrapManifest `yaml:"bootstrapManifests,omitempty" json:"bootstrapManifests,omitempty"`
+
+	// BootstrapSecrets are created from host files and environment variables
+	// as soon as the API server is up, before the CNI and any bootstrap
+	// manifests, EG image pull secrets, TLS material or cloud credentials
+	BootstrapSecrets []BootstrapSecret `yaml:"bootstrapSecrets,omitempty" json:"bootstrapSecrets,omitempty"`
+
+	// KubeletCredentialProvider configures kubelet image credential
+	// provider plugins on all kubernetes nodes, the config and plugin
+	// binaries are mounted into the nodes and the kubelet flags set
+	// EG to test ecr or gcr credential provider flows
+	// Requires Kubernetes v1.20 or later
+	KubeletCredentialProvider *KubeletCredentialProvider `yaml:"kubeletCredentialProvider,omitempty" json:"kubeletCredentialProvider,omitempty"`
+
+	// ClusterIdentity supplies the kubeadm bootstrap token, certificate key
+	// and cluster CA from host files or environment variables instead of
+	// generating them, so recreated clusters keep a stable identity
+	// EG for pre-distributed kubeconfigs or webhooks pinning the cluster CA
+	ClusterIdentity *ClusterIdentity `yaml:"clusterIdentity,omitempty" json:"clusterIdentity,omitempty"`
+
+	// DNS configures a node-local DNS cache and / or replaces CoreDNS with
+	// another DNS deployment, kind points the kubelet's clusterDNS at them
+	// EG to reproduce DNS at scale behavior or conntrack races locally
+	DNS *DNS `yaml:"dns,omitempty" json:"dns,omitempty"`
+
+	// ImagePulls configures image pulls on the kubernetes nodes and lists
+	// workload images to pre-pull onto all of them at create
+	// EG to shave the first deploy latency of large test suites
+	ImagePulls *ImagePulls `yaml:"imagePulls,omitempty" json:"imagePulls,omitempty"`
+
+	// Konnectivity deploys the konnectivity server on the control plane
+	// nodes and its agents as a DaemonSet, and configures the API server's
+	// egress selector to reach nodes, pods and services through them, as
+	// in many managed clouds
+	// Requires Kubernetes v1.18 or later
+	Konnectivity *Konnectivity `yaml:"konnectivity,omitempty" json:"konnectivity,omitempty"`
+
+	// ControlPlaneMetrics publishes the metrics and pprof endpoints of the
+	// kube-controller-manager and kube-scheduler on every control plane node
+	// to the host, and generates a client certificate authorized to read
+	// them and the API server's in the cluster's directory, next to an
+	// endpoints file listing where each is published
+	ControlPlaneMetrics *ControlPlaneMetrics `yaml:"controlPlaneMetrics,omitempty" json:"controlPlaneMetrics,omitempty"`
+
+	// SystemdSlice places the node containers in a systemd slice on the
+	// host with CPU and memory accounting, so the cluster can be monitored
+	// and bounded as a whole with standard systemd tooling, EG
+	// systemd-cgtop or systemctl status
+	// Requires a linux host with docker using the systemd cgroup driver,
+	// and permission to manage systemd units
+	SystemdSlice *SystemdSlice `yaml:"systemdSlice,omitempty" json:"systemdSlice,omitempty"`
+
+	// SELinuxLabel is the SELinux label option of the node containers on
+	// SELinux enabled hosts, passed to docker as --security-opt label=<value>,
+	// EG disable or type:spc_t
+	// By default docker does not confine the privileged node containers,
+	// node containers confined to another type cannot run containers
+	SELinuxLabel string `yaml:"selinuxLabel,omitempty" json:"selinuxLabel,omitempty"`
+
+	// AuxiliaryContainers are extra containers kind starts on the cluster
+	// network at create and deletes with the cluster, nodes and pods reach
+	// them by name
+	// EG an NFS server, an LDAP server or a mock cloud metadata service
+	AuxiliaryContainers []AuxiliaryContainer `yaml:"auxiliaryContainers,omitempty" json:"auxiliaryContainers,omitempty"`
+
 	// KubeadmConfigPatches are applied to the generated kubeadm config as
 	// strategic merge patches to `kustomize build` internally
 	// https://github.com/kubernetes/community/blob/master/contributors/devel/strategic-merge-patch.md
@@ -63,6 +186,15 @@ type Node struct {
 	// If unset a default image will be used, see defaults.Image
 	Image string `yaml:"image,omitempty" json:"image,omitempty"`
 
+	// Platform is the os/arch[/variant] of the node image variant to run,
+	// EG linux/arm64 for an arm64 worker among amd64 nodes, emulated with
+	// qemu if the host has binfmt_misc handlers registered for it
+	// Platforms other than the host's are much slower, and only useful
+	// for testing multi-arch scheduling and image manifests
+	//
+	// Defaults to the platform of the container runtime
+	Platform string `yaml:"platform,omitempty" json:"platform,omitempty"`
+
 	/* Advanced fields */
 
 	// TODO: cri-like types should be inline instead
@@ -73,6 +205,76 @@ type Node struct {
 	// ExtraPortMappings describes additional port mappings for the node container
 	// binded to a host Port
 	ExtraPortMappings []PortMapping `yaml:"extraPortMappings,omitempty" json:"extraPortMappings,omitempty"`
+
+	// Files are written into the node before the kubelet is started,
+	// EG registries.conf, CA bundles or kubelet credential provider configs
+	Files []File `yaml:"files,omitempty" json:"files,omitempty"`
+
+	// RestartPolicy is the restart policy of the node container, one of
+	// no, always, unless-stopped, on-failure or on-failure:<max-retries>
+	// Restarted nodes may need fixups such as updating their IP, see
+	// `kind supervise`
+	//
+	// Defaults to no
+	RestartPolicy string `yaml:"restartPolicy,omitempty" json:"restartPolicy,omitempty"`
+
+	// Ulimits are the resource limits of the node container, EG nofile,
+	// nproc or memlock, scale tests and eBPF based CNIs often need more
+	// than the container runtime's defaults
+	Ulimits []Ulimit `yaml:"ulimits,omitempty" json:"ulimits,omitempty"`
+
+	// Storage backs the node's kubelet and container image filesystems with
+	// size limited volumes, to exercise image garbage collection and disk
+	// pressure eviction
+	// When any node configures storage the kubelet's default image garbage
+	// collection and eviction thresholds are kept, rather than disabled
+	Storage *NodeStorage `yaml:"storage,omitempty" json:"storage,omitempty"`
+}
+
+// Ulimit is a resource limit of a node container, see `docker run --ulimit`
+type Ulimit struct {
+	// Name of the limit, one of core, cpu, data, fsize, locks, memlock,
+	// msgqueue, nice, nofile, nproc, rss, rtprio, rttime, sigpending or stack
+	Name string `yaml:"name" json:"name"`
+	// Soft limit, -1 for unlimited
+	Soft int64 `yaml:"soft" json:"soft"`
+	// Hard limit, -1 for unlimited
+	//
+	// Defaults to the soft limit
+	Hard int64 `yaml:"hard,omitempty" json:"hard,omitempty"`
+}
+
+// NodeStorage configures size limited filesystems for a node, sizes are
+// quantities of bytes such as 512Mi or 20Gi
+type NodeStorage struct {
+	// Size limits the filesystem holding the kubelet's data, which is the
+	// kubelet's node filesystem for pod logs and emptyDir volumes
+	Size string `yaml:"size,omitempty" json:"size,omitempty"`
+	// ImageFilesystemSize places the container runtime's images and
+	// writable layers on a separate filesystem of this size, which the
+	// kubelet then manages as its image filesystem
+	// This must fit the images preloaded in the node image
+	ImageFilesystemSize string `yaml:"imageFilesystemSize,omitempty" json:"imageFilesystemSize,omitempty"`
+}
+
+// File is a file written into a node
+type File struct {
+	// Path is the absolute path of the file within the node
+	Path string `yaml:"path" json:"path"`
+	// Content is the inline content of the file
+	// Exactly one of content or hostPath must be set
+	Content string `yaml:"content,omitempty" json:"content,omitempty"`
+	// HostPath is the path to a file on the host to copy into the node,
+	// relative paths are relative to the working directory
+	HostPath string `yaml:"hostPath,omitempty" json:"hostPath,omitempty"`
+	// Mode is the file mode in octal
+	//
+	// Defaults to "0644"
+	Mode string `yaml:"mode,omitempty" json:"mode,omitempty"`
+	// Owner is the owner of the file, as user[:group]
+	//
+	// Defaults to "root:root"
+	Owner string `yaml:"owner,omitempty" json:"owner,omitempty"`
 }
 
 // NodeRole defines possible role for nodes in a Kubernetes cluster managed by `kind`
@@ -86,8 +288,33 @@ const (
 	ControlPlaneRole NodeRole = "control-plane"
 	// WorkerRole identifies a node that hosts a Kubernetes worker
 	WorkerRole NodeRole = "worker"
+	// RegistryRole identifies a node that hosts an OCI registry on the
+	// cluster network, served with TLS certificates generated by kind.
+	// The other nodes are configured to use it as a mirror for
+	// localhost:5000 and <registry-node-name>:5000.
+	// Registry storage may be persisted by adding an extraMount with
+	// containerPath /var/lib/registry.
+	RegistryRole NodeRole = "registry"
 )
 
+// CustomRole is an additional node role, nodes with a custom role are
+// Kubernetes worker nodes
+type CustomRole struct {
+	// Name is the role name nodes use as their role
+	Name NodeRole `yaml:"name,omitempty" json:"name,omitempty"`
+	// Labels are set on the Kubernetes nodes with this role, in addition to
+	// node-role.kubernetes.io/<name>
+	Labels map[string]string `yaml:"labels,omitempty" json:"labels,omitempty"`
+	// Taints are set on the Kubernetes nodes with this role, as
+	// key[=value]:effect, EG dedicated=infra:NoSchedule
+	Taints []string `yaml:"taints,omitempty" json:"taints,omitempty"`
+	// ExtraMounts are added to the extraMounts of each node with this role
+	ExtraMounts []Mount `yaml:"extraMounts,omitempty" json:"extraMounts,omitempty"`
+	// KubeadmConfigPatches are applied to the generated kubeadm config of
+	// nodes with this role only, after the cluster wide patches
+	KubeadmConfigPatches []string `yaml:"kubeadmConfigPatches,omitempty" json:"kubeadmConfigPatches,omitempty"`
+}
+
 // Networking contains cluster wide network settings
 type Networking struct {
 	// IPFamily is the network cluster model, currently it can be ipv4 or ipv6
@@ -100,6 +327,17 @@ type Networking struct {
 	//
 	// Defaults to 127.0.0.1
 	APIServerAddress string `yaml:"apiServerAddress,omitempty" json:"apiServerAddress,omitempty"`
+	// APIServerHostname is a DNS name to use as the server address in the
+	// kubeconfig instead of apiServerAddress, EG when the kubeconfig is used
+	// from other containers or machines through a tunnel
+	// It is added to the API server certificate SANs, but must otherwise
+	// resolve wherever the kubeconfig is used, see addAPIServerHostnameToHosts
+	APIServerHostname string `yaml:"apiServerHostname,omitempty" json:"apiServerHostname,omitempty"`
+	// AddAPIServerHostnameToHosts adds apiServerHostname to the host's
+	// hosts file, resolving to apiServerAddress, the entry is removed again
+	// when the cluster is deleted
+	// This requires permission to write the hosts file
+	AddAPIServerHostnameToHosts bool `yaml:"addAPIServerHostnameToHosts,omitempty" json:"addAPIServerHostnameToHosts,omitempty"`
 	// PodSubnet is the CIDR used for pod IPs
 	// kind will select a default if unspecified
 	PodSubnet string `yaml:"podSubnet,omitempty" json:"podSubnet,omitempty"`
@@ -109,6 +347,41 @@ type Networking struct {
 	// If DisableDefaultCNI is true, kind will not install the default CNI setup.
 	// Instead the user should install their own CNI after creating the cluster.
 	DisableDefaultCNI bool `yaml:"disableDefaultCNI,omitempty" json:"disableDefaultCNI,omitempty"`
+	// NodeSubnet is the IPv4 CIDR of the dedicated network created for the
+	// cluster's node containers
+	// Docker will select a free subnet if unspecified
+	NodeSubnet string `yaml:"nodeSubnet,omitempty" json:"nodeSubnet,omitempty"`
+	// NodeGateway is the IPv4 gateway of the cluster's node network,
+	// it must be within NodeSubnet
+	NodeGateway string `yaml:"nodeGateway,omitempty" json:"nodeGateway,omitempty"`
+	// NodeIPv6Subnet is the IPv6 CIDR of the cluster's node network,
+	// if set IPv6 is enabled on the network
+	// For IPv6 clusters kind will select a /64 within fc00:f853:ccd::/48 not
+	// overlapping any existing docker network if unspecified
+	NodeIPv6Subnet string `yaml:"nodeIPv6Subnet,omitempty" json:"nodeIPv6Subnet,omitempty"`
+	// MTU is the MTU of the cluster's node network, EG lower this on hosts
+	// connected through a VPN
+	// Docker will use the daemon default if unspecified
+	MTU int32 `yaml:"mtu,omitempty" json:"mtu,omitempty"`
+	// HostNetwork runs the node container in the host's network namespace,
+	// making NodePorts, pod hostPorts and the API server on port 6443
+	// directly reachable on the host without port mappings
+	// This is only supported for single node clusters on Linux hosts, and
+	// the CNI and kube-proxy then configure routes and iptables rules in the
+	// host's network namespace, these are not removed on delete
+	HostNetwork bool `yaml:"hostNetwork,omitempty" json:"hostNetwork,omitempty"`
+}
+
+// ContainerLogs configures the kubelet's container log rotation
+type ContainerLogs struct {
+	// MaxSize is the size a container log file is rotated at, as a
+	// Kubernetes quantity
+	// Defaults to 10Mi
+	MaxSize string `yaml:"maxSize,omitempty" json:"maxSize,omitempty"`
+	// MaxFiles is the maximum number of log files kept per container,
+	// including the current one, it must be at least 2
+	// Defaults to 3
+	MaxFiles int32 `yaml:"maxFiles,omitempty" json:"maxFiles,omitempty"`
 }
 
 // ClusterIPFamily defines cluster network IP family
@@ -121,6 +394,299 @@ const (
 	IPv6Family ClusterIPFamily = "ipv6"
 )
 
+// ComponentEnv contains environment variables for a kubernetes component
+type ComponentEnv struct {
+	// Component is one of kubelet, kube-apiserver, kube-controller-manager,
+	// kube-scheduler or etcd
+	Component string `yaml:"component" json:"component"`
+	// Role limits the variables to nodes with this role
+	// If unset the variables are set on all nodes running the component
+	Role NodeRole `yaml:"role,omitempty" json:"role,omitempty"`
+	// Env are the environment variables to set, later entries for the same
+	// component and variable take precedence
+	Env []EnvVar `yaml:"env,omitempty" json:"env,omitempty"`
+}
+
+// EnvVar is an environment variable
+type EnvVar struct {
+	Name  string `yaml:"name" json:"name"`
+	Value string `yaml:"value,omitempty" json:"value,omitempty"`
+}
+
+// AuxiliaryContainer is a container kind runs on the cluster network,
+// these are not Kubernetes nodes
+type AuxiliaryContainer struct {
+	// Name is the container's hostname and its alias on the cluster network,
+	// the container is named <cluster name>-<name>
+	Name string `yaml:"name" json:"name"`
+	// Image is the container image, it is pulled if not present
+	Image string `yaml:"image" json:"image"`
+	// Command overrides the image's command, if set
+	Command []string `yaml:"command,omitempty" json:"command,omitempty"`
+	// Env are environment variables set in the container
+	Env []EnvVar `yaml:"env,omitempty" json:"env,omitempty"`
+	// Privileged runs the container privileged, EG for a kernel NFS server
+	Privileged bool `yaml:"privileged,omitempty" json:"privileged,omitempty"`
+	// ExtraMounts describes additional mount points for the container
+	ExtraMounts []Mount `yaml:"extraMounts,omitempty" json:"extraMounts,omitempty"`
+	// ExtraPortMappings publish container ports on the host
+	ExtraPortMappings []PortMapping `yaml:"extraPortMappings,omitempty" json:"extraPortMappings,omitempty"`
+}
+
+// ComponentScheduling customizes the pods of a workload kind installs
+type ComponentScheduling struct {
+	// Component is one of cni (the default CNI), node-local-dns or
+	// konnectivity-agent
+	// If unset the settings apply to all of them, settings for a component
+	// take precedence over those for all of them
+	Component string `yaml:"component,omitempty" json:"component,omitempty"`
+	// NodeSelector is merged into the pods' node selector
+	NodeSelector map[string]string `yaml:"nodeSelector,omitempty" json:"nodeSelector,omitempty"`
+	// Tolerations are added to the pods' tolerations
+	Tolerations []Toleration `yaml:"tolerations,omitempty" json:"tolerations,omitempty"`
+	// Resources are merged into the resources of all of the pods' containers
+	Resources ResourceRequirements `yaml:"resources,omitempty" json:"resources,omitempty"`
+}
+
+// Toleration is a pod toleration, see the Kubernetes Toleration type
+type Toleration struct {
+	Key string `yaml:"key,omitempty" json:"key,omitempty"`
+	// Operator is Exists or Equal, defaults to Equal
+	Operator string `yaml:"operator,omitempty" json:"operator,omitempty"`
+	Value    string `yaml:"value,omitempty" json:"value,omitempty"`
+	// Effect is NoSchedule, PreferNoSchedule or NoExecute, all effects
+	// are tolerated if unset
+	Effect string `yaml:"effect,omitempty" json:"effect,omitempty"`
+}
+
+// ResourceRequirements are container resource requests and limits,
+// keyed by resource name with quantities as values, EG cpu: 100m
+type ResourceRequirements struct {
+	Requests map[string]string `yaml:"requests,omitempty" json:"requests,omitempty"`
+	Limits   map[string]string `yaml:"limits,omitempty" json:"limits,omitempty"`
+}
+
+// CRDSource is a manifest of CustomResourceDefinitions and / or APIServices,
+// exactly one of Path or URL must be set
+// APIServices only become available once the server behind them runs, so
+// their Service should be in the same manifest
+type CRDSource struct {
+	// Path is a manifest file on the host, relative to the working directory,
+	// or a directory of .yaml, .yml and .json manifests applied in name order
+	Path string `yaml:"path,omitempty" json:"path,omitempty"`
+	// URL is a manifest URL, downloaded on the host, a #sha256=<hex>
+	// fragment verifies it, see also KIND_DOWNLOAD_MIRRORS
+	URL string `yaml:"url,omitempty" json:"url,omitempty"`
+}
+
+// BootstrapManifest is a manifest or helm chart applied once the cluster
+// is ready, exactly one of Path, URL or Chart must be set
+type BootstrapManifest struct {
+	// Path is a manifest file on the host, relative to the working directory
+	Path string `yaml:"path,omitempty" json:"path,omitempty"`
+	// URL is a manifest URL, downloaded on the host, a #sha256=<hex>
+	// fragment verifies it, see also KIND_DOWNLOAD_MIRRORS
+	URL string `yaml:"url,omitempty" json:"url,omitempty"`
+	// Chart is a helm chart reference, EG jetstack/cert-manager, or a chart
+	// name in Repo
+	// Charts are installed with helm on the host, which must be installed
+	Chart string `yaml:"chart,omitempty" json:"chart,omitempty"`
+	// Repo is the chart repository URL, only valid with Chart
+	Repo string `yaml:"repo,omitempty" json:"repo,omitempty"`
+	// Version is the chart version, only valid with Chart
+	// Defaults to the latest version
+	Version string `yaml:"version,omitempty" json:"version,omitempty"`
+	// Namespace the manifest or chart is installed into
+	// Manifest objects that specify a namespace are unaffected, the
+	// namespace is created for charts if needed
+	Namespace string `yaml:"namespace,omitempty" json:"namespace,omitempty"`
+}
+
+// BootstrapSecret is a secret created from host files and environment
+// variables, secret values are never written to the kind cluster state
+type BootstrapSecret struct {
+	// Name of the secret
+	Name string `yaml:"name" json:"name"`
+	// Namespace of the secret, which is created if it does not exist
+	//
+	// Defaults to "default"
+	Namespace string `yaml:"namespace,omitempty" json:"namespace,omitempty"`
+	// Type of the secret, EG kubernetes.io/dockerconfigjson or
+	// kubernetes.io/tls
+	//
+	// Defaults to "Opaque"
+	Type string `yaml:"type,omitempty" json:"type,omitempty"`
+	// Data are the secret's keys and where to read their values from
+	Data []SecretData `yaml:"data,omitempty" json:"data,omitempty"`
+}
+
+// SecretData is a secret key read from exactly one of File or Env
+type SecretData struct {
+	// Key in the secret, EG .dockerconfigjson or tls.crt
+	Key string `yaml:"key" json:"key"`
+	// File is a host path to read the value from
+	File string `yaml:"file,omitempty" json:"file,omitempty"`
+	// Env is a host environment variable to read the value from, it must
+	// be set when the cluster is created
+	Env string `yaml:"env,omitempty" json:"env,omitempty"`
+}
+
+// ClusterIdentity supplies the cluster's kubeadm identity, unset fields are
+// generated as usual
+type ClusterIdentity struct {
+	// Token is the kubeadm bootstrap token nodes join with, formatted as
+	// [a-z0-9]{6}.[a-z0-9]{16}
+	//
+	// Defaults to a well known token
+	Token *ValueSource `yaml:"token,omitempty" json:"token,omitempty"`
+	// CertificateKey is the hex encoded AES key the control plane
+	// certificates are uploaded to the cluster encrypted with, so control
+	// planes joined later with `kubeadm join --certificate-key` can reuse it,
+	// formatted as 64 hex characters
+	//
+	// Defaults to not uploading the certificates
+	// Requires Kubernetes v1.15 or later
+	CertificateKey *ValueSource `yaml:"certificateKey,omitempty" json:"certificateKey,omitempty"`
+	// CACert and CAKey are the PEM encoded cluster CA certificate and key,
+	// kubeadm signs all of the cluster's certificates with it
+	// Either both or neither must be set
+	CACert *ValueSource `yaml:"caCert,omitempty" json:"caCert,omitempty"`
+	CAKey  *ValueSource `yaml:"caKey,omitempty" json:"caKey,omitempty"`
+}
+
+// ValueSource is a value read from exactly one of File or Env when the
+// cluster is created
+type ValueSource struct {
+	// File is a host path to read the value from
+	File string `yaml:"file,omitempty" json:"file,omitempty"`
+	// Env is a host environment variable to read the value from, it must
+	// be set when the cluster is created
+	Env string `yaml:"env,omitempty" json:"env,omitempty"`
+}
+
+// KubeletCredentialProvider configures kubelet image credential provider plugins
+// See: https://kubernetes.io/docs/tasks/kubelet-credential-provider/kubelet-credential-provider/
+type KubeletCredentialProvider struct {
+	// Config is the host path to the kubelet CredentialProviderConfig
+	Config string `yaml:"config" json:"config"`
+	// BinDir is the host directory containing the plugin binaries
+	BinDir string `yaml:"binDir" json:"binDir"`
+}
+
+// DNS configures the cluster DNS addon
+type DNS struct {
+	// NodeLocalCache deploys node-local-dns as a DaemonSet caching DNS on
+	// every node, the kubelet is configured to use it as the cluster DNS
+	// See: https://kubernetes.io/docs/tasks/administer-cluster/nodelocaldns/
+	NodeLocalCache bool `yaml:"nodeLocalCache,omitempty" json:"nodeLocalCache,omitempty"`
+	// NodeLocalCacheIP is the link-local address the cache listens on
+	//
+	// Defaults to 169.254.20.10, or fd00::a9fe:140a in IPv6 clusters
+	NodeLocalCacheIP string `yaml:"nodeLocalCacheIP,omitempty" json:"nodeLocalCacheIP,omitempty"`
+	// Manifest is a path or http(s) URL to a manifest deploying DNS in place
+	// of CoreDNS, which is then not installed
+	// The manifest is applied once the CNI is installed, URLs are downloaded
+	// like CRD URLs
+	Manifest string `yaml:"manifest,omitempty" json:"manifest,omitempty"`
+	// ServiceIP is the cluster IP of the replacement DNS service, only valid
+	// with Manifest
+	//
+	// Defaults to the tenth address of the service subnet, as used by kubeadm
+	// for the kube-dns service
+	ServiceIP string `yaml:"serviceIP,omitempty" json:"serviceIP,omitempty"`
+}
+
+// Konnectivity configures the konnectivity server and agents
+// See: https://kubernetes.io/docs/tasks/extend-kubernetes/setup-konnectivity/
+type Konnectivity struct {
+	// Mode is the proxy protocol between the API server and the
+	// konnectivity server, one of grpc or http-connect
+	//
+	// Defaults to grpc
+	Mode KonnectivityMode `yaml:"mode,omitempty" json:"mode,omitempty"`
+	// Version of the konnectivity server and agent images
+	// (k8s.gcr.io/kas-network-proxy/proxy-server and proxy-agent)
+	//
+	// Defaults to v0.0.16
+	Version string `yaml:"version,omitempty" json:"version,omitempty"`
+}
+
+// KonnectivityMode is the protocol the API server uses to reach the
+// konnectivity server
+type KonnectivityMode string
+
+const (
+	// KonnectivityGRPC uses the grpc proxy protocol
+	KonnectivityGRPC KonnectivityMode = "grpc"
+	// KonnectivityHTTPConnect uses HTTP CONNECT
+	KonnectivityHTTPConnect KonnectivityMode = "http-connect"
+)
+
+// ControlPlaneMetrics configures publishing the control plane components'
+// metrics and profiling endpoints
+type ControlPlaneMetrics struct {
+	// ListenAddress is the host address the endpoints are published on,
+	// the host ports are picked at random
+	//
+	// Defaults to 127.0.0.1
+	ListenAddress string `yaml:"listenAddress,omitempty" json:"listenAddress,omitempty"`
+}
+
+// SystemdSlice configures the systemd slice the node containers run in
+type SystemdSlice struct {
+	// Name is the name of the slice, it must end in .slice, dashes nest it
+	// in the slices named by the preceding parts as usual for systemd
+	//
+	// Defaults to kind-<cluster name>.slice
+	Name string `yaml:"name,omitempty" json:"name,omitempty"`
+	// CPUQuota limits the CPU time of all of the nodes, as a percentage of
+	// one CPU, EG 200% for two CPUs
+	CPUQuota string `yaml:"cpuQuota,omitempty" json:"cpuQuota,omitempty"`
+	// MemoryMax limits the memory of all of the nodes, EG 8G
+	MemoryMax string `yaml:"memoryMax,omitempty" json:"memoryMax,omitempty"`
+}
+
+// ImagePulls configures how the kubernetes nodes pull images
+type ImagePulls struct {
+	// PrePull images are pulled onto all kubernetes nodes once they are up,
+	// in parallel with waiting for the control plane to become ready
+	PrePull []string `yaml:"prePull,omitempty" json:"prePull,omitempty"`
+	// Policy is the cluster wide image pull policy, one of IfNotPresent or
+	// Always, Always enables the AlwaysPullImages admission plugin
+	//
+	// Defaults to IfNotPresent, leaving the pod's imagePullPolicy as is
+	Policy ImagePullPolicy `yaml:"policy,omitempty" json:"policy,omitempty"`
+	// Parallel lets the kubelet pull multiple images at once
+	// (serializeImagePulls: false)
+	Parallel bool `yaml:"parallel,omitempty" json:"parallel,omitempty"`
+	// Timeout bounds each image pull, EG "10m", this sets the kubelet's
+	// runtimeRequestTimeout so large images do not fail to pull
+	//
+	// Defaults to the kubelet default of 2m for the kubelet, and 5m for
+	// pre-pulled images
+	Timeout string `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+}
+
+// ImagePullPolicy is the cluster wide image pull policy
+type ImagePullPolicy string
+
+const (
+	// PullIfNotPresent leaves the pod's imagePullPolicy as is
+	PullIfNotPresent ImagePullPolicy = "IfNotPresent"
+	// PullAlways forces every pod to always pull its images
+	PullAlways ImagePullPolicy = "Always"
+)
+
+// ContainerRuntime defines the CRI implementation run inside the nodes
+type ContainerRuntime string
+
+const (
+	// ContainerdRuntime sets ContainerRuntime to containerd
+	ContainerdRuntime ContainerRuntime = "containerd"
+	// CRIORuntime sets ContainerRuntime to cri-o
+	CRIORuntime ContainerRuntime = "cri-o"
+)
+
 // PatchJSON6902 represents an inline kustomize json 6902 patch
 // https://tools.ietf.org/html/rfc6902
 type PatchJSON6902 struct {
@@ -166,6 +732,10 @@ type Mount struct {
 	Readonly bool `yaml:"readOnly,omitempty" json:"readOnly,omitempty"`
 	// If set, the mount needs SELinux relabeling.
 	SelinuxRelabel bool `yaml:"selinuxRelabel,omitempty" json:"selinuxRelabel,omitempty"`
+	// If set along with SelinuxRelabel, the mount is relabeled with the label
+	// shared by all containers rather than one private to the container, so
+	// several nodes may mount the same host path.
+	SelinuxRelabelShared bool `yaml:"selinuxRelabelShared,omitempty" json:"selinuxRelabelShared,omitempty"`
 	// Requested propagation mode.
 	Propagation MountPropagation `yaml:"propagation,omitempty" json:"propagation,omitempty"`
 }
@@ -183,10 +753,32 @@ type PortMapping struct {
 	HostPort int32 `yaml:"hostPort,omitempty" json:"hostPort,omitempty"`
 	// TODO: add protocol (tcp/udp) and port-ranges
 	ListenAddress string `yaml:"listenAddress,omitempty" json:"listenAddress,omitempty"`
+	// ListenInterface is a host network interface, EG eth1, the mapping
+	// listens on the interface's addresses (of ListenIPFamily if set)
+	// Mutually exclusive with ListenAddress
+	ListenInterface string `yaml:"listenInterface,omitempty" json:"listenInterface,omitempty"`
+	// ListenIPFamily is the IP family the mapping listens on if
+	// ListenAddress is not set, one of ipv4, ipv6 or dual
+	// With a random HostPort each family is assigned its own port
+	//
+	// Defaults to the container runtime's default, all IPv4 addresses
+	ListenIPFamily PortMappingIPFamily `yaml:"listenIPFamily,omitempty" json:"listenIPFamily,omitempty"`
 	// Protocol (TCP/UDP)
 	Protocol PortMappingProtocol `yaml:"protocol,omitempty" json:"protocol,omitempty"`
 }
 
+// PortMappingIPFamily is the IP family a port mapping listens on
+type PortMappingIPFamily string
+
+const (
+	// PortMappingIPv4 listens on IPv4 addresses only
+	PortMappingIPv4 PortMappingIPFamily = "ipv4"
+	// PortMappingIPv6 listens on IPv6 addresses only
+	PortMappingIPv6 PortMappingIPFamily = "ipv6"
+	// PortMappingDualStack listens on both IPv4 and IPv6 addresses
+	PortMappingDualStack PortMappingIPFamily = "dual"
+)
+
 // MountPropagation represents an "enum" for mount propagation options,
 // see also Mount.
 type MountPropagation int32
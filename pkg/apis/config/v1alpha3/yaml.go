@@ -30,11 +30,12 @@ Custom YAML (de)serialization for these types
 func (m *Mount) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	// this is basically Mount, except Propagation is a string for further parsing
 	type MountYaml struct {
-		ContainerPath  string `yaml:"containerPath,omitempty"`
-		HostPath       string `yaml:"hostPath,omitempty"`
-		Readonly       bool   `yaml:"readOnly,omitempty"`
-		SelinuxRelabel bool   `yaml:"selinuxRelabel,omitempty"`
-		Propagation    string `yaml:"propagation,omitempty"`
+		ContainerPath        string `yaml:"containerPath,omitempty"`
+		HostPath             string `yaml:"hostPath,omitempty"`
+		Readonly             bool   `yaml:"readOnly,omitempty"`
+		SelinuxRelabel       bool   `yaml:"selinuxRelabel,omitempty"`
+		SelinuxRelabelShared bool   `yaml:"selinuxRelabelShared,omitempty"`
+		Propagation          string `yaml:"propagation,omitempty"`
 	}
 	aux := MountYaml{}
 	if err := unmarshal(&aux); err != nil {
@@ -45,6 +46,7 @@ func (m *Mount) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	m.HostPath = aux.HostPath
 	m.Readonly = aux.Readonly
 	m.SelinuxRelabel = aux.SelinuxRelabel
+	m.SelinuxRelabelShared = aux.SelinuxRelabelShared
 	// handle special field
 	if aux.Propagation != "" {
 		val, ok := MountPropagationNameToValue[aux.Propagation]
@@ -61,10 +63,12 @@ func (m *Mount) UnmarshalYAML(unmarshal func(interface{}) error) error {
 func (p *PortMapping) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	// this is basically PortMappingYaml, except Protocol is a string for further parsing
 	type PortMappingYaml struct {
-		ContainerPort int32  `yaml:"containerPort,omitempty"`
-		HostPort      int32  `yaml:"hostPort,omitempty"`
-		ListenAddress string `yaml:"listenAddress,omitempty"`
-		Protocol      string `yaml:"protocol"`
+		ContainerPort   int32               `yaml:"containerPort,omitempty"`
+		HostPort        int32               `yaml:"hostPort,omitempty"`
+		ListenAddress   string              `yaml:"listenAddress,omitempty"`
+		ListenInterface string              `yaml:"listenInterface,omitempty"`
+		ListenIPFamily  PortMappingIPFamily `yaml:"listenIPFamily,omitempty"`
+		Protocol        string              `yaml:"protocol"`
 	}
 	aux := PortMappingYaml{}
 	if err := unmarshal(&aux); err != nil {
@@ -74,6 +78,8 @@ func (p *PortMapping) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	p.ContainerPort = aux.ContainerPort
 	p.HostPort = aux.HostPort
 	p.ListenAddress = aux.ListenAddress
+	p.ListenInterface = aux.ListenInterface
+	p.ListenIPFamily = aux.ListenIPFamily
 	// handle special field
 	if aux.Protocol != "" {
 		val, ok := PortMappingProtocolNameToValue[strings.ToUpper(aux.Protocol)]
@@ -35,8 +35,9 @@ const DefaultImage = "kindest/base:latest"
 // build configuration
 type BuildContext struct {
 	// option fields
-	sourceDir string
-	image     string
+	sourceDir        string
+	image            string
+	containerRuntime string
 }
 
 // Option is BuildContext configuration option supplied to NewBuildContext
@@ -56,6 +57,14 @@ func WithImage(image string) Option {
 	}
 }
 
+// WithContainerRuntime configures a NewBuildContext to additionally install
+// the container runtime `runtime`, one of containerd or cri-o
+func WithContainerRuntime(runtime string) Option {
+	return func(b *BuildContext) {
+		b.containerRuntime = runtime
+	}
+}
+
 // NewBuildContext creates a new BuildContext with
 // default configuration
 func NewBuildContext(options ...Option) *BuildContext {
@@ -103,7 +112,11 @@ func (c *BuildContext) Build() (err error) {
 
 func (c *BuildContext) buildImage(dir string) error {
 	// build the image, tagged as tagImageAs, using the our tempdir as the context
-	cmd := exec.Command("docker", "build", "-t", c.image, dir)
+	args := []string{"build", "-t", c.image}
+	if c.containerRuntime != "" {
+		args = append(args, "--build-arg", "CONTAINER_RUNTIME="+c.containerRuntime)
+	}
+	cmd := exec.Command("docker", append(args, dir)...)
 	globals.GetLogger().V(0).Info("Starting Docker build ...")
 	exec.InheritOutput(cmd)
 	err := cmd.Run()
@@ -0,0 +1,101 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/build/node/internal/container/docker"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/fs"
+	"sigs.k8s.io/kind/pkg/internal/util/env"
+)
+
+// SnapshotRepository is the image repository build snapshots are tagged in
+//
+// A snapshot is the base image with all images that are pulled rather than
+// built already loaded, but without kubernetes installed. Rebuilds with the
+// same inputs start from it and only need to install the kubernetes build
+// artifacts.
+const SnapshotRepository = "kind-build-cache"
+
+// DefaultCacheDir returns the default directory build artifacts are cached in
+func DefaultCacheDir() string {
+	return filepath.Join(env.HomeDir(), ".kind", "cache", "node-image")
+}
+
+// buildCache stores intermediate node image build artifacts keyed by their
+// content, a zero value buildCache caches nothing
+type buildCache struct {
+	dir string
+}
+
+func (b *buildCache) enabled() bool {
+	return b.dir != ""
+}
+
+// saveImage saves image to dest as in docker save, reusing the cached
+// archive of the same image content if there is one
+func (b *buildCache) saveImage(image, dest string) error {
+	if !b.enabled() {
+		return docker.Save(image, dest)
+	}
+	id, err := docker.ImageID(image)
+	if err != nil {
+		return err
+	}
+	cached := filepath.Join(b.dir, "images", strings.TrimPrefix(id, "sha256:")+".tar")
+	if _, err := os.Stat(cached); err != nil {
+		if err := os.MkdirAll(filepath.Dir(cached), 0755); err != nil {
+			return errors.Wrap(err, "failed to create image cache dir")
+		}
+		// save to a temporary file first so interrupted saves are not cached
+		tmp := cached + ".partial"
+		if err := docker.Save(image, tmp); err != nil {
+			return err
+		}
+		if err := os.Rename(tmp, cached); err != nil {
+			return errors.Wrap(err, "failed to cache image archive")
+		}
+	}
+	return fs.CopyFile(cached, dest)
+}
+
+// snapshotImage returns the snapshot image for a build with inputs, these
+// should be content hashes or otherwise identify the snapshot contents
+func (b *buildCache) snapshotImage(inputs ...string) string {
+	h := sha256.New()
+	for _, input := range inputs {
+		_, _ = io.WriteString(h, input)
+		_, _ = h.Write([]byte{0})
+	}
+	return fmt.Sprintf("%s:%x", SnapshotRepository, h.Sum(nil)[:16])
+}
+
+// hasSnapshot returns true if caching is enabled and snapshot exists locally
+func (b *buildCache) hasSnapshot(snapshot string) bool {
+	if !b.enabled() || snapshot == "" {
+		return false
+	}
+	return exec.Command("docker", "inspect", "--type=image", snapshot).Run() == nil
+}
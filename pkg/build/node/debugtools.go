@@ -0,0 +1,79 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/build/node/internal/container/docker"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/globals"
+)
+
+// DebugTools are the packages installed into node images built with
+// WithDebugTools
+var DebugTools = []string{
+	"conntrack",
+	"dnsutils",
+	"ethtool",
+	"iproute2",
+	"iptables",
+	"procps",
+	"strace",
+	"tcpdump",
+	"util-linux", // nsenter
+}
+
+// nsenterContainerPath is the path of the helper entering a CRI container's
+// namespaces in debug node images
+const nsenterContainerPath = "/usr/local/bin/nsenter-container"
+
+// nsenterContainer enters the namespaces of a CRI container by ID, EG:
+// nsenter-container <id> tcpdump -i eth0
+const nsenterContainer = `#!/bin/sh
+# enter the namespaces of a CRI container: nsenter-container <container-id> [command...]
+set -e
+if [ $# -lt 1 ]; then
+	echo "usage: $0 <container-id> [command...]" >&2
+	exit 1
+fi
+pid="$(crictl inspect --output go-template --template '{{.info.pid}}' "$1")"
+shift
+if [ $# -eq 0 ]; then
+	set -- sh
+fi
+exec nsenter --target "${pid}" --net --pid --uts --ipc "$@"
+`
+
+// installDebugTools installs DebugTools and the nsenter helper into the
+// build container
+func (c *BuildContext) installDebugTools(containerID string) error {
+	globals.GetLogger().V(0).Infof("Installing debug tools: %s", strings.Join(DebugTools, ", "))
+	cmder := docker.ContainerCmder(containerID)
+	// clean up the package lists to keep the image small
+	install := "apt-get update && " +
+		"DEBIAN_FRONTEND=noninteractive apt-get install -y --no-install-recommends " + strings.Join(DebugTools, " ") +
+		" && rm -rf /var/lib/apt/lists/*"
+	if err := exec.InheritOutput(cmder.Command("sh", "-c", install)).Run(); err != nil {
+		return errors.Wrap(err, "failed to install debug tools")
+	}
+	if err := createFile(cmder, nsenterContainerPath, nsenterContainer); err != nil {
+		return errors.Wrap(err, "failed to write nsenter helper")
+	}
+	return cmder.Command("chmod", "+x", nsenterContainerPath).Run()
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+)
+
+// ImageVersions are the versions of the software in a node image
+type ImageVersions struct {
+	// Components maps the node components, EG kubelet or containerd, to
+	// their versions
+	Components map[string]string `json:"components"`
+	// Packages maps the base OS packages to their versions
+	Packages map[string]string `json:"packages"`
+}
+
+// VersionChange is a change in the version of a component or package
+// between two images, From is empty if it was added and To if it was removed
+type VersionChange struct {
+	Name string `json:"name"`
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+}
+
+// ImageDiff lists the changes between two node images, sorted by name
+type ImageDiff struct {
+	Components []VersionChange `json:"components"`
+	Packages   []VersionChange `json:"packages"`
+}
+
+// packagePrefix marks the lines of inspectScript listing packages
+const packagePrefix = "package:"
+
+// inspectScript prints a tab separated name and version per line, each
+// component is optional as images differ in the runtime they ship
+var inspectScript = strings.Join([]string{
+	`printf 'os\t%s\n' "$(. /etc/os-release && echo "$PRETTY_NAME")"`,
+	`printf 'kubernetes\t%s\n' "$(cat /kind/version 2>/dev/null)"`,
+	`printf 'kubelet\t%s\n' "$(kubelet --version 2>/dev/null)"`,
+	`printf 'containerd\t%s\n' "$(containerd --version 2>/dev/null)"`,
+	`printf 'cri-o\t%s\n' "$(crio --version 2>/dev/null | head -n1)"`,
+	`printf 'runc\t%s\n' "$(runc --version 2>/dev/null | head -n1)"`,
+	`printf 'crictl\t%s\n' "$(crictl --version 2>/dev/null)"`,
+	// the plugins print their version when run without a CNI_COMMAND, older
+	// plugins only print the protocol versions they support
+	`printf 'cni-plugins\t%s\n' "$(` + cniBinDir + `/host-local 2>&1 | grep -v 'protocol versions' | head -n1)"`,
+	`dpkg-query -W -f='` + packagePrefix + `${Package}\t${Version}\n' 2>/dev/null || true`,
+}, "\n")
+
+// InspectImage returns the versions of the software in the node image,
+// running a container from it, which is pulled if necessary
+func InspectImage(image string) (*ImageVersions, error) {
+	lines, err := exec.OutputLines(exec.Command(
+		"docker", "run", "--rm", "--entrypoint", "/bin/sh", image, "-c", inspectScript,
+	))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to inspect image %s", image)
+	}
+	return parseImageVersions(lines), nil
+}
+
+// DiffImages returns the changes from the versions in one image to another
+func DiffImages(from, to *ImageVersions) *ImageDiff {
+	return &ImageDiff{
+		Components: diffVersions(from.Components, to.Components),
+		Packages:   diffVersions(from.Packages, to.Packages),
+	}
+}
+
+// versionRE matches the version in the output of a --version flag
+var versionRE = regexp.MustCompile(`v?[0-9]+\.[0-9]+(\.[0-9]+)?[0-9A-Za-z.+~-]*`)
+
+// parseImageVersions parses the output of inspectScript
+func parseImageVersions(lines []string) *ImageVersions {
+	v := &ImageVersions{
+		Components: map[string]string{},
+		Packages:   map[string]string{},
+	}
+	for _, line := range lines {
+		parts := strings.SplitN(line, "\t", 2)
+		if len(parts) != 2 || parts[1] == "" {
+			continue
+		}
+		if strings.HasPrefix(parts[0], packagePrefix) {
+			v.Packages[strings.TrimPrefix(parts[0], packagePrefix)] = parts[1]
+			continue
+		}
+		// only keep the version of components, EG "Kubernetes v1.20.2",
+		// the os is a description without one
+		version := parts[1]
+		if parts[0] != "os" {
+			if match := versionRE.FindString(version); match != "" {
+				version = match
+			}
+		}
+		v.Components[parts[0]] = version
+	}
+	return v
+}
+
+// diffVersions returns the changes between the versions from and to
+func diffVersions(from, to map[string]string) []VersionChange {
+	changes := []VersionChange{}
+	for name, fromVersion := range from {
+		if toVersion := to[name]; toVersion != fromVersion {
+			changes = append(changes, VersionChange{Name: name, From: fromVersion, To: toVersion})
+		}
+	}
+	for name, toVersion := range to {
+		if _, ok := from[name]; !ok {
+			changes = append(changes, VersionChange{Name: name, To: toVersion})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+	return changes
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseImageVersions(t *testing.T) {
+	t.Parallel()
+	actual := parseImageVersions([]string{
+		"os\tUbuntu Groovy Gorilla (development branch)",
+		"kubernetes\tv1.20.2",
+		"kubelet\tKubernetes v1.20.2",
+		"containerd\tcontainerd github.com/containerd/containerd v1.4.0-106-gce4439a8 ce4439a8151f77dc50adb655ab4852ee9c366589",
+		"cri-o\t",
+		"runc\trunc version 1.0.0-rc92",
+		"cni-plugins\tCNI host-local plugin v0.9.0",
+		"package:libc6\t2.32-0ubuntu3",
+	})
+	expected := &ImageVersions{
+		Components: map[string]string{
+			"os":          "Ubuntu Groovy Gorilla (development branch)",
+			"kubernetes":  "v1.20.2",
+			"kubelet":     "v1.20.2",
+			"containerd":  "v1.4.0-106-gce4439a8",
+			"runc":        "1.0.0-rc92",
+			"cni-plugins": "v0.9.0",
+		},
+		Packages: map[string]string{
+			"libc6": "2.32-0ubuntu3",
+		},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("parseImageVersions() = %+v, expected %+v", actual, expected)
+	}
+}
+
+func TestDiffImages(t *testing.T) {
+	t.Parallel()
+	actual := DiffImages(
+		&ImageVersions{
+			Components: map[string]string{"kubelet": "v1.19.1", "runc": "1.0.0-rc92"},
+			Packages:   map[string]string{"libc6": "2.31-0ubuntu9", "removed": "1.0"},
+		},
+		&ImageVersions{
+			Components: map[string]string{"kubelet": "v1.20.2", "runc": "1.0.0-rc92"},
+			Packages:   map[string]string{"libc6": "2.32-0ubuntu3", "added": "2.0"},
+		},
+	)
+	expected := &ImageDiff{
+		Components: []VersionChange{
+			{Name: "kubelet", From: "v1.19.1", To: "v1.20.2"},
+		},
+		Packages: []VersionChange{
+			{Name: "added", To: "2.0"},
+			{Name: "libc6", From: "2.31-0ubuntu9", To: "2.32-0ubuntu3"},
+			{Name: "removed", From: "1.0"},
+		},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("DiffImages() = %+v, expected %+v", actual, expected)
+	}
+}
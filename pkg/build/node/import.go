@@ -57,3 +57,28 @@ func (c *containerdImporter) LoadCommand() exec.Cmd {
 func (c *containerdImporter) End() error {
 	return c.containerCmder.Command("pkill", "containerd").Run()
 }
+
+type crioImporter struct {
+	containerCmder exec.Cmder
+}
+
+func newCRIOImporter(containerCmder exec.Cmder) imageImporter {
+	return &crioImporter{
+		containerCmder: containerCmder,
+	}
+}
+
+var _ imageImporter = &crioImporter{}
+
+// Prepare is a no-op, podman writes directly to the image store cri-o uses
+func (c *crioImporter) Prepare() error {
+	return nil
+}
+
+func (c *crioImporter) LoadCommand() exec.Cmd {
+	return c.containerCmder.Command("podman", "load")
+}
+
+func (c *crioImporter) End() error {
+	return nil
+}
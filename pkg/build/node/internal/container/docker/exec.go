@@ -17,6 +17,7 @@ limitations under the License.
 package docker
 
 import (
+	"context"
 	"io"
 
 	"sigs.k8s.io/kind/pkg/exec"
@@ -42,9 +43,22 @@ func (c *containerCmder) Command(command string, args ...string) exec.Cmd {
 	}
 }
 
+// CommandContext is like Command, but docker exec is killed once ctx is
+// done, the containers are only used while building so the command left
+// running in the container is not stopped
+func (c *containerCmder) CommandContext(ctx context.Context, command string, args ...string) exec.Cmd {
+	return &containerCmd{
+		nameOrID: c.nameOrID,
+		ctx:      ctx,
+		command:  command,
+		args:     args,
+	}
+}
+
 // containerCmd implements exec.Cmd for docker containers
 type containerCmd struct {
 	nameOrID string // the container name or ID
+	ctx      context.Context
 	command  string
 	args     []string
 	env      []string
@@ -83,6 +97,9 @@ func (c *containerCmd) Run() error {
 		c.args...,
 	)
 	cmd := exec.Command("docker", args...)
+	if c.ctx != nil {
+		cmd = exec.CommandContext(c.ctx, "docker", args...)
+	}
 	if c.stdin != nil {
 		cmd.SetStdin(c.stdin)
 	}
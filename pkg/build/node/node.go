@@ -20,10 +20,11 @@ package node
 import (
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"path"
+	"sort"
 	"strings"
-	"time"
 
 	"github.com/google/uuid"
 
@@ -49,6 +50,10 @@ const DefaultBaseImage = "kindest/base:v20191025-0fae556c@sha256:6c1479d05220704
 // see pkg/build/kube.Bits
 const DefaultMode = "docker"
 
+// DefaultContainerRuntime is the default container runtime images are
+// loaded into
+const DefaultContainerRuntime = "containerd"
+
 // Option is BuildContext configuration option supplied to NewBuildContext
 type Option func(*BuildContext)
 
@@ -80,17 +85,53 @@ func WithKuberoot(root string) Option {
 	}
 }
 
+// WithContainerRuntime sets the container runtime images are loaded into,
+// one of containerd or cri-o, the base image must contain this runtime
+func WithContainerRuntime(runtime string) Option {
+	return func(b *BuildContext) {
+		b.containerRuntime = runtime
+	}
+}
+
+// WithCacheDir sets the directory intermediate build artifacts are cached in,
+// if empty nothing is cached, which is the default, see DefaultCacheDir and
+// SnapshotRepository
+func WithCacheDir(dir string) Option {
+	return func(b *BuildContext) {
+		b.cache = buildCache{dir: dir}
+	}
+}
+
+// WithDebugTools configures the built image to include common debugging
+// tools, see DebugTools
+func WithDebugTools(debugTools bool) Option {
+	return func(b *BuildContext) {
+		b.debugTools = debugTools
+	}
+}
+
 // BuildContext is used to build the kind node image, and contains
 // build configuration
 type BuildContext struct {
 	// option fields
-	mode      string
-	image     string
-	baseImage string
+	mode             string
+	image            string
+	baseImage        string
+	containerRuntime string
+	cache            buildCache
+	debugTools       bool
+	// image slimming options, see slim.go
+	skipImages        []string
+	stripDebugSymbols bool
+	excludeCNIPlugins []string
+	validate          bool
+	// overlay Dockerfile, see overlay.go
+	overlay string
 	// non-option fields
-	arch     string // TODO(bentheelder): this should be an option
-	kubeRoot string
-	bits     kube.Bits
+	arch      string // TODO(bentheelder): this should be an option
+	kubeRoot  string
+	bits      kube.Bits
+	resources buildResources
 }
 
 // NewBuildContext creates a new BuildContext with default configuration,
@@ -98,10 +139,11 @@ type BuildContext struct {
 func NewBuildContext(options ...Option) (ctx *BuildContext, err error) {
 	// default options
 	ctx = &BuildContext{
-		mode:      DefaultMode,
-		image:     DefaultImage,
-		baseImage: DefaultBaseImage,
-		arch:      env.GetArch(),
+		mode:             DefaultMode,
+		image:            DefaultImage,
+		baseImage:        DefaultBaseImage,
+		containerRuntime: DefaultContainerRuntime,
+		arch:             env.GetArch(),
 	}
 	// apply user options
 	for _, option := range options {
@@ -131,6 +173,16 @@ func NewBuildContext(options ...Option) (ctx *BuildContext, err error) {
 // Build builds the cluster node image, the sourcedir must be set on
 // the BuildContext
 func (c *BuildContext) Build() (err error) {
+	// remove the build containers even if interrupted
+	stop := c.resources.cleanupOnSignal()
+	defer stop()
+
+	if c.overlay != "" {
+		if err := checkOverlay(c.overlay); err != nil {
+			return err
+		}
+	}
+
 	// ensure kubernetes build is up to date first
 	globals.GetLogger().V(0).Info("Starting to build Kubernetes")
 	if err = c.bits.Build(); err != nil {
@@ -197,6 +249,13 @@ const BuildContainerLabelKey = "io.k8s.sigs.kind.build"
 // will be stored.
 const DockerImageArchives = "/kind/images"
 
+// the kubernetes build artifacts in the build container before they are
+// installed, see populateBits
+const (
+	buildVersionLocation = "/build/bits/version"
+	buildKubeadmLocation = "/build/bits/bin/kubeadm"
+)
+
 // private kube.InstallContext implementation, local to the image build
 type installContext struct {
 	basePath    string
@@ -241,15 +300,28 @@ func (c *BuildContext) buildImage(dir string) error {
 	// if docker gets proper squash support, we can rm them instead
 	// This also allows the KubeBit implementations to perform programmatic
 	// install in the image
-	containerID, err := c.createBuildContainer(dir)
-	cmder := docker.ContainerCmder(containerID)
+	//
+	// if a previous build loaded the same pulled images into the same base
+	// image, start from its snapshot instead, this is checked before pulling
+	// anything
+	snapshot, err := c.snapshotImage(dir)
+	if err != nil {
+		return err
+	}
+	buildFrom := c.baseImage
+	cached := c.cache.hasSnapshot(snapshot)
+	if cached {
+		globals.GetLogger().V(0).Infof("Using cached build snapshot %s", snapshot)
+		buildFrom = snapshot
+	}
+	containerID, err := c.createBuildContainer(buildFrom, dir)
 
 	// ensure we will delete it
-	if containerID != "" {
-		defer func() {
-			_ = exec.Command("docker", "rm", "-f", "-v", containerID).Run()
-		}()
-	}
+	defer func() {
+		if containerID != "" {
+			c.resources.remove(containerID)
+		}
+	}()
 	if err != nil {
 		globals.GetLogger().Errorf("Image build Failed! Failed to create build container: %v", err)
 		return err
@@ -257,19 +329,115 @@ func (c *BuildContext) buildImage(dir string) error {
 
 	globals.GetLogger().V(0).Info("Building in " + containerID)
 
+	// pull images that were not part of the build, unless the snapshot
+	// already contains them
+	plan, err := c.planImages(dir, containerID, !cached)
+	if err != nil {
+		globals.GetLogger().Errorf("Image build Failed! Failed to pull Images: %v", err)
+		return err
+	}
+	if len(plan.pulled) > 0 {
+		if err := c.loadImages(containerID, plan.pulled, nil); err != nil {
+			globals.GetLogger().Errorf("Image build Failed! Failed to load images %v", err)
+			return err
+		}
+	}
+
+	// snapshot the container before kubernetes is installed, so that the
+	// snapshot only contains what it is keyed by
+	if !cached && c.cache.enabled() {
+		c.saveSnapshot(dir, containerID, snapshot)
+	}
+
+	if err := c.installKubernetes(containerID); err != nil {
+		return err
+	}
+
+	if err := c.loadImages(containerID, c.bits.ImagePaths(), plan.fixRepository); err != nil {
+		globals.GetLogger().Errorf("Image build Failed! Failed to load images %v", err)
+		return err
+	}
+
+	if c.debugTools {
+		if err := c.installDebugTools(containerID); err != nil {
+			globals.GetLogger().Errorf("Image build Failed! %v", err)
+			return err
+		}
+	}
+
+	if c.stripDebugSymbols {
+		if err := c.stripBinaries(containerID); err != nil {
+			globals.GetLogger().Errorf("Image build Failed! %v", err)
+			return err
+		}
+	}
+
+	if len(c.excludeCNIPlugins) > 0 {
+		if err := c.removeCNIPlugins(containerID); err != nil {
+			globals.GetLogger().Errorf("Image build Failed! %v", err)
+			return err
+		}
+	}
+
+	// Save the image changes to a new image, when applying an overlay this
+	// is the image the overlay builds on
+	commitAs := c.image
+	if c.overlay != "" {
+		commitAs, err = overlayBaseImage(c.image)
+		if err != nil {
+			return err
+		}
+	}
+	cmd := exec.Command(
+		"docker", "commit",
+		// we need to put this back after changing it when running the image
+		"--change", `ENTRYPOINT [ "/usr/local/bin/entrypoint", "/sbin/init" ]`,
+		// label the image so it can be pruned once it is replaced
+		"--change", "LABEL "+buildLabel(BuildImageLabelKey),
+		containerID, commitAs,
+	)
+	exec.InheritOutput(cmd)
+	if err = cmd.Run(); err != nil {
+		globals.GetLogger().Errorf("Image build Failed! Failed to save image: %v", err)
+		return err
+	}
+
+	if c.overlay != "" {
+		if err := c.applyOverlay(commitAs); err != nil {
+			globals.GetLogger().Errorf("Image build Failed! %v", err)
+			return err
+		}
+	}
+
+	if c.validate {
+		if err := c.validateImage(plan.preloaded); err != nil {
+			globals.GetLogger().Errorf("Image build Failed! Built image %s failed validation: %v", c.image, err)
+			return err
+		}
+	}
+
+	globals.GetLogger().V(0).Info("Image build completed.")
+	return nil
+}
+
+// installKubernetes installs the kubernetes build artifacts and configures
+// the kubelet in the build container, it is safe to run more than once
+func (c *BuildContext) installKubernetes(containerID string) error {
+	cmder := docker.ContainerCmder(containerID)
+
 	// helper we will use to run "build steps"
 	execInBuild := func(command string, args ...string) error {
 		return exec.InheritOutput(cmder.Command(command, args...)).Run()
 	}
 
 	// make artifacts directory
-	if err = execInBuild("mkdir", "/kind/"); err != nil {
+	if err := execInBuild("mkdir", "-p", "/kind/"); err != nil {
 		globals.GetLogger().Errorf("Image build Failed! Failed to make directory %v", err)
 		return err
 	}
 
 	// copy artifacts in
-	if err = execInBuild("rsync", "-r", "/build/bits/", "/kind/"); err != nil {
+	if err := execInBuild("rsync", "-r", "/build/bits/", "/kind/"); err != nil {
 		globals.GetLogger().Errorf("Image build Failed! Failed to sync bits: %v", err)
 		return err
 	}
@@ -279,7 +447,7 @@ func (c *BuildContext) buildImage(dir string) error {
 		basePath:    "/kind/",
 		containerID: containerID,
 	}
-	if err = c.bits.Install(ic); err != nil {
+	if err := c.bits.Install(ic); err != nil {
 		globals.GetLogger().Errorf("Image build Failed! Failed to install Kubernetes: %v", err)
 		return err
 	}
@@ -303,33 +471,19 @@ func (c *BuildContext) buildImage(dir string) error {
 	}
 
 	// ensure we don't fail if swap is enabled on the host
-	if err = execInBuild("/bin/sh", "-c",
-		`echo "KUBELET_EXTRA_ARGS=--fail-swap-on=false" >> /etc/default/kubelet`,
+	if err := execInBuild("/bin/sh", "-c",
+		`grep -qs fail-swap-on /etc/default/kubelet || echo "KUBELET_EXTRA_ARGS=--fail-swap-on=false" >> /etc/default/kubelet`,
 	); err != nil {
 		globals.GetLogger().Errorf("Image build Failed! Failed to add kubelet extra args: %v", err)
 		return err
 	}
 
-	// pre-pull images that were not part of the build
-	if err = c.prePullImages(dir, containerID); err != nil {
-		globals.GetLogger().Errorf("Image build Failed! Failed to pull Images: %v", err)
-		return err
-	}
-
-	// Save the image changes to a new image
-	cmd := exec.Command(
-		"docker", "commit",
-		// we need to put this back after changing it when running the image
-		"--change", `ENTRYPOINT [ "/usr/local/bin/entrypoint", "/sbin/init" ]`,
-		containerID, c.image,
-	)
-	exec.InheritOutput(cmd)
-	if err = cmd.Run(); err != nil {
-		globals.GetLogger().Errorf("Image build Failed! Failed to save image: %v", err)
+	// write the default CNI manifest
+	if err := createFile(cmder, defaultCNIManifestLocation, defaultCNIManifest); err != nil {
+		globals.GetLogger().Errorf("Image build Failed! Failed write default CNI Manifest: %v", err)
 		return err
 	}
 
-	globals.GetLogger().V(0).Info("Image build completed.")
 	return nil
 }
 
@@ -349,32 +503,41 @@ func createFile(containerCmder exec.Cmder, filePath, contents string) error {
 	).Run()
 }
 
-// must be run after kubernetes has been installed on the node
-func (c *BuildContext) prePullImages(dir, containerID string) error {
+// imagePlan describes the images to load into the node image
+type imagePlan struct {
+	// pulled are the archives of the required images that were not built
+	pulled []string
+	// fixRepository corrects the repository of built images for the
+	// kubernetes version
+	fixRepository func(string) string
+	// preloaded are the required images that were not skipped
+	preloaded []string
+}
+
+// planImages determines the images to load using the kubernetes build
+// artifacts mounted in the build container, pulling and saving the images
+// that were not built if pull is set
+func (c *BuildContext) planImages(dir, containerID string, pull bool) (*imagePlan, error) {
 	// first get the images we actually built
 	builtImages, err := c.getBuiltImages()
 	if err != nil {
 		globals.GetLogger().Errorf("Image build Failed! Failed to get built images: %v", err)
-		return err
+		return nil, err
 	}
 
 	// helpers to run things in the build container
 	cmder := docker.ContainerCmder(containerID)
-	inheritOutputAndRun := func(cmd exec.Cmd) error {
-		exec.InheritOutput(cmd)
-		return cmd.Run()
-	}
 
 	// get the Kubernetes version we installed on the node
 	// we need this to ask kubeadm what images we need
-	rawVersion, err := exec.CombinedOutputLines(cmder.Command("cat", kubernetesVersionLocation))
+	rawVersion, err := exec.CombinedOutputLines(cmder.Command("cat", buildVersionLocation))
 	if err != nil {
 		globals.GetLogger().Errorf("Image build Failed! Failed to get Kubernetes version: %v", err)
-		return err
+		return nil, err
 	}
 	if len(rawVersion) != 1 {
 		globals.GetLogger().Errorf("Image build Failed! Failed to get Kubernetes version: %v", err)
-		return errors.New("invalid kubernetes version file")
+		return nil, errors.New("invalid kubernetes version file")
 	}
 
 	// before Kubernetes v1.12.0 kubeadm requires arch specific images, instead
@@ -383,7 +546,7 @@ func (c *BuildContext) prePullImages(dir, containerID string) error {
 	// so we virtually re-tag them here.
 	ver, err := version.ParseGeneric(rawVersion[0])
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// get image tag fixing function for this version
@@ -395,7 +558,7 @@ func (c *BuildContext) prePullImages(dir, containerID string) error {
 	for _, image := range builtImages.List() {
 		registry, tag, err := docker.SplitImage(image)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		registry = fixRepository(registry)
 		fixedImages.Insert(registry + ":" + tag)
@@ -403,92 +566,152 @@ func (c *BuildContext) prePullImages(dir, containerID string) error {
 	builtImages = fixedImages
 	globals.GetLogger().V(0).Info("Detected built images: " + strings.Join(builtImages.List(), ", "))
 
-	// write the default CNI manifest
-	// NOTE: the paths inside the container should use the path package
-	// and not filepath (!), we want posixy paths in the linux container, NOT
-	// whatever path format the host uses. For paths on the host we use filepath
-	if err := inheritOutputAndRun(cmder.Command(
-		"mkdir", "-p", path.Dir(defaultCNIManifestLocation),
-	)); err != nil {
-		globals.GetLogger().Errorf("Image build Failed! Failed write default CNI Manifest: %v", err)
-		return err
-	}
-	if err := cmder.Command(
-		"cp", "/dev/stdin", defaultCNIManifestLocation,
-	).SetStdin(
-		strings.NewReader(defaultCNIManifest),
-	).Run(); err != nil {
-		globals.GetLogger().Errorf("Image build Failed! Failed write default CNI Manifest: %v", err)
-		return err
-	}
-
 	// gets the list of images required by kubeadm
 	requiredImages, err := exec.OutputLines(cmder.Command(
-		"kubeadm", "config", "images", "list", "--kubernetes-version", rawVersion[0],
+		buildKubeadmLocation, "config", "images", "list", "--kubernetes-version", rawVersion[0],
 	))
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// all builds should isntall the default CNI images currently
 	requiredImages = append(requiredImages, defaultCNIImages...)
 
-	// Create "images" subdir.
-	imagesDir := path.Join(dir, "bits", "images")
+	// drop any images the user chose not to preload, built images are
+	// always loaded
+	if len(c.skipImages) > 0 {
+		kept := []string{}
+		for _, image := range requiredImages {
+			if !builtImages.Has(image) && c.skipImage(image) {
+				globals.GetLogger().V(0).Infof("Skipping preloading image: %s", image)
+				continue
+			}
+			kept = append(kept, image)
+		}
+		requiredImages = kept
+	}
+
+	// Create "images" subdir, outside of bits so it is never synced into
+	// the image itself
+	imagesDir := path.Join(dir, "images")
 	if err := os.MkdirAll(imagesDir, 0777); err != nil {
 		globals.GetLogger().Errorf("Image build Failed! Failed create local images dir: %v", err)
-		return errors.Wrap(err, "failed to make images dir")
+		return nil, errors.Wrap(err, "failed to make images dir")
 	}
 
+	plan := &imagePlan{
+		fixRepository: fixRepository,
+		preloaded:     requiredImages,
+	}
+	if !pull {
+		return plan, nil
+	}
 	fns := []func() error{}
-	pulledImages := make(chan string, len(requiredImages))
 	for i, image := range requiredImages {
-		i, image := i, image // https://golang.org/doc/faq#closures_and_goroutines
+		if builtImages.Has(image) {
+			continue
+		}
+		// TODO(bentheelder): generate a friendlier name
+		pullName := fmt.Sprintf("%d.tar", i)
+		pullTo := path.Join(imagesDir, pullName)
+		plan.pulled = append(plan.pulled, pullTo)
+		image := image // https://golang.org/doc/faq#closures_and_goroutines
 		fns = append(fns, func() error {
-			if !builtImages.Has(image) {
-				fmt.Printf("Pulling: %s\n", image)
-				err := docker.Pull(image, 2)
-				if err != nil {
-					globals.GetLogger().Warnf("Failed to pull %s with error: %v", image, err)
-				}
-				// TODO(bentheelder): generate a friendlier name
-				pullName := fmt.Sprintf("%d.tar", i)
-				pullTo := path.Join(imagesDir, pullName)
-				err = docker.Save(image, pullTo)
-				if err != nil {
-					return err
-				}
-				pulledImages <- pullTo
+			fmt.Printf("Pulling: %s\n", image)
+			err := docker.Pull(image, 2)
+			if err != nil {
+				globals.GetLogger().Warnf("Failed to pull %s with error: %v", image, err)
 			}
-			return nil
+			return c.cache.saveImage(image, pullTo)
 		})
 	}
 	if err := errors.AggregateConcurrent(fns...); err != nil {
-		return err
+		return nil, err
+	}
+	return plan, nil
+}
+
+// snapshotImage returns the build snapshot image for this build, keyed by
+// everything determining its contents: the base image, the container
+// runtime and the pulled images, which follow from the kubernetes version,
+// the built images and the skipped images.
+// It is empty if caching is disabled or the base image is not present
+// locally, as nothing is pulled to look for a snapshot
+func (c *BuildContext) snapshotImage(dir string) (string, error) {
+	if !c.cache.enabled() {
+		return "", nil
+	}
+	baseID, err := docker.ImageID(c.baseImage)
+	if err != nil {
+		return "", nil
+	}
+	version, err := ioutil.ReadFile(path.Join(dir, "bits", "version"))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read kubernetes version")
+	}
+	builtImages, err := c.getBuiltImages()
+	if err != nil {
+		return "", err
+	}
+	inputs := []string{baseID, c.containerRuntime, strings.TrimSpace(string(version))}
+	inputs = append(inputs, builtImages.List()...)
+	inputs = append(inputs, defaultCNIImages...)
+	skipImages := append([]string{}, c.skipImages...)
+	sort.Strings(skipImages)
+	inputs = append(inputs, skipImages...)
+	return c.cache.snapshotImage(inputs...), nil
+}
+
+// saveSnapshot commits the build container as the snapshot image, see
+// snapshotImage, the container runtime must be stopped so its state on disk
+// is consistent. The snapshot is only an optimization for later builds, so
+// failures are only logged
+func (c *BuildContext) saveSnapshot(dir, containerID, snapshot string) {
+	// the base image may only be present since the build container was
+	// created
+	if snapshot == "" {
+		var err error
+		if snapshot, err = c.snapshotImage(dir); err != nil || snapshot == "" {
+			globals.GetLogger().Warnf("Failed to save build snapshot: %v", err)
+			return
+		}
 	}
-	close(pulledImages)
-	pulled := []string{}
-	for image := range pulledImages {
-		pulled = append(pulled, image)
+	if err := exec.Command(
+		"docker", "commit", "--change", "LABEL "+buildLabel(BuildImageLabelKey), containerID, snapshot,
+	).Run(); err != nil {
+		globals.GetLogger().Warnf("Failed to save build snapshot: %v", err)
 	}
+}
+
+// loadImages loads the image archives into the container runtime,
+// correcting their repositories with fixRepository if set
+func (c *BuildContext) loadImages(containerID string, archives []string, fixRepository func(string) string) (err error) {
+	cmder := docker.ContainerCmder(containerID)
 
 	// setup image importer
 	importer := newContainerdImporter(cmder)
+	if c.containerRuntime == "cri-o" {
+		importer = newCRIOImporter(cmder)
+	}
 	if err := importer.Prepare(); err != nil {
-		globals.GetLogger().Errorf("Image build Failed! Failed to prepare containerd to load images %v", err)
+		globals.GetLogger().Errorf("Image build Failed! Failed to prepare %s to load images %v", c.containerRuntime, err)
 		return err
 	}
 
-	// TODO: return this error?
+	// the runtime is stopped once done, so that the container can be
+	// snapshotted, see saveSnapshot
 	defer func() {
-		if err := importer.End(); err != nil {
-			globals.GetLogger().Errorf("Image build Failed! Failed to tear down containerd after loading images %v", err)
+		if endErr := importer.End(); endErr != nil {
+			globals.GetLogger().Errorf("Image build Failed! Failed to tear down %s after loading images %v", c.containerRuntime, endErr)
+			if err == nil {
+				err = endErr
+			}
 		}
 	}()
 
 	// create a plan of image loading
 	loadFns := []func() error{}
-	for _, image := range pulled {
+	for _, image := range archives {
 		image := image // capture loop var
 		loadFns = append(loadFns, func() error {
 			f, err := os.Open(image)
@@ -496,35 +719,18 @@ func (c *BuildContext) prePullImages(dir, containerID string) error {
 				return err
 			}
 			defer f.Close()
-			return importer.LoadCommand().SetStdout(os.Stdout).SetStderr(os.Stdout).SetStdin(f).Run()
-		})
-	}
-	for _, image := range c.bits.ImagePaths() {
-		image := image // capture loop var
-		loadFns = append(loadFns, func() error {
-			f, err := os.Open(image)
-			if err != nil {
-				return err
+			if fixRepository == nil {
+				return importer.LoadCommand().SetStdout(os.Stdout).SetStderr(os.Stdout).SetStdin(f).Run()
 			}
-			defer f.Close()
-			//return importer.LoadCommand().SetStdout(os.Stdout).SetStderr(os.Stderr).SetStdin(f).Run()
 			// we will rewrite / correct the tags as we load the image
-			if err := exec.RunWithStdinWriter(importer.LoadCommand().SetStdout(os.Stdout).SetStderr(os.Stdout), func(w io.Writer) error {
+			return exec.RunWithStdinWriter(importer.LoadCommand().SetStdout(os.Stdout).SetStderr(os.Stdout), func(w io.Writer) error {
 				return docker.EditArchiveRepositories(f, w, fixRepository)
-			}); err != nil {
-				return err
-			}
-			return nil
+			})
 		})
 	}
 
 	// run all image loading concurrently until one fails or all succeed
-	if err := errors.UntilErrorConcurrent(loadFns); err != nil {
-		globals.GetLogger().Errorf("Image build Failed! Failed to load images %v", err)
-		return err
-	}
-
-	return nil
+	return errors.UntilErrorConcurrent(loadFns)
 }
 
 func repositoryCorrectorForVersion(kubeVersion *version.Version) func(string) string {
@@ -560,17 +766,19 @@ func repositoryCorrectorForVersion(kubeVersion *version.Version) func(string) st
 	}
 }
 
-func (c *BuildContext) createBuildContainer(buildDir string) (id string, err error) {
+func (c *BuildContext) createBuildContainer(image, buildDir string) (id string, err error) {
 	// attempt to explicitly pull the image if it doesn't exist locally
 	// we don't care if this errors, we'll still try to run which also pulls
-	_, _ = docker.PullIfNotPresent(c.baseImage, 4)
+	_, _ = docker.PullIfNotPresent(image, 4)
 	id = "kind-build-" + uuid.New().String()
+	c.resources.add(id)
 	err = docker.Run(
-		c.baseImage,
+		image,
 		docker.WithRunArgs(
 			"-d", // make the client exit while the container continues to run
 			// label the container to make them easier to track
-			"--label", fmt.Sprintf("%s=%s", BuildContainerLabelKey, time.Now().Format(time.RFC3339Nano)),
+			"--label", buildLabel(BuildContainerLabelKey),
+			"--label", ownerLabel(),
 			"-v", fmt.Sprintf("%s:/build", buildDir),
 			// the container should hang forever so we can exec in it
 			"--entrypoint=sleep",
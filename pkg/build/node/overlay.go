@@ -0,0 +1,157 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/build/node/internal/container/docker"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/globals"
+)
+
+// OverlayBaseImageArg is the build arg an overlay Dockerfile receives the
+// standard node image in, EG:
+//
+//	ARG BASE_IMAGE
+//	FROM ${BASE_IMAGE}
+const OverlayBaseImageArg = "BASE_IMAGE"
+
+// nodeEntrypoint is the entrypoint of built node images, overlays must not
+// change it
+const nodeEntrypoint = `["/usr/local/bin/entrypoint","/sbin/init"]`
+
+// overlayCriticalPaths are the paths an overlay must not remove from the
+// node image for nodes to boot and kubeadm to work
+var overlayCriticalPaths = []string{
+	"/usr/local/bin/entrypoint",
+	kubernetesVersionLocation,
+	defaultCNIManifestLocation,
+	"/kind/systemd/kubelet.service",
+	"/etc/systemd/system/kubelet.service.d/10-kubeadm.conf",
+}
+
+// overlayCriticalCommands must remain on the PATH of the node image
+var overlayCriticalCommands = []string{
+	"kubeadm",
+	"kubelet",
+	"kubectl",
+	"crictl",
+}
+
+// overlayFromRE matches a FROM instruction using the base image build arg
+var overlayFromRE = regexp.MustCompile(`(?im)^\s*FROM\s+\$\{?` + OverlayBaseImageArg + `\}?(\s|$)`)
+
+// WithOverlay configures the build to apply the Dockerfile at `dockerfile`
+// on top of the standard node image, the Dockerfile must build
+// FROM ${BASE_IMAGE}, see OverlayBaseImageArg. The directory containing
+// the Dockerfile is used as the build context.
+func WithOverlay(dockerfile string) Option {
+	return func(b *BuildContext) {
+		b.overlay = dockerfile
+	}
+}
+
+// checkOverlay verifies the overlay Dockerfile is readable and builds on the
+// standard node image, so that mistakes are caught before building kubernetes
+func checkOverlay(dockerfile string) error {
+	contents, err := ioutil.ReadFile(dockerfile)
+	if err != nil {
+		return errors.Wrap(err, "failed to read overlay Dockerfile")
+	}
+	if !overlayFromRE.Match(contents) {
+		return errors.Errorf(
+			"overlay Dockerfile %s must build FROM ${%s}, the standard node image",
+			dockerfile, OverlayBaseImageArg,
+		)
+	}
+	return nil
+}
+
+// overlayBaseImage returns the tag the standard node image is saved as
+// before applying the overlay to it, keeping it around lets docker reuse the
+// overlay's cached layers when rebuilding
+func overlayBaseImage(image string) (string, error) {
+	repository, tag, err := docker.SplitImage(image)
+	if err != nil {
+		return "", err
+	}
+	// digests cannot be retagged
+	if i := strings.IndexByte(tag, '@'); i != -1 {
+		tag = tag[:i]
+	}
+	return repository + ":" + tag + "-base", nil
+}
+
+// applyOverlay builds the overlay Dockerfile on top of baseImage, tagging
+// the result as the image being built
+func (c *BuildContext) applyOverlay(baseImage string) error {
+	globals.GetLogger().V(0).Infof("Applying overlay %s on top of %s ...", c.overlay, baseImage)
+	cmd := exec.Command(
+		"docker", "build",
+		"-f", c.overlay,
+		"--build-arg", OverlayBaseImageArg+"="+baseImage,
+		// leave nothing behind if the build fails, and label the image so
+		// it can be pruned once it is replaced
+		"--force-rm",
+		"--label", buildLabel(BuildImageLabelKey),
+		"-t", c.image,
+		filepath.Dir(c.overlay),
+	)
+	exec.InheritOutput(cmd)
+	if err := cmd.Run(); err != nil {
+		return errors.Wrap(err, "failed to build overlay")
+	}
+	return c.checkOverlayResult()
+}
+
+// checkOverlayResult verifies the overlay did not break the node image
+func (c *BuildContext) checkOverlayResult() error {
+	lines, err := exec.OutputLines(exec.Command(
+		"docker", "inspect", "--format", "{{json .Config.Entrypoint}}", c.image,
+	))
+	if err != nil {
+		return errors.Wrap(err, "failed to inspect overlaid image")
+	}
+	if len(lines) != 1 || lines[0] != nodeEntrypoint {
+		return errors.Errorf("overlay must not change the entrypoint, got %s", strings.Join(lines, " "))
+	}
+
+	// report everything missing at once
+	var script []string
+	for _, p := range overlayCriticalPaths {
+		script = append(script, "test -e "+p+" || echo "+p)
+	}
+	for _, command := range overlayCriticalCommands {
+		script = append(script, "command -v "+command+" >/dev/null || echo "+command)
+	}
+	missing, err := exec.OutputLines(exec.Command(
+		"docker", "run", "--rm", "--entrypoint", "sh", c.image,
+		"-c", strings.Join(script, "\n"),
+	))
+	if err != nil {
+		return errors.Wrap(err, "failed to check overlaid image")
+	}
+	if len(missing) > 0 {
+		return errors.Errorf("overlay removed files the node image requires: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
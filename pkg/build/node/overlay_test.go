@@ -0,0 +1,97 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckOverlay(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "kind-overlay")
+	if err != nil {
+		t.Fatalf("failed to create tempdir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	cases := []struct {
+		Name        string
+		Dockerfile  string
+		ExpectError bool
+	}{
+		{
+			Name:       "braced build arg",
+			Dockerfile: "ARG BASE_IMAGE\nFROM ${BASE_IMAGE}\nRUN echo hi\n",
+		},
+		{
+			Name:       "unbraced build arg, lowercase from",
+			Dockerfile: "ARG BASE_IMAGE\nfrom $BASE_IMAGE AS node\n",
+		},
+		{
+			Name:        "other base image",
+			Dockerfile:  "FROM ubuntu:20.04\n",
+			ExpectError: true,
+		},
+		{
+			Name:        "similarly named build arg",
+			Dockerfile:  "ARG BASE_IMAGE_2\nFROM ${BASE_IMAGE_2}\n",
+			ExpectError: true,
+		},
+	}
+	for i, tc := range cases {
+		dockerfile := filepath.Join(dir, "Dockerfile."+string(rune('a'+i)))
+		if err := ioutil.WriteFile(dockerfile, []byte(tc.Dockerfile), 0644); err != nil {
+			t.Fatalf("failed to write Dockerfile: %v", err)
+		}
+		t.Run(tc.Name, func(t *testing.T) {
+			err := checkOverlay(dockerfile)
+			if err != nil && !tc.ExpectError {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err == nil && tc.ExpectError {
+				t.Fatalf("expected an error")
+			}
+		})
+	}
+	if err := checkOverlay(filepath.Join(dir, "missing")); err == nil {
+		t.Errorf("expected an error for a missing Dockerfile")
+	}
+}
+
+func TestOverlayBaseImage(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Image    string
+		Expected string
+	}{
+		{Image: "kindest/node:latest", Expected: "kindest/node:latest-base"},
+		{Image: "kindest/node", Expected: "kindest/node:latest-base"},
+		{Image: "kindest/node:v1.16.2@sha256:abc", Expected: "kindest/node:v1.16.2-base"},
+	}
+	for _, tc := range cases {
+		actual, err := overlayBaseImage(tc.Image)
+		if err != nil {
+			t.Errorf("overlayBaseImage(%q) unexpected error: %v", tc.Image, err)
+			continue
+		}
+		if actual != tc.Expected {
+			t.Errorf("overlayBaseImage(%q) = %q, expected %q", tc.Image, actual, tc.Expected)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/globals"
+
+	"sigs.k8s.io/kind/pkg/internal/util/env"
+)
+
+// BuildImageLabelKey is applied to each image the build commits or builds,
+// untagged images with it are left behind by interrupted or repeated builds
+// and are removed by Prune
+const BuildImageLabelKey = "io.k8s.sigs.kind.build.image"
+
+// BuildOwnerLabelKey is applied to each build container, the value is the
+// host and pid of the build running it as <host>/<pid>
+const BuildOwnerLabelKey = "io.k8s.sigs.kind.build.owner"
+
+// buildLabel returns the --label value marking a build resource with key,
+// the value is when it was created
+func buildLabel(key string) string {
+	return fmt.Sprintf("%s=%s", key, time.Now().Format(time.RFC3339Nano))
+}
+
+// ownerLabel returns the --label value marking a build container as run by
+// this process, see BuildOwnerLabelKey
+func ownerLabel() string {
+	host, _ := os.Hostname()
+	return fmt.Sprintf("%s=%s/%d", BuildOwnerLabelKey, host, os.Getpid())
+}
+
+// ownerRunning returns true if the build owning a container may still be
+// running, IE its process is alive or runs on another host sharing the
+// docker daemon, owners of containers from before they were recorded are
+// assumed to have exited
+func ownerRunning(owner string) bool {
+	parts := strings.SplitN(owner, "/", 2)
+	if len(parts) != 2 {
+		return false
+	}
+	if host, _ := os.Hostname(); parts[0] != host {
+		return true
+	}
+	pid, err := strconv.Atoi(parts[1])
+	return err == nil && env.ProcessRunning(pid)
+}
+
+// buildResources tracks the containers a build creates, so they are
+// removed even if the build is interrupted
+type buildResources struct {
+	mu         sync.Mutex
+	containers map[string]bool
+}
+
+// add tracks the container id
+func (r *buildResources) add(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.containers == nil {
+		r.containers = map[string]bool{}
+	}
+	r.containers[id] = true
+}
+
+// remove removes the container id and stops tracking it
+func (r *buildResources) remove(id string) {
+	_ = exec.Command("docker", "rm", "-f", "-v", id).Run()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.containers, id)
+}
+
+// removeAll removes all of the tracked containers
+func (r *buildResources) removeAll() {
+	r.mu.Lock()
+	ids := []string{}
+	for id := range r.containers {
+		ids = append(ids, id)
+	}
+	r.mu.Unlock()
+	for _, id := range ids {
+		r.remove(id)
+	}
+}
+
+// cleanupOnSignal removes the tracked containers if the process is
+// interrupted or terminated before the returned func is called, deferred
+// cleanups do not run then
+// The signal is raised again once they are removed, terminating the process
+func (r *buildResources) cleanupOnSignal() (stop func()) {
+	signals := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case sig := <-signals:
+			globals.GetLogger().Warnf("Build interrupted, removing build containers ...")
+			r.removeAll()
+			signal.Stop(signals)
+			if p, err := os.FindProcess(os.Getpid()); err != nil || p.Signal(sig) != nil {
+				os.Exit(1)
+			}
+		case <-done:
+		}
+	}()
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
+
+// PruneResult lists what Prune removed
+type PruneResult struct {
+	// Containers are the names of the removed build containers
+	Containers []string
+	// Images are the IDs of the removed images
+	Images []string
+}
+
+// Prune removes the build containers and the untagged build images left
+// behind by interrupted builds, see BuildContainerLabelKey and
+// BuildImageLabelKey, created more than olderThan ago
+// Build containers of builds still running are kept, see
+// BuildOwnerLabelKey
+func Prune(olderThan time.Duration) (*PruneResult, error) {
+	result := &PruneResult{}
+	cutoff := time.Now().Add(-olderThan)
+	lines, err := exec.OutputLines(exec.Command(
+		"docker", "ps", "-a",
+		"--filter", "label="+BuildContainerLabelKey,
+		"--format", fmt.Sprintf(`{{.Names}}\t{{.Label "%s"}}\t{{.Label "%s"}}`, BuildContainerLabelKey, BuildOwnerLabelKey),
+	))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list build containers")
+	}
+	for _, line := range lines {
+		parts := strings.SplitN(line, "\t", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		if ownerRunning(parts[2]) {
+			globals.GetLogger().V(1).Infof("Build container %s belongs to a running build, skipping", parts[0])
+			continue
+		}
+		// containers with an unknown creation time are considered old
+		if created, err := time.Parse(time.RFC3339Nano, parts[1]); err == nil && created.After(cutoff) {
+			continue
+		}
+		if err := exec.Command("docker", "rm", "-f", "-v", parts[0]).Run(); err != nil {
+			return result, errors.Wrapf(err, "failed to remove build container %s", parts[0])
+		}
+		result.Containers = append(result.Containers, parts[0])
+	}
+
+	args := []string{"image", "prune", "--force", "--filter", "label=" + BuildImageLabelKey}
+	if olderThan > 0 {
+		args = append(args, "--filter", "until="+olderThan.String())
+	}
+	lines, err = exec.OutputLines(exec.Command("docker", args...))
+	if err != nil {
+		return result, errors.Wrap(err, "failed to prune build images")
+	}
+	for _, line := range lines {
+		if id := strings.TrimPrefix(line, "deleted: "); id != line {
+			result.Images = append(result.Images, id)
+		}
+	}
+	return result, nil
+}
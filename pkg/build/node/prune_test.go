@@ -0,0 +1,82 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"fmt"
+	"os"
+	osexec "os/exec"
+	"testing"
+)
+
+func TestOwnerRunning(t *testing.T) {
+	t.Parallel()
+	host, err := os.Hostname()
+	if err != nil {
+		t.Fatalf("failed to get hostname: %v", err)
+	}
+	// a pid of a process that exited
+	exited := osexec.Command("true")
+	if err := exited.Run(); err != nil {
+		t.Fatalf("failed to run true: %v", err)
+	}
+	cases := []struct {
+		Name     string
+		Owner    string
+		Expected bool
+	}{
+		{
+			Name:     "this process",
+			Owner:    fmt.Sprintf("%s/%d", host, os.Getpid()),
+			Expected: true,
+		},
+		{
+			Name:     "parent process",
+			Owner:    fmt.Sprintf("%s/%d", host, os.Getppid()),
+			Expected: true,
+		},
+		{
+			Name:     "exited process",
+			Owner:    fmt.Sprintf("%s/%d", host, exited.Process.Pid),
+			Expected: false,
+		},
+		{
+			Name:     "another host",
+			Owner:    fmt.Sprintf("%s-other/%d", host, exited.Process.Pid),
+			Expected: true,
+		},
+		{
+			Name:     "not recorded",
+			Owner:    "",
+			Expected: false,
+		},
+		{
+			Name:     "invalid pid",
+			Owner:    host + "/bogus",
+			Expected: false,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			if result := ownerRunning(tc.Owner); result != tc.Expected {
+				t.Errorf("ownerRunning(%q) = %v, expected %v", tc.Owner, result, tc.Expected)
+			}
+		})
+	}
+}
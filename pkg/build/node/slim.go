@@ -0,0 +1,187 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"sigs.k8s.io/kind/pkg/build/node/internal/container/docker"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/globals"
+	"sigs.k8s.io/kind/pkg/internal/util/probe"
+)
+
+// cniBinDir is where the base image installs the CNI plugins
+const cniBinDir = "/opt/cni/bin"
+
+// validateTimeout bounds waiting for a built image to boot when validating it
+const validateTimeout = 2 * time.Minute
+
+// WithSkipImages configures the build to not preload the named images,
+// each either a full image reference or a repository matching any tag,
+// EG k8s.gcr.io/coredns or kindest/kindnetd:0.5.2
+//
+// Only images pulled during the build may be skipped, kubernetes images
+// built from source are always loaded. Skipped images are pulled by the
+// nodes on demand instead.
+func WithSkipImages(images ...string) Option {
+	return func(b *BuildContext) {
+		b.skipImages = append(b.skipImages, images...)
+	}
+}
+
+// WithStripDebugSymbols configures the build to strip debug symbols from the
+// kubernetes binaries
+func WithStripDebugSymbols(strip bool) Option {
+	return func(b *BuildContext) {
+		b.stripDebugSymbols = strip
+	}
+}
+
+// WithExcludeCNIPlugins configures the build to remove the named CNI plugin
+// binaries shipped in the base image, EG if a CNI other than the default is
+// always installed
+func WithExcludeCNIPlugins(plugins ...string) Option {
+	return func(b *BuildContext) {
+		b.excludeCNIPlugins = append(b.excludeCNIPlugins, plugins...)
+	}
+}
+
+// WithValidate configures the build to boot the built image once to
+// verify it still works, which is recommended when slimming the image
+func WithValidate(validate bool) Option {
+	return func(b *BuildContext) {
+		b.validate = validate
+	}
+}
+
+// skipImage returns true if image should not be preloaded
+func (c *BuildContext) skipImage(image string) bool {
+	repository, _, err := docker.SplitImage(image)
+	if err != nil {
+		return false
+	}
+	for _, skip := range c.skipImages {
+		if skip == image || skip == repository {
+			return true
+		}
+	}
+	return false
+}
+
+// stripBinaries strips debug symbols from the kubernetes binaries in the
+// build container, binutils are only installed for the duration
+func (c *BuildContext) stripBinaries(containerID string) error {
+	globals.GetLogger().V(0).Info("Stripping debug symbols from kubernetes binaries")
+	cmder := docker.ContainerCmder(containerID)
+	// resolve the binaries as they may be symlinked from /kind/bin
+	strip := "apt-get update && " +
+		"DEBIAN_FRONTEND=noninteractive apt-get install -y --no-install-recommends binutils && " +
+		`for b in kubeadm kubelet kubectl; do strip --strip-debug "$(readlink -f "/usr/bin/${b}")"; done && ` +
+		"DEBIAN_FRONTEND=noninteractive apt-get purge -y --auto-remove binutils && " +
+		"rm -rf /var/lib/apt/lists/*"
+	if err := exec.InheritOutput(cmder.Command("sh", "-c", strip)).Run(); err != nil {
+		return errors.Wrap(err, "failed to strip kubernetes binaries")
+	}
+	return nil
+}
+
+// removeCNIPlugins removes the excluded CNI plugin binaries from the build
+// container
+func (c *BuildContext) removeCNIPlugins(containerID string) error {
+	globals.GetLogger().V(0).Infof("Removing CNI plugins: %s", strings.Join(c.excludeCNIPlugins, ", "))
+	args := []string{"-f"}
+	for _, plugin := range c.excludeCNIPlugins {
+		if plugin == "" || strings.Contains(plugin, "/") {
+			return errors.Errorf("invalid CNI plugin name %q", plugin)
+		}
+		args = append(args, cniBinDir+"/"+plugin)
+	}
+	if err := docker.ContainerCmder(containerID).Command("rm", args...).Run(); err != nil {
+		return errors.Wrap(err, "failed to remove CNI plugins")
+	}
+	return nil
+}
+
+// validateImage boots the built image the same way cluster nodes are run,
+// and checks that systemd and the container runtime come up, the kubernetes
+// binaries run and the preloaded images are present
+func (c *BuildContext) validateImage(preloaded []string) error {
+	globals.GetLogger().V(0).Infof("Validating image %s boots ...", c.image)
+	containerID := "kind-build-validate-" + uuid.New().String()
+	c.resources.add(containerID)
+	defer c.resources.remove(containerID)
+	if err := exec.Command(
+		"docker", "run", "-d",
+		"--name", containerID,
+		"--label", buildLabel(BuildContainerLabelKey),
+		"--label", ownerLabel(),
+		// these match how nodes are run by the docker provider
+		"--privileged",
+		"--security-opt", "seccomp=unconfined",
+		"--tmpfs", "/tmp",
+		"--tmpfs", "/run",
+		"--volume", "/var",
+		"--volume", "/lib/modules:/lib/modules:ro",
+		c.image,
+	).Run(); err != nil {
+		return errors.Wrap(err, "failed to boot image")
+	}
+	cmder := docker.ContainerCmder(containerID)
+
+	// a degraded system is fine, the kubelet fails until kubeadm runs
+	result, err := probe.Until(time.Now().Add(validateTimeout), probe.DefaultBackoff, probe.Command(
+		func(ctx context.Context) exec.Cmd {
+			return exec.CommandWithContext(ctx, cmder, "systemctl", "is-system-running")
+		},
+		func(lines []string) error {
+			if len(lines) == 1 && (lines[0] == "running" || lines[0] == "degraded") {
+				return nil
+			}
+			return errors.Errorf("system is %q", strings.Join(lines, " "))
+		},
+	))
+	if err != nil {
+		return errors.Wrapf(err, "image did not boot after %d attempts", result.Attempts)
+	}
+
+	runtimeService := "containerd"
+	if c.containerRuntime == "cri-o" {
+		runtimeService = "crio"
+	}
+	checks := [][]string{
+		{"systemctl", "is-active", runtimeService},
+		{"kubeadm", "version"},
+		{"kubelet", "--version"},
+		{"kubectl", "version", "--client"},
+	}
+	for _, image := range preloaded {
+		checks = append(checks, []string{"crictl", "inspecti", image})
+	}
+	for _, check := range checks {
+		if out, err := exec.CombinedOutputLines(cmder.Command(check[0], check[1:]...)); err != nil {
+			return errors.Wrapf(err, "image validation %q failed: %s", strings.Join(check, " "), strings.Join(out, "\n"))
+		}
+	}
+	globals.GetLogger().V(0).Info("Image validated.")
+	return nil
+}
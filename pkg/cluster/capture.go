@@ -0,0 +1,144 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"io"
+	"strconv"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+)
+
+// DefaultCaptureDuration is how long Capture captures traffic by default
+const DefaultCaptureDuration = 30 * time.Second
+
+// DefaultCaptureMaxBytes bounds the size of a capture by default
+const DefaultCaptureMaxBytes = 100 << 20
+
+// captureScript runs tcpdump for a bounded duration, truncating its output
+// at a maximum size, the arguments are:
+// <duration-seconds> <interface> <max-bytes> [tcpdump-args...]
+// head is always used so the exit status is that of the pipe, not the
+// timeout that ended the capture
+const captureScript = `d="$1"; i="$2"; n="$3"; shift 3
+if [ "${n}" -gt 0 ]; then
+	timeout "${d}" tcpdump -i "${i}" -U -w - "$@" | head -c "${n}"
+else
+	timeout "${d}" tcpdump -i "${i}" -U -w - "$@" | cat
+fi`
+
+// CaptureOption is an option for Capture
+type CaptureOption func(*captureOptions)
+
+type captureOptions struct {
+	filter    string
+	iface     string
+	duration  time.Duration
+	maxBytes  int64
+	maxPacket int
+}
+
+// CaptureFilter configures a tcpdump filter expression, EG "port 6443"
+func CaptureFilter(filter string) CaptureOption {
+	return func(o *captureOptions) {
+		o.filter = filter
+	}
+}
+
+// CaptureInterface configures the node network interface to capture on,
+// by default all interfaces are captured
+func CaptureInterface(iface string) CaptureOption {
+	return func(o *captureOptions) {
+		o.iface = iface
+	}
+}
+
+// CaptureDuration configures how long to capture for,
+// see DefaultCaptureDuration
+func CaptureDuration(duration time.Duration) CaptureOption {
+	return func(o *captureOptions) {
+		o.duration = duration
+	}
+}
+
+// CaptureMaxBytes configures the maximum size of the capture, 0 for no
+// limit, see DefaultCaptureMaxBytes
+// The capture is truncated once reached, which may cut off the last packet
+func CaptureMaxBytes(maxBytes int64) CaptureOption {
+	return func(o *captureOptions) {
+		o.maxBytes = maxBytes
+	}
+}
+
+// CaptureMaxPackets configures the capture to stop after this many
+// packets, 0 for no limit
+func CaptureMaxPackets(packets int) CaptureOption {
+	return func(o *captureOptions) {
+		o.maxPacket = packets
+	}
+}
+
+// Capture captures network traffic on the named node of the cluster with
+// tcpdump, writing the capture in pcap format to w
+//
+// tcpdump is not part of the standard node image, nodes should use the
+// debug image variant, see `kind build node-image --debug-tools`
+func (p *Provider) Capture(name, nodeName string, w io.Writer, options ...CaptureOption) error {
+	o := &captureOptions{
+		iface:    "any",
+		duration: DefaultCaptureDuration,
+		maxBytes: DefaultCaptureMaxBytes,
+	}
+	for _, option := range options {
+		option(o)
+	}
+	if o.duration < time.Second {
+		return errors.Errorf("invalid capture duration %s, must be at least 1s", o.duration)
+	}
+	if o.maxBytes < 0 || o.maxPacket < 0 {
+		return errors.New("capture limits must not be negative")
+	}
+	node, err := p.node(name, nodeName)
+	if err != nil {
+		return err
+	}
+	if err := node.Command("sh", "-c", "command -v tcpdump").Run(); err != nil {
+		return errors.Errorf(
+			"tcpdump not found on node %s, create the cluster with a debug node image, see kind build node-image --debug-tools",
+			nodeName,
+		)
+	}
+	args := []string{
+		"-c", captureScript, "sh",
+		strconv.Itoa(int(o.duration.Seconds())), o.iface, strconv.FormatInt(o.maxBytes, 10),
+	}
+	if o.maxPacket > 0 {
+		args = append(args, "-c", strconv.Itoa(o.maxPacket))
+	}
+	if o.filter != "" {
+		args = append(args, o.filter)
+	}
+	cmd := node.Command("sh", args...)
+	return exec.RunWithStdoutReader(cmd, func(r io.Reader) error {
+		if _, err := io.Copy(w, r); err != nil {
+			return errors.Wrap(err, "failed to write capture")
+		}
+		return nil
+	})
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"time"
+
+	"sigs.k8s.io/kind/pkg/internal/cluster/changelog"
+)
+
+// Change actions, see Change.Action
+const (
+	// ChangeCreate is the cluster being created
+	ChangeCreate = changelog.ActionCreate
+	// ChangeReconfigure is nodes being reconfigured, EG by SetNodeResources
+	ChangeReconfigure = changelog.ActionReconfigure
+	// ChangeLoadImage is images being loaded into nodes
+	ChangeLoadImage = changelog.ActionLoadImage
+	// ChangeHeal is nodes being started again and repaired by Heal
+	ChangeHeal = changelog.ActionHeal
+	// ChangeFailover is a control plane being stopped by Failover
+	ChangeFailover = changelog.ActionFailover
+	// ChangeFailoverRestore is control planes being started again by
+	// RestoreFailover
+	ChangeFailoverRestore = changelog.ActionFailoverRestore
+	// ChangeRestore is nodes being restored from a checkpoint by Restore
+	ChangeRestore = changelog.ActionRestore
+	// ChangeFault is a service being crashed on a node by InjectFault
+	ChangeFault = changelog.ActionFault
+	// ChangeConnect is the cluster being connected to another cluster by
+	// ConnectClusters
+	ChangeConnect = changelog.ActionConnect
+)
+
+// ChangelogConfigMap is the kube-system ConfigMap the changelog is mirrored
+// to in the cluster, as JSON lines under the ChangelogConfigMapKey key
+const (
+	ChangelogConfigMap    = changelog.ConfigMapName
+	ChangelogConfigMapKey = changelog.ConfigMapKey
+)
+
+// Change is a mutation kind made to a cluster, see Provider.Changelog
+// The JSON encoding is stable, fields are only added
+type Change struct {
+	// Time is when the mutation was made
+	Time time.Time `json:"time"`
+	// Action is what was done, one of the Change* constants, EG ChangeCreate
+	Action string `json:"action"`
+	// Nodes are the names of the nodes mutated, if not the whole cluster
+	Nodes []string `json:"nodes,omitempty"`
+	// Details describe the mutation, depending on Action
+	Details map[string]string `json:"details,omitempty"`
+}
+
+// Changelog returns the mutations kind made to the cluster, oldest first.
+// They are kept in the cluster directory, see Provider.Path, and the latest
+// are mirrored to the ChangelogConfigMap in the cluster.
+func (p *Provider) Changelog(name string) ([]Change, error) {
+	entries, err := changelog.Read(p.ic(name))
+	if err != nil {
+		return nil, err
+	}
+	changes := make([]Change, 0, len(entries))
+	for _, e := range entries {
+		changes = append(changes, Change(e))
+	}
+	return changes, nil
+}
+
+// RecordChange records a mutation made to the cluster outside of Provider,
+// EG by loading images into nodes, in the cluster changelog.
+// Its Time is set to now if unset, failures are only logged.
+func (p *Provider) RecordChange(name string, change Change) {
+	changelog.Record(p.ic(name), changelog.Entry(change))
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/internal/cluster/kubeconfig"
+)
+
+// DefaultCheckpointName is the name of node checkpoints if none is specified
+const DefaultCheckpointName = "kind"
+
+// CheckpointOption is an option for Checkpoint and Restore
+type CheckpointOption func(*checkpointOptions)
+
+type checkpointOptions struct {
+	checkpoint string
+	nodeNames  []string
+}
+
+// CheckpointName configures the name of the checkpoint to create or restore,
+// see DefaultCheckpointName
+func CheckpointName(checkpoint string) CheckpointOption {
+	return func(o *checkpointOptions) {
+		o.checkpoint = checkpoint
+	}
+}
+
+// CheckpointNodes limits Checkpoint and Restore to the named nodes of the
+// cluster, by default all of the cluster's nodes are included
+func CheckpointNodes(nodeNames ...string) CheckpointOption {
+	return func(o *checkpointOptions) {
+		o.nodeNames = append(o.nodeNames, nodeNames...)
+	}
+}
+
+// Checkpoint saves the process state of the cluster's nodes using the
+// runtime's CRIU support and stops them, they can be resumed with Restore.
+// This is experimental and requires CRIU and an experimental runtime.
+func (p *Provider) Checkpoint(name string, options ...CheckpointOption) error {
+	opts, selected, err := p.checkpointNodes(name, options)
+	if err != nil {
+		return err
+	}
+	return p.provider.CheckpointNodes(selected, opts.checkpoint)
+}
+
+// Restore resumes the cluster's nodes from a checkpoint created by Checkpoint.
+// When restoring the whole cluster the kubeconfig is also updated in case
+// the API server host port changed.
+// This is experimental and requires CRIU and an experimental runtime.
+func (p *Provider) Restore(name string, options ...CheckpointOption) error {
+	opts, selected, err := p.checkpointNodes(name, options)
+	if err != nil {
+		return err
+	}
+	if err := p.provider.RestoreNodes(selected, opts.checkpoint); err != nil {
+		return err
+	}
+	restored := make([]string, 0, len(selected))
+	for _, n := range selected {
+		restored = append(restored, n.String())
+	}
+	p.RecordChange(name, Change{
+		Action:  ChangeRestore,
+		Nodes:   restored,
+		Details: map[string]string{"checkpoint": opts.checkpoint},
+	})
+	// nodes that were not restored may not be running
+	if len(opts.nodeNames) > 0 {
+		return nil
+	}
+	_, err = kubeconfig.Refresh(p.ic(name))
+	return err
+}
+
+// checkpointNodes resolves options and the nodes they select
+func (p *Provider) checkpointNodes(name string, options []CheckpointOption) (*checkpointOptions, []nodes.Node, error) {
+	opts := &checkpointOptions{
+		checkpoint: DefaultCheckpointName,
+	}
+	for _, o := range options {
+		o(opts)
+	}
+	if len(opts.nodeNames) == 0 {
+		allNodes, err := p.ListNodes(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(allNodes) == 0 {
+			return nil, nil, errors.Errorf("no nodes found for cluster %q", name)
+		}
+		return opts, allNodes, nil
+	}
+	selected := []nodes.Node{}
+	for _, nodeName := range opts.nodeNames {
+		n, err := p.node(name, nodeName)
+		if err != nil {
+			return nil, nil, err
+		}
+		selected = append(selected, n)
+	}
+	return opts, selected, nil
+}
@@ -0,0 +1,37 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+// ExportCompose returns a docker compose file describing the cluster's node
+// containers, their images, mounts, published ports and networks, to audit
+// or replicate what kind runs
+// The nodes are configured beyond what compose describes when the cluster is
+// created, so the compose file alone does not produce a working cluster
+func (p *Provider) ExportCompose(name string) ([]byte, error) {
+	n, err := p.ListNodes(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(n) == 0 {
+		return nil, errors.Errorf("unknown cluster %q", name)
+	}
+	return p.provider.Compose(n)
+}
@@ -27,7 +27,19 @@ const ClusterLabelKey = "io.k8s.sigs.kind.cluster"
 // of nodes by role
 const NodeRoleKey = "io.k8s.sigs.kind.role"
 
+// ProtectedLabelKey is applied to each "node" docker container of clusters
+// created with `kind create cluster --protect`, these are not deleted
+// unless forced
+const ProtectedLabelKey = "io.k8s.sigs.kind.protected"
+
+// ImageLabelKey is applied to each "node" docker container with the node
+// image as declared in the config, before it was replaced by its debug or
+// platform variant
+const ImageLabelKey = "io.k8s.sigs.kind.image"
+
 /* node role value constants */
+// Nodes may also have custom roles defined in the cluster config, these are
+// kubernetes worker nodes, see nodeutils.IsKubernetesRole
 const (
 	// ControlPlaneNodeRoleValue identifies a node that hosts a Kubernetes
 	// control-plane.
@@ -53,4 +65,18 @@ const (
 	// Please note that `kind` nodes hosting external etcd are not
 	// kubernetes nodes
 	ExternalEtcdNodeRoleValue string = "external-etcd"
+
+	// RegistryNodeRoleValue identifies a node that hosts an OCI registry
+	// on the cluster network, used as a mirror by the other nodes.
+	//
+	// Please note that `kind` nodes hosting a registry are not
+	// kubernetes nodes
+	RegistryNodeRoleValue string = "registry"
+
+	// AuxiliaryNodeRoleValue identifies a user configured auxiliary
+	// container kind runs on the cluster network, EG an NFS server.
+	//
+	// Please note that `kind` auxiliary containers are not
+	// kubernetes nodes
+	AuxiliaryNodeRoleValue string = "auxiliary"
 )
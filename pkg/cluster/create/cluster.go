@@ -17,10 +17,13 @@ limitations under the License.
 package create
 
 import (
+	"io"
 	"time"
 
 	"sigs.k8s.io/kind/pkg/apis/config/v1alpha3"
 	internalencoding "sigs.k8s.io/kind/pkg/internal/apis/config/encoding"
+	"sigs.k8s.io/kind/pkg/internal/cluster/create/actions"
+	"sigs.k8s.io/kind/pkg/internal/cluster/create/preflight"
 	internaltypes "sigs.k8s.io/kind/pkg/internal/cluster/create/types"
 )
 
@@ -36,6 +39,19 @@ func WithConfigFile(path string) ClusterOption {
 	}
 }
 
+// WithConfigTemplate configures creating the cluster using the config Go
+// template at path, rendered with the values merged from the yaml files at
+// valuesPaths, later files overriding earlier ones
+// The values are available to the template as .Values, along with sprig
+// like helpers such as default, required, quote, toYaml, nindent and until
+func WithConfigTemplate(path string, valuesPaths ...string) ClusterOption {
+	return func(o *internaltypes.ClusterOptions) (*internaltypes.ClusterOptions, error) {
+		var err error
+		o.Config, err = internalencoding.LoadTemplate(path, valuesPaths...)
+		return o, err
+	}
+}
+
 // WithV1Alpha3 configures creating the cluster with a v1alpha3 config
 func WithV1Alpha3(cluster *v1alpha3.Cluster) ClusterOption {
 	return func(o *internaltypes.ClusterOptions) (*internaltypes.ClusterOptions, error) {
@@ -69,6 +85,19 @@ func WaitForReady(interval time.Duration) ClusterOption {
 	}
 }
 
+// WaitForReadyNodes configures create to also wait for at least min
+// Kubernetes nodes, of any role, to be Ready when waiting for the cluster
+// to be ready, see WaitForReady
+// Other nodes are tolerated to be NotReady, EG workers that are broken
+// on purpose for negative tests
+// Creating fails if min is set without a wait time
+func WaitForReadyNodes(min int) ClusterOption {
+	return func(o *internaltypes.ClusterOptions) (*internaltypes.ClusterOptions, error) {
+		o.WaitMinNodes = min
+		return o, nil
+	}
+}
+
 // SetupKubernetes configures create command to setup kubernetes after creating nodes containers
 // TODO: Refactor this. It is a temporary solution for a phased breakdown of different
 //      operations, specifically create. see https://github.com/kubernetes-sigs/kind/issues/324
@@ -78,3 +107,121 @@ func SetupKubernetes(setupKubernetes bool) ClusterOption {
 		return o, nil
 	}
 }
+
+// Protect configures marking the cluster as protected, protected clusters
+// are not deleted unless forced
+func Protect(protect bool) ClusterOption {
+	return func(o *internaltypes.ClusterOptions) (*internaltypes.ClusterOptions, error) {
+		o.Protect = protect
+		return o, nil
+	}
+}
+
+// WithLoadKernelModules configures loading any kernel modules required by
+// the cluster config that are missing on the host, this typically requires
+// running as root.
+// Otherwise cluster creation fails if any are missing.
+func WithLoadKernelModules(load bool) ClusterOption {
+	return func(o *internaltypes.ClusterOptions) (*internaltypes.ClusterOptions, error) {
+		o.LoadKernelModules = load
+		return o, nil
+	}
+}
+
+// WithFixFirewall configures adding rules to the host's active firewalls
+// (firewalld, ufw and iptables) allowing all traffic on the cluster network,
+// which typically requires running as root.
+// The rules are removed when the cluster is deleted.
+func WithFixFirewall(fix bool) ClusterOption {
+	return func(o *internaltypes.ClusterOptions) (*internaltypes.ClusterOptions, error) {
+		o.FixFirewall = fix
+		return o, nil
+	}
+}
+
+// WithFixHostLimits configures raising the host's inotify and open file
+// limits (fs.inotify.* and fs.file-max) when they are too low for the nodes,
+// which typically requires running as root.
+// The original values are restored when the cluster is deleted, unless other
+// kind clusters remain.
+func WithFixHostLimits(fix bool) ClusterOption {
+	return func(o *internaltypes.ClusterOptions) (*internaltypes.ClusterOptions, error) {
+		o.FixHostLimits = fix
+		return o, nil
+	}
+}
+
+// WithInjectedFailure configures create to fail the phase on purpose, for
+// testing error handling and cleanup of tools building on kind.
+// If nodes are given only commands run on those nodes during the phase
+// fail, otherwise the phase fails before it runs, creating fails if the
+// phase runs no commands on nodes, such as "provision".
+// Phases are "provision" and the create actions, EG "kubeadmjoin", see also
+// the KIND_INJECT_FAILURES environment variable.
+func WithInjectedFailure(phase string, nodes ...string) ClusterOption {
+	return func(o *internaltypes.ClusterOptions) (*internaltypes.ClusterOptions, error) {
+		if o.Failures == nil {
+			o.Failures = actions.Failures{}
+		}
+		o.Failures.Add(phase, nodes...)
+		return o, nil
+	}
+}
+
+// WithPhaseTimeout configures how long waiting within the create phase may
+// take at most, EG for the cluster to be ready in "waitforready", phases
+// are named as for WithInjectedFailure.
+// This bounds the wait time of WaitForReady.
+func WithPhaseTimeout(phase string, timeout time.Duration) ClusterOption {
+	return func(o *internaltypes.ClusterOptions) (*internaltypes.ClusterOptions, error) {
+		if o.PhaseTimeouts == nil {
+			o.PhaseTimeouts = map[string]time.Duration{}
+		}
+		o.PhaseTimeouts[phase] = timeout
+		return o, nil
+	}
+}
+
+// WithPhaseObserver configures create to call observer with each phase
+// once it ends, with how long it took and whether it succeeded.
+// Phases are named as for WithInjectedFailure, plus "preflight" for the
+// preflight checks, phases running several actions are observed once per
+// action.
+func WithPhaseObserver(observer func(phase string, elapsed time.Duration, success bool)) ClusterOption {
+	return func(o *internaltypes.ClusterOptions) (*internaltypes.ClusterOptions, error) {
+		o.PhaseObserver = observer
+		return o, nil
+	}
+}
+
+// WithNodeConsole configures create to stream the output of the node
+// containers to w while creating them, as docker logs --follow does, each
+// line prefixed with the node name. This surfaces failures while the nodes
+// boot, EG of systemd services, as they happen.
+// Each line is written with a single Write, concurrent nodes don't interleave.
+func WithNodeConsole(w io.Writer) ClusterOption {
+	return func(o *internaltypes.ClusterOptions) (*internaltypes.ClusterOptions, error) {
+		o.NodeConsole = w
+		return o, nil
+	}
+}
+
+// WithImageScan configures create to scan the node images for vulnerabilities
+// with scanner before creating any nodes, failing if any vulnerability at or
+// above severity is found, or only warning if warnOnly is set.
+// If scanner is "" trivy is used, if severity is "" HIGH is used.
+func WithImageScan(scanner, severity string, warnOnly bool) ClusterOption {
+	return func(o *internaltypes.ClusterOptions) (*internaltypes.ClusterOptions, error) {
+		if severity != "" {
+			if _, err := preflight.SeveritiesAtOrAbove(severity); err != nil {
+				return o, err
+			}
+		}
+		o.ImageScan = &internaltypes.ImageScanOptions{
+			Scanner:  scanner,
+			Severity: severity,
+			WarnOnly: warnOnly,
+		}
+		return o, nil
+	}
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+
+	"sigs.k8s.io/kind/pkg/cluster/constants"
+	"sigs.k8s.io/kind/pkg/errors"
+
+	internalcontext "sigs.k8s.io/kind/pkg/internal/cluster/context"
+	"sigs.k8s.io/kind/pkg/internal/cluster/registry"
+)
+
+// DescriptorVersion is the version of the Descriptor format, it only
+// changes if fields are removed or change meaning
+const DescriptorVersion = "v1"
+
+// Descriptor is a stable machine readable description of a cluster for
+// integrations such as devcontainer features and IDE plugins.
+// It is written to the cluster directory when the cluster is created and
+// when RefreshKubeConfig updates the endpoints, see Provider.Path
+type Descriptor struct {
+	// Version is the descriptor format version, see DescriptorVersion
+	Version string `json:"version"`
+	// Name is the cluster name
+	Name string `json:"name"`
+	// Provider is the name of the provider of the cluster's nodes, EG docker,
+	// so that integrations need not guess which runtime to use
+	Provider string `json:"provider"`
+	// KubeConfigPath is the path to the cluster's host kubeconfig
+	KubeConfigPath string `json:"kubeconfigPath"`
+	// Dir is the directory kind keeps files for the cluster in
+	Dir string `json:"dir"`
+	// Registry is the URL of the cluster registry reachable from the host
+	// if it is published, otherwise from within the cluster network
+	Registry string `json:"registry,omitempty"`
+	// Endpoints are the API server, load balancer and node endpoints,
+	// including the ports published on the host
+	Endpoints
+}
+
+// Describe returns the descriptor for the cluster
+func (p *Provider) Describe(name string) (*Descriptor, error) {
+	endpoints, err := p.Endpoints(name)
+	if err != nil {
+		return nil, err
+	}
+	ic := p.ic(name)
+	descriptor := &Descriptor{
+		Version:        DescriptorVersion,
+		Name:           name,
+		Provider:       p.name,
+		KubeConfigPath: ic.KubeConfigPath(),
+		Dir:            ic.Dir(),
+		Registry:       registryURL(endpoints),
+		Endpoints:      *endpoints,
+	}
+	return descriptor, nil
+}
+
+// writeDescriptor updates the descriptor file in the cluster directory
+func (p *Provider) writeDescriptor(name string) error {
+	descriptor, err := p.Describe(name)
+	if err != nil {
+		return err
+	}
+	raw, err := json.MarshalIndent(descriptor, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to encode cluster descriptor")
+	}
+	return p.ic(name).WriteFile(internalcontext.DescriptorFile, append(raw, '\n'))
+}
+
+// registryURL returns the URL of the registry node, if any
+func registryURL(endpoints *Endpoints) string {
+	for _, n := range endpoints.Nodes {
+		if n.Role != constants.RegistryNodeRoleValue {
+			continue
+		}
+		for _, m := range n.PortMappings {
+			if m.ContainerPort != registry.Port {
+				continue
+			}
+			address := m.ListenAddress
+			if ip := net.ParseIP(address); address == "" || (ip != nil && ip.IsUnspecified()) {
+				address = "localhost"
+			}
+			return fmt.Sprintf("https://%s", net.JoinHostPort(address, strconv.Itoa(int(m.HostPort))))
+		}
+		return fmt.Sprintf("https://%s", net.JoinHostPort(n.Name, strconv.Itoa(registry.Port)))
+	}
+	return ""
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"testing"
+)
+
+func TestRegistryURL(t *testing.T) {
+	cases := []struct {
+		Name      string
+		Endpoints Endpoints
+		Expected  string
+	}{
+		{
+			Name: "no registry",
+			Endpoints: Endpoints{Nodes: []NodeEndpoints{
+				{Name: "kind-control-plane", Role: "control-plane"},
+			}},
+			Expected: "",
+		},
+		{
+			Name: "unpublished registry",
+			Endpoints: Endpoints{Nodes: []NodeEndpoints{
+				{Name: "kind-registry", Role: "registry"},
+			}},
+			Expected: "https://kind-registry:5000",
+		},
+		{
+			Name: "published registry",
+			Endpoints: Endpoints{Nodes: []NodeEndpoints{
+				{Name: "kind-registry", Role: "registry", PortMappings: []PortMapping{
+					{ContainerPort: 5000, HostPort: 5001, ListenAddress: "0.0.0.0", Protocol: "TCP"},
+				}},
+			}},
+			Expected: "https://localhost:5001",
+		},
+		{
+			Name: "published registry on ipv6",
+			Endpoints: Endpoints{Nodes: []NodeEndpoints{
+				{Name: "kind-registry", Role: "registry", PortMappings: []PortMapping{
+					{ContainerPort: 5000, HostPort: 5001, ListenAddress: "::1", Protocol: "TCP"},
+				}},
+			}},
+			Expected: "https://[::1]:5001",
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			if result := registryURL(&tc.Endpoints); result != tc.Expected {
+				t.Errorf("registryURL() = %q, expected %q", result, tc.Expected)
+			}
+		})
+	}
+}
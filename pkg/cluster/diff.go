@@ -0,0 +1,200 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/cluster/constants"
+	"sigs.k8s.io/kind/pkg/cluster/create"
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	internalcreate "sigs.k8s.io/kind/pkg/internal/cluster/create"
+)
+
+// Difference fields, see Difference.Field
+const (
+	// DiffNodes is the number of nodes with a role
+	DiffNodes = "nodes"
+	// DiffImage is the images of the nodes with a role
+	DiffImage = "image"
+	// DiffPortMapping is a port mapping of the nodes with a role
+	DiffPortMapping = "portMapping"
+)
+
+// Difference is a way a cluster differs from its declared config
+// The JSON encoding is stable, fields are only added
+type Difference struct {
+	// Role is the role of the nodes that differ
+	Role string `json:"role"`
+	// Field is what differs, one of the Diff* constants, EG DiffImage
+	Field string `json:"field"`
+	// Declared is the value in the config, if any
+	Declared string `json:"declared,omitempty"`
+	// Observed is the value of the cluster, if any
+	Observed string `json:"observed,omitempty"`
+}
+
+func (d Difference) String() string {
+	declared, observed := d.Declared, d.Observed
+	if declared == "" {
+		declared = "none"
+	}
+	if observed == "" {
+		observed = "none"
+	}
+	return fmt.Sprintf("%s %s: declared %s, observed %s", d.Role, d.Field, declared, observed)
+}
+
+// observedNode is what Diff compares of a node of the cluster
+type observedNode struct {
+	Role         string
+	Image        string
+	PortMappings []PortMapping
+}
+
+// Diff compares the cluster to the config the options select, as Create
+// would use them, EG create.WithConfigFile, reporting the nodes, images and
+// port mappings by node role that drifted from it, if any
+func (p *Provider) Diff(name string, options ...create.ClusterOption) ([]Difference, error) {
+	cfg, err := internalcreate.Config(options...)
+	if err != nil {
+		return nil, err
+	}
+	allNodes, err := p.ListNodes(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(allNodes) == 0 {
+		return nil, errors.Errorf("no nodes found for cluster %q", name)
+	}
+	observed := []observedNode{}
+	for _, n := range allNodes {
+		role, err := n.Role()
+		if err != nil {
+			return nil, err
+		}
+		stats, err := p.provider.GetNodeStats(n)
+		if err != nil {
+			return nil, err
+		}
+		mappings, err := n.PortMappings()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get port mappings for node: %s", n.String())
+		}
+		observed = append(observed, observedNode{Role: role, Image: stats.Image, PortMappings: mappings})
+	}
+	return diffNodes(cfg.Nodes, observed), nil
+}
+
+// diffNodes compares the declared nodes to the observed nodes by role,
+// nodes kind adds, the external load balancer, are not compared
+func diffNodes(declared []config.Node, observed []observedNode) []Difference {
+	declaredByRole := map[string][]config.Node{}
+	observedByRole := map[string][]observedNode{}
+	roles := []string{}
+	for _, n := range declared {
+		role := string(n.Role)
+		if _, ok := declaredByRole[role]; !ok {
+			roles = append(roles, role)
+		}
+		declaredByRole[role] = append(declaredByRole[role], n)
+	}
+	for _, n := range observed {
+		if n.Role == constants.ExternalLoadBalancerNodeRoleValue {
+			continue
+		}
+		if _, ok := declaredByRole[n.Role]; !ok {
+			if _, ok := observedByRole[n.Role]; !ok {
+				roles = append(roles, n.Role)
+			}
+		}
+		observedByRole[n.Role] = append(observedByRole[n.Role], n)
+	}
+	sort.Strings(roles)
+
+	diffs := []Difference{}
+	for _, role := range roles {
+		d, o := declaredByRole[role], observedByRole[role]
+		if len(d) != len(o) {
+			diffs = append(diffs, Difference{
+				Role: role, Field: DiffNodes,
+				Declared: strconv.Itoa(len(d)), Observed: strconv.Itoa(len(o)),
+			})
+		}
+		declaredImages, observedImages := []string{}, []string{}
+		for _, n := range d {
+			declaredImages = append(declaredImages, n.Image)
+		}
+		for _, n := range o {
+			observedImages = append(observedImages, n.Image)
+		}
+		if di, oi := uniqueSorted(declaredImages), uniqueSorted(observedImages); len(d) > 0 && len(o) > 0 && di != oi {
+			diffs = append(diffs, Difference{Role: role, Field: DiffImage, Declared: di, Observed: oi})
+		}
+		// random host ports are only compared by container port
+		for _, n := range d {
+			for _, m := range n.ExtraPortMappings {
+				if len(o) > 0 && !hasPortMapping(o, m) {
+					diffs = append(diffs, Difference{Role: role, Field: DiffPortMapping, Declared: formatPortMapping(m)})
+				}
+			}
+		}
+	}
+	return diffs
+}
+
+// hasPortMapping returns true if one of nodes publishes m
+func hasPortMapping(nodes []observedNode, m config.PortMapping) bool {
+	protocol := config.PortMappingProtocolValueToName[m.Protocol]
+	for _, n := range nodes {
+		for _, o := range n.PortMappings {
+			if o.ContainerPort == m.ContainerPort && strings.EqualFold(o.Protocol, protocol) &&
+				(m.HostPort == 0 || o.HostPort == m.HostPort) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// formatPortMapping formats m like docker run --publish
+func formatPortMapping(m config.PortMapping) string {
+	hostPort := "random"
+	if m.HostPort != 0 {
+		hostPort = strconv.Itoa(int(m.HostPort))
+	}
+	return fmt.Sprintf("%s:%d/%s", hostPort, m.ContainerPort, strings.ToLower(config.PortMappingProtocolValueToName[m.Protocol]))
+}
+
+// uniqueSorted returns the unique values sorted and comma separated
+func uniqueSorted(values []string) string {
+	seen := map[string]bool{}
+	unique := []string{}
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			unique = append(unique, v)
+		}
+	}
+	sort.Strings(unique)
+	return strings.Join(unique, ",")
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"reflect"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+func TestDiffNodes(t *testing.T) {
+	const image = "kindest/node:v1.27.3"
+	cases := []struct {
+		Name     string
+		Declared []config.Node
+		Observed []observedNode
+		Expected []Difference
+	}{
+		{
+			Name: "no drift",
+			Declared: []config.Node{
+				{Role: config.ControlPlaneRole, Image: image},
+				{Role: config.ControlPlaneRole, Image: image},
+				{Role: config.WorkerRole, Image: image, ExtraPortMappings: []config.PortMapping{
+					{ContainerPort: 80},
+				}},
+			},
+			Observed: []observedNode{
+				{Role: "control-plane", Image: image},
+				{Role: "control-plane", Image: image},
+				{Role: "external-load-balancer", Image: "kindest/haproxy"},
+				{Role: "worker", Image: image, PortMappings: []PortMapping{
+					{ContainerPort: 80, HostPort: 32768, Protocol: "TCP"},
+				}},
+			},
+			Expected: []Difference{},
+		},
+		{
+			Name: "worker removed",
+			Declared: []config.Node{
+				{Role: config.ControlPlaneRole, Image: image},
+				{Role: config.WorkerRole, Image: image},
+				{Role: config.WorkerRole, Image: image},
+			},
+			Observed: []observedNode{
+				{Role: "control-plane", Image: image},
+				{Role: "worker", Image: image},
+			},
+			Expected: []Difference{
+				{Role: "worker", Field: DiffNodes, Declared: "2", Observed: "1"},
+			},
+		},
+		{
+			Name: "undeclared role and image changed",
+			Declared: []config.Node{
+				{Role: config.ControlPlaneRole, Image: "kindest/node:v1.28.0"},
+			},
+			Observed: []observedNode{
+				{Role: "control-plane", Image: image},
+				{Role: "ingress", Image: image},
+			},
+			Expected: []Difference{
+				{Role: "control-plane", Field: DiffImage, Declared: "kindest/node:v1.28.0", Observed: image},
+				{Role: "ingress", Field: DiffNodes, Declared: "0", Observed: "1"},
+			},
+		},
+		{
+			Name: "port mapping missing",
+			Declared: []config.Node{
+				{Role: config.ControlPlaneRole, Image: image, ExtraPortMappings: []config.PortMapping{
+					{ContainerPort: 80, HostPort: 8080},
+					{ContainerPort: 53, Protocol: config.PortMappingProtocolUDP},
+				}},
+			},
+			Observed: []observedNode{
+				{Role: "control-plane", Image: image, PortMappings: []PortMapping{
+					{ContainerPort: 80, HostPort: 8081, Protocol: "TCP"},
+					{ContainerPort: 53, HostPort: 32768, Protocol: "TCP"},
+				}},
+			},
+			Expected: []Difference{
+				{Role: "control-plane", Field: DiffPortMapping, Declared: "8080:80/tcp"},
+				{Role: "control-plane", Field: DiffPortMapping, Declared: "random:53/udp"},
+			},
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			result := diffNodes(tc.Declared, tc.Observed)
+			if !reflect.DeepEqual(result, tc.Expected) {
+				t.Errorf("diffNodes() = %v, expected %v", result, tc.Expected)
+			}
+		})
+	}
+}
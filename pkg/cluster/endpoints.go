@@ -0,0 +1,131 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"net"
+	"strconv"
+
+	"sigs.k8s.io/kind/pkg/cluster/constants"
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/internal/cluster/providers/provider/common"
+)
+
+// Endpoints describes how a cluster and its nodes may be reached
+type Endpoints struct {
+	// APIServer is the API server endpoint reachable from the host
+	APIServer string `json:"apiServer"`
+	// APIServerInternal is the API server endpoint reachable from
+	// within the cluster network
+	APIServerInternal string `json:"apiServerInternal"`
+	// LoadBalancer is the address of the external load balancer node, if any
+	LoadBalancer string `json:"loadBalancer,omitempty"`
+	// Nodes contains the endpoints of each node in the cluster
+	Nodes []NodeEndpoints `json:"nodes"`
+}
+
+// NodeEndpoints describes how a single node may be reached
+type NodeEndpoints struct {
+	// Name is the name of the node
+	Name string `json:"name"`
+	// Role is the role of the node, see also: pkg/cluster/constants
+	Role string `json:"role"`
+	// IPv4 is the node's container IPv4 address, if any
+	IPv4 string `json:"ipv4,omitempty"`
+	// IPv6 is the node's container IPv6 address, if any
+	IPv6 string `json:"ipv6,omitempty"`
+	// PortMappings are the ports published from the node on the host
+	PortMappings []PortMapping `json:"portMappings,omitempty"`
+}
+
+// PortMapping is a port published from a node on the host
+type PortMapping = nodes.PortMapping
+
+// GetAPIServerEndpoint returns the host:port the cluster's API server is
+// published on the host, IE that of the load balancer in HA clusters
+func (p *Provider) GetAPIServerEndpoint(name string) (string, error) {
+	return p.ic(name).GetAPIServerEndpoint()
+}
+
+// Endpoints returns the API server, load balancer and node endpoints
+// for the cluster
+func (p *Provider) Endpoints(name string) (*Endpoints, error) {
+	allNodes, err := p.ListNodes(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(allNodes) == 0 {
+		return nil, errors.Errorf("no nodes found for cluster %q", name)
+	}
+
+	endpoints := &Endpoints{}
+	endpoints.APIServer, err = p.GetAPIServerEndpoint(name)
+	if err != nil {
+		return nil, err
+	}
+
+	// the internal endpoint is on the load balancer or bootstrap node,
+	// prefer IPv4 when the node has both
+	apiNode, err := nodeutils.APIServerEndpointNode(allNodes)
+	if err != nil {
+		return nil, err
+	}
+	ipv4, ipv6, err := apiNode.IP()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get IPs for node: %s", apiNode.String())
+	}
+	apiIP := ipv4
+	if apiIP == "" {
+		apiIP = ipv6
+	}
+	endpoints.APIServerInternal = net.JoinHostPort(apiIP, strconv.Itoa(common.APIServerInternalPort))
+
+	for _, n := range allNodes {
+		role, err := n.Role()
+		if err != nil {
+			return nil, err
+		}
+		ipv4, ipv6, err := n.IP()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get IPs for node: %s", n.String())
+		}
+		if role == constants.ExternalLoadBalancerNodeRoleValue {
+			endpoints.LoadBalancer = ipv4
+			if endpoints.LoadBalancer == "" {
+				endpoints.LoadBalancer = ipv6
+			}
+		}
+		mappings, err := n.PortMappings()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get port mappings for node: %s", n.String())
+		}
+		nodeEndpoints := NodeEndpoints{
+			Name: n.String(),
+			Role: role,
+			IPv4: ipv4,
+			IPv6: ipv6,
+		}
+		if len(mappings) > 0 {
+			nodeEndpoints.PortMappings = mappings
+		}
+		endpoints.Nodes = append(endpoints.Nodes, nodeEndpoints)
+	}
+	return endpoints, nil
+}
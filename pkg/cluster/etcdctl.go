@@ -0,0 +1,99 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"io"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+// EtcdctlOption is an option for Etcdctl
+type EtcdctlOption func(*etcdctlOptions)
+
+type etcdctlOptions struct {
+	node   string
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// EtcdctlNode configures Etcdctl to run against the etcd member on the
+// named control plane node instead of the bootstrap control plane node
+func EtcdctlNode(name string) EtcdctlOption {
+	return func(o *etcdctlOptions) {
+		o.node = name
+	}
+}
+
+// EtcdctlStreams configures the streams Etcdctl is attached to, any of
+// them may be nil
+func EtcdctlStreams(stdin io.Reader, stdout, stderr io.Writer) EtcdctlOption {
+	return func(o *etcdctlOptions) {
+		o.stdin = stdin
+		o.stdout = stdout
+		o.stderr = stderr
+	}
+}
+
+// Etcdctl runs etcdctl with args in the etcd static pod of one of the
+// cluster's control plane nodes, with the member's endpoint and client
+// certificates preconfigured
+func (p *Provider) Etcdctl(name string, args []string, options ...EtcdctlOption) error {
+	o := &etcdctlOptions{}
+	for _, option := range options {
+		option(o)
+	}
+
+	controlPlanes, err := p.controlPlanes(name)
+	if err != nil {
+		return err
+	}
+	var node nodes.Node
+	if o.node == "" {
+		node, err = nodeutils.BootstrapControlPlaneNode(controlPlanes)
+		if err != nil {
+			return err
+		}
+	} else {
+		for _, n := range controlPlanes {
+			if n.String() == o.node {
+				node = n
+			}
+		}
+		if node == nil {
+			return errors.Errorf("unknown control plane node %q for cluster %q", o.node, name)
+		}
+	}
+
+	cmd, err := nodeutils.EtcdctlCommand(node, args...)
+	if err != nil {
+		return err
+	}
+	if o.stdin != nil {
+		cmd.SetStdin(o.stdin)
+	}
+	if o.stdout != nil {
+		cmd.SetStdout(o.stdout)
+	}
+	if o.stderr != nil {
+		cmd.SetStderr(o.stderr)
+	}
+	return cmd.Run()
+}
@@ -0,0 +1,277 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+
+	"sigs.k8s.io/kind/pkg/internal/util/probe"
+)
+
+// DefaultFailoverTimeout is how long Failover and RestoreFailover wait for
+// the control plane to recover by default
+const DefaultFailoverTimeout = 2 * time.Minute
+
+// FailoverReport describes stopping or restarting control plane nodes and
+// how long the control plane took to recover
+type FailoverReport struct {
+	// Nodes are the control plane nodes that were stopped or started
+	Nodes []string
+	// PreviousLeader is the etcd leader before the nodes were stopped or
+	// started, if there was one and it was looked up, Failover only looks
+	// it up when no FailoverNode is given
+	PreviousLeader string
+	// Leader is the etcd leader after recovery
+	Leader string
+	// LeaderElected is how long it took until there was an etcd leader
+	LeaderElected time.Duration
+	// APIServerAvailable is how long it took until the API server was
+	// available through the cluster's control plane endpoint
+	APIServerAvailable time.Duration
+}
+
+// FailoverOption is an option for Failover and RestoreFailover
+type FailoverOption func(*failoverOptions)
+
+type failoverOptions struct {
+	node    string
+	timeout time.Duration
+}
+
+// FailoverNode configures Failover to stop the named control plane node
+// instead of the etcd leader
+func FailoverNode(name string) FailoverOption {
+	return func(o *failoverOptions) {
+		o.node = name
+	}
+}
+
+// FailoverTimeout configures how long to wait for the control plane to
+// recover, see DefaultFailoverTimeout
+func FailoverTimeout(timeout time.Duration) FailoverOption {
+	return func(o *failoverOptions) {
+		o.timeout = timeout
+	}
+}
+
+// Failover stops the control plane node hosting the etcd leader, then waits
+// for a new leader to be elected and the API server to be available again
+// The stopped node may be started again with RestoreFailover
+func (p *Provider) Failover(name string, options ...FailoverOption) (*FailoverReport, error) {
+	o := newFailoverOptions(options)
+	controlPlanes, err := p.controlPlanes(name)
+	if err != nil {
+		return nil, err
+	}
+	// etcd only keeps quorum with a member stopped if it has at least three
+	if len(controlPlanes) < 3 {
+		return nil, errors.Errorf("failover requires a cluster with at least three control-plane nodes, cluster %q has %d", name, len(controlPlanes))
+	}
+
+	report := &FailoverReport{}
+	var target nodes.Node
+	if o.node == "" {
+		leader, err := etcdLeader(controlPlanes)
+		if err != nil {
+			return nil, err
+		}
+		target = leader
+		report.PreviousLeader = leader.String()
+	} else {
+		for _, n := range controlPlanes {
+			if n.String() == o.node {
+				target = n
+			}
+		}
+		if target == nil {
+			return nil, errors.Errorf("unknown control-plane node %q", o.node)
+		}
+	}
+	report.Nodes = []string{target.String()}
+
+	remaining := []nodes.Node{}
+	for _, n := range controlPlanes {
+		if n.String() != target.String() {
+			remaining = append(remaining, n)
+		}
+	}
+
+	if err := p.provider.StopNodes([]nodes.Node{target}); err != nil {
+		return nil, err
+	}
+	// record once the API server is back, or given up on
+	err = waitForRecovery(report, remaining, false, o.timeout)
+	p.RecordChange(name, Change{Action: ChangeFailover, Nodes: report.Nodes})
+	if err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+// RestoreFailover starts any stopped control plane nodes, then waits for
+// them to rejoin etcd and for the API server to be available
+func (p *Provider) RestoreFailover(name string, options ...FailoverOption) (*FailoverReport, error) {
+	o := newFailoverOptions(options)
+	controlPlanes, err := p.controlPlanes(name)
+	if err != nil {
+		return nil, err
+	}
+
+	// nodes we cannot run commands on are not running
+	stopped := []nodes.Node{}
+	running := []nodes.Node{}
+	for _, n := range controlPlanes {
+		if err := n.Command("true").Run(); err != nil {
+			stopped = append(stopped, n)
+		} else {
+			running = append(running, n)
+		}
+	}
+	if len(stopped) == 0 {
+		return nil, errors.Errorf("no stopped control-plane nodes found for cluster %q", name)
+	}
+
+	report := &FailoverReport{}
+	for _, n := range stopped {
+		report.Nodes = append(report.Nodes, n.String())
+	}
+	// there may not be a leader if quorum was lost
+	if leader, err := etcdLeader(running); err == nil {
+		report.PreviousLeader = leader.String()
+	}
+	if err := p.provider.StartNodes(stopped); err != nil {
+		return nil, err
+	}
+	err = waitForRecovery(report, controlPlanes, true, o.timeout)
+	p.RecordChange(name, Change{Action: ChangeFailoverRestore, Nodes: report.Nodes})
+	if err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+func newFailoverOptions(options []FailoverOption) *failoverOptions {
+	o := &failoverOptions{
+		timeout: DefaultFailoverTimeout,
+	}
+	for _, option := range options {
+		option(o)
+	}
+	return o
+}
+
+func (p *Provider) controlPlanes(name string) ([]nodes.Node, error) {
+	allNodes, err := p.ListNodes(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(allNodes) == 0 {
+		return nil, errors.Errorf("no nodes found for cluster %q", name)
+	}
+	return nodeutils.ControlPlaneNodes(allNodes)
+}
+
+// etcdLeader returns the control plane node hosting the etcd leader
+func etcdLeader(controlPlanes []nodes.Node) (nodes.Node, error) {
+	for _, n := range controlPlanes {
+		if isLeader, err := nodeutils.IsEtcdLeader(n); err == nil && isLeader {
+			return n, nil
+		}
+	}
+	return nil, errors.New("failed to find the etcd leader")
+}
+
+// waitForRecovery waits until there is an etcd leader among controlPlanes,
+// and all of their members are serving if requireAll is set, then until
+// the API server is available, recording how long each took in report
+func waitForRecovery(report *FailoverReport, controlPlanes []nodes.Node, requireAll bool, timeout time.Duration) error {
+	// probe at a constant interval to keep the recorded timings accurate
+	backoff := probe.Constant(500 * time.Millisecond)
+	start := time.Now()
+	deadline := start.Add(timeout)
+
+	// probes are cancelled at the deadline, so only successful probes
+	// record anything
+	leader := ""
+	if _, err := probe.Until(deadline, backoff, func(ctx context.Context) error {
+		elected := ""
+		serving := 0
+		var lastErr error
+		for _, n := range controlPlanes {
+			isLeader, err := nodeutils.IsEtcdLeader(&contextNode{Node: n, ctx: ctx})
+			if err != nil {
+				lastErr = errors.Wrapf(err, "etcd on node %s is not serving", n.String())
+				continue
+			}
+			serving++
+			if isLeader {
+				elected = n.String()
+			}
+		}
+		if elected == "" {
+			return errors.New("no etcd leader elected")
+		}
+		if requireAll && serving != len(controlPlanes) {
+			return lastErr
+		}
+		leader = elected
+		return nil
+	}); err != nil {
+		return errors.Wrap(err, "failed waiting for an etcd leader")
+	}
+	report.Leader = leader
+	report.LeaderElected = time.Since(start)
+
+	// the admin kubeconfig points at the control plane endpoint, so this
+	// also exercises the load balancer
+	if _, err := probe.Until(deadline, backoff, func(ctx context.Context) error {
+		var lastErr error
+		for _, n := range controlPlanes {
+			lastErr = probe.Command(func(ctx context.Context) exec.Cmd {
+				return exec.CommandWithContext(ctx, n,
+					"kubectl", "--kubeconfig=/etc/kubernetes/admin.conf",
+					"get", "--raw=/healthz",
+				)
+			}, nil)(ctx)
+			if lastErr == nil {
+				return nil
+			}
+		}
+		return lastErr
+	}); err != nil {
+		return errors.Wrap(err, "failed waiting for the API server")
+	}
+	report.APIServerAvailable = time.Since(start)
+	return nil
+}
+
+// contextNode is a node whose commands are bound to ctx, for helpers taking
+// a node such as nodeutils.IsEtcdLeader
+type contextNode struct {
+	nodes.Node
+	ctx context.Context
+}
+
+func (n *contextNode) Command(command string, args ...string) exec.Cmd {
+	return exec.CommandWithContext(n.ctx, n.Node, command, args...)
+}
@@ -0,0 +1,309 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/cluster/constants"
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+
+	"sigs.k8s.io/kind/pkg/internal/cluster/providers/provider"
+)
+
+// fakeCluster is a provider for a single cluster of fake nodes, which
+// elects an etcd leader among its running control planes
+type fakeCluster struct {
+	// unimplemented methods panic
+	provider.Provider
+	mu     sync.Mutex
+	nodes  []*fakeNode
+	leader string
+	// events are the node stops and starts and the etcd metrics reads,
+	// in order
+	events []string
+}
+
+func newFakeCluster(controlPlanes int) *fakeCluster {
+	c := &fakeCluster{}
+	for i := 1; i <= controlPlanes; i++ {
+		name := "kind-control-plane"
+		if i > 1 {
+			name = fmt.Sprintf("%s%d", name, i)
+		}
+		c.nodes = append(c.nodes, &fakeNode{cluster: c, name: name, role: constants.ControlPlaneNodeRoleValue})
+	}
+	c.nodes = append(c.nodes, &fakeNode{cluster: c, name: "kind-worker", role: constants.WorkerNodeRoleValue})
+	c.leader = "kind-control-plane"
+	return c
+}
+
+func (c *fakeCluster) ListNodes(cluster string) ([]nodes.Node, error) {
+	allNodes := []nodes.Node{}
+	for _, n := range c.nodes {
+		allNodes = append(allNodes, n)
+	}
+	return allNodes, nil
+}
+
+func (c *fakeCluster) StopNodes(stop []nodes.Node) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, n := range stop {
+		n.(*fakeNode).stopped = true
+		c.events = append(c.events, "stop "+n.String())
+		if n.String() == c.leader {
+			c.leader = ""
+		}
+	}
+	// the remaining members elect a new leader
+	for _, n := range c.nodes {
+		if c.leader == "" && !n.stopped && n.role == constants.ControlPlaneNodeRoleValue {
+			c.leader = n.name
+		}
+	}
+	return nil
+}
+
+func (c *fakeCluster) GetAPIServerEndpoint(cluster string) (string, error) {
+	return "127.0.0.1:6443", nil
+}
+
+func (c *fakeCluster) StartNodes(start []nodes.Node) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, n := range start {
+		n.(*fakeNode).stopped = false
+		c.events = append(c.events, "start "+n.String())
+	}
+	return nil
+}
+
+type fakeNode struct {
+	cluster *fakeCluster
+	name    string
+	role    string
+	ipv4    string
+	stopped bool
+	// files are read with cat and written with cp /dev/stdin, by path
+	files map[string]string
+}
+
+func (n *fakeNode) String() string                             { return n.name }
+func (n *fakeNode) Role() (string, error)                      { return n.role, nil }
+func (n *fakeNode) IP() (string, string, error)                { return n.ipv4, "", nil }
+func (n *fakeNode) PortMappings() ([]nodes.PortMapping, error) { return nil, nil }
+
+func (n *fakeNode) Command(command string, args ...string) exec.Cmd {
+	return &fakeNodeCmd{node: n, command: command, args: args, stdin: strings.NewReader(""), stdout: ioutil.Discard}
+}
+
+// fakeNodeCmd fails on stopped nodes, serves etcd metrics for curl and the
+// node's files for cat and cp and otherwise succeeds
+type fakeNodeCmd struct {
+	node    *fakeNode
+	command string
+	args    []string
+	stdin   io.Reader
+	stdout  io.Writer
+}
+
+func (c *fakeNodeCmd) Run() error {
+	cluster := c.node.cluster
+	cluster.mu.Lock()
+	defer cluster.mu.Unlock()
+	if c.node.stopped {
+		return &exec.RunError{
+			Command: append([]string{c.command}, c.args...),
+			Inner:   errors.Errorf("node %s is not running", c.node.name),
+		}
+	}
+	switch {
+	case c.command == "curl":
+		cluster.events = append(cluster.events, "metrics "+c.node.name)
+		isLeader := 0
+		if cluster.leader == c.node.name {
+			isLeader = 1
+		}
+		_, err := fmt.Fprintf(c.stdout, "etcd_server_is_leader %d\n", isLeader)
+		return err
+	case c.command == "cat" && len(c.args) == 1:
+		_, err := io.WriteString(c.stdout, c.node.files[c.args[0]])
+		return err
+	case c.command == "cp" && len(c.args) == 2 && c.args[0] == "/dev/stdin":
+		content, err := ioutil.ReadAll(c.stdin)
+		if err != nil {
+			return err
+		}
+		if c.node.files == nil {
+			c.node.files = map[string]string{}
+		}
+		c.node.files[c.args[1]] = string(content)
+	}
+	return nil
+}
+
+func (c *fakeNodeCmd) SetEnv(...string) exec.Cmd      { return c }
+func (c *fakeNodeCmd) SetStdin(r io.Reader) exec.Cmd  { c.stdin = r; return c }
+func (c *fakeNodeCmd) SetStdout(w io.Writer) exec.Cmd { c.stdout = w; return c }
+func (c *fakeNodeCmd) SetStderr(io.Writer) exec.Cmd   { return c }
+
+// withTempHome points HOME at a temporary directory for the cluster
+// directory, returning a func restoring it
+func withTempHome(t *testing.T) func() {
+	dir, err := ioutil.TempDir("", "kind-home")
+	if err != nil {
+		t.Fatal(err)
+	}
+	home := os.Getenv("HOME")
+	if err := os.Setenv("HOME", dir); err != nil {
+		t.Fatal(err)
+	}
+	return func() {
+		os.Setenv("HOME", home)
+		os.RemoveAll(dir)
+	}
+}
+
+func TestFailover(t *testing.T) {
+	defer withTempHome(t)()
+	cases := []struct {
+		Name                   string
+		ControlPlanes          int
+		Options                []FailoverOption
+		ExpectError            bool
+		ExpectedNodes          []string
+		ExpectedPreviousLeader string
+		ExpectedLeader         string
+		// ExpectedFirstEvent checks whether the leader was looked up
+		// before stopping a node
+		ExpectedFirstEvent string
+	}{
+		{
+			Name:          "too few control planes",
+			ControlPlanes: 2,
+			ExpectError:   true,
+		},
+		{
+			Name:                   "leader",
+			ControlPlanes:          3,
+			ExpectedNodes:          []string{"kind-control-plane"},
+			ExpectedPreviousLeader: "kind-control-plane",
+			ExpectedLeader:         "kind-control-plane2",
+			ExpectedFirstEvent:     "metrics kind-control-plane",
+		},
+		{
+			Name:               "named node",
+			ControlPlanes:      3,
+			Options:            []FailoverOption{FailoverNode("kind-control-plane3")},
+			ExpectedNodes:      []string{"kind-control-plane3"},
+			ExpectedLeader:     "kind-control-plane",
+			ExpectedFirstEvent: "stop kind-control-plane3",
+		},
+		{
+			Name:          "unknown node",
+			ControlPlanes: 3,
+			Options:       []FailoverOption{FailoverNode("kind-worker")},
+			ExpectError:   true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			fake := newFakeCluster(tc.ControlPlanes)
+			p := &Provider{provider: fake}
+			options := append([]FailoverOption{FailoverTimeout(10 * time.Second)}, tc.Options...)
+			report, err := p.Failover("kind", options...)
+			if tc.ExpectError {
+				if err == nil {
+					t.Fatalf("expected Failover() to fail")
+				}
+				for _, e := range fake.events {
+					if strings.HasPrefix(e, "stop ") {
+						t.Errorf("expected no nodes to be stopped, got %q", e)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(report.Nodes, tc.ExpectedNodes) {
+				t.Errorf("Nodes = %v, expected %v", report.Nodes, tc.ExpectedNodes)
+			}
+			if report.PreviousLeader != tc.ExpectedPreviousLeader {
+				t.Errorf("PreviousLeader = %q, expected %q", report.PreviousLeader, tc.ExpectedPreviousLeader)
+			}
+			if report.Leader != tc.ExpectedLeader {
+				t.Errorf("Leader = %q, expected %q", report.Leader, tc.ExpectedLeader)
+			}
+			if fake.events[0] != tc.ExpectedFirstEvent {
+				t.Errorf("first event = %q, expected %q", fake.events[0], tc.ExpectedFirstEvent)
+			}
+		})
+	}
+}
+
+func TestRestoreFailover(t *testing.T) {
+	defer withTempHome(t)()
+	fake := newFakeCluster(3)
+	p := &Provider{provider: fake}
+	if _, err := p.RestoreFailover("kind"); err == nil {
+		t.Errorf("expected RestoreFailover() to fail without stopped nodes")
+	}
+
+	if _, err := p.Failover("kind", FailoverTimeout(10*time.Second)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	report, err := p.RestoreFailover("kind", FailoverTimeout(10*time.Second))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := []string{"kind-control-plane"}; !reflect.DeepEqual(report.Nodes, expected) {
+		t.Errorf("Nodes = %v, expected %v", report.Nodes, expected)
+	}
+	if report.PreviousLeader != "kind-control-plane2" {
+		t.Errorf("PreviousLeader = %q, expected %q", report.PreviousLeader, "kind-control-plane2")
+	}
+	for _, n := range fake.nodes {
+		if n.stopped {
+			t.Errorf("expected node %s to be running", n.name)
+		}
+	}
+
+	changes, err := p.Changelog("kind")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	actions := []string{}
+	for _, c := range changes {
+		actions = append(actions, c.Action)
+	}
+	if expected := []string{ChangeFailover, ChangeFailoverRestore}; !reflect.DeepEqual(actions, expected) {
+		t.Errorf("changelog actions = %v, expected %v", actions, expected)
+	}
+}
@@ -0,0 +1,185 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/globals"
+)
+
+// DefaultFaultDowntime is how long an injected fault keeps a service down
+// before systemd restarts it by default
+const DefaultFaultDowntime = 10 * time.Second
+
+// DefaultFaultUptime is how long a crash looping service is left running
+// between crashes by default
+const DefaultFaultUptime = 10 * time.Second
+
+// FaultServices are the node services faults may be injected into
+var FaultServices = []string{"kubelet", "containerd", "crio"}
+
+// faultDropIn is the systemd drop-in controlling restarts of a faulted
+// service, it lives under /run so it never outlives the node container
+const faultDropIn = "/run/systemd/system/%s.service.d/99-kind-fault.conf"
+
+// FaultOption is an option for InjectFault
+type FaultOption func(*faultOptions)
+
+type faultOptions struct {
+	crashes  int
+	downtime time.Duration
+	uptime   time.Duration
+}
+
+// FaultCrashLoop configures InjectFault to crash the service crashes times
+func FaultCrashLoop(crashes int) FaultOption {
+	return func(o *faultOptions) {
+		o.crashes = crashes
+	}
+}
+
+// FaultDowntime configures how long the service stays down after each
+// crash, see DefaultFaultDowntime
+func FaultDowntime(downtime time.Duration) FaultOption {
+	return func(o *faultOptions) {
+		o.downtime = downtime
+	}
+}
+
+// FaultUptime configures how long the service runs between crashes,
+// see DefaultFaultUptime
+func FaultUptime(uptime time.Duration) FaultOption {
+	return func(o *faultOptions) {
+		o.uptime = uptime
+	}
+}
+
+// InjectFault repeatedly kills service on the named node of the cluster,
+// using a systemd drop-in so that systemd restarts it after a fixed downtime,
+// then restores the service's normal restart behavior and ensures it is
+// running again
+func (p *Provider) InjectFault(name, nodeName, service string, options ...FaultOption) (err error) {
+	o := &faultOptions{
+		crashes:  1,
+		downtime: DefaultFaultDowntime,
+		uptime:   DefaultFaultUptime,
+	}
+	for _, option := range options {
+		option(o)
+	}
+	if o.crashes < 1 {
+		return errors.Errorf("invalid crash count %d, must be at least 1", o.crashes)
+	}
+	if !isFaultService(service) {
+		return errors.Errorf("unknown service %q, must be one of %s", service, strings.Join(FaultServices, ", "))
+	}
+	node, err := p.node(name, nodeName)
+	if err != nil {
+		return err
+	}
+
+	// systemd restarts the service itself once downtime has passed, without
+	// rate limiting so that long crash loops are never given up on
+	dropIn := fmt.Sprintf(faultDropIn, service)
+	if err := nodeutils.WriteFile(node, dropIn, fmt.Sprintf(
+		"[Unit]\nStartLimitIntervalSec=0\n[Service]\nRestart=always\nRestartSec=%dms\n",
+		o.downtime.Milliseconds(),
+	)); err != nil {
+		return errors.Wrap(err, "failed to write systemd drop-in")
+	}
+	defer func() {
+		if restoreErr := restoreFaultService(node, service, dropIn); restoreErr != nil && err == nil {
+			err = restoreErr
+		}
+		// record once the service is restored, or failed to be
+		p.RecordChange(name, Change{
+			Action: ChangeFault,
+			Nodes:  []string{node.String()},
+			Details: map[string]string{
+				"service": service,
+				"crashes": strconv.Itoa(o.crashes),
+			},
+		})
+	}()
+	if err := node.Command("systemctl", "daemon-reload").Run(); err != nil {
+		return errors.Wrap(err, "failed to reload systemd")
+	}
+
+	logger := globals.GetLogger()
+	for i := 1; i <= o.crashes; i++ {
+		logger.V(0).Infof("Killing %s on %s (%d/%d)", service, node.String(), i, o.crashes)
+		if err := node.Command("systemctl", "kill", "--signal=SIGKILL", service).Run(); err != nil {
+			return errors.Wrapf(err, "failed to kill %s", service)
+		}
+		time.Sleep(o.downtime)
+		// there is no need to wait for the service to run again after the
+		// final crash, restoring the service starts it
+		if i < o.crashes {
+			time.Sleep(o.uptime)
+		}
+	}
+	logger.V(0).Infof("Restoring %s on %s", service, node.String())
+	return nil
+}
+
+// restoreFaultService removes the fault drop-in and ensures service is running
+func restoreFaultService(node nodes.Node, service, dropIn string) error {
+	if err := node.Command("rm", "-f", dropIn).Run(); err != nil {
+		return errors.Wrap(err, "failed to remove systemd drop-in")
+	}
+	if err := node.Command("systemctl", "daemon-reload").Run(); err != nil {
+		return errors.Wrap(err, "failed to reload systemd")
+	}
+	if err := node.Command("systemctl", "reset-failed", service).Run(); err != nil {
+		return errors.Wrapf(err, "failed to reset %s", service)
+	}
+	return errors.Wrapf(
+		node.Command("systemctl", "start", service).Run(),
+		"failed to start %s", service,
+	)
+}
+
+func isFaultService(service string) bool {
+	for _, s := range FaultServices {
+		if s == service {
+			return true
+		}
+	}
+	return false
+}
+
+// node returns the named node of the cluster, nodeName may omit the
+// cluster name prefix, EG worker2
+func (p *Provider) node(name, nodeName string) (nodes.Node, error) {
+	allNodes, err := p.ListNodes(name)
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range allNodes {
+		if n.String() == nodeName || n.String() == name+"-"+nodeName {
+			return n, nil
+		}
+	}
+	return nil, errors.Errorf("unknown node %q for cluster %q", nodeName, name)
+}
@@ -0,0 +1,228 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/cluster/constants"
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/internal/cluster/kubeconfig"
+	"sigs.k8s.io/kind/pkg/internal/cluster/loadbalancer"
+)
+
+// kubeletFlagsPath is where kubeadm writes the kubelet flags on each node
+const kubeletFlagsPath = "/var/lib/kubelet/kubeadm-flags.env"
+
+// nodeIPFlagRE matches the kubelet --node-ip flag in kubeletFlagsPath
+var nodeIPFlagRE = regexp.MustCompile(`--node-ip=([^\s"]+)`)
+
+// HealReport describes the repairs made by Heal
+type HealReport struct {
+	// Restarted are the nodes that had exited and were started
+	Restarted []string
+	// Reconciled are the nodes whose kubelet was updated to a new node IP
+	Reconciled []string
+	// LoadBalancerReconfigured is true if the load balancer was updated to
+	// the current control plane node IPs
+	LoadBalancerReconfigured bool
+	// KubeConfigRefreshed is true if the kubeconfig was updated to a new
+	// API server endpoint
+	KubeConfigRefreshed bool
+	// NeedRecreation are the control plane nodes that came back with a new
+	// IP, which cannot be healed: their static pod manifests, etcd member
+	// and API server certificate still use the old IP, so the cluster must be
+	// recreated
+	NeedRecreation []string
+}
+
+// Healed returns true if any repairs were made
+func (r *HealReport) Healed() bool {
+	return len(r.Restarted) > 0 || len(r.Reconciled) > 0 || r.LoadBalancerReconfigured || r.KubeConfigRefreshed
+}
+
+// Heal starts any exited nodes of the cluster, then runs the fixups needed
+// after nodes restart, whether started by Heal or by their restart policy:
+// the kubelet is restarted with the node's current IP, the load balancer is
+// pointed at the current control plane IPs, and the kubeconfig at the
+// current API server endpoint.
+// Control plane nodes with a new IP are reported in NeedRecreation instead
+// of being reconciled, see HealReport
+func (p *Provider) Heal(name string) (*HealReport, error) {
+	allNodes, err := p.ListNodes(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(allNodes) == 0 {
+		return nil, errors.Errorf("no nodes found for cluster %q", name)
+	}
+
+	// nodes we cannot run commands on are not running
+	report := &HealReport{}
+	stopped := []nodes.Node{}
+	for _, n := range allNodes {
+		if err := n.Command("true").Run(); err != nil {
+			stopped = append(stopped, n)
+			report.Restarted = append(report.Restarted, n.String())
+		}
+	}
+	if err := p.provider.StartNodes(stopped); err != nil {
+		return report, err
+	}
+
+	kubernetesNodes, err := nodeutils.KubernetesNodes(allNodes)
+	if err != nil {
+		return report, err
+	}
+	controlPlaneChanged := false
+	for _, n := range kubernetesNodes {
+		role, err := n.Role()
+		if err != nil {
+			return report, err
+		}
+		controlPlane := role == constants.ControlPlaneNodeRoleValue
+		reconciled, err := reconcileNodeIP(n, controlPlane)
+		if err == errNodeIPChanged {
+			report.NeedRecreation = append(report.NeedRecreation, n.String())
+			continue
+		}
+		if err != nil {
+			return report, errors.Wrapf(err, "failed to reconcile IP of node %s", n.String())
+		}
+		restarted := containsNode(stopped, n)
+		if reconciled {
+			report.Reconciled = append(report.Reconciled, n.String())
+		} else if restarted {
+			// the kubelet may have started before the node was ready
+			if err := n.Command("systemctl", "restart", "kubelet").Run(); err != nil {
+				return report, errors.Wrapf(err, "failed to restart kubelet on node %s", n.String())
+			}
+		}
+		if (reconciled || restarted) && controlPlane {
+			controlPlaneChanged = true
+		}
+	}
+
+	loadBalancerNode, err := nodeutils.ExternalLoadBalancerNode(allNodes)
+	if err != nil {
+		return report, err
+	}
+	if loadBalancerNode != nil && (controlPlaneChanged || containsNode(stopped, loadBalancerNode)) {
+		if err := reconfigureLoadBalancer(loadBalancerNode, allNodes); err != nil {
+			return report, err
+		}
+		report.LoadBalancerReconfigured = true
+	}
+
+	// restarted nodes may have new host ports
+	report.KubeConfigRefreshed, err = kubeconfig.Refresh(p.ic(name))
+	if len(report.Restarted) > 0 || len(report.Reconciled) > 0 || report.LoadBalancerReconfigured {
+		p.RecordChange(name, Change{
+			Action: ChangeHeal,
+			Nodes:  append(append([]string{}, report.Restarted...), report.Reconciled...),
+			Details: map[string]string{
+				"restarted":                strings.Join(report.Restarted, ","),
+				"reconciled":               strings.Join(report.Reconciled, ","),
+				"loadBalancerReconfigured": strconv.FormatBool(report.LoadBalancerReconfigured),
+			},
+		})
+	}
+	return report, err
+}
+
+// errNodeIPChanged is returned by reconcileNodeIP for control plane nodes
+// with a new IP
+var errNodeIPChanged = errors.New("node IP changed")
+
+// reconcileNodeIP updates the kubelet's --node-ip to the node's current IP
+// and restarts the kubelet, returning true if the IP had changed.
+// The IP of control plane nodes is also in their static pod manifests and
+// certificates, for those errNodeIPChanged is returned instead
+func reconcileNodeIP(n nodes.Node, controlPlane bool) (bool, error) {
+	var buff bytes.Buffer
+	if err := n.Command("cat", kubeletFlagsPath).SetStdout(&buff).Run(); err != nil {
+		return false, errors.Wrap(err, "failed to read kubelet flags")
+	}
+	ipv4, ipv6, err := n.IP()
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get node IP")
+	}
+	flags, changed := updateNodeIP(buff.String(), ipv4, ipv6)
+	if !changed {
+		return false, nil
+	}
+	if controlPlane {
+		return false, errNodeIPChanged
+	}
+	if err := nodeutils.WriteFile(n, kubeletFlagsPath, flags); err != nil {
+		return false, errors.Wrap(err, "failed to write kubelet flags")
+	}
+	return true, errors.Wrap(
+		n.Command("systemctl", "restart", "kubelet").Run(),
+		"failed to restart kubelet",
+	)
+}
+
+// updateNodeIP returns flags with the --node-ip flag set to the current
+// IP of the same family, and whether that changed it
+func updateNodeIP(flags, ipv4, ipv6 string) (string, bool) {
+	match := nodeIPFlagRE.FindStringSubmatch(flags)
+	if match == nil {
+		return flags, false
+	}
+	current := ipv4
+	if strings.Contains(match[1], ":") {
+		current = ipv6
+	}
+	if current == "" || current == match[1] {
+		return flags, false
+	}
+	return nodeIPFlagRE.ReplaceAllLiteralString(flags, "--node-ip="+current), true
+}
+
+// reconfigureLoadBalancer points the load balancer at the current control
+// plane node IPs
+func reconfigureLoadBalancer(loadBalancerNode nodes.Node, allNodes []nodes.Node) error {
+	controlPlanes, err := nodeutils.ControlPlaneNodes(allNodes)
+	if err != nil {
+		return err
+	}
+	if len(controlPlanes) == 0 {
+		return nil
+	}
+	// the cluster is IPv6 if the control plane nodes only have IPv6 addresses
+	ipv4, _, err := controlPlanes[0].IP()
+	if err != nil {
+		return errors.Wrapf(err, "failed to get IP for node %s", controlPlanes[0].String())
+	}
+	return loadbalancer.Configure(loadBalancerNode, controlPlanes, ipv4 == "")
+}
+
+func containsNode(nodeList []nodes.Node, n nodes.Node) bool {
+	for _, other := range nodeList {
+		if other.String() == n.String() {
+			return true
+		}
+	}
+	return false
+}
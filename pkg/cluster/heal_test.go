@@ -0,0 +1,114 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"reflect"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/cluster/constants"
+)
+
+func TestHealNodeIPChanged(t *testing.T) {
+	defer withTempHome(t)()
+	fake := newFakeCluster(1)
+	for _, n := range fake.nodes {
+		n.ipv4 = "172.17.0.3"
+		n.files = map[string]string{
+			kubeletFlagsPath: `KUBELET_KUBEADM_ARGS="--node-ip=172.17.0.2"`,
+		}
+	}
+	p := &Provider{provider: fake}
+	// the kubeconfig is not faked, so refreshing it fails
+	report, _ := p.Heal("kind")
+	if report == nil {
+		t.Fatalf("expected a report")
+	}
+	if expected := []string{"kind-worker"}; !reflect.DeepEqual(report.Reconciled, expected) {
+		t.Errorf("Reconciled = %v, expected %v", report.Reconciled, expected)
+	}
+	if expected := []string{"kind-control-plane"}; !reflect.DeepEqual(report.NeedRecreation, expected) {
+		t.Errorf("NeedRecreation = %v, expected %v", report.NeedRecreation, expected)
+	}
+	for _, n := range fake.nodes {
+		expected := `KUBELET_KUBEADM_ARGS="--node-ip=172.17.0.3"`
+		if n.role == constants.ControlPlaneNodeRoleValue {
+			expected = `KUBELET_KUBEADM_ARGS="--node-ip=172.17.0.2"`
+		}
+		if flags := n.files[kubeletFlagsPath]; flags != expected {
+			t.Errorf("node %s kubelet flags = %q, expected %q", n.name, flags, expected)
+		}
+	}
+}
+
+func TestUpdateNodeIP(t *testing.T) {
+	cases := []struct {
+		Name            string
+		Flags           string
+		IPv4            string
+		IPv6            string
+		ExpectedFlags   string
+		ExpectedChanged bool
+	}{
+		{
+			Name:            "changed ipv4",
+			Flags:           `KUBELET_KUBEADM_ARGS="--fail-swap-on=false --node-ip=172.17.0.2"`,
+			IPv4:            "172.17.0.3",
+			ExpectedFlags:   `KUBELET_KUBEADM_ARGS="--fail-swap-on=false --node-ip=172.17.0.3"`,
+			ExpectedChanged: true,
+		},
+		{
+			Name:          "unchanged ipv4",
+			Flags:         `KUBELET_KUBEADM_ARGS="--node-ip=172.17.0.2 --fail-swap-on=false"`,
+			IPv4:          "172.17.0.2",
+			IPv6:          "fc00:f853:ccd:e793::2",
+			ExpectedFlags: `KUBELET_KUBEADM_ARGS="--node-ip=172.17.0.2 --fail-swap-on=false"`,
+		},
+		{
+			Name:            "changed ipv6",
+			Flags:           `KUBELET_KUBEADM_ARGS="--node-ip=fc00:f853:ccd:e793::2"`,
+			IPv4:            "172.17.0.3",
+			IPv6:            "fc00:f853:ccd:e793::3",
+			ExpectedFlags:   `KUBELET_KUBEADM_ARGS="--node-ip=fc00:f853:ccd:e793::3"`,
+			ExpectedChanged: true,
+		},
+		{
+			Name:          "no node-ip flag",
+			Flags:         `KUBELET_KUBEADM_ARGS="--fail-swap-on=false"`,
+			IPv4:          "172.17.0.3",
+			ExpectedFlags: `KUBELET_KUBEADM_ARGS="--fail-swap-on=false"`,
+		},
+		{
+			Name:          "no current IP",
+			Flags:         `KUBELET_KUBEADM_ARGS="--node-ip=172.17.0.2"`,
+			ExpectedFlags: `KUBELET_KUBEADM_ARGS="--node-ip=172.17.0.2"`,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			flags, changed := updateNodeIP(tc.Flags, tc.IPv4, tc.IPv6)
+			if flags != tc.ExpectedFlags {
+				t.Errorf("updateNodeIP() = %q, expected %q", flags, tc.ExpectedFlags)
+			}
+			if changed != tc.ExpectedChanged {
+				t.Errorf("updateNodeIP() changed = %v, expected %v", changed, tc.ExpectedChanged)
+			}
+		})
+	}
+}
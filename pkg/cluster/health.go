@@ -0,0 +1,395 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+)
+
+// DefaultHealthInterval is how often a HealthMonitor probes by default
+const DefaultHealthInterval = 30 * time.Second
+
+// DefaultHealthTimeout bounds each health check by default
+const DefaultHealthTimeout = 10 * time.Second
+
+// The health checks, see HealthStatus
+const (
+	// HealthCheckAPIServer checks the API server is reachable from the host
+	HealthCheckAPIServer = "apiserver"
+	// HealthCheckNodes checks all kubernetes nodes are Ready
+	HealthCheckNodes = "nodes"
+	// HealthCheckAddons checks the kube-system deployments and daemonsets
+	// are ready, EG CoreDNS, the CNI and kube-proxy
+	HealthCheckAddons = "addons"
+)
+
+// addonsTemplate prints one "<kind>/<name> <desired> <ready>" line per
+// kube-system deployment and daemonset, ready is omitted if there are none
+const addonsTemplate = `{{range .items}}{{.kind}}/{{.metadata.name}} ` +
+	`{{if eq .kind "DaemonSet"}}{{.status.desiredNumberScheduled}} {{.status.numberReady}}` +
+	`{{else}}{{.spec.replicas}} {{.status.readyReplicas}}{{end}}{{"\n"}}{{end}}`
+
+// nodesTemplate prints one "<name> <Ready condition status>" line per node
+const nodesTemplate = `{{range .items}}{{.metadata.name}}` +
+	`{{range .status.conditions}}{{if eq .type "Ready"}} {{.status}}{{end}}{{end}}{{"\n"}}{{end}}`
+
+// HealthStatus is the result of probing a cluster's health
+type HealthStatus struct {
+	// Cluster is the name of the cluster
+	Cluster string `json:"cluster"`
+	// Checked is when the cluster was probed
+	Checked time.Time `json:"checked"`
+	// Checks are the results of each check, in order
+	Checks []HealthCheck `json:"checks"`
+}
+
+// HealthCheck is the result of a single health check
+type HealthCheck struct {
+	// Name is the name of the check, EG HealthCheckAPIServer
+	Name string `json:"name"`
+	// Healthy is true if the check passed
+	Healthy bool `json:"healthy"`
+	// Message describes why the check failed
+	Message string `json:"message,omitempty"`
+}
+
+// Live returns true if the API server is reachable, IE the cluster is up
+// even if it is not ready
+func (s *HealthStatus) Live() bool {
+	for _, c := range s.Checks {
+		if c.Name == HealthCheckAPIServer {
+			return c.Healthy
+		}
+	}
+	return false
+}
+
+// Ready returns true if all checks passed
+func (s *HealthStatus) Ready() bool {
+	for _, c := range s.Checks {
+		if !c.Healthy {
+			return false
+		}
+	}
+	return len(s.Checks) > 0
+}
+
+// HealthOption is an option for Health and NewHealthMonitor
+type HealthOption func(*healthOptions)
+
+type healthOptions struct {
+	interval time.Duration
+	timeout  time.Duration
+}
+
+// HealthInterval configures how often a HealthMonitor probes each cluster,
+// see DefaultHealthInterval which is used for non-positive intervals
+func HealthInterval(interval time.Duration) HealthOption {
+	return func(o *healthOptions) {
+		o.interval = interval
+	}
+}
+
+// HealthTimeout configures the timeout of each health check,
+// see DefaultHealthTimeout which is used for non-positive timeouts
+func HealthTimeout(timeout time.Duration) HealthOption {
+	return func(o *healthOptions) {
+		o.timeout = timeout
+	}
+}
+
+func newHealthOptions(options []HealthOption) *healthOptions {
+	o := &healthOptions{
+		interval: DefaultHealthInterval,
+		timeout:  DefaultHealthTimeout,
+	}
+	for _, option := range options {
+		option(o)
+	}
+	if o.interval <= 0 {
+		o.interval = DefaultHealthInterval
+	}
+	if o.timeout <= 0 {
+		o.timeout = DefaultHealthTimeout
+	}
+	return o
+}
+
+// Health probes the cluster's health, failing checks are reported in the
+// status, an error is only returned if the cluster could not be probed
+func (p *Provider) Health(name string, options ...HealthOption) (*HealthStatus, error) {
+	o := newHealthOptions(options)
+	allNodes, err := p.ListNodes(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(allNodes) == 0 {
+		return nil, errors.Errorf("no nodes found for cluster %q", name)
+	}
+	controlPlanes, err := nodeutils.ControlPlaneNodes(allNodes)
+	if err != nil {
+		return nil, err
+	}
+	if len(controlPlanes) == 0 {
+		return nil, errors.Errorf("no control plane nodes found for cluster %q", name)
+	}
+	node := controlPlanes[0]
+
+	status := &HealthStatus{
+		Cluster: name,
+		Checked: time.Now(),
+	}
+	status.Checks = append(status.Checks, healthCheck(HealthCheckAPIServer, p.checkAPIServer(name, o.timeout)))
+	// the remaining checks need the API server
+	if !status.Live() {
+		for _, check := range []string{HealthCheckNodes, HealthCheckAddons} {
+			status.Checks = append(status.Checks, healthCheck(check, errors.New("API server is not reachable")))
+		}
+		return status, nil
+	}
+	status.Checks = append(status.Checks,
+		healthCheck(HealthCheckNodes, checkNodes(node, o.timeout)),
+		healthCheck(HealthCheckAddons, checkAddons(node, o.timeout)),
+	)
+	return status, nil
+}
+
+func healthCheck(name string, err error) HealthCheck {
+	if err != nil {
+		return HealthCheck{Name: name, Message: err.Error()}
+	}
+	return HealthCheck{Name: name, Healthy: true}
+}
+
+// checkAPIServer checks the API server's /healthz is reachable on the host,
+// kubeadm allows unauthenticated access to it
+func (p *Provider) checkAPIServer(name string, timeout time.Duration) error {
+	endpoint, err := p.GetAPIServerEndpoint(name)
+	if err != nil {
+		return err
+	}
+	client := &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			// the cluster CA is not trusted by the host, this only checks
+			// reachability
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+	resp, err := client.Get("https://" + endpoint + "/healthz")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("%s/healthz returned %s", endpoint, resp.Status)
+	}
+	return nil
+}
+
+// checkNodes checks all nodes registered with the API server are Ready
+func checkNodes(n nodes.Node, timeout time.Duration) error {
+	lines, err := exec.OutputLines(kubectl(n,
+		"get", "nodes", requestTimeout(timeout), "-o", "go-template="+nodesTemplate,
+	))
+	if err != nil {
+		return errors.Wrap(err, "failed to get nodes")
+	}
+	return parseNodesReady(lines)
+}
+
+// checkAddons checks the kube-system deployments and daemonsets are ready
+func checkAddons(n nodes.Node, timeout time.Duration) error {
+	lines, err := exec.OutputLines(kubectl(n,
+		"get", "deployments,daemonsets", "--namespace=kube-system",
+		requestTimeout(timeout), "-o", "go-template="+addonsTemplate,
+	))
+	if err != nil {
+		return errors.Wrap(err, "failed to get addons")
+	}
+	return parseAddonsReady(lines)
+}
+
+func requestTimeout(timeout time.Duration) string {
+	return "--request-timeout=" + timeout.String()
+}
+
+// parseNodesReady parses nodesTemplate output
+func parseNodesReady(lines []string) error {
+	notReady := []string{}
+	for _, line := range lines {
+		parts := strings.Fields(line)
+		if len(parts) == 0 {
+			continue
+		}
+		if len(parts) != 2 || parts[1] != "True" {
+			notReady = append(notReady, parts[0])
+		}
+	}
+	if len(notReady) > 0 {
+		return errors.Errorf("nodes not Ready: %s", strings.Join(notReady, ", "))
+	}
+	return nil
+}
+
+// parseAddonsReady parses addonsTemplate output
+func parseAddonsReady(lines []string) error {
+	notReady := []string{}
+	for _, line := range lines {
+		parts := strings.Fields(line)
+		if len(parts) == 0 {
+			continue
+		}
+		desired, ready := 0, 0
+		if len(parts) > 1 {
+			desired, _ = strconv.Atoi(parts[1])
+		}
+		if len(parts) > 2 {
+			ready, _ = strconv.Atoi(parts[2])
+		}
+		if ready < desired {
+			notReady = append(notReady, fmt.Sprintf("%s (%d/%d)", parts[0], ready, desired))
+		}
+	}
+	if len(notReady) > 0 {
+		return errors.Errorf("addons not ready: %s", strings.Join(notReady, ", "))
+	}
+	return nil
+}
+
+// HealthMonitor probes the health of clusters in the background, caching
+// the latest status of each for cheap and frequent reads, EG from the
+// health endpoints of a service managing clusters
+type HealthMonitor struct {
+	provider *Provider
+	options  []HealthOption
+	interval time.Duration
+
+	mu       sync.RWMutex
+	statuses map[string]*HealthStatus
+	stops    map[string]chan struct{}
+}
+
+// NewHealthMonitor returns a HealthMonitor probing with options,
+// clusters must be added with Watch
+func (p *Provider) NewHealthMonitor(options ...HealthOption) *HealthMonitor {
+	return &HealthMonitor{
+		provider: p,
+		options:  options,
+		interval: newHealthOptions(options).interval,
+		statuses: map[string]*HealthStatus{},
+		stops:    map[string]chan struct{}{},
+	}
+}
+
+// Watch starts probing the cluster every interval, starting now
+func (m *HealthMonitor) Watch(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, watching := m.stops[name]; watching {
+		return
+	}
+	stop := make(chan struct{})
+	m.stops[name] = stop
+	go m.run(name, stop)
+}
+
+// Unwatch stops probing the cluster and forgets its status
+func (m *HealthMonitor) Unwatch(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if stop, watching := m.stops[name]; watching {
+		close(stop)
+		delete(m.stops, name)
+	}
+	delete(m.statuses, name)
+}
+
+// Stop stops probing all clusters
+func (m *HealthMonitor) Stop() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for name, stop := range m.stops {
+		close(stop)
+		delete(m.stops, name)
+	}
+}
+
+// Status returns the latest status of the cluster, or nil if it has not
+// been probed yet
+func (m *HealthMonitor) Status(name string) *HealthStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.statuses[name]
+}
+
+// Statuses returns the latest status of every probed cluster
+func (m *HealthMonitor) Statuses() map[string]*HealthStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	statuses := make(map[string]*HealthStatus, len(m.statuses))
+	for name, status := range m.statuses {
+		statuses[name] = status
+	}
+	return statuses
+}
+
+func (m *HealthMonitor) run(name string, stop chan struct{}) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	for {
+		m.probe(name, stop)
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// probe stores the cluster's current status, clusters that cannot be
+// probed, EG because they were deleted, are reported with a failed check
+func (m *HealthMonitor) probe(name string, stop chan struct{}) {
+	status, err := m.provider.Health(name, m.options...)
+	if err != nil {
+		status = &HealthStatus{
+			Cluster: name,
+			Checked: time.Now(),
+			Checks:  []HealthCheck{healthCheck(HealthCheckAPIServer, err)},
+		}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	// don't store statuses of clusters unwatched while probing
+	select {
+	case <-stop:
+		return
+	default:
+	}
+	m.statuses[name] = status
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseNodesReady(t *testing.T) {
+	cases := []struct {
+		Name        string
+		Lines       []string
+		ExpectError bool
+	}{
+		{
+			Name:  "all ready",
+			Lines: []string{"kind-control-plane True", "kind-worker True"},
+		},
+		{
+			Name:        "not ready",
+			Lines:       []string{"kind-control-plane True", "kind-worker False"},
+			ExpectError: true,
+		},
+		{
+			Name:        "no ready condition yet",
+			Lines:       []string{"kind-worker"},
+			ExpectError: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			err := parseNodesReady(tc.Lines)
+			if (err != nil) != tc.ExpectError {
+				t.Errorf("expected error: %v but got: %v", tc.ExpectError, err)
+			}
+		})
+	}
+}
+
+func TestParseAddonsReady(t *testing.T) {
+	cases := []struct {
+		Name        string
+		Lines       []string
+		ExpectError bool
+	}{
+		{
+			Name: "all ready",
+			Lines: []string{
+				"Deployment/coredns 2 2",
+				"DaemonSet/kindnet 3 3",
+			},
+		},
+		{
+			Name:        "partially ready",
+			Lines:       []string{"DaemonSet/kube-proxy 3 2"},
+			ExpectError: true,
+		},
+		{
+			Name:        "none ready",
+			Lines:       []string{"Deployment/coredns 2 <no value>"},
+			ExpectError: true,
+		},
+		{
+			Name:  "scaled to zero",
+			Lines: []string{"Deployment/coredns 0 <no value>"},
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			err := parseAddonsReady(tc.Lines)
+			if (err != nil) != tc.ExpectError {
+				t.Errorf("expected error: %v but got: %v", tc.ExpectError, err)
+			}
+		})
+	}
+}
+
+func TestHealthStatus(t *testing.T) {
+	status := &HealthStatus{Checks: []HealthCheck{
+		{Name: HealthCheckAPIServer, Healthy: true},
+		{Name: HealthCheckNodes, Healthy: false},
+	}}
+	if !status.Live() {
+		t.Errorf("expected status to be live")
+	}
+	if status.Ready() {
+		t.Errorf("expected status not to be ready")
+	}
+	if (&HealthStatus{}).Ready() {
+		t.Errorf("expected an unprobed status not to be ready")
+	}
+}
+
+func TestHealthOptions(t *testing.T) {
+	cases := []struct {
+		Name             string
+		Options          []HealthOption
+		ExpectedInterval time.Duration
+		ExpectedTimeout  time.Duration
+	}{
+		{
+			Name:             "defaults",
+			ExpectedInterval: DefaultHealthInterval,
+			ExpectedTimeout:  DefaultHealthTimeout,
+		},
+		{
+			Name:             "configured",
+			Options:          []HealthOption{HealthInterval(time.Minute), HealthTimeout(time.Second)},
+			ExpectedInterval: time.Minute,
+			ExpectedTimeout:  time.Second,
+		},
+		{
+			Name:             "zero",
+			Options:          []HealthOption{HealthInterval(0), HealthTimeout(0)},
+			ExpectedInterval: DefaultHealthInterval,
+			ExpectedTimeout:  DefaultHealthTimeout,
+		},
+		{
+			Name:             "negative",
+			Options:          []HealthOption{HealthInterval(-time.Second), HealthTimeout(-time.Second)},
+			ExpectedInterval: DefaultHealthInterval,
+			ExpectedTimeout:  DefaultHealthTimeout,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			o := newHealthOptions(tc.Options)
+			if o.interval != tc.ExpectedInterval {
+				t.Errorf("interval = %v, expected %v", o.interval, tc.ExpectedInterval)
+			}
+			if o.timeout != tc.ExpectedTimeout {
+				t.Errorf("timeout = %v, expected %v", o.timeout, tc.ExpectedTimeout)
+			}
+			// the monitor's ticker panics on non-positive intervals
+			if m := (&Provider{}).NewHealthMonitor(tc.Options...); m.interval != tc.ExpectedInterval {
+				t.Errorf("monitor interval = %v, expected %v", m.interval, tc.ExpectedInterval)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+
+	internallogs "sigs.k8s.io/kind/pkg/internal/cluster/logs"
+)
+
+// StreamLogsOption is an option for StreamLogs
+type StreamLogsOption func(*streamLogsOptions)
+
+type streamLogsOptions struct {
+	roles []string
+	logs  internallogs.StreamOptions
+}
+
+// StreamLogsRoles limits StreamLogs to the nodes with one of roles,
+// including custom roles
+func StreamLogsRoles(roles ...string) StreamLogsOption {
+	return func(o *streamLogsOptions) {
+		o.roles = append(o.roles, roles...)
+	}
+}
+
+// StreamLogsFollow configures StreamLogs to keep streaming new entries
+// until it is interrupted, like tail -f
+func StreamLogsFollow(follow bool) StreamLogsOption {
+	return func(o *streamLogsOptions) {
+		o.logs.Follow = follow
+	}
+}
+
+// StreamLogsSince limits StreamLogs to entries written since then,
+// the zero time streams all of them
+func StreamLogsSince(since time.Time) StreamLogsOption {
+	return func(o *streamLogsOptions) {
+		o.logs.Since = since
+	}
+}
+
+// StreamLogsUnits limits StreamLogs to the journal of these systemd units
+// on the nodes, EG kubelet
+func StreamLogsUnits(units ...string) StreamLogsOption {
+	return func(o *streamLogsOptions) {
+		o.logs.Units = append(o.logs.Units, units...)
+	}
+}
+
+// StreamLogsSerial configures StreamLogs to stream the output of the node
+// containers, as docker logs does, instead of their journal
+// This cannot be combined with StreamLogsUnits
+func StreamLogsSerial(serial bool) StreamLogsOption {
+	return func(o *streamLogsOptions) {
+		o.logs.Serial = serial
+	}
+}
+
+// StreamLogs writes the logs of the cluster's nodes to w as they are
+// read, each line prefixed with the name of its node
+func (p *Provider) StreamLogs(name string, w io.Writer, options ...StreamLogsOption) error {
+	opts := &streamLogsOptions{}
+	for _, o := range options {
+		o(opts)
+	}
+	if opts.logs.Serial && len(opts.logs.Units) > 0 {
+		return errors.New("serial logs cannot be limited to units")
+	}
+	n, err := p.logNodes(name, opts.roles, nil)
+	if err != nil {
+		return err
+	}
+	if len(n) == 0 {
+		return errors.Errorf("unknown cluster %q", name)
+	}
+	return internallogs.Stream(context.Background(), n, w, opts.logs)
+}
+
+// logNodes returns the internal nodes of the cluster with one of roles and
+// one of names, either being empty selects every node
+func (p *Provider) logNodes(name string, roles, names []string) ([]nodes.Node, error) {
+	n, err := p.ListInternalNodes(name)
+	if err != nil {
+		return nil, err
+	}
+	return filterNodes(n, roles, names, fmt.Sprintf("cluster %q", name))
+}
+
+// selectNodes returns the containers matching the docker label selector
+// with one of roles and one of names, either being empty selects every
+// container
+func (p *Provider) selectNodes(selector string, roles, names []string) ([]nodes.Node, error) {
+	n, err := p.provider.ListNodesByLabel(selector)
+	if err != nil {
+		return nil, err
+	}
+	if len(n) == 0 {
+		return nil, errors.Errorf("no containers match label selector %q", selector)
+	}
+	return filterNodes(n, roles, names, fmt.Sprintf("label selector %q", selector))
+}
+
+// filterNodes returns the nodes n with one of roles and one of names,
+// either being empty selects every node, source names where n came from
+func filterNodes(n []nodes.Node, roles, names []string, source string) ([]nodes.Node, error) {
+	if len(roles) > 0 {
+		selected := []nodes.Node{}
+		for _, role := range roles {
+			withRole, err := nodeutils.SelectNodesByRole(n, role)
+			if err != nil {
+				return nil, err
+			}
+			selected = append(selected, withRole...)
+		}
+		n = selected
+	}
+	if len(names) > 0 {
+		byName := map[string]nodes.Node{}
+		for _, node := range n {
+			byName[node.String()] = node
+		}
+		selected := []nodes.Node{}
+		for _, nodeName := range names {
+			node, ok := byName[nodeName]
+			if !ok {
+				return nil, errors.Errorf("unknown node %q in %s", nodeName, source)
+			}
+			selected = append(selected, node)
+		}
+		n = selected
+	}
+	return n, nil
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"reflect"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/cluster/constants"
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+)
+
+// labelCluster is a fakeCluster whose nodes are labeled selector
+type labelCluster struct {
+	*fakeCluster
+	selector string
+}
+
+func (c *labelCluster) ListNodesByLabel(selector string) ([]nodes.Node, error) {
+	if selector != c.selector {
+		return []nodes.Node{}, nil
+	}
+	return c.ListNodes("")
+}
+
+func TestSelectNodes(t *testing.T) {
+	cases := []struct {
+		Name      string
+		Selector  string
+		Roles     []string
+		Names     []string
+		Expected  []string
+		ExpectErr bool
+	}{
+		{
+			Name:     "every node",
+			Selector: "io.x-k8s.kind.cluster=ci",
+			Expected: []string{"kind-control-plane", "kind-control-plane2", "kind-worker"},
+		},
+		{
+			Name:     "by role",
+			Selector: "io.x-k8s.kind.cluster=ci",
+			Roles:    []string{constants.WorkerNodeRoleValue},
+			Expected: []string{"kind-worker"},
+		},
+		{
+			Name:     "by name",
+			Selector: "io.x-k8s.kind.cluster=ci",
+			Names:    []string{"kind-control-plane2"},
+			Expected: []string{"kind-control-plane2"},
+		},
+		{
+			Name:      "unknown name",
+			Selector:  "io.x-k8s.kind.cluster=ci",
+			Names:     []string{"kind-worker2"},
+			ExpectErr: true,
+		},
+		{
+			Name:      "no match",
+			Selector:  "io.x-k8s.kind.cluster=other",
+			ExpectErr: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			p := &Provider{provider: &labelCluster{fakeCluster: newFakeCluster(2), selector: "io.x-k8s.kind.cluster=ci"}}
+			selected, err := p.selectNodes(tc.Selector, tc.Roles, tc.Names)
+			if (err != nil) != tc.ExpectErr {
+				t.Fatalf("selectNodes() error = %v, ExpectErr %v", err, tc.ExpectErr)
+			}
+			if tc.ExpectErr {
+				return
+			}
+			names := []string{}
+			for _, n := range selected {
+				names = append(names, n.String())
+			}
+			if !reflect.DeepEqual(names, tc.Expected) {
+				t.Errorf("selectNodes() = %v, expected %v", names, tc.Expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,354 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+)
+
+// defaultDNSDomain is the cluster DNS domain if kubeadm did not record one
+const defaultDNSDomain = "cluster.local"
+
+// kubeadmConfigFieldRE matches the networking fields of the kubeadm
+// ClusterConfiguration stored in the cluster
+var kubeadmConfigFieldRE = regexp.MustCompile(`(?m)^\s*(dnsDomain|serviceSubnet):\s*"?([^"\s]+)"?\s*$`)
+
+// ConnectOption is an option for ConnectClusters
+type ConnectOption func(*connectOptions)
+
+type connectOptions struct {
+	stubDomains bool
+}
+
+// ConnectStubDomains configures ConnectClusters to also configure each
+// cluster's CoreDNS to resolve the other cluster's services under
+// StubDomain, this requires the clusters to have distinct service subnets
+func ConnectStubDomains(stubDomains bool) ConnectOption {
+	return func(o *connectOptions) {
+		o.stubDomains = stubDomains
+	}
+}
+
+// StubDomain returns the DNS domain connected clusters resolve the named
+// cluster's services under, EG: my-svc.my-namespace.svc.<name>.local
+func StubDomain(name string) string {
+	return name + ".local"
+}
+
+// clusterNetwork is the networking of a cluster relevant to connecting it
+type clusterNetwork struct {
+	name string
+	// nodes are the cluster's Kubernetes nodes
+	nodes []nodes.Node
+	// podCIDRs maps node names to their IPv4 pod CIDR
+	podCIDRs      map[string]string
+	serviceSubnet string
+	dnsDomain     string
+	dnsIP         string
+}
+
+// ConnectClusters attaches the nodes of clusters a and b to each other's
+// networks and routes each cluster's pod subnets via its nodes, so that
+// pods in either cluster can reach pods in the other directly.
+//
+// The clusters must have distinct pod subnets. Routes live on the nodes,
+// so ConnectClusters should be run again after nodes are restarted.
+func (p *Provider) ConnectClusters(a, b string, options ...ConnectOption) error {
+	opts := &connectOptions{}
+	for _, o := range options {
+		o(opts)
+	}
+	if a == b {
+		return errors.Errorf("cannot connect cluster %q to itself", a)
+	}
+
+	networkA, err := p.clusterNetwork(a)
+	if err != nil {
+		return err
+	}
+	networkB, err := p.clusterNetwork(b)
+	if err != nil {
+		return err
+	}
+	for nodeA, cidrA := range networkA.podCIDRs {
+		for nodeB, cidrB := range networkB.podCIDRs {
+			overlap, err := cidrsOverlap(cidrA, cidrB)
+			if err != nil {
+				return err
+			}
+			if overlap {
+				return errors.Errorf(
+					"pod subnet %s of node %s overlaps pod subnet %s of node %s, clusters must be created with distinct networking.podSubnet",
+					cidrA, nodeA, cidrB, nodeB,
+				)
+			}
+		}
+	}
+	if opts.stubDomains {
+		overlap, err := cidrsOverlap(networkA.serviceSubnet, networkB.serviceSubnet)
+		if err != nil {
+			return err
+		}
+		if overlap {
+			return errors.Errorf(
+				"service subnets %s and %s overlap, clusters must be created with distinct networking.serviceSubnet",
+				networkA.serviceSubnet, networkB.serviceSubnet,
+			)
+		}
+	}
+
+	if err := p.provider.ConnectNodes(b, networkA.nodes); err != nil {
+		return err
+	}
+	if err := p.provider.ConnectNodes(a, networkB.nodes); err != nil {
+		return err
+	}
+	// the clusters' nodes are attached to each other's networks from here
+	p.RecordChange(a, Change{Action: ChangeConnect, Details: map[string]string{"cluster": b}})
+	p.RecordChange(b, Change{Action: ChangeConnect, Details: map[string]string{"cluster": a}})
+	if err := addRoutes(networkA, networkB, opts.stubDomains); err != nil {
+		return err
+	}
+	if err := addRoutes(networkB, networkA, opts.stubDomains); err != nil {
+		return err
+	}
+	if !opts.stubDomains {
+		return nil
+	}
+	if err := addStubDomain(networkA, networkB); err != nil {
+		return err
+	}
+	return addStubDomain(networkB, networkA)
+}
+
+// clusterNetwork looks up the networking of the named cluster from its
+// first control plane node
+func (p *Provider) clusterNetwork(name string) (*clusterNetwork, error) {
+	allNodes, err := p.ListNodes(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(allNodes) == 0 {
+		return nil, errors.Errorf("no nodes found for cluster %q", name)
+	}
+	kubernetesNodes, err := nodeutils.KubernetesNodes(allNodes)
+	if err != nil {
+		return nil, err
+	}
+	controlPlanes, err := nodeutils.ControlPlaneNodes(allNodes)
+	if err != nil {
+		return nil, err
+	}
+	if len(controlPlanes) == 0 {
+		return nil, errors.Errorf("no control plane nodes found for cluster %q", name)
+	}
+	controlPlane := controlPlanes[0]
+
+	network := &clusterNetwork{
+		name:     name,
+		nodes:    kubernetesNodes,
+		podCIDRs: map[string]string{},
+	}
+	lines, err := exec.OutputLines(kubectl(controlPlane,
+		"get", "nodes",
+		"-o", `jsonpath={range .items[*]}{.metadata.name}{"\t"}{.spec.podCIDR}{"\n"}{end}`,
+	))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get pod CIDRs of cluster %q", name)
+	}
+	for _, line := range lines {
+		parts := strings.Split(line, "\t")
+		// only IPv4 pod CIDRs are routed
+		if len(parts) != 2 || parts[1] == "" || strings.Contains(parts[1], ":") {
+			continue
+		}
+		network.podCIDRs[parts[0]] = parts[1]
+	}
+
+	var buff bytes.Buffer
+	if err := kubectl(controlPlane,
+		"-n", "kube-system", "get", "configmap", "kubeadm-config",
+		"-o", "jsonpath={.data.ClusterConfiguration}",
+	).SetStdout(&buff).Run(); err != nil {
+		return nil, errors.Wrapf(err, "failed to get kubeadm config of cluster %q", name)
+	}
+	network.serviceSubnet, network.dnsDomain = parseKubeadmNetworking(buff.String())
+
+	lines, err = exec.OutputLines(kubectl(controlPlane,
+		"-n", "kube-system", "get", "service", "kube-dns",
+		"-o", "jsonpath={.spec.clusterIP}",
+	))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get DNS service of cluster %q", name)
+	}
+	if len(lines) == 1 {
+		network.dnsIP = lines[0]
+	}
+	return network, nil
+}
+
+// addRoutes routes the pod subnets of dst on each node of src via the
+// dst node owning the subnet, and if services is set the service subnet
+// of dst via its first node
+func addRoutes(src, dst *clusterNetwork, services bool) error {
+	routes := map[string]string{}
+	for i, n := range dst.nodes {
+		ipv4, _, err := n.IP()
+		if err != nil {
+			return errors.Wrapf(err, "failed to get IP for node %s", n.String())
+		}
+		if ipv4 == "" {
+			return errors.Errorf("node %s has no IPv4 address, only IPv4 clusters can be connected", n.String())
+		}
+		if cidr, ok := dst.podCIDRs[n.String()]; ok {
+			routes[cidr] = ipv4
+		}
+		if services && dst.serviceSubnet != "" && i == 0 {
+			// kube-proxy on any node will forward service traffic
+			routes[dst.serviceSubnet] = ipv4
+		}
+	}
+	for _, n := range src.nodes {
+		for subnet, via := range routes {
+			if err := n.Command("ip", "route", "replace", subnet, "via", via).Run(); err != nil {
+				return errors.Wrapf(err, "failed to add route to %s on node %s", subnet, n.String())
+			}
+		}
+	}
+	return nil
+}
+
+// addStubDomain configures the CoreDNS of src to forward queries for
+// StubDomain(dst.name) to the DNS service of dst
+func addStubDomain(src, dst *clusterNetwork) error {
+	if dst.dnsIP == "" {
+		return errors.Errorf("cluster %q has no DNS service", dst.name)
+	}
+	controlPlanes, err := nodeutils.ControlPlaneNodes(src.nodes)
+	if err != nil {
+		return err
+	}
+	if len(controlPlanes) == 0 {
+		return errors.Errorf("no control plane nodes found for cluster %q", src.name)
+	}
+	var buff bytes.Buffer
+	if err := kubectl(controlPlanes[0],
+		"-n", "kube-system", "get", "configmap", "coredns",
+		"-o", "jsonpath={.data.Corefile}",
+	).SetStdout(&buff).Run(); err != nil {
+		return errors.Wrapf(err, "failed to get CoreDNS config of cluster %q", src.name)
+	}
+	corefile := setStubDomain(buff.String(), StubDomain(dst.name), dst.dnsDomain, dst.dnsIP)
+	patch, err := json.Marshal(map[string]interface{}{
+		"data": map[string]string{"Corefile": corefile},
+	})
+	if err != nil {
+		return err
+	}
+	// CoreDNS reloads the Corefile itself
+	return errors.Wrapf(kubectl(controlPlanes[0],
+		"-n", "kube-system", "patch", "configmap", "coredns",
+		"--type", "merge", "-p", string(patch),
+	).Run(), "failed to update CoreDNS config of cluster %q", src.name)
+}
+
+// setStubDomain returns corefile with a server block for domain, replacing
+// any previous block for it, which forwards queries to dnsIP after
+// rewriting domain to the remote cluster's dnsDomain
+func setStubDomain(corefile, domain, dnsDomain, dnsIP string) string {
+	header := domain + ":53 {"
+	lines := strings.Split(strings.TrimRight(corefile, "\n"), "\n")
+	kept := []string{}
+	inBlock := false
+	for _, line := range lines {
+		if line == header {
+			inBlock = true
+			continue
+		}
+		if inBlock {
+			// server blocks close unindented
+			if line == "}" {
+				inBlock = false
+			}
+			continue
+		}
+		kept = append(kept, line)
+	}
+	quotedDomain := regexp.QuoteMeta(domain)
+	quotedDNSDomain := regexp.QuoteMeta(dnsDomain)
+	kept = append(kept,
+		header,
+		"    errors",
+		"    cache 30",
+		"    rewrite stop {",
+		fmt.Sprintf(`        name regex (.*)\.%s {1}.%s`, quotedDomain, dnsDomain),
+		fmt.Sprintf(`        answer name (.*)\.%s {1}.%s`, quotedDNSDomain, domain),
+		"    }",
+		"    forward . "+dnsIP,
+		"}",
+	)
+	return strings.Join(kept, "\n") + "\n"
+}
+
+// parseKubeadmNetworking returns the service subnet and DNS domain from a
+// kubeadm ClusterConfiguration
+func parseKubeadmNetworking(clusterConfiguration string) (serviceSubnet, dnsDomain string) {
+	dnsDomain = defaultDNSDomain
+	for _, match := range kubeadmConfigFieldRE.FindAllStringSubmatch(clusterConfiguration, -1) {
+		switch match[1] {
+		case "dnsDomain":
+			dnsDomain = match[2]
+		case "serviceSubnet":
+			// only the IPv4 service subnet of dual stack clusters is routed
+			for _, subnet := range strings.Split(match[2], ",") {
+				if !strings.Contains(subnet, ":") {
+					serviceSubnet = subnet
+					break
+				}
+			}
+		}
+	}
+	return serviceSubnet, dnsDomain
+}
+
+// cidrsOverlap returns true if the CIDRs a and b share any addresses
+func cidrsOverlap(a, b string) (bool, error) {
+	_, netA, err := net.ParseCIDR(a)
+	if err != nil {
+		return false, errors.Wrapf(err, "invalid CIDR %q", a)
+	}
+	_, netB, err := net.ParseCIDR(b)
+	if err != nil {
+		return false, errors.Wrapf(err, "invalid CIDR %q", b)
+	}
+	return netA.Contains(netB.IP) || netB.Contains(netA.IP), nil
+}
+
+// kubectl returns a command running kubectl as the cluster admin on n
+func kubectl(n nodes.Node, args ...string) exec.Cmd {
+	return n.Command("kubectl", append([]string{"--kubeconfig=/etc/kubernetes/admin.conf"}, args...)...)
+}
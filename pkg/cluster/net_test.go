@@ -0,0 +1,141 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"testing"
+)
+
+func TestSetStubDomain(t *testing.T) {
+	const block = `other.local:53 {
+    errors
+    cache 30
+    rewrite stop {
+        name regex (.*)\.other\.local {1}.cluster.local
+        answer name (.*)\.cluster\.local {1}.other.local
+    }
+    forward . 10.97.0.10
+}
+`
+	const corefile = `.:53 {
+    errors
+    forward . /etc/resolv.conf
+}
+`
+	cases := []struct {
+		Name     string
+		Corefile string
+		Expected string
+	}{
+		{
+			Name:     "new stub domain",
+			Corefile: corefile,
+			Expected: corefile + block,
+		},
+		{
+			Name:     "replaced stub domain",
+			Corefile: corefile + "other.local:53 {\n    forward . 10.97.0.99\n}\n",
+			Expected: corefile + block,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			result := setStubDomain(tc.Corefile, "other.local", "cluster.local", "10.97.0.10")
+			if result != tc.Expected {
+				t.Errorf("setStubDomain() = %q, expected %q", result, tc.Expected)
+			}
+		})
+	}
+}
+
+func TestParseKubeadmNetworking(t *testing.T) {
+	cases := []struct {
+		Name                  string
+		ClusterConfiguration  string
+		ExpectedServiceSubnet string
+		ExpectedDNSDomain     string
+	}{
+		{
+			Name:                  "default domain",
+			ClusterConfiguration:  "networking:\n  podSubnet: 10.244.0.0/16\n  serviceSubnet: 10.96.0.0/12\n",
+			ExpectedServiceSubnet: "10.96.0.0/12",
+			ExpectedDNSDomain:     "cluster.local",
+		},
+		{
+			Name:                  "dual stack",
+			ClusterConfiguration:  "networking:\n  dnsDomain: example.local\n  serviceSubnet: fd00:10:96::/112,10.96.0.0/12\n",
+			ExpectedServiceSubnet: "10.96.0.0/12",
+			ExpectedDNSDomain:     "example.local",
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			serviceSubnet, dnsDomain := parseKubeadmNetworking(tc.ClusterConfiguration)
+			if serviceSubnet != tc.ExpectedServiceSubnet || dnsDomain != tc.ExpectedDNSDomain {
+				t.Errorf(
+					"parseKubeadmNetworking() = %q, %q, expected %q, %q",
+					serviceSubnet, dnsDomain, tc.ExpectedServiceSubnet, tc.ExpectedDNSDomain,
+				)
+			}
+		})
+	}
+}
+
+func TestCIDRsOverlap(t *testing.T) {
+	cases := []struct {
+		Name      string
+		A         string
+		B         string
+		Expected  bool
+		ExpectErr bool
+	}{
+		{
+			Name:     "contained",
+			A:        "10.244.0.0/16",
+			B:        "10.244.1.0/24",
+			Expected: true,
+		},
+		{
+			Name: "distinct",
+			A:    "10.244.0.0/16",
+			B:    "10.245.0.0/16",
+		},
+		{
+			Name:      "invalid",
+			A:         "10.244.0.0",
+			B:         "10.245.0.0/16",
+			ExpectErr: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			result, err := cidrsOverlap(tc.A, tc.B)
+			if (err != nil) != tc.ExpectErr {
+				t.Fatalf("cidrsOverlap() error = %v, ExpectErr %v", err, tc.ExpectErr)
+			}
+			if result != tc.Expected {
+				t.Errorf("cidrsOverlap() = %v, expected %v", result, tc.Expected)
+			}
+		})
+	}
+}
@@ -33,4 +33,19 @@ type Node interface {
 	// Possibly remove this method in favor of obtaining this detail with
 	// exec or from the provider
 	IP() (ipv4 string, ipv6 string, err error)
+	// PortMappings should return the ports published from the node on the
+	// host, EG the API server port of control plane and load balancer nodes
+	PortMappings() ([]PortMapping, error)
+}
+
+// PortMapping is a port published from a node on the host
+type PortMapping struct {
+	// ContainerPort is the port within the node container
+	ContainerPort int32 `json:"containerPort"`
+	// HostPort is the port on the host
+	HostPort int32 `json:"hostPort"`
+	// ListenAddress is the host address the port is bound to
+	ListenAddress string `json:"listenAddress,omitempty"`
+	// Protocol is one of TCP, UDP or SCTP
+	Protocol string `json:"protocol"`
 }
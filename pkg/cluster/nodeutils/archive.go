@@ -0,0 +1,94 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeutils
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"path"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+// ArchiveImage is an image contained in an image archive
+type ArchiveImage struct {
+	// ID is the image ID, IE the digest of the image config
+	ID string
+	// RepoTags are the tags of the image, if any
+	RepoTags []string
+}
+
+// ArchiveImages returns the images in the image archive read from r,
+// as written by docker save
+// https://github.com/moby/moby/blob/master/image/spec/v1.2.md
+func ArchiveImages(r io.Reader) ([]ArchiveImage, error) {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil, errors.New("could not find manifest.json in image archive")
+		}
+		if err != nil {
+			return nil, err
+		}
+		if hdr.Name == "manifest.json" {
+			break
+		}
+	}
+	raw, err := ioutil.ReadAll(tr)
+	if err != nil {
+		return nil, err
+	}
+	return parseArchiveManifest(raw)
+}
+
+func parseArchiveManifest(raw []byte) ([]ArchiveImage, error) {
+	manifest := []struct {
+		Config   string
+		RepoTags []string
+	}{}
+	if err := json.Unmarshal(raw, &manifest); err != nil {
+		return nil, errors.Wrap(err, "failed to parse image archive manifest.json")
+	}
+	images := make([]ArchiveImage, len(manifest))
+	for i, m := range manifest {
+		// the config is named for its digest, either <hex>.json or
+		// blobs/sha256/<hex> depending on the docker version
+		images[i] = ArchiveImage{
+			ID:       "sha256:" + strings.TrimSuffix(path.Base(m.Config), ".json"),
+			RepoTags: m.RepoTags,
+		}
+	}
+	return images, nil
+}
+
+// VerifyImage returns an error unless image is present on the node
+// with the image ID id
+func VerifyImage(n nodes.Node, image, id string) error {
+	actual, err := ImageID(n, image)
+	if err != nil {
+		return errors.Wrapf(err, "image %q not found on node %s", image, n.String())
+	}
+	if actual != id {
+		return errors.Errorf("image %q on node %s has ID %q, expected %q", image, n.String(), actual, id)
+	}
+	return nil
+}
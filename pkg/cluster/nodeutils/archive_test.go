@@ -0,0 +1,64 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeutils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseArchiveManifest(t *testing.T) {
+	cases := []struct {
+		Name      string
+		Raw       string
+		Expected  []ArchiveImage
+		ExpectErr bool
+	}{
+		{
+			Name: "docker save",
+			Raw:  `[{"Config":"4a6e0a8e.json","RepoTags":["example.com/app:v1","example.com/app:latest"],"Layers":["a/layer.tar"]}]`,
+			Expected: []ArchiveImage{
+				{ID: "sha256:4a6e0a8e", RepoTags: []string{"example.com/app:v1", "example.com/app:latest"}},
+			},
+		},
+		{
+			Name: "oci layout config and untagged image",
+			Raw:  `[{"Config":"blobs/sha256/4a6e0a8e","RepoTags":null}]`,
+			Expected: []ArchiveImage{
+				{ID: "sha256:4a6e0a8e"},
+			},
+		},
+		{
+			Name:      "invalid",
+			Raw:       `{`,
+			ExpectErr: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			result, err := parseArchiveManifest([]byte(tc.Raw))
+			if (err != nil) != tc.ExpectErr {
+				t.Fatalf("parseArchiveManifest() error = %v, ExpectErr %v", err, tc.ExpectErr)
+			}
+			if !reflect.DeepEqual(result, tc.Expected) {
+				t.Errorf("parseArchiveManifest() = %#v, expected %#v", result, tc.Expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeutils
+
+import (
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+)
+
+// EtcdMetrics returns the prometheus metrics of the local etcd member on
+// the control plane node n
+//
+// The metrics are read with the kubeadm generated etcd healthcheck client
+// certificate, which works regardless of the etcd image contents
+func EtcdMetrics(n nodes.Node) ([]string, error) {
+	lines, err := exec.OutputLines(n.Command(
+		"curl", "--silent", "--show-error", "--fail",
+		"--cacert", "/etc/kubernetes/pki/etcd/ca.crt",
+		"--cert", "/etc/kubernetes/pki/etcd/healthcheck-client.crt",
+		"--key", "/etc/kubernetes/pki/etcd/healthcheck-client.key",
+		// localhost as the member listens on either 127.0.0.1 or ::1
+		"https://localhost:2379/metrics",
+	))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get etcd metrics from node %s", n.String())
+	}
+	return lines, nil
+}
+
+// IsEtcdLeader returns true if the local etcd member on the control plane
+// node n is the etcd cluster leader
+func IsEtcdLeader(n nodes.Node) (bool, error) {
+	lines, err := EtcdMetrics(n)
+	if err != nil {
+		return false, err
+	}
+	for _, line := range lines {
+		if line == "etcd_server_is_leader 1" {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// EtcdctlCommand returns a command running etcdctl with args in the etcd
+// static pod on the control plane node n, configured with the local member's
+// endpoint and the kubeadm generated etcd healthcheck client certificate
+func EtcdctlCommand(n nodes.Node, args ...string) (exec.Cmd, error) {
+	lines, err := exec.OutputLines(n.Command(
+		"crictl", "ps", "--quiet", "--state", "running", "--name", "^etcd$",
+	))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to find etcd on node %s", n.String())
+	}
+	if len(lines) == 0 {
+		return nil, errors.Errorf("etcd is not running on node %s", n.String())
+	}
+	container := lines[0]
+
+	execArgs := []string{"exec", "-i", container}
+	// etcdctl before v3.4 defaults to the v2 API, the images shipping those
+	// are not distroless and have env to select v3
+	version, err := exec.OutputLines(n.Command("crictl", "exec", container, "etcdctl", "version"))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get etcdctl version on node %s", n.String())
+	}
+	for _, line := range version {
+		if strings.HasPrefix(line, "API version: 2") {
+			execArgs = append(execArgs, "env", "ETCDCTL_API=3")
+		}
+	}
+
+	execArgs = append(execArgs,
+		"etcdctl",
+		// localhost as the member listens on either 127.0.0.1 or ::1
+		"--endpoints=https://localhost:2379",
+		"--cacert=/etc/kubernetes/pki/etcd/ca.crt",
+		"--cert=/etc/kubernetes/pki/etcd/healthcheck-client.crt",
+		"--key=/etc/kubernetes/pki/etcd/healthcheck-client.key",
+	)
+	return n.Command("crictl", append(execArgs, args...)...), nil
+}
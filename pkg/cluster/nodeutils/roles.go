@@ -66,6 +66,76 @@ func ExternalLoadBalancerNode(allNodes []nodes.Node) (nodes.Node, error) {
 	return loadBalancerNodes[0], nil
 }
 
+// RegistryNode returns a node handle for the registry node
+// or nil if there isn't one
+func RegistryNode(allNodes []nodes.Node) (nodes.Node, error) {
+	registryNodes, err := SelectNodesByRole(
+		allNodes,
+		constants.RegistryNodeRoleValue,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(registryNodes) < 1 {
+		return nil, nil
+	}
+	if len(registryNodes) > 1 {
+		return nil, errors.Errorf(
+			"unexpected number of %s nodes %d",
+			constants.RegistryNodeRoleValue,
+			len(registryNodes),
+		)
+	}
+	return registryNodes[0], nil
+}
+
+// IsKubernetesRole returns true if nodes with role are part of the Kubernetes
+// cluster, IE every role but those of the nodes kind runs alongside it.
+// Roles other than the ones in constants are custom worker roles.
+func IsKubernetesRole(role string) bool {
+	switch role {
+	case "",
+		constants.ExternalLoadBalancerNodeRoleValue,
+		constants.ExternalEtcdNodeRoleValue,
+		constants.RegistryNodeRoleValue,
+		constants.AuxiliaryNodeRoleValue:
+		return false
+	}
+	return true
+}
+
+// KubernetesNodes returns all nodes that are part of the Kubernetes cluster,
+// IE the control plane, worker and custom role nodes
+func KubernetesNodes(allNodes []nodes.Node) ([]nodes.Node, error) {
+	out := []nodes.Node{}
+	for _, node := range allNodes {
+		nodeRole, err := node.Role()
+		if err != nil {
+			return nil, err
+		}
+		if IsKubernetesRole(nodeRole) {
+			out = append(out, node)
+		}
+	}
+	return out, nil
+}
+
+// WorkerNodes returns the nodes that join the Kubernetes cluster as workers,
+// IE the worker and custom role nodes
+func WorkerNodes(allNodes []nodes.Node) ([]nodes.Node, error) {
+	out := []nodes.Node{}
+	for _, node := range allNodes {
+		nodeRole, err := node.Role()
+		if err != nil {
+			return nil, err
+		}
+		if IsKubernetesRole(nodeRole) && nodeRole != constants.ControlPlaneNodeRoleValue {
+			out = append(out, node)
+		}
+	}
+	return out, nil
+}
+
 func APIServerEndpointNode(allNodes []nodes.Node) (nodes.Node, error) {
 	if n, err := ExternalLoadBalancerNode(allNodes); err != nil {
 		return nil, errors.Wrap(err, "failed to find api-server endpoint node")
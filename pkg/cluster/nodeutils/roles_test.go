@@ -0,0 +1,48 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeutils
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/cluster/constants"
+)
+
+func TestIsKubernetesRole(t *testing.T) {
+	cases := []struct {
+		Role     string
+		Expected bool
+	}{
+		{Role: constants.ControlPlaneNodeRoleValue, Expected: true},
+		{Role: constants.WorkerNodeRoleValue, Expected: true},
+		{Role: "infra", Expected: true},
+		{Role: constants.ExternalLoadBalancerNodeRoleValue},
+		{Role: constants.ExternalEtcdNodeRoleValue},
+		{Role: constants.RegistryNodeRoleValue},
+		{Role: constants.AuxiliaryNodeRoleValue},
+		{Role: ""},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Role, func(t *testing.T) {
+			t.Parallel()
+			if result := IsKubernetesRole(tc.Role); result != tc.Expected {
+				t.Errorf("IsKubernetesRole(%q) = %v, expected %v", tc.Role, result, tc.Expected)
+			}
+		})
+	}
+}
@@ -29,6 +29,7 @@ import (
 	"sigs.k8s.io/kind/pkg/exec"
 
 	"sigs.k8s.io/kind/pkg/internal/cluster/providers/provider/common"
+	"sigs.k8s.io/kind/pkg/internal/cluster/runtime"
 )
 
 // GetControlPlaneEndpoint returns the control plane endpoints for IPv4 and IPv6
@@ -76,6 +77,17 @@ func WriteFile(n nodes.Node, dest, content string) error {
 	return n.Command("cp", "/dev/stdin", dest).SetStdin(strings.NewReader(content)).Run()
 }
 
+// AppendFile appends content to dest on the node, creating it if necessary
+func AppendFile(n nodes.Node, dest, content string) error {
+	// create destination directory
+	err := n.Command("mkdir", "-p", filepath.Dir(dest)).Run()
+	if err != nil {
+		return errors.Wrapf(err, "failed to create directory %s", dest)
+	}
+
+	return n.Command("tee", "-a", dest).SetStdin(strings.NewReader(content)).Run()
+}
+
 // CopyNodeToNode copies file from a to b
 func CopyNodeToNode(a, b nodes.Node, file string) error {
 	// create destination directory
@@ -98,7 +110,11 @@ func CopyNodeToNode(a, b nodes.Node, file string) error {
 }
 
 func LoadImageArchive(n nodes.Node, image io.Reader) error {
-	cmd := n.Command("ctr", "--namespace=k8s.io", "images", "import", "-").SetStdin(image)
+	r, err := runtime.ForNode(n)
+	if err != nil {
+		return err
+	}
+	cmd := n.Command(r.ImportCommand[0], r.ImportCommand[1:]...).SetStdin(image)
 	if err := cmd.Run(); err != nil {
 		return errors.Wrap(err, "failed to load image")
 	}
@@ -18,21 +18,28 @@ package cluster
 
 import (
 	"bytes"
+	"context"
+	"io"
 	"io/ioutil"
 	"os"
+	"time"
 
 	"sigs.k8s.io/kind/pkg/cluster/constants"
 	"sigs.k8s.io/kind/pkg/cluster/create"
 	"sigs.k8s.io/kind/pkg/cluster/nodes"
 	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/globals"
 
 	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
 	internalcontext "sigs.k8s.io/kind/pkg/internal/cluster/context"
 	internalcreate "sigs.k8s.io/kind/pkg/internal/cluster/create"
 	internaldelete "sigs.k8s.io/kind/pkg/internal/cluster/delete"
+	"sigs.k8s.io/kind/pkg/internal/cluster/kubeconfig"
 	internallogs "sigs.k8s.io/kind/pkg/internal/cluster/logs"
+	"sigs.k8s.io/kind/pkg/internal/cluster/providers"
 	"sigs.k8s.io/kind/pkg/internal/cluster/providers/docker"
 	internalprovider "sigs.k8s.io/kind/pkg/internal/cluster/providers/provider"
+	"sigs.k8s.io/kind/pkg/internal/util/units"
 )
 
 // DefaultName is the default cluster name
@@ -41,6 +48,8 @@ const DefaultName = constants.DefaultClusterName
 // Provider is used to perform cluster operations
 type Provider struct {
 	provider internalprovider.Provider
+	// name is the name of the provider, empty if configured by option
+	name string
 }
 
 // NewProvider returns a new provider based on the supplied options
@@ -51,6 +60,7 @@ func NewProvider(options ...ProviderOption) *Provider {
 	}
 	if p.provider == nil {
 		p.provider = docker.NewProvider()
+		p.name = providers.Docker
 	}
 	return p
 }
@@ -65,11 +75,47 @@ func (p *Provider) ic(name string) *internalcontext.Context {
 
 // Create provisions and starts a kubernetes-in-docker cluster
 func (p *Provider) Create(name string, options ...create.ClusterOption) error {
-	return internalcreate.Cluster(p.ic(name), options...)
+	if err := internalcreate.Cluster(p.ic(name), options...); err != nil {
+		return err
+	}
+	// the descriptor is for integrations, the cluster is usable without it
+	if err := p.writeDescriptor(name); err != nil {
+		globals.GetLogger().Warnf("failed to write cluster descriptor: %v", err)
+	}
+	return nil
+}
+
+// DeleteOption is an option for deleting a cluster
+type DeleteOption func(*deleteOptions)
+
+type deleteOptions struct {
+	force bool
+}
+
+// ForceDelete configures Delete to also delete protected clusters
+func ForceDelete(force bool) DeleteOption {
+	return func(o *deleteOptions) {
+		o.force = force
+	}
 }
 
 // Delete tears down a kubernetes-in-docker cluster
-func (p *Provider) Delete(name string) error {
+// Clusters created with create.Protect are only deleted if forced,
+// see ForceDelete
+func (p *Provider) Delete(name string, options ...DeleteOption) error {
+	o := &deleteOptions{}
+	for _, option := range options {
+		option(o)
+	}
+	if !o.force {
+		protected, err := p.provider.IsProtected(name)
+		if err != nil {
+			return err
+		}
+		if protected {
+			return errors.Errorf("cluster %q is protected from deletion and must be force deleted", name)
+		}
+	}
 	return internaldelete.Cluster(p.ic(name))
 }
 
@@ -84,6 +130,25 @@ func (p *Provider) KubeConfigPath(name string) string {
 	return p.ic(name).KubeConfigPath()
 }
 
+// Path returns the directory kind keeps generated files for the cluster in,
+// such as kubeadm configs, applied manifests and a copy of the kubeconfig.
+// The directory is removed when the cluster is deleted.
+func (p *Provider) Path(name string) string {
+	return p.ic(name).Dir()
+}
+
+// TempDir creates a new temporary directory in the cluster directory, see
+// Path, callers should remove it when done
+func (p *Provider) TempDir(name, prefix string) (string, error) {
+	return p.ic(name).TempDir(prefix)
+}
+
+// ArtifactDir creates a new directory for files exported from the cluster in
+// the cluster directory, see Path, it is removed along with the cluster
+func (p *Provider) ArtifactDir(name, prefix string) (string, error) {
+	return p.ic(name).ArtifactDir(prefix)
+}
+
 // KubeConfig returns the KUBECONFIG for the cluster
 // If internal is true, this will contain the internal IP etc.
 // If internal is fale, this will contain the host IP etc.
@@ -110,6 +175,20 @@ func (p *Provider) KubeConfig(name string, internal bool) (string, error) {
 		return buff.String(), nil
 	}
 
+	// the API server port may have changed if the nodes were restarted,
+	// return the current endpoint but leave the file to RefreshKubeConfig
+	kubeConfig, changed, err := kubeconfig.Read(p.ic(name))
+	if err == nil {
+		if changed {
+			globals.GetLogger().Warnf(
+				"kubeconfig %s points at a stale API server endpoint, run `kind kubeconfig refresh --name %s` to update it",
+				p.KubeConfigPath(name), name,
+			)
+		}
+		return string(kubeConfig), nil
+	}
+	globals.GetLogger().Warnf("failed to check kubeconfig API server endpoint: %v", err)
+
 	// TODO(bentheelder): should not depend on host kubeconfig file!
 	f, err := os.Open(p.KubeConfigPath(name))
 	if err != nil {
@@ -123,6 +202,21 @@ func (p *Provider) KubeConfig(name string, internal bool) (string, error) {
 	return string(out), nil
 }
 
+// RefreshKubeConfig updates the cluster's kubeconfig to the current API
+// server endpoint, which changes if the nodes were restarted and got new host
+// ports, along with the cluster descriptor, see Describe. It returns true
+// if the kubeconfig was changed.
+func (p *Provider) RefreshKubeConfig(name string) (bool, error) {
+	changed, err := kubeconfig.Refresh(p.ic(name))
+	if changed {
+		globals.GetLogger().V(0).Infof("Updated kubeconfig %s to the current API server endpoint", p.KubeConfigPath(name))
+		if err := p.writeDescriptor(name); err != nil {
+			globals.GetLogger().Warnf("failed to write cluster descriptor: %v", err)
+		}
+	}
+	return changed, err
+}
+
 // ListNodes returns the list of container IDs for the "nodes" in the cluster
 func (p *Provider) ListNodes(name string) ([]nodes.Node, error) {
 	return p.ic(name).ListNodes()
@@ -134,13 +228,267 @@ func (p *Provider) ListInternalNodes(name string) ([]nodes.Node, error) {
 	return p.ic(name).ListInternalNodes()
 }
 
+// CollectLogsOption is an option for CollectLogs
+type CollectLogsOption func(*collectLogsOptions)
+
+type collectLogsOptions struct {
+	ctx      context.Context
+	roles    []string
+	nodes    []string
+	selector string
+	archive  bool
+	// maxFileSize is parsed into logs.MaxFileSize
+	maxFileSize string
+	logs        internallogs.Options
+}
+
+// CollectLogsRoles limits CollectLogs to the nodes with one of roles,
+// including custom roles
+func CollectLogsRoles(roles ...string) CollectLogsOption {
+	return func(o *collectLogsOptions) {
+		o.roles = append(o.roles, roles...)
+	}
+}
+
+// CollectLogsNodes limits CollectLogs to the nodes with these names, EG
+// kind-worker2, combined with CollectLogsRoles they must have both
+func CollectLogsNodes(names ...string) CollectLogsOption {
+	return func(o *collectLogsOptions) {
+		o.nodes = append(o.nodes, names...)
+	}
+}
+
+// CollectLogsContext configures CollectLogs to abort once ctx is done, EG
+// on interrupt, killing the commands collecting logs and keeping whatever
+// was collected
+func CollectLogsContext(ctx context.Context) CollectLogsOption {
+	return func(o *collectLogsOptions) {
+		o.ctx = ctx
+	}
+}
+
+// CollectLogsNodesFrom configures CollectLogs to collect from the containers
+// matching the docker label selector, key or key=value joined by commas,
+// instead of the nodes of the named cluster
+// The containers must implement the node contract, EG nodes of clusters
+// created by a fork of kind with its own labels
+func CollectLogsNodesFrom(selector string) CollectLogsOption {
+	return func(o *collectLogsOptions) {
+		o.selector = selector
+	}
+}
+
+// CollectLogsMaxConcurrency limits CollectLogs to running at most n
+// commands at once, on the host and in the nodes, so large clusters do not
+// overwhelm slow hosts, n <= 0 does not limit them
+func CollectLogsMaxConcurrency(n int) CollectLogsOption {
+	return func(o *collectLogsOptions) {
+		o.logs.MaxConcurrency = n
+	}
+}
+
+// CollectLogsMapOwnership configures CollectLogs to give the files copied
+// from the nodes to the invoking user, the sudo user if run with sudo,
+// rather than preserving their owner on the nodes when running as root
+func CollectLogsMapOwnership(mapOwnership bool) CollectLogsOption {
+	return func(o *collectLogsOptions) {
+		o.logs.MapOwnership = mapOwnership
+	}
+}
+
+// CollectLogsRedact configures CollectLogs to scrub bearer and bootstrap
+// tokens, certificates, keys and kubeconfig credentials from the collected
+// files before they are written, so the logs can be shared publicly
+// Compressed files, EG rotated logs, cannot be scrubbed and are left out,
+// the content of other binary files is replaced by a note
+func CollectLogsRedact(redact bool) CollectLogsOption {
+	return func(o *collectLogsOptions) {
+		o.logs.Redact = redact
+	}
+}
+
+// CollectLogsMaxFileSize configures CollectLogs to truncate collected files
+// larger than size, EG 100Mi, keeping their head and tail around a marker,
+// so runaway logs do not produce huge exports
+// Compressed files, EG rotated logs, cannot be truncated and are left out if
+// larger
+func CollectLogsMaxFileSize(size string) CollectLogsOption {
+	return func(o *collectLogsOptions) {
+		o.maxFileSize = size
+	}
+}
+
+// CollectLogsHTMLIndex configures CollectLogs to also write an index.html
+// linking the collected files by node, with filtering and search, so the
+// export can be browsed in a web browser
+func CollectLogsHTMLIndex(index bool) CollectLogsOption {
+	return func(o *collectLogsOptions) {
+		o.logs.HTMLIndex = index
+	}
+}
+
+// CollectLogsSpoolDir configures CollectLogs writing an archive, and
+// CollectLogsToWriter, to keep collected files larger than 1 MiB in dir
+// until they are complete, rather than in memory, as the size of each file
+// precedes it in the stream
+func CollectLogsSpoolDir(dir string) CollectLogsOption {
+	return func(o *collectLogsOptions) {
+		o.logs.SpoolDir = dir
+	}
+}
+
+// CollectLogsWindow limits CollectLogs to logs written between since and
+// until, either may be zero to leave that side of the window open
+// Journal and container logs are filtered by entry, other files are skipped
+// if they were last modified before since
+func CollectLogsWindow(since, until time.Time) CollectLogsOption {
+	return func(o *collectLogsOptions) {
+		o.logs.Since = since
+		o.logs.Until = until
+	}
+}
+
+// CollectLogsIncremental configures CollectLogs to append to a previous
+// export into the same dir, only collecting logs written since then and
+// skipping unchanged files, rather than exporting everything again
+func CollectLogsIncremental(incremental bool) CollectLogsOption {
+	return func(o *collectLogsOptions) {
+		o.logs.Incremental = incremental
+	}
+}
+
+// CollectLogsCollectors limits CollectLogs to the named collectors, EG
+// kubelet or serial, by default all of them run, see LogCollectors
+func CollectLogsCollectors(collectors ...string) CollectLogsOption {
+	return func(o *collectLogsOptions) {
+		o.logs.Collectors = append(o.logs.Collectors, collectors...)
+	}
+}
+
+// LogCollectors returns the names of the collectors CollectLogs may run,
+// including those registered with RegisterLogCollector
+func LogCollectors() []string {
+	return internallogs.AllCollectors()
+}
+
+// LogCollector collects an additional artifact from each node for
+// CollectLogs, writing it to <node>/<name>.log in the export
+type LogCollector = internallogs.Collector
+
+// RegisterLogCollector adds c to the collectors CollectLogs runs, so that
+// downstream projects can export their own artifacts, EG CNI specific
+// state, it is meant to be called during initialization
+// The name of c must be unique, lowercase alphanumerics and dashes
+func RegisterLogCollector(c LogCollector) error {
+	return internallogs.RegisterCollector(c)
+}
+
+// CollectLogsArchive configures CollectLogs to write a single gzip
+// compressed tarball to the path dir instead of populating the directory,
+// this cannot be combined with CollectLogsIncremental
+// dir may also be an s3:// (aws cli), gs:// (gsutil) or http(s):// (PUT)
+// location the tarball is streamed to, so it is not stored on local disk,
+// locations ending in / get a logs.tar.gz
+func CollectLogsArchive(archive bool) CollectLogsOption {
+	return func(o *collectLogsOptions) {
+		o.archive = archive
+	}
+}
+
 // CollectLogs will populate dir with cluster logs and other debug files
-func (p *Provider) CollectLogs(name, dir string) error {
+func (p *Provider) CollectLogs(name, dir string, options ...CollectLogsOption) error {
+	opts, n, err := p.collectLogsNodes(name, options)
+	if err != nil {
+		return err
+	}
+	if opts.archive && opts.logs.Incremental {
+		return errors.New("incremental log exports cannot be archived")
+	}
+	if !opts.archive {
+		return internallogs.Collect(opts.ctx, n, dir, opts.logs)
+	}
+
+	// the archive is streamed to the output as logs are collected
+	w, err := internallogs.ParseOutput(dir).Open(opts.ctx)
+	if err != nil {
+		return err
+	}
+	out := &recordingWriter{w: w}
+	collectErr := internallogs.CollectArchive(opts.ctx, n, out, opts.logs)
+	if err := out.err; err != nil {
+		// do not leave a truncated archive in object storage
+		if aborter, ok := w.(interface{ CloseWithError(error) error }); ok {
+			_ = aborter.CloseWithError(err)
+		} else {
+			w.Close()
+		}
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return errors.Wrap(err, "failed to write logs archive")
+	}
+	return collectErr
+}
+
+// recordingWriter records the first error writing to w, so that failing
+// to write the output can be told apart from collectors failing
+type recordingWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (r *recordingWriter) Write(b []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	n, err := r.w.Write(b)
+	if err != nil {
+		r.err = err
+	}
+	return n, err
+}
+
+// CollectLogsToWriter is CollectLogs writing the export to w as an
+// uncompressed tar stream instead of a directory, so that it can be piped
+// elsewhere without touching the filesystem, unless CollectLogsSpoolDir is
+// set, whatever was collected is written even if some collectors fail
+// This cannot be combined with CollectLogsArchive or CollectLogsIncremental
+func (p *Provider) CollectLogsToWriter(name string, w io.Writer, options ...CollectLogsOption) error {
+	opts, n, err := p.collectLogsNodes(name, options)
+	if err != nil {
+		return err
+	}
+	if opts.archive {
+		return errors.New("log exports written to a stream cannot be archived")
+	}
+	return internallogs.CollectToWriter(opts.ctx, n, w, opts.logs)
+}
+
+// collectLogsNodes applies options and returns them with the nodes of the
+// cluster name to collect logs from
+func (p *Provider) collectLogsNodes(name string, options []CollectLogsOption) (*collectLogsOptions, []nodes.Node, error) {
+	opts := &collectLogsOptions{ctx: context.Background()}
+	for _, o := range options {
+		o(opts)
+	}
+	if opts.maxFileSize != "" {
+		size, err := units.ParseBytes(opts.maxFileSize)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "invalid max file size")
+		}
+		opts.logs.MaxFileSize = int64(size)
+	}
 	// TODO: should use ListNodes and Collect should handle nodes differently
 	// based on role ...
-	n, err := p.ListInternalNodes(name)
+	var n []nodes.Node
+	var err error
+	if opts.selector != "" {
+		n, err = p.selectNodes(opts.selector, opts.roles, opts.nodes)
+	} else {
+		n, err = p.logNodes(name, opts.roles, opts.nodes)
+	}
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
-	return internallogs.Collect(n, dir)
+	return opts, n, nil
 }
@@ -0,0 +1,77 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/internal/cluster/apiproxy"
+)
+
+// ProxyAPIServerOption is an option for ProxyAPIServer
+type ProxyAPIServerOption func(*proxyAPIServerOptions)
+
+type proxyAPIServerOptions struct {
+	address          string
+	reconnectTimeout time.Duration
+	started          func(address string)
+}
+
+// ProxyAPIServerAddress configures the host address ProxyAPIServer listens
+// on, either host:port or a host, in which case the port the cluster was
+// last proxied on is reused if free, or a free port picked otherwise
+// The default is 127.0.0.1
+func ProxyAPIServerAddress(address string) ProxyAPIServerOption {
+	return func(o *proxyAPIServerOptions) {
+		o.address = address
+	}
+}
+
+// ProxyAPIServerReconnectTimeout configures how long a client connection
+// waits for the API server to become reachable again, EG while Docker
+// Desktop restarts, before it is closed
+func ProxyAPIServerReconnectTimeout(timeout time.Duration) ProxyAPIServerOption {
+	return func(o *proxyAPIServerOptions) {
+		o.reconnectTimeout = timeout
+	}
+}
+
+// ProxyAPIServerStarted configures ProxyAPIServer to call started with the
+// address it listens on once the kubeconfig points at it
+func ProxyAPIServerStarted(started func(address string)) ProxyAPIServerOption {
+	return func(o *proxyAPIServerOptions) {
+		o.started = started
+	}
+}
+
+// ProxyAPIServer serves a TCP proxy on the host to the cluster's API server
+// until ctx is done, pointing the cluster kubeconfig at it meanwhile.
+// Unlike the published API server port, which changes when the nodes are
+// started again, EG after Docker Desktop restarts, the proxy address is
+// stable: the proxy resolves the published port again whenever the API
+// server is unreachable, holding new connections until it is reachable.
+// Once the proxy stops the kubeconfig is pointed back at the API server.
+func (p *Provider) ProxyAPIServer(ctx context.Context, name string, options ...ProxyAPIServerOption) error {
+	o := &proxyAPIServerOptions{
+		address: "127.0.0.1",
+	}
+	for _, option := range options {
+		option(o)
+	}
+	return apiproxy.Run(ctx, p.ic(name), o.address, o.reconnectTimeout, o.started)
+}
@@ -0,0 +1,223 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/globals"
+
+	internalprovider "sigs.k8s.io/kind/pkg/internal/cluster/providers/provider"
+	"sigs.k8s.io/kind/pkg/internal/util/units"
+)
+
+// resourcesDropIn is the kubelet systemd drop-in reserving the node
+// capacity outside of its resource limits, it sorts after 10-kubeadm.conf
+// so its ExecStart replaces that one
+const resourcesDropIn = "/etc/systemd/system/kubelet.service.d/20-kind-resources.conf"
+
+// NodeResourcesOption is an option for SetNodeResources
+type NodeResourcesOption func(*internalprovider.NodeResources) error
+
+// NodeCPUs limits the node to cpus CPUs, which may be fractional
+func NodeCPUs(cpus float64) NodeResourcesOption {
+	return func(r *internalprovider.NodeResources) error {
+		if cpus <= 0 {
+			return errors.Errorf("invalid cpus %v, must be positive", cpus)
+		}
+		r.CPUs = cpus
+		return nil
+	}
+}
+
+// NodeMemory limits the node to memory, a size such as 8Gi or 512M
+func NodeMemory(memory string) NodeResourcesOption {
+	return func(r *internalprovider.NodeResources) error {
+		bytes, err := units.ParseBytes(memory)
+		if err != nil {
+			return err
+		}
+		if bytes == 0 {
+			return errors.Errorf("invalid memory %q, must be positive", memory)
+		}
+		r.MemoryBytes = bytes
+		return nil
+	}
+}
+
+// SetNodeResources changes the resource limits of the running named node of
+// the cluster, nodeName may omit the cluster name prefix, EG worker2
+//
+// The kubelet reads the capacity of the host rather than the limits of the
+// node, so the difference is reserved for the system instead, making the
+// allocatable resources of the node match its limits. The kubelet is only
+// restarted if that reservation changed.
+func (p *Provider) SetNodeResources(name, nodeName string, options ...NodeResourcesOption) error {
+	r := internalprovider.NodeResources{}
+	for _, option := range options {
+		if err := option(&r); err != nil {
+			return err
+		}
+	}
+	if r.CPUs == 0 && r.MemoryBytes == 0 {
+		return errors.New("at least one of cpus or memory must be set")
+	}
+	node, err := p.node(name, nodeName)
+	if err != nil {
+		return err
+	}
+	if err := p.provider.UpdateNodeResources(node, r); err != nil {
+		return err
+	}
+	p.RecordChange(name, Change{
+		Action:  ChangeReconfigure,
+		Nodes:   []string{node.String()},
+		Details: resourceDetails(r),
+	})
+
+	// keep any reservation for a limit that is not changed
+	var buf bytes.Buffer
+	if err := node.Command(
+		"sh", "-c", `cat "$1" 2>/dev/null || true`, "sh", resourcesDropIn,
+	).SetStdout(&buf).Run(); err != nil {
+		return errors.Wrap(err, "failed to read kubelet drop-in")
+	}
+	previous := buf.String()
+	reserved := reservedResources(previous)
+	hostCPUs, hostMemory, err := hostCapacity(node)
+	if err != nil {
+		return err
+	}
+	if r.CPUs > 0 {
+		delete(reserved, "cpu")
+		if millis := int64(hostCPUs*1000) - int64(r.CPUs*1000); millis > 0 {
+			reserved["cpu"] = fmt.Sprintf("%dm", millis)
+		}
+	}
+	if r.MemoryBytes > 0 {
+		delete(reserved, "memory")
+		if r.MemoryBytes < hostMemory {
+			reserved["memory"] = strconv.FormatUint(hostMemory-r.MemoryBytes, 10)
+		}
+	}
+	dropIn := resourcesDropInFor(reserved)
+	if dropIn == previous {
+		return nil
+	}
+
+	globals.GetLogger().V(0).Infof("Restarting kubelet on %s with system-reserved %q", node.String(), formatReserved(reserved))
+	if dropIn == "" {
+		err = node.Command("rm", "-f", resourcesDropIn).Run()
+	} else {
+		err = nodeutils.WriteFile(node, resourcesDropIn, dropIn)
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to write kubelet drop-in")
+	}
+	if err := node.Command("systemctl", "daemon-reload").Run(); err != nil {
+		return errors.Wrap(err, "failed to reload systemd")
+	}
+	return errors.Wrap(node.Command("systemctl", "restart", "kubelet").Run(), "failed to restart kubelet")
+}
+
+// resourceDetails describes the resources r set for the changelog
+func resourceDetails(r internalprovider.NodeResources) map[string]string {
+	details := map[string]string{}
+	if r.CPUs > 0 {
+		details["cpus"] = strconv.FormatFloat(r.CPUs, 'f', -1, 64)
+	}
+	if r.MemoryBytes > 0 {
+		details["memory"] = strconv.FormatUint(r.MemoryBytes, 10)
+	}
+	return details
+}
+
+// hostCapacity returns the CPUs and memory the kubelet on node detects
+func hostCapacity(node nodes.Node) (cpus float64, memory uint64, err error) {
+	lines, err := exec.OutputLines(node.Command(
+		"sh", "-c", `getconf _NPROCESSORS_ONLN && awk '/^MemTotal:/ { print $2 }' /proc/meminfo`,
+	))
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "failed to read node capacity")
+	}
+	if len(lines) != 2 {
+		return 0, 0, errors.Errorf("unexpected node capacity output: %q", strings.Join(lines, "\n"))
+	}
+	cpus, err = strconv.ParseFloat(lines[0], 64)
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "failed to parse node CPUs")
+	}
+	kib, err := strconv.ParseUint(lines[1], 10, 64)
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "failed to parse node memory")
+	}
+	return cpus, kib * 1024, nil
+}
+
+// reservedArg prefixes the kubelet flag in the resources drop-in
+const reservedArg = "--system-reserved="
+
+// resourcesDropInFor returns the resources drop-in reserving reserved, or
+// "" if nothing is reserved
+func resourcesDropInFor(reserved map[string]string) string {
+	if len(reserved) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(`[Service]
+Environment="KIND_RESOURCES_ARGS=%s%s"
+ExecStart=
+ExecStart=/usr/bin/kubelet $KUBELET_KUBECONFIG_ARGS $KUBELET_CONFIG_ARGS $KUBELET_KUBEADM_ARGS $KUBELET_EXTRA_ARGS $KIND_RESOURCES_ARGS
+`, reservedArg, formatReserved(reserved))
+}
+
+// reservedResources parses the reservation of a resources drop-in
+func reservedResources(dropIn string) map[string]string {
+	reserved := map[string]string{}
+	i := strings.Index(dropIn, reservedArg)
+	if i == -1 {
+		return reserved
+	}
+	value := dropIn[i+len(reservedArg):]
+	if end := strings.IndexAny(value, "\" \n"); end != -1 {
+		value = value[:end]
+	}
+	for _, pair := range strings.Split(value, ",") {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) == 2 {
+			reserved[parts[0]] = parts[1]
+		}
+	}
+	return reserved
+}
+
+// formatReserved formats reserved as the kubelet --system-reserved value
+func formatReserved(reserved map[string]string) string {
+	pairs := []string{}
+	for _, resource := range []string{"cpu", "memory"} {
+		if value, ok := reserved[resource]; ok {
+			pairs = append(pairs, resource+"="+value)
+		}
+	}
+	return strings.Join(pairs, ",")
+}
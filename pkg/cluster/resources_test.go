@@ -0,0 +1,47 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestResourcesDropIn(t *testing.T) {
+	t.Parallel()
+	cases := []map[string]string{
+		{},
+		{"cpu": "4000m"},
+		{"cpu": "500m", "memory": "8589934592"},
+	}
+	for _, reserved := range cases {
+		dropIn := resourcesDropInFor(reserved)
+		if len(reserved) == 0 {
+			if dropIn != "" {
+				t.Errorf("expected no drop-in for no reservation, got %q", dropIn)
+			}
+			continue
+		}
+		if !strings.Contains(dropIn, "$KIND_RESOURCES_ARGS") {
+			t.Errorf("expected the drop-in to pass the reservation to the kubelet, got %q", dropIn)
+		}
+		if actual := reservedResources(dropIn); !reflect.DeepEqual(actual, reserved) {
+			t.Errorf("reservedResources(resourcesDropInFor(%v)) = %v", reserved, actual)
+		}
+	}
+}
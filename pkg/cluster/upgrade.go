@@ -0,0 +1,94 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"bytes"
+	"io"
+	"path"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+// upgradeStagingDir is where UpgradePlan stages files from the new node
+// image on the control plane node
+const upgradeStagingDir = "/kind/upgrade"
+
+// UpgradePlan reports what upgrading a cluster to a node image would do
+type UpgradePlan struct {
+	// Image is the node image planned for
+	Image string
+	// Version is the Kubernetes version of Image
+	Version string
+	// Output is the output of `kubeadm upgrade plan`
+	Output string
+}
+
+// PlanUpgrade stages the kubeadm binary of the node image on a control plane
+// node of the cluster and runs `kubeadm upgrade plan` with it, reporting what
+// an in-place upgrade to image would do without changing the cluster
+func (p *Provider) PlanUpgrade(name, image string) (*UpgradePlan, error) {
+	allNodes, err := p.ListNodes(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(allNodes) == 0 {
+		return nil, errors.Errorf("no nodes found for cluster %q", name)
+	}
+	node, err := nodeutils.BootstrapControlPlaneNode(allNodes)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := node.Command("mkdir", "-p", upgradeStagingDir).Run(); err != nil {
+		return nil, errors.Wrap(err, "failed to create upgrade staging directory")
+	}
+	defer func() {
+		_ = node.Command("rm", "-rf", upgradeStagingDir).Run()
+	}()
+	// docker cp style archives contain the file at their root
+	for _, file := range []string{"/usr/bin/kubeadm", "/kind/version"} {
+		if err := p.provider.CopyFromImage(image, file, func(archive io.Reader) error {
+			return node.Command("tar", "-x", "-C", upgradeStagingDir).SetStdin(archive).Run()
+		}); err != nil {
+			return nil, errors.Wrap(err, "failed to stage files from the new node image")
+		}
+	}
+
+	var buff bytes.Buffer
+	if err := node.Command("cat", path.Join(upgradeStagingDir, "version")).SetStdout(&buff).Run(); err != nil {
+		return nil, errors.Wrap(err, "failed to read the new Kubernetes version")
+	}
+	plan := &UpgradePlan{
+		Image:   image,
+		Version: strings.TrimSpace(buff.String()),
+	}
+
+	buff.Reset()
+	if err := node.Command(
+		path.Join(upgradeStagingDir, "kubeadm"), "upgrade", "plan", plan.Version,
+		// node images are frequently built from pre-release versions
+		"--allow-experimental-upgrades",
+		"--allow-release-candidate-upgrades",
+	).SetStdout(&buff).SetStderr(&buff).Run(); err != nil {
+		return nil, errors.Wrapf(err, "kubeadm upgrade plan failed: %s", buff.String())
+	}
+	plan.Output = buff.String()
+	return plan, nil
+}
@@ -0,0 +1,246 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+
+	"sigs.k8s.io/kind/pkg/internal/cluster/create/actions/preloadedimages"
+	internalprovider "sigs.k8s.io/kind/pkg/internal/cluster/providers/provider"
+)
+
+// Usage is a summary of the resources consumed by a cluster over its lifetime
+type Usage struct {
+	// Name is the cluster name
+	Name string `json:"name"`
+	// Created is when the first node of the cluster was created
+	Created time.Time `json:"created"`
+	// Lifetime is how long the cluster has existed
+	Lifetime string `json:"lifetime"`
+	// PeakMemoryBytes is the sum of the peak memory usage of all nodes
+	PeakMemoryBytes uint64 `json:"peakMemoryBytes"`
+	// CPUSeconds is the total CPU time consumed by all nodes
+	CPUSeconds float64 `json:"cpuSeconds"`
+	// DiskBytes is the disk space consumed by all nodes on top of their images
+	DiskBytes uint64 `json:"diskBytes"`
+	// Images are the distinct images pulled or loaded into the nodes'
+	// container runtime, excluding those shipped in the node image
+	Images []string `json:"images"`
+	// Nodes is the usage of each node, the totals above only include the
+	// nodes that reported no error
+	Nodes []NodeUsage `json:"nodes"`
+}
+
+// NodeUsage is a summary of the resources consumed by a single node
+type NodeUsage struct {
+	Name            string    `json:"name"`
+	Role            string    `json:"role"`
+	Created         time.Time `json:"created"`
+	PeakMemoryBytes uint64    `json:"peakMemoryBytes"`
+	CPUSeconds      float64   `json:"cpuSeconds"`
+	DiskBytes       uint64    `json:"diskBytes"`
+	Images          []string  `json:"images,omitempty"`
+	// Error is set when the usage of the node could not be collected
+	Error string `json:"error,omitempty"`
+}
+
+// Usage reports the resources consumed by the cluster so far, this is
+// intended to be collected just before deleting the cluster to help size
+// the machines running it, EG in CI
+//
+// Peak memory and CPU time are read from the nodes' cgroups, so the nodes
+// must be running, nodes failing to report are listed with their error
+// rather than failing the whole summary
+func (p *Provider) Usage(name string) (*Usage, error) {
+	provider := p.ic(name).Provider()
+	n, err := provider.ListNodes(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(n) == 0 {
+		return nil, errors.Errorf("no nodes found for cluster %q", name)
+	}
+	usage := &Usage{Name: name}
+	images := sets.NewString()
+	for _, node := range n {
+		nodeUsage, err := collectNodeUsage(provider, node)
+		if err != nil {
+			nodeUsage.Error = err.Error()
+			usage.Nodes = append(usage.Nodes, *nodeUsage)
+			continue
+		}
+		if usage.Created.IsZero() || nodeUsage.Created.Before(usage.Created) {
+			usage.Created = nodeUsage.Created
+		}
+		usage.PeakMemoryBytes += nodeUsage.PeakMemoryBytes
+		usage.CPUSeconds += nodeUsage.CPUSeconds
+		usage.DiskBytes += nodeUsage.DiskBytes
+		images.Insert(nodeUsage.Images...)
+		usage.Nodes = append(usage.Nodes, *nodeUsage)
+	}
+	sort.Slice(usage.Nodes, func(i, j int) bool {
+		return usage.Nodes[i].Name < usage.Nodes[j].Name
+	})
+	if !usage.Created.IsZero() {
+		usage.Lifetime = time.Since(usage.Created).Round(time.Second).String()
+	}
+	usage.Images = images.List()
+	return usage, nil
+}
+
+// collectNodeUsage always returns a usage identifying the node, which is
+// partial when an error is returned
+func collectNodeUsage(provider internalprovider.Provider, n nodes.Node) (*NodeUsage, error) {
+	usage := &NodeUsage{Name: n.String()}
+	role, err := n.Role()
+	if err != nil {
+		return usage, err
+	}
+	usage.Role = role
+	stats, err := provider.GetNodeStats(n)
+	if err != nil {
+		return usage, err
+	}
+	usage.Created = stats.Created
+	usage.DiskBytes = stats.DiskBytes
+	if usage.PeakMemoryBytes, err = peakMemory(n); err != nil {
+		return usage, err
+	}
+	if usage.CPUSeconds, err = cpuSeconds(n); err != nil {
+		return usage, err
+	}
+	// only kubernetes nodes run a CRI, other nodes have no images to report
+	if nodeutils.IsKubernetesRole(role) {
+		if usage.Images, err = nodeImages(n); err != nil {
+			return usage, err
+		}
+	}
+	return usage, nil
+}
+
+// peakMemory returns the peak memory usage of the node's cgroup, the node
+// has a private cgroup namespace so the root of /sys/fs/cgroup is the node's
+func peakMemory(n nodes.Node) (uint64, error) {
+	lines, err := exec.OutputLines(n.Command(
+		"sh", "-c",
+		// cgroup v2, falling back to v1
+		"cat /sys/fs/cgroup/memory.peak 2>/dev/null || cat /sys/fs/cgroup/memory/memory.max_usage_in_bytes",
+	))
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to read peak memory usage of node %s", n.String())
+	}
+	if len(lines) != 1 {
+		return 0, errors.Errorf("invalid peak memory usage of node %s: %q", n.String(), lines)
+	}
+	peak, err := strconv.ParseUint(strings.TrimSpace(lines[0]), 10, 64)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to parse peak memory usage of node %s", n.String())
+	}
+	return peak, nil
+}
+
+// cpuSeconds returns the total CPU time consumed by the node's cgroup
+func cpuSeconds(n nodes.Node) (float64, error) {
+	lines, err := exec.OutputLines(n.Command(
+		"sh", "-c",
+		// cgroup v2, falling back to v1 (in nanoseconds)
+		"cat /sys/fs/cgroup/cpu.stat 2>/dev/null || cat /sys/fs/cgroup/cpuacct/cpuacct.usage",
+	))
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to read CPU usage of node %s", n.String())
+	}
+	seconds, err := parseCPUUsage(lines)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to parse CPU usage of node %s", n.String())
+	}
+	return seconds, nil
+}
+
+// parseCPUUsage parses either a cgroup v2 cpu.stat file or a
+// cgroup v1 cpuacct.usage file into seconds
+func parseCPUUsage(lines []string) (float64, error) {
+	// cgroup v1 cpuacct.usage is a single value in nanoseconds
+	if len(lines) == 1 && !strings.Contains(lines[0], " ") {
+		ns, err := strconv.ParseUint(strings.TrimSpace(lines[0]), 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		return float64(ns) / float64(time.Second), nil
+	}
+	// cgroup v2 cpu.stat is a list of "key value" lines
+	for _, line := range lines {
+		parts := strings.Fields(line)
+		if len(parts) == 2 && parts[0] == "usage_usec" {
+			us, err := strconv.ParseUint(parts[1], 10, 64)
+			if err != nil {
+				return 0, err
+			}
+			return float64(us) / float64(time.Second/time.Microsecond), nil
+		}
+	}
+	return 0, errors.Errorf("no CPU usage found in %q", lines)
+}
+
+// nodeImages returns the images present in the node's container runtime
+// other than those recorded as shipped in the node image at creation
+func nodeImages(n nodes.Node) ([]string, error) {
+	lines, err := exec.OutputLines(n.Command(
+		"ctr", "--namespace=k8s.io", "images", "list", "--quiet",
+	))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list images on node %s", n.String())
+	}
+	// clusters created before the images were recorded have no record,
+	// in which case nothing is excluded
+	preloaded, err := exec.OutputLines(n.Command(
+		"sh", "-c", "cat "+preloadedimages.Path+" 2>/dev/null || true",
+	))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read preloaded images on node %s", n.String())
+	}
+	exclude := sets.NewString(preloaded...)
+	images := []string{}
+	for _, image := range filterImageRefs(lines) {
+		if !exclude.Has(image) {
+			images = append(images, image)
+		}
+	}
+	return images, nil
+}
+
+// filterImageRefs drops the digest only references containerd keeps
+// alongside the named references to each image
+func filterImageRefs(refs []string) []string {
+	images := []string{}
+	for _, ref := range refs {
+		if ref == "" || strings.HasPrefix(ref, "sha256:") || strings.Contains(ref, "@sha256:") {
+			continue
+		}
+		images = append(images, ref)
+	}
+	return images
+}
@@ -0,0 +1,202 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+
+	"sigs.k8s.io/kind/pkg/internal/cluster/create/actions/preloadedimages"
+	"sigs.k8s.io/kind/pkg/internal/cluster/providers/provider"
+)
+
+func TestParseCPUUsage(t *testing.T) {
+	cases := []struct {
+		Name      string
+		Lines     []string
+		Expected  float64
+		ExpectErr bool
+	}{
+		{
+			Name: "cgroup v2",
+			Lines: []string{
+				"usage_usec 2500000",
+				"user_usec 2000000",
+				"system_usec 500000",
+			},
+			Expected: 2.5,
+		},
+		{
+			Name:     "cgroup v1",
+			Lines:    []string{"1500000000"},
+			Expected: 1.5,
+		},
+		{
+			Name:      "no usage",
+			Lines:     []string{"user_usec 2000000", "system_usec 500000"},
+			ExpectErr: true,
+		},
+		{
+			Name:      "invalid v1 usage",
+			Lines:     []string{"bogus"},
+			ExpectErr: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			result, err := parseCPUUsage(tc.Lines)
+			if (err != nil) != tc.ExpectErr {
+				t.Fatalf("parseCPUUsage() error = %v, ExpectErr %v", err, tc.ExpectErr)
+			}
+			if result != tc.Expected {
+				t.Errorf("parseCPUUsage() = %v, expected %v", result, tc.Expected)
+			}
+		})
+	}
+}
+
+func TestFilterImageRefs(t *testing.T) {
+	refs := []string{
+		"docker.io/library/nginx:latest",
+		"docker.io/library/nginx@sha256:0d17b565c37bcbd895e9d92315a05c1c3c9a29f762b011a10c54a66cd53c9b31",
+		"sha256:bc9a0695f5712dcaaa09a5adc415a3936ccba13fc2587dfd76b1b8aeea3f221c",
+		"k8s.gcr.io/pause:3.1",
+		"",
+	}
+	expected := []string{
+		"docker.io/library/nginx:latest",
+		"k8s.gcr.io/pause:3.1",
+	}
+	if result := filterImageRefs(refs); !reflect.DeepEqual(result, expected) {
+		t.Errorf("filterImageRefs() = %v, expected %v", result, expected)
+	}
+}
+
+// usageCluster is a fakeCluster whose nodes answer the usage commands
+type usageCluster struct {
+	*fakeCluster
+	// outputs are the outputs of each node's commands by node name and
+	// command line, commands without an output fail
+	outputs map[string]map[string]string
+}
+
+func (c *usageCluster) ListNodes(cluster string) ([]nodes.Node, error) {
+	allNodes := []nodes.Node{}
+	for _, n := range c.nodes {
+		allNodes = append(allNodes, &usageNode{fakeNode: n, outputs: c.outputs[n.name]})
+	}
+	return allNodes, nil
+}
+
+func (c *usageCluster) GetNodeStats(n nodes.Node) (*provider.NodeStats, error) {
+	return &provider.NodeStats{Created: time.Now().Add(-time.Hour), DiskBytes: 100}, nil
+}
+
+type usageNode struct {
+	*fakeNode
+	outputs map[string]string
+}
+
+func (n *usageNode) Command(command string, args ...string) exec.Cmd {
+	cmd := n.fakeNode.Command(command, args...).(*fakeNodeCmd)
+	return &usageNodeCmd{fakeNodeCmd: cmd, outputs: n.outputs}
+}
+
+type usageNodeCmd struct {
+	*fakeNodeCmd
+	outputs map[string]string
+}
+
+func (c *usageNodeCmd) Run() error {
+	line := strings.Join(append([]string{c.command}, c.args...), " ")
+	output, ok := c.outputs[line]
+	if !ok {
+		return &exec.RunError{Command: []string{line}, Inner: errors.New("command failed")}
+	}
+	_, err := io.WriteString(c.stdout, output)
+	return err
+}
+
+func (c *usageNodeCmd) SetStdout(w io.Writer) exec.Cmd { c.stdout = w; return c }
+
+func TestUsage(t *testing.T) {
+	const (
+		memory    = "sh -c cat /sys/fs/cgroup/memory.peak 2>/dev/null || cat /sys/fs/cgroup/memory/memory.max_usage_in_bytes"
+		cpu       = "sh -c cat /sys/fs/cgroup/cpu.stat 2>/dev/null || cat /sys/fs/cgroup/cpuacct/cpuacct.usage"
+		images    = "ctr --namespace=k8s.io images list --quiet"
+		preloaded = "sh -c cat " + preloadedimages.Path + " 2>/dev/null || true"
+	)
+	fake := &usageCluster{
+		fakeCluster: newFakeCluster(2),
+		outputs: map[string]map[string]string{
+			"kind-control-plane": {
+				memory:    "1000\n",
+				cpu:       "usage_usec 1000000\n",
+				images:    "k8s.gcr.io/pause:3.1\ndocker.io/library/nginx:latest\n",
+				preloaded: "k8s.gcr.io/pause:3.1\n",
+			},
+			// no preloaded images were recorded for this node
+			"kind-control-plane2": {
+				memory:    "2000\n",
+				cpu:       "usage_usec 2000000\n",
+				images:    "k8s.gcr.io/pause:3.1\n",
+				preloaded: "",
+			},
+			// the worker fails to report its memory
+			"kind-worker": {
+				cpu:       "usage_usec 4000000\n",
+				images:    "docker.io/library/redis:latest\n",
+				preloaded: "",
+			},
+		},
+	}
+	p := &Provider{provider: fake}
+	usage, err := p.Usage("kind")
+	if err != nil {
+		t.Fatalf("Usage() error = %v", err)
+	}
+	if usage.PeakMemoryBytes != 3000 {
+		t.Errorf("PeakMemoryBytes = %d, expected 3000", usage.PeakMemoryBytes)
+	}
+	if usage.CPUSeconds != 3 {
+		t.Errorf("CPUSeconds = %v, expected 3", usage.CPUSeconds)
+	}
+	if usage.DiskBytes != 200 {
+		t.Errorf("DiskBytes = %d, expected 200", usage.DiskBytes)
+	}
+	expectedImages := []string{"docker.io/library/nginx:latest", "k8s.gcr.io/pause:3.1"}
+	if !reflect.DeepEqual(usage.Images, expectedImages) {
+		t.Errorf("Images = %v, expected %v", usage.Images, expectedImages)
+	}
+	if len(usage.Nodes) != 3 {
+		t.Fatalf("Nodes = %v, expected 3 nodes", usage.Nodes)
+	}
+	for _, n := range usage.Nodes {
+		if (n.Error != "") != (n.Name == "kind-worker") {
+			t.Errorf("node %s Error = %q", n.Name, n.Error)
+		}
+	}
+}
@@ -16,6 +16,11 @@ limitations under the License.
 
 package exec
 
+import (
+	"context"
+	"io"
+)
+
 // DefaultCmder is a LocalCmder instance used for convenience, packages
 // originally using os/exec.Command can instead use pkg/kind/exec.Command
 // which forwards to this instance
@@ -27,3 +32,51 @@ var DefaultCmder = &LocalCmder{}
 func Command(command string, args ...string) Cmd {
 	return DefaultCmder.Command(command, args...)
 }
+
+// CommandContext is a convenience wrapper over DefaultCmder.CommandContext
+func CommandContext(ctx context.Context, command string, args ...string) Cmd {
+	return DefaultCmder.CommandContext(ctx, command, args...)
+}
+
+// CommandWithContext returns a command from cmder bound to ctx if cmder is
+// a ContextCmder, otherwise the command is only not started once ctx is
+// done, it runs to completion if started
+func CommandWithContext(ctx context.Context, cmder Cmder, command string, args ...string) Cmd {
+	if c, ok := cmder.(ContextCmder); ok {
+		return c.CommandContext(ctx, command, args...)
+	}
+	return &startContextCmd{Cmd: cmder.Command(command, args...), ctx: ctx}
+}
+
+// startContextCmd is a Cmd which is not run once ctx is done
+type startContextCmd struct {
+	Cmd
+	ctx context.Context
+}
+
+func (c *startContextCmd) Run() error {
+	if err := c.ctx.Err(); err != nil {
+		return err
+	}
+	return c.Cmd.Run()
+}
+
+func (c *startContextCmd) SetEnv(env ...string) Cmd {
+	c.Cmd.SetEnv(env...)
+	return c
+}
+
+func (c *startContextCmd) SetStdin(r io.Reader) Cmd {
+	c.Cmd.SetStdin(r)
+	return c
+}
+
+func (c *startContextCmd) SetStdout(w io.Writer) Cmd {
+	c.Cmd.SetStdout(w)
+	return c
+}
+
+func (c *startContextCmd) SetStderr(w io.Writer) Cmd {
+	c.Cmd.SetStderr(w)
+	return c
+}
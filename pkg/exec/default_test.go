@@ -0,0 +1,55 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package exec
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// plainCmder only implements Cmder, its commands always fail
+type plainCmder struct{}
+
+func (c *plainCmder) Command(string, ...string) Cmd {
+	return DefaultCmder.Command("false")
+}
+
+func TestCommandWithContext(t *testing.T) {
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		err := CommandWithContext(ctx, DefaultCmder, "sleep", "10").Run()
+		if err == nil {
+			t.Fatalf("expected the command to be killed")
+		}
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Errorf("expected the command to be killed once ctx is done, took %v", elapsed)
+		}
+	})
+	t.Run("not a ContextCmder", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if err := CommandWithContext(ctx, &plainCmder{}, "true").Run(); err != context.Canceled {
+			t.Errorf("expected %v, got %v", context.Canceled, err)
+		}
+		if err := CommandWithContext(context.Background(), DefaultCmder, "true").Run(); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
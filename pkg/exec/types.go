@@ -17,6 +17,7 @@ limitations under the License.
 package exec
 
 import (
+	"context"
 	"fmt"
 	"io"
 )
@@ -39,6 +40,15 @@ type Cmder interface {
 	Command(string, ...string) Cmd
 }
 
+// ContextCmder is optionally implemented by Cmders which can bind commands
+// to a context, see CommandWithContext
+type ContextCmder interface {
+	Cmder
+	// like Command, but the command is killed if the context is done
+	// before it completes, just like os/exec.CommandContext
+	CommandContext(context.Context, string, ...string) Cmd
+}
+
 // RunError represents an error running a Cmd
 type RunError struct {
 	Command []string // [Name Args...]
@@ -24,6 +24,10 @@ func Convertv1alpha3(in *v1alpha3.Cluster) *Cluster {
 	in = in.DeepCopy() // deep copy first to avoid touching the original
 	out := &Cluster{
 		Nodes:                        make([]Node, len(in.Nodes)),
+		ContainerRuntime:             ContainerRuntime(in.ContainerRuntime),
+		KernelModules:                in.KernelModules,
+		PreferDebugImages:            in.PreferDebugImages,
+		Seed:                         in.Seed,
 		KubeadmConfigPatches:         in.KubeadmConfigPatches,
 		KubeadmConfigPatchesJSON6902: make([]PatchJSON6902, len(in.KubeadmConfigPatchesJSON6902)),
 	}
@@ -32,8 +36,107 @@ func Convertv1alpha3(in *v1alpha3.Cluster) *Cluster {
 		convertv1alpha3Node(&in.Nodes[i], &out.Nodes[i])
 	}
 
+	out.Roles = make([]CustomRole, len(in.Roles))
+	for i := range in.Roles {
+		convertv1alpha3CustomRole(&in.Roles[i], &out.Roles[i])
+	}
+
 	convertv1alpha3Networking(&in.Networking, &out.Networking)
 
+	out.ContainerLogs = ContainerLogs{
+		MaxSize:  in.ContainerLogs.MaxSize,
+		MaxFiles: in.ContainerLogs.MaxFiles,
+	}
+
+	out.ComponentEnv = make([]ComponentEnv, len(in.ComponentEnv))
+	for i := range in.ComponentEnv {
+		convertv1alpha3ComponentEnv(&in.ComponentEnv[i], &out.ComponentEnv[i])
+	}
+
+	out.ComponentScheduling = make([]ComponentScheduling, len(in.ComponentScheduling))
+	for i := range in.ComponentScheduling {
+		convertv1alpha3ComponentScheduling(&in.ComponentScheduling[i], &out.ComponentScheduling[i])
+	}
+
+	out.CRDs = make([]CRDSource, len(in.CRDs))
+	for i := range in.CRDs {
+		out.CRDs[i] = CRDSource{
+			Path: in.CRDs[i].Path,
+			URL:  in.CRDs[i].URL,
+		}
+	}
+
+	out.BootstrapManifests = make([]BootstrapManifest, len(in.BootstrapManifests))
+	for i := range in.BootstrapManifests {
+		convertv1alpha3BootstrapManifest(&in.BootstrapManifests[i], &out.BootstrapManifests[i])
+	}
+
+	out.BootstrapSecrets = make([]BootstrapSecret, len(in.BootstrapSecrets))
+	for i := range in.BootstrapSecrets {
+		convertv1alpha3BootstrapSecret(&in.BootstrapSecrets[i], &out.BootstrapSecrets[i])
+	}
+
+	if in.ClusterIdentity != nil {
+		out.ClusterIdentity = &ClusterIdentity{
+			Token:          convertv1alpha3ValueSource(in.ClusterIdentity.Token),
+			CertificateKey: convertv1alpha3ValueSource(in.ClusterIdentity.CertificateKey),
+			CACert:         convertv1alpha3ValueSource(in.ClusterIdentity.CACert),
+			CAKey:          convertv1alpha3ValueSource(in.ClusterIdentity.CAKey),
+		}
+	}
+
+	if in.KubeletCredentialProvider != nil {
+		out.KubeletCredentialProvider = &KubeletCredentialProvider{
+			Config: in.KubeletCredentialProvider.Config,
+			BinDir: in.KubeletCredentialProvider.BinDir,
+		}
+	}
+
+	if in.DNS != nil {
+		out.DNS = &DNS{
+			NodeLocalCache:   in.DNS.NodeLocalCache,
+			NodeLocalCacheIP: in.DNS.NodeLocalCacheIP,
+			Manifest:         in.DNS.Manifest,
+			ServiceIP:        in.DNS.ServiceIP,
+		}
+	}
+
+	if in.ImagePulls != nil {
+		out.ImagePulls = &ImagePulls{
+			PrePull:  append([]string{}, in.ImagePulls.PrePull...),
+			Policy:   ImagePullPolicy(in.ImagePulls.Policy),
+			Parallel: in.ImagePulls.Parallel,
+			Timeout:  in.ImagePulls.Timeout,
+		}
+	}
+
+	if in.Konnectivity != nil {
+		out.Konnectivity = &Konnectivity{
+			Mode:    KonnectivityMode(in.Konnectivity.Mode),
+			Version: in.Konnectivity.Version,
+		}
+	}
+
+	if in.ControlPlaneMetrics != nil {
+		out.ControlPlaneMetrics = &ControlPlaneMetrics{
+			ListenAddress: in.ControlPlaneMetrics.ListenAddress,
+		}
+	}
+
+	if in.SystemdSlice != nil {
+		out.SystemdSlice = &SystemdSlice{
+			Name:      in.SystemdSlice.Name,
+			CPUQuota:  in.SystemdSlice.CPUQuota,
+			MemoryMax: in.SystemdSlice.MemoryMax,
+		}
+	}
+	out.SELinuxLabel = in.SELinuxLabel
+
+	out.AuxiliaryContainers = make([]AuxiliaryContainer, len(in.AuxiliaryContainers))
+	for i := range in.AuxiliaryContainers {
+		convertv1alpha3AuxiliaryContainer(&in.AuxiliaryContainers[i], &out.AuxiliaryContainers[i])
+	}
+
 	for i := range in.KubeadmConfigPatchesJSON6902 {
 		convertv1alphaPatchJSON6902(&in.KubeadmConfigPatchesJSON6902[i], &out.KubeadmConfigPatchesJSON6902[i])
 	}
@@ -41,9 +144,97 @@ func Convertv1alpha3(in *v1alpha3.Cluster) *Cluster {
 	return out
 }
 
+func convertv1alpha3CustomRole(in *v1alpha3.CustomRole, out *CustomRole) {
+	out.Name = NodeRole(in.Name)
+	out.Labels = in.Labels
+	out.Taints = in.Taints
+	out.KubeadmConfigPatches = in.KubeadmConfigPatches
+	out.ExtraMounts = make([]Mount, len(in.ExtraMounts))
+	for i := range in.ExtraMounts {
+		convertv1alpha3Mount(&in.ExtraMounts[i], &out.ExtraMounts[i])
+	}
+}
+
+func convertv1alpha3ComponentEnv(in *v1alpha3.ComponentEnv, out *ComponentEnv) {
+	out.Component = in.Component
+	out.Role = NodeRole(in.Role)
+	out.Env = make([]EnvVar, len(in.Env))
+	for i := range in.Env {
+		out.Env[i] = EnvVar{
+			Name:  in.Env[i].Name,
+			Value: in.Env[i].Value,
+		}
+	}
+}
+
+func convertv1alpha3AuxiliaryContainer(in *v1alpha3.AuxiliaryContainer, out *AuxiliaryContainer) {
+	out.Name = in.Name
+	out.Image = in.Image
+	out.Command = in.Command
+	out.Env = make([]EnvVar, len(in.Env))
+	for i := range in.Env {
+		out.Env[i] = EnvVar{
+			Name:  in.Env[i].Name,
+			Value: in.Env[i].Value,
+		}
+	}
+	out.Privileged = in.Privileged
+	out.ExtraMounts = make([]Mount, len(in.ExtraMounts))
+	for i := range in.ExtraMounts {
+		convertv1alpha3Mount(&in.ExtraMounts[i], &out.ExtraMounts[i])
+	}
+	out.ExtraPortMappings = make([]PortMapping, len(in.ExtraPortMappings))
+	for i := range in.ExtraPortMappings {
+		convertv1alpha3PortMapping(&in.ExtraPortMappings[i], &out.ExtraPortMappings[i])
+	}
+}
+
+func convertv1alpha3ComponentScheduling(in *v1alpha3.ComponentScheduling, out *ComponentScheduling) {
+	out.Component = in.Component
+	out.NodeSelector = in.NodeSelector
+	out.Tolerations = make([]Toleration, len(in.Tolerations))
+	for i := range in.Tolerations {
+		out.Tolerations[i] = Toleration{
+			Key:      in.Tolerations[i].Key,
+			Operator: in.Tolerations[i].Operator,
+			Value:    in.Tolerations[i].Value,
+			Effect:   in.Tolerations[i].Effect,
+		}
+	}
+	out.Resources = ResourceRequirements{
+		Requests: in.Resources.Requests,
+		Limits:   in.Resources.Limits,
+	}
+}
+
+func convertv1alpha3BootstrapManifest(in *v1alpha3.BootstrapManifest, out *BootstrapManifest) {
+	out.Path = in.Path
+	out.URL = in.URL
+	out.Chart = in.Chart
+	out.Repo = in.Repo
+	out.Version = in.Version
+	out.Namespace = in.Namespace
+}
+
+func convertv1alpha3BootstrapSecret(in *v1alpha3.BootstrapSecret, out *BootstrapSecret) {
+	out.Name = in.Name
+	out.Namespace = in.Namespace
+	out.Type = in.Type
+	out.Data = make([]SecretData, len(in.Data))
+	for i := range in.Data {
+		out.Data[i] = SecretData{
+			Key:  in.Data[i].Key,
+			File: in.Data[i].File,
+			Env:  in.Data[i].Env,
+		}
+	}
+}
+
 func convertv1alpha3Node(in *v1alpha3.Node, out *Node) {
 	out.Role = NodeRole(in.Role)
 	out.Image = in.Image
+	out.Platform = in.Platform
+	out.RestartPolicy = in.RestartPolicy
 
 	out.ExtraMounts = make([]Mount, len(in.ExtraMounts))
 	out.ExtraPortMappings = make([]PortMapping, len(in.ExtraPortMappings))
@@ -55,6 +246,35 @@ func convertv1alpha3Node(in *v1alpha3.Node, out *Node) {
 	for i := range in.ExtraPortMappings {
 		convertv1alpha3PortMapping(&in.ExtraPortMappings[i], &out.ExtraPortMappings[i])
 	}
+
+	out.Files = make([]File, len(in.Files))
+	for i := range in.Files {
+		convertv1alpha3File(&in.Files[i], &out.Files[i])
+	}
+
+	out.Ulimits = make([]Ulimit, len(in.Ulimits))
+	for i := range in.Ulimits {
+		out.Ulimits[i] = Ulimit{
+			Name: in.Ulimits[i].Name,
+			Soft: in.Ulimits[i].Soft,
+			Hard: in.Ulimits[i].Hard,
+		}
+	}
+
+	if in.Storage != nil {
+		out.Storage = &NodeStorage{
+			Size:                in.Storage.Size,
+			ImageFilesystemSize: in.Storage.ImageFilesystemSize,
+		}
+	}
+}
+
+func convertv1alpha3File(in *v1alpha3.File, out *File) {
+	out.Path = in.Path
+	out.Content = in.Content
+	out.HostPath = in.HostPath
+	out.Mode = in.Mode
+	out.Owner = in.Owner
 }
 
 func convertv1alphaPatchJSON6902(in *v1alpha3.PatchJSON6902, out *PatchJSON6902) {
@@ -70,9 +290,16 @@ func convertv1alpha3Networking(in *v1alpha3.Networking, out *Networking) {
 	out.IPFamily = ClusterIPFamily(in.IPFamily)
 	out.APIServerPort = in.APIServerPort
 	out.APIServerAddress = in.APIServerAddress
+	out.APIServerHostname = in.APIServerHostname
+	out.AddAPIServerHostnameToHosts = in.AddAPIServerHostnameToHosts
 	out.PodSubnet = in.PodSubnet
 	out.ServiceSubnet = in.ServiceSubnet
 	out.DisableDefaultCNI = in.DisableDefaultCNI
+	out.NodeSubnet = in.NodeSubnet
+	out.NodeGateway = in.NodeGateway
+	out.NodeIPv6Subnet = in.NodeIPv6Subnet
+	out.MTU = in.MTU
+	out.HostNetwork = in.HostNetwork
 }
 
 func convertv1alpha3Mount(in *v1alpha3.Mount, out *Mount) {
@@ -80,6 +307,7 @@ func convertv1alpha3Mount(in *v1alpha3.Mount, out *Mount) {
 	out.HostPath = in.HostPath
 	out.Readonly = in.Readonly
 	out.SelinuxRelabel = in.SelinuxRelabel
+	out.SelinuxRelabelShared = in.SelinuxRelabelShared
 	out.Propagation = MountPropagation(in.Propagation)
 }
 
@@ -87,5 +315,17 @@ func convertv1alpha3PortMapping(in *v1alpha3.PortMapping, out *PortMapping) {
 	out.ContainerPort = in.ContainerPort
 	out.HostPort = in.HostPort
 	out.ListenAddress = in.ListenAddress
+	out.ListenInterface = in.ListenInterface
+	out.ListenIPFamily = PortMappingIPFamily(in.ListenIPFamily)
 	out.Protocol = PortMappingProtocol(in.Protocol)
 }
+
+func convertv1alpha3ValueSource(in *v1alpha3.ValueSource) *ValueSource {
+	if in == nil {
+		return nil
+	}
+	return &ValueSource{
+		File: in.File,
+		Env:  in.Env,
+	}
+}
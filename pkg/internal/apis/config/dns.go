@@ -0,0 +1,73 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"math/big"
+	"net"
+
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+// DNSServiceIP returns the cluster IP of the cluster DNS service, either the
+// configured replacement's or the kube-dns service IP kubeadm picks, which is
+// the tenth address of the service subnet
+func (c *Cluster) DNSServiceIP() (string, error) {
+	if c.DNS != nil && c.DNS.ServiceIP != "" {
+		return c.DNS.ServiceIP, nil
+	}
+	_, subnet, err := net.ParseCIDR(c.Networking.ServiceSubnet)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to parse serviceSubnet")
+	}
+	ip := nthIP(subnet, 10)
+	if ip == nil {
+		return "", errors.Errorf("serviceSubnet %s is too small", c.Networking.ServiceSubnet)
+	}
+	return ip.String(), nil
+}
+
+// KubeletClusterDNS returns the address the kubelet should use as the
+// cluster DNS, or "" if the kubeadm default is fine
+func (c *Cluster) KubeletClusterDNS() string {
+	if c.DNS == nil {
+		return ""
+	}
+	if c.DNS.NodeLocalCache {
+		return c.DNS.NodeLocalCacheIP
+	}
+	if c.DNS.Manifest != "" {
+		return c.DNS.ServiceIP
+	}
+	return ""
+}
+
+// nthIP returns the nth address of subnet or nil if it is out of range
+func nthIP(subnet *net.IPNet, n int64) net.IP {
+	base := big.NewInt(0).SetBytes(subnet.IP)
+	ip := base.Add(base, big.NewInt(n)).Bytes()
+	if len(ip) > len(subnet.IP) {
+		return nil
+	}
+	// left pad back to the address length
+	out := make(net.IP, len(subnet.IP))
+	copy(out[len(out)-len(ip):], ip)
+	if !subnet.Contains(out) {
+		return nil
+	}
+	return out
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"testing"
+)
+
+func TestDNSServiceIP(t *testing.T) {
+	cases := []struct {
+		Name          string
+		ServiceSubnet string
+		DNS           *DNS
+		Expected      string
+		ExpectErr     bool
+	}{
+		{
+			Name:          "ipv4 default",
+			ServiceSubnet: "10.96.0.0/12",
+			Expected:      "10.96.0.10",
+		},
+		{
+			Name:          "ipv6 default",
+			ServiceSubnet: "fd00:10:96::/112",
+			Expected:      "fd00:10:96::a",
+		},
+		{
+			Name:          "replacement service IP",
+			ServiceSubnet: "10.96.0.0/12",
+			DNS:           &DNS{Manifest: "dns.yaml", ServiceIP: "10.96.0.53"},
+			Expected:      "10.96.0.53",
+		},
+		{
+			Name:          "subnet too small",
+			ServiceSubnet: "10.96.0.0/29",
+			ExpectErr:     true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			c := Cluster{DNS: tc.DNS}
+			c.Networking.ServiceSubnet = tc.ServiceSubnet
+			result, err := c.DNSServiceIP()
+			if (err != nil) != tc.ExpectErr {
+				t.Fatalf("DNSServiceIP() error = %v, ExpectErr %v", err, tc.ExpectErr)
+			}
+			if result != tc.Expected {
+				t.Errorf("DNSServiceIP() = %q, expected %q", result, tc.Expected)
+			}
+		})
+	}
+}
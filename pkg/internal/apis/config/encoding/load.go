@@ -47,7 +47,31 @@ func Load(path string) (*config.Cluster, error) {
 	if err != nil {
 		return nil, err
 	}
+	return decode(raw)
+}
+
+// LoadTemplate reads the Go template at path, see Render, renders it with
+// the values merged from the values files at valuesPaths in order, and
+// converts the result like Load
+// If path == "-" then reads from stdin
+func LoadTemplate(path string, valuesPaths ...string) (*config.Cluster, error) {
+	raw, err := readAll(path)
+	if err != nil {
+		return nil, err
+	}
+	values, err := ReadValues(valuesPaths...)
+	if err != nil {
+		return nil, err
+	}
+	rendered, err := Render(raw, values)
+	if err != nil {
+		return nil, err
+	}
+	return decode(rendered)
+}
 
+// decode converts the raw config into a `kind` Config
+func decode(raw []byte) (*config.Cluster, error) {
 	// get kind & apiVersion
 	tm := typeMeta{}
 	if err := yaml.Unmarshal(raw, &tm); err != nil {
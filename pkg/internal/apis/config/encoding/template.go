@@ -0,0 +1,216 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package encoding
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+
+	yaml "gopkg.in/yaml.v3"
+
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+// noValue is what text/template prints for missing values, which are
+// rendered empty instead so they may be given a default
+const noValue = "<no value>"
+
+// ReadValues reads and merges the yaml values files at paths, values in
+// later files override those in earlier ones, maps are merged recursively
+func ReadValues(paths ...string) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+	for _, path := range paths {
+		raw, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, errors.Wrap(err, "error reading values file")
+		}
+		file := map[string]interface{}{}
+		if err := yaml.Unmarshal(raw, &file); err != nil {
+			return nil, errors.Wrapf(err, "unable to decode values file %s", path)
+		}
+		mergeValues(values, file)
+	}
+	return values, nil
+}
+
+// mergeValues merges src into dst, see ReadValues
+func mergeValues(dst, src map[string]interface{}) {
+	for k, v := range src {
+		srcMap, srcIsMap := v.(map[string]interface{})
+		dstMap, dstIsMap := dst[k].(map[string]interface{})
+		if srcIsMap && dstIsMap {
+			mergeValues(dstMap, srcMap)
+			continue
+		}
+		dst[k] = v
+	}
+}
+
+// Render renders the config Go template raw with values, available to the
+// template as .Values, and the helpers in templateFuncs
+// A template looks like:
+//
+//	nodes:
+//	- role: control-plane
+//	{{- range until (.Values.workers | default 2) }}
+//	- role: worker
+//	  image: kindest/node:{{ required "version is required" .Values.version }}
+//	{{- end }}
+func Render(raw []byte, values map[string]interface{}) ([]byte, error) {
+	tmpl, err := template.New("config").Funcs(templateFuncs).Parse(string(raw))
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to parse config template")
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, map[string]interface{}{"Values": values}); err != nil {
+		return nil, errors.Wrap(err, "unable to render config template")
+	}
+	return bytes.Replace(buf.Bytes(), []byte(noValue), nil, -1), nil
+}
+
+// templateFuncs are the helpers available to config templates, named and
+// behaving like their sprig counterparts
+var templateFuncs = template.FuncMap{
+	"default":  defaultValue,
+	"required": required,
+	"empty":    empty,
+	"quote": func(v interface{}) string {
+		return strconv.Quote(toString(v))
+	},
+	"toYaml": func(v interface{}) (string, error) {
+		raw, err := yaml.Marshal(v)
+		return strings.TrimSuffix(string(raw), "\n"), err
+	},
+	"indent": indent,
+	"nindent": func(n int, s string) string {
+		return "\n" + indent(n, s)
+	},
+	"upper":      strings.ToUpper,
+	"lower":      strings.ToLower,
+	"trim":       strings.TrimSpace,
+	"trimSuffix": func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+	"trimPrefix": func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+	"replace":    func(old, new, s string) string { return strings.Replace(s, old, new, -1) },
+	"split":      func(sep, s string) []string { return strings.Split(s, sep) },
+	"join": func(sep string, list interface{}) string {
+		items := []string{}
+		v := reflect.ValueOf(list)
+		if v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+			for i := 0; i < v.Len(); i++ {
+				items = append(items, toString(v.Index(i).Interface()))
+			}
+		}
+		return strings.Join(items, sep)
+	},
+	"list": func(items ...interface{}) []interface{} { return items },
+	"until": func(n interface{}) ([]int, error) {
+		count, err := toInt(n)
+		if err != nil {
+			return nil, err
+		}
+		seq := make([]int, 0, count)
+		for i := 0; i < count; i++ {
+			seq = append(seq, i)
+		}
+		return seq, nil
+	},
+	"add": func(a, b interface{}) (int, error) { return arith(a, b, func(x, y int) int { return x + y }) },
+	"sub": func(a, b interface{}) (int, error) { return arith(a, b, func(x, y int) int { return x - y }) },
+	"mul": func(a, b interface{}) (int, error) { return arith(a, b, func(x, y int) int { return x * y }) },
+	"env": os.Getenv,
+}
+
+// defaultValue returns d if v is empty, see empty
+func defaultValue(d, v interface{}) interface{} {
+	if empty(v) {
+		return d
+	}
+	return v
+}
+
+// required returns an error with msg if v is empty, see empty
+func required(msg string, v interface{}) (interface{}, error) {
+	if empty(v) {
+		return nil, errors.New(msg)
+	}
+	return v, nil
+}
+
+// empty returns true if v is nil or the zero value of its type, or an
+// empty slice or map
+func empty(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Array, reflect.String:
+		return rv.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return rv.IsNil()
+	}
+	return reflect.DeepEqual(v, reflect.Zero(rv.Type()).Interface())
+}
+
+// indent indents each line of s by n spaces
+func indent(n int, s string) string {
+	pad := strings.Repeat(" ", n)
+	return pad + strings.Replace(s, "\n", "\n"+pad, -1)
+}
+
+func toString(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+// toInt converts the numbers values may be decoded to, and numeric strings,
+// to int
+func toInt(v interface{}) (int, error) {
+	switch n := v.(type) {
+	case int:
+		return n, nil
+	case int64:
+		return int(n), nil
+	case uint64:
+		return int(n), nil
+	case float64:
+		return int(n), nil
+	case string:
+		return strconv.Atoi(n)
+	}
+	return 0, errors.Errorf("expected a number, got %T %v", v, v)
+}
+
+func arith(a, b interface{}, op func(x, y int) int) (int, error) {
+	x, err := toInt(a)
+	if err != nil {
+		return 0, err
+	}
+	y, err := toInt(b)
+	if err != nil {
+		return 0, err
+	}
+	return op(x, y), nil
+}
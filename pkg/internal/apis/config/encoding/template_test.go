@@ -0,0 +1,95 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package encoding
+
+import (
+	"testing"
+)
+
+func TestLoadTemplate(t *testing.T) {
+	cases := []struct {
+		TestName      string
+		Values        []string
+		ExpectNodes   int
+		ExpectSubnet  string
+		ExpectVersion string
+		ExpectError   bool
+	}{
+		{
+			TestName:      "values",
+			Values:        []string{"./testdata/template/values.yaml"},
+			ExpectNodes:   4,
+			ExpectSubnet:  "10.100.0.0/16",
+			ExpectVersion: "kindest/node:v1.17.0",
+		},
+		{
+			TestName:      "later values override earlier ones",
+			Values:        []string{"./testdata/template/values.yaml", "./testdata/template/override.yaml"},
+			ExpectNodes:   3,
+			ExpectSubnet:  "10.100.0.0/16",
+			ExpectVersion: "kindest/node:v1.17.0",
+		},
+		{
+			TestName:    "missing required value",
+			Values:      []string{"./testdata/template/override.yaml"},
+			ExpectError: true,
+		},
+		{
+			TestName:    "missing values file",
+			Values:      []string{"./testdata/template/not-a-file.yaml"},
+			ExpectError: true,
+		},
+	}
+	for _, c := range cases {
+		c := c // capture loop variable
+		t.Run(c.TestName, func(t *testing.T) {
+			t.Parallel()
+			cfg, err := LoadTemplate("./testdata/template/cluster.yaml", c.Values...)
+			if c.ExpectError {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(cfg.Nodes) != c.ExpectNodes {
+				t.Errorf("expected %d nodes, got %d", c.ExpectNodes, len(cfg.Nodes))
+			}
+			if cfg.Networking.PodSubnet != c.ExpectSubnet {
+				t.Errorf("expected pod subnet %q, got %q", c.ExpectSubnet, cfg.Networking.PodSubnet)
+			}
+			for _, n := range cfg.Nodes {
+				if n.Image != c.ExpectVersion {
+					t.Errorf("expected image %q, got %q", c.ExpectVersion, n.Image)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderDefaults(t *testing.T) {
+	t.Parallel()
+	raw, err := Render([]byte(`a: {{ .Values.missing | default 3 }}{{ .Values.empty }}`), map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(raw) != "a: 3" {
+		t.Errorf("expected %q, got %q", "a: 3", string(raw))
+	}
+}
@@ -26,9 +26,81 @@ type Cluster struct {
 
 	/* Advanced fields */
 
+	// Roles defines additional roles nodes may have, nodes with these roles
+	// are joined as workers and then labeled and tainted
+	Roles []CustomRole
+
 	// Networking contains cluster wide network settings
 	Networking Networking
 
+	// ContainerLogs configures container log rotation on the kubernetes nodes
+	ContainerLogs ContainerLogs
+
+	// ContainerRuntime is the CRI implementation run inside the nodes,
+	// one of containerd or cri-o
+	ContainerRuntime ContainerRuntime
+
+	// KernelModules are host kernel modules required by the cluster
+	KernelModules []string
+
+	// PreferDebugImages uses the debug variant of each node image if it is
+	// present locally, see defaults.DebugImage
+	PreferDebugImages bool
+
+	// Seed if set derives node MAC addresses and machine IDs from it and
+	// creates the nodes in order, making repeated creations comparable
+	Seed string
+
+	// ComponentEnv sets environment variables on the kubelet and the
+	// control plane static pods, optionally only on nodes with a given role
+	ComponentEnv []ComponentEnv
+
+	// ComponentScheduling sets node selectors, tolerations and resources on
+	// the workloads kind installs, optionally only on one of them
+	ComponentScheduling []ComponentScheduling
+
+	// CRDs are applied in order and waited for before BootstrapManifests
+	CRDs []CRDSource
+
+	// BootstrapManifests are applied in order once the cluster is ready
+	BootstrapManifests []BootstrapManifest
+
+	// BootstrapSecrets are created as soon as the API server is up
+	BootstrapSecrets []BootstrapSecret
+
+	// KubeletCredentialProvider configures kubelet image credential
+	// provider plugins on all kubernetes nodes
+	KubeletCredentialProvider *KubeletCredentialProvider
+
+	// ClusterIdentity supplies the bootstrap token, certificate key and
+	// cluster CA instead of generating them
+	ClusterIdentity *ClusterIdentity
+
+	// DNS configures a node-local DNS cache and / or replaces CoreDNS
+	DNS *DNS
+
+	// ImagePulls configures image pulls on the kubernetes nodes and images
+	// to pre-pull onto them at create
+	ImagePulls *ImagePulls
+
+	// Konnectivity deploys konnectivity and configures the API server's
+	// egress selector to reach the cluster through it
+	Konnectivity *Konnectivity
+
+	// ControlPlaneMetrics publishes the metrics and profiling endpoints of
+	// the control plane components to the host
+	ControlPlaneMetrics *ControlPlaneMetrics
+
+	// SystemdSlice places the node containers in a systemd slice on the host
+	SystemdSlice *SystemdSlice
+
+	// SELinuxLabel is the SELinux label option of the node containers
+	SELinuxLabel string
+
+	// AuxiliaryContainers are started on the cluster network alongside the
+	// nodes and deleted with the cluster
+	AuxiliaryContainers []AuxiliaryContainer
+
 	// KubeadmConfigPatches are applied to the generated kubeadm config as
 	// strategic merge patches to `kustomize build` internally
 	// https://github.com/kubernetes/community/blob/master/contributors/devel/strategic-merge-patch.md
@@ -54,6 +126,10 @@ type Node struct {
 	// If unset a default image will be used, see defaults.Image
 	Image string
 
+	// Platform is the os/arch[/variant] of the node image variant to run,
+	// EG linux/arm64, if unset the container runtime's
+	Platform string
+
 	/* Advanced fields */
 
 	// ExtraMounts describes additional mount points for the node container
@@ -63,6 +139,52 @@ type Node struct {
 	// ExtraPortMappings describes additional port mappings for the node container
 	// binded to a host Port
 	ExtraPortMappings []PortMapping
+
+	// Files are written into the node before the kubelet is started
+	Files []File
+
+	// RestartPolicy is the restart policy of the node container
+	RestartPolicy string
+
+	// Ulimits are the resource limits of the node container
+	Ulimits []Ulimit
+
+	// Storage backs the node's kubelet and container image filesystems with
+	// size limited volumes
+	Storage *NodeStorage
+}
+
+// Ulimit is a resource limit of a node container
+type Ulimit struct {
+	// Name of the limit, EG nofile
+	Name string
+	// Soft limit, -1 for unlimited
+	Soft int64
+	// Hard limit, -1 for unlimited, 0 for the soft limit
+	Hard int64
+}
+
+// NodeStorage configures size limited filesystems for a node
+type NodeStorage struct {
+	// Size limits the kubelet's node filesystem
+	Size string
+	// ImageFilesystemSize places the container runtime's data on a separate
+	// image filesystem of this size
+	ImageFilesystemSize string
+}
+
+// File is a file written into a node
+type File struct {
+	// Path is the absolute path of the file within the node
+	Path string
+	// Content is the inline content of the file
+	Content string
+	// HostPath is the path to a file on the host to copy into the node
+	HostPath string
+	// Mode is the file mode in octal
+	Mode string
+	// Owner is the owner of the file, as user[:group]
+	Owner string
 }
 
 // NodeRole defines possible role for nodes in a Kubernetes cluster managed by `kind`
@@ -76,8 +198,33 @@ const (
 	ControlPlaneRole NodeRole = "control-plane"
 	// WorkerRole identifies a node that hosts a Kubernetes worker
 	WorkerRole NodeRole = "worker"
+	// RegistryRole identifies a node that hosts an OCI registry on the
+	// cluster network, served with TLS certificates generated by kind.
+	// The other nodes are configured to use it as a mirror for
+	// localhost:5000 and <registry-node-name>:5000.
+	// Registry storage may be persisted by adding an extraMount with
+	// containerPath /var/lib/registry.
+	RegistryRole NodeRole = "registry"
 )
 
+// CustomRole is an additional node role, nodes with a custom role are
+// Kubernetes worker nodes
+type CustomRole struct {
+	// Name is the role name nodes use as their Role
+	Name NodeRole
+	// Labels are set on the Kubernetes nodes with this role, in addition to
+	// node-role.kubernetes.io/<name>
+	Labels map[string]string
+	// Taints are set on the Kubernetes nodes with this role, as
+	// key[=value]:effect
+	Taints []string
+	// ExtraMounts are added to the ExtraMounts of each node with this role
+	ExtraMounts []Mount
+	// KubeadmConfigPatches are applied to the generated kubeadm config of
+	// nodes with this role only, after the cluster wide patches
+	KubeadmConfigPatches []string
+}
+
 // Networking contains cluster wide network settings
 type Networking struct {
 	// IPFamily is the network cluster model, currently it can be ipv4 or ipv6
@@ -90,6 +237,13 @@ type Networking struct {
 	//
 	// Defaults to 127.0.0.1
 	APIServerAddress string
+	// APIServerHostname is a DNS name to use as the server address in the
+	// kubeconfig instead of APIServerAddress, it is added to the API server
+	// certificate SANs
+	APIServerHostname string
+	// AddAPIServerHostnameToHosts adds APIServerHostname to the host's
+	// hosts file
+	AddAPIServerHostnameToHosts bool
 	// PodSubnet is the CIDR used for pod IPs
 	// kind will select a default if unspecified
 	PodSubnet string
@@ -99,6 +253,27 @@ type Networking struct {
 	// If DisableDefaultCNI is true, kind will not install the default CNI setup.
 	// Instead the user should install their own CNI after creating the cluster.
 	DisableDefaultCNI bool
+	// NodeSubnet is the IPv4 CIDR of the dedicated network created for the
+	// cluster's node containers
+	NodeSubnet string
+	// NodeGateway is the IPv4 gateway of the cluster's node network
+	NodeGateway string
+	// NodeIPv6Subnet is the IPv6 CIDR of the cluster's node network,
+	// if set IPv6 is enabled on the network
+	NodeIPv6Subnet string
+	// MTU is the MTU of the cluster's node network
+	MTU int32
+	// HostNetwork runs the node of a single node cluster in the host's
+	// network namespace instead of on a dedicated network
+	HostNetwork bool
+}
+
+// ContainerLogs configures the kubelet's container log rotation
+type ContainerLogs struct {
+	// MaxSize is the size a container log is rotated at, EG 10Mi
+	MaxSize string
+	// MaxFiles is the maximum number of log files kept per container
+	MaxFiles int32
 }
 
 // ClusterIPFamily defines cluster network IP family
@@ -111,6 +286,226 @@ const (
 	IPv6Family ClusterIPFamily = "ipv6"
 )
 
+// ComponentEnv contains environment variables for a kubernetes component
+type ComponentEnv struct {
+	// Component is one of kubelet, kube-apiserver, kube-controller-manager,
+	// kube-scheduler or etcd
+	Component string
+	// Role limits the variables to nodes with this role, if set
+	Role NodeRole
+	// Env are the environment variables to set
+	Env []EnvVar
+}
+
+// EnvVar is an environment variable
+type EnvVar struct {
+	Name  string
+	Value string
+}
+
+// AuxiliaryContainer is a container kind runs on the cluster network
+type AuxiliaryContainer struct {
+	// Name is the container's hostname and network alias
+	Name string
+	// Image is the container image
+	Image string
+	// Command overrides the image's command, if set
+	Command []string
+	// Env are environment variables set in the container
+	Env []EnvVar
+	// Privileged runs the container privileged
+	Privileged bool
+	// ExtraMounts are bind mounts into the container
+	ExtraMounts []Mount
+	// ExtraPortMappings publish container ports on the host
+	ExtraPortMappings []PortMapping
+}
+
+// ComponentScheduling customizes the pods of a workload kind installs
+type ComponentScheduling struct {
+	// Component is one of cni, node-local-dns or konnectivity-agent, if
+	// unset the settings apply to all of them
+	Component string
+	// NodeSelector is merged into the pods' node selector
+	NodeSelector map[string]string
+	// Tolerations are added to the pods' tolerations
+	Tolerations []Toleration
+	// Resources are merged into the resources of the pods' containers
+	Resources ResourceRequirements
+}
+
+// Toleration is a pod toleration
+type Toleration struct {
+	Key      string
+	Operator string
+	Value    string
+	Effect   string
+}
+
+// ResourceRequirements are container resource requests and limits,
+// keyed by resource name with quantities as values, EG cpu: 100m
+type ResourceRequirements struct {
+	Requests map[string]string
+	Limits   map[string]string
+}
+
+// CRDSource is a manifest of CustomResourceDefinitions and / or APIServices,
+// exactly one of Path or URL is set
+type CRDSource struct {
+	// Path is a manifest file or directory of manifests on the host
+	Path string
+	// URL is a manifest URL
+	URL string
+}
+
+// BootstrapManifest is a manifest or helm chart applied once the cluster
+// is ready, exactly one of Path, URL or Chart is set
+type BootstrapManifest struct {
+	// Path is a manifest file on the host
+	Path string
+	// URL is a manifest URL
+	URL string
+	// Chart is a helm chart reference, installed with helm on the host
+	Chart string
+	// Repo is the chart repository URL
+	Repo string
+	// Version is the chart version
+	Version string
+	// Namespace the manifest or chart is installed into
+	Namespace string
+}
+
+// BootstrapSecret is a secret created from host files and environment
+type BootstrapSecret struct {
+	// Name of the secret
+	Name string
+	// Namespace of the secret, created if needed
+	Namespace string
+	// Type of the secret
+	Type string
+	// Data are the secret's keys
+	Data []SecretData
+}
+
+// SecretData is a secret key read from exactly one of File or Env
+type SecretData struct {
+	// Key in the secret
+	Key string
+	// File is a host path to read the value from
+	File string
+	// Env is a host environment variable to read the value from
+	Env string
+}
+
+// ClusterIdentity supplies the cluster's kubeadm identity, unset fields are
+// generated as usual
+type ClusterIdentity struct {
+	// Token is the kubeadm bootstrap token nodes join with
+	Token *ValueSource
+	// CertificateKey is the key kubeadm encrypts uploaded certificates with
+	CertificateKey *ValueSource
+	// CACert and CAKey are the PEM encoded cluster CA
+	CACert *ValueSource
+	CAKey  *ValueSource
+}
+
+// ValueSource is a value read from exactly one of File or Env
+type ValueSource struct {
+	// File is a host path to read the value from
+	File string
+	// Env is a host environment variable to read the value from
+	Env string
+}
+
+// KubeletCredentialProvider configures kubelet image credential provider plugins
+type KubeletCredentialProvider struct {
+	// Config is the host path to the kubelet CredentialProviderConfig
+	Config string
+	// BinDir is the host directory containing the plugin binaries
+	BinDir string
+}
+
+// DNS configures the cluster DNS addon
+type DNS struct {
+	// NodeLocalCache deploys node-local-dns and points the kubelet at it
+	NodeLocalCache bool
+	// NodeLocalCacheIP is the link-local address the cache listens on
+	NodeLocalCacheIP string
+	// Manifest is a path or URL to a DNS deployment replacing CoreDNS
+	Manifest string
+	// ServiceIP is the cluster IP of the replacement DNS service
+	ServiceIP string
+}
+
+// Konnectivity configures the konnectivity server and agents
+type Konnectivity struct {
+	// Mode is the proxy protocol between the API server and server
+	Mode KonnectivityMode
+	// Version of the konnectivity images
+	Version string
+}
+
+// KonnectivityMode is the protocol the API server uses to reach the
+// konnectivity server
+type KonnectivityMode string
+
+const (
+	// KonnectivityGRPC uses the grpc proxy protocol
+	KonnectivityGRPC KonnectivityMode = "grpc"
+	// KonnectivityHTTPConnect uses HTTP CONNECT
+	KonnectivityHTTPConnect KonnectivityMode = "http-connect"
+)
+
+// SystemdSlice configures the systemd slice the node containers run in
+type SystemdSlice struct {
+	// Name is the name of the slice, defaulted when the cluster is created
+	Name string
+	// CPUQuota limits the CPU time of the nodes, EG 200%
+	CPUQuota string
+	// MemoryMax limits the memory of the nodes, EG 8G
+	MemoryMax string
+}
+
+// ControlPlaneMetrics configures publishing the control plane components'
+// metrics and profiling endpoints
+type ControlPlaneMetrics struct {
+	// ListenAddress is the host address the endpoints are published on
+	ListenAddress string
+}
+
+// ImagePulls configures how the kubernetes nodes pull images
+type ImagePulls struct {
+	// PrePull images are pulled onto all kubernetes nodes after bring-up
+	PrePull []string
+	// Policy is the cluster wide pull policy, IfNotPresent or Always
+	Policy ImagePullPolicy
+	// Parallel disables the kubelet's serialized image pulls
+	Parallel bool
+	// Timeout bounds each image pull, as a duration string
+	Timeout string
+}
+
+// ImagePullPolicy is the cluster wide image pull policy
+type ImagePullPolicy string
+
+const (
+	// PullIfNotPresent leaves the pod's imagePullPolicy as is
+	PullIfNotPresent ImagePullPolicy = "IfNotPresent"
+	// PullAlways forces every pod to always pull its images via the
+	// AlwaysPullImages admission plugin
+	PullAlways ImagePullPolicy = "Always"
+)
+
+// ContainerRuntime defines the CRI implementation run inside the nodes
+type ContainerRuntime string
+
+const (
+	// ContainerdRuntime sets ContainerRuntime to containerd
+	ContainerdRuntime ContainerRuntime = "containerd"
+	// CRIORuntime sets ContainerRuntime to cri-o
+	CRIORuntime ContainerRuntime = "cri-o"
+)
+
 // PatchJSON6902 represents an inline kustomize json 6902 patch
 // https://tools.ietf.org/html/rfc6902
 type PatchJSON6902 struct {
@@ -151,6 +546,9 @@ type Mount struct {
 	Readonly bool
 	// If set, the mount needs SELinux relabeling.
 	SelinuxRelabel bool
+	// If set along with SelinuxRelabel, the mount is relabeled with the label
+	// shared by all containers rather than one private to the container.
+	SelinuxRelabelShared bool
 	// Requested propagation mode.
 	Propagation MountPropagation
 }
@@ -168,10 +566,26 @@ type PortMapping struct {
 	HostPort int32
 	// TODO: add protocol (tcp/udp) and port-ranges
 	ListenAddress string
+	// ListenInterface is a host interface whose addresses are listened on
+	ListenInterface string
+	// ListenIPFamily restricts the listen addresses to an IP family
+	ListenIPFamily PortMappingIPFamily
 	// Protocol (TCP/UDP)
 	Protocol PortMappingProtocol
 }
 
+// PortMappingIPFamily is the IP family a port mapping listens on
+type PortMappingIPFamily string
+
+const (
+	// PortMappingIPv4 listens on IPv4 addresses only
+	PortMappingIPv4 PortMappingIPFamily = "ipv4"
+	// PortMappingIPv6 listens on IPv6 addresses only
+	PortMappingIPv6 PortMappingIPFamily = "ipv6"
+	// PortMappingDualStack listens on both IPv4 and IPv6 addresses
+	PortMappingDualStack PortMappingIPFamily = "dual"
+)
+
 // MountPropagation represents an "enum" for mount propagation options,
 // see also Mount.
 type MountPropagation int32
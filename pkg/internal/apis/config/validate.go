@@ -18,8 +18,16 @@ package config
 
 import (
 	"net"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/validation"
 
 	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/internal/util/download"
 )
 
 // Validate returns a ConfigErrors with an entry for each problem
@@ -45,12 +53,193 @@ func (c *Cluster) Validate() error {
 		errs = append(errs, errors.Wrapf(err, "invalid serviceSubnet"))
 	}
 
+	// the node network settings are optional, but must be valid if set
+	if c.Networking.NodeSubnet != "" {
+		_, nodeSubnet, err := net.ParseCIDR(c.Networking.NodeSubnet)
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "invalid nodeSubnet"))
+		} else if c.Networking.NodeGateway != "" {
+			gateway := net.ParseIP(c.Networking.NodeGateway)
+			if gateway == nil || !nodeSubnet.Contains(gateway) {
+				errs = append(errs, errors.Errorf("invalid nodeGateway %q, must be an IP within nodeSubnet", c.Networking.NodeGateway))
+			}
+		}
+	} else if c.Networking.NodeGateway != "" {
+		errs = append(errs, errors.New("nodeGateway requires nodeSubnet to be set"))
+	}
+	if c.Networking.NodeIPv6Subnet != "" {
+		if _, _, err := net.ParseCIDR(c.Networking.NodeIPv6Subnet); err != nil {
+			errs = append(errs, errors.Wrapf(err, "invalid nodeIPv6Subnet"))
+		}
+	}
+	// 68 is the minimum IPv4 MTU
+	if c.Networking.MTU != 0 && (c.Networking.MTU < 68 || c.Networking.MTU > 65535) {
+		errs = append(errs, errors.Errorf("invalid mtu %d, must be between 68 and 65535", c.Networking.MTU))
+	}
+
+	if c.Networking.APIServerHostname != "" {
+		for _, msg := range validation.IsDNS1123Subdomain(c.Networking.APIServerHostname) {
+			errs = append(errs, errors.Errorf("invalid apiServerHostname: %s", msg))
+		}
+	} else if c.Networking.AddAPIServerHostnameToHosts {
+		errs = append(errs, errors.New("addAPIServerHostnameToHosts requires apiServerHostname to be set"))
+	}
+
+	// custom roles must not shadow the built in roles or each other
+	customRoles := map[NodeRole]bool{}
+	for i, r := range c.Roles {
+		if err := r.Validate(); err != nil {
+			errs = append(errs, errors.Errorf("invalid role %d: %v", i, err))
+		}
+		if customRoles[r.Name] {
+			errs = append(errs, errors.Errorf("role %q is defined more than once", r.Name))
+		}
+		customRoles[r.Name] = true
+	}
+
+	// componentEnv must target known components on nodes running them
+	for i, e := range c.ComponentEnv {
+		if err := e.validate(customRoles); err != nil {
+			errs = append(errs, errors.Errorf("invalid componentEnv %d: %v", i, err))
+		}
+	}
+
+	// componentScheduling must target workloads kind installs
+	for i, s := range c.ComponentScheduling {
+		if err := s.Validate(); err != nil {
+			errs = append(errs, errors.Errorf("invalid componentScheduling %d: %v", i, err))
+		}
+	}
+
+	// crds must each reference exactly one source
+	for i, s := range c.CRDs {
+		if (s.Path == "") == (s.URL == "") {
+			errs = append(errs, errors.Errorf("invalid crd %d: exactly one of path or url must be set", i))
+		}
+		if s.URL != "" {
+			if _, err := download.Parse(s.URL); err != nil {
+				errs = append(errs, errors.Errorf("invalid crd %d: %v", i, err))
+			}
+		}
+	}
+
+	// bootstrapManifests must each reference exactly one source
+	for i, m := range c.BootstrapManifests {
+		if err := m.Validate(); err != nil {
+			errs = append(errs, errors.Errorf("invalid bootstrapManifest %d: %v", i, err))
+		}
+	}
+
+	// bootstrapSecrets must be valid and unique
+	secrets := map[string]bool{}
+	for i, s := range c.BootstrapSecrets {
+		if err := s.Validate(); err != nil {
+			errs = append(errs, errors.Errorf("invalid bootstrapSecret %d: %v", i, err))
+		}
+		id := s.Namespace + "/" + s.Name
+		if secrets[id] {
+			errs = append(errs, errors.Errorf("invalid bootstrapSecret %d: duplicate secret %s", i, id))
+		}
+		secrets[id] = true
+	}
+
+	// kubeletCredentialProvider requires both the config and plugins
+	if c.KubeletCredentialProvider != nil {
+		if err := c.KubeletCredentialProvider.Validate(); err != nil {
+			errs = append(errs, errors.Wrap(err, "invalid kubeletCredentialProvider"))
+		}
+	}
+
+	// dns addresses must be valid and match the service subnet
+	if c.DNS != nil {
+		if err := c.DNS.validate(c.Networking.ServiceSubnet); err != nil {
+			errs = append(errs, errors.Wrap(err, "invalid dns"))
+		}
+	}
+
+	// containerLogs must be understood by the kubelet
+	if !quantityRE.MatchString(c.ContainerLogs.MaxSize) {
+		errs = append(errs, errors.Errorf("invalid containerLogs maxSize %q", c.ContainerLogs.MaxSize))
+	}
+	if c.ContainerLogs.MaxFiles < 2 {
+		errs = append(errs, errors.Errorf("invalid containerLogs maxFiles %d, must be at least 2", c.ContainerLogs.MaxFiles))
+	}
+
+	// konnectivity must use a known mode
+	if c.Konnectivity != nil {
+		if c.Konnectivity.Mode != KonnectivityGRPC && c.Konnectivity.Mode != KonnectivityHTTPConnect {
+			errs = append(errs, errors.Errorf(
+				"invalid konnectivity mode %q, must be one of %s, %s",
+				c.Konnectivity.Mode, KonnectivityGRPC, KonnectivityHTTPConnect,
+			))
+		}
+	}
+
+	// controlPlaneMetrics are published on a host address
+	if c.ControlPlaneMetrics != nil && net.ParseIP(c.ControlPlaneMetrics.ListenAddress) == nil {
+		errs = append(errs, errors.Errorf("invalid controlPlaneMetrics listenAddress %q", c.ControlPlaneMetrics.ListenAddress))
+	}
+
+	// systemdSlice settings are passed to systemd
+	if c.SystemdSlice != nil {
+		if err := c.SystemdSlice.validate(); err != nil {
+			errs = append(errs, errors.Wrap(err, "invalid systemdSlice"))
+		}
+	}
+
+	// selinuxLabel is passed to docker as a label security option
+	if c.SELinuxLabel != "" && !selinuxLabelRE.MatchString(c.SELinuxLabel) {
+		errs = append(errs, errors.Errorf(
+			"invalid selinuxLabel %q, must be disable or one of user:, role:, type: or level: followed by a value",
+			c.SELinuxLabel,
+		))
+	}
+
+	// clusterIdentity values must each come from exactly one source
+	if c.ClusterIdentity != nil {
+		if err := c.ClusterIdentity.validate(); err != nil {
+			errs = append(errs, errors.Wrap(err, "invalid clusterIdentity"))
+		}
+	}
+
+	// auxiliaryContainers must be valid and uniquely named
+	auxiliaryNames := map[string]bool{}
+	for i, a := range c.AuxiliaryContainers {
+		if err := a.Validate(); err != nil {
+			errs = append(errs, errors.Errorf("invalid auxiliaryContainer %d: %v", i, err))
+		}
+		if auxiliaryNames[a.Name] {
+			errs = append(errs, errors.Errorf("invalid auxiliaryContainer %d: duplicate name %q", i, a.Name))
+		}
+		auxiliaryNames[a.Name] = true
+		// containers are named like the nodes, <cluster>-<role>[N]
+		role := NodeRole(strings.TrimRight(a.Name, "0123456789"))
+		if customRoles[role] || (role != "auxiliary" && isReservedRole(role)) {
+			errs = append(errs, errors.Errorf("invalid auxiliaryContainer %d: name %q conflicts with node names", i, a.Name))
+		}
+	}
+
+	// imagePulls must have a known policy and a parseable timeout
+	if c.ImagePulls != nil {
+		if err := c.ImagePulls.validate(); err != nil {
+			errs = append(errs, errors.Wrap(err, "invalid imagePulls"))
+		}
+	}
+
+	// containerRuntime should be a known runtime
+	if c.ContainerRuntime != ContainerdRuntime && c.ContainerRuntime != CRIORuntime {
+		errs = append(errs, errors.Errorf(
+			"invalid containerRuntime %q, must be one of %s, %s",
+			c.ContainerRuntime, ContainerdRuntime, CRIORuntime,
+		))
+	}
+
 	// validate nodes
 	numByRole := make(map[NodeRole]int32)
 	// All nodes in the config should be valid
 	for i, n := range c.Nodes {
 		// validate the node
-		if err := n.Validate(); err != nil {
+		if err := n.validate(customRoles); err != nil {
 			errs = append(errs, errors.Errorf("invalid configuration for node %d: %v", i, err))
 		}
 		// update role count
@@ -67,23 +256,192 @@ func (c *Cluster) Validate() error {
 		errs = append(errs, errors.Errorf("must have at least one %s node", string(ControlPlaneRole)))
 	}
 
+	// there may be at most one registry node
+	if numByRole[RegistryRole] > 1 {
+		errs = append(errs, errors.Errorf("must have at most one %s node", string(RegistryRole)))
+	}
+
+	// hostNetwork is only possible for a lone control-plane node
+	if c.Networking.HostNetwork {
+		if err := c.validateHostNetwork(); err != nil {
+			errs = append(errs, errors.Wrap(err, "invalid hostNetwork"))
+		}
+	}
+
 	if len(errs) > 0 {
 		return errors.NewAggregate(errs)
 	}
 	return nil
 }
 
+// validateHostNetwork checks the constraints of running the cluster in the
+// host's network namespace, there is no node network to configure and
+// nothing to map ports from
+func (c *Cluster) validateHostNetwork() error {
+	errs := []error{}
+	if len(c.Nodes) != 1 || c.Nodes[0].Role != ControlPlaneRole {
+		errs = append(errs, errors.Errorf("requires exactly one node with role %s", ControlPlaneRole))
+	}
+	for i, n := range c.Nodes {
+		if len(n.ExtraPortMappings) > 0 {
+			errs = append(errs, errors.Errorf("node %d may not set extraPortMappings, ports are reachable directly on the host", i))
+		}
+	}
+	if len(c.AuxiliaryContainers) > 0 {
+		errs = append(errs, errors.New("auxiliaryContainers are not supported"))
+	}
+	// the API server listens on the host directly
+	if c.Networking.APIServerPort != 0 && c.Networking.APIServerPort != 6443 {
+		errs = append(errs, errors.Errorf("apiServerPort %d is not supported, the API server listens on 6443", c.Networking.APIServerPort))
+	}
+	if c.Networking.NodeSubnet != "" || c.Networking.NodeIPv6Subnet != "" || c.Networking.NodeGateway != "" || c.Networking.MTU != 0 {
+		errs = append(errs, errors.New("the node network may not be configured"))
+	}
+	// the seed derives MAC addresses for the node network
+	if c.Seed != "" {
+		errs = append(errs, errors.New("seed is not supported"))
+	}
+	if len(errs) > 0 {
+		return errors.NewAggregate(errs)
+	}
+	return nil
+}
+
+// reservedRoles are the roles of kind's own nodes, custom roles may not
+// use these names
+var reservedRoles = []NodeRole{
+	ControlPlaneRole,
+	WorkerRole,
+	RegistryRole,
+	"external-load-balancer",
+	"external-etcd",
+	"auxiliary",
+}
+
+// isReservedRole returns true if role is one of the reservedRoles
+func isReservedRole(role NodeRole) bool {
+	for _, reserved := range reservedRoles {
+		if role == reserved {
+			return true
+		}
+	}
+	return false
+}
+
+// taintRE matches taints formatted as key[=value]:effect
+var taintRE = regexp.MustCompile(`^([^=:]+)(=([^:]*))?:(NoSchedule|PreferNoSchedule|NoExecute)$`)
+
+// Validate returns a ConfigErrors with an entry for each problem
+// with the CustomRole, or nil if there are none
+func (r *CustomRole) Validate() error {
+	errs := []error{}
+
+	for _, msg := range validation.IsDNS1123Label(string(r.Name)) {
+		errs = append(errs, errors.Errorf("invalid name %q: %s", r.Name, msg))
+	}
+	if isReservedRole(r.Name) {
+		errs = append(errs, errors.Errorf("%q is a built in role", r.Name))
+	}
+	for key, value := range r.Labels {
+		for _, msg := range validation.IsQualifiedName(key) {
+			errs = append(errs, errors.Errorf("invalid label key %q: %s", key, msg))
+		}
+		for _, msg := range validation.IsValidLabelValue(value) {
+			errs = append(errs, errors.Errorf("invalid label value %q: %s", value, msg))
+		}
+	}
+	for _, taint := range r.Taints {
+		match := taintRE.FindStringSubmatch(taint)
+		if match == nil {
+			errs = append(errs, errors.Errorf(
+				"invalid taint %q, must be key[=value]:effect with effect one of NoSchedule, PreferNoSchedule or NoExecute",
+				taint,
+			))
+			continue
+		}
+		for _, msg := range validation.IsQualifiedName(match[1]) {
+			errs = append(errs, errors.Errorf("invalid taint key %q: %s", match[1], msg))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.NewAggregate(errs)
+	}
+	return nil
+}
+
+// validRestartPolicyRE matches the container restart policies
+var validRestartPolicyRE = regexp.MustCompile(`^(no|always|unless-stopped|on-failure(:[0-9]+)?)$`)
+
+// validateListen returns an error if the mapping's listen settings
+// are invalid or conflict
+func (m *PortMapping) validateListen() error {
+	switch m.ListenIPFamily {
+	case "", PortMappingIPv4, PortMappingIPv6, PortMappingDualStack:
+	default:
+		return errors.Errorf("invalid listenIPFamily %q, must be one of ipv4, ipv6 or dual", m.ListenIPFamily)
+	}
+	if m.ListenAddress == "" {
+		return nil
+	}
+	if m.ListenInterface != "" {
+		return errors.New("only one of listenAddress or listenInterface may be set")
+	}
+	ip := net.ParseIP(m.ListenAddress)
+	if ip == nil {
+		return errors.Errorf("invalid listenAddress %q, must be an IP", m.ListenAddress)
+	}
+	isIPv4 := ip.To4() != nil
+	if m.ListenIPFamily == PortMappingDualStack ||
+		(m.ListenIPFamily == PortMappingIPv4 && !isIPv4) ||
+		(m.ListenIPFamily == PortMappingIPv6 && isIPv4) {
+		return errors.Errorf("listenAddress %q does not match listenIPFamily %q", m.ListenAddress, m.ListenIPFamily)
+	}
+	return nil
+}
+
+// validUlimits are the resource limits supported by container runtimes
+var validUlimits = map[string]bool{
+	"core": true, "cpu": true, "data": true, "fsize": true, "locks": true,
+	"memlock": true, "msgqueue": true, "nice": true, "nofile": true,
+	"nproc": true, "rss": true, "rtprio": true, "rttime": true,
+	"sigpending": true, "stack": true,
+}
+
+// Validate returns an error if the Ulimit is not valid
+func (u *Ulimit) Validate() error {
+	if !validUlimits[u.Name] {
+		return errors.Errorf("unknown ulimit name %q", u.Name)
+	}
+	if u.Soft < -1 || u.Hard < -1 {
+		return errors.New("limits must be -1 (unlimited) or greater")
+	}
+	// a hard limit of 0 is the soft limit
+	if u.Hard != 0 && u.Hard != -1 && (u.Soft == -1 || u.Soft > u.Hard) {
+		return errors.Errorf("soft limit %d exceeds hard limit %d", u.Soft, u.Hard)
+	}
+	return nil
+}
+
 // Validate returns a ConfigErrors with an entry for each problem
 // with the Node, or nil if there are none
 func (n *Node) Validate() error {
+	return n.validate(nil)
+}
+
+// validate is Validate, additionally allowing the customRoles
+func (n *Node) validate(customRoles map[NodeRole]bool) error {
 	errs := []error{}
 
 	// validate node role should be one of the expected values
 	switch n.Role {
 	case ControlPlaneRole,
-		WorkerRole:
+		WorkerRole,
+		RegistryRole:
 	default:
-		errs = append(errs, errors.Errorf("%q is not a valid node role", n.Role))
+		if !customRoles[n.Role] {
+			errs = append(errs, errors.Errorf("%q is not a valid node role", n.Role))
+		}
 	}
 
 	// image should be defined
@@ -99,6 +457,283 @@ func (n *Node) Validate() error {
 		if err := validatePort(mapping.ContainerPort); err != nil {
 			errs = append(errs, errors.Wrapf(err, "invalid containerPort"))
 		}
+		if err := mapping.validateListen(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	// restartPolicy must be understood by the container runtime
+	if n.RestartPolicy != "" && !validRestartPolicyRE.MatchString(n.RestartPolicy) {
+		errs = append(errs, errors.Errorf(
+			"%q is not a valid restartPolicy, must be one of no, always, unless-stopped, on-failure or on-failure:<max-retries>",
+			n.RestartPolicy,
+		))
+	}
+
+	// validate ulimits
+	ulimits := map[string]bool{}
+	for _, u := range n.Ulimits {
+		if err := u.Validate(); err != nil {
+			errs = append(errs, errors.Wrapf(err, "invalid ulimit %q", u.Name))
+		}
+		if ulimits[u.Name] {
+			errs = append(errs, errors.Errorf("duplicate ulimit %q", u.Name))
+		}
+		ulimits[u.Name] = true
+	}
+
+	// validate files
+	for _, f := range n.Files {
+		if err := f.Validate(); err != nil {
+			errs = append(errs, errors.Wrapf(err, "invalid file %q", f.Path))
+		}
+	}
+
+	if n.Platform != "" && !platformRE.MatchString(n.Platform) {
+		errs = append(errs, errors.Errorf(
+			"invalid platform %q, must be linux/<arch>[/<variant>]",
+			n.Platform,
+		))
+	}
+
+	// storage is for the kubelet and runtime, which registry nodes lack
+	if n.Storage != nil {
+		if n.Role == RegistryRole {
+			errs = append(errs, errors.New("storage is not supported for registry nodes"))
+		} else if err := n.Storage.Validate(); err != nil {
+			errs = append(errs, errors.Wrap(err, "invalid storage"))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.NewAggregate(errs)
+	}
+
+	return nil
+}
+
+// platformRE matches linux image platforms such as linux/arm64 or
+// linux/arm/v7, node images are linux only
+var platformRE = regexp.MustCompile(`^linux/[a-z0-9_]+(/v[0-9]+)?$`)
+
+// sizeRE matches whole byte quantities such as 512Mi or 20G
+var sizeRE = regexp.MustCompile(`^[0-9]+(k|M|G|T|Ki|Mi|Gi|Ti)?$`)
+
+// Validate returns a ConfigErrors with an entry for each problem
+// with the NodeStorage, or nil if there are none
+func (s *NodeStorage) Validate() error {
+	errs := []error{}
+
+	if s.Size == "" && s.ImageFilesystemSize == "" {
+		errs = append(errs, errors.New("at least one of size, imageFilesystemSize must be set"))
+	}
+	if s.Size != "" && !sizeRE.MatchString(s.Size) {
+		errs = append(errs, errors.Errorf("invalid size %q, must be a quantity of bytes such as 20Gi", s.Size))
+	}
+	if s.ImageFilesystemSize != "" && !sizeRE.MatchString(s.ImageFilesystemSize) {
+		errs = append(errs, errors.Errorf("invalid imageFilesystemSize %q, must be a quantity of bytes such as 20Gi", s.ImageFilesystemSize))
+	}
+
+	if len(errs) > 0 {
+		return errors.NewAggregate(errs)
+	}
+
+	return nil
+}
+
+// Validate returns a ConfigErrors with an entry for each problem
+// with the File, or nil if there are none
+func (f *File) Validate() error {
+	errs := []error{}
+
+	// paths are within the linux node, regardless of the host
+	if !path.IsAbs(f.Path) {
+		errs = append(errs, errors.New("path must be absolute"))
+	}
+	if (f.Content == "") == (f.HostPath == "") {
+		errs = append(errs, errors.New("exactly one of content or hostPath must be set"))
+	}
+	if mode, err := strconv.ParseUint(f.Mode, 8, 32); err != nil || mode > 07777 {
+		errs = append(errs, errors.Errorf("%q is not a valid octal file mode", f.Mode))
+	}
+	if f.Owner == "" || strings.ContainsAny(f.Owner, " \t") {
+		errs = append(errs, errors.Errorf("%q is not a valid owner", f.Owner))
+	}
+
+	if len(errs) > 0 {
+		return errors.NewAggregate(errs)
+	}
+
+	return nil
+}
+
+// Validate returns a ConfigErrors with an entry for each problem
+// with the ComponentEnv, or nil if there are none
+func (e *ComponentEnv) Validate() error {
+	return e.validate(nil)
+}
+
+// validate is Validate, additionally allowing the customRoles
+func (e *ComponentEnv) validate(customRoles map[NodeRole]bool) error {
+	errs := []error{}
+
+	// the kubelet runs on every kubernetes node, the static pods only on
+	// control plane nodes
+	switch e.Component {
+	case "kubelet":
+		if e.Role != "" && e.Role != ControlPlaneRole && e.Role != WorkerRole && !customRoles[e.Role] {
+			errs = append(errs, errors.Errorf("%s does not run on %q nodes", e.Component, e.Role))
+		}
+	case "kube-apiserver",
+		"kube-controller-manager",
+		"kube-scheduler",
+		"etcd":
+		if e.Role != "" && e.Role != ControlPlaneRole {
+			errs = append(errs, errors.Errorf("%s does not run on %q nodes", e.Component, e.Role))
+		}
+	default:
+		errs = append(errs, errors.Errorf("%q is not a valid component", e.Component))
+	}
+
+	for _, env := range e.Env {
+		if env.Name == "" || strings.ContainsAny(env.Name, "= ") {
+			errs = append(errs, errors.Errorf("%q is not a valid environment variable name", env.Name))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.NewAggregate(errs)
+	}
+
+	return nil
+}
+
+// Validate returns a ConfigErrors with an entry for each problem
+// with the AuxiliaryContainer, or nil if there are none
+func (a *AuxiliaryContainer) Validate() error {
+	errs := []error{}
+
+	for _, msg := range validation.IsDNS1123Label(a.Name) {
+		errs = append(errs, errors.Errorf("invalid name %q: %s", a.Name, msg))
+	}
+	if a.Image == "" {
+		errs = append(errs, errors.New("image is a required field"))
+	}
+	for _, env := range a.Env {
+		if env.Name == "" || strings.ContainsAny(env.Name, "= ") {
+			errs = append(errs, errors.Errorf("%q is not a valid environment variable name", env.Name))
+		}
+	}
+	for _, mapping := range a.ExtraPortMappings {
+		if err := validatePort(mapping.HostPort); err != nil {
+			errs = append(errs, errors.Wrapf(err, "invalid hostPort"))
+		}
+		if err := validatePort(mapping.ContainerPort); err != nil {
+			errs = append(errs, errors.Wrapf(err, "invalid containerPort"))
+		}
+		if err := mapping.validateListen(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.NewAggregate(errs)
+	}
+	return nil
+}
+
+// scheduledComponents are the workloads kind installs that
+// ComponentScheduling may target
+var scheduledComponents = []string{"cni", "node-local-dns", "konnectivity-agent"}
+
+// quantityRE matches resource quantities such as 100m, 0.5 or 128Mi
+var quantityRE = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?(m|k|M|G|T|P|E|Ki|Mi|Gi|Ti|Pi|Ei)?$`)
+
+// Validate returns a ConfigErrors with an entry for each problem
+// with the ComponentScheduling, or nil if there are none
+func (s *ComponentScheduling) Validate() error {
+	errs := []error{}
+
+	if s.Component != "" {
+		known := false
+		for _, c := range scheduledComponents {
+			known = known || s.Component == c
+		}
+		if !known {
+			errs = append(errs, errors.Errorf(
+				"%q is not a valid component, must be one of %s",
+				s.Component, strings.Join(scheduledComponents, ", "),
+			))
+		}
+	}
+
+	for key, value := range s.NodeSelector {
+		for _, msg := range validation.IsQualifiedName(key) {
+			errs = append(errs, errors.Errorf("invalid nodeSelector key %q: %s", key, msg))
+		}
+		for _, msg := range validation.IsValidLabelValue(value) {
+			errs = append(errs, errors.Errorf("invalid nodeSelector value %q: %s", value, msg))
+		}
+	}
+
+	for _, t := range s.Tolerations {
+		switch t.Operator {
+		case "", "Equal":
+			if t.Key == "" {
+				errs = append(errs, errors.New("tolerations with operator Equal require a key"))
+			}
+		case "Exists":
+			if t.Value != "" {
+				errs = append(errs, errors.Errorf("toleration %q with operator Exists may not have a value", t.Key))
+			}
+		default:
+			errs = append(errs, errors.Errorf("invalid toleration operator %q, must be Equal or Exists", t.Operator))
+		}
+		switch t.Effect {
+		case "", "NoSchedule", "PreferNoSchedule", "NoExecute":
+		default:
+			errs = append(errs, errors.Errorf(
+				"invalid toleration effect %q, must be one of NoSchedule, PreferNoSchedule or NoExecute",
+				t.Effect,
+			))
+		}
+	}
+
+	for _, resources := range []map[string]string{s.Resources.Requests, s.Resources.Limits} {
+		for name, quantity := range resources {
+			if !quantityRE.MatchString(quantity) {
+				errs = append(errs, errors.Errorf("invalid %s quantity %q", name, quantity))
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.NewAggregate(errs)
+	}
+	return nil
+}
+
+// Validate returns a ConfigErrors with an entry for each problem
+// with the BootstrapManifest, or nil if there are none
+func (m *BootstrapManifest) Validate() error {
+	errs := []error{}
+
+	sources := 0
+	for _, source := range []string{m.Path, m.URL, m.Chart} {
+		if source != "" {
+			sources++
+		}
+	}
+	if sources != 1 {
+		errs = append(errs, errors.New("exactly one of path, url or chart must be set"))
+	}
+	if m.Chart == "" && (m.Repo != "" || m.Version != "") {
+		errs = append(errs, errors.New("repo and version are only valid with chart"))
+	}
+	if m.URL != "" {
+		if _, err := download.Parse(m.URL); err != nil {
+			errs = append(errs, err)
+		}
 	}
 
 	if len(errs) > 0 {
@@ -114,3 +749,181 @@ func validatePort(port int32) error {
 	}
 	return nil
 }
+
+func (d *DNS) validate(serviceSubnet string) error {
+	errs := []error{}
+
+	if d.NodeLocalCacheIP != "" {
+		if !d.NodeLocalCache {
+			errs = append(errs, errors.New("nodeLocalCacheIP requires nodeLocalCache to be enabled"))
+		} else if net.ParseIP(d.NodeLocalCacheIP) == nil {
+			errs = append(errs, errors.Errorf("invalid nodeLocalCacheIP %q", d.NodeLocalCacheIP))
+		}
+	}
+	if download.IsURL(d.Manifest) {
+		if _, err := download.Parse(d.Manifest); err != nil {
+			errs = append(errs, errors.Wrap(err, "invalid manifest"))
+		}
+	}
+	if d.ServiceIP != "" {
+		if d.Manifest == "" {
+			errs = append(errs, errors.New("serviceIP requires manifest to be set"))
+		}
+		ip := net.ParseIP(d.ServiceIP)
+		_, subnet, err := net.ParseCIDR(serviceSubnet)
+		if ip == nil || (err == nil && !subnet.Contains(ip)) {
+			errs = append(errs, errors.Errorf("invalid serviceIP %q, must be an IP within serviceSubnet", d.ServiceIP))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.NewAggregate(errs)
+	}
+
+	return nil
+}
+
+// Validate returns a ConfigErrors with an entry for each problem
+// with the BootstrapSecret, or nil if there are none
+func (s *BootstrapSecret) Validate() error {
+	errs := []error{}
+
+	for _, msg := range validation.IsDNS1123Subdomain(s.Name) {
+		errs = append(errs, errors.Errorf("invalid name %q: %s", s.Name, msg))
+	}
+	for _, msg := range validation.IsDNS1123Label(s.Namespace) {
+		errs = append(errs, errors.Errorf("invalid namespace %q: %s", s.Namespace, msg))
+	}
+	if s.Type == "" {
+		errs = append(errs, errors.New("type must be set"))
+	}
+
+	keys := map[string]bool{}
+	for _, d := range s.Data {
+		for _, msg := range validation.IsConfigMapKey(d.Key) {
+			errs = append(errs, errors.Errorf("invalid key %q: %s", d.Key, msg))
+		}
+		if keys[d.Key] {
+			errs = append(errs, errors.Errorf("duplicate key %q", d.Key))
+		}
+		keys[d.Key] = true
+		if (d.File == "") == (d.Env == "") {
+			errs = append(errs, errors.Errorf("key %q must set exactly one of file or env", d.Key))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.NewAggregate(errs)
+	}
+
+	return nil
+}
+
+func (p *ImagePulls) validate() error {
+	errs := []error{}
+
+	if p.Policy != PullIfNotPresent && p.Policy != PullAlways {
+		errs = append(errs, errors.Errorf(
+			"invalid policy %q, must be one of %s, %s",
+			p.Policy, PullIfNotPresent, PullAlways,
+		))
+	}
+	if p.Timeout != "" {
+		if d, err := time.ParseDuration(p.Timeout); err != nil || d <= 0 {
+			errs = append(errs, errors.Errorf("invalid timeout %q, must be a positive duration", p.Timeout))
+		}
+	}
+	for _, image := range p.PrePull {
+		if image == "" {
+			errs = append(errs, errors.New("prePull images must not be empty"))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.NewAggregate(errs)
+	}
+
+	return nil
+}
+
+// Validate returns a ConfigErrors with an entry for each problem
+// with the KubeletCredentialProvider, or nil if there are none
+func (p *KubeletCredentialProvider) Validate() error {
+	errs := []error{}
+
+	if p.Config == "" {
+		errs = append(errs, errors.New("config must be set"))
+	}
+	if p.BinDir == "" {
+		errs = append(errs, errors.New("binDir must be set"))
+	}
+
+	if len(errs) > 0 {
+		return errors.NewAggregate(errs)
+	}
+
+	return nil
+}
+
+func (i *ClusterIdentity) validate() error {
+	errs := []error{}
+
+	sources := []struct {
+		name   string
+		source *ValueSource
+	}{
+		{"token", i.Token},
+		{"certificateKey", i.CertificateKey},
+		{"caCert", i.CACert},
+		{"caKey", i.CAKey},
+	}
+	for _, s := range sources {
+		if s.source == nil {
+			continue
+		}
+		if (s.source.File == "") == (s.source.Env == "") {
+			errs = append(errs, errors.Errorf("%s must set exactly one of file, env", s.name))
+		}
+	}
+	if (i.CACert == nil) != (i.CAKey == nil) {
+		errs = append(errs, errors.New("caCert and caKey must be set together"))
+	}
+
+	if len(errs) > 0 {
+		return errors.NewAggregate(errs)
+	}
+
+	return nil
+}
+
+// selinuxLabelRE matches docker label security options
+var selinuxLabelRE = regexp.MustCompile(`^(disable|(user|role|type|level):\S+)$`)
+
+// sliceNameRE matches systemd slice names
+var sliceNameRE = regexp.MustCompile(`^[a-zA-Z0-9:_.\\-]+\.slice$`)
+
+// cpuQuotaRE matches systemd CPUQuota percentages
+var cpuQuotaRE = regexp.MustCompile(`^[0-9]+%$`)
+
+// memoryMaxRE matches systemd MemoryMax byte values
+var memoryMaxRE = regexp.MustCompile(`^([0-9]+[KMGT]?|infinity)$`)
+
+func (s *SystemdSlice) validate() error {
+	errs := []error{}
+
+	if s.Name != "" && !sliceNameRE.MatchString(s.Name) {
+		errs = append(errs, errors.Errorf("invalid name %q, must be a systemd slice name ending in .slice", s.Name))
+	}
+	if s.CPUQuota != "" && !cpuQuotaRE.MatchString(s.CPUQuota) {
+		errs = append(errs, errors.Errorf("invalid cpuQuota %q, must be a percentage such as 200%%", s.CPUQuota))
+	}
+	if s.MemoryMax != "" && !memoryMaxRE.MatchString(s.MemoryMax) {
+		errs = append(errs, errors.Errorf("invalid memoryMax %q, must be bytes with an optional K, M, G or T suffix, or infinity", s.MemoryMax))
+	}
+
+	if len(errs) > 0 {
+		return errors.NewAggregate(errs)
+	}
+
+	return nil
+}
@@ -46,6 +46,246 @@ func TestClusterValidate(t *testing.T) {
 			}(),
 			ExpectErrors: 1,
 		},
+		{
+			Name: "bogus containerRuntime",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				SetDefaultsCluster(&c)
+				c.ContainerRuntime = "docker"
+				return c
+			}(),
+			ExpectErrors: 1,
+		},
+		{
+			Name: "bogus bootstrapManifest",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				SetDefaultsCluster(&c)
+				c.BootstrapManifests = []BootstrapManifest{
+					{Path: "crds.yaml"},
+					{Path: "crds.yaml", URL: "https://example.com/crds.yaml"},
+					{URL: "https://example.com/crds.yaml", Version: "v1"},
+				}
+				return c
+			}(),
+			ExpectErrors: 2,
+		},
+		{
+			Name: "incomplete kubeletCredentialProvider",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				SetDefaultsCluster(&c)
+				c.KubeletCredentialProvider = &KubeletCredentialProvider{
+					Config: "credential-provider.yaml",
+				}
+				return c
+			}(),
+			ExpectErrors: 1,
+		},
+		{
+			Name: "bogus dns",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				c.DNS = &DNS{
+					NodeLocalCache: true,
+					ServiceIP:      "10.0.0.10",
+				}
+				SetDefaultsCluster(&c)
+				return c
+			}(),
+			ExpectErrors: 2,
+		},
+		{
+			Name: "bogus bootstrapSecrets",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				c.BootstrapSecrets = []BootstrapSecret{
+					{
+						Name: "creds",
+						Data: []SecretData{
+							{Key: "token", Env: "TOKEN"},
+							{Key: "token", File: "token", Env: "TOKEN"},
+						},
+					},
+					{Name: "creds"},
+				}
+				SetDefaultsCluster(&c)
+				return c
+			}(),
+			ExpectErrors: 2,
+		},
+		{
+			Name: "bogus componentScheduling",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				c.ComponentScheduling = []ComponentScheduling{
+					{
+						NodeSelector: map[string]string{"experiment": "control"},
+						Tolerations: []Toleration{
+							{Operator: "Exists"},
+							{Key: "dedicated", Value: "experiment", Effect: "NoSchedule"},
+						},
+						Resources: ResourceRequirements{
+							Requests: map[string]string{"cpu": "100m", "memory": "64Mi"},
+						},
+					},
+					{
+						Component:   "coredns",
+						Tolerations: []Toleration{{Operator: "Sometimes"}},
+						Resources: ResourceRequirements{
+							Limits: map[string]string{"cpu": "lots"},
+						},
+					},
+					{
+						Component:    "cni",
+						NodeSelector: map[string]string{"-bogus-": "value"},
+					},
+				}
+				SetDefaultsCluster(&c)
+				return c
+			}(),
+			ExpectErrors: 2,
+		},
+		{
+			Name: "bogus auxiliaryContainers",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				c.AuxiliaryContainers = []AuxiliaryContainer{
+					{
+						Name:  "nfs",
+						Image: "erichough/nfs-server",
+						Env:   []EnvVar{{Name: "NFS_EXPORT_0", Value: "/export *(rw,no_root_squash)"}},
+					},
+					{Name: "nfs", Image: "erichough/nfs-server"},
+					{Name: "LDAP"},
+					{Name: "worker2", Image: "osixia/openldap"},
+				}
+				SetDefaultsCluster(&c)
+				return c
+			}(),
+			ExpectErrors: 3,
+		},
+		{
+			Name: "bogus containerLogs",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				c.ContainerLogs = ContainerLogs{MaxSize: "ten megs", MaxFiles: 1}
+				SetDefaultsCluster(&c)
+				return c
+			}(),
+			ExpectErrors: 2,
+		},
+		{
+			Name: "bogus controlPlaneMetrics",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				c.ControlPlaneMetrics = &ControlPlaneMetrics{ListenAddress: "localhost"}
+				SetDefaultsCluster(&c)
+				return c
+			}(),
+			ExpectErrors: 1,
+		},
+		{
+			Name: "crds without a source",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				c.CRDs = []CRDSource{{Path: "crds/"}, {}}
+				SetDefaultsCluster(&c)
+				return c
+			}(),
+			ExpectErrors: 1,
+		},
+		{
+			Name: "crd with a bogus checksum",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				c.CRDs = []CRDSource{{URL: "https://example.com/crds.yaml#sha256=abc"}}
+				SetDefaultsCluster(&c)
+				return c
+			}(),
+			ExpectErrors: 1,
+		},
+		{
+			Name: "bogus systemdSlice",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				c.SystemdSlice = &SystemdSlice{Name: "kind", CPUQuota: "2", MemoryMax: "8G"}
+				SetDefaultsCluster(&c)
+				return c
+			}(),
+			ExpectErrors: 2,
+		},
+		{
+			Name: "bogus selinuxLabel",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				c.SELinuxLabel = "spc_t"
+				SetDefaultsCluster(&c)
+				return c
+			}(),
+			ExpectErrors: 1,
+		},
+		{
+			Name: "bogus clusterIdentity",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				c.ClusterIdentity = &ClusterIdentity{
+					Token:  &ValueSource{File: "token", Env: "TOKEN"},
+					CACert: &ValueSource{File: "ca.crt"},
+				}
+				SetDefaultsCluster(&c)
+				return c
+			}(),
+			ExpectErrors: 2,
+		},
+		{
+			Name: "hostNetwork single node",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				c.Networking.HostNetwork = true
+				SetDefaultsCluster(&c)
+				return c
+			}(),
+			ExpectErrors: 0,
+		},
+		{
+			Name: "hostNetwork with a worker",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				c.Networking.HostNetwork = true
+				n, n2 := Node{}, Node{}
+				SetDefaultsNode(&n)
+				SetDefaultsNode(&n2)
+				n2.Role = WorkerRole
+				c.Nodes = []Node{n, n2}
+				SetDefaultsCluster(&c)
+				return c
+			}(),
+			ExpectErrors: 1,
+		},
+		{
+			Name: "bogus imagePulls",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				c.ImagePulls = &ImagePulls{
+					Policy:  "Sometimes",
+					Timeout: "soon",
+				}
+				SetDefaultsCluster(&c)
+				return c
+			}(),
+			ExpectErrors: 2,
+		},
+		{
+			Name: "bogus apiServerHostname",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				SetDefaultsCluster(&c)
+				c.Networking.APIServerHostname = "Not_A_Hostname"
+				return c
+			}(),
+			ExpectErrors: 1,
+		},
 		{
 			Name: "missing control-plane",
 			Cluster: func() Cluster {
@@ -56,6 +296,19 @@ func TestClusterValidate(t *testing.T) {
 			}(),
 			ExpectErrors: 1,
 		},
+		{
+			Name: "multiple registries",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				n, n2, n3 := Node{}, Node{}, Node{}
+				n2.Role = RegistryRole
+				n3.Role = RegistryRole
+				c.Nodes = []Node{n, n2, n3}
+				SetDefaultsCluster(&c)
+				return c
+			}(),
+			ExpectErrors: 1,
+		},
 		{
 			Name: "bogus node",
 			Cluster: func() Cluster {
@@ -68,6 +321,74 @@ func TestClusterValidate(t *testing.T) {
 			}(),
 			ExpectErrors: 1,
 		},
+		{
+			Name: "custom role node",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				n, n2 := Node{}, Node{}
+				n2.Role = "infra"
+				c.Nodes = []Node{n, n2}
+				c.Roles = []CustomRole{{
+					Name:   "infra",
+					Labels: map[string]string{"example.com/infra": "true"},
+					Taints: []string{"dedicated=infra:NoSchedule"},
+				}}
+				c.ComponentEnv = []ComponentEnv{{Component: "kubelet", Role: "infra"}}
+				SetDefaultsCluster(&c)
+				return c
+			}(),
+			ExpectErrors: 0,
+		},
+		{
+			Name: "invalid custom roles",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				c.Nodes = []Node{{}}
+				c.Roles = []CustomRole{
+					{Name: "worker"},
+					{Name: "storage", Taints: []string{"dedicated=storage"}},
+					{Name: "storage"},
+				}
+				SetDefaultsCluster(&c)
+				return c
+			}(),
+			ExpectErrors: 3,
+		},
+		{
+			Name: "valid node network",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				SetDefaultsCluster(&c)
+				c.Networking.NodeSubnet = "172.30.0.0/16"
+				c.Networking.NodeGateway = "172.30.0.1"
+				c.Networking.NodeIPv6Subnet = "fc00:f853:ccd:1::/64"
+				c.Networking.MTU = 1400
+				return c
+			}(),
+		},
+		{
+			Name: "invalid node network",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				SetDefaultsCluster(&c)
+				c.Networking.NodeSubnet = "172.30.0.0/16"
+				c.Networking.NodeGateway = "10.0.0.1"
+				c.Networking.NodeIPv6Subnet = "fc00::1"
+				c.Networking.MTU = 67
+				return c
+			}(),
+			ExpectErrors: 3,
+		},
+		{
+			Name: "nodeGateway without nodeSubnet",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				SetDefaultsCluster(&c)
+				c.Networking.NodeGateway = "172.30.0.1"
+				return c
+			}(),
+			ExpectErrors: 1,
+		},
 	}
 
 	for _, tc := range cases {
@@ -121,6 +442,11 @@ func TestNodeValidate(t *testing.T) {
 			Node:         newDefaultedNode(WorkerRole),
 			ExpectErrors: 0,
 		},
+		{
+			TestName:     "Canonical registry node",
+			Node:         newDefaultedNode(RegistryRole),
+			ExpectErrors: 0,
+		},
 		{
 			TestName: "Empty image field",
 			Node: func() Node {
@@ -139,6 +465,135 @@ func TestNodeValidate(t *testing.T) {
 			}(),
 			ExpectErrors: 1,
 		},
+		{
+			TestName: "Valid platform",
+			Node: func() Node {
+				cfg := newDefaultedNode(WorkerRole)
+				cfg.Platform = "linux/arm/v7"
+				return cfg
+			}(),
+			ExpectErrors: 0,
+		},
+		{
+			TestName: "Invalid platform",
+			Node: func() Node {
+				cfg := newDefaultedNode(WorkerRole)
+				cfg.Platform = "windows/amd64"
+				return cfg
+			}(),
+			ExpectErrors: 1,
+		},
+		{
+			TestName: "Valid storage",
+			Node: func() Node {
+				cfg := newDefaultedNode(WorkerRole)
+				cfg.Storage = &NodeStorage{Size: "20Gi", ImageFilesystemSize: "8G"}
+				return cfg
+			}(),
+			ExpectErrors: 0,
+		},
+		{
+			TestName: "Invalid storage size",
+			Node: func() Node {
+				cfg := newDefaultedNode(WorkerRole)
+				cfg.Storage = &NodeStorage{ImageFilesystemSize: "1.5Gi"}
+				return cfg
+			}(),
+			ExpectErrors: 1,
+		},
+		{
+			TestName: "Valid file",
+			Node: func() Node {
+				cfg := newDefaultedNode(WorkerRole)
+				cfg.Files = []File{
+					{Path: "/etc/ssl/certs/extra.pem", HostPath: "ca.pem", Mode: "0644", Owner: "root:root"},
+				}
+				return cfg
+			}(),
+			ExpectErrors: 0,
+		},
+		{
+			TestName: "Invalid file",
+			Node: func() Node {
+				cfg := newDefaultedNode(WorkerRole)
+				cfg.Files = []File{
+					{Path: "relative", Content: "a", HostPath: "b", Mode: "0999", Owner: "root"},
+				}
+				return cfg
+			}(),
+			ExpectErrors: 3,
+		},
+		{
+			TestName: "Valid restartPolicy",
+			Node: func() Node {
+				cfg := newDefaultedNode(ControlPlaneRole)
+				cfg.RestartPolicy = "on-failure:3"
+				return cfg
+			}(),
+			ExpectErrors: 0,
+		},
+		{
+			TestName: "Invalid restartPolicy",
+			Node: func() Node {
+				cfg := newDefaultedNode(ControlPlaneRole)
+				cfg.RestartPolicy = "sometimes"
+				return cfg
+			}(),
+			ExpectErrors: 1,
+		},
+		{
+			TestName: "Valid ulimits",
+			Node: func() Node {
+				cfg := newDefaultedNode(ControlPlaneRole)
+				cfg.Ulimits = []Ulimit{
+					{Name: "nofile", Soft: 65536, Hard: 1048576},
+					{Name: "memlock", Soft: -1, Hard: -1},
+					{Name: "nproc", Soft: 4096},
+				}
+				return cfg
+			}(),
+			ExpectErrors: 0,
+		},
+		{
+			TestName: "Invalid ulimits",
+			Node: func() Node {
+				cfg := newDefaultedNode(ControlPlaneRole)
+				cfg.Ulimits = []Ulimit{
+					{Name: "files", Soft: 1},
+					{Name: "nofile", Soft: 2, Hard: 1},
+					{Name: "nofile", Soft: 1},
+				}
+				return cfg
+			}(),
+			ExpectErrors: 3,
+		},
+		{
+			TestName: "Valid port mapping listen settings",
+			Node: func() Node {
+				cfg := newDefaultedNode(ControlPlaneRole)
+				cfg.ExtraPortMappings = []PortMapping{
+					{ContainerPort: 80, HostPort: 8080, ListenAddress: "::1", ListenIPFamily: PortMappingIPv6},
+					{ContainerPort: 81, HostPort: 8081, ListenInterface: "eth1", ListenIPFamily: PortMappingIPv4},
+					{ContainerPort: 82, HostPort: 8082, ListenIPFamily: PortMappingDualStack},
+				}
+				return cfg
+			}(),
+			ExpectErrors: 0,
+		},
+		{
+			TestName: "Invalid port mapping listen settings",
+			Node: func() Node {
+				cfg := newDefaultedNode(ControlPlaneRole)
+				cfg.ExtraPortMappings = []PortMapping{
+					{ContainerPort: 80, HostPort: 8080, ListenAddress: "127.0.0.1", ListenIPFamily: PortMappingIPv6},
+					{ContainerPort: 81, HostPort: 8081, ListenAddress: "127.0.0.1", ListenInterface: "eth1"},
+					{ContainerPort: 82, HostPort: 8082, ListenIPFamily: "ipv5"},
+					{ContainerPort: 83, HostPort: 8083, ListenAddress: "localhost"},
+				}
+				return cfg
+			}(),
+			ExpectErrors: 4,
+		},
 		{
 			TestName: "Unknown role field",
 			Node: func() Node {
@@ -0,0 +1,260 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package apiproxy implements a host TCP proxy to the API server of a
+// cluster, so clients have an address that survives the published API
+// server port changing, EG when Docker Desktop restarts
+package apiproxy
+
+import (
+	gocontext "context"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/internal/cluster/context"
+	"sigs.k8s.io/kind/pkg/internal/cluster/kubeconfig"
+)
+
+const (
+	// dialTimeout bounds connecting to the API server once
+	dialTimeout = 5 * time.Second
+	// retryInterval is how long to wait before resolving the endpoint again
+	// when the API server is unreachable
+	retryInterval = time.Second
+	// DefaultReconnectTimeout is how long a client connection waits for the
+	// API server to become reachable again, such as while Docker Desktop
+	// restarts and the nodes are started again
+	DefaultReconnectTimeout = 2 * time.Minute
+)
+
+// Proxy forwards the connections it accepts on a host address to the API
+// server endpoint, resolving it again whenever it becomes unreachable
+type Proxy struct {
+	listener net.Listener
+	resolve  func() (string, error)
+	// reconnectTimeout bounds waiting for an unreachable API server
+	reconnectTimeout time.Duration
+
+	mu      sync.Mutex
+	backend string
+	conns   map[net.Conn]struct{}
+}
+
+// New returns a Proxy listening on the TCP address for connections to the
+// endpoint returned by resolve, which is called again whenever the last
+// endpoint is unreachable
+// If reconnectTimeout is not positive DefaultReconnectTimeout is used
+func New(address string, resolve func() (string, error), reconnectTimeout time.Duration) (*Proxy, error) {
+	if reconnectTimeout <= 0 {
+		reconnectTimeout = DefaultReconnectTimeout
+	}
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to listen on %s", address)
+	}
+	return &Proxy{
+		listener:         listener,
+		resolve:          resolve,
+		reconnectTimeout: reconnectTimeout,
+		conns:            map[net.Conn]struct{}{},
+	}, nil
+}
+
+// Addr returns the host:port the proxy listens on
+func (p *Proxy) Addr() string {
+	return p.listener.Addr().String()
+}
+
+// Serve proxies connections until ctx is done, then closes the listener and
+// every proxied connection
+func (p *Proxy) Serve(ctx gocontext.Context) error {
+	wg := &sync.WaitGroup{}
+	defer wg.Wait()
+	go func() {
+		<-ctx.Done()
+		p.listener.Close()
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		for conn := range p.conns {
+			conn.Close()
+		}
+		p.conns = nil
+	}()
+	for {
+		conn, err := p.listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return errors.Wrap(err, "failed to accept connection")
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.proxy(ctx, conn)
+		}()
+	}
+}
+
+// proxy forwards client to the API server until either side closes
+func (p *Proxy) proxy(ctx gocontext.Context, client net.Conn) {
+	if !p.track(client) {
+		return
+	}
+	defer p.untrack(client)
+	backend, err := p.dial(ctx)
+	if err != nil {
+		return
+	}
+	if !p.track(backend) {
+		return
+	}
+	defer p.untrack(backend)
+	done := make(chan struct{}, 2)
+	go func() {
+		_, _ = io.Copy(backend, client)
+		closeWrite(backend)
+		done <- struct{}{}
+	}()
+	go func() {
+		_, _ = io.Copy(client, backend)
+		closeWrite(client)
+		done <- struct{}{}
+	}()
+	<-done
+	<-done
+}
+
+// dial connects to the API server, resolving its endpoint again and
+// retrying while it is unreachable, until reconnectTimeout passes
+func (p *Proxy) dial(ctx gocontext.Context) (net.Conn, error) {
+	deadline := time.Now().Add(p.reconnectTimeout)
+	for {
+		p.mu.Lock()
+		backend := p.backend
+		p.mu.Unlock()
+		if backend != "" {
+			conn, err := net.DialTimeout("tcp", backend, dialTimeout)
+			if err == nil {
+				return conn, nil
+			}
+		}
+		// the published port changes when the nodes are started again,
+		// and is unreachable until they are
+		resolved, err := p.resolve()
+		if err == nil && resolved != backend {
+			p.mu.Lock()
+			p.backend = resolved
+			p.mu.Unlock()
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, errors.Errorf("API server %s unreachable for %s", backend, p.reconnectTimeout)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(retryInterval):
+		}
+	}
+}
+
+// track records conn to be closed on shutdown, closing it instead and
+// returning false if the proxy already shut down
+func (p *Proxy) track(conn net.Conn) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.conns == nil {
+		conn.Close()
+		return false
+	}
+	p.conns[conn] = struct{}{}
+	return true
+}
+
+func (p *Proxy) untrack(conn net.Conn) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.conns, conn)
+	conn.Close()
+}
+
+// closeWrite half closes conn if possible, so the other side sees EOF
+// while responses are still copied back
+func closeWrite(conn net.Conn) {
+	if c, ok := conn.(interface{ CloseWrite() error }); ok {
+		_ = c.CloseWrite()
+		return
+	}
+	conn.Close()
+}
+
+// Run serves a Proxy for the cluster on address until ctx is done, with the
+// cluster kubeconfig pointed at it meanwhile, it is pointed back at the API
+// server endpoint once the proxy stops
+// If address has no port the port the cluster was last proxied on is
+// reused if free, so clients keep working across runs
+func Run(ctx gocontext.Context, cctx *context.Context, address string, reconnectTimeout time.Duration, started func(address string)) error {
+	p, err := newForCluster(cctx, address, reconnectTimeout)
+	if err != nil {
+		return err
+	}
+	if err := kubeconfig.RecordProxy(cctx, p.Addr()); err != nil {
+		p.listener.Close()
+		return err
+	}
+	// the recorded address is kept to be reused, it is ignored once this
+	// process exits
+	defer func() {
+		_, _ = kubeconfig.Refresh(cctx)
+	}()
+	// the proxy must be serving before the kubeconfig is pointed at it
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- p.Serve(ctx)
+	}()
+	if _, err := kubeconfig.Refresh(cctx); err != nil {
+		p.listener.Close()
+		<-errCh
+		return err
+	}
+	if started != nil {
+		started(p.Addr())
+	}
+	return <-errCh
+}
+
+// newForCluster returns a Proxy for the cluster's API server on address,
+// see Run
+func newForCluster(cctx *context.Context, address string, reconnectTimeout time.Duration) (*Proxy, error) {
+	resolve := cctx.GetAPIServerEndpoint
+	if _, _, err := net.SplitHostPort(address); err == nil {
+		return New(address, resolve, reconnectTimeout)
+	}
+	if last, _, err := kubeconfig.RecordedProxy(cctx); err == nil {
+		_, port, err := net.SplitHostPort(last)
+		if err == nil {
+			if p, err := New(net.JoinHostPort(address, port), resolve, reconnectTimeout); err == nil {
+				return p, nil
+			}
+		}
+	}
+	return New(net.JoinHostPort(address, "0"), resolve, reconnectTimeout)
+}
@@ -0,0 +1,271 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apiproxy
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	clustercontext "sigs.k8s.io/kind/pkg/internal/cluster/context"
+	"sigs.k8s.io/kind/pkg/internal/cluster/kubeconfig"
+	"sigs.k8s.io/kind/pkg/internal/cluster/providers/provider"
+)
+
+// echoServer returns a listener echoing what is written back, callers
+// should close it
+func echoServer(t *testing.T) net.Listener {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_, _ = io.Copy(conn, conn)
+			}()
+		}
+	}()
+	return listener
+}
+
+// closedAddress returns an address nothing listens on
+func closedAddress(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+	return address
+}
+
+func TestProxyReconnects(t *testing.T) {
+	// the first endpoint is gone, as after the nodes were started again,
+	// the proxy must resolve the new one
+	echo := echoServer(t)
+	defer echo.Close()
+	endpoints := []string{closedAddress(t), echo.Addr().String()}
+	mu := sync.Mutex{}
+	resolves := 0
+	resolve := func() (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		endpoint := endpoints[resolves%len(endpoints)]
+		resolves++
+		return endpoint, nil
+	}
+	p, err := New("127.0.0.1:0", resolve, 10*time.Second)
+	if err != nil {
+		t.Fatalf("failed to create proxy: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- p.Serve(ctx)
+	}()
+
+	conn, err := net.Dial("tcp", p.Addr())
+	if err != nil {
+		t.Fatalf("failed to connect to proxy: %v", err)
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte("hello\n")); err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		t.Fatalf("failed to read: %v", err)
+	}
+	if line != "hello\n" {
+		t.Errorf("read %q, expected %q", line, "hello\n")
+	}
+
+	cancel()
+	if err := <-served; err != nil {
+		t.Errorf("Serve() = %v, expected nil once stopped", err)
+	}
+}
+
+func TestProxyReconnectTimeout(t *testing.T) {
+	unreachable := closedAddress(t)
+	p, err := New("127.0.0.1:0", func() (string, error) {
+		return unreachable, nil
+	}, time.Millisecond)
+	if err != nil {
+		t.Fatalf("failed to create proxy: %v", err)
+	}
+	if _, err := p.dial(context.Background()); err == nil {
+		t.Errorf("dial() succeeded, expected an error for an unreachable API server")
+	}
+	p.listener.Close()
+}
+
+// fakeProvider publishes the API server on endpoint
+type fakeProvider struct {
+	// unimplemented methods panic
+	provider.Provider
+	endpoint string
+}
+
+func (p *fakeProvider) GetAPIServerEndpoint(cluster string) (string, error) {
+	return p.endpoint, nil
+}
+
+// withTempHome points HOME at a temporary directory for the cluster
+// directory and kubeconfig, returning a func restoring it
+func withTempHome(t *testing.T) func() {
+	dir, err := ioutil.TempDir("", "kind-home")
+	if err != nil {
+		t.Fatal(err)
+	}
+	home := os.Getenv("HOME")
+	if err := os.Setenv("HOME", dir); err != nil {
+		t.Fatal(err)
+	}
+	return func() {
+		os.Setenv("HOME", home)
+		os.RemoveAll(dir)
+	}
+}
+
+// kubeconfigServer returns the server of the cluster's kubeconfig
+func kubeconfigServer(t *testing.T, cctx *clustercontext.Context) string {
+	contents, err := ioutil.ReadFile(cctx.KubeConfigPath())
+	if err != nil {
+		t.Fatalf("failed to read kubeconfig: %v", err)
+	}
+	for _, line := range strings.Split(string(contents), "\n") {
+		if server := strings.TrimPrefix(strings.TrimSpace(line), "server: https://"); server != strings.TrimSpace(line) {
+			return server
+		}
+	}
+	t.Fatalf("no server in kubeconfig %q", contents)
+	return ""
+}
+
+// runProxy runs the proxy for cctx on address until the returned func is
+// called, returning the address it listens on
+func runProxy(t *testing.T, cctx *clustercontext.Context, address string) (string, func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	started := make(chan string, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- Run(ctx, cctx, address, time.Second, func(address string) {
+			started <- address
+		})
+	}()
+	select {
+	case address := <-started:
+		return address, func() {
+			cancel()
+			if err := <-done; err != nil {
+				t.Errorf("Run() = %v, expected nil once stopped", err)
+			}
+		}
+	case err := <-done:
+		cancel()
+		t.Fatalf("Run() = %v before starting", err)
+		return "", nil
+	}
+}
+
+func TestRun(t *testing.T) {
+	defer withTempHome(t)()
+	echo := echoServer(t)
+	defer echo.Close()
+	cctx := clustercontext.NewProviderContext(&fakeProvider{endpoint: echo.Addr().String()}, "kind")
+	if err := os.MkdirAll(filepath.Dir(cctx.KubeConfigPath()), 0755); err != nil {
+		t.Fatal(err)
+	}
+	config := fmt.Sprintf("clusters:\n- cluster:\n    server: https://%s\n", echo.Addr())
+	if err := ioutil.WriteFile(cctx.KubeConfigPath(), []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// the kubeconfig points at the proxy while it runs, and back after
+	address, stop := runProxy(t, cctx, "127.0.0.1")
+	if server := kubeconfigServer(t, cctx); server != address {
+		t.Errorf("kubeconfig server = %s while proxying, expected %s", server, address)
+	}
+	stop()
+	if server := kubeconfigServer(t, cctx); server != echo.Addr().String() {
+		t.Errorf("kubeconfig server = %s once stopped, expected %s", server, echo.Addr())
+	}
+
+	// the port is reused by the next run
+	reused, stop := runProxy(t, cctx, "127.0.0.1")
+	stop()
+	if reused != address {
+		t.Errorf("proxied on %s again, expected %s to be reused", reused, address)
+	}
+
+	// a proxy on every address is recorded as loopback for the kubeconfig
+	all, stop := runProxy(t, cctx, "0.0.0.0")
+	_, port, _ := net.SplitHostPort(all)
+	if server := kubeconfigServer(t, cctx); server != net.JoinHostPort("127.0.0.1", port) {
+		t.Errorf("kubeconfig server = %s while proxying on %s, expected the loopback address", server, all)
+	}
+	stop()
+}
+
+func TestNewForClusterPortTaken(t *testing.T) {
+	defer withTempHome(t)()
+	cctx := clustercontext.NewProviderContext(&fakeProvider{}, "kind")
+	// the port last proxied on was taken since
+	taken, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer taken.Close()
+	if err := kubeconfig.RecordProxy(cctx, taken.Addr().String()); err != nil {
+		t.Fatal(err)
+	}
+	p, err := newForCluster(cctx, "127.0.0.1", time.Second)
+	if err != nil {
+		t.Fatalf("newForCluster() error = %v", err)
+	}
+	defer p.listener.Close()
+	if p.Addr() == taken.Addr().String() {
+		t.Errorf("newForCluster() listens on %s, expected another port", p.Addr())
+	}
+
+	// an explicit port is used as is
+	explicit := closedAddress(t)
+	p2, err := newForCluster(cctx, explicit, time.Second)
+	if err != nil {
+		t.Fatalf("newForCluster() error = %v", err)
+	}
+	defer p2.listener.Close()
+	if p2.Addr() != explicit {
+		t.Errorf("newForCluster() listens on %s, expected %s", p2.Addr(), explicit)
+	}
+}
@@ -0,0 +1,201 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package changelog implements recording the mutations kind makes to a
+// cluster, in the cluster directory and in a ConfigMap in the cluster, so
+// tools can tell what changed a cluster since it was created
+package changelog
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/globals"
+
+	"sigs.k8s.io/kind/pkg/internal/cluster/context"
+)
+
+const (
+	// ConfigMapName is the name of the kube-system ConfigMap the changelog
+	// is mirrored to, under the ConfigMapKey key
+	ConfigMapName = "kind-changelog"
+	// ConfigMapKey is the ConfigMap key holding the changelog
+	ConfigMapKey = "changelog.jsonl"
+	// maxConfigMapEntries bounds the entries mirrored to the ConfigMap,
+	// which is limited in size, the oldest are left out
+	maxConfigMapEntries = 200
+)
+
+// Actions are the mutations recorded
+const (
+	// ActionCreate is the cluster being created
+	ActionCreate = "create"
+	// ActionReconfigure is nodes being reconfigured, EG their resources
+	ActionReconfigure = "reconfigure"
+	// ActionLoadImage is images being loaded into nodes
+	ActionLoadImage = "load-image"
+	// ActionHeal is nodes being started again and repaired
+	ActionHeal = "heal"
+	// ActionFailover is a control plane being stopped to fail over
+	ActionFailover = "failover"
+	// ActionFailoverRestore is stopped control planes being started again
+	ActionFailoverRestore = "failover-restore"
+	// ActionRestore is nodes being restored from a checkpoint
+	ActionRestore = "restore"
+	// ActionFault is a service being repeatedly crashed on a node
+	ActionFault = "fault"
+	// ActionConnect is the cluster being connected to another cluster
+	ActionConnect = "connect"
+)
+
+// Entry is a mutation of the cluster
+// The JSON encoding is stable, fields are only added
+type Entry struct {
+	// Time is when the mutation was made
+	Time time.Time `json:"time"`
+	// Action is what was done, EG ActionCreate
+	Action string `json:"action"`
+	// Nodes are the names of the nodes mutated, if not the whole cluster
+	Nodes []string `json:"nodes,omitempty"`
+	// Details describe the mutation, depending on Action
+	Details map[string]string `json:"details,omitempty"`
+}
+
+// Record appends e to the cluster's changelog, setting its Time if unset,
+// and mirrors the changelog to the cluster ConfigMap
+// The mutation was made, so failures are only logged
+func Record(ctx *context.Context, e Entry) {
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	if err := appendEntry(ctx.Path(context.ChangelogFile), e); err != nil {
+		globals.GetLogger().Warnf("failed to record %s in the cluster changelog: %v", e.Action, err)
+		return
+	}
+	entries, err := Read(ctx)
+	if err == nil {
+		err = syncConfigMap(ctx, entries)
+	}
+	if err != nil {
+		globals.GetLogger().Warnf("failed to update the %s ConfigMap: %v", ConfigMapName, err)
+	}
+}
+
+// Read returns the entries of the cluster's changelog, oldest first
+func Read(ctx *context.Context) ([]Entry, error) {
+	f, err := os.Open(ctx.Path(context.ChangelogFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read cluster changelog")
+	}
+	defer f.Close()
+	entries := []Entry{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, errors.Wrap(err, "failed to parse cluster changelog")
+		}
+		entries = append(entries, e)
+	}
+	return entries, errors.Wrap(scanner.Err(), "failed to read cluster changelog")
+}
+
+// appendEntry appends e as a line of JSON to the file at path
+func appendEntry(path string, e Entry) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// syncConfigMap applies the ConfigMap holding the latest entries to the
+// cluster from a control plane node
+func syncConfigMap(ctx *context.Context, entries []Entry) error {
+	allNodes, err := ctx.ListNodes()
+	if err != nil {
+		return err
+	}
+	controlPlanes, err := nodeutils.ControlPlaneNodes(allNodes)
+	if err != nil {
+		return err
+	}
+	if len(controlPlanes) == 0 {
+		return errors.New("no control plane nodes")
+	}
+	node := controlPlanes[0]
+	// nothing to update until kubeadm set up Kubernetes, if ever
+	if err := node.Command("test", "-f", "/etc/kubernetes/admin.conf").Run(); err != nil {
+		return nil
+	}
+	manifest, err := configMap(entries)
+	if err != nil {
+		return err
+	}
+	return node.Command(
+		"kubectl", "--kubeconfig=/etc/kubernetes/admin.conf", "--request-timeout=30s", "apply", "-f", "-",
+	).SetStdin(bytes.NewReader(manifest)).Run()
+}
+
+// configMap returns the ConfigMap holding up to the latest
+// maxConfigMapEntries entries
+func configMap(entries []Entry) ([]byte, error) {
+	if len(entries) > maxConfigMapEntries {
+		entries = entries[len(entries)-maxConfigMapEntries:]
+	}
+	var data bytes.Buffer
+	for _, e := range entries {
+		line, err := json.Marshal(e)
+		if err != nil {
+			return nil, err
+		}
+		data.Write(append(line, '\n'))
+	}
+	return json.Marshal(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"name":      ConfigMapName,
+			"namespace": "kube-system",
+		},
+		"data": map[string]string{
+			ConfigMapKey: data.String(),
+		},
+	})
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package changelog
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfigMap(t *testing.T) {
+	entries := []Entry{}
+	for i := 0; i < maxConfigMapEntries+5; i++ {
+		entries = append(entries, Entry{
+			Time:   time.Date(2020, 1, 1, 0, 0, i, 0, time.UTC),
+			Action: ActionLoadImage,
+			Nodes:  []string{"kind-worker"},
+		})
+	}
+	raw, err := configMap(entries)
+	if err != nil {
+		t.Fatalf("configMap() failed: %v", err)
+	}
+	var cm struct {
+		Kind     string            `json:"kind"`
+		Metadata map[string]string `json:"metadata"`
+		Data     map[string]string `json:"data"`
+	}
+	if err := json.Unmarshal(raw, &cm); err != nil {
+		t.Fatalf("configMap() is not valid JSON: %v", err)
+	}
+	if cm.Kind != "ConfigMap" || cm.Metadata["name"] != ConfigMapName || cm.Metadata["namespace"] != "kube-system" {
+		t.Errorf("configMap() = %s, expected the kube-system %s ConfigMap", raw, ConfigMapName)
+	}
+	lines := strings.Split(strings.TrimSuffix(cm.Data[ConfigMapKey], "\n"), "\n")
+	if len(lines) != maxConfigMapEntries {
+		t.Fatalf("configMap() has %d entries, expected %d", len(lines), maxConfigMapEntries)
+	}
+	// the oldest entries are left out
+	var first Entry
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+		t.Fatalf("invalid entry %q: %v", lines[0], err)
+	}
+	if !first.Time.Equal(entries[5].Time) {
+		t.Errorf("first entry is from %v, expected %v", first.Time, entries[5].Time)
+	}
+}
@@ -0,0 +1,228 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package componentenv implements setting user configured environment
+// variables on the kubelet and the control plane static pods
+package componentenv
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/cluster/providers/provider/common"
+	"sigs.k8s.io/kind/pkg/internal/util/probe"
+)
+
+// KubeletDropInPath is the systemd drop-in setting the kubelet environment
+const KubeletDropInPath = "/etc/systemd/system/kubelet.service.d/20-kind-env.conf"
+
+// ManifestsDir is the directory kubeadm writes the static pod manifests to
+const ManifestsDir = "/etc/kubernetes/manifests"
+
+// RestartTimeout is how long waiting for the API server to be ready again
+// after setting its environment takes at most, as long as kubeadm waits for
+// the control plane to come up
+const RestartTimeout = 4 * time.Minute
+
+// apiServerReadyScript succeeds once the API server container is no longer
+// the container $1 and the API server at $2 is healthy
+const apiServerReadyScript = `id="$(crictl ps --name kube-apiserver --state running -q | head -n 1)"
+if [ -z "${id}" ] || [ "${id}" = "$1" ]; then
+	echo "kube-apiserver has not been restarted yet"
+	exit 1
+fi
+exec kubectl --kubeconfig=/etc/kubernetes/admin.conf --server="$2" get --raw=/healthz
+`
+
+// StaticPodComponents are the components kubeadm runs as static pods
+var StaticPodComponents = []string{
+	"kube-apiserver",
+	"kube-controller-manager",
+	"kube-scheduler",
+	"etcd",
+}
+
+// ForNode returns the environment for component on nodes with role,
+// later entries for the same variable take precedence
+func ForNode(cfg *config.Cluster, role config.NodeRole, component string) []config.EnvVar {
+	index := map[string]int{}
+	env := []config.EnvVar{}
+	for _, e := range cfg.ComponentEnv {
+		if e.Component != component || (e.Role != "" && e.Role != role) {
+			continue
+		}
+		for _, v := range e.Env {
+			if i, ok := index[v.Name]; ok {
+				env[i] = v
+				continue
+			}
+			index[v.Name] = len(env)
+			env = append(env, v)
+		}
+	}
+	return env
+}
+
+// KubeletDropIn returns a systemd drop-in setting env on the kubelet
+func KubeletDropIn(env []config.EnvVar) string {
+	var b strings.Builder
+	b.WriteString("# generated by kind\n[Service]\n")
+	for _, v := range env {
+		// % starts a systemd specifier
+		assignment := strings.Replace(v.Name+"="+v.Value, "%", "%%", -1)
+		fmt.Fprintf(&b, "Environment=%s\n", strconv.Quote(assignment))
+	}
+	return b.String()
+}
+
+// PatchStaticPod sets env on the first container of the static pod manifest,
+// replacing any existing variables with the same name
+func PatchStaticPod(manifest []byte, env []config.EnvVar) ([]byte, error) {
+	pod := map[string]interface{}{}
+	if err := yaml.Unmarshal(manifest, &pod); err != nil {
+		return nil, errors.Wrap(err, "failed to parse static pod manifest")
+	}
+	spec, _ := pod["spec"].(map[string]interface{})
+	containers, _ := spec["containers"].([]interface{})
+	if len(containers) == 0 {
+		return nil, errors.New("static pod manifest has no containers")
+	}
+	container, ok := containers[0].(map[string]interface{})
+	if !ok {
+		return nil, errors.New("invalid container in static pod manifest")
+	}
+
+	existing, _ := container["env"].([]interface{})
+	for _, v := range env {
+		replaced := false
+		for i := range existing {
+			if e, ok := existing[i].(map[string]interface{}); ok && e["name"] == v.Name {
+				existing[i] = map[string]interface{}{"name": v.Name, "value": v.Value}
+				replaced = true
+			}
+		}
+		if !replaced {
+			existing = append(existing, map[string]interface{}{"name": v.Name, "value": v.Value})
+		}
+	}
+	container["env"] = existing
+
+	return yaml.Marshal(pod)
+}
+
+// ConfigureKubelet writes the kubelet environment drop-in for node, this
+// must happen before kubeadm (re)starts the kubelet
+func ConfigureKubelet(node nodes.Node, cfg *config.Cluster) error {
+	role, err := node.Role()
+	if err != nil {
+		return err
+	}
+	env := ForNode(cfg, config.NodeRole(role), "kubelet")
+	if len(env) == 0 {
+		return nil
+	}
+	if err := nodeutils.WriteFile(node, KubeletDropInPath, KubeletDropIn(env)); err != nil {
+		return errors.Wrap(err, "failed to write kubelet environment")
+	}
+	return node.Command("systemctl", "daemon-reload").Run()
+}
+
+// ConfigureStaticPods sets the configured environment on the static pods
+// kubeadm created on node, the kubelet restarts the updated pods.
+// If the API server is updated this waits until the restarted API server is
+// ready, until deadline, so it can be used again right away.
+func ConfigureStaticPods(node nodes.Node, cfg *config.Cluster, deadline time.Time) error {
+	role, err := node.Role()
+	if err != nil {
+		return err
+	}
+	restartsAPIServer := false
+	previousAPIServer := ""
+	for _, component := range StaticPodComponents {
+		env := ForNode(cfg, config.NodeRole(role), component)
+		if len(env) == 0 {
+			continue
+		}
+		if component == "kube-apiserver" {
+			lines, err := exec.OutputLines(node.Command("crictl", "ps", "--name", component, "--state", "running", "-q"))
+			if err != nil {
+				return errors.Wrap(err, "failed to find the kube-apiserver container")
+			}
+			if len(lines) > 0 {
+				previousAPIServer = lines[0]
+			}
+			restartsAPIServer = true
+		}
+		manifestPath := path.Join(ManifestsDir, component+".yaml")
+		var manifest bytes.Buffer
+		if err := node.Command("cat", manifestPath).SetStdout(&manifest).Run(); err != nil {
+			return errors.Wrapf(err, "failed to read %s", manifestPath)
+		}
+		patched, err := PatchStaticPod(manifest.Bytes(), env)
+		if err != nil {
+			return errors.Wrapf(err, "failed to set %s environment", component)
+		}
+		// write outside of the manifests dir and move into place, so the
+		// kubelet never observes a partially written manifest
+		tmpPath := path.Join("/kind", component+".yaml")
+		if err := nodeutils.WriteFile(node, tmpPath, string(patched)); err != nil {
+			return errors.Wrapf(err, "failed to write %s", manifestPath)
+		}
+		if err := node.Command("mv", tmpPath, manifestPath).Run(); err != nil {
+			return errors.Wrapf(err, "failed to write %s", manifestPath)
+		}
+	}
+	if !restartsAPIServer {
+		return nil
+	}
+	return waitForAPIServer(node, cfg, previousAPIServer, deadline)
+}
+
+// waitForAPIServer waits until the kubelet replaced the API server container
+// previous on node and the new API server is healthy, until deadline
+func waitForAPIServer(node nodes.Node, cfg *config.Cluster, previous string, deadline time.Time) error {
+	ipv4, ipv6, err := node.IP()
+	if err != nil {
+		return errors.Wrap(err, "failed to get node IP")
+	}
+	// the API server certificate is valid for the node address
+	host := ipv4
+	if cfg.Networking.IPFamily == config.IPv6Family {
+		host = ipv6
+	}
+	server := "https://" + net.JoinHostPort(host, strconv.Itoa(common.APIServerInternalPort))
+	ready := probe.Command(func(ctx context.Context) exec.Cmd {
+		return exec.CommandWithContext(ctx, node, "sh", "-c", apiServerReadyScript, "sh", previous, server)
+	}, nil)
+	if _, err := probe.Until(deadline, probe.DefaultBackoff, ready); err != nil {
+		return errors.Wrapf(err, "kube-apiserver on %s was not ready after setting its environment", node.String())
+	}
+	return nil
+}
@@ -0,0 +1,241 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package componentenv
+
+import (
+	"io"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+func TestPatchStaticPod(t *testing.T) {
+	cases := []struct {
+		Name      string
+		Manifest  string
+		Env       []config.EnvVar
+		Expected  string
+		ExpectErr bool
+	}{
+		{
+			Name: "no existing env",
+			Manifest: `apiVersion: v1
+kind: Pod
+spec:
+  containers:
+  - name: kube-apiserver
+`,
+			Env: []config.EnvVar{{Name: "GOGC", Value: "50"}},
+			Expected: `apiVersion: v1
+kind: Pod
+spec:
+  containers:
+  - env:
+    - name: GOGC
+      value: "50"
+    name: kube-apiserver
+`,
+		},
+		{
+			Name: "replace existing env",
+			Manifest: `apiVersion: v1
+kind: Pod
+spec:
+  containers:
+  - env:
+    - name: HTTPS_PROXY
+      value: old
+    - name: NO_PROXY
+      value: localhost
+    name: etcd
+`,
+			Env: []config.EnvVar{{Name: "HTTPS_PROXY", Value: "new"}},
+			Expected: `apiVersion: v1
+kind: Pod
+spec:
+  containers:
+  - env:
+    - name: HTTPS_PROXY
+      value: new
+    - name: NO_PROXY
+      value: localhost
+    name: etcd
+`,
+		},
+		{
+			Name: "no containers",
+			Manifest: `apiVersion: v1
+kind: Pod
+spec: {}
+`,
+			Env:       []config.EnvVar{{Name: "GOGC", Value: "50"}},
+			ExpectErr: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			result, err := PatchStaticPod([]byte(tc.Manifest), tc.Env)
+			if (err != nil) != tc.ExpectErr {
+				t.Fatalf("PatchStaticPod() error = %v, ExpectErr %v", err, tc.ExpectErr)
+			}
+			if err != nil {
+				return
+			}
+			// compare the normalized documents
+			expected, err := yaml.YAMLToJSON([]byte(tc.Expected))
+			if err != nil {
+				t.Fatalf("failed to parse expected manifest: %v", err)
+			}
+			actual, err := yaml.YAMLToJSON(result)
+			if err != nil {
+				t.Fatalf("failed to parse result manifest: %v", err)
+			}
+			if string(actual) != string(expected) {
+				t.Errorf("PatchStaticPod() = %s, expected %s", result, tc.Expected)
+			}
+		})
+	}
+}
+
+func TestConfigureStaticPodsWaitsForAPIServer(t *testing.T) {
+	cases := []struct {
+		Name        string
+		NotReady    int
+		Timeout     time.Duration
+		ExpectError bool
+	}{
+		{
+			Name:     "ready after restarting",
+			NotReady: 2,
+			Timeout:  time.Minute,
+		},
+		{
+			Name:        "not ready in time",
+			NotReady:    1000,
+			Timeout:     500 * time.Millisecond,
+			ExpectError: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			node := &fakeNode{
+				notReady: tc.NotReady,
+				files: map[string]string{
+					"/etc/kubernetes/manifests/kube-apiserver.yaml": "spec:\n  containers:\n  - name: kube-apiserver\n",
+				},
+			}
+			cfg := &config.Cluster{
+				ComponentEnv: []config.ComponentEnv{{
+					Component: "kube-apiserver",
+					Env:       []config.EnvVar{{Name: "GODEBUG", Value: "x509sha1=1"}},
+				}},
+			}
+			err := ConfigureStaticPods(node, cfg, time.Now().Add(tc.Timeout))
+			if tc.ExpectError {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if node.probes != tc.NotReady+1 {
+				t.Errorf("probed the API server %d times, expected %d", node.probes, tc.NotReady+1)
+			}
+			if !strings.Contains(node.files["/etc/kubernetes/manifests/kube-apiserver.yaml"], "GODEBUG") {
+				t.Errorf("the kube-apiserver manifest was not patched")
+			}
+		})
+	}
+}
+
+// fakeNode serves files for cat, cp and mv, reports the container "old" for
+// crictl and fails the API server probe notReady times
+type fakeNode struct {
+	mu       sync.Mutex
+	files    map[string]string
+	notReady int
+	probes   int
+}
+
+func (n *fakeNode) String() string                             { return "kind-control-plane" }
+func (n *fakeNode) Role() (string, error)                      { return "control-plane", nil }
+func (n *fakeNode) IP() (string, string, error)                { return "172.18.0.2", "", nil }
+func (n *fakeNode) PortMappings() ([]nodes.PortMapping, error) { return nil, nil }
+
+func (n *fakeNode) Command(command string, args ...string) exec.Cmd {
+	return &fakeCmd{node: n, command: command, args: args, stdin: strings.NewReader(""), stdout: ioutil.Discard}
+}
+
+type fakeCmd struct {
+	node    *fakeNode
+	command string
+	args    []string
+	stdin   io.Reader
+	stdout  io.Writer
+}
+
+func (c *fakeCmd) Run() error {
+	n := c.node
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	switch c.command {
+	case "cat":
+		_, err := io.WriteString(c.stdout, n.files[c.args[0]])
+		return err
+	case "cp":
+		content, err := ioutil.ReadAll(c.stdin)
+		if err != nil {
+			return err
+		}
+		n.files[c.args[1]] = string(content)
+	case "mv":
+		n.files[c.args[1]] = n.files[c.args[0]]
+		delete(n.files, c.args[0])
+	case "crictl":
+		_, err := io.WriteString(c.stdout, "old\n")
+		return err
+	case "sh":
+		n.probes++
+		if n.probes <= n.notReady {
+			return &exec.RunError{
+				Command: append([]string{c.command}, c.args...),
+				Inner:   errors.New("kube-apiserver has not been restarted yet"),
+			}
+		}
+	}
+	return nil
+}
+
+func (c *fakeCmd) SetEnv(...string) exec.Cmd      { return c }
+func (c *fakeCmd) SetStdin(r io.Reader) exec.Cmd  { c.stdin = r; return c }
+func (c *fakeCmd) SetStdout(w io.Writer) exec.Cmd { c.stdout = w; return c }
+func (c *fakeCmd) SetStderr(io.Writer) exec.Cmd   { return c }
@@ -0,0 +1,176 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package componentscheduling implements setting user configured node
+// selectors, tolerations and resources on the workloads kind installs
+package componentscheduling
+
+import (
+	"regexp"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+// ForComponent returns the scheduling settings for component, settings for
+// the component take precedence over those for all components
+func ForComponent(cfg *config.Cluster, component string) config.ComponentScheduling {
+	out := config.ComponentScheduling{
+		Component:    component,
+		NodeSelector: map[string]string{},
+		Resources: config.ResourceRequirements{
+			Requests: map[string]string{},
+			Limits:   map[string]string{},
+		},
+	}
+	// apply the settings for all components first
+	for _, target := range []string{"", component} {
+		for _, s := range cfg.ComponentScheduling {
+			if s.Component != target {
+				continue
+			}
+			for k, v := range s.NodeSelector {
+				out.NodeSelector[k] = v
+			}
+			out.Tolerations = append(out.Tolerations, s.Tolerations...)
+			for k, v := range s.Resources.Requests {
+				out.Resources.Requests[k] = v
+			}
+			for k, v := range s.Resources.Limits {
+				out.Resources.Limits[k] = v
+			}
+		}
+	}
+	return out
+}
+
+// isEmpty returns true if s does not change any workload
+func isEmpty(s config.ComponentScheduling) bool {
+	return len(s.NodeSelector) == 0 && len(s.Tolerations) == 0 &&
+		len(s.Resources.Requests) == 0 && len(s.Resources.Limits) == 0
+}
+
+// workloadKinds are the kinds with a pod template PatchManifest patches
+var workloadKinds = map[string]bool{
+	"DaemonSet":   true,
+	"Deployment":  true,
+	"StatefulSet": true,
+}
+
+// documentSeparatorRE matches yaml document separators
+var documentSeparatorRE = regexp.MustCompile(`(?m)^---[ \t]*$`)
+
+// PatchManifest applies s to the pod templates of the workloads in the
+// multi-document manifest, other documents are left untouched
+func PatchManifest(manifest string, s config.ComponentScheduling) (string, error) {
+	if isEmpty(s) {
+		return manifest, nil
+	}
+	documents := documentSeparatorRE.Split(manifest, -1)
+	for i, document := range documents {
+		obj := map[string]interface{}{}
+		if err := yaml.Unmarshal([]byte(document), &obj); err != nil {
+			return "", errors.Wrap(err, "failed to parse manifest")
+		}
+		if kind, _ := obj["kind"].(string); !workloadKinds[kind] {
+			continue
+		}
+		if err := patchWorkload(obj, s); err != nil {
+			return "", err
+		}
+		patched, err := yaml.Marshal(obj)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to encode manifest")
+		}
+		documents[i] = "\n" + string(patched)
+	}
+	return strings.Join(documents, "---"), nil
+}
+
+// patchWorkload applies s to the pod template of the workload obj
+func patchWorkload(obj map[string]interface{}, s config.ComponentScheduling) error {
+	spec, _ := obj["spec"].(map[string]interface{})
+	template, _ := spec["template"].(map[string]interface{})
+	podSpec, _ := template["spec"].(map[string]interface{})
+	if podSpec == nil {
+		return errors.Errorf("%v has no pod template", obj["kind"])
+	}
+
+	if len(s.NodeSelector) > 0 {
+		nodeSelector, _ := podSpec["nodeSelector"].(map[string]interface{})
+		if nodeSelector == nil {
+			nodeSelector = map[string]interface{}{}
+		}
+		for k, v := range s.NodeSelector {
+			nodeSelector[k] = v
+		}
+		podSpec["nodeSelector"] = nodeSelector
+	}
+
+	if len(s.Tolerations) > 0 {
+		tolerations, _ := podSpec["tolerations"].([]interface{})
+		for _, t := range s.Tolerations {
+			toleration := map[string]interface{}{}
+			for k, v := range map[string]string{
+				"key": t.Key, "operator": t.Operator, "value": t.Value, "effect": t.Effect,
+			} {
+				if v != "" {
+					toleration[k] = v
+				}
+			}
+			tolerations = append(tolerations, toleration)
+		}
+		podSpec["tolerations"] = tolerations
+	}
+
+	if len(s.Resources.Requests) > 0 || len(s.Resources.Limits) > 0 {
+		containers, _ := podSpec["containers"].([]interface{})
+		for _, c := range containers {
+			container, ok := c.(map[string]interface{})
+			if !ok {
+				return errors.New("invalid container in pod template")
+			}
+			resources, _ := container["resources"].(map[string]interface{})
+			if resources == nil {
+				resources = map[string]interface{}{}
+			}
+			mergeQuantities(resources, "requests", s.Resources.Requests)
+			mergeQuantities(resources, "limits", s.Resources.Limits)
+			container["resources"] = resources
+		}
+	}
+
+	return nil
+}
+
+// mergeQuantities sets quantities on resources[field]
+func mergeQuantities(resources map[string]interface{}, field string, quantities map[string]string) {
+	if len(quantities) == 0 {
+		return
+	}
+	existing, _ := resources[field].(map[string]interface{})
+	if existing == nil {
+		existing = map[string]interface{}{}
+	}
+	for k, v := range quantities {
+		existing[k] = v
+	}
+	resources[field] = existing
+}
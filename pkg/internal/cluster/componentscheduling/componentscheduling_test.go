@@ -0,0 +1,162 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package componentscheduling
+
+import (
+	"reflect"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+func TestPatchManifest(t *testing.T) {
+	cases := []struct {
+		Name      string
+		Manifest  string
+		Settings  config.ComponentScheduling
+		Expected  string
+		ExpectErr bool
+	}{
+		{
+			Name: "no settings",
+			Manifest: `# comment
+---
+kind: DaemonSet
+spec: {}
+`,
+			Expected: `# comment
+---
+kind: DaemonSet
+spec: {}
+`,
+		},
+		{
+			Name: "daemonset",
+			Manifest: `# comment
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: kindnet
+---
+apiVersion: apps/v1
+kind: DaemonSet
+spec:
+  template:
+    spec:
+      containers:
+      - name: kindnet
+        resources:
+          limits:
+            memory: 50Mi
+      tolerations:
+      - operator: Exists
+`,
+			Settings: config.ComponentScheduling{
+				NodeSelector: map[string]string{"experiment": "control"},
+				Tolerations:  []config.Toleration{{Key: "dedicated", Value: "experiment", Effect: "NoSchedule"}},
+				Resources: config.ResourceRequirements{
+					Requests: map[string]string{"cpu": "100m"},
+					Limits:   map[string]string{"cpu": "200m"},
+				},
+			},
+			Expected: `# comment
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: kindnet
+---
+apiVersion: apps/v1
+kind: DaemonSet
+spec:
+  template:
+    spec:
+      containers:
+      - name: kindnet
+        resources:
+          limits:
+            cpu: 200m
+            memory: 50Mi
+          requests:
+            cpu: 100m
+      nodeSelector:
+        experiment: control
+      tolerations:
+      - operator: Exists
+      - effect: NoSchedule
+        key: dedicated
+        value: experiment
+`,
+		},
+		{
+			Name: "workload without pod template",
+			Manifest: `kind: Deployment
+spec: {}
+`,
+			Settings: config.ComponentScheduling{
+				NodeSelector: map[string]string{"experiment": "control"},
+			},
+			ExpectErr: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			out, err := PatchManifest(tc.Manifest, tc.Settings)
+			if err != nil != tc.ExpectErr {
+				t.Fatalf("unexpected error state: %v", err)
+			}
+			if err == nil && out != tc.Expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tc.Expected, out)
+			}
+		})
+	}
+}
+
+func TestForComponent(t *testing.T) {
+	cfg := &config.Cluster{
+		ComponentScheduling: []config.ComponentScheduling{
+			{
+				Component:    "cni",
+				NodeSelector: map[string]string{"pool": "cni"},
+			},
+			{
+				NodeSelector: map[string]string{"pool": "all", "zone": "a"},
+				Tolerations:  []config.Toleration{{Operator: "Exists"}},
+			},
+			{
+				Component: "node-local-dns",
+				Resources: config.ResourceRequirements{
+					Requests: map[string]string{"cpu": "50m"},
+				},
+			},
+		},
+	}
+	s := ForComponent(cfg, "cni")
+	expected := map[string]string{"pool": "cni", "zone": "a"}
+	if !reflect.DeepEqual(s.NodeSelector, expected) {
+		t.Errorf("expected node selector %v, got %v", expected, s.NodeSelector)
+	}
+	if len(s.Tolerations) != 1 {
+		t.Errorf("expected 1 toleration, got %v", s.Tolerations)
+	}
+	if len(s.Resources.Requests) != 0 {
+		t.Errorf("expected no requests, got %v", s.Resources.Requests)
+	}
+}
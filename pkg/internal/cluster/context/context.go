@@ -20,11 +20,17 @@ package context
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
 	"path/filepath"
 
 	"sigs.k8s.io/kind/pkg/cluster/constants"
 	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/globals"
 
+	"sigs.k8s.io/kind/pkg/internal/cluster/lifecycle"
 	"sigs.k8s.io/kind/pkg/internal/cluster/providers/docker"
 	"sigs.k8s.io/kind/pkg/internal/cluster/providers/provider"
 	"sigs.k8s.io/kind/pkg/internal/util/env"
@@ -85,6 +91,121 @@ func (c *Context) KubeConfigPath() string {
 	return filepath.Join(configDir, fileName)
 }
 
+// The per-cluster directory managed by kind has the following layout,
+// everything in it is removed when the cluster is deleted, except for the
+// status file of a cluster deleted because it failed to create:
+//
+//	~/.kind/clusters/<name>/
+//	  status.json        machine readable cluster status
+//	  descriptor.json    machine readable cluster descriptor for integrations
+//	  kubeconfig         copy of the generated (external) kubeconfig
+//	  kubeadm/<node>.conf  kubeadm config generated for each node
+//	  manifests/         manifests kind applied to the cluster
+//	  artifacts/         files exported from the cluster
+//	  tmp/               scratch space for in progress operations
+//	  firewall           interface host firewall rules were added for
+//	  host-limits        original values of the host limits kind raised
+//	  metrics/           control plane metrics client certificate and endpoints
+//	  systemd-slice      host systemd slice the nodes run in
+//	  apiserver-proxy    host address and pid the API server was last proxied on
+//	  changelog.jsonl    mutations kind made to the cluster, one JSON per line
+const (
+	// StatusFile is the cluster status file, relative to Dir()
+	StatusFile = "status.json"
+	// DescriptorFile is the cluster descriptor, relative to Dir()
+	DescriptorFile = "descriptor.json"
+	// KubeConfigFile is the kubeconfig copy, relative to Dir()
+	KubeConfigFile = "kubeconfig"
+	// KubeadmDir contains the generated kubeadm configs, relative to Dir()
+	KubeadmDir = "kubeadm"
+	// ManifestsDir contains the applied manifests, relative to Dir()
+	ManifestsDir = "manifests"
+	// ArtifactsDir contains exported artifacts, relative to Dir()
+	ArtifactsDir = "artifacts"
+	// TmpDir contains temporary files, relative to Dir()
+	TmpDir = "tmp"
+	// FirewallFile records the host network interface kind added firewall
+	// rules for, relative to Dir()
+	FirewallFile = "firewall"
+	// HostLimitsFile records the host limits kind raised and their original
+	// values, relative to Dir()
+	HostLimitsFile = "host-limits"
+	// MetricsDir contains the control plane metrics client certificate
+	// and endpoints, relative to Dir()
+	MetricsDir = "metrics"
+	// SystemdSliceFile records the host systemd slice kind started for the
+	// nodes, relative to Dir()
+	SystemdSliceFile = "systemd-slice"
+	// APIServerProxyFile records the host address the API server was last
+	// proxied on and the pid of the proxy, relative to Dir()
+	APIServerProxyFile = "apiserver-proxy"
+	// ChangelogFile records the mutations kind made to the cluster, relative
+	// to Dir()
+	ChangelogFile = "changelog.jsonl"
+)
+
+// Dir returns the directory kind keeps state for the cluster in
+func (c *Context) Dir() string {
+	return filepath.Join(env.HomeDir(), ".kind", "clusters", c.Name())
+}
+
+// Path returns elem joined onto the cluster directory
+func (c *Context) Path(elem ...string) string {
+	return filepath.Join(append([]string{c.Dir()}, elem...)...)
+}
+
+// StatusPath returns the path to the cluster's machine readable status file
+func (c *Context) StatusPath() string {
+	return c.Path(StatusFile)
+}
+
+// WriteFile writes contents to the file at path relative to the cluster
+// directory, creating any parent directories
+func (c *Context) WriteFile(path string, contents []byte) error {
+	dest := c.Path(path)
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return errors.Wrap(err, "failed to create cluster directory")
+	}
+	return ioutil.WriteFile(dest, contents, 0600)
+}
+
+// KeepFile is like WriteFile, but for copies kept only for later inspection,
+// failures are only logged
+func (c *Context) KeepFile(path string, contents []byte) {
+	if err := c.WriteFile(path, contents); err != nil {
+		globals.GetLogger().Warnf("failed to save %s: %v", c.Path(path), err)
+	}
+}
+
+// TempDir creates a new temporary directory in the cluster's tmp directory,
+// callers should remove it when done, it is otherwise removed along with
+// the cluster
+func (c *Context) TempDir(prefix string) (string, error) {
+	dir := c.Path(TmpDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", errors.Wrap(err, "failed to create cluster tmp directory")
+	}
+	return ioutil.TempDir(dir, prefix)
+}
+
+// ArtifactDir creates a new directory for files exported from the cluster in
+// the cluster's artifacts directory, it is removed along with the cluster
+func (c *Context) ArtifactDir(prefix string) (string, error) {
+	dir := c.Path(ArtifactsDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", errors.Wrap(err, "failed to create cluster artifacts directory")
+	}
+	return ioutil.TempDir(dir, prefix)
+}
+
+// SetPhase records that the cluster entered phase in the status file,
+// failures are only logged as the status file is informational
+func (c *Context) SetPhase(phase lifecycle.Phase, cause error) {
+	if err := lifecycle.Transition(c.StatusPath(), c.Name(), phase, cause); err != nil {
+		globals.GetLogger().Warnf("failed to update cluster status: %v", err)
+	}
+}
+
 // ClusterLabel returns the docker object label that will be applied
 // to cluster "node" containers
 func (c *Context) ClusterLabel() string {
@@ -109,7 +230,7 @@ func (c *Context) ListInternalNodes() ([]nodes.Node, error) {
 		if err != nil {
 			return nil, err
 		}
-		if nodeRole == constants.WorkerNodeRoleValue || nodeRole == constants.ControlPlaneNodeRoleValue {
+		if nodeutils.IsKubernetesRole(nodeRole) {
 			selectedNodes = append(selectedNodes, node)
 		}
 	}
@@ -18,8 +18,11 @@ package actions
 
 import (
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
 	"sigs.k8s.io/kind/pkg/internal/apis/config"
 	"sigs.k8s.io/kind/pkg/internal/cluster/context"
 	"sigs.k8s.io/kind/pkg/internal/util/cli"
@@ -37,6 +40,14 @@ type ActionContext struct {
 	Config         *config.Cluster
 	ClusterContext *context.Context
 	cache          *cachedData
+	phase          string
+	failures       Failures
+	timeouts       map[string]time.Duration
+	observer       func(phase string, elapsed time.Duration, success bool)
+	// started is when the phase being executed started
+	started time.Time
+	// listed is set once Nodes is called while executing the phase
+	listed int32
 }
 
 // NewActionContext returns a new ActionContext
@@ -72,14 +83,68 @@ func (cd *cachedData) setNodes(n []nodes.Node) {
 
 // Nodes returns the list of cluster nodes, this is a cached call
 func (ac *ActionContext) Nodes() ([]nodes.Node, error) {
+	atomic.StoreInt32(&ac.listed, 1)
 	cachedNodes := ac.cache.getNodes()
 	if cachedNodes != nil {
-		return cachedNodes, nil
+		return injectFailures(ac.phase, cachedNodes, ac.failures.nodes(ac.phase))
 	}
 	n, err := ac.ClusterContext.ListNodes()
 	if err != nil {
 		return nil, err
 	}
 	ac.cache.setNodes(n)
-	return n, nil
+	return injectFailures(ac.phase, n, ac.failures.nodes(ac.phase))
+}
+
+// SetFailures sets the failures to inject into the actions executed with
+// this context
+func (ac *ActionContext) SetFailures(failures Failures) {
+	ac.failures = failures
+}
+
+// SetPhaseTimeouts sets how long waiting within each phase may take at most,
+// by phase name, see Deadline
+func (ac *ActionContext) SetPhaseTimeouts(timeouts map[string]time.Duration) {
+	ac.timeouts = timeouts
+}
+
+// SetPhaseObserver sets observer to be called with the phase of each action
+// executed once it ends, with how long it took and whether it succeeded
+func (ac *ActionContext) SetPhaseObserver(observer func(phase string, elapsed time.Duration, success bool)) {
+	ac.observer = observer
+}
+
+// Deadline returns when the phase being executed must stop waiting, EG for
+// readiness probes, which is timeout from now unless the phase times out
+// earlier
+func (ac *ActionContext) Deadline(timeout time.Duration) time.Time {
+	deadline := time.Now().Add(timeout)
+	if phaseTimeout, ok := ac.timeouts[ac.phase]; ok {
+		if phaseDeadline := ac.started.Add(phaseTimeout); phaseDeadline.Before(deadline) {
+			deadline = phaseDeadline
+		}
+	}
+	return deadline
+}
+
+// Execute executes action, first failing it if failures are injected into
+// its phase, see Name. Failures injected into nodes are an error if the
+// action never lists the nodes, as they would not be injected.
+func (ac *ActionContext) Execute(action Action) (err error) {
+	ac.phase = Name(action)
+	ac.started = time.Now()
+	if ac.observer != nil {
+		defer func() { ac.observer(ac.phase, time.Since(ac.started), err == nil) }()
+	}
+	if err := ac.failures.Check(ac.phase); err != nil {
+		return err
+	}
+	atomic.StoreInt32(&ac.listed, 0)
+	if err := action.Execute(ac); err != nil {
+		return err
+	}
+	if len(ac.failures.nodes(ac.phase)) > 0 && atomic.LoadInt32(&ac.listed) == 0 {
+		return errors.Errorf("cannot inject failures into nodes in %s, it runs no commands on nodes", ac.phase)
+	}
+	return nil
 }
@@ -0,0 +1,106 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package actions
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// deadlineAction records the deadline of its phase for timeout
+type deadlineAction struct {
+	timeout  time.Duration
+	deadline time.Time
+}
+
+func (a *deadlineAction) Execute(ctx *ActionContext) error {
+	a.deadline = ctx.Deadline(a.timeout)
+	return nil
+}
+
+func TestDeadline(t *testing.T) {
+	cases := []struct {
+		Name          string
+		PhaseTimeouts map[string]time.Duration
+		Timeout       time.Duration
+		Expected      time.Duration
+	}{
+		{
+			Name:     "no phase timeout",
+			Timeout:  time.Hour,
+			Expected: time.Hour,
+		},
+		{
+			Name:          "earlier phase timeout",
+			PhaseTimeouts: map[string]time.Duration{"actions": time.Minute},
+			Timeout:       time.Hour,
+			Expected:      time.Minute,
+		},
+		{
+			Name:          "later phase timeout",
+			PhaseTimeouts: map[string]time.Duration{"actions": time.Hour},
+			Timeout:       time.Minute,
+			Expected:      time.Minute,
+		},
+		{
+			Name:          "other phase timeout",
+			PhaseTimeouts: map[string]time.Duration{"kubeadmjoin": time.Minute},
+			Timeout:       time.Hour,
+			Expected:      time.Hour,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			ac := &ActionContext{cache: &cachedData{}}
+			ac.SetPhaseTimeouts(tc.PhaseTimeouts)
+			action := &deadlineAction{timeout: tc.Timeout}
+			start := time.Now()
+			if err := ac.Execute(action); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if d := action.deadline.Sub(start); d < tc.Expected || d > tc.Expected+time.Second {
+				t.Errorf("deadline is %s after the phase started, expected %s", d, tc.Expected)
+			}
+		})
+	}
+}
+
+func TestExecuteObservesPhase(t *testing.T) {
+	t.Parallel()
+	observed := []string{}
+	ac := &ActionContext{cache: &cachedData{}}
+	ac.SetPhaseObserver(func(phase string, elapsed time.Duration, success bool) {
+		observed = append(observed, fmt.Sprintf("%s %t", phase, success))
+	})
+	failures := Failures{}
+	failures.Add("actions")
+	if err := ac.Execute(&deadlineAction{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ac.SetFailures(failures)
+	if err := ac.Execute(&deadlineAction{}); err == nil {
+		t.Fatal("expected the injected failure")
+	}
+	expected := []string{"actions true", "actions false"}
+	if !reflect.DeepEqual(observed, expected) {
+		t.Errorf("observed %v, expected %v", observed, expected)
+	}
+}
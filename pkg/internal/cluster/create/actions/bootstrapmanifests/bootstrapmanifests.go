@@ -0,0 +1,194 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package bootstrapmanifests implements the action applying the configured
+// bootstrap manifests and helm charts once the cluster is ready
+package bootstrapmanifests
+
+import (
+	"bytes"
+	gocontext "context"
+	"fmt"
+	"io/ioutil"
+	osexec "os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/cluster/context"
+	"sigs.k8s.io/kind/pkg/internal/cluster/create/actions"
+	"sigs.k8s.io/kind/pkg/internal/util/download"
+)
+
+// waitTimeout bounds waiting for each applied object or chart to be ready
+const waitTimeout = "5m"
+
+// appliedTemplate prints one "<kind> <namespace> <name>" line per applied
+// object, cluster scoped objects have namespace "-"
+const appliedTemplate = `{{.kind}} {{or .metadata.namespace "-"}} {{.metadata.name}}{{"\n"}}`
+
+type action struct{}
+
+// NewAction returns a new action for applying the bootstrap manifests
+func NewAction() actions.Action {
+	return &action{}
+}
+
+// Execute runs the action
+func (a *action) Execute(ctx *actions.ActionContext) error {
+	if len(ctx.Config.BootstrapManifests) == 0 {
+		return nil
+	}
+
+	ctx.Status.Start("Applying bootstrap manifests 📦")
+	defer ctx.Status.End(false)
+
+	allNodes, err := ctx.Nodes()
+	if err != nil {
+		return err
+	}
+	controlPlanes, err := nodeutils.ControlPlaneNodes(allNodes)
+	if err != nil {
+		return err
+	}
+	node := controlPlanes[0] // kind expects at least one always
+
+	// manifests are applied and waited for in order, so later entries may
+	// depend on CRDs or webhooks installed by earlier ones
+	for i, m := range ctx.Config.BootstrapManifests {
+		if m.Chart != "" {
+			err = installChart(ctx.ClusterContext.KubeConfigPath(), m)
+		} else {
+			err = applyManifest(ctx.ClusterContext, node, i, m)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to apply bootstrap manifest %d", i)
+		}
+	}
+
+	// mark success
+	ctx.Status.End(true)
+	return nil
+}
+
+// applyManifest applies m with kubectl on node and waits for the objects
+// it created to be ready
+func applyManifest(cctx *context.Context, node nodes.Node, i int, m config.BootstrapManifest) error {
+	args := []string{
+		"kubectl", "--kubeconfig=/etc/kubernetes/admin.conf", "apply",
+		"-o", "go-template=" + appliedTemplate,
+	}
+	if m.Namespace != "" {
+		args = append(args, "--namespace", m.Namespace)
+	}
+
+	// manifests are read or downloaded on the host and piped in
+	var manifest []byte
+	var err error
+	if m.URL != "" {
+		manifest, err = download.Fetch(gocontext.Background(), m.URL)
+	} else {
+		manifest, err = ioutil.ReadFile(m.Path)
+		err = errors.Wrap(err, "failed to read manifest")
+	}
+	if err != nil {
+		return err
+	}
+	cctx.KeepFile(
+		filepath.Join(context.ManifestsDir, fmt.Sprintf("bootstrap-%d.yaml", i)),
+		manifest,
+	)
+	cmd := node.Command(args[0], append(args[1:], "-f", "-")...).SetStdin(bytes.NewReader(manifest))
+
+	applied, err := exec.OutputLines(cmd)
+	if err != nil {
+		return errors.Wrap(err, "failed to apply manifest")
+	}
+
+	for _, line := range applied {
+		wait := waitArgs(line)
+		if wait == nil {
+			continue
+		}
+		if err := node.Command(wait[0], wait[1:]...).Run(); err != nil {
+			return errors.Wrapf(err, "failed waiting for %s", line)
+		}
+	}
+	return nil
+}
+
+// waitArgs returns the command waiting for the object described by a line of
+// appliedTemplate output to be ready, or nil if there is nothing to wait for
+func waitArgs(applied string) []string {
+	parts := strings.Fields(applied)
+	if len(parts) != 3 {
+		return nil
+	}
+	kind, namespace, name := parts[0], parts[1], parts[2]
+
+	args := []string{"kubectl", "--kubeconfig=/etc/kubernetes/admin.conf"}
+	switch kind {
+	case "CustomResourceDefinition":
+		// CRDs are cluster scoped
+		return append(args,
+			"wait", "--for=condition=Established", "--timeout="+waitTimeout,
+			"customresourcedefinition/"+name,
+		)
+	case "Deployment", "StatefulSet", "DaemonSet":
+		args = append(args,
+			"rollout", "status", "--timeout="+waitTimeout,
+			strings.ToLower(kind)+"/"+name,
+		)
+	case "Job":
+		args = append(args,
+			"wait", "--for=condition=Complete", "--timeout="+waitTimeout,
+			"job/"+name,
+		)
+	default:
+		return nil
+	}
+	return append(args, "--namespace", namespace)
+}
+
+// installChart installs the chart referenced by m with helm on the host,
+// waiting for its resources to be ready
+func installChart(kubeconfig string, m config.BootstrapManifest) error {
+	if _, err := osexec.LookPath("helm"); err != nil {
+		return errors.Errorf("helm is required to install chart %q but was not found", m.Chart)
+	}
+	args := []string{
+		"upgrade", "--install",
+		path.Base(m.Chart), m.Chart,
+		"--kubeconfig", kubeconfig,
+		"--wait", "--timeout", waitTimeout,
+	}
+	if m.Repo != "" {
+		args = append(args, "--repo", m.Repo)
+	}
+	if m.Version != "" {
+		args = append(args, "--version", m.Version)
+	}
+	if m.Namespace != "" {
+		args = append(args, "--namespace", m.Namespace, "--create-namespace")
+	}
+	return exec.Command("helm", args...).Run()
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bootstrapmanifests
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWaitArgs(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Applied  string
+		Expected []string
+	}{
+		{
+			Name:    "deployment",
+			Applied: "Deployment cert-manager cert-manager-webhook",
+			Expected: []string{
+				"kubectl", "--kubeconfig=/etc/kubernetes/admin.conf",
+				"rollout", "status", "--timeout=5m", "deployment/cert-manager-webhook",
+				"--namespace", "cert-manager",
+			},
+		},
+		{
+			Name:    "crd",
+			Applied: "CustomResourceDefinition - certificates.cert-manager.io",
+			Expected: []string{
+				"kubectl", "--kubeconfig=/etc/kubernetes/admin.conf",
+				"wait", "--for=condition=Established", "--timeout=5m",
+				"customresourcedefinition/certificates.cert-manager.io",
+			},
+		},
+		{
+			Name:    "job",
+			Applied: "Job default migrate",
+			Expected: []string{
+				"kubectl", "--kubeconfig=/etc/kubernetes/admin.conf",
+				"wait", "--for=condition=Complete", "--timeout=5m", "job/migrate",
+				"--namespace", "default",
+			},
+		},
+		{
+			Name:    "nothing to wait for",
+			Applied: "ConfigMap default settings",
+		},
+		{
+			Name:    "malformed",
+			Applied: "Deployment",
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			result := waitArgs(tc.Applied)
+			if !reflect.DeepEqual(result, tc.Expected) {
+				t.Errorf("waitArgs() = %v, expected %v", result, tc.Expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package bootstrapsecrets implements the action creating the configured
+// bootstrap secrets as soon as the API server is up
+package bootstrapsecrets
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/cluster/create/actions"
+)
+
+type action struct{}
+
+// NewAction returns a new action for creating the bootstrap secrets
+func NewAction() actions.Action {
+	return &action{}
+}
+
+// Execute runs the action
+func (a *action) Execute(ctx *actions.ActionContext) error {
+	if len(ctx.Config.BootstrapSecrets) == 0 {
+		return nil
+	}
+
+	ctx.Status.Start("Creating bootstrap secrets 🔑")
+	defer ctx.Status.End(false)
+
+	allNodes, err := ctx.Nodes()
+	if err != nil {
+		return err
+	}
+	controlPlanes, err := nodeutils.ControlPlaneNodes(allNodes)
+	if err != nil {
+		return err
+	}
+	node := controlPlanes[0] // kind expects at least one always
+
+	// secrets are piped to kubectl and never kept with the cluster state
+	list, err := secretList(ctx.Config.BootstrapSecrets, os.LookupEnv)
+	if err != nil {
+		return err
+	}
+	cmd := node.Command(
+		"kubectl", "--kubeconfig=/etc/kubernetes/admin.conf", "apply", "-f", "-",
+	)
+	cmd.SetStdin(bytes.NewReader(list))
+	if err := cmd.Run(); err != nil {
+		return errors.Wrap(err, "failed to create bootstrap secrets")
+	}
+
+	// mark success
+	ctx.Status.End(true)
+	return nil
+}
+
+// secretList returns a v1 List of the secrets and the namespaces they are
+// created in, reading secret values from host files and lookupEnv
+func secretList(secrets []config.BootstrapSecret, lookupEnv func(string) (string, bool)) ([]byte, error) {
+	items := []interface{}{}
+	namespaces := map[string]bool{
+		// these always exist, don't take ownership of them with apply
+		"default":     true,
+		"kube-system": true,
+	}
+	for _, s := range secrets {
+		if !namespaces[s.Namespace] {
+			namespaces[s.Namespace] = true
+			items = append(items, map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Namespace",
+				"metadata": map[string]interface{}{
+					"name": s.Namespace,
+				},
+			})
+		}
+
+		// []byte values are marshalled as base64 as the API expects
+		data := map[string][]byte{}
+		for _, d := range s.Data {
+			value, err := readValue(d, lookupEnv)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to read secret %s/%s", s.Namespace, s.Name)
+			}
+			data[d.Key] = value
+		}
+		items = append(items, map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Secret",
+			"metadata": map[string]interface{}{
+				"name":      s.Name,
+				"namespace": s.Namespace,
+				"labels": map[string]string{
+					"app.kubernetes.io/managed-by": "kind",
+				},
+			},
+			"type": s.Type,
+			"data": data,
+		})
+	}
+	return json.Marshal(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "List",
+		"items":      items,
+	})
+}
+
+// readValue reads the value of d from its host file or environment variable
+func readValue(d config.SecretData, lookupEnv func(string) (string, bool)) ([]byte, error) {
+	if d.File != "" {
+		value, err := ioutil.ReadFile(d.File)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read key %q", d.Key)
+		}
+		return value, nil
+	}
+	value, ok := lookupEnv(d.Env)
+	if !ok {
+		return nil, errors.Errorf("key %q: environment variable %s is not set", d.Key, d.Env)
+	}
+	return []byte(value), nil
+}
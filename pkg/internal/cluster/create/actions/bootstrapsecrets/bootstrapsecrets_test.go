@@ -0,0 +1,111 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bootstrapsecrets
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+func TestSecretList(t *testing.T) {
+	env := map[string]string{"TOKEN": "hunter2"}
+	lookupEnv := func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}
+	cases := []struct {
+		Name          string
+		Secrets       []config.BootstrapSecret
+		ExpectedKinds []string
+		ExpectedData  map[string]string
+		ExpectError   bool
+	}{
+		{
+			Name: "default namespace",
+			Secrets: []config.BootstrapSecret{
+				{
+					Name: "token", Namespace: "default", Type: "Opaque",
+					Data: []config.SecretData{{Key: "token", Env: "TOKEN"}},
+				},
+			},
+			ExpectedKinds: []string{"Secret"},
+			// base64 of hunter2
+			ExpectedData: map[string]string{"token": "aHVudGVyMg=="},
+		},
+		{
+			Name: "namespace created once",
+			Secrets: []config.BootstrapSecret{
+				{Name: "a", Namespace: "ci", Type: "Opaque"},
+				{Name: "b", Namespace: "ci", Type: "Opaque"},
+			},
+			ExpectedKinds: []string{"Namespace", "Secret", "Secret"},
+			ExpectedData:  map[string]string{},
+		},
+		{
+			Name: "unset env",
+			Secrets: []config.BootstrapSecret{
+				{
+					Name: "token", Namespace: "default", Type: "Opaque",
+					Data: []config.SecretData{{Key: "token", Env: "UNSET"}},
+				},
+			},
+			ExpectError: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			raw, err := secretList(tc.Secrets, lookupEnv)
+			if err != nil {
+				if !tc.ExpectError {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if tc.ExpectError {
+				t.Fatal("expected an error but got none")
+			}
+			list := struct {
+				Items []struct {
+					Kind string            `json:"kind"`
+					Data map[string]string `json:"data"`
+				} `json:"items"`
+			}{}
+			if err := json.Unmarshal(raw, &list); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			kinds := []string{}
+			data := map[string]string{}
+			for _, item := range list.Items {
+				kinds = append(kinds, item.Kind)
+				for k, v := range item.Data {
+					data[k] = v
+				}
+			}
+			if !reflect.DeepEqual(kinds, tc.ExpectedKinds) {
+				t.Errorf("expected kinds %v but got %v", tc.ExpectedKinds, kinds)
+			}
+			if !reflect.DeepEqual(data, tc.ExpectedData) {
+				t.Errorf("expected data %v but got %v", tc.ExpectedData, data)
+			}
+		})
+	}
+}
@@ -19,18 +19,24 @@ package config
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 
-	"sigs.k8s.io/kind/pkg/cluster/constants"
 	"sigs.k8s.io/kind/pkg/cluster/nodes"
 	"sigs.k8s.io/kind/pkg/errors"
 	"sigs.k8s.io/kind/pkg/globals"
 
 	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
 	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/cluster/componentenv"
+	"sigs.k8s.io/kind/pkg/internal/cluster/context"
 	"sigs.k8s.io/kind/pkg/internal/cluster/create/actions"
+	"sigs.k8s.io/kind/pkg/internal/cluster/identity"
+	"sigs.k8s.io/kind/pkg/internal/cluster/konnectivity"
 	"sigs.k8s.io/kind/pkg/internal/cluster/kubeadm"
 	"sigs.k8s.io/kind/pkg/internal/cluster/providers/provider/common"
+	"sigs.k8s.io/kind/pkg/internal/cluster/runtime"
 	"sigs.k8s.io/kind/pkg/internal/util/patch"
 )
 
@@ -66,6 +72,23 @@ func (a *Action) Execute(ctx *actions.ActionContext) error {
 		controlPlaneEndpoint = controlPlaneEndpointIPv6
 	}
 
+	// kubeadm configures the kubelet to use the runtime's CRI socket
+	r, err := runtime.ForName(string(ctx.Config.ContainerRuntime))
+	if err != nil {
+		return err
+	}
+
+	// a user supplied identity replaces the well known token and the
+	// generated certificate key and CA
+	clusterIdentity, err := identity.Resolve(ctx.Config.ClusterIdentity, os.LookupEnv)
+	if err != nil {
+		return errors.Wrap(err, "invalid clusterIdentity")
+	}
+	token := kubeadm.Token
+	if clusterIdentity.Token != "" {
+		token = clusterIdentity.Token
+	}
+
 	// create kubeadm init config
 	fns := []func() error{}
 
@@ -74,11 +97,45 @@ func (a *Action) Execute(ctx *actions.ActionContext) error {
 		ControlPlaneEndpoint: controlPlaneEndpoint,
 		APIBindPort:          common.APIServerInternalPort,
 		APIServerAddress:     ctx.Config.Networking.APIServerAddress,
-		Token:                kubeadm.Token,
+		APIServerHostname:    ctx.Config.Networking.APIServerHostname,
+		Token:                token,
+		CertificateKey:       clusterIdentity.CertificateKey,
 		PodSubnet:            ctx.Config.Networking.PodSubnet,
 		ServiceSubnet:        ctx.Config.Networking.ServiceSubnet,
 		ControlPlane:         true,
 		IPv6:                 ctx.Config.Networking.IPFamily == "ipv6",
+		CRISocket:            r.Socket,
+		ClusterDNS:           ctx.Config.KubeletClusterDNS(),
+		ContainerLogMaxSize:  ctx.Config.ContainerLogs.MaxSize,
+		ContainerLogMaxFiles: ctx.Config.ContainerLogs.MaxFiles,
+	}
+	// nodes with their own storage are left to manage their disk usage
+	for _, n := range ctx.Config.Nodes {
+		configData.DiskEviction = configData.DiskEviction || n.Storage != nil
+	}
+	if ctx.Config.KubeletCredentialProvider != nil {
+		configData.CredentialProviderConfig = kubeadm.CredentialProviderConfigPath
+		configData.CredentialProviderBinDir = kubeadm.CredentialProviderBinDir
+	}
+	if p := ctx.Config.ImagePulls; p != nil {
+		configData.AlwaysPullImages = p.Policy == config.PullAlways
+		configData.ParallelImagePulls = p.Parallel
+		configData.RuntimeRequestTimeout = p.Timeout
+	}
+	if ctx.Config.Konnectivity != nil {
+		configData.Konnectivity = true
+		configData.KonnectivityDir = konnectivity.Dir
+	}
+	// the metrics are published from the node network, or served on the
+	// host directly if the node shares its network
+	if m := ctx.Config.ControlPlaneMetrics; m != nil {
+		configData.ComponentBindAddress = "0.0.0.0"
+		if ctx.Config.Networking.IPFamily == "ipv6" {
+			configData.ComponentBindAddress = "::"
+		}
+		if ctx.Config.Networking.HostNetwork {
+			configData.ComponentBindAddress = m.ListenAddress
+		}
 	}
 
 	// create the kubeadm join configuration for control plane nodes
@@ -86,16 +143,34 @@ func (a *Action) Execute(ctx *actions.ActionContext) error {
 	if err != nil {
 		return err
 	}
+
+	// kubeadm init keeps an existing CA, the other control plane nodes
+	// receive it from the bootstrap node like the generated one
+	if clusterIdentity.CACert != nil {
+		bootstrapNode, err := nodeutils.BootstrapControlPlaneNode(allNodes)
+		if err != nil {
+			return err
+		}
+		if err := writeCA(bootstrapNode, clusterIdentity); err != nil {
+			return err
+		}
+	}
 	for _, node := range controlPlanes {
 		node := node             // capture loop variable
 		configData := configData // copy config data
 		fns = append(fns, func() error {
-			return writeKubeadmConfig(ctx.Config, configData, node)
+			if err := writeKubeadmConfig(ctx, configData, node); err != nil {
+				return err
+			}
+			if ctx.Config.Konnectivity == nil {
+				return nil
+			}
+			return writeKonnectivityConfig(ctx, node, len(controlPlanes))
 		})
 	}
 
 	// then create the kubeadm join config for the worker nodes if any
-	workers, err := nodeutils.SelectNodesByRole(allNodes, constants.WorkerNodeRoleValue)
+	workers, err := nodeutils.WorkerNodes(allNodes)
 	if err != nil {
 		return err
 	}
@@ -106,7 +181,7 @@ func (a *Action) Execute(ctx *actions.ActionContext) error {
 			configData := configData // copy config data
 			configData.ControlPlane = false
 			fns = append(fns, func() error {
-				return writeKubeadmConfig(ctx.Config, configData, node)
+				return writeKubeadmConfig(ctx, configData, node)
 			})
 		}
 	}
@@ -121,9 +196,51 @@ func (a *Action) Execute(ctx *actions.ActionContext) error {
 	return nil
 }
 
+// writeCA writes the user supplied cluster CA where kubeadm init expects it
+func writeCA(node nodes.Node, clusterIdentity *identity.Identity) error {
+	if err := nodeutils.WriteFile(node, "/etc/kubernetes/pki/ca.crt", string(clusterIdentity.CACert)); err != nil {
+		return errors.Wrap(err, "failed to write CA certificate to node")
+	}
+	if err := nodeutils.WriteFile(node, "/etc/kubernetes/pki/ca.key", string(clusterIdentity.CAKey)); err != nil {
+		return errors.Wrap(err, "failed to write CA key to node")
+	}
+	if err := node.Command("chmod", "600", "/etc/kubernetes/pki/ca.key").Run(); err != nil {
+		return errors.Wrap(err, "failed to restrict CA key permissions")
+	}
+	return nil
+}
+
+// writeKonnectivityConfig writes the API server's egress selector config and
+// the konnectivity server static pod to the control plane node, the kubelet
+// starts the server once kubeadm has created the certificates it uses
+func writeKonnectivityConfig(ctx *actions.ActionContext, node nodes.Node, serverCount int) error {
+	kubeVersion, err := nodeutils.KubeVersion(node)
+	if err != nil {
+		return errors.Wrap(err, "failed to get kubernetes version from node")
+	}
+	if err := konnectivity.CheckVersion(kubeVersion); err != nil {
+		return err
+	}
+	egressSelectorConfig, err := konnectivity.EgressSelectorConfig(ctx.Config.Konnectivity, kubeVersion)
+	if err != nil {
+		return err
+	}
+	if err := nodeutils.WriteFile(node, konnectivity.EgressSelectorConfigPath, egressSelectorConfig); err != nil {
+		return errors.Wrap(err, "failed to write egress selector config to node")
+	}
+	serverManifest, err := konnectivity.ServerManifest(ctx.Config.Konnectivity, serverCount)
+	if err != nil {
+		return err
+	}
+	if err := nodeutils.WriteFile(node, konnectivity.ServerManifestPath, serverManifest); err != nil {
+		return errors.Wrap(err, "failed to write konnectivity server manifest to node")
+	}
+	return nil
+}
+
 // getKubeadmConfig generates the kubeadm config contents for the cluster
-// by running data through the template.
-func getKubeadmConfig(cfg *config.Cluster, data kubeadm.ConfigData) (path string, err error) {
+// by running data through the template, patched for a node with role.
+func getKubeadmConfig(cfg *config.Cluster, data kubeadm.ConfigData, role config.NodeRole) (path string, err error) {
 	// generate the config contents
 	config, err := kubeadm.Config(data)
 	if err != nil {
@@ -131,7 +248,7 @@ func getKubeadmConfig(cfg *config.Cluster, data kubeadm.ConfigData) (path string
 	}
 	// fix all the patches to have name metadata matching the generated config
 	patches, jsonPatches := setPatchNames(
-		allPatchesFromConfig(cfg),
+		allPatchesFromConfig(cfg, role),
 	)
 	// apply patches
 	// TODO(bentheelder): this does not respect per node patches at all
@@ -156,8 +273,13 @@ func removeMetadata(kustomized string) string {
 	)
 }
 
-func allPatchesFromConfig(cfg *config.Cluster) (patches []string, jsonPatches []config.PatchJSON6902) {
-	return cfg.KubeadmConfigPatches, cfg.KubeadmConfigPatchesJSON6902
+func allPatchesFromConfig(cfg *config.Cluster, role config.NodeRole) (patches []string, jsonPatches []config.PatchJSON6902) {
+	patches = cfg.KubeadmConfigPatches
+	// custom roles may further patch the config of their nodes
+	if customRole := cfg.CustomRole(role); customRole != nil {
+		patches = append(append([]string{}, patches...), customRole.KubeadmConfigPatches...)
+	}
+	return patches, cfg.KubeadmConfigPatchesJSON6902
 }
 
 // setPatchNames sets the targeted object name on every patch to be the fixed
@@ -179,7 +301,8 @@ func setPatchNames(patches []string, jsonPatches []config.PatchJSON6902) ([]stri
 }
 
 // writeKubeadmConfig writes the kubeadm configuration in the specified node
-func writeKubeadmConfig(cfg *config.Cluster, data kubeadm.ConfigData, node nodes.Node) error {
+func writeKubeadmConfig(ctx *actions.ActionContext, data kubeadm.ConfigData, node nodes.Node) error {
+	cfg := ctx.Config
 	kubeVersion, err := nodeutils.KubeVersion(node)
 	if err != nil {
 		// TODO(bentheelder): logging here
@@ -199,7 +322,11 @@ func writeKubeadmConfig(cfg *config.Cluster, data kubeadm.ConfigData, node nodes
 		data.NodeAddress = nodeAddressIPv6
 	}
 
-	kubeadmConfig, err := getKubeadmConfig(cfg, data)
+	role, err := node.Role()
+	if err != nil {
+		return errors.Wrap(err, "failed to get role for node")
+	}
+	kubeadmConfig, err := getKubeadmConfig(cfg, data, config.NodeRole(role))
 
 	if err != nil {
 		// TODO(bentheelder): logging here
@@ -214,5 +341,12 @@ func writeKubeadmConfig(cfg *config.Cluster, data kubeadm.ConfigData, node nodes
 		return errors.Wrap(err, "failed to copy kubeadm config to node")
 	}
 
-	return nil
+	// keep a copy on the host for inspection
+	ctx.ClusterContext.KeepFile(
+		filepath.Join(context.KubeadmDir, node.String()+".conf"),
+		[]byte(kubeadmConfig),
+	)
+
+	// kubeadm starts the kubelet, so its environment must be in place first
+	return componentenv.ConfigureKubelet(node, cfg)
 }
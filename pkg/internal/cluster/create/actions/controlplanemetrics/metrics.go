@@ -0,0 +1,169 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package controlplanemetrics implements the action authorizing a client
+// certificate to read the control plane metrics published to the host
+package controlplanemetrics
+
+import (
+	"bytes"
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/internal/cluster/context"
+	"sigs.k8s.io/kind/pkg/internal/cluster/create/actions"
+	"sigs.k8s.io/kind/pkg/internal/cluster/metrics"
+	"sigs.k8s.io/kind/pkg/internal/cluster/providers/provider/common"
+)
+
+// components are the control plane components by their port in the node
+var components = map[int32]string{
+	common.ControllerManagerInternalPort: "kube-controller-manager",
+	common.SchedulerInternalPort:         "kube-scheduler",
+}
+
+type action struct{}
+
+// NewAction returns a new action for publishing control plane metrics
+func NewAction() actions.Action {
+	return &action{}
+}
+
+// Execute runs the action
+func (a *action) Execute(ctx *actions.ActionContext) error {
+	if ctx.Config.ControlPlaneMetrics == nil {
+		return nil
+	}
+
+	ctx.Status.Start("Publishing control-plane metrics 📈")
+	defer ctx.Status.End(false)
+
+	allNodes, err := ctx.Nodes()
+	if err != nil {
+		return err
+	}
+	node, err := nodeutils.BootstrapControlPlaneNode(allNodes)
+	if err != nil {
+		return err
+	}
+	kubeVersion, err := nodeutils.KubeVersion(node)
+	if err != nil {
+		return errors.Wrap(err, "failed to get kubernetes version from node")
+	}
+	if err := metrics.CheckVersion(kubeVersion); err != nil {
+		return err
+	}
+
+	// authorize the client certificate's user
+	if err := node.Command(
+		"kubectl", "--kubeconfig=/etc/kubernetes/admin.conf",
+		"apply", "-f", "-",
+	).SetStdin(strings.NewReader(metrics.RBACManifest)).Run(); err != nil {
+		return errors.Wrap(err, "failed to apply control plane metrics RBAC")
+	}
+
+	// sign the client certificate with the cluster CA, which all of the
+	// components trust for client authentication
+	caCert, err := readFile(node, metrics.CACertPath)
+	if err != nil {
+		return err
+	}
+	caKey, err := readFile(node, metrics.CAKeyPath)
+	if err != nil {
+		return err
+	}
+	cert, key, err := metrics.GenerateClientCert(caCert, caKey)
+	if err != nil {
+		return err
+	}
+
+	endpoints, err := publishedEndpoints(ctx, allNodes)
+	if err != nil {
+		return err
+	}
+
+	for name, contents := range map[string][]byte{
+		"ca.crt":     caCert,
+		"client.crt": cert,
+		"client.key": key,
+		"endpoints":  []byte(metrics.EndpointsFile(endpoints)),
+	} {
+		if err := ctx.ClusterContext.WriteFile(filepath.Join(context.MetricsDir, name), contents); err != nil {
+			return err
+		}
+	}
+
+	// mark success
+	ctx.Status.End(true)
+	return nil
+}
+
+// publishedEndpoints returns the API server endpoint and the endpoints each
+// control plane node publishes the other components on
+func publishedEndpoints(ctx *actions.ActionContext, allNodes []nodes.Node) ([]metrics.Endpoint, error) {
+	apiServer, err := ctx.ClusterContext.GetAPIServerEndpoint()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get api server endpoint")
+	}
+	endpoints := []metrics.Endpoint{{Component: "kube-apiserver", Address: apiServer}}
+
+	controlPlanes, err := nodeutils.ControlPlaneNodes(allNodes)
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range controlPlanes {
+		// the components serve on the host directly
+		if ctx.Config.Networking.HostNetwork {
+			for _, port := range []int32{common.ControllerManagerInternalPort, common.SchedulerInternalPort} {
+				endpoints = append(endpoints, metrics.Endpoint{
+					Component: components[port],
+					Node:      n.String(),
+					Address:   net.JoinHostPort(ctx.Config.ControlPlaneMetrics.ListenAddress, strconv.Itoa(int(port))),
+				})
+			}
+			continue
+		}
+		mappings, err := n.PortMappings()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get port mappings for node %s", n.String())
+		}
+		for _, m := range mappings {
+			if component, ok := components[m.ContainerPort]; ok {
+				endpoints = append(endpoints, metrics.Endpoint{
+					Component: component,
+					Node:      n.String(),
+					Address:   net.JoinHostPort(m.ListenAddress, strconv.Itoa(int(m.HostPort))),
+				})
+			}
+		}
+	}
+	return endpoints, nil
+}
+
+// readFile returns the contents of file on node
+func readFile(node nodes.Node, file string) ([]byte, error) {
+	var buff bytes.Buffer
+	if err := node.Command("cat", file).SetStdout(&buff).Run(); err != nil {
+		return nil, errors.Wrapf(err, "failed to read %s from node %s", file, node.String())
+	}
+	return buff.Bytes(), nil
+}
@@ -0,0 +1,209 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package actions
+
+import (
+	"io"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+)
+
+// FailuresEnv injects failures into the create phases when set, in the
+// format parsed by ParseFailures, so tools building on kind can test their
+// error handling and cleanup
+const FailuresEnv = "KIND_INJECT_FAILURES"
+
+// ProvisionPhase is the phase creating the node containers, which runs
+// before any action
+const ProvisionPhase = "provision"
+
+// PreflightPhase is the phase running the preflight checks, which runs
+// before provisioning
+const PreflightPhase = "preflight"
+
+// Failures are the create phases to fail on purpose, by phase name
+type Failures map[string]*Failure
+
+// Failure is the failure injected into a phase
+type Failure struct {
+	// Phase fails the phase before it runs
+	Phase bool
+	// Nodes are the names of the nodes commands fail on during the phase
+	Nodes []string
+}
+
+// Add injects a failure into phase, on the named nodes if any are given,
+// otherwise into the whole phase
+func (f Failures) Add(phase string, nodes ...string) {
+	failure, ok := f[phase]
+	if !ok {
+		failure = &Failure{}
+		f[phase] = failure
+	}
+	if len(nodes) == 0 {
+		failure.Phase = true
+	}
+	failure.Nodes = append(failure.Nodes, nodes...)
+}
+
+// nodes returns the names of the nodes commands fail on during phase
+func (f Failures) nodes(phase string) []string {
+	if failure, ok := f[phase]; ok {
+		return failure.Nodes
+	}
+	return nil
+}
+
+// ParseFailures parses failures from a ; separated list of phases, each
+// optionally followed by : and a , separated list of nodes,
+// EG kubeadmjoin:kind-worker2;installcni
+func ParseFailures(s string) (Failures, error) {
+	failures := Failures{}
+	for _, entry := range strings.Split(s, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, ":", 2)
+		phase := strings.TrimSpace(parts[0])
+		if phase == "" {
+			return nil, errors.Errorf("invalid failure %q, missing phase", entry)
+		}
+		nodes := []string{}
+		if len(parts) == 2 {
+			for _, node := range strings.Split(parts[1], ",") {
+				if node = strings.TrimSpace(node); node != "" {
+					nodes = append(nodes, node)
+				}
+			}
+		}
+		failures.Add(phase, nodes...)
+	}
+	return failures, nil
+}
+
+// Validate returns an error if failures names a phase other than phases,
+// or nodes to fail in the provision phase, which runs no commands on nodes
+func (f Failures) Validate(phases []string) error {
+	if len(f.nodes(ProvisionPhase)) > 0 {
+		return errors.Errorf(
+			"cannot inject failures into nodes in %s, only into the whole phase", ProvisionPhase,
+		)
+	}
+	known := map[string]bool{}
+	for _, phase := range phases {
+		known[phase] = true
+	}
+	unknown := []string{}
+	for phase := range f {
+		if !known[phase] {
+			unknown = append(unknown, phase)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return errors.Errorf(
+			"cannot inject failures into %s, phases run are: %s",
+			strings.Join(unknown, ", "), strings.Join(phases, ", "),
+		)
+	}
+	return nil
+}
+
+// Check returns an error if phase should fail outright
+func (f Failures) Check(phase string) error {
+	if failure, ok := f[phase]; ok && failure.Phase {
+		return errors.Errorf("injected failure in %s", phase)
+	}
+	return nil
+}
+
+// Name returns the phase name of action, the name of its package
+func Name(action Action) string {
+	t := reflect.TypeOf(action)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return path.Base(t.PkgPath())
+}
+
+// injectFailures wraps the nodes named in failing so their commands fail,
+// naming a node that is not in all is an error
+func injectFailures(phase string, all []nodes.Node, failing []string) ([]nodes.Node, error) {
+	if len(failing) == 0 {
+		return all, nil
+	}
+	fail := map[string]bool{}
+	for _, name := range failing {
+		fail[name] = true
+	}
+	wrapped := make([]nodes.Node, len(all))
+	for i, node := range all {
+		wrapped[i] = node
+		if fail[node.String()] {
+			wrapped[i] = &failingNode{Node: node, phase: phase}
+			delete(fail, node.String())
+		}
+	}
+	if len(fail) > 0 {
+		unknown := []string{}
+		for name := range fail {
+			unknown = append(unknown, name)
+		}
+		sort.Strings(unknown)
+		return nil, errors.Errorf(
+			"cannot inject failures in %s into unknown nodes: %s", phase, strings.Join(unknown, ", "),
+		)
+	}
+	return wrapped, nil
+}
+
+// failingNode is a node whose commands fail, for injecting failures
+type failingNode struct {
+	nodes.Node
+	phase string
+}
+
+func (n *failingNode) Command(command string, args ...string) exec.Cmd {
+	return &failingCmd{
+		command: append([]string{command}, args...),
+		err:     errors.Errorf("injected failure in %s on %s", n.phase, n.String()),
+	}
+}
+
+// failingCmd is an exec.Cmd that fails without running
+type failingCmd struct {
+	command []string
+	err     error
+}
+
+var _ exec.Cmd = &failingCmd{}
+
+func (c *failingCmd) Run() error {
+	return &exec.RunError{Command: c.command, Inner: c.err}
+}
+
+func (c *failingCmd) SetEnv(...string) exec.Cmd    { return c }
+func (c *failingCmd) SetStdin(io.Reader) exec.Cmd  { return c }
+func (c *failingCmd) SetStdout(io.Writer) exec.Cmd { return c }
+func (c *failingCmd) SetStderr(io.Writer) exec.Cmd { return c }
@@ -0,0 +1,176 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package actions
+
+import (
+	"reflect"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/exec"
+)
+
+func TestParseFailures(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name        string
+		Spec        string
+		Expected    Failures
+		ExpectError bool
+	}{
+		{
+			Name: "phases and nodes",
+			Spec: "kubeadmjoin:kind-worker,kind-worker2; installcni",
+			Expected: Failures{
+				"kubeadmjoin": {Nodes: []string{"kind-worker", "kind-worker2"}},
+				"installcni":  {Phase: true},
+			},
+		},
+		{
+			Name:     "repeated phase keeps failing the whole phase",
+			Spec:     "kubeadmjoin;kubeadmjoin:kind-worker",
+			Expected: Failures{"kubeadmjoin": {Phase: true, Nodes: []string{"kind-worker"}}},
+		},
+		{
+			Name:     "empty",
+			Spec:     ";",
+			Expected: Failures{},
+		},
+		{
+			Name:        "missing phase",
+			Spec:        ":kind-worker",
+			ExpectError: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			result, err := ParseFailures(tc.Spec)
+			if err != nil != tc.ExpectError {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !tc.ExpectError && !reflect.DeepEqual(result, tc.Expected) {
+				t.Errorf("expected %v but got %v", tc.Expected, result)
+			}
+		})
+	}
+}
+
+func TestFailures(t *testing.T) {
+	t.Parallel()
+	failures := Failures{}
+	failures.Add("kubeadmjoin", "kind-worker")
+	failures.Add("installcni")
+	if err := failures.Validate([]string{"provision", "kubeadmjoin", "installcni"}); err != nil {
+		t.Errorf("unexpected error validating known phases: %v", err)
+	}
+	if err := failures.Validate([]string{"provision", "kubeadmjoin"}); err == nil {
+		t.Errorf("expected an error validating a phase that is not run")
+	}
+	if err := failures.Check("installcni"); err == nil {
+		t.Errorf("expected installcni to fail")
+	}
+	if err := failures.Check("kubeadmjoin"); err != nil {
+		t.Errorf("expected kubeadmjoin to only fail on nodes: %v", err)
+	}
+	// failing the whole phase is kept when nodes are added later
+	failures.Add("installcni", "kind-worker")
+	if err := failures.Check("installcni"); err == nil {
+		t.Errorf("expected installcni to still fail")
+	}
+	// provisioning runs no commands on nodes to fail
+	provision := Failures{}
+	provision.Add(ProvisionPhase, "kind-worker")
+	if err := provision.Validate([]string{ProvisionPhase}); err == nil {
+		t.Errorf("expected an error injecting failures into nodes in %s", ProvisionPhase)
+	}
+	provision = Failures{}
+	provision.Add(ProvisionPhase)
+	if err := provision.Validate([]string{ProvisionPhase}); err != nil {
+		t.Errorf("unexpected error failing %s: %v", ProvisionPhase, err)
+	}
+}
+
+type fakeNode struct {
+	nodes.Node
+	name string
+}
+
+func (n *fakeNode) String() string { return n.name }
+
+func TestInjectFailures(t *testing.T) {
+	t.Parallel()
+	all := []nodes.Node{&fakeNode{name: "kind-control-plane"}, &fakeNode{name: "kind-worker"}}
+	wrapped, err := injectFailures("kubeadmjoin", all, []string{"kind-worker"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if wrapped[0] != all[0] {
+		t.Errorf("expected kind-control-plane to be unchanged")
+	}
+	err = wrapped[1].Command("kubeadm", "join").Run()
+	if _, ok := err.(*exec.RunError); !ok {
+		t.Errorf("expected a *RunError from kind-worker but got %v", err)
+	}
+	if _, err := injectFailures("kubeadmjoin", all, []string{"kind-worker2"}); err == nil {
+		t.Errorf("expected an error injecting failures into an unknown node")
+	}
+}
+
+func TestExecuteNodeFailures(t *testing.T) {
+	t.Parallel()
+	failures := Failures{}
+	failures.Add("actions", "kind-worker")
+	ac := &ActionContext{cache: &cachedData{}}
+	ac.cache.setNodes([]nodes.Node{&fakeNode{name: "kind-worker"}})
+	ac.SetFailures(failures)
+	if err := ac.Execute(&failingNodeAction{}); err == nil {
+		t.Errorf("expected an error for failures injected into nodes the action never lists")
+	}
+	if err := ac.Execute(&failingNodeAction{listNodes: true}); err == nil {
+		t.Errorf("expected the injected failure on kind-worker")
+	}
+}
+
+func TestName(t *testing.T) {
+	t.Parallel()
+	if name := Name(&failingNodeAction{}); name != "actions" {
+		t.Errorf("expected actions but got %q", name)
+	}
+}
+
+// failingNodeAction runs a command on every node if listNodes is set
+type failingNodeAction struct {
+	listNodes bool
+}
+
+func (a *failingNodeAction) Execute(ctx *ActionContext) error {
+	if !a.listNodes {
+		return nil
+	}
+	allNodes, err := ctx.Nodes()
+	if err != nil {
+		return err
+	}
+	for _, node := range allNodes {
+		if err := node.Command("true").Run(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -20,11 +20,14 @@ package installcni
 import (
 	"bytes"
 	"html/template"
+	"path/filepath"
 	"strings"
 
 	"sigs.k8s.io/kind/pkg/errors"
 
 	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/internal/cluster/componentscheduling"
+	"sigs.k8s.io/kind/pkg/internal/cluster/context"
 	"sigs.k8s.io/kind/pkg/internal/cluster/create/actions"
 )
 
@@ -83,6 +86,17 @@ func (a *action) Execute(ctx *actions.ActionContext) error {
 		manifest = out.String()
 	}
 
+	manifest, err = componentscheduling.PatchManifest(
+		manifest, componentscheduling.ForComponent(ctx.Config, "cni"),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to set CNI scheduling")
+	}
+
+	ctx.ClusterContext.KeepFile(
+		filepath.Join(context.ManifestsDir, "cni.yaml"), []byte(manifest),
+	)
+
 	// install the manifest
 	if err := node.Command(
 		"kubectl", "create", "--kubeconfig=/etc/kubernetes/admin.conf",
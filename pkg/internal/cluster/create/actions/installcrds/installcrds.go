@@ -0,0 +1,192 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package installcrds implements the action applying the configured CRDs
+// and APIServices, and waiting for them to be served, before the bootstrap
+// manifests
+package installcrds
+
+import (
+	"bytes"
+	gocontext "context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/cluster/context"
+	"sigs.k8s.io/kind/pkg/internal/cluster/create/actions"
+	"sigs.k8s.io/kind/pkg/internal/util/download"
+)
+
+// waitTimeout bounds waiting for each CRD or APIService
+const waitTimeout = "5m"
+
+// appliedTemplate prints one "<kind> <name>" line per applied object
+const appliedTemplate = `{{.kind}} {{.metadata.name}}{{"\n"}}`
+
+// manifestExtensions are the files applied from a directory
+var manifestExtensions = map[string]bool{".yaml": true, ".yml": true, ".json": true}
+
+type action struct{}
+
+// NewAction returns a new action for installing the CRDs
+func NewAction() actions.Action {
+	return &action{}
+}
+
+// Execute runs the action
+func (a *action) Execute(ctx *actions.ActionContext) error {
+	if len(ctx.Config.CRDs) == 0 {
+		return nil
+	}
+
+	ctx.Status.Start("Installing CRDs 📐")
+	defer ctx.Status.End(false)
+
+	allNodes, err := ctx.Nodes()
+	if err != nil {
+		return err
+	}
+	controlPlanes, err := nodeutils.ControlPlaneNodes(allNodes)
+	if err != nil {
+		return err
+	}
+	node := controlPlanes[0] // kind expects at least one always
+
+	for i, s := range ctx.Config.CRDs {
+		if err := applyCRDs(ctx.ClusterContext, node, i, s); err != nil {
+			return errors.Wrapf(err, "failed to install crd %d", i)
+		}
+	}
+
+	// mark success
+	ctx.Status.End(true)
+	return nil
+}
+
+// applyCRDs applies s with kubectl on node and waits for the CRDs and
+// APIServices it created to be served
+func applyCRDs(cctx *context.Context, node nodes.Node, i int, s config.CRDSource) error {
+	// manifests are read or downloaded on the host and piped in
+	var manifest []byte
+	var err error
+	if s.URL != "" {
+		manifest, err = download.Fetch(gocontext.Background(), s.URL)
+	} else {
+		manifest, err = readManifest(s.Path)
+	}
+	if err != nil {
+		return err
+	}
+	cctx.KeepFile(
+		filepath.Join(context.ManifestsDir, fmt.Sprintf("crds-%d.yaml", i)),
+		manifest,
+	)
+	cmd := node.Command(
+		"kubectl", "--kubeconfig=/etc/kubernetes/admin.conf", "apply",
+		"-o", "go-template="+appliedTemplate, "-f", "-",
+	).SetStdin(bytes.NewReader(manifest))
+
+	applied, err := exec.OutputLines(cmd)
+	if err != nil {
+		return errors.Wrap(err, "failed to apply manifest")
+	}
+
+	// wait for all of them at once, they are typically established together
+	args := []string{"kubectl", "--kubeconfig=/etc/kubernetes/admin.conf", "wait", "--timeout=" + waitTimeout}
+	crds, apiServices := waitTargets(applied)
+	if len(crds) > 0 {
+		wait := append(append(args, "--for=condition=Established"), crds...)
+		if err := node.Command(wait[0], wait[1:]...).Run(); err != nil {
+			return errors.Wrap(err, "failed waiting for CustomResourceDefinitions to be established")
+		}
+	}
+	if len(apiServices) > 0 {
+		wait := append(append(args, "--for=condition=Available"), apiServices...)
+		if err := node.Command(wait[0], wait[1:]...).Run(); err != nil {
+			return errors.Wrap(err, "failed waiting for APIServices to be available")
+		}
+	}
+	return nil
+}
+
+// waitTargets returns the CRDs and APIServices among the lines of
+// appliedTemplate output, as kubectl resource/name references
+func waitTargets(applied []string) (crds, apiServices []string) {
+	for _, line := range applied {
+		parts := strings.Fields(line)
+		if len(parts) != 2 {
+			continue
+		}
+		switch parts[0] {
+		case "CustomResourceDefinition":
+			crds = append(crds, "customresourcedefinition/"+parts[1])
+		case "APIService":
+			apiServices = append(apiServices, "apiservice/"+parts[1])
+		}
+	}
+	return crds, apiServices
+}
+
+// readManifest reads the manifest file path, or the manifests in the
+// directory path in name order as a single multi-document manifest
+func readManifest(path string) ([]byte, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read manifest")
+	}
+	if !info.IsDir() {
+		manifest, err := ioutil.ReadFile(path)
+		return manifest, errors.Wrap(err, "failed to read manifest")
+	}
+	entries, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read manifest directory")
+	}
+	names := []string{}
+	for _, e := range entries {
+		if !e.IsDir() && manifestExtensions[filepath.Ext(e.Name())] {
+			names = append(names, e.Name())
+		}
+	}
+	if len(names) == 0 {
+		return nil, errors.Errorf("no manifests found in %s", path)
+	}
+	sort.Strings(names)
+	var manifest bytes.Buffer
+	for _, name := range names {
+		raw, err := ioutil.ReadFile(filepath.Join(path, name))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read manifest")
+		}
+		// JSON documents are valid YAML documents
+		manifest.WriteString("---\n")
+		manifest.Write(raw)
+		if len(raw) > 0 && raw[len(raw)-1] != '\n' {
+			manifest.WriteString("\n")
+		}
+	}
+	return manifest.Bytes(), nil
+}
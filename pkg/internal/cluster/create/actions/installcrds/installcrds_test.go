@@ -0,0 +1,71 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package installcrds
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestWaitTargets(t *testing.T) {
+	crds, apiServices := waitTargets([]string{
+		"CustomResourceDefinition certificates.cert-manager.io",
+		"APIService v1beta1.metrics.k8s.io",
+		"Namespace cert-manager",
+		"CustomResourceDefinition issuers.cert-manager.io",
+	})
+	expectedCRDs := []string{
+		"customresourcedefinition/certificates.cert-manager.io",
+		"customresourcedefinition/issuers.cert-manager.io",
+	}
+	if !reflect.DeepEqual(crds, expectedCRDs) {
+		t.Errorf("expected %v but got %v", expectedCRDs, crds)
+	}
+	expectedAPIServices := []string{"apiservice/v1beta1.metrics.k8s.io"}
+	if !reflect.DeepEqual(apiServices, expectedAPIServices) {
+		t.Errorf("expected %v but got %v", expectedAPIServices, apiServices)
+	}
+}
+
+func TestReadManifestDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kind-crds")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"b.yaml":    "kind: B\n",
+		"a.json":    `{"kind": "A"}`,
+		"README.md": "not a manifest",
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	manifest, err := readManifest(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "---\n{\"kind\": \"A\"}\n---\nkind: B\n"
+	if string(manifest) != expected {
+		t.Errorf("expected %q but got %q", expected, string(manifest))
+	}
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package installdns implements the action installing the configured
+// node-local DNS cache and / or CoreDNS replacement
+package installdns
+
+import (
+	"bytes"
+	gocontext "context"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/internal/cluster/componentscheduling"
+	"sigs.k8s.io/kind/pkg/internal/cluster/context"
+	"sigs.k8s.io/kind/pkg/internal/cluster/create/actions"
+	"sigs.k8s.io/kind/pkg/internal/util/download"
+)
+
+type action struct{}
+
+// NewAction returns a new action for installing DNS
+func NewAction() actions.Action {
+	return &action{}
+}
+
+// Execute runs the action
+func (a *action) Execute(ctx *actions.ActionContext) error {
+	dns := ctx.Config.DNS
+	if dns == nil || (!dns.NodeLocalCache && dns.Manifest == "") {
+		return nil
+	}
+
+	ctx.Status.Start("Installing DNS 📖")
+	defer ctx.Status.End(false)
+
+	allNodes, err := ctx.Nodes()
+	if err != nil {
+		return err
+	}
+
+	// get the target node for this task
+	controlPlanes, err := nodeutils.ControlPlaneNodes(allNodes)
+	if err != nil {
+		return err
+	}
+	node := controlPlanes[0] // kind expects at least one always
+
+	// install the CoreDNS replacement first, the cache forwards to it
+	if dns.Manifest != "" {
+		if err := applyReplacement(ctx.ClusterContext, node, dns.Manifest); err != nil {
+			return errors.Wrap(err, "failed to install DNS manifest")
+		}
+	}
+
+	if dns.NodeLocalCache {
+		manifest, err := nodeLocalDNSManifest(ctx.Config)
+		if err != nil {
+			return err
+		}
+		manifest, err = componentscheduling.PatchManifest(
+			manifest, componentscheduling.ForComponent(ctx.Config, "node-local-dns"),
+		)
+		if err != nil {
+			return errors.Wrap(err, "failed to set node-local-dns scheduling")
+		}
+		ctx.ClusterContext.KeepFile(
+			filepath.Join(context.ManifestsDir, "node-local-dns.yaml"),
+			[]byte(manifest),
+		)
+		if err := apply(node, strings.NewReader(manifest)); err != nil {
+			return errors.Wrap(err, "failed to install node-local-dns")
+		}
+	}
+
+	// mark success
+	ctx.Status.End(true)
+	return nil
+}
+
+// applyReplacement applies the manifest at source, a path or a URL
+func applyReplacement(cctx *context.Context, node nodes.Node, source string) error {
+	var manifest []byte
+	var err error
+	if download.IsURL(source) {
+		manifest, err = download.Fetch(gocontext.Background(), source)
+	} else {
+		manifest, err = ioutil.ReadFile(source)
+		err = errors.Wrap(err, "failed to read manifest")
+	}
+	if err != nil {
+		return err
+	}
+	cctx.KeepFile(filepath.Join(context.ManifestsDir, "dns.yaml"), manifest)
+	return apply(node, bytes.NewReader(manifest))
+}
+
+func apply(node nodes.Node, manifest io.Reader) error {
+	return node.Command(
+		"kubectl", "--kubeconfig=/etc/kubernetes/admin.conf",
+		"apply", "-f", "-",
+	).SetStdin(manifest).Run()
+}
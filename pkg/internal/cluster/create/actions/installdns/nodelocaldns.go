@@ -0,0 +1,192 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package installdns
+
+import (
+	"bytes"
+	"net"
+	"text/template"
+
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+// nodeLocalDNSImage is the node-local-dns cache image
+const nodeLocalDNSImage = "k8s.gcr.io/dns/k8s-dns-node-cache:1.15.13"
+
+// nodeLocalDNSManifest returns the node-local-dns manifest for cfg
+//
+// Unlike the upstream manifest the cache only binds the link-local address,
+// as the kubelet is pointed at it there is no need to intercept traffic to
+// the DNS service, which is used as the upstream for the cluster domain
+func nodeLocalDNSManifest(cfg *config.Cluster) (string, error) {
+	upstream, err := cfg.DNSServiceIP()
+	if err != nil {
+		return "", err
+	}
+	t, err := template.New("node-local-dns").Parse(nodeLocalDNSTemplate)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to parse node-local-dns manifest template")
+	}
+	var out bytes.Buffer
+	if err := t.Execute(&out, &struct {
+		Image       string
+		LocalIP     string
+		HealthAddr  string
+		UpstreamDNS string
+	}{
+		Image:       nodeLocalDNSImage,
+		LocalIP:     cfg.DNS.NodeLocalCacheIP,
+		HealthAddr:  net.JoinHostPort(cfg.DNS.NodeLocalCacheIP, "8080"),
+		UpstreamDNS: upstream,
+	}); err != nil {
+		return "", errors.Wrap(err, "failed to execute node-local-dns manifest template")
+	}
+	return out.String(), nil
+}
+
+// nodeLocalDNSTemplate is derived from the upstream manifest at
+// https://github.com/kubernetes/kubernetes/blob/master/cluster/addons/dns/nodelocaldns/nodelocaldns.yaml
+const nodeLocalDNSTemplate = `# node-local-dns installed by kind
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: node-local-dns
+  namespace: kube-system
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: node-local-dns
+  namespace: kube-system
+data:
+  Corefile: |
+    cluster.local:53 {
+        errors
+        cache {
+            success 9984 30
+            denial 9984 5
+        }
+        reload
+        loop
+        bind {{ .LocalIP }}
+        forward . {{ .UpstreamDNS }} {
+            force_tcp
+        }
+        prometheus :9253
+        health {{ .HealthAddr }}
+    }
+    in-addr.arpa:53 {
+        errors
+        cache 30
+        reload
+        loop
+        bind {{ .LocalIP }}
+        forward . {{ .UpstreamDNS }} {
+            force_tcp
+        }
+        prometheus :9253
+    }
+    ip6.arpa:53 {
+        errors
+        cache 30
+        reload
+        loop
+        bind {{ .LocalIP }}
+        forward . {{ .UpstreamDNS }} {
+            force_tcp
+        }
+        prometheus :9253
+    }
+    .:53 {
+        errors
+        cache 30
+        reload
+        loop
+        bind {{ .LocalIP }}
+        forward . /etc/resolv.conf
+        prometheus :9253
+    }
+---
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: node-local-dns
+  namespace: kube-system
+  labels:
+    k8s-app: node-local-dns
+spec:
+  updateStrategy:
+    rollingUpdate:
+      maxUnavailable: 10%
+  selector:
+    matchLabels:
+      k8s-app: node-local-dns
+  template:
+    metadata:
+      labels:
+        k8s-app: node-local-dns
+    spec:
+      priorityClassName: system-node-critical
+      serviceAccountName: node-local-dns
+      hostNetwork: true
+      # the cache resolves non cluster names with the node's resolv.conf
+      dnsPolicy: Default
+      tolerations:
+      - operator: Exists
+      containers:
+      - name: node-cache
+        image: {{ .Image }}
+        args: ["-localip", "{{ .LocalIP }}", "-conf", "/etc/Corefile"]
+        securityContext:
+          privileged: true
+        ports:
+        - containerPort: 53
+          name: dns
+          protocol: UDP
+        - containerPort: 53
+          name: dns-tcp
+          protocol: TCP
+        - containerPort: 9253
+          name: metrics
+          protocol: TCP
+        livenessProbe:
+          httpGet:
+            host: {{ .LocalIP }}
+            path: /health
+            port: 8080
+          initialDelaySeconds: 60
+          timeoutSeconds: 5
+        volumeMounts:
+        - mountPath: /run/xtables.lock
+          name: xtables-lock
+          readOnly: false
+        - name: config-volume
+          mountPath: /etc/coredns
+      volumes:
+      - name: xtables-lock
+        hostPath:
+          path: /run/xtables.lock
+          type: FileOrCreate
+      - name: config-volume
+        configMap:
+          name: node-local-dns
+          items:
+          - key: Corefile
+            path: Corefile.base
+`
@@ -0,0 +1,68 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package installdns
+
+import (
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+func TestNodeLocalDNSManifest(t *testing.T) {
+	cases := []struct {
+		Name     string
+		IPFamily config.ClusterIPFamily
+		Expected []string
+	}{
+		{
+			Name: "ipv4",
+			Expected: []string{
+				"bind 169.254.20.10",
+				"forward . 10.96.0.10 {",
+				"health 169.254.20.10:8080",
+			},
+		},
+		{
+			Name:     "ipv6",
+			IPFamily: "ipv6",
+			Expected: []string{
+				"bind fd00::a9fe:140a",
+				"forward . fd00:10:96::a {",
+				"health [fd00::a9fe:140a]:8080",
+			},
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			cfg := &config.Cluster{DNS: &config.DNS{NodeLocalCache: true}}
+			cfg.Networking.IPFamily = tc.IPFamily
+			config.SetDefaultsCluster(cfg)
+			manifest, err := nodeLocalDNSManifest(cfg)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, expected := range tc.Expected {
+				if !strings.Contains(manifest, expected) {
+					t.Errorf("expected manifest to contain %q", expected)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package installkonnectivity implements the action installing the
+// konnectivity agents, the servers are static pods written by the config
+// action
+package installkonnectivity
+
+import (
+	"net"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/internal/cluster/componentscheduling"
+	"sigs.k8s.io/kind/pkg/internal/cluster/context"
+	"sigs.k8s.io/kind/pkg/internal/cluster/create/actions"
+	"sigs.k8s.io/kind/pkg/internal/cluster/konnectivity"
+)
+
+type action struct{}
+
+// NewAction returns a new action for installing konnectivity
+func NewAction() actions.Action {
+	return &action{}
+}
+
+// Execute runs the action
+func (a *action) Execute(ctx *actions.ActionContext) error {
+	if ctx.Config.Konnectivity == nil {
+		return nil
+	}
+
+	ctx.Status.Start("Installing konnectivity 🔌")
+	defer ctx.Status.End(false)
+
+	allNodes, err := ctx.Nodes()
+	if err != nil {
+		return err
+	}
+
+	// agents reach the servers the same way nodes reach the API server,
+	// through the load balancer if there are multiple control plane nodes
+	endpoint, endpointIPv6, err := nodeutils.GetControlPlaneEndpoint(allNodes)
+	if err != nil {
+		return err
+	}
+	if ctx.Config.Networking.IPFamily == "ipv6" {
+		endpoint = endpointIPv6
+	}
+	serverHost, _, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		return errors.Wrapf(err, "invalid control plane endpoint %q", endpoint)
+	}
+
+	manifest, err := konnectivity.AgentManifest(ctx.Config.Konnectivity, serverHost)
+	if err != nil {
+		return err
+	}
+	manifest, err = componentscheduling.PatchManifest(
+		manifest, componentscheduling.ForComponent(ctx.Config, "konnectivity-agent"),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to set konnectivity agent scheduling")
+	}
+	ctx.ClusterContext.KeepFile(
+		filepath.Join(context.ManifestsDir, "konnectivity-agent.yaml"),
+		[]byte(manifest),
+	)
+
+	// get the target node for this task
+	controlPlanes, err := nodeutils.ControlPlaneNodes(allNodes)
+	if err != nil {
+		return err
+	}
+	node := controlPlanes[0] // kind expects at least one always
+	if err := node.Command(
+		"kubectl", "--kubeconfig=/etc/kubernetes/admin.conf",
+		"apply", "-f", "-",
+	).SetStdin(strings.NewReader(manifest)).Run(); err != nil {
+		return errors.Wrap(err, "failed to apply konnectivity agent manifest")
+	}
+
+	// mark success
+	ctx.Status.End(true)
+	return nil
+}
@@ -19,12 +19,14 @@ limitations under the License.
 package installstorage
 
 import (
+	"path/filepath"
 	"strings"
 
 	"sigs.k8s.io/kind/pkg/cluster/nodes"
 	"sigs.k8s.io/kind/pkg/errors"
 
 	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/internal/cluster/context"
 	"sigs.k8s.io/kind/pkg/internal/cluster/create/actions"
 )
 
@@ -53,6 +55,10 @@ func (a *action) Execute(ctx *actions.ActionContext) error {
 	node := controlPlanes[0] // kind expects at least one always
 
 	// add the default storage class
+	ctx.ClusterContext.KeepFile(
+		filepath.Join(context.ManifestsDir, "storage.yaml"),
+		[]byte(defaultStorageClassManifest),
+	)
 	if err := addDefaultStorageClass(node); err != nil {
 		return errors.Wrap(err, "failed to add default storage class")
 	}
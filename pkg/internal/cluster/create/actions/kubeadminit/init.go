@@ -19,11 +19,10 @@ package kubeadminit
 
 import (
 	"bytes"
-	"fmt"
 	"io/ioutil"
+	"net"
 	"os"
 	"path/filepath"
-	"regexp"
 	"strings"
 
 	"sigs.k8s.io/kind/pkg/cluster/nodes"
@@ -33,7 +32,12 @@ import (
 
 	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
 
+	"sigs.k8s.io/kind/pkg/internal/cluster/componentenv"
+	"sigs.k8s.io/kind/pkg/internal/cluster/context"
 	"sigs.k8s.io/kind/pkg/internal/cluster/create/actions"
+	"sigs.k8s.io/kind/pkg/internal/cluster/kubeadm"
+	"sigs.k8s.io/kind/pkg/internal/cluster/kubeconfig"
+	"sigs.k8s.io/kind/pkg/internal/util/hostsfile"
 )
 
 // kubeadmInitAction implements action for executing the kubadm init
@@ -65,9 +69,9 @@ func (a *action) Execute(ctx *actions.ActionContext) error {
 	}
 
 	// run kubeadm
-	cmd := node.Command(
+	args := []string{
 		// init because this is the control plane node
-		"kubeadm", "init",
+		"init",
 		// preflight errors are expected, in particular for swap being enabled
 		// TODO(bentheelder): limit the set of acceptable errors
 		"--ignore-preflight-errors=all",
@@ -76,11 +80,26 @@ func (a *action) Execute(ctx *actions.ActionContext) error {
 		"--skip-token-print",
 		// increase verbosity for debugging
 		"--v=6",
-	)
+	}
+	// the configured DNS manifest replaces CoreDNS, see installdns
+	if ctx.Config.DNS != nil && ctx.Config.DNS.Manifest != "" {
+		args = append(args, "--skip-phases=addon/coredns")
+	}
+	// with a configured certificate key the control plane certificates are
+	// uploaded so that control planes joined later can fetch them with it
+	if ctx.Config.ClusterIdentity != nil && ctx.Config.ClusterIdentity.CertificateKey != nil {
+		args = append(args, "--upload-certs")
+	}
+	cmd := node.Command("kubeadm", args...)
 	lines, err := exec.CombinedOutputLines(cmd)
 	globals.GetLogger().V(3).Info(strings.Join(lines, "\n"))
 	if err != nil {
-		return errors.Wrap(err, "failed to init node with kubeadm")
+		return errors.Wrap(kubeadm.PreflightError(err, lines), "failed to init node with kubeadm")
+	}
+
+	// set any configured environment on the static pods kubeadm created
+	if err := componentenv.ConfigureStaticPods(node, ctx.Config, ctx.Deadline(componentenv.RestartTimeout)); err != nil {
+		return err
 	}
 
 	// copy some files to the other control plane nodes
@@ -115,14 +134,33 @@ func (a *action) Execute(ctx *actions.ActionContext) error {
 	if err != nil {
 		return errors.Wrap(err, "failed to get api server endpoint from node")
 	}
+	// use the configured DNS name instead of the listen address if any
+	if hostname := ctx.Config.Networking.APIServerHostname; hostname != "" {
+		_, port, err := net.SplitHostPort(endpoint)
+		if err != nil {
+			return errors.Wrap(err, "failed to parse api server endpoint")
+		}
+		endpoint = net.JoinHostPort(hostname, port)
+		if ctx.Config.Networking.AddAPIServerHostnameToHosts {
+			addHostsEntry(ctx.ClusterContext.Name(), ctx.Config.Networking.APIServerAddress, hostname)
+		}
+	}
 	kubeConfigPath := ctx.ClusterContext.KubeConfigPath()
 	if err := writeKubeConfig(node, kubeConfigPath, endpoint); err != nil {
 		return errors.Wrap(err, "failed to get kubeconfig from node")
 	}
+	// keep a copy alongside the rest of the cluster's files
+	if kubeConfig, err := ioutil.ReadFile(kubeConfigPath); err == nil {
+		ctx.ClusterContext.KeepFile(context.KubeConfigFile, kubeConfig)
+	}
 
 	// if we are only provisioning one node, remove the master taint
 	// https://kubernetes.io/docs/setup/independent/create-cluster-kubeadm/#master-isolation
-	if len(allNodes) == 1 {
+	kubernetesNodes, err := nodeutils.KubernetesNodes(allNodes)
+	if err != nil {
+		return err
+	}
+	if len(kubernetesNodes) == 1 {
 		if err := node.Command(
 			"kubectl", "--kubeconfig=/etc/kubernetes/admin.conf",
 			"taint", "nodes", "--all", "node-role.kubernetes.io/master-",
@@ -136,11 +174,25 @@ func (a *action) Execute(ctx *actions.ActionContext) error {
 	return nil
 }
 
-// matches kubeconfig server entry like:
-//    server: https://172.17.0.2:6443
-// which we rewrite to:
-//    server: https://$ADDRESS:$PORT
-var serverAddressRE = regexp.MustCompile(`^(\s+server:) https://.*:\d+$`)
+// addHostsEntry adds hostname to the host's hosts file resolving to the
+// API server listen address, failures are only logged as the cluster is
+// otherwise usable
+func addHostsEntry(cluster, address, hostname string) {
+	// the API server listens on all addresses, loopback is one of them
+	if ip := net.ParseIP(address); ip != nil && ip.IsUnspecified() {
+		address = "127.0.0.1"
+		if ip.To4() == nil {
+			address = "::1"
+		}
+	}
+	path := hostsfile.Path()
+	if err := hostsfile.Add(path, cluster, address, hostname); err != nil {
+		globals.GetLogger().Warnf(
+			"failed to add %s to %s, add \"%s %s\" manually: %v",
+			hostname, path, address, hostname, err,
+		)
+	}
+}
 
 // writeKubeConfig writes a fixed KUBECONFIG to dest
 // this should only be called on a control plane node
@@ -148,29 +200,20 @@ var serverAddressRE = regexp.MustCompile(`^(\s+server:) https://.*:\d+$`)
 // is replaced with local host and the control plane port with
 // a randomly generated port reserved during node creation.
 func writeKubeConfig(n nodes.Node, dest string, endpoint string) error {
-	cmd := n.Command("cat", "/etc/kubernetes/admin.conf")
-	lines, err := exec.CombinedOutputLines(cmd)
-	if err != nil {
+	var buff bytes.Buffer
+	cmd := n.Command("cat", "/etc/kubernetes/admin.conf").SetStdout(&buff)
+	if err := cmd.Run(); err != nil {
 		return errors.Wrap(err, "failed to get kubeconfig from node")
 	}
 
 	// fix the config file, swapping out the server for the forwarded localhost:port
-	var buff bytes.Buffer
-	for _, line := range lines {
-		match := serverAddressRE.FindStringSubmatch(line)
-		if len(match) > 1 {
-			line = fmt.Sprintf("%s https://%s", match[1], endpoint)
-		}
-		buff.WriteString(line)
-		buff.WriteString("\n")
-	}
+	kubeConfig := kubeconfig.SetServer(buff.Bytes(), endpoint)
 
 	// create the directory to contain the KUBECONFIG file.
 	// 0755 is taken from client-go's config handling logic: https://github.com/kubernetes/client-go/blob/5d107d4ebc00ee0ea606ad7e39fd6ce4b0d9bf9e/tools/clientcmd/loader.go#L412
-	err = os.MkdirAll(filepath.Dir(dest), 0755)
-	if err != nil {
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
 		return errors.Wrap(err, "failed to create kubeconfig output directory")
 	}
 
-	return ioutil.WriteFile(dest, buff.Bytes(), 0600)
+	return ioutil.WriteFile(dest, kubeConfig, 0600)
 }
@@ -20,7 +20,6 @@ package kubeadmjoin
 import (
 	"strings"
 
-	"sigs.k8s.io/kind/pkg/cluster/constants"
 	"sigs.k8s.io/kind/pkg/cluster/nodes"
 	"sigs.k8s.io/kind/pkg/errors"
 	"sigs.k8s.io/kind/pkg/exec"
@@ -28,7 +27,9 @@ import (
 
 	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
 
+	"sigs.k8s.io/kind/pkg/internal/cluster/componentenv"
 	"sigs.k8s.io/kind/pkg/internal/cluster/create/actions"
+	"sigs.k8s.io/kind/pkg/internal/cluster/kubeadm"
 )
 
 // Action implements action for creating the kubeadm join
@@ -59,7 +60,7 @@ func (a *Action) Execute(ctx *actions.ActionContext) error {
 	}
 
 	// then join worker nodes if any
-	workers, err := nodeutils.SelectNodesByRole(allNodes, constants.WorkerNodeRoleValue)
+	workers, err := nodeutils.WorkerNodes(allNodes)
 	if err != nil {
 		return err
 	}
@@ -86,6 +87,9 @@ func joinSecondaryControlPlanes(
 		if err := runKubeadmJoin(node); err != nil {
 			return err
 		}
+		if err := componentenv.ConfigureStaticPods(node, ctx.Config, ctx.Deadline(componentenv.RestartTimeout)); err != nil {
+			return err
+		}
 	}
 
 	ctx.Status.End(true)
@@ -132,7 +136,7 @@ func runKubeadmJoin(node nodes.Node) error {
 	lines, err := exec.CombinedOutputLines(cmd)
 	globals.GetLogger().V(3).Info(strings.Join(lines, "\n"))
 	if err != nil {
-		return errors.Wrap(err, "failed to join node with kubeadm")
+		return errors.Wrap(kubeadm.PreflightError(err, lines), "failed to join node with kubeadm")
 	}
 
 	return nil
@@ -18,15 +18,11 @@ limitations under the License.
 package loadbalancer
 
 import (
-	"fmt"
-
 	"sigs.k8s.io/kind/pkg/cluster/constants"
-	"sigs.k8s.io/kind/pkg/errors"
 
 	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
 	"sigs.k8s.io/kind/pkg/internal/cluster/create/actions"
 	"sigs.k8s.io/kind/pkg/internal/cluster/loadbalancer"
-	"sigs.k8s.io/kind/pkg/internal/cluster/providers/provider/common"
 )
 
 // Action implements and action for configuring and starting the
@@ -66,8 +62,6 @@ func (a *Action) Execute(ctx *actions.ActionContext) error {
 	ctx.Status.Start("Configuring the external load balancer ⚖️")
 	defer ctx.Status.End(false)
 
-	// collect info about the existing controlplane nodes
-	var backendServers = map[string]string{}
 	controlPlaneNodes, err := nodeutils.SelectNodesByRole(
 		allNodes,
 		constants.ControlPlaneNodeRoleValue,
@@ -75,38 +69,8 @@ func (a *Action) Execute(ctx *actions.ActionContext) error {
 	if err != nil {
 		return err
 	}
-	for _, n := range controlPlaneNodes {
-		controlPlaneIPv4, controlPlaneIPv6, err := n.IP()
-		if err != nil {
-			return errors.Wrapf(err, "failed to get IP for node %s", n.String())
-		}
-		if controlPlaneIPv4 != "" && !ipv6 {
-			backendServers[n.String()] = fmt.Sprintf("%s:%d", controlPlaneIPv4, common.APIServerInternalPort)
-		}
-		if controlPlaneIPv6 != "" && ipv6 {
-			backendServers[n.String()] = fmt.Sprintf("[%s]:%d", controlPlaneIPv6, common.APIServerInternalPort)
-		}
-	}
-
-	// create loadbalancer config data
-	loadbalancerConfig, err := loadbalancer.Config(&loadbalancer.ConfigData{
-		ControlPlanePort: common.APIServerInternalPort,
-		BackendServers:   backendServers,
-		IPv6:             ipv6,
-	})
-	if err != nil {
-		return errors.Wrap(err, "failed to generate loadbalancer config data")
-	}
-
-	// create loadbalancer config on the node
-	if err := nodeutils.WriteFile(loadBalancerNode, loadbalancer.ConfigPath, loadbalancerConfig); err != nil {
-		// TODO: logging here
-		return errors.Wrap(err, "failed to copy loadbalancer config to node")
-	}
-
-	// reload the config. haproxy will reload on SIGHUP
-	if err := loadBalancerNode.Command("kill", "-s", "HUP", "1").Run(); err != nil {
-		return errors.Wrap(err, "failed to reload loadbalancer")
+	if err := loadbalancer.Configure(loadBalancerNode, controlPlaneNodes, ipv6); err != nil {
+		return err
 	}
 
 	ctx.Status.End(true)
@@ -0,0 +1,110 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package nodefiles implements the action writing the files configured
+// for each node
+package nodefiles
+
+import (
+	"io/ioutil"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/cluster/create/actions"
+	"sigs.k8s.io/kind/pkg/internal/cluster/providers/provider/common"
+)
+
+type action struct{}
+
+// NewAction returns a new action for writing the configured node files
+func NewAction() actions.Action {
+	return &action{}
+}
+
+// Execute runs the action
+func (a *action) Execute(ctx *actions.ActionContext) error {
+	// most clusters write no files, skip the phase rather than show it empty
+	hasFiles := false
+	for _, n := range ctx.Config.Nodes {
+		hasFiles = hasFiles || len(n.Files) > 0
+	}
+	if !hasFiles {
+		return nil
+	}
+
+	ctx.Status.Start("Writing node files 📄")
+	defer ctx.Status.End(false)
+
+	allNodes, err := ctx.Nodes()
+	if err != nil {
+		return err
+	}
+	nodesByName := map[string]nodes.Node{}
+	for _, n := range allNodes {
+		nodesByName[n.String()] = n
+	}
+
+	// config nodes are named in order by role, as they were when provisioned
+	nodeNamer := common.MakeNodeNamer(ctx.ClusterContext.Name())
+	fns := []func() error{}
+	for _, configNode := range ctx.Config.Nodes {
+		name := nodeNamer(string(configNode.Role))
+		if len(configNode.Files) == 0 {
+			continue
+		}
+		node, ok := nodesByName[name]
+		if !ok {
+			return errors.Errorf("failed to find node %q", name)
+		}
+		files := configNode.Files
+		fns = append(fns, func() error {
+			for _, f := range files {
+				if err := writeFile(node, f); err != nil {
+					return errors.Wrapf(err, "failed to write %s to node %s", f.Path, node.String())
+				}
+			}
+			return nil
+		})
+	}
+	if err := errors.UntilErrorConcurrent(fns); err != nil {
+		return err
+	}
+
+	// mark success
+	ctx.Status.End(true)
+	return nil
+}
+
+func writeFile(node nodes.Node, f config.File) error {
+	content := f.Content
+	if f.HostPath != "" {
+		raw, err := ioutil.ReadFile(f.HostPath)
+		if err != nil {
+			return err
+		}
+		content = string(raw)
+	}
+	if err := nodeutils.WriteFile(node, f.Path, content); err != nil {
+		return err
+	}
+	if err := node.Command("chmod", f.Mode, f.Path).Run(); err != nil {
+		return errors.Wrap(err, "failed to set file mode")
+	}
+	return errors.Wrap(node.Command("chown", f.Owner, f.Path).Run(), "failed to set file owner")
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package noderoles implements the action labeling and tainting the
+// Kubernetes nodes with custom roles
+package noderoles
+
+import (
+	"fmt"
+	"sort"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/cluster/create/actions"
+)
+
+// roleLabelPrefix is the prefix of the well known Kubernetes node role labels
+const roleLabelPrefix = "node-role.kubernetes.io/"
+
+type action struct{}
+
+// NewAction returns a new action for labeling and tainting custom role nodes
+func NewAction() actions.Action {
+	return &action{}
+}
+
+// Execute runs the action
+func (a *action) Execute(ctx *actions.ActionContext) error {
+	if len(ctx.Config.Roles) == 0 {
+		return nil
+	}
+
+	ctx.Status.Start("Configuring node roles 🏷")
+	defer ctx.Status.End(false)
+
+	allNodes, err := ctx.Nodes()
+	if err != nil {
+		return err
+	}
+	node, err := nodeutils.BootstrapControlPlaneNode(allNodes)
+	if err != nil {
+		return err
+	}
+	workers, err := nodeutils.WorkerNodes(allNodes)
+	if err != nil {
+		return err
+	}
+	for _, n := range workers {
+		role, err := n.Role()
+		if err != nil {
+			return err
+		}
+		customRole := ctx.Config.CustomRole(config.NodeRole(role))
+		if customRole == nil {
+			continue
+		}
+		if err := kubectl(node, append([]string{"label", "node", n.String(), "--overwrite"}, labels(customRole)...)...); err != nil {
+			return errors.Wrapf(err, "failed to label node %s", n.String())
+		}
+		if len(customRole.Taints) == 0 {
+			continue
+		}
+		if err := kubectl(node, append([]string{"taint", "node", n.String(), "--overwrite"}, customRole.Taints...)...); err != nil {
+			return errors.Wrapf(err, "failed to taint node %s", n.String())
+		}
+	}
+
+	// mark success
+	ctx.Status.End(true)
+	return nil
+}
+
+// labels returns the kubectl label arguments for nodes with role, in a
+// stable order
+func labels(role *config.CustomRole) []string {
+	args := []string{roleLabelPrefix + string(role.Name) + "="}
+	keys := make([]string, 0, len(role.Labels))
+	for key := range role.Labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		args = append(args, fmt.Sprintf("%s=%s", key, role.Labels[key]))
+	}
+	return args
+}
+
+func kubectl(node nodes.Node, args ...string) error {
+	return node.Command(
+		"kubectl", append([]string{"--kubeconfig=/etc/kubernetes/admin.conf"}, args...)...,
+	).Run()
+}
@@ -0,0 +1,245 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package nodestorage implements the action backing the kubelet and
+// container image filesystems of nodes with size limited volumes
+package nodestorage
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/cluster/create/actions"
+	"sigs.k8s.io/kind/pkg/internal/cluster/providers/provider/common"
+	"sigs.k8s.io/kind/pkg/internal/cluster/runtime"
+)
+
+// storageDir holds the filesystem images and the mounts attaching them
+const storageDir = "/kind/storage"
+
+// kubeletDir is the kubelet's data directory, its node filesystem
+const kubeletDir = "/var/lib/kubelet"
+
+// mountsFile lists the filesystems to attach, one "<image> <dir>" per line
+const mountsFile = storageDir + "/mounts"
+
+// mountScript attaches the filesystems in mountsFile, loop devices created
+// after the node container started are missing from its /dev, so they are
+// created as needed
+// The loop devices are shared by the host and every node, which may be
+// provisioned concurrently, so mount finds and attaches a free device at
+// once, retrying when another node still took it first. Loop devices
+// attached by mount are detached by the kernel once unmounted, which
+// happens when the node stops or is deleted as its mounts go away with it,
+// so they never outlive the node.
+const mountScript = `#!/bin/sh
+# attaches the size limited node filesystems configured by kind
+set -e
+mkloops() {
+	for dev in /sys/block/loop*; do
+		n="${dev#/sys/block/loop}"
+		[ -e "/dev/loop${n}" ] || mknod "/dev/loop${n}" b 7 "${n}" || true
+	done
+}
+while read -r img dir; do
+	mountpoint -q "${dir}" && continue
+	mounted=""
+	for attempt in 1 2 3 4 5; do
+		mkloops
+		mount -o loop "${img}" "${dir}" && mounted=1 && break
+		sleep 1
+	done
+	[ -n "${mounted}" ] || { echo "failed to attach ${img}" >&2; exit 1; }
+done < ` + mountsFile + `
+`
+
+// mountUnit attaches the filesystems whenever the node boots, before the
+// container runtime and kubelet start
+const mountUnit = `[Unit]
+Description=kind size limited node filesystems
+DefaultDependencies=no
+Before=containerd.service crio.service kubelet.service
+
+[Service]
+Type=oneshot
+RemainAfterExit=yes
+ExecStart=` + storageDir + `/mount.sh
+
+[Install]
+WantedBy=multi-user.target
+`
+
+type action struct{}
+
+// NewAction returns a new action for setting up the node storage
+func NewAction() actions.Action {
+	return &action{}
+}
+
+// Execute runs the action
+func (a *action) Execute(ctx *actions.ActionContext) error {
+	// storage is opt-in per node, only show the phase if any node sets it
+	hasStorage := false
+	for _, n := range ctx.Config.Nodes {
+		hasStorage = hasStorage || n.Storage != nil
+	}
+	if !hasStorage {
+		return nil
+	}
+
+	ctx.Status.Start("Preparing node storage 💾")
+	defer ctx.Status.End(false)
+
+	allNodes, err := ctx.Nodes()
+	if err != nil {
+		return err
+	}
+	nodesByName := map[string]nodes.Node{}
+	for _, n := range allNodes {
+		nodesByName[n.String()] = n
+	}
+
+	// config nodes are named in order by role, as they were when provisioned
+	nodeNamer := common.MakeNodeNamer(ctx.ClusterContext.Name())
+	fns := []func() error{}
+	for _, configNode := range ctx.Config.Nodes {
+		name := nodeNamer(string(configNode.Role))
+		if configNode.Storage == nil {
+			continue
+		}
+		node, ok := nodesByName[name]
+		if !ok {
+			return errors.Errorf("failed to find node %q", name)
+		}
+		storage := configNode.Storage
+		fns = append(fns, func() error {
+			return errors.Wrapf(setupStorage(node, storage), "failed to set up storage on node %s", node.String())
+		})
+	}
+	if err := errors.UntilErrorConcurrent(fns); err != nil {
+		return err
+	}
+
+	// mark success
+	ctx.Status.End(true)
+	return nil
+}
+
+// setupStorage moves the kubelet and runtime data of node onto size limited
+// filesystems, they are stopped meanwhile
+func setupStorage(node nodes.Node, storage *config.NodeStorage) error {
+	if err := node.Command("sh", "-c", "command -v mkfs.ext4").Run(); err != nil {
+		return errors.New("node image lacks mkfs.ext4")
+	}
+	r, err := runtime.ForNode(node)
+	if err != nil {
+		return err
+	}
+	filesystems := []struct {
+		name string
+		dir  string
+		size string
+	}{
+		{"kubelet", kubeletDir, storage.Size},
+		{"images", r.Root, storage.ImageFilesystemSize},
+	}
+
+	services := []string{"kubelet", r.Service}
+	if err := node.Command("systemctl", append([]string{"stop"}, services...)...).Run(); err != nil {
+		return errors.Wrap(err, "failed to stop the kubelet and container runtime")
+	}
+	mounts := ""
+	for _, fs := range filesystems {
+		if fs.size == "" {
+			continue
+		}
+		bytes, err := sizeBytes(fs.size)
+		if err != nil {
+			return err
+		}
+		img := path.Join(storageDir, fs.name+".img")
+		// the filesystem is populated with the current contents, EG the
+		// images preloaded in the node image
+		if err := node.Command(
+			"sh", "-c", `mkdir -p "$(dirname "$1")" "$3" && truncate -s "$2" "$1" && mkfs.ext4 -q -F -m 0 -d "$3" "$1"`,
+			"sh", img, strconv.FormatInt(bytes, 10), fs.dir,
+		).Run(); err != nil {
+			return errors.Wrapf(err, "failed to create %s filesystem of %s, it must fit the current contents of %s", fs.name, fs.size, fs.dir)
+		}
+		mounts += fmt.Sprintf("%s %s\n", img, fs.dir)
+	}
+	if err := nodeutils.WriteFile(node, mountsFile, mounts); err != nil {
+		return err
+	}
+	if err := nodeutils.WriteFile(node, storageDir+"/mount.sh", mountScript); err != nil {
+		return err
+	}
+	if err := node.Command("chmod", "+x", storageDir+"/mount.sh").Run(); err != nil {
+		return errors.Wrap(err, "failed to make the storage script executable")
+	}
+	if err := nodeutils.WriteFile(node, "/etc/systemd/system/kind-storage.service", mountUnit); err != nil {
+		return err
+	}
+	if err := node.Command("systemctl", "daemon-reload").Run(); err != nil {
+		return errors.Wrap(err, "failed to reload systemd")
+	}
+	if err := node.Command("systemctl", "enable", "--now", "kind-storage.service").Run(); err != nil {
+		return errors.Wrap(err, "failed to attach node filesystems")
+	}
+	// the runtime first, the kubelet depends on it
+	for i := len(services) - 1; i >= 0; i-- {
+		if err := node.Command("systemctl", "start", services[i]).Run(); err != nil {
+			return errors.Wrapf(err, "failed to start %s", services[i])
+		}
+	}
+	return nil
+}
+
+// sizeRE matches the quantities allowed by config validation
+var sizeRE = regexp.MustCompile(`^([0-9]+)(k|M|G|T|Ki|Mi|Gi|Ti)?$`)
+
+// sizeMultipliers are the bytes in each quantity suffix
+var sizeMultipliers = map[string]int64{
+	"":   1,
+	"k":  1e3,
+	"M":  1e6,
+	"G":  1e9,
+	"T":  1e12,
+	"Ki": 1 << 10,
+	"Mi": 1 << 20,
+	"Gi": 1 << 30,
+	"Ti": 1 << 40,
+}
+
+// sizeBytes returns the number of bytes in the quantity size, EG 20Gi
+func sizeBytes(size string) (int64, error) {
+	match := sizeRE.FindStringSubmatch(size)
+	if match == nil {
+		return 0, errors.Errorf("invalid size %q", size)
+	}
+	n, err := strconv.ParseInt(match[1], 10, 64)
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid size %q", size)
+	}
+	return n * sizeMultipliers[match[2]], nil
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodestorage
+
+import (
+	"testing"
+)
+
+func TestSizeBytes(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Size        string
+		Expected    int64
+		ExpectError bool
+	}{
+		{Size: "4096", Expected: 4096},
+		{Size: "512Mi", Expected: 512 << 20},
+		{Size: "20Gi", Expected: 20 << 30},
+		{Size: "8G", Expected: 8e9},
+		{Size: "1k", Expected: 1000},
+		{Size: "1.5Gi", ExpectError: true},
+		{Size: "10gb", ExpectError: true},
+	}
+	for _, tc := range cases {
+		tc := tc // capture tc
+		t.Run(tc.Size, func(t *testing.T) {
+			t.Parallel()
+			bytes, err := sizeBytes(tc.Size)
+			if tc.ExpectError {
+				if err == nil {
+					t.Fatalf("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if bytes != tc.Expected {
+				t.Errorf("expected %d but got %d", tc.Expected, bytes)
+			}
+		})
+	}
+}
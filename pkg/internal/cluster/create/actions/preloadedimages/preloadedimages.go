@@ -0,0 +1,83 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package preloadedimages implements the action recording the images the
+// node images ship with
+package preloadedimages
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/globals"
+
+	"sigs.k8s.io/kind/pkg/internal/cluster/create/actions"
+)
+
+// Path is where the image references present in each Kubernetes node's
+// container runtime before anything was pulled or loaded are recorded, one
+// per line as listed by ctr
+const Path = "/kind/preloaded-images"
+
+// recordScript lists the images once containerd is up, which it may not be
+// yet right after the node started
+var recordScript = fmt.Sprintf(`for i in $(seq 30); do
+  if ctr --namespace=k8s.io images list --quiet > %[1]s.partial; then
+    exec mv %[1]s.partial %[1]s
+  fi
+  sleep 1
+done
+exit 1`, Path)
+
+type action struct{}
+
+// NewAction returns a new action for recording the preloaded images
+func NewAction() actions.Action {
+	return &action{}
+}
+
+// Execute runs the action, the record is only informational so failures
+// are only logged
+func (a *action) Execute(ctx *actions.ActionContext) error {
+	allNodes, err := ctx.Nodes()
+	if err != nil {
+		return err
+	}
+	kubernetesNodes, err := nodeutils.KubernetesNodes(allNodes)
+	if err != nil {
+		return err
+	}
+	fns := []func() error{}
+	for _, node := range kubernetesNodes {
+		node := node // capture loop variable
+		fns = append(fns, func() error {
+			return record(node)
+		})
+	}
+	if err := errors.AggregateConcurrent(fns...); err != nil {
+		globals.GetLogger().Warnf("failed to record preloaded images: %v", err)
+	}
+	return nil
+}
+
+func record(node nodes.Node) error {
+	return errors.Wrapf(
+		node.Command("sh", "-c", recordScript).Run(),
+		"failed to list images on node %s", node.String(),
+	)
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package prepullimages implements the actions pre-pulling the configured
+// workload images onto all kubernetes nodes while the cluster comes up
+package prepullimages
+
+import (
+	gocontext "context"
+	"fmt"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/cluster/create/actions"
+	"sigs.k8s.io/kind/pkg/internal/util/ratelimit"
+)
+
+// defaultTimeout bounds each pull if the config does not set a timeout
+const defaultTimeout = 5 * time.Minute
+
+// pulls is the state shared by the start and wait actions
+type pulls struct {
+	images []string
+	done   chan error
+	// ctx bounds the pulls, it is cancelled by stop
+	ctx    gocontext.Context
+	cancel gocontext.CancelFunc
+	wg     sync.WaitGroup
+}
+
+type startAction struct {
+	*pulls
+}
+
+type waitAction struct {
+	*pulls
+}
+
+// NewActions returns a pair of actions, the first starts pulling the
+// configured images in the background and the second waits for them
+//
+// Actions run between the two (EG waiting for readiness) overlap the pulls.
+// stop cancels the pulls and waits for them to return, it must be called
+// once creating the cluster is done, including when an action failed before
+// the wait action ran
+func NewActions() (start, wait actions.Action, stop func()) {
+	ctx, cancel := gocontext.WithCancel(gocontext.Background())
+	p := &pulls{ctx: ctx, cancel: cancel}
+	return &startAction{p}, &waitAction{p}, p.stop
+}
+
+// stop cancels any pulls still running and waits for them to return
+func (p *pulls) stop() {
+	p.cancel()
+	p.wg.Wait()
+}
+
+// Execute runs the action
+func (a *startAction) Execute(ctx *actions.ActionContext) error {
+	// nothing to do
+	if ctx.Config.ImagePulls == nil || len(ctx.Config.ImagePulls.PrePull) == 0 {
+		return nil
+	}
+
+	allNodes, err := ctx.Nodes()
+	if err != nil {
+		return err
+	}
+	kubeNodes, err := nodeutils.KubernetesNodes(allNodes)
+	if err != nil {
+		return err
+	}
+
+	timeout := pullTimeout(ctx.Config.ImagePulls)
+	// rate limited pulls are reported together, rather than per node
+	var mu sync.Mutex
+	limited := &ratelimit.Error{
+		Pulls:  map[string][]string{},
+		Advice: ratelimit.WorkloadImageAdvice,
+	}
+	fns := []func() error{}
+	for _, node := range kubeNodes {
+		for _, image := range ctx.Config.ImagePulls.PrePull {
+			node, image := node, image // capture loop variables
+			fns = append(fns, func() error {
+				err := pullImage(a.ctx, node, image, timeout)
+				if ratelimit.Is(err) {
+					mu.Lock()
+					defer mu.Unlock()
+					limited.Pulls[image] = append(limited.Pulls[image], node.String())
+					return nil
+				}
+				return err
+			})
+		}
+	}
+
+	a.images = ctx.Config.ImagePulls.PrePull
+	a.done = make(chan error, 1)
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		err := errors.AggregateConcurrent(fns...)
+		if len(limited.Pulls) > 0 {
+			err = errors.NewAggregate([]error{limited, err})
+		}
+		a.done <- err
+	}()
+	return nil
+}
+
+// Execute runs the action
+func (a *waitAction) Execute(ctx *actions.ActionContext) error {
+	// the pulls were never started
+	if a.done == nil {
+		return nil
+	}
+
+	ctx.Status.Start(fmt.Sprintf("Pre-pulling %d images 📦", len(a.images)))
+	defer ctx.Status.End(false)
+
+	if err := <-a.done; err != nil {
+		return errors.Wrap(err, "failed to pre-pull images")
+	}
+
+	ctx.Status.End(true)
+	return nil
+}
+
+// rateLimitRetries is how many times pulls failing on a registry rate limit
+// are retried, backing off between them
+const rateLimitRetries = 3
+
+// pullImage pulls image onto node with the runtime's CRI client, which
+// works the same for all supported runtimes, until ctx is done
+func pullImage(ctx gocontext.Context, node nodes.Node, image string, timeout time.Duration) error {
+	var err error
+	for attempt := 0; ; attempt++ {
+		err = exec.CommandWithContext(ctx, node,
+			"timeout", fmt.Sprintf("%gs", timeout.Seconds()),
+			"crictl", "pull", image,
+		).Run()
+		if !ratelimit.Is(err) || attempt == rateLimitRetries {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return errors.Wrapf(ctx.Err(), "failed to pull %q on node %q", image, node.String())
+		case <-time.After(ratelimit.Backoff(attempt)):
+		}
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to pull %q on node %q", image, node.String())
+	}
+	return nil
+}
+
+// pullTimeout returns the configured pull timeout or the default
+func pullTimeout(p *config.ImagePulls) time.Duration {
+	// validation ensures the timeout parses if set
+	if d, err := time.ParseDuration(p.Timeout); err == nil && d > 0 {
+		return d
+	}
+	return defaultTimeout
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prepullimages
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/exec"
+)
+
+// hangingNode is a node where every command runs until its ctx is done
+type hangingNode struct {
+	nodes.Node
+}
+
+func (n *hangingNode) String() string { return "kind-worker" }
+
+func (n *hangingNode) Command(command string, args ...string) exec.Cmd {
+	return n.CommandContext(context.Background(), command, args...)
+}
+
+func (n *hangingNode) CommandContext(ctx context.Context, command string, args ...string) exec.Cmd {
+	return &hangingCmd{ctx: ctx, args: append([]string{command}, args...)}
+}
+
+type hangingCmd struct {
+	ctx  context.Context
+	args []string
+}
+
+func (c *hangingCmd) Run() error {
+	<-c.ctx.Done()
+	return &exec.RunError{Command: c.args, Inner: c.ctx.Err()}
+}
+
+func (c *hangingCmd) SetEnv(...string) exec.Cmd    { return c }
+func (c *hangingCmd) SetStdin(io.Reader) exec.Cmd  { return c }
+func (c *hangingCmd) SetStdout(io.Writer) exec.Cmd { return c }
+func (c *hangingCmd) SetStderr(io.Writer) exec.Cmd { return c }
+
+func TestPullImageCancelled(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- pullImage(ctx, &hangingNode{}, "busybox", time.Hour)
+	}()
+	cancel()
+	select {
+	case err := <-errCh:
+		if err == nil {
+			t.Errorf("pullImage() = nil, expected an error once cancelled")
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("pullImage() did not return once cancelled")
+	}
+}
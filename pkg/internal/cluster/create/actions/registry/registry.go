@@ -0,0 +1,144 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package registry implements the registry node configuration action
+package registry
+
+import (
+	"fmt"
+	"net"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/internal/cluster/create/actions"
+	"sigs.k8s.io/kind/pkg/internal/cluster/registry"
+	"sigs.k8s.io/kind/pkg/internal/cluster/runtime"
+)
+
+// Action implements an action for configuring and starting the registry
+// node, and configuring the other nodes to use it as a mirror
+type Action struct{}
+
+// NewAction returns a new Action for configuring the registry
+func NewAction() actions.Action {
+	return &Action{}
+}
+
+// Execute runs the action
+func (a *Action) Execute(ctx *actions.ActionContext) error {
+	allNodes, err := ctx.Nodes()
+	if err != nil {
+		return err
+	}
+
+	// identify the registry node
+	registryNode, err := nodeutils.RegistryNode(allNodes)
+	if err != nil {
+		return err
+	}
+
+	// if there's no registry we're done
+	if registryNode == nil {
+		return nil
+	}
+
+	// otherwise notify the user
+	ctx.Status.Start("Configuring the registry 🗄️")
+	defer ctx.Status.End(false)
+
+	// the nodes reach the registry by IP, so the mirror does not depend on
+	// name resolution on the node network
+	ipv4, ipv6, err := registryNode.IP()
+	if err != nil {
+		return errors.Wrapf(err, "failed to get IP for node %s", registryNode.String())
+	}
+	ip := ipv4
+	if ctx.Config.Networking.IPFamily == "ipv6" {
+		ip = ipv6
+	}
+	port := fmt.Sprintf("%d", registry.Port)
+	endpoint := net.JoinHostPort(ip, port)
+
+	// generate and write the serving certificates, the key must be written
+	// last as the registry starts serving as soon as it exists
+	certs, err := registry.GenerateCerts(registryNode.String(), "localhost", ip)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate registry certificates")
+	}
+	if err := nodeutils.WriteFile(registryNode, registry.CertPath, string(certs.Cert)); err != nil {
+		return errors.Wrap(err, "failed to copy registry certificate to node")
+	}
+	if err := nodeutils.WriteFile(registryNode, registry.KeyPath, string(certs.Key)); err != nil {
+		return errors.Wrap(err, "failed to copy registry key to node")
+	}
+
+	// configure the kubernetes nodes to use the registry as a mirror
+	r, err := runtime.ForName(string(ctx.Config.ContainerRuntime))
+	if err != nil {
+		return err
+	}
+	mirrorConfigData := &registry.MirrorConfigData{
+		Hosts: []string{
+			net.JoinHostPort("localhost", port),
+			net.JoinHostPort(registryNode.String(), port),
+		},
+		Endpoint: endpoint,
+		CAPath:   registry.CAPath,
+	}
+	configPath, caPath := registry.ContainerdConfigPath, registry.CAPath
+	mirrorConfig, err := registry.MirrorConfig(mirrorConfigData)
+	if r.Name == runtime.CRIO.Name {
+		configPath, caPath = registry.CRIOConfigPath, registry.CRIOCAPath(endpoint)
+		mirrorConfig, err = registry.CRIOMirrorConfig(mirrorConfigData)
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to generate registry mirror config")
+	}
+	kubernetesNodes, err := nodeutils.KubernetesNodes(allNodes)
+	if err != nil {
+		return err
+	}
+	fns := []func() error{}
+	for _, node := range kubernetesNodes {
+		node := node // capture loop variable
+		fns = append(fns, func() error {
+			return configureMirror(node, r, caPath, string(certs.CACert), configPath, mirrorConfig)
+		})
+	}
+	if err := errors.UntilErrorConcurrent(fns); err != nil {
+		return err
+	}
+
+	ctx.Status.End(true)
+	return nil
+}
+
+// configureMirror trusts the registry CA on node, appends the mirror config
+// to the runtime config and restarts the runtime to pick it up
+func configureMirror(node nodes.Node, r runtime.Runtime, caPath, caCert, configPath, mirrorConfig string) error {
+	if err := nodeutils.WriteFile(node, caPath, caCert); err != nil {
+		return errors.Wrap(err, "failed to copy registry CA to node")
+	}
+	if err := nodeutils.AppendFile(node, configPath, mirrorConfig); err != nil {
+		return errors.Wrap(err, "failed to configure registry mirror on node")
+	}
+	if err := node.Command("systemctl", "restart", r.Service).Run(); err != nil {
+		return errors.Wrapf(err, "failed to restart %s", r.Name)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package runtime implements the container runtime configuration action
+package runtime
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/internal/cluster/create/actions"
+	"sigs.k8s.io/kind/pkg/internal/cluster/runtime"
+)
+
+// Action implements an action for switching the kubernetes nodes to the
+// configured container runtime
+type Action struct{}
+
+// NewAction returns a new Action for configuring the container runtime
+func NewAction() actions.Action {
+	return &Action{}
+}
+
+// Execute runs the action
+func (a *Action) Execute(ctx *actions.ActionContext) error {
+	r, err := runtime.ForName(string(ctx.Config.ContainerRuntime))
+	if err != nil {
+		return err
+	}
+
+	// the node images run the default runtime already, nothing to do
+	if r.Name == runtime.Containerd.Name {
+		return nil
+	}
+
+	ctx.Status.Start(fmt.Sprintf("Starting %s ⚙️", r.Name))
+	defer ctx.Status.End(false)
+
+	allNodes, err := ctx.Nodes()
+	if err != nil {
+		return err
+	}
+	kubernetesNodes, err := nodeutils.KubernetesNodes(allNodes)
+	if err != nil {
+		return err
+	}
+
+	fns := []func() error{}
+	for _, node := range kubernetesNodes {
+		node := node // capture loop variable
+		fns = append(fns, func() error {
+			return switchRuntime(node, r)
+		})
+	}
+	if err := errors.UntilErrorConcurrent(fns); err != nil {
+		return err
+	}
+
+	ctx.Status.End(true)
+	return nil
+}
+
+// switchRuntime replaces the default runtime on node with r
+func switchRuntime(node nodes.Node, r runtime.Runtime) error {
+	if err := node.Command("systemctl", "cat", r.Service).Run(); err != nil {
+		return errors.Errorf(
+			"node %s image does not contain %s, use a node image built with `kind build base-image --container-runtime=%s`",
+			node.String(), r.Name, r.Name,
+		)
+	}
+	if err := node.Command("systemctl", "disable", "--now", runtime.Containerd.Service).Run(); err != nil {
+		return errors.Wrapf(err, "failed to stop %s", runtime.Containerd.Name)
+	}
+	if err := node.Command("systemctl", "enable", "--now", r.Service).Run(); err != nil {
+		return errors.Wrapf(err, "failed to start %s", r.Name)
+	}
+	// point crictl at the new runtime
+	crictlConfig := fmt.Sprintf("runtime-endpoint: unix://%s\n", r.Socket)
+	if err := nodeutils.WriteFile(node, "/etc/crictl.yaml", crictlConfig); err != nil {
+		return errors.Wrap(err, "failed to configure crictl")
+	}
+	// record the runtime for later operations on the node (image loading etc.)
+	return nodeutils.WriteFile(node, runtime.MarkerPath, r.Name)
+}
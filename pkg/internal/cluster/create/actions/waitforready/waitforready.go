@@ -18,25 +18,32 @@ limitations under the License.
 package waitforready
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
 
 	"sigs.k8s.io/kind/pkg/cluster/nodes"
 	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
 	"sigs.k8s.io/kind/pkg/exec"
 	"sigs.k8s.io/kind/pkg/internal/cluster/create/actions"
+	"sigs.k8s.io/kind/pkg/internal/cluster/lifecycle"
+	"sigs.k8s.io/kind/pkg/internal/util/probe"
 )
 
 // Action implements an action for waiting for the cluster to be ready
 type Action struct {
 	waitTime time.Duration
+	minNodes int
 }
 
-// NewAction returns a new action for waiting for the cluster to be ready
-func NewAction(waitTime time.Duration) actions.Action {
+// NewAction returns a new action for waiting for the cluster to be ready,
+// IE the control plane and at least minNodes nodes of any role
+func NewAction(waitTime time.Duration, minNodes int) actions.Action {
 	return &Action{
 		waitTime: waitTime,
+		minNodes: minNodes,
 	}
 }
 
@@ -46,17 +53,17 @@ func (a *Action) Execute(ctx *actions.ActionContext) error {
 	if a.waitTime == time.Duration(0) {
 		return nil
 	}
-	ctx.Status.Start(
-		fmt.Sprintf(
-			"Waiting ≤ %s for control-plane = Ready ⏳",
-			formatDuration(a.waitTime),
-		),
-	)
-
 	allNodes, err := ctx.Nodes()
 	if err != nil {
 		return err
 	}
+	kubernetesNodes, err := nodeutils.KubernetesNodes(allNodes)
+	if err != nil {
+		return err
+	}
+	if a.minNodes > len(kubernetesNodes) {
+		return errors.Errorf("cannot wait for %d nodes to be Ready, the cluster has %d", a.minNodes, len(kubernetesNodes))
+	}
 	// get a control plane node to use to check cluster status
 	controlPlanes, err := nodeutils.ControlPlaneNodes(allNodes)
 	if err != nil {
@@ -64,12 +71,33 @@ func (a *Action) Execute(ctx *actions.ActionContext) error {
 	}
 	node := controlPlanes[0] // kind expects at least one always
 
+	target := "control-plane"
+	if a.minNodes > 0 {
+		target = fmt.Sprintf("control-plane and %d nodes", a.minNodes)
+	}
+	// the phase may time out before the requested wait time
+	deadline := ctx.Deadline(a.waitTime)
+	waitTime := time.Until(deadline)
+	ctx.Status.Start(
+		fmt.Sprintf(
+			"Waiting ≤ %s for %s = Ready ⏳",
+			formatDuration(waitTime), target,
+		),
+	)
+
 	// Wait for the nodes to reach Ready status.
 	startTime := time.Now()
-	isReady := waitForReady(node, startTime.Add(a.waitTime))
-	if !isReady {
+	ready := controlPlanesReady(node)
+	if a.minNodes > 0 {
+		ready = probe.All(ready, nodesReady(node, a.minNodes))
+	}
+	if _, err := probe.Until(deadline, probe.DefaultBackoff, ready); err != nil {
 		ctx.Status.End(false)
 		fmt.Println(" • WARNING: Timed out waiting for Ready ⚠️")
+		fmt.Printf(" • %v\n", err)
+		ctx.ClusterContext.SetPhase(lifecycle.Degraded, errors.Wrapf(
+			err, "timed out waiting %s for %s to be Ready", formatDuration(waitTime), target,
+		))
 		return nil
 	}
 
@@ -79,11 +107,11 @@ func (a *Action) Execute(ctx *actions.ActionContext) error {
 	return nil
 }
 
-// WaitForReady uses kubectl inside the "node" container to check if the
-// control plane nodes are "Ready".
-func waitForReady(node nodes.Node, until time.Time) bool {
-	return tryUntil(until, func() bool {
-		cmd := node.Command(
+// controlPlanesReady returns a probe using kubectl inside the "node"
+// container to check if the control plane nodes are "Ready".
+func controlPlanesReady(node nodes.Node) probe.Func {
+	return probe.Command(func(ctx context.Context) exec.Cmd {
+		return exec.CommandWithContext(ctx, node,
 			"kubectl",
 			"--kubeconfig=/etc/kubernetes/admin.conf",
 			"get",
@@ -93,11 +121,10 @@ func waitForReady(node nodes.Node, until time.Time) bool {
 			// to true.
 			"-o=jsonpath='{.items..status.conditions[-1:].status}'",
 		)
-		lines, err := exec.CombinedOutputLines(cmd)
-		if err != nil {
-			return false
+	}, func(lines []string) error {
+		if len(lines) == 0 {
+			return errors.New("no control plane nodes found")
 		}
-
 		// 'lines' will return the status of all nodes labeled as master. For
 		// example, if we have three control plane nodes, and all are ready,
 		// then the status will have the following format: `True True True'.
@@ -106,22 +133,41 @@ func waitForReady(node nodes.Node, until time.Time) bool {
 			// Check node status. If node is ready then this will be 'True',
 			// 'False' or 'Unkown' otherwise.
 			if !strings.Contains(s, "True") {
-				return false
+				return errors.Errorf("control plane nodes are not Ready: %s", lines[0])
 			}
 		}
-		return true
+		return nil
+	})
+}
+
+// nodesReady returns a probe using kubectl inside the "node" container to
+// check if at least min nodes of any role are "Ready"
+func nodesReady(node nodes.Node, min int) probe.Func {
+	return probe.Command(func(ctx context.Context) exec.Cmd {
+		return exec.CommandWithContext(ctx, node,
+			"kubectl",
+			"--kubeconfig=/etc/kubernetes/admin.conf",
+			"get",
+			"nodes",
+			`-o=jsonpath={range .items[*]}{.status.conditions[?(@.type=="Ready")].status}{"\n"}{end}`,
+		)
+	}, func(lines []string) error {
+		if ready := countReady(lines); ready < min {
+			return errors.Errorf("%d of at least %d nodes are Ready", ready, min)
+		}
+		return nil
 	})
 }
 
-// helper that calls `try()`` in a loop until the deadline `until`
-// has passed or `try()`returns true, returns whether try ever returned true
-func tryUntil(until time.Time, try func() bool) bool {
-	for until.After(time.Now()) {
-		if try() {
-			return true
+// countReady counts the nodes whose Ready condition status is True
+func countReady(lines []string) int {
+	ready := 0
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "True" {
+			ready++
 		}
 	}
-	return false
+	return ready
 }
 
 func formatDuration(duration time.Duration) string {
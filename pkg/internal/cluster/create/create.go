@@ -18,27 +18,49 @@ package create
 
 import (
 	"fmt"
+	"os"
 	"regexp"
 	"runtime"
+	"strings"
+	"time"
 
 	"sigs.k8s.io/kind/pkg/internal/cluster/create/actions"
+	"sigs.k8s.io/kind/pkg/internal/cluster/create/actions/bootstrapmanifests"
+	"sigs.k8s.io/kind/pkg/internal/cluster/create/actions/bootstrapsecrets"
 
 	"sigs.k8s.io/kind/pkg/cluster/create"
 	"sigs.k8s.io/kind/pkg/errors"
 	"sigs.k8s.io/kind/pkg/globals"
 	"sigs.k8s.io/kind/pkg/internal/apis/config"
 	"sigs.k8s.io/kind/pkg/internal/apis/config/encoding"
+	"sigs.k8s.io/kind/pkg/internal/cluster/changelog"
 	"sigs.k8s.io/kind/pkg/internal/cluster/context"
+	"sigs.k8s.io/kind/pkg/internal/cluster/create/preflight"
 	createtypes "sigs.k8s.io/kind/pkg/internal/cluster/create/types"
 	"sigs.k8s.io/kind/pkg/internal/cluster/delete"
+	"sigs.k8s.io/kind/pkg/internal/cluster/lifecycle"
 	"sigs.k8s.io/kind/pkg/internal/util/cli"
+	"sigs.k8s.io/kind/pkg/internal/util/firewall"
+	"sigs.k8s.io/kind/pkg/internal/util/hostlimits"
+	"sigs.k8s.io/kind/pkg/internal/util/systemd"
 
 	configaction "sigs.k8s.io/kind/pkg/internal/cluster/create/actions/config"
+	"sigs.k8s.io/kind/pkg/internal/cluster/create/actions/controlplanemetrics"
 	"sigs.k8s.io/kind/pkg/internal/cluster/create/actions/installcni"
+	"sigs.k8s.io/kind/pkg/internal/cluster/create/actions/installcrds"
+	"sigs.k8s.io/kind/pkg/internal/cluster/create/actions/installdns"
+	"sigs.k8s.io/kind/pkg/internal/cluster/create/actions/installkonnectivity"
 	"sigs.k8s.io/kind/pkg/internal/cluster/create/actions/installstorage"
 	"sigs.k8s.io/kind/pkg/internal/cluster/create/actions/kubeadminit"
 	"sigs.k8s.io/kind/pkg/internal/cluster/create/actions/kubeadmjoin"
 	"sigs.k8s.io/kind/pkg/internal/cluster/create/actions/loadbalancer"
+	"sigs.k8s.io/kind/pkg/internal/cluster/create/actions/nodefiles"
+	"sigs.k8s.io/kind/pkg/internal/cluster/create/actions/noderoles"
+	"sigs.k8s.io/kind/pkg/internal/cluster/create/actions/nodestorage"
+	"sigs.k8s.io/kind/pkg/internal/cluster/create/actions/preloadedimages"
+	"sigs.k8s.io/kind/pkg/internal/cluster/create/actions/prepullimages"
+	"sigs.k8s.io/kind/pkg/internal/cluster/create/actions/registry"
+	runtimeaction "sigs.k8s.io/kind/pkg/internal/cluster/create/actions/runtime"
 	"sigs.k8s.io/kind/pkg/internal/cluster/create/actions/waitforready"
 )
 
@@ -78,28 +100,27 @@ func Cluster(ctx *context.Context, options ...create.ClusterOption) error {
 	if err := opts.Config.Validate(); err != nil {
 		return err
 	}
-
-	// setup a status object to show progress to the user
-	status := cli.StatusForLogger(globals.GetLogger())
-
-	// Create node containers implementing defined config Nodes
-	if err := ctx.Provider().Provision(status, ctx.Name(), opts.Config); err != nil {
-		// In case of errors nodes are deleted (except if retain is explicitly set)
-		globals.GetLogger().Errorf("%v", err)
-		if !opts.Retain {
-			_ = delete.Cluster(ctx)
-		}
-		return err
+	// the minimum of ready nodes is only waited for with the control plane
+	if opts.WaitMinNodes != 0 && opts.WaitForReady == 0 {
+		return errors.Errorf("waiting for %d nodes to be Ready requires a wait time", opts.WaitMinNodes)
 	}
 
+	// plan the actions run after provisioning, failures may only be
+	// injected into phases that run
 	// TODO(bentheelder): make this controllable from the command line?
 	actionsToRun := []actions.Action{
-		loadbalancer.NewAction(), // setup external loadbalancer
-		configaction.NewAction(), // setup kubeadm config
+		loadbalancer.NewAction(),    // setup external loadbalancer
+		nodefiles.NewAction(),       // write configured node files
+		runtimeaction.NewAction(),   // setup container runtime
+		preloadedimages.NewAction(), // record images shipped in the node images
+		nodestorage.NewAction(),     // setup size limited node storage
+		registry.NewAction(),        // setup registry and node mirrors
+		configaction.NewAction(),    // setup kubeadm config
 	}
 	if opts.SetupKubernetes {
 		actionsToRun = append(actionsToRun,
-			kubeadminit.NewAction(), // run kubeadm init
+			kubeadminit.NewAction(),      // run kubeadm init
+			bootstrapsecrets.NewAction(), // create secrets once the API is up
 		)
 		// this step might be skipped, but is next after init
 		if !opts.Config.Networking.DisableDefaultCNI {
@@ -107,25 +128,122 @@ func Cluster(ctx *context.Context, options ...create.ClusterOption) error {
 				installcni.NewAction(), // install CNI
 			)
 		}
-		// add remaining steps
+		// add remaining steps, pre-pulling images while waiting for readiness
+		startPulls, waitPulls, stopPulls := prepullimages.NewActions()
+		defer stopPulls()
+		waitForReady := waitforready.NewAction(opts.WaitForReady, opts.WaitMinNodes)
 		actionsToRun = append(actionsToRun,
-			installstorage.NewAction(),                // install StorageClass
-			kubeadmjoin.NewAction(),                   // run kubeadm join
-			waitforready.NewAction(opts.WaitForReady), // wait for cluster readiness
+			installkonnectivity.NewAction(), // install konnectivity agents
+			installdns.NewAction(),          // install node-local DNS / replace CoreDNS
+			installstorage.NewAction(),      // install StorageClass
+			kubeadmjoin.NewAction(),         // run kubeadm join
+			noderoles.NewAction(),           // label and taint custom role nodes
+			controlplanemetrics.NewAction(), // authorize reading control plane metrics
+			startPulls,                      // start pre-pulling images
+			waitForReady,                    // wait for cluster readiness
+			waitPulls,                       // wait for pre-pulled images
+			installcrds.NewAction(),         // install CRDs and APIServices
+			bootstrapmanifests.NewAction(),  // apply bootstrap manifests
 		)
 	}
+	phases := []string{actions.ProvisionPhase}
+	seen := map[string]bool{}
+	for _, action := range actionsToRun {
+		// some phases run several actions, EG prepullimages
+		if phase := actions.Name(action); !seen[phase] {
+			seen[phase] = true
+			phases = append(phases, phase)
+		}
+	}
+	if err := opts.Failures.Validate(phases); err != nil {
+		return err
+	}
+	for phase := range opts.PhaseTimeouts {
+		if !seen[phase] {
+			return errors.Errorf("cannot set a timeout for unknown create phase %q", phase)
+		}
+	}
+
+	// setup a status object to show progress to the user
+	status := cli.StatusForLogger(globals.GetLogger())
+
+	// the phases before the actions are observed as the actions are
+	observe := func(phase string, started time.Time, err error) {
+		if opts.PhaseObserver != nil {
+			opts.PhaseObserver(phase, time.Since(started), err == nil)
+		}
+	}
+
+	// run any preflight checks before creating anything
+	started := time.Now()
+	err = preflight.Run(status, opts.Config, preflightChecks(ctx, opts)...)
+	observe(actions.PreflightPhase, started, err)
+	if err != nil {
+		return err
+	}
+
+	// raise host limits before the nodes start using them
+	if opts.FixHostLimits {
+		raiseHostLimits(ctx)
+	}
+
+	// start the systemd slice the nodes will run in
+	if opts.Config.SystemdSlice != nil {
+		if err := setupSystemdSlice(ctx, opts.Config.SystemdSlice); err != nil {
+			return err
+		}
+	}
+
+	// show the node consoles as the nodes boot, until created
+	stopConsoles := func() {}
+	if opts.NodeConsole != nil {
+		stopConsoles = streamNodeConsoles(ctx, opts.NodeConsole)
+		defer stopConsoles()
+	}
+
+	// Create node containers implementing defined config Nodes
+	ctx.SetPhase(lifecycle.Creating, nil)
+	started = time.Now()
+	err = ctx.Provider().Provision(status, ctx.Name(), opts.Config, opts.Protect)
+	if err == nil {
+		err = opts.Failures.Check(actions.ProvisionPhase)
+	}
+	observe(actions.ProvisionPhase, started, err)
+	if err != nil {
+		// In case of errors nodes are deleted (except if retain is explicitly set)
+		globals.GetLogger().Errorf("%v", err)
+		failCreate(ctx, opts.Retain, err)
+		return err
+	}
+
+	// the cluster network exists now, allow its traffic through the firewall
+	if opts.FixFirewall {
+		allowFirewall(ctx)
+	}
 
 	// run all actions
 	actionsContext := actions.NewActionContext(opts.Config, ctx, status)
+	actionsContext.SetFailures(opts.Failures)
+	actionsContext.SetPhaseTimeouts(opts.PhaseTimeouts)
+	actionsContext.SetPhaseObserver(opts.PhaseObserver)
 	for _, action := range actionsToRun {
-		if err := action.Execute(actionsContext); err != nil {
-			if !opts.Retain {
-				_ = delete.Cluster(ctx)
-			}
+		if err := actionsContext.Execute(action); err != nil {
+			failCreate(ctx, opts.Retain, err)
 			return err
 		}
 	}
 
+	// the instructions printed next should not be interleaved with consoles
+	stopConsoles()
+
+	recordCreate(ctx, opts.Config)
+
+	// actions may mark the cluster degraded without failing creation,
+	// E.G. when it is not ready in time
+	if current, err := lifecycle.Read(ctx.StatusPath()); err != nil || current == nil || current.Phase == lifecycle.Creating {
+		ctx.SetPhase(lifecycle.Ready, nil)
+	}
+
 	if !opts.SetupKubernetes {
 		// prints how to manually setup the cluster
 		printSetupInstruction(ctx.Name())
@@ -138,6 +256,124 @@ func Cluster(ctx *context.Context, options ...create.ClusterOption) error {
 	return nil
 }
 
+// failCreate marks the cluster degraded after creating it failed with
+// cause if it is retained, otherwise it deletes the cluster and keeps its
+// status file to report the failure
+func failCreate(ctx *context.Context, retain bool, cause error) {
+	if retain {
+		ctx.SetPhase(lifecycle.Degraded, cause)
+		return
+	}
+	status, _ := lifecycle.Read(ctx.StatusPath())
+	_ = delete.Cluster(ctx)
+	if status != nil {
+		if err := lifecycle.Write(ctx.StatusPath(), status); err != nil {
+			globals.GetLogger().Warnf("failed to restore cluster status: %v", err)
+		}
+	}
+	ctx.SetPhase(lifecycle.Failed, cause)
+}
+
+// recordCreate records creating the cluster with cfg in its changelog
+func recordCreate(ctx *context.Context, cfg *config.Cluster) {
+	entry := changelog.Entry{Action: changelog.ActionCreate, Details: map[string]string{}}
+	if n, err := ctx.ListNodes(); err == nil {
+		for _, node := range n {
+			entry.Nodes = append(entry.Nodes, node.String())
+		}
+	}
+	images := []string{}
+	seen := map[string]bool{}
+	for _, node := range cfg.Nodes {
+		if node.Role != config.RegistryRole && !seen[node.Image] {
+			seen[node.Image] = true
+			images = append(images, node.Image)
+		}
+	}
+	entry.Details["images"] = strings.Join(images, ",")
+	changelog.Record(ctx, entry)
+}
+
+// allowFirewall adds host firewall rules for the cluster network, failures
+// are only logged as the firewall may not be interfering
+func allowFirewall(ctx *context.Context) {
+	iface, err := ctx.Provider().GetNetworkInterface(ctx.Name())
+	if err != nil {
+		globals.GetLogger().Warnf("failed to get the cluster network interface, not adding firewall rules: %v", err)
+		return
+	}
+	// record the interface first so delete removes any partially added rules
+	ctx.KeepFile(context.FirewallFile, []byte(iface))
+	if err := firewall.Allow(iface); err != nil {
+		globals.GetLogger().Warnf("failed to add firewall rules for %s: %v", iface, err)
+	}
+}
+
+// raiseHostLimits raises the host limits that are too low for the nodes,
+// failures are only logged as the nodes may not reach them
+func raiseHostLimits(ctx *context.Context) {
+	if runtime.GOOS != "linux" {
+		return
+	}
+	low, err := hostlimits.Detect()
+	if err != nil {
+		globals.GetLogger().Warnf("failed to check host limits, not raising them: %v", err)
+		return
+	}
+	if len(low) == 0 {
+		return
+	}
+	// record the original values first so delete restores any partially
+	// raised limits
+	ctx.KeepFile(context.HostLimitsFile, []byte(hostlimits.Format(low)))
+	if err := hostlimits.Raise(low); err != nil {
+		globals.GetLogger().Warnf("failed to raise host limits: %v", err)
+	}
+}
+
+// setupSystemdSlice starts the systemd slice the nodes run in with its
+// limits, defaulting its name for the provider
+func setupSystemdSlice(ctx *context.Context, slice *config.SystemdSlice) error {
+	if runtime.GOOS != "linux" {
+		return errors.Errorf("systemdSlice is only supported on linux, not %s", runtime.GOOS)
+	}
+	if slice.Name == "" {
+		slice.Name = systemd.DefaultSliceName(ctx.Name())
+	}
+	// record the slice first so delete stops a partially configured slice
+	ctx.KeepFile(context.SystemdSliceFile, []byte(slice.Name))
+	return systemd.SetupSlice(slice.Name, systemd.Limits{
+		CPUQuota:  slice.CPUQuota,
+		MemoryMax: slice.MemoryMax,
+	})
+}
+
+// preflightChecks returns the preflight checks enabled by opts
+func preflightChecks(ctx *context.Context, opts *createtypes.ClusterOptions) []preflight.Check {
+	checks := []preflight.Check{
+		preflight.KernelModules(opts.LoadKernelModules),
+		preflight.HostPolicy(preflight.HostPolicyPath(), ctx.Provider()),
+		preflight.Firewall(opts.FixFirewall),
+		preflight.HostLimits(opts.FixHostLimits),
+		preflight.SELinux(),
+	}
+	if opts.ImageScan != nil {
+		checks = append(checks, preflight.ImageScan(
+			opts.ImageScan.Scanner, opts.ImageScan.Severity, opts.ImageScan.WarnOnly,
+		))
+	}
+	return checks
+}
+
+// Config returns the defaulted cluster config Cluster creates with options
+func Config(options ...create.ClusterOption) (*config.Cluster, error) {
+	opts, err := collectOptions(options...)
+	if err != nil {
+		return nil, err
+	}
+	return opts.Config, nil
+}
+
 func collectOptions(options ...create.ClusterOption) (*createtypes.ClusterOptions, error) {
 	// apply options
 	opts := &createtypes.ClusterOptions{
@@ -151,6 +387,26 @@ func collectOptions(options ...create.ClusterOption) (*createtypes.ClusterOption
 		opts = newOpts
 	}
 
+	// failures may also be injected from the environment, EG into kind's
+	// own e2e tests
+	if spec := os.Getenv(actions.FailuresEnv); spec != "" {
+		failures, err := actions.ParseFailures(spec)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid %s", actions.FailuresEnv)
+		}
+		if opts.Failures == nil {
+			opts.Failures = actions.Failures{}
+		}
+		for phase, failure := range failures {
+			if failure.Phase {
+				opts.Failures.Add(phase)
+			}
+			if len(failure.Nodes) > 0 {
+				opts.Failures.Add(phase, failure.Nodes...)
+			}
+		}
+	}
+
 	// do post processing for options
 	// first ensure we at least have a default cluster config
 	if opts.Config == nil {
@@ -167,6 +423,10 @@ func collectOptions(options ...create.ClusterOption) (*createtypes.ClusterOption
 		// TODO(fabrizio pandini): this should be reconsidered when implementing
 		//     https://github.com/kubernetes-sigs/kind/issues/133
 		for i := range opts.Config.Nodes {
+			// registry nodes do not run the node image
+			if opts.Config.Nodes[i].Role == config.RegistryRole {
+				continue
+			}
 			opts.Config.Nodes[i].Image = opts.NodeImage
 		}
 	}
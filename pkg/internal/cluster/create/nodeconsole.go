@@ -0,0 +1,108 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package create
+
+import (
+	gocontext "context"
+	"io"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/internal/cluster/context"
+	"sigs.k8s.io/kind/pkg/internal/cluster/logs"
+)
+
+// nodeConsolePollInterval is how often new node containers are looked for
+// while streaming node consoles
+const nodeConsolePollInterval = 500 * time.Millisecond
+
+// streamNodeConsoles streams the output of the cluster's node containers to
+// w, each line prefixed with the name of its node, starting on each node as
+// soon as its container is created, until stop is called
+func streamNodeConsoles(ctx *context.Context, w io.Writer) (stop func()) {
+	return followNodes(ctx, w, streamNodeConsole)
+}
+
+// streamNodeConsole streams the output of the node container to w until
+// ctx is done
+func streamNodeConsole(ctx gocontext.Context, node nodes.Node, w io.Writer) {
+	// the stream ends with an error once stopped, or the node is deleted,
+	// neither are of interest
+	_ = logs.Stream(ctx, []nodes.Node{node}, w, logs.StreamOptions{
+		Follow: true,
+		Serial: true,
+	})
+}
+
+// followNodes calls stream for each of the cluster's nodes as soon as it
+// is created, until stop is called, which cancels the ctx of every stream
+// and waits for them to return
+func followNodes(ctx *context.Context, w io.Writer, stream func(gocontext.Context, nodes.Node, io.Writer)) (stop func()) {
+	streamCtx, cancel := gocontext.WithCancel(gocontext.Background())
+	// lines from nodes streamed separately must not interleave
+	w = &syncWriter{w: w}
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		streaming := map[string]bool{}
+		ticker := time.NewTicker(nodeConsolePollInterval)
+		defer ticker.Stop()
+		for {
+			// listing fails while nodes are partially created, try again
+			// on the next tick
+			if n, err := ctx.ListInternalNodes(); err == nil {
+				for _, node := range n {
+					if streaming[node.String()] {
+						continue
+					}
+					streaming[node.String()] = true
+					wg.Add(1)
+					go func(node nodes.Node) {
+						defer wg.Done()
+						stream(streamCtx, node, w)
+					}(node)
+				}
+			}
+			select {
+			case <-streamCtx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	once := sync.Once{}
+	return func() {
+		once.Do(func() {
+			cancel()
+			wg.Wait()
+		})
+	}
+}
+
+// syncWriter serializes writes to w
+type syncWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (s *syncWriter) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.Write(p)
+}
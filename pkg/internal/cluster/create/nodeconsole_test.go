@@ -0,0 +1,121 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package create
+
+import (
+	"bytes"
+	gocontext "context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/cluster/constants"
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+
+	"sigs.k8s.io/kind/pkg/internal/cluster/context"
+	"sigs.k8s.io/kind/pkg/internal/cluster/providers/provider"
+)
+
+// fakeProvider lists the nodes created so far
+type fakeProvider struct {
+	// unimplemented methods panic
+	provider.Provider
+	mu    sync.Mutex
+	nodes []nodes.Node
+}
+
+func (p *fakeProvider) ListNodes(cluster string) ([]nodes.Node, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]nodes.Node{}, p.nodes...), nil
+}
+
+func (p *fakeProvider) add(n nodes.Node) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.nodes = append(p.nodes, n)
+}
+
+// fakeNode is a node only implementing String and Role
+type fakeNode struct {
+	nodes.Node
+	name string
+	role string
+}
+
+func (n *fakeNode) String() string        { return n.name }
+func (n *fakeNode) Role() (string, error) { return n.role, nil }
+
+func TestFollowNodes(t *testing.T) {
+	t.Parallel()
+	p := &fakeProvider{nodes: []nodes.Node{
+		&fakeNode{name: "kind-control-plane", role: constants.ControlPlaneNodeRoleValue},
+		&fakeNode{name: "kind-external-load-balancer", role: constants.ExternalLoadBalancerNodeRoleValue},
+	}}
+	ctx := context.NewProviderContext(p, "kind")
+
+	// each stream runs until it is stopped
+	var running int32
+	started := make(chan string, 10)
+	stream := func(ctx gocontext.Context, node nodes.Node, w io.Writer) {
+		atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		fmt.Fprintf(w, "%s: started\n", node)
+		started <- node.String()
+		<-ctx.Done()
+	}
+	waitStarted := func(expected string) {
+		select {
+		case name := <-started:
+			if name != expected {
+				t.Fatalf("started streaming %s, expected %s", name, expected)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting to stream %s", expected)
+		}
+	}
+
+	var out bytes.Buffer
+	stop := followNodes(ctx, &out, stream)
+	waitStarted("kind-control-plane")
+	// nodes created later are streamed too
+	p.add(&fakeNode{name: "kind-worker", role: constants.WorkerNodeRoleValue})
+	waitStarted("kind-worker")
+
+	stop()
+	if n := atomic.LoadInt32(&running); n != 0 {
+		t.Errorf("%d streams still running once stopped", n)
+	}
+	// stopping again is a no-op
+	stop()
+	select {
+	case name := <-started:
+		t.Errorf("unexpected stream of %s, nodes are streamed once and only kubernetes nodes", name)
+	default:
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	sort.Strings(lines)
+	expected := []string{"kind-control-plane: started", "kind-worker: started"}
+	if strings.Join(lines, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("streamed %q, expected %q", lines, expected)
+	}
+}
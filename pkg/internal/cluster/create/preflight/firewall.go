@@ -0,0 +1,61 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preflight
+
+import (
+	"runtime"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/util/firewall"
+)
+
+type hostFirewall struct {
+	fix bool
+}
+
+// Firewall returns a Check warning about host firewall configurations known
+// to break traffic on the cluster network, such as mixed iptables backends,
+// firewalld with nftables or ufw dropping forwarded traffic.
+//
+// If fix is set the warnings note that kind will add rules allowing the
+// cluster's traffic once its network exists, see firewall.Allow
+func Firewall(fix bool) Check {
+	return &hostFirewall{
+		fix: fix,
+	}
+}
+
+// Name is part of the Check interface
+func (h *hostFirewall) Name() string {
+	return "firewall"
+}
+
+// Run is part of the Check interface
+func (h *hostFirewall) Run(cfg *config.Cluster) (warnings []string, err error) {
+	// on other platforms docker runs in a VM with its own firewall
+	if runtime.GOOS != "linux" {
+		return nil, nil
+	}
+	hint := " (use --fix-firewall to add rules allowing cluster traffic)"
+	if h.fix {
+		hint = " (rules allowing cluster traffic will be added)"
+	}
+	for _, problem := range firewall.Detect() {
+		warnings = append(warnings, problem+hint)
+	}
+	return warnings, nil
+}
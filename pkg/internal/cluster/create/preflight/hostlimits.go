@@ -0,0 +1,69 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preflight
+
+import (
+	"fmt"
+	"runtime"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/util/hostlimits"
+)
+
+type hostLimits struct {
+	fix bool
+}
+
+// HostLimits returns a Check warning about host inotify and open file limits
+// too low for the cluster's nodes, the most common cause of "too many open
+// files" failures of the kubelet and pods.
+//
+// If fix is set the warnings note that kind will raise them before creating
+// the nodes, see hostlimits.Raise
+func HostLimits(fix bool) Check {
+	return &hostLimits{
+		fix: fix,
+	}
+}
+
+// Name is part of the Check interface
+func (h *hostLimits) Name() string {
+	return "host-limits"
+}
+
+// Run is part of the Check interface
+func (h *hostLimits) Run(cfg *config.Cluster) (warnings []string, err error) {
+	// on other platforms docker runs in a VM with its own limits
+	if runtime.GOOS != "linux" {
+		return nil, nil
+	}
+	low, err := hostlimits.Detect()
+	if err != nil {
+		return []string{fmt.Sprintf("unable to check host limits: %v", err)}, nil
+	}
+	if len(low) == 0 {
+		return nil, nil
+	}
+	hint := fmt.Sprintf(" (raise them with `sudo %s` or use --fix-host-limits)", hostlimits.Command(low))
+	if h.fix {
+		hint = " (they will be raised until the cluster is deleted)"
+	}
+	for _, s := range low {
+		warnings = append(warnings, fmt.Sprintf("%s is %d, nodes may fail with too many open files%s", s.Name, s.Value, hint))
+	}
+	return warnings, nil
+}
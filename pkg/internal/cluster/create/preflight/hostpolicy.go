@@ -0,0 +1,211 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preflight
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	yaml "gopkg.in/yaml.v3"
+
+	"sigs.k8s.io/kind/pkg/cluster/constants"
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/cluster/providers/provider"
+	"sigs.k8s.io/kind/pkg/internal/util/units"
+)
+
+// DefaultHostPolicyPath is where the host policy is read from by default
+const DefaultHostPolicyPath = "/etc/kind/policy.yaml"
+
+// HostPolicyPathEnv overrides DefaultHostPolicyPath if set
+const HostPolicyPathEnv = "KIND_HOST_POLICY"
+
+// DefaultHostPolicyNodeMemory is how much memory a new node is expected to
+// use when enforcing maxMemory, if the policy does not set nodeMemory
+const DefaultHostPolicyNodeMemory = "1Gi"
+
+// HostPolicyPath returns the path to the host policy file
+func HostPolicyPath() string {
+	if path := os.Getenv(HostPolicyPathEnv); path != "" {
+		return path
+	}
+	return DefaultHostPolicyPath
+}
+
+// hostPolicy caps the resources used by all kind clusters on the host,
+// zero values are not enforced
+type hostPolicy struct {
+	// MaxClusters is the maximum number of clusters
+	MaxClusters int `yaml:"maxClusters"`
+	// MaxNodes is the maximum number of node containers across all clusters,
+	// including external load balancers
+	MaxNodes int `yaml:"maxNodes"`
+	// MaxMemory is the maximum memory used by all nodes, EG 16Gi
+	MaxMemory string `yaml:"maxMemory"`
+	// NodeMemory is the memory each new node is expected to use,
+	// see DefaultHostPolicyNodeMemory
+	NodeMemory string `yaml:"nodeMemory"`
+}
+
+// hostUsage is the resources that would be used on the host once the new
+// cluster is created
+type hostUsage struct {
+	clusters int
+	nodes    int
+	memory   uint64
+}
+
+type hostPolicyCheck struct {
+	path     string
+	provider provider.Provider
+}
+
+// HostPolicy returns a Check that creating the cluster will not exceed
+// the limits set by the host policy file at path, if it exists
+func HostPolicy(path string, p provider.Provider) Check {
+	return &hostPolicyCheck{
+		path:     path,
+		provider: p,
+	}
+}
+
+// Name is part of the Check interface
+func (h *hostPolicyCheck) Name() string {
+	return "host-policy"
+}
+
+// Run is part of the Check interface
+func (h *hostPolicyCheck) Run(cfg *config.Cluster) (warnings []string, err error) {
+	policy, err := readHostPolicy(h.path)
+	if err != nil || policy == nil {
+		return nil, err
+	}
+
+	clusters, err := h.provider.ListClusters()
+	if err != nil {
+		return nil, err
+	}
+	existing := []nodes.Node{}
+	for _, cluster := range clusters {
+		n, err := h.provider.ListNodes(cluster)
+		if err != nil {
+			return nil, err
+		}
+		existing = append(existing, n...)
+	}
+	newNodes := newNodeCount(cfg)
+	usage := hostUsage{
+		clusters: len(clusters) + 1,
+		nodes:    len(existing) + newNodes,
+	}
+
+	if policy.MaxMemory != "" {
+		nodeMemory := DefaultHostPolicyNodeMemory
+		if policy.NodeMemory != "" {
+			nodeMemory = policy.NodeMemory
+		}
+		perNode, err := units.ParseBytes(nodeMemory)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid nodeMemory in %s", h.path)
+		}
+		used, err := h.provider.GetMemoryUsage(existing)
+		if err != nil {
+			return nil, err
+		}
+		usage.memory = used + uint64(newNodes)*perNode
+	}
+
+	if err := policy.enforce(usage); err != nil {
+		return nil, errors.Wrapf(err, "host policy %s", h.path)
+	}
+	return nil, nil
+}
+
+// enforce returns an error for each limit in the policy usage exceeds
+func (p *hostPolicy) enforce(usage hostUsage) error {
+	errs := []error{}
+	if p.MaxClusters > 0 && usage.clusters > p.MaxClusters {
+		errs = append(errs, errors.Errorf(
+			"creating this cluster would exceed maxClusters: %d clusters, limit is %d",
+			usage.clusters, p.MaxClusters,
+		))
+	}
+	if p.MaxNodes > 0 && usage.nodes > p.MaxNodes {
+		errs = append(errs, errors.Errorf(
+			"creating this cluster would exceed maxNodes: %d nodes, limit is %d",
+			usage.nodes, p.MaxNodes,
+		))
+	}
+	if p.MaxMemory != "" {
+		maxMemory, err := units.ParseBytes(p.MaxMemory)
+		if err != nil {
+			errs = append(errs, errors.Wrap(err, "invalid maxMemory"))
+		} else if usage.memory > maxMemory {
+			errs = append(errs, errors.Errorf(
+				"creating this cluster would exceed maxMemory: an estimated %s used, limit is %s",
+				formatGi(usage.memory), p.MaxMemory,
+			))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.NewAggregate(errs)
+	}
+	return nil
+}
+
+// readHostPolicy reads the policy at path, or returns nil if there is none
+func readHostPolicy(path string) (*hostPolicy, error) {
+	raw, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to read host policy")
+	}
+	policy := &hostPolicy{}
+	decoder := yaml.NewDecoder(bytes.NewReader(raw))
+	decoder.KnownFields(true)
+	// an empty policy file enforces nothing
+	if err := decoder.Decode(policy); err != nil && err != io.EOF {
+		return nil, errors.Wrapf(err, "failed to parse host policy %s", path)
+	}
+	return policy, nil
+}
+
+// newNodeCount returns the number of node containers cfg will create
+func newNodeCount(cfg *config.Cluster) int {
+	controlPlanes := 0
+	for _, n := range cfg.Nodes {
+		if string(n.Role) == constants.ControlPlaneNodeRoleValue {
+			controlPlanes++
+		}
+	}
+	// multiple control planes implicitly create a load balancer
+	if controlPlanes > 1 {
+		return len(cfg.Nodes) + 1
+	}
+	return len(cfg.Nodes)
+}
+
+func formatGi(bytes uint64) string {
+	return fmt.Sprintf("%.1fGi", float64(bytes)/(1<<30))
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preflight
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+func TestHostPolicyEnforce(t *testing.T) {
+	cases := []struct {
+		Name         string
+		Policy       hostPolicy
+		Usage        hostUsage
+		ExpectErrors int
+	}{
+		{
+			Name:   "empty policy",
+			Policy: hostPolicy{},
+			Usage:  hostUsage{clusters: 10, nodes: 100, memory: 1 << 40},
+		},
+		{
+			Name:   "within limits",
+			Policy: hostPolicy{MaxClusters: 2, MaxNodes: 4, MaxMemory: "8Gi"},
+			Usage:  hostUsage{clusters: 2, nodes: 4, memory: 8 << 30},
+		},
+		{
+			Name:         "too many clusters and nodes",
+			Policy:       hostPolicy{MaxClusters: 1, MaxNodes: 3},
+			Usage:        hostUsage{clusters: 2, nodes: 4},
+			ExpectErrors: 2,
+		},
+		{
+			Name:         "too much memory",
+			Policy:       hostPolicy{MaxMemory: "4Gi"},
+			Usage:        hostUsage{clusters: 1, nodes: 5, memory: 5 << 30},
+			ExpectErrors: 1,
+		},
+		{
+			Name:         "bogus maxMemory",
+			Policy:       hostPolicy{MaxMemory: "lots"},
+			Usage:        hostUsage{clusters: 1, nodes: 1},
+			ExpectErrors: 1,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			err := tc.Policy.enforce(tc.Usage)
+			if err == nil {
+				if tc.ExpectErrors != 0 {
+					t.Fatalf("expected %d errors but got nil", tc.ExpectErrors)
+				}
+				return
+			}
+			errs := errors.Errors(err)
+			if errs == nil {
+				errs = []error{err}
+			}
+			if len(errs) != tc.ExpectErrors {
+				t.Fatalf("expected %d errors but got %d: %v", tc.ExpectErrors, len(errs), errs)
+			}
+		})
+	}
+}
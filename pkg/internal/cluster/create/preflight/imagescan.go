@@ -0,0 +1,148 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preflight
+
+import (
+	"fmt"
+	"os"
+	osexec "os/exec"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/globals"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/cluster/providers/provider/common"
+)
+
+// DefaultImageScanner is the scanner used if none is specified
+const DefaultImageScanner = "trivy"
+
+// DefaultImageScanSeverity is the default minimum severity failing a scan
+const DefaultImageScanSeverity = "HIGH"
+
+// ImageScanSeverityEnv is set in the environment of non-trivy scanners
+// to the comma separated list of severities that should fail the scan
+const ImageScanSeverityEnv = "KIND_SCAN_SEVERITY"
+
+// imageScanSeverities are the known vulnerability severities in
+// increasing order
+var imageScanSeverities = []string{"UNKNOWN", "LOW", "MEDIUM", "HIGH", "CRITICAL"}
+
+// SeveritiesAtOrAbove returns the list of known severities at or above
+// threshold, or an error if threshold is not a known severity
+func SeveritiesAtOrAbove(threshold string) ([]string, error) {
+	threshold = strings.ToUpper(threshold)
+	for i, severity := range imageScanSeverities {
+		if severity == threshold {
+			return imageScanSeverities[i:], nil
+		}
+	}
+	return nil, errors.Errorf(
+		"unknown severity %q, must be one of %s",
+		threshold, strings.Join(imageScanSeverities, ", "),
+	)
+}
+
+type imageScan struct {
+	scanner  string
+	severity string
+	warnOnly bool
+}
+
+// ImageScan returns a Check that scans every node image with scanner,
+// failing if any vulnerability at or above severity is found.
+//
+// If scanner is trivy it will be invoked as `trivy image ...`, any other
+// scanner is invoked as `scanner <image>` with ImageScanSeverityEnv set,
+// and should exit non-zero if the image fails the scan.
+//
+// If warnOnly is set, failing scans are only reported as warnings
+func ImageScan(scanner, severity string, warnOnly bool) Check {
+	if scanner == "" {
+		scanner = DefaultImageScanner
+	}
+	if severity == "" {
+		severity = DefaultImageScanSeverity
+	}
+	return &imageScan{
+		scanner:  scanner,
+		severity: severity,
+		warnOnly: warnOnly,
+	}
+}
+
+// Name is part of the Check interface
+func (i *imageScan) Name() string {
+	return "image-scan"
+}
+
+// Run is part of the Check interface
+func (i *imageScan) Run(cfg *config.Cluster) (warnings []string, err error) {
+	severities, err := SeveritiesAtOrAbove(i.severity)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := osexec.LookPath(i.scanner); err != nil {
+		return nil, errors.Errorf("image scanner %q not found", i.scanner)
+	}
+
+	errs := []error{}
+	for _, image := range common.RequiredNodeImages(cfg).List() {
+		lines, err := exec.CombinedOutputLines(i.scanCommand(image, severities))
+		globals.GetLogger().V(1).Info(strings.Join(lines, "\n"))
+		if err == nil {
+			continue
+		}
+		if i.warnOnly {
+			warnings = append(warnings, fmt.Sprintf(
+				"image %q failed vulnerability scan at severity >= %s",
+				image, severities[0],
+			))
+			continue
+		}
+		errs = append(errs, errors.Wrapf(
+			err, "image %q failed vulnerability scan at severity >= %s",
+			image, severities[0],
+		))
+	}
+	if len(errs) > 0 {
+		return warnings, errors.NewAggregate(errs)
+	}
+	return warnings, nil
+}
+
+func (i *imageScan) scanCommand(image string, severities []string) exec.Cmd {
+	if filepath.Base(i.scanner) == "trivy" {
+		return exec.Command(
+			i.scanner, "image",
+			"--quiet", "--no-progress",
+			// only fail for the selected severities
+			"--exit-code", "1",
+			"--severity", strings.Join(severities, ","),
+			image,
+		)
+	}
+	cmd := exec.Command(i.scanner, image)
+	cmd.SetEnv(append(
+		os.Environ(),
+		fmt.Sprintf("%s=%s", ImageScanSeverityEnv, strings.Join(severities, ",")),
+	)...)
+	return cmd
+}
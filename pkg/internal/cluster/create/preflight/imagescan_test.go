@@ -0,0 +1,65 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preflight
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSeveritiesAtOrAbove(t *testing.T) {
+	cases := []struct {
+		Name      string
+		Threshold string
+		Expected  []string
+		ExpectErr bool
+	}{
+		{
+			Name:      "lowest severity",
+			Threshold: "UNKNOWN",
+			Expected:  []string{"UNKNOWN", "LOW", "MEDIUM", "HIGH", "CRITICAL"},
+		},
+		{
+			Name:      "default severity",
+			Threshold: DefaultImageScanSeverity,
+			Expected:  []string{"HIGH", "CRITICAL"},
+		},
+		{
+			Name:      "lower case",
+			Threshold: "critical",
+			Expected:  []string{"CRITICAL"},
+		},
+		{
+			Name:      "bogus severity",
+			Threshold: "bogus",
+			ExpectErr: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			result, err := SeveritiesAtOrAbove(tc.Threshold)
+			if (err != nil) != tc.ExpectErr {
+				t.Fatalf("SeveritiesAtOrAbove() error = %v, ExpectErr %v", err, tc.ExpectErr)
+			}
+			if !reflect.DeepEqual(result, tc.Expected) {
+				t.Errorf("SeveritiesAtOrAbove() = %v, expected %v", result, tc.Expected)
+			}
+		})
+	}
+}
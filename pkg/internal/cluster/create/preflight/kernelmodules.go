@@ -0,0 +1,137 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preflight
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+type kernelModules struct {
+	load bool
+}
+
+// KernelModules returns a Check that the kernel modules required by the
+// cluster config are loaded (or built in) on the host.
+//
+// If load is set, missing modules are loaded with modprobe, which
+// typically requires root.
+func KernelModules(load bool) Check {
+	return &kernelModules{
+		load: load,
+	}
+}
+
+// Name is part of the Check interface
+func (k *kernelModules) Name() string {
+	return "kernel-modules"
+}
+
+// Run is part of the Check interface
+func (k *kernelModules) Run(cfg *config.Cluster) (warnings []string, err error) {
+	if len(cfg.KernelModules) == 0 {
+		return nil, nil
+	}
+
+	// on other platforms docker runs in a VM we can't inspect
+	if runtime.GOOS != "linux" {
+		return []string{fmt.Sprintf(
+			"unable to check kernel modules on %s, ensure these are loaded in the docker VM: %s",
+			runtime.GOOS, strings.Join(cfg.KernelModules, ", "),
+		)}, nil
+	}
+
+	available, err := availableKernelModules()
+	if err != nil {
+		return []string{fmt.Sprintf("unable to determine loaded kernel modules: %v", err)}, nil
+	}
+
+	missing := []string{}
+	for _, module := range cfg.KernelModules {
+		// the kernel treats - and _ in module names interchangeably
+		if !available.Has(strings.Replace(module, "-", "_", -1)) {
+			missing = append(missing, module)
+		}
+	}
+	if len(missing) == 0 {
+		return nil, nil
+	}
+
+	modprobe := "modprobe -a " + strings.Join(missing, " ")
+	if !k.load {
+		return nil, errors.Errorf(
+			"required kernel modules are not loaded: %s, load them with `sudo %s` or use --load-kernel-modules",
+			strings.Join(missing, ", "), modprobe,
+		)
+	}
+	lines, err := exec.CombinedOutputLines(exec.Command("modprobe", append([]string{"-a"}, missing...)...))
+	if err != nil {
+		return nil, errors.Wrapf(err,
+			"failed to load kernel modules %s, try running `sudo %s`: %s",
+			strings.Join(missing, ", "), modprobe, strings.Join(lines, "\n"),
+		)
+	}
+	return nil, nil
+}
+
+// availableKernelModules returns the names of all loaded and built in
+// kernel modules on the host
+func availableKernelModules() (sets.String, error) {
+	modules := sets.NewString()
+
+	// loaded modules are listed one per line, name first
+	loaded, err := ioutil.ReadFile("/proc/modules")
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range strings.Split(string(loaded), "\n") {
+		if fields := strings.Fields(line); len(fields) > 0 {
+			modules.Insert(fields[0])
+		}
+	}
+
+	// built in modules are listed by path, EG kernel/net/bridge/br_netfilter.ko
+	release, err := ioutil.ReadFile("/proc/sys/kernel/osrelease")
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(filepath.Join("/lib/modules", strings.TrimSpace(string(release)), "modules.builtin"))
+	if os.IsNotExist(err) {
+		return modules, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		name := strings.TrimSuffix(filepath.Base(scanner.Text()), ".ko")
+		modules.Insert(strings.Replace(name, "-", "_", -1))
+	}
+	return modules, scanner.Err()
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package preflight implements checks run before any cluster nodes are created
+package preflight
+
+import (
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/globals"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/util/cli"
+)
+
+// Check is a single preflight check, run against the cluster config
+// prior to provisioning any nodes
+type Check interface {
+	// Name returns a short human readable name for the check
+	Name() string
+	// Run executes the check, problems that should not fail cluster creation
+	// are returned as warnings, anything else as an error
+	Run(cfg *config.Cluster) (warnings []string, err error)
+}
+
+// Run runs all checks, logging any warnings and returning an aggregate
+// of all check failures
+func Run(status *cli.Status, cfg *config.Cluster, checks ...Check) (err error) {
+	status.Start("Running preflight checks 🔍")
+	defer func() { status.End(err == nil) }()
+
+	errs := []error{}
+	for _, check := range checks {
+		warnings, err := check.Run(cfg)
+		for _, warning := range warnings {
+			globals.GetLogger().Warnf("WARNING: preflight check %s: %s", check.Name(), warning)
+		}
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "preflight check %s failed", check.Name()))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.NewAggregate(errs)
+	}
+	return nil
+}
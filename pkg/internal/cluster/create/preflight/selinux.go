@@ -0,0 +1,162 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preflight
+
+import (
+	"fmt"
+	"io/ioutil"
+	"runtime"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+// selinuxEnforcePath reports whether SELinux is enforcing on the host
+const selinuxEnforcePath = "/sys/fs/selinux/enforce"
+
+// unconfinedTypes are the SELinux process types allowed to do everything
+// a node does, such as mounting cgroups and running containers
+var unconfinedTypes = []string{"spc_t", "unconfined_t"}
+
+// containerFileTypes are the SELinux file types confined containers may use
+var containerFileTypes = []string{"container_file_t", "container_ro_file_t", "svirt_sandbox_file_t"}
+
+type selinuxCheck struct{}
+
+// SELinux returns a Check that SELinux separation on the host will not
+// break the cluster: node containers confined by selinuxLabel fail to run
+// the kubelet and its containers, and mounts into confined containers
+// are denied unless their host paths are labeled for containers.
+func SELinux() Check {
+	return &selinuxCheck{}
+}
+
+// Name is part of the Check interface
+func (s *selinuxCheck) Name() string {
+	return "selinux"
+}
+
+// Run is part of the Check interface
+func (s *selinuxCheck) Run(cfg *config.Cluster) (warnings []string, err error) {
+	// on other platforms docker runs in a VM without SELinux
+	if runtime.GOOS != "linux" {
+		return nil, nil
+	}
+	if !selinuxEnforcing() {
+		return nil, nil
+	}
+	if !dockerSELinuxEnabled() {
+		if cfg.SELinuxLabel != "" {
+			warnings = append(warnings, fmt.Sprintf(
+				"selinuxLabel %q has no effect, SELinux support is not enabled in dockerd (selinux-enabled)",
+				cfg.SELinuxLabel,
+			))
+		}
+		return warnings, nil
+	}
+
+	if confinedLabel(cfg.SELinuxLabel, true) {
+		return nil, errors.Errorf(
+			"selinuxLabel %q confines the node containers, which SELinux will deny mounting cgroups and running containers, "+
+				"failing the kubelet with permission denied errors; use type:spc_t or disable instead",
+			cfg.SELinuxLabel,
+		)
+	}
+
+	// node containers are unconfined, only auxiliary containers may be denied
+	// access to their mounts
+	for _, aux := range cfg.AuxiliaryContainers {
+		if !confinedLabel("", aux.Privileged) {
+			continue
+		}
+		for _, m := range aux.ExtraMounts {
+			if m.SelinuxRelabel {
+				continue
+			}
+			fileType, err := fileLabelType(m.HostPath)
+			if err != nil || hasString(containerFileTypes, fileType) {
+				continue
+			}
+			warnings = append(warnings, fmt.Sprintf(
+				"auxiliary container %q will be denied access to %s labeled %s, set selinuxRelabel on the mount",
+				aux.Name, m.HostPath, fileType,
+			))
+		}
+	}
+	return warnings, nil
+}
+
+// confinedLabel returns true if a container with the label security option
+// (see config.Cluster.SELinuxLabel) runs confined by SELinux
+func confinedLabel(label string, privileged bool) bool {
+	switch {
+	case label == "":
+		// docker does not confine privileged containers by default
+		return !privileged
+	case label == "disable":
+		return false
+	case strings.HasPrefix(label, "type:"):
+		return !hasString(unconfinedTypes, strings.TrimPrefix(label, "type:"))
+	}
+	// user, role and level options keep the default type
+	return !privileged
+}
+
+// selinuxEnforcing returns true if SELinux is enforcing on the host
+func selinuxEnforcing() bool {
+	raw, err := ioutil.ReadFile(selinuxEnforcePath)
+	return err == nil && strings.TrimSpace(string(raw)) == "1"
+}
+
+// dockerSELinuxEnabled returns true if dockerd labels containers
+func dockerSELinuxEnabled() bool {
+	cmd := exec.Command("docker", "info", "--format", "{{json .SecurityOptions}}")
+	lines, err := exec.OutputLines(cmd)
+	if err != nil || len(lines) == 0 {
+		return false
+	}
+	return strings.Contains(lines[0], "name=selinux")
+}
+
+// fileLabelType returns the SELinux type of the file at path on the host
+func fileLabelType(path string) (string, error) {
+	lines, err := exec.OutputLines(exec.Command("stat", "-L", "-c", "%C", path))
+	if err != nil {
+		return "", err
+	}
+	if len(lines) != 1 {
+		return "", errors.Errorf("unexpected stat output for %s", path)
+	}
+	// user:role:type:level
+	parts := strings.SplitN(lines[0], ":", 4)
+	if len(parts) < 3 {
+		return "", errors.Errorf("invalid SELinux label %q for %s", lines[0], path)
+	}
+	return parts[2], nil
+}
+
+func hasString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,48 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preflight
+
+import (
+	"testing"
+)
+
+func TestConfinedLabel(t *testing.T) {
+	cases := []struct {
+		Name       string
+		Label      string
+		Privileged bool
+		Expected   bool
+	}{
+		{Name: "privileged default", Privileged: true, Expected: false},
+		{Name: "unprivileged default", Expected: true},
+		{Name: "disable", Label: "disable", Expected: false},
+		{Name: "spc_t", Label: "type:spc_t", Expected: false},
+		{Name: "unconfined_t", Label: "type:unconfined_t", Privileged: true, Expected: false},
+		{Name: "container_t", Label: "type:container_t", Privileged: true, Expected: true},
+		{Name: "privileged level", Label: "level:s0:c1,c2", Privileged: true, Expected: false},
+		{Name: "unprivileged level", Label: "level:s0:c1,c2", Expected: true},
+	}
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			if actual := confinedLabel(tc.Label, tc.Privileged); actual != tc.Expected {
+				t.Errorf("expected confinedLabel(%q, %v) to be %v", tc.Label, tc.Privileged, tc.Expected)
+			}
+		})
+	}
+}
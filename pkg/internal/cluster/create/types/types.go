@@ -19,9 +19,11 @@ limitations under the License.
 package types
 
 import (
+	"io"
 	"time"
 
 	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/cluster/create/actions"
 )
 
 // ClusterOptions holds cluster creation options
@@ -34,7 +36,45 @@ type ClusterOptions struct {
 	NodeImage    string
 	Retain       bool
 	WaitForReady time.Duration
+	// WaitMinNodes is the number of nodes that must be Ready in addition
+	// to the control plane when waiting for the cluster to be ready
+	WaitMinNodes int
 	//TODO: Refactor this. It is a temporary solution for a phased breakdown of different
 	//      operations, specifically create. see https://github.com/kubernetes-sigs/kind/issues/324
 	SetupKubernetes bool // if kind should setup kubernetes after creating nodes
+	// ImageScan enables scanning the node images prior to creating nodes
+	// if non-nil
+	ImageScan *ImageScanOptions
+	// LoadKernelModules allows loading missing kernel modules required by
+	// Config on the host
+	LoadKernelModules bool
+	// FixFirewall adds host firewall rules allowing traffic on the
+	// cluster network, they are removed when the cluster is deleted
+	FixFirewall bool
+	// FixHostLimits raises host inotify and open file limits that are too
+	// low for the nodes, they are restored when the cluster is deleted
+	FixHostLimits bool
+	// Protect marks the cluster as protected from deletion unless forced
+	Protect bool
+	// Failures are create phases to fail on purpose, by phase name
+	Failures actions.Failures
+	// PhaseTimeouts bound how long waiting within create phases may take,
+	// by phase name
+	PhaseTimeouts map[string]time.Duration
+	// PhaseObserver is called with the name of each phase of create once it
+	// ends, if set
+	PhaseObserver func(phase string, elapsed time.Duration, success bool)
+	// NodeConsole receives the output of the node containers while creating
+	// them, each line prefixed with the node name, if set
+	NodeConsole io.Writer
+}
+
+// ImageScanOptions holds node image vulnerability scan options
+type ImageScanOptions struct {
+	// Scanner is the scanner executable, defaults to trivy
+	Scanner string
+	// Severity is the minimum vulnerability severity failing the scan
+	Severity string
+	// WarnOnly reports failing scans as warnings instead of failing create
+	WarnOnly bool
 }
@@ -18,6 +18,7 @@ package delete
 
 import (
 	"fmt"
+	"io/ioutil"
 	"os"
 	"strings"
 
@@ -25,6 +26,11 @@ import (
 	"sigs.k8s.io/kind/pkg/globals"
 
 	"sigs.k8s.io/kind/pkg/internal/cluster/context"
+	"sigs.k8s.io/kind/pkg/internal/cluster/lifecycle"
+	"sigs.k8s.io/kind/pkg/internal/util/firewall"
+	"sigs.k8s.io/kind/pkg/internal/util/hostlimits"
+	"sigs.k8s.io/kind/pkg/internal/util/hostsfile"
+	"sigs.k8s.io/kind/pkg/internal/util/systemd"
 )
 
 // Cluster deletes the cluster identified by ctx
@@ -34,16 +40,84 @@ func Cluster(c *context.Context) error {
 		return errors.Wrap(err, "error listing nodes")
 	}
 
+	// only track deletion of clusters that exist
+	if len(n) > 0 {
+		c.SetPhase(lifecycle.Deleting, nil)
+	}
+
 	// try to remove the kind kube config file generated by "kind create cluster"
 	err = os.Remove(c.KubeConfigPath())
 	if err != nil && !os.IsNotExist(err) {
 		globals.GetLogger().Warnf("Tried to remove %s but received error: %s\n", c.KubeConfigPath(), err)
 	}
 
+	// remove any hosts file entries added for apiServerHostname
+	if err := hostsfile.Remove(hostsfile.Path(), c.Name()); err != nil {
+		globals.GetLogger().Warnf("Tried to remove hosts file entries for the cluster but received error: %s\n", err)
+	}
+
+	// remove any host firewall rules added for the cluster network
+	if iface, err := ioutil.ReadFile(c.Path(context.FirewallFile)); err == nil {
+		if err := firewall.Remove(string(iface)); err != nil {
+			globals.GetLogger().Warnf("Tried to remove firewall rules for %s but received error: %s\n", iface, err)
+		}
+	}
+
 	// check if $KUBECONFIG is set and let the user know to unset if so
 	if strings.Contains(os.Getenv("KUBECONFIG"), c.KubeConfigPath()) {
 		fmt.Printf("$KUBECONFIG is still set to use %s even though that file has been deleted, remember to unset it\n", c.KubeConfigPath())
 	}
 
-	return c.Provider().DeleteNodes(n)
+	if err := c.Provider().DeleteNodes(n); err != nil {
+		c.SetPhase(lifecycle.Deleting, err)
+		return err
+	}
+	if err := c.Provider().DeleteNetwork(c.Name()); err != nil {
+		c.SetPhase(lifecycle.Deleting, err)
+		return err
+	}
+
+	// restore any host limits raised for the cluster, now that its nodes
+	// no longer need them
+	if err := restoreHostLimits(c); err != nil {
+		globals.GetLogger().Warnf("Tried to restore host limits but received error: %s\n", err)
+	}
+
+	// stop the systemd slice the nodes ran in, now that it is empty
+	if name, err := ioutil.ReadFile(c.Path(context.SystemdSliceFile)); err == nil {
+		if err := systemd.StopSlice(string(name)); err != nil {
+			globals.GetLogger().Warnf("Tried to stop systemd slice %s but received error: %s\n", name, err)
+		}
+	}
+
+	// finally remove the cluster's state, including the status file
+	if err := os.RemoveAll(c.Dir()); err != nil {
+		globals.GetLogger().Warnf("Tried to remove %s but received error: %s\n", c.Dir(), err)
+	}
+	return nil
+}
+
+// restoreHostLimits restores the host limits raised when creating the
+// cluster, unless other kind clusters remain that may depend on them, as
+// the limits are host wide
+func restoreHostLimits(c *context.Context) error {
+	raw, err := ioutil.ReadFile(c.Path(context.HostLimitsFile))
+	if err != nil {
+		return nil
+	}
+	settings, err := hostlimits.Parse(string(raw))
+	if err != nil {
+		return err
+	}
+	clusters, err := c.Provider().ListClusters()
+	if err != nil {
+		return errors.Wrap(err, "failed to list remaining clusters")
+	}
+	for _, name := range clusters {
+		if name != c.Name() {
+			globals.GetLogger().V(1).Infof("Leaving host limits raised for remaining cluster %q", name)
+			return nil
+		}
+	}
+	return hostlimits.Restore(settings)
 }
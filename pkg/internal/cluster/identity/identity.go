@@ -0,0 +1,123 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package identity resolves the user supplied kubeadm token, certificate key
+// and cluster CA from their configured sources
+package identity
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"regexp"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+var (
+	tokenRE          = regexp.MustCompile(`^[a-z0-9]{6}\.[a-z0-9]{16}$`)
+	certificateKeyRE = regexp.MustCompile(`^[a-fA-F0-9]{64}$`)
+)
+
+// Identity is a resolved config.ClusterIdentity, unset values are empty
+type Identity struct {
+	Token          string
+	CertificateKey string
+	CACert         []byte
+	CAKey          []byte
+}
+
+// Resolve reads every value of the cluster identity, looking up environment
+// variables with lookupEnv (normally os.LookupEnv)
+func Resolve(in *config.ClusterIdentity, lookupEnv func(string) (string, bool)) (*Identity, error) {
+	out := &Identity{}
+	if in == nil {
+		return out, nil
+	}
+
+	token, err := read(in.Token, lookupEnv)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read token")
+	}
+	out.Token = strings.TrimSpace(string(token))
+	if out.Token != "" && !tokenRE.MatchString(out.Token) {
+		return nil, errors.Errorf("invalid token, must match %s", tokenRE)
+	}
+
+	key, err := read(in.CertificateKey, lookupEnv)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read certificateKey")
+	}
+	out.CertificateKey = strings.TrimSpace(string(key))
+	if out.CertificateKey != "" && !certificateKeyRE.MatchString(out.CertificateKey) {
+		return nil, errors.New("invalid certificateKey, must be 64 hex characters")
+	}
+
+	if out.CACert, err = read(in.CACert, lookupEnv); err != nil {
+		return nil, errors.Wrap(err, "failed to read caCert")
+	}
+	if out.CAKey, err = read(in.CAKey, lookupEnv); err != nil {
+		return nil, errors.Wrap(err, "failed to read caKey")
+	}
+	if out.CACert != nil {
+		if err := validateCA(out.CACert, out.CAKey); err != nil {
+			return nil, err
+		}
+	}
+
+	return out, nil
+}
+
+// read returns the value of source, or nil if source is unset
+func read(source *config.ValueSource, lookupEnv func(string) (string, bool)) ([]byte, error) {
+	if source == nil {
+		return nil, nil
+	}
+	if source.Env != "" {
+		value, ok := lookupEnv(source.Env)
+		if !ok || value == "" {
+			return nil, errors.Errorf("environment variable %s is not set", source.Env)
+		}
+		return []byte(value), nil
+	}
+	value, err := ioutil.ReadFile(source.File)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return value, nil
+}
+
+// validateCA checks that the PEM encoded certificate is a CA and the key is
+// present, so kubeadm does not fail much later on a bad input
+func validateCA(cert, key []byte) error {
+	block, _ := pem.Decode(cert)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return errors.New("invalid caCert, expected a PEM encoded CERTIFICATE")
+	}
+	parsed, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return errors.Wrap(err, "invalid caCert")
+	}
+	if !parsed.IsCA {
+		return errors.New("invalid caCert, certificate is not a CA")
+	}
+	if keyBlock, _ := pem.Decode(key); keyBlock == nil {
+		return errors.New("invalid caKey, expected a PEM encoded private key")
+	}
+	return nil
+}
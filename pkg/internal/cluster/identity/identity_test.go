@@ -0,0 +1,115 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package identity
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+func TestResolve(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "kind-identity")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	tokenFile := filepath.Join(dir, "token")
+	if err := ioutil.WriteFile(tokenFile, []byte("abcdef.0123456789abcdef\n"), 0600); err != nil {
+		t.Fatalf("failed to write token: %v", err)
+	}
+	notCA := filepath.Join(dir, "ca.crt")
+	if err := ioutil.WriteFile(notCA, []byte("not a certificate"), 0600); err != nil {
+		t.Fatalf("failed to write ca.crt: %v", err)
+	}
+
+	env := map[string]string{
+		"KIND_CERT_KEY": strings.Repeat("ab", 32),
+		"KIND_TOKEN":    "bogus",
+	}
+	lookupEnv := func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	}
+
+	cases := []struct {
+		Name        string
+		Identity    *config.ClusterIdentity
+		Expected    Identity
+		ExpectError bool
+	}{
+		{
+			Name: "unset",
+		},
+		{
+			Name: "token from file, certificateKey from env",
+			Identity: &config.ClusterIdentity{
+				Token:          &config.ValueSource{File: tokenFile},
+				CertificateKey: &config.ValueSource{Env: "KIND_CERT_KEY"},
+			},
+			Expected: Identity{
+				Token:          "abcdef.0123456789abcdef",
+				CertificateKey: strings.Repeat("ab", 32),
+			},
+		},
+		{
+			Name: "malformed token",
+			Identity: &config.ClusterIdentity{
+				Token: &config.ValueSource{Env: "KIND_TOKEN"},
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "missing env",
+			Identity: &config.ClusterIdentity{
+				CertificateKey: &config.ValueSource{Env: "KIND_MISSING"},
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "invalid CA",
+			Identity: &config.ClusterIdentity{
+				CACert: &config.ValueSource{File: notCA},
+				CAKey:  &config.ValueSource{File: notCA},
+			},
+			ExpectError: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture tc
+		t.Run(tc.Name, func(t *testing.T) {
+			out, err := Resolve(tc.Identity, lookupEnv)
+			if tc.ExpectError {
+				if err == nil {
+					t.Fatalf("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if out.Token != tc.Expected.Token || out.CertificateKey != tc.Expected.CertificateKey {
+				t.Errorf("expected %+v but got %+v", tc.Expected, *out)
+			}
+		})
+	}
+}
@@ -0,0 +1,259 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package konnectivity
+
+import (
+	"bytes"
+	"text/template"
+
+	"k8s.io/apimachinery/pkg/util/version"
+
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+// Dir is the directory on control plane nodes holding the egress selector
+// config and the konnectivity server socket, it is mounted into the
+// API server
+const Dir = "/etc/kubernetes/konnectivity"
+
+// EgressSelectorConfigPath is the API server's egress selector config
+const EgressSelectorConfigPath = Dir + "/egress-selector-configuration.yaml"
+
+// ServerManifestPath is the konnectivity server static pod manifest
+const ServerManifestPath = "/etc/kubernetes/manifests/konnectivity-server.yaml"
+
+// AgentPort is the port the konnectivity server listens on for agents
+const AgentPort = 8132
+
+// minimumVersion is the first version with the egress selector in beta
+var minimumVersion = version.MustParseSemantic("v1.18.0")
+
+// egressSelectorV1beta1Version is the first version serving the v1beta1
+// EgressSelectorConfiguration
+var egressSelectorV1beta1Version = version.MustParseSemantic("v1.20.0")
+
+// templateData is supplied to the templates
+type templateData struct {
+	*config.Konnectivity
+	// APIVersion of the EgressSelectorConfiguration
+	APIVersion string
+	// ProxyProtocol of the EgressSelectorConfiguration for Mode
+	ProxyProtocol string
+	// SocketPath is the server's unix socket for the API server
+	SocketPath string
+	// ServerCount is the number of servers agents connect to
+	ServerCount int
+	// ServerHost is the address agents reach servers on
+	ServerHost string
+	AgentPort  int
+}
+
+const egressSelectorTemplate = `apiVersion: {{ .APIVersion }}
+kind: EgressSelectorConfiguration
+egressSelections:
+- name: cluster
+  connection:
+    proxyProtocol: {{ .ProxyProtocol }}
+    transport:
+      uds:
+        udsName: {{ .SocketPath }}
+`
+
+const serverTemplate = `apiVersion: v1
+kind: Pod
+metadata:
+  name: konnectivity-server
+  namespace: kube-system
+spec:
+  priorityClassName: system-cluster-critical
+  hostNetwork: true
+  containers:
+  - name: konnectivity-server
+    image: k8s.gcr.io/kas-network-proxy/proxy-server:{{ .Version }}
+    command: ["/proxy-server"]
+    args:
+    - --logtostderr=true
+    - --uds-name={{ .SocketPath }}
+    - --delete-existing-uds-file
+    - --cluster-cert=/etc/kubernetes/pki/apiserver.crt
+    - --cluster-key=/etc/kubernetes/pki/apiserver.key
+    - --mode={{ .Mode }}
+    - --server-port=0
+    - --agent-port={{ .AgentPort }}
+    - --admin-port=8133
+    - --health-port=8134
+    - --server-count={{ .ServerCount }}
+    - --agent-namespace=kube-system
+    - --agent-service-account=konnectivity-agent
+    - --authentication-audience=system:konnectivity-server
+    - --kubeconfig=/etc/kubernetes/admin.conf
+    livenessProbe:
+      httpGet:
+        scheme: HTTP
+        host: 127.0.0.1
+        port: 8134
+        path: /healthz
+      initialDelaySeconds: 30
+      timeoutSeconds: 60
+    volumeMounts:
+    - name: pki
+      mountPath: /etc/kubernetes/pki
+      readOnly: true
+    - name: kubeconfig
+      mountPath: /etc/kubernetes/admin.conf
+      readOnly: true
+    - name: konnectivity
+      mountPath: ` + Dir + `
+  volumes:
+  - name: pki
+    hostPath:
+      path: /etc/kubernetes/pki
+  - name: kubeconfig
+    hostPath:
+      path: /etc/kubernetes/admin.conf
+      type: FileOrCreate
+  - name: konnectivity
+    hostPath:
+      path: ` + Dir + `
+      type: DirectoryOrCreate
+`
+
+const agentTemplate = `apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: konnectivity-agent
+  namespace: kube-system
+---
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: konnectivity-agent
+  namespace: kube-system
+  labels:
+    k8s-app: konnectivity-agent
+spec:
+  selector:
+    matchLabels:
+      k8s-app: konnectivity-agent
+  template:
+    metadata:
+      labels:
+        k8s-app: konnectivity-agent
+    spec:
+      priorityClassName: system-cluster-critical
+      serviceAccountName: konnectivity-agent
+      tolerations:
+      - operator: Exists
+      containers:
+      - name: konnectivity-agent
+        image: k8s.gcr.io/kas-network-proxy/proxy-agent:{{ .Version }}
+        command: ["/proxy-agent"]
+        args:
+        - --logtostderr=true
+        - --ca-cert=/var/run/secrets/kubernetes.io/serviceaccount/ca.crt
+        - --proxy-server-host={{ .ServerHost }}
+        - --proxy-server-port={{ .AgentPort }}
+        - --admin-server-port=8133
+        - --health-server-port=8134
+        - --service-account-token-path=/var/run/secrets/tokens/konnectivity-agent-token
+        livenessProbe:
+          httpGet:
+            port: 8134
+            path: /healthz
+          initialDelaySeconds: 15
+          timeoutSeconds: 15
+        volumeMounts:
+        - name: konnectivity-agent-token
+          mountPath: /var/run/secrets/tokens
+      volumes:
+      - name: konnectivity-agent-token
+        projected:
+          sources:
+          - serviceAccountToken:
+              path: konnectivity-agent-token
+              audience: system:konnectivity-server
+`
+
+// CheckVersion returns an error if konnectivity is not supported by the
+// kubernetes version
+func CheckVersion(kubeVersion string) error {
+	v, err := version.ParseSemantic(kubeVersion)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse kubernetes version %q", kubeVersion)
+	}
+	if v.LessThan(minimumVersion) {
+		return errors.Errorf("konnectivity requires kubernetes %s or later, the node image is %s", minimumVersion, kubeVersion)
+	}
+	return nil
+}
+
+// EgressSelectorConfig returns the API server's egress selector config
+func EgressSelectorConfig(cfg *config.Konnectivity, kubeVersion string) (string, error) {
+	v, err := version.ParseSemantic(kubeVersion)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to parse kubernetes version %q", kubeVersion)
+	}
+	data := newTemplateData(cfg)
+	data.APIVersion = "apiserver.k8s.io/v1alpha1"
+	if v.AtLeast(egressSelectorV1beta1Version) {
+		data.APIVersion = "apiserver.k8s.io/v1beta1"
+	}
+	return execute("egress-selector", egressSelectorTemplate, data)
+}
+
+// ServerManifest returns the konnectivity server static pod manifest for
+// a cluster with serverCount control plane nodes
+func ServerManifest(cfg *config.Konnectivity, serverCount int) (string, error) {
+	data := newTemplateData(cfg)
+	data.ServerCount = serverCount
+	return execute("konnectivity-server", serverTemplate, data)
+}
+
+// AgentManifest returns the konnectivity agent manifest, the agents reach
+// the servers on serverHost, IE the control plane endpoint
+func AgentManifest(cfg *config.Konnectivity, serverHost string) (string, error) {
+	data := newTemplateData(cfg)
+	data.ServerHost = serverHost
+	return execute("konnectivity-agent", agentTemplate, data)
+}
+
+func newTemplateData(cfg *config.Konnectivity) *templateData {
+	data := &templateData{
+		Konnectivity:  cfg,
+		ProxyProtocol: "GRPC",
+		SocketPath:    Dir + "/konnectivity-server.socket",
+		AgentPort:     AgentPort,
+	}
+	if cfg.Mode == config.KonnectivityHTTPConnect {
+		data.ProxyProtocol = "HTTPConnect"
+	}
+	return data
+}
+
+func execute(name, text string, data *templateData) (string, error) {
+	t, err := template.New(name).Parse(text)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to parse %s template", name)
+	}
+	var buff bytes.Buffer
+	if err := t.Execute(&buff, data); err != nil {
+		return "", errors.Wrapf(err, "failed to execute %s template", name)
+	}
+	return buff.String(), nil
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package konnectivity
+
+import (
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+func TestEgressSelectorConfig(t *testing.T) {
+	cases := []struct {
+		Name        string
+		Mode        config.KonnectivityMode
+		KubeVersion string
+		Expected    []string
+		ExpectError bool
+	}{
+		{
+			Name:        "grpc on v1.19",
+			Mode:        config.KonnectivityGRPC,
+			KubeVersion: "v1.19.1",
+			Expected:    []string{"apiVersion: apiserver.k8s.io/v1alpha1", "proxyProtocol: GRPC"},
+		},
+		{
+			Name:        "http-connect on v1.20",
+			Mode:        config.KonnectivityHTTPConnect,
+			KubeVersion: "v1.20.0",
+			Expected:    []string{"apiVersion: apiserver.k8s.io/v1beta1", "proxyProtocol: HTTPConnect"},
+		},
+		{
+			Name:        "invalid version",
+			Mode:        config.KonnectivityGRPC,
+			KubeVersion: "bogus",
+			ExpectError: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			out, err := EgressSelectorConfig(&config.Konnectivity{Mode: tc.Mode}, tc.KubeVersion)
+			if err != nil != tc.ExpectError {
+				t.Fatalf("unexpected error state: %v", err)
+			}
+			for _, expected := range tc.Expected {
+				if !strings.Contains(out, expected) {
+					t.Errorf("expected output to contain %q, got:\n%s", expected, out)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckVersion(t *testing.T) {
+	if err := CheckVersion("v1.17.5"); err == nil {
+		t.Errorf("expected an error for v1.17.5")
+	}
+	if err := CheckVersion("v1.18.0"); err != nil {
+		t.Errorf("unexpected error for v1.18.0: %v", err)
+	}
+}
@@ -39,18 +39,55 @@ type ConfigData struct {
 	APIBindPort int
 	// The API server external listen IP (which we will port forward)
 	APIServerAddress string
+	// An additional DNS name for the API server certificate, used as the
+	// server address in the host kubeconfig if set
+	APIServerHostname string
 	// ControlPlane flag specifies the node belongs to the control plane
 	ControlPlane bool
 	// The main IP address of the node
 	NodeAddress string
 	// The Token for TLS bootstrap
 	Token string
+	// CertificateKey is the key the control plane certificates are uploaded
+	// to the cluster encrypted with, they are only uploaded if it is set
+	CertificateKey string
 	// The subnet used for pods
 	PodSubnet string
 	// The subnet used for services
 	ServiceSubnet string
 	// IPv4 values take precedence over IPv6 by default, if true set IPv6 default values
 	IPv6 bool
+	// The path to the container runtime's CRI socket on the node
+	CRISocket string
+	// The kubelet image credential provider config and plugin directory
+	// on the node, if credential providers are enabled
+	CredentialProviderConfig string
+	CredentialProviderBinDir string
+	// The address the kubelet uses for cluster DNS if not the kubeadm
+	// default, EG a node-local DNS cache
+	ClusterDNS string
+	// AlwaysPullImages enables the AlwaysPullImages admission plugin
+	AlwaysPullImages bool
+	// ParallelImagePulls disables the kubelet's serialized image pulls
+	ParallelImagePulls bool
+	// RuntimeRequestTimeout overrides the kubelet's CRI request timeout,
+	// which bounds image pulls
+	RuntimeRequestTimeout string
+	// ContainerLogMaxSize and ContainerLogMaxFiles configure the kubelet's
+	// container log rotation
+	ContainerLogMaxSize  string
+	ContainerLogMaxFiles int32
+	// Konnectivity configures the API server's egress selector, the
+	// config and the server socket are in KonnectivityDir
+	Konnectivity    bool
+	KonnectivityDir string
+	// DiskEviction keeps the kubelet's default image garbage collection and
+	// disk pressure eviction, which are otherwise disabled as the nodes
+	// share the host disk
+	DiskEviction bool
+	// ComponentBindAddress overrides the address the kube-controller-manager
+	// and kube-scheduler serve on, to publish their metrics from the node
+	ComponentBindAddress string
 	// DerivedConfigData is populated by Derive()
 	// These auto-generated fields are available to Config templates,
 	// but not meant to be set by hand
@@ -110,7 +147,7 @@ apiServerExtraVolumes:
 # on docker for mac we have to expose the api server via port forward,
 # so we need to ensure the cert is valid for localhost so we can talk
 # to the cluster after rewriting the kubeconfig to point to localhost
-apiServerCertSANs: [localhost, "{{.APIServerAddress}}"]
+apiServerCertSANs: [localhost, "{{.APIServerAddress}}"{{ if .APIServerHostname }}, "{{.APIServerHostname}}"{{ end }}]
 kubeletConfiguration:
   baseConfig:
     # configure ipv6 addresses in IPv6 mode
@@ -122,15 +159,18 @@ kubeletConfiguration:
     # kubelet will see the host disk that the inner container runtime
     # is ultimately backed by and attempt to recover disk space.
     # we don't want that.
+    # unless the nodes have their own size limited storage to manage
+{{- if not .DiskEviction }}
     imageGCHighThresholdPercent: 100
     evictionHard:
       nodefs.available: "0%"
       nodefs.inodesFree: "0%"
       imagefs.available: "0%"
+{{- end }}
 controllerManagerExtraArgs:
   enable-hostpath-provisioner: "true"
 nodeRegistration:
-  criSocket: "/run/containerd/containerd.sock"
+  criSocket: "{{ .CRISocket }}"
   kubeletExtraArgs:
     fail-swap-on: "false"
     node-ip: "{{ .NodeAddress }}"
@@ -146,7 +186,7 @@ discoveryTokenAPIServers: ["{{ .ControlPlaneEndpoint }}"]
 token: "{{ .Token }}"
 discoveryTokenUnsafeSkipCAVerification: true
 nodeRegistration:
-  criSocket: "/run/containerd/containerd.sock"
+  criSocket: "{{ .CRISocket }}"
   kubeletExtraArgs:
     fail-swap-on: "false"
     node-ip: "{{ .NodeAddress }}"
@@ -176,7 +216,11 @@ apiServerExtraVolumes:
 # on docker for mac we have to expose the api server via port forward,
 # so we need to ensure the cert is valid for localhost so we can talk
 # to the cluster after rewriting the kubeconfig to point to localhost
-apiServerCertSANs: [localhost, "{{.APIServerAddress}}"]
+apiServerCertSANs: [localhost, "{{.APIServerAddress}}"{{ if .APIServerHostname }}, "{{.APIServerHostname}}"{{ end }}]
+{{ if .AlwaysPullImages -}}
+apiServerExtraArgs:
+  enable-admission-plugins: "NodeRestriction,AlwaysPullImages"
+{{ end -}}
 controllerManagerExtraArgs:
   enable-hostpath-provisioner: "true"
 networking:
@@ -195,7 +239,7 @@ apiEndpoint:
   advertiseAddress: "{{ .NodeAddress }}"
   bindPort: {{.APIBindPort}}
 nodeRegistration:
-  criSocket: "/run/containerd/containerd.sock"
+  criSocket: "{{ .CRISocket }}"
   kubeletExtraArgs:
     fail-swap-on: "false"
     node-ip: "{{ .NodeAddress }}"
@@ -215,7 +259,7 @@ apiEndpoint:
   bindPort: {{.APIBindPort}}
 {{- end }}
 nodeRegistration:
-  criSocket: "/run/containerd/containerd.sock"
+  criSocket: "{{ .CRISocket }}"
   kubeletExtraArgs:
     fail-swap-on: "false"
     node-ip: "{{ .NodeAddress }}"
@@ -224,6 +268,19 @@ apiVersion: kubelet.config.k8s.io/v1beta1
 kind: KubeletConfiguration
 metadata:
   name: config
+{{ if .ClusterDNS -}}
+clusterDNS: ["{{ .ClusterDNS }}"]
+{{ end -}}
+{{ if .ParallelImagePulls -}}
+serializeImagePulls: false
+{{ end -}}
+{{ if .RuntimeRequestTimeout -}}
+runtimeRequestTimeout: "{{ .RuntimeRequestTimeout }}"
+{{ end -}}
+{{ if .ContainerLogMaxSize -}}
+containerLogMaxSize: "{{ .ContainerLogMaxSize }}"
+containerLogMaxFiles: {{ .ContainerLogMaxFiles }}
+{{ end -}}
 # configure ipv6 addresses in IPv6 mode
 {{ if .IPv6 -}}
 address: "::"
@@ -232,11 +289,14 @@ healthzBindAddress: "::"
 # disable disk resource management by default
 # kubelet will see the host disk that the inner container runtime
 # is ultimately backed by and attempt to recover disk space. we don't want that.
+# unless the nodes have their own size limited storage to manage
+{{ if not .DiskEviction -}}
 imageGCHighThresholdPercent: 100
 evictionHard:
   nodefs.available: "0%"
   nodefs.inodesFree: "0%"
   imagefs.available: "0%"
+{{ end -}}
 ---
 # no-op entry that exists solely so it can be patched
 apiVersion: kubeproxy.config.k8s.io/v1alpha1
@@ -258,12 +318,18 @@ controlPlaneEndpoint: "{{ .ControlPlaneEndpoint }}"
 # so we need to ensure the cert is valid for localhost so we can talk
 # to the cluster after rewriting the kubeconfig to point to localhost
 apiServer:
-  certSANs: [localhost, "{{.APIServerAddress}}"]
+  certSANs: [localhost, "{{.APIServerAddress}}"{{ if .APIServerHostname }}, "{{.APIServerHostname}}"{{ end }}]
+  {{ if .AlwaysPullImages -}}
+  extraArgs:
+    enable-admission-plugins: "NodeRestriction,AlwaysPullImages"
+  {{- end }}
 controllerManager:
   extraArgs:
     enable-hostpath-provisioner: "true"
     # configure ipv6 default addresses for IPv6 clusters
-    {{ if .IPv6 -}}
+    {{ if .ComponentBindAddress -}}
+    bind-address: "{{ .ComponentBindAddress }}"
+    {{- else if .IPv6 -}}
     bind-address: "::"
     {{- end }}
 scheduler:
@@ -271,6 +337,10 @@ scheduler:
     # configure ipv6 default addresses for IPv6 clusters
     {{ if .IPv6 -}}
     address: "::"
+    {{ end -}}
+    {{ if .ComponentBindAddress -}}
+    bind-address: "{{ .ComponentBindAddress }}"
+    {{- else if .IPv6 -}}
     bind-address: "::1"
     {{- end }}
 networking:
@@ -290,7 +360,7 @@ localAPIEndpoint:
   advertiseAddress: "{{ .NodeAddress }}"
   bindPort: {{.APIBindPort}}
 nodeRegistration:
-  criSocket: "/run/containerd/containerd.sock"
+  criSocket: "{{ .CRISocket }}"
   kubeletExtraArgs:
     fail-swap-on: "false"
     node-ip: "{{ .NodeAddress }}"
@@ -307,7 +377,7 @@ controlPlane:
     bindPort: {{.APIBindPort}}
 {{- end }}
 nodeRegistration:
-  criSocket: "/run/containerd/containerd.sock"
+  criSocket: "{{ .CRISocket }}"
   kubeletExtraArgs:
     fail-swap-on: "false"
     node-ip: "{{ .NodeAddress }}"
@@ -321,6 +391,19 @@ apiVersion: kubelet.config.k8s.io/v1beta1
 kind: KubeletConfiguration
 metadata:
   name: config
+{{ if .ClusterDNS -}}
+clusterDNS: ["{{ .ClusterDNS }}"]
+{{ end -}}
+{{ if .ParallelImagePulls -}}
+serializeImagePulls: false
+{{ end -}}
+{{ if .RuntimeRequestTimeout -}}
+runtimeRequestTimeout: "{{ .RuntimeRequestTimeout }}"
+{{ end -}}
+{{ if .ContainerLogMaxSize -}}
+containerLogMaxSize: "{{ .ContainerLogMaxSize }}"
+containerLogMaxFiles: {{ .ContainerLogMaxFiles }}
+{{ end -}}
 # configure ipv6 addresses in IPv6 mode
 {{ if .IPv6 -}}
 address: "::"
@@ -329,11 +412,14 @@ healthzBindAddress: "::"
 # disable disk resource management by default
 # kubelet will see the host disk that the inner container runtime
 # is ultimately backed by and attempt to recover disk space. we don't want that.
+# unless the nodes have their own size limited storage to manage
+{{ if not .DiskEviction -}}
 imageGCHighThresholdPercent: 100
 evictionHard:
   nodefs.available: "0%"
   nodefs.inodesFree: "0%"
   imagefs.available: "0%"
+{{ end -}}
 ---
 # no-op entry that exists solely so it can be patched
 apiVersion: kubeproxy.config.k8s.io/v1alpha1
@@ -355,12 +441,32 @@ controlPlaneEndpoint: "{{ .ControlPlaneEndpoint }}"
 # so we need to ensure the cert is valid for localhost so we can talk
 # to the cluster after rewriting the kubeconfig to point to localhost
 apiServer:
-  certSANs: [localhost, "{{.APIServerAddress}}"]
+  certSANs: [localhost, "{{.APIServerAddress}}"{{ if .APIServerHostname }}, "{{.APIServerHostname}}"{{ end }}]
+  {{ if or .AlwaysPullImages .Konnectivity -}}
+  extraArgs:
+    {{ if .AlwaysPullImages -}}
+    enable-admission-plugins: "NodeRestriction,AlwaysPullImages"
+    {{ end -}}
+    {{ if .Konnectivity -}}
+    egress-selector-config-file: "{{ .KonnectivityDir }}/egress-selector-configuration.yaml"
+    # konnectivity agents authenticate with projected service account tokens
+    service-account-issuer: "https://kubernetes.default.svc.cluster.local"
+    service-account-signing-key-file: "/etc/kubernetes/pki/sa.key"
+    {{- end }}
+  {{- end }}
+  {{ if .Konnectivity -}}
+  extraVolumes:
+  - name: konnectivity
+    hostPath: "{{ .KonnectivityDir }}"
+    mountPath: "{{ .KonnectivityDir }}"
+  {{- end }}
 controllerManager:
   extraArgs:
     enable-hostpath-provisioner: "true"
     # configure ipv6 default addresses for IPv6 clusters
-    {{ if .IPv6 -}}
+    {{ if .ComponentBindAddress -}}
+    bind-address: "{{ .ComponentBindAddress }}"
+    {{- else if .IPv6 -}}
     bind-address: "::"
     {{- end }}
 scheduler:
@@ -368,6 +474,10 @@ scheduler:
     # configure ipv6 default addresses for IPv6 clusters
     {{ if .IPv6 -}}
     address: "::"
+    {{ end -}}
+    {{ if .ComponentBindAddress -}}
+    bind-address: "{{ .ComponentBindAddress }}"
+    {{- else if .IPv6 -}}
     bind-address: "::1"
     {{- end }}
 networking:
@@ -381,16 +491,25 @@ metadata:
 # we use a well know token for TLS bootstrap
 bootstrapTokens:
 - token: "{{ .Token }}"
+{{ if .CertificateKey -}}
+certificateKey: "{{ .CertificateKey }}"
+{{ end -}}
 # we use a well know port for making the API server discoverable inside docker network. 
 # from the host machine such port will be accessible via a random local port instead.
 localAPIEndpoint:
   advertiseAddress: "{{ .NodeAddress }}"
   bindPort: {{.APIBindPort}}
 nodeRegistration:
-  criSocket: "/run/containerd/containerd.sock"
+  criSocket: "{{ .CRISocket }}"
   kubeletExtraArgs:
     fail-swap-on: "false"
     node-ip: "{{ .NodeAddress }}"
+{{- if .CredentialProviderConfig }}
+    # credential providers are alpha, and must be enabled with a feature gate
+    feature-gates: "KubeletCredentialProviders=true"
+    image-credential-provider-config: "{{ .CredentialProviderConfig }}"
+    image-credential-provider-bin-dir: "{{ .CredentialProviderBinDir }}"
+{{- end }}
 ---
 # no-op entry that exists solely so it can be patched
 apiVersion: kubeadm.k8s.io/v1beta2
@@ -402,12 +521,21 @@ controlPlane:
   localAPIEndpoint:
     advertiseAddress: "{{ .NodeAddress }}"
     bindPort: {{.APIBindPort}}
+{{- if .CertificateKey }}
+  certificateKey: "{{ .CertificateKey }}"
+{{- end }}
 {{- end }}
 nodeRegistration:
-  criSocket: "/run/containerd/containerd.sock"
+  criSocket: "{{ .CRISocket }}"
   kubeletExtraArgs:
     fail-swap-on: "false"
     node-ip: "{{ .NodeAddress }}"
+{{- if .CredentialProviderConfig }}
+    # credential providers are alpha, and must be enabled with a feature gate
+    feature-gates: "KubeletCredentialProviders=true"
+    image-credential-provider-config: "{{ .CredentialProviderConfig }}"
+    image-credential-provider-bin-dir: "{{ .CredentialProviderBinDir }}"
+{{- end }}
 discovery:
   bootstrapToken:
     apiServerEndpoint: "{{ .ControlPlaneEndpoint }}"
@@ -418,6 +546,19 @@ apiVersion: kubelet.config.k8s.io/v1beta1
 kind: KubeletConfiguration
 metadata:
   name: config
+{{ if .ClusterDNS -}}
+clusterDNS: ["{{ .ClusterDNS }}"]
+{{ end -}}
+{{ if .ParallelImagePulls -}}
+serializeImagePulls: false
+{{ end -}}
+{{ if .RuntimeRequestTimeout -}}
+runtimeRequestTimeout: "{{ .RuntimeRequestTimeout }}"
+{{ end -}}
+{{ if .ContainerLogMaxSize -}}
+containerLogMaxSize: "{{ .ContainerLogMaxSize }}"
+containerLogMaxFiles: {{ .ContainerLogMaxFiles }}
+{{ end -}}
 # configure ipv6 addresses in IPv6 mode
 {{ if .IPv6 -}}
 address: "::"
@@ -426,11 +567,14 @@ healthzBindAddress: "::"
 # disable disk resource management by default
 # kubelet will see the host disk that the inner container runtime
 # is ultimately backed by and attempt to recover disk space. we don't want that.
+# unless the nodes have their own size limited storage to manage
+{{ if not .DiskEviction -}}
 imageGCHighThresholdPercent: 100
 evictionHard:
   nodefs.available: "0%"
   nodefs.inodesFree: "0%"
   imagefs.available: "0%"
+{{ end -}}
 ---
 # no-op entry that exists solely so it can be patched
 apiVersion: kubeproxy.config.k8s.io/v1alpha1
@@ -448,6 +592,17 @@ func Config(data ConfigData) (config string, err error) {
 		return "", err
 	}
 
+	// the certificate key is only configurable from kubeadm v1beta2
+	if data.CertificateKey != "" && ver.LessThan(version.MustParseSemantic("v1.15.0")) {
+		return "", errors.Errorf("a certificateKey requires Kubernetes v1.15.0 or later, got %s", data.KubernetesVersion)
+	}
+
+	// the kubelet gained image credential provider plugins in v1.20, older
+	// kubelets fail on the flags
+	if data.CredentialProviderConfig != "" && ver.LessThan(version.MustParseSemantic("v1.20.0")) {
+		return "", errors.Errorf("kubeletCredentialProvider requires Kubernetes v1.20.0 or later, got %s", data.KubernetesVersion)
+	}
+
 	// assume the latest API version, then fallback if the k8s version is too low
 	templateSource := ConfigTemplateBetaV2
 	if ver.LessThan(version.MustParseSemantic("v1.12.0")) {
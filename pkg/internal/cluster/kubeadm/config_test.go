@@ -0,0 +1,126 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeadm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConfigCertificateKey(t *testing.T) {
+	key := strings.Repeat("ab", 32)
+	cases := []struct {
+		Name              string
+		KubernetesVersion string
+		ControlPlane      bool
+		ExpectedKeys      int
+		ExpectError       bool
+	}{
+		{
+			Name:              "init and control plane join",
+			KubernetesVersion: "v1.16.3",
+			ControlPlane:      true,
+			ExpectedKeys:      2,
+		},
+		{
+			Name:              "worker join",
+			KubernetesVersion: "v1.16.3",
+			ExpectedKeys:      1,
+		},
+		{
+			Name:              "unsupported version",
+			KubernetesVersion: "v1.14.3",
+			ControlPlane:      true,
+			ExpectError:       true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			config, err := Config(ConfigData{
+				ClusterName:       "kind",
+				KubernetesVersion: tc.KubernetesVersion,
+				ControlPlane:      tc.ControlPlane,
+				Token:             Token,
+				CertificateKey:    key,
+			})
+			if tc.ExpectError {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if n := strings.Count(config, "certificateKey: \""+key+"\""); n != tc.ExpectedKeys {
+				t.Errorf("config has the certificate key %d times, expected %d:\n%s", n, tc.ExpectedKeys, config)
+			}
+		})
+	}
+}
+
+func TestConfigCredentialProvider(t *testing.T) {
+	cases := []struct {
+		Name              string
+		KubernetesVersion string
+		ExpectError       bool
+	}{
+		{
+			Name:              "supported version",
+			KubernetesVersion: "v1.20.2",
+		},
+		{
+			Name:              "kubelet without credential providers",
+			KubernetesVersion: "v1.19.7",
+			ExpectError:       true,
+		},
+		{
+			Name:              "older kubeadm config API",
+			KubernetesVersion: "v1.13.12",
+			ExpectError:       true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			config, err := Config(ConfigData{
+				ClusterName:              "kind",
+				KubernetesVersion:        tc.KubernetesVersion,
+				ControlPlane:             true,
+				Token:                    Token,
+				CredentialProviderConfig: "/etc/kubernetes/credential-provider.yaml",
+				CredentialProviderBinDir: "/usr/local/bin/credential-providers",
+			})
+			if tc.ExpectError {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			// both the init and the join configuration set the flags
+			if n := strings.Count(config, "image-credential-provider-config:"); n != 2 {
+				t.Errorf("config sets the credential provider config %d times, expected 2:\n%s", n, config)
+			}
+		})
+	}
+}
@@ -22,3 +22,11 @@ const Token = "abcdef.0123456789abcdef"
 // ObjectName is the name every generated object will have
 // I.E. `metadata:\nname: config`
 const ObjectName = "config"
+
+// CredentialProviderConfigPath is the path within the node the kubelet
+// image credential provider config is mounted at
+const CredentialProviderConfigPath = "/etc/kubernetes/credential-provider/config.yaml"
+
+// CredentialProviderBinDir is the directory within the node the kubelet
+// image credential provider plugins are mounted at
+const CredentialProviderBinDir = "/etc/kubernetes/credential-provider/bin"
@@ -0,0 +1,149 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeadm
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// preflightLineRE matches the errors and warnings of kubeadm preflight
+// checks, EG: [WARNING Swap]: running with swap on is not supported
+var preflightLineRE = regexp.MustCompile(`\[(ERROR|WARNING) ([^\]]+)\]: (.*)$`)
+
+// PreflightIssue is an error or warning reported by a kubeadm preflight check,
+// kind ignores all preflight errors so these are usually warnings
+type PreflightIssue struct {
+	// Severity is ERROR or WARNING
+	Severity string
+	// Check is the name of the check, EG Swap or Port-6443
+	Check string
+	// Message is the message reported by the check
+	Message string
+}
+
+// ParsePreflight returns the preflight issues found in kubeadm output lines
+func ParsePreflight(lines []string) []PreflightIssue {
+	issues := []PreflightIssue{}
+	for _, line := range lines {
+		match := preflightLineRE.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		issues = append(issues, PreflightIssue{
+			Severity: match[1],
+			Check:    match[2],
+			Message:  strings.TrimSpace(match[3]),
+		})
+	}
+	return issues
+}
+
+// knownPreflightIssue is a preflight issue signature known to break
+// clusters, with a friendly explanation and remediation
+type knownPreflightIssue struct {
+	// check matches PreflightIssue.Check
+	check *regexp.Regexp
+	// message optionally matches PreflightIssue.Message
+	message     *regexp.Regexp
+	explanation string
+	remediation string
+}
+
+// knownPreflightIssues are matched in order, the first match wins.
+// Issues kind works around, such as swap being enabled, are not listed.
+var knownPreflightIssues = []knownPreflightIssue{
+	{
+		check:       regexp.MustCompile(`^Mem$`),
+		explanation: "the node has less memory than kubeadm requires",
+		remediation: "make at least 2GB of memory available to docker, EG in the Docker Desktop resource settings",
+	},
+	{
+		check:       regexp.MustCompile(`^NumCPU$`),
+		explanation: "the node has fewer CPUs than kubeadm requires",
+		remediation: "make at least 2 CPUs available to docker, EG in the Docker Desktop resource settings",
+	},
+	{
+		check:       regexp.MustCompile(`^Port-\d+$`),
+		explanation: "a port kubeadm needs is already in use within the node",
+		remediation: "the node is most likely left over from a failed attempt, delete the cluster and create it again",
+	},
+	{
+		check:       regexp.MustCompile(`^(FileAvailable|DirAvailable)--`),
+		explanation: "the node already contains files from a previous kubeadm run",
+		remediation: "delete the cluster and create it again",
+	},
+	{
+		check:       regexp.MustCompile(`^FileContent--proc-sys-net-bridge-bridge-nf-call-ip`),
+		explanation: "bridged traffic is not passed to iptables on the host",
+		remediation: "load the br_netfilter kernel module on the host, EG with kernelModules: [br_netfilter] in the cluster config and --load-kernel-modules",
+	},
+	{
+		check:       regexp.MustCompile(`^SystemVerification$`),
+		message:     regexp.MustCompile(`cgroup`),
+		explanation: "the host kernel does not provide cgroups the kubelet requires",
+		remediation: "enable the missing cgroups on the host, EG with cgroup_enable=memory on the kernel command line",
+	},
+}
+
+// preflightError is a kubeadm failure explained by preflight issues
+type preflightError struct {
+	error
+	explained []string
+}
+
+func (e *preflightError) Error() string {
+	return fmt.Sprintf(
+		"%v\n\nkubeadm preflight checks found problems that are likely responsible:\n%s",
+		e.error, strings.Join(e.explained, "\n"),
+	)
+}
+
+// Cause returns the kubeadm failure
+func (e *preflightError) Cause() error {
+	return e.error
+}
+
+// PreflightError returns err, the failure of a kubeadm command with output
+// lines, explained by any known preflight issues in the output, otherwise
+// it returns err unchanged
+func PreflightError(err error, lines []string) error {
+	if err == nil {
+		return nil
+	}
+	explained := []string{}
+	for _, issue := range ParsePreflight(lines) {
+		for _, known := range knownPreflightIssues {
+			if !known.check.MatchString(issue.Check) {
+				continue
+			}
+			if known.message != nil && !known.message.MatchString(issue.Message) {
+				continue
+			}
+			explained = append(explained, fmt.Sprintf(
+				" • %s (%s: %s)\n   fix: %s",
+				known.explanation, issue.Check, issue.Message, known.remediation,
+			))
+			break
+		}
+	}
+	if len(explained) == 0 {
+		return err
+	}
+	return &preflightError{error: err, explained: explained}
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeadm
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+func TestParsePreflight(t *testing.T) {
+	lines := []string{
+		"[preflight] Running pre-flight checks",
+		"\t[WARNING Swap]: running with swap on is not supported. Please disable swap",
+		"\t[WARNING NumCPU]: the number of available CPUs 1 is less than the required 2",
+		"[init] Using Kubernetes version: v1.16.3",
+	}
+	expected := []PreflightIssue{
+		{Severity: "WARNING", Check: "Swap", Message: "running with swap on is not supported. Please disable swap"},
+		{Severity: "WARNING", Check: "NumCPU", Message: "the number of available CPUs 1 is less than the required 2"},
+	}
+	if result := ParsePreflight(lines); !reflect.DeepEqual(result, expected) {
+		t.Errorf("ParsePreflight() = %+v, expected %+v", result, expected)
+	}
+}
+
+func TestPreflightError(t *testing.T) {
+	failure := errors.New("exit status 1")
+	cases := []struct {
+		Name     string
+		Lines    []string
+		Expected []string
+	}{
+		{
+			Name:  "no known issues",
+			Lines: []string{"\t[WARNING Swap]: running with swap on is not supported. Please disable swap"},
+		},
+		{
+			Name: "known issues",
+			Lines: []string{
+				"\t[WARNING Port-10250]: Port 10250 is in use",
+				"\t[WARNING Mem]: the system RAM (983 MB) is less than the minimum 1700 MB",
+			},
+			Expected: []string{
+				"a port kubeadm needs is already in use within the node",
+				"the node has less memory than kubeadm requires",
+			},
+		},
+		{
+			Name:  "message mismatch",
+			Lines: []string{"\t[WARNING SystemVerification]: this Docker version is not on the list of validated versions"},
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			err := PreflightError(failure, tc.Lines)
+			if len(tc.Expected) == 0 {
+				if err != failure {
+					t.Errorf("PreflightError() = %v, expected the error unchanged", err)
+				}
+				return
+			}
+			if cause := err.(interface{ Cause() error }).Cause(); cause != failure {
+				t.Errorf("PreflightError() cause = %v, expected %v", cause, failure)
+			}
+			for _, explanation := range tc.Expected {
+				if !strings.Contains(err.Error(), explanation) {
+					t.Errorf("PreflightError() = %q, expected it to contain %q", err.Error(), explanation)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kubeconfig implements updating the host kubeconfig kind writes
+// for a cluster
+package kubeconfig
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/internal/cluster/context"
+	"sigs.k8s.io/kind/pkg/internal/util/env"
+)
+
+// matches kubeconfig server entry like:
+//
+//	server: https://172.17.0.2:6443
+//
+// which we rewrite to:
+//
+//	server: https://$ADDRESS:$PORT
+var serverAddressRE = regexp.MustCompile(`^(\s+server:) https://(.*:\d+)$`)
+
+// SetServer returns kubeconfig with every server entry pointed at endpoint
+func SetServer(kubeconfig []byte, endpoint string) []byte {
+	updated, _ := rewriteServers(kubeconfig, func(string) string {
+		return endpoint
+	})
+	return updated
+}
+
+// Refresh points the cluster's host kubeconfig and the copy kept in the
+// cluster directory at the current API server endpoint, which changes
+// when the nodes are restarted and get new host ports, or at the API server
+// proxy if one is running for the cluster.
+// Server hostnames, EG from apiServerHostname, are kept and only their
+// port is updated. Refresh returns true if the kubeconfig was changed.
+func Refresh(ctx *context.Context) (bool, error) {
+	updated, changed, err := Read(ctx)
+	if err != nil || !changed {
+		return false, err
+	}
+	if err := ioutil.WriteFile(ctx.KubeConfigPath(), updated, 0600); err != nil {
+		return false, errors.Wrap(err, "failed to write kubeconfig")
+	}
+	ctx.KeepFile(context.KubeConfigFile, updated)
+	return true, nil
+}
+
+// Read returns the cluster's host kubeconfig pointed at the current API
+// server endpoint like Refresh, and whether that differs from the file,
+// without writing anything
+func Read(ctx *context.Context) ([]byte, bool, error) {
+	current, err := ioutil.ReadFile(ctx.KubeConfigPath())
+	if err != nil {
+		return nil, false, errors.Wrap(err, "failed to read kubeconfig")
+	}
+	endpoint, ok := proxyEndpoint(ctx)
+	if !ok {
+		if endpoint, err = ctx.GetAPIServerEndpoint(); err != nil {
+			return nil, false, errors.Wrap(err, "failed to get api server endpoint")
+		}
+	}
+	return refreshServers(current, endpoint)
+}
+
+// RecordProxy records that this process proxies the cluster's API server on
+// address, Refresh points the kubeconfig at it while the process runs
+// An unspecified host, EG 0.0.0.0, is recorded as the loopback address as
+// the proxy accepts connections on every address then
+func RecordProxy(ctx *context.Context, address string) error {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse API server proxy address")
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsUnspecified() {
+		host = "127.0.0.1"
+	}
+	record := fmt.Sprintf("%s %d", net.JoinHostPort(host, port), os.Getpid())
+	if err := ctx.WriteFile(context.APIServerProxyFile, []byte(record)); err != nil {
+		return errors.Wrap(err, "failed to record API server proxy address")
+	}
+	return nil
+}
+
+// RecordedProxy returns the address the cluster's API server was last
+// proxied on and the pid of the process proxying it, see RecordProxy
+func RecordedProxy(ctx *context.Context) (address string, pid int, err error) {
+	recorded, err := ioutil.ReadFile(ctx.Path(context.APIServerProxyFile))
+	if err != nil {
+		return "", 0, err
+	}
+	fields := strings.Fields(string(recorded))
+	if len(fields) != 2 {
+		return "", 0, errors.Errorf("invalid API server proxy record %q", recorded)
+	}
+	pid, err = strconv.Atoi(fields[1])
+	if err != nil {
+		return "", 0, errors.Wrapf(err, "invalid API server proxy record %q", recorded)
+	}
+	return fields[0], pid, nil
+}
+
+// proxyEndpoint returns the address the cluster's API server is proxied on
+// and true, if the process recorded proxying it still runs and listens
+// there, rather than anything else that took the port since
+func proxyEndpoint(ctx *context.Context) (string, bool) {
+	endpoint, pid, err := RecordedProxy(ctx)
+	if err != nil || !env.ProcessRunning(pid) {
+		return "", false
+	}
+	conn, err := net.DialTimeout("tcp", endpoint, time.Second)
+	if err != nil {
+		return "", false
+	}
+	conn.Close()
+	return endpoint, true
+}
+
+// refreshServers returns kubeconfig with every server entry pointed at
+// endpoint, keeping hostnames, and whether anything changed
+func refreshServers(kubeconfig []byte, endpoint string) ([]byte, bool, error) {
+	host, port, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "failed to parse api server endpoint")
+	}
+	updated, changed := rewriteServers(kubeconfig, func(server string) string {
+		serverHost, _, err := net.SplitHostPort(server)
+		if err == nil && net.ParseIP(serverHost) == nil {
+			return net.JoinHostPort(serverHost, port)
+		}
+		return net.JoinHostPort(host, port)
+	})
+	return updated, changed, nil
+}
+
+// rewriteServers replaces every server entry in kubeconfig with the result
+// of calling endpoint with its current host:port
+func rewriteServers(kubeconfig []byte, endpoint func(string) string) ([]byte, bool) {
+	changed := false
+	lines := strings.Split(string(kubeconfig), "\n")
+	for i, line := range lines {
+		match := serverAddressRE.FindStringSubmatch(line)
+		if len(match) < 3 {
+			continue
+		}
+		if server := endpoint(match[2]); server != match[2] {
+			lines[i] = fmt.Sprintf("%s https://%s", match[1], server)
+			changed = true
+		}
+	}
+	return []byte(strings.Join(lines, "\n")), changed
+}
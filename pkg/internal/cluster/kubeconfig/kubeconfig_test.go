@@ -0,0 +1,260 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeconfig
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	osexec "os/exec"
+	"path/filepath"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/cluster/context"
+)
+
+func TestRefreshServers(t *testing.T) {
+	cases := []struct {
+		Name            string
+		KubeConfig      string
+		Endpoint        string
+		ExpectedConfig  string
+		ExpectedChanged bool
+		ExpectErr       bool
+	}{
+		{
+			Name:            "port changed",
+			KubeConfig:      "clusters:\n- cluster:\n    server: https://127.0.0.1:32768\n",
+			Endpoint:        "127.0.0.1:32771",
+			ExpectedConfig:  "clusters:\n- cluster:\n    server: https://127.0.0.1:32771\n",
+			ExpectedChanged: true,
+		},
+		{
+			Name:           "unchanged",
+			KubeConfig:     "clusters:\n- cluster:\n    server: https://127.0.0.1:32768\n",
+			Endpoint:       "127.0.0.1:32768",
+			ExpectedConfig: "clusters:\n- cluster:\n    server: https://127.0.0.1:32768\n",
+		},
+		{
+			Name:            "hostname is kept",
+			KubeConfig:      "clusters:\n- cluster:\n    server: https://kind.example.com:32768\n",
+			Endpoint:        "127.0.0.1:32771",
+			ExpectedConfig:  "clusters:\n- cluster:\n    server: https://kind.example.com:32771\n",
+			ExpectedChanged: true,
+		},
+		{
+			Name:            "ipv6",
+			KubeConfig:      "clusters:\n- cluster:\n    server: https://[::1]:32768\n",
+			Endpoint:        "[::1]:32771",
+			ExpectedConfig:  "clusters:\n- cluster:\n    server: https://[::1]:32771\n",
+			ExpectedChanged: true,
+		},
+		{
+			Name:       "bogus endpoint",
+			KubeConfig: "clusters:\n- cluster:\n    server: https://127.0.0.1:32768\n",
+			Endpoint:   "127.0.0.1",
+			ExpectErr:  true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			result, changed, err := refreshServers([]byte(tc.KubeConfig), tc.Endpoint)
+			if (err != nil) != tc.ExpectErr {
+				t.Fatalf("refreshServers() error = %v, ExpectErr %v", err, tc.ExpectErr)
+			}
+			if tc.ExpectErr {
+				return
+			}
+			if string(result) != tc.ExpectedConfig {
+				t.Errorf("refreshServers() = %q, expected %q", result, tc.ExpectedConfig)
+			}
+			if changed != tc.ExpectedChanged {
+				t.Errorf("refreshServers() changed = %v, expected %v", changed, tc.ExpectedChanged)
+			}
+		})
+	}
+}
+
+// withTempHome points HOME at a temporary directory for the cluster
+// directory, returning a func restoring it
+func withTempHome(t *testing.T) func() {
+	dir, err := ioutil.TempDir("", "kind-home")
+	if err != nil {
+		t.Fatal(err)
+	}
+	home := os.Getenv("HOME")
+	if err := os.Setenv("HOME", dir); err != nil {
+		t.Fatal(err)
+	}
+	return func() {
+		os.Setenv("HOME", home)
+		os.RemoveAll(dir)
+	}
+}
+
+func TestRecordProxy(t *testing.T) {
+	defer withTempHome(t)()
+	ctx := context.NewProviderContext(nil, "kind")
+	cases := []struct {
+		Name     string
+		Address  string
+		Expected string
+	}{
+		{
+			Name:     "loopback",
+			Address:  "127.0.0.1:6443",
+			Expected: "127.0.0.1:6443",
+		},
+		{
+			Name:     "unspecified ipv4",
+			Address:  "0.0.0.0:6443",
+			Expected: "127.0.0.1:6443",
+		},
+		{
+			Name:     "unspecified ipv6",
+			Address:  "[::]:6443",
+			Expected: "127.0.0.1:6443",
+		},
+		{
+			Name:     "host address",
+			Address:  "192.168.1.2:6443",
+			Expected: "192.168.1.2:6443",
+		},
+	}
+	for _, tc := range cases {
+		if err := RecordProxy(ctx, tc.Address); err != nil {
+			t.Fatalf("%s: RecordProxy() error = %v", tc.Name, err)
+		}
+		address, pid, err := RecordedProxy(ctx)
+		if err != nil {
+			t.Fatalf("%s: RecordedProxy() error = %v", tc.Name, err)
+		}
+		if address != tc.Expected || pid != os.Getpid() {
+			t.Errorf("%s: RecordedProxy() = %s, %d, expected %s, %d", tc.Name, address, pid, tc.Expected, os.Getpid())
+		}
+	}
+}
+
+func TestProxyEndpoint(t *testing.T) {
+	defer withTempHome(t)()
+	ctx := context.NewProviderContext(nil, "kind")
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer listener.Close()
+	// a pid of a process that exited
+	exited := osexec.Command("true")
+	if err := exited.Run(); err != nil {
+		t.Fatalf("failed to run true: %v", err)
+	}
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	closed.Close()
+	cases := []struct {
+		Name       string
+		Record     string
+		ExpectedOK bool
+	}{
+		{
+			Name:       "running proxy",
+			Record:     fmt.Sprintf("%s %d", listener.Addr(), os.Getpid()),
+			ExpectedOK: true,
+		},
+		{
+			// something else took the port of a proxy that exited
+			Name:   "exited proxy",
+			Record: fmt.Sprintf("%s %d", listener.Addr(), exited.Process.Pid),
+		},
+		{
+			Name:   "not listening",
+			Record: fmt.Sprintf("%s %d", closed.Addr(), os.Getpid()),
+		},
+		{
+			Name:   "address only",
+			Record: listener.Addr().String(),
+		},
+	}
+	for _, tc := range cases {
+		if err := ctx.WriteFile(context.APIServerProxyFile, []byte(tc.Record)); err != nil {
+			t.Fatal(err)
+		}
+		endpoint, ok := proxyEndpoint(ctx)
+		if ok != tc.ExpectedOK {
+			t.Errorf("%s: proxyEndpoint() = %q, %v, expected ok %v", tc.Name, endpoint, ok, tc.ExpectedOK)
+		}
+		if ok && endpoint != listener.Addr().String() {
+			t.Errorf("%s: proxyEndpoint() = %q, expected %q", tc.Name, endpoint, listener.Addr())
+		}
+	}
+	if err := os.Remove(ctx.Path(context.APIServerProxyFile)); err != nil {
+		t.Fatal(err)
+	}
+	if endpoint, ok := proxyEndpoint(ctx); ok {
+		t.Errorf("proxyEndpoint() = %q, expected none without a record", endpoint)
+	}
+}
+
+func TestRead(t *testing.T) {
+	defer withTempHome(t)()
+	ctx := context.NewProviderContext(nil, "kind")
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer listener.Close()
+	if err := RecordProxy(ctx, listener.Addr().String()); err != nil {
+		t.Fatal(err)
+	}
+	stale := "clusters:\n- cluster:\n    server: https://127.0.0.1:1\n"
+	if err := os.MkdirAll(filepath.Dir(ctx.KubeConfigPath()), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(ctx.KubeConfigPath(), []byte(stale), 0600); err != nil {
+		t.Fatal(err)
+	}
+	expected := fmt.Sprintf("clusters:\n- cluster:\n    server: https://%s\n", listener.Addr())
+	kubeConfig, changed, err := Read(ctx)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if string(kubeConfig) != expected || !changed {
+		t.Errorf("Read() = %q, %v, expected %q, true", kubeConfig, changed, expected)
+	}
+	// the file is only updated by Refresh
+	onDisk, err := ioutil.ReadFile(ctx.KubeConfigPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(onDisk) != stale {
+		t.Errorf("Read() changed the kubeconfig to %q", onDisk)
+	}
+	if changed, err := Refresh(ctx); err != nil || !changed {
+		t.Fatalf("Refresh() = %v, %v, expected true, nil", changed, err)
+	}
+	if onDisk, err = ioutil.ReadFile(ctx.KubeConfigPath()); err != nil {
+		t.Fatal(err)
+	}
+	if string(onDisk) != expected {
+		t.Errorf("Refresh() wrote %q, expected %q", onDisk, expected)
+	}
+}
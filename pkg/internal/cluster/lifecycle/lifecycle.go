@@ -0,0 +1,128 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package lifecycle implements the machine readable cluster status file,
+// updated as the cluster moves through its lifecycle so that external
+// tools may poll the cluster state
+package lifecycle
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+// Phase is a cluster lifecycle phase
+type Phase string
+
+const (
+	// Creating is the phase from provisioning nodes until the cluster is up
+	Creating Phase = "creating"
+	// Ready is the phase of a successfully created cluster
+	Ready Phase = "ready"
+	// Degraded is the phase of a cluster that exists but did not come up
+	// correctly, E.G. failed creation with --retain
+	Degraded Phase = "degraded"
+	// Deleting is the phase while the cluster is being deleted, the status
+	// file is removed once deletion completes
+	Deleting Phase = "deleting"
+	// Failed is the phase of a cluster that failed to create and was
+	// deleted, the status file is kept to report the failure until a
+	// cluster of the same name is created
+	Failed Phase = "failed"
+)
+
+// Status is the content of the status file
+type Status struct {
+	// Cluster is the cluster name
+	Cluster string `json:"cluster"`
+	// Phase is the current lifecycle phase
+	Phase Phase `json:"phase"`
+	// CreationTimestamp is when cluster creation started
+	CreationTimestamp time.Time `json:"creationTimestamp"`
+	// LastTransitionTime is when the cluster entered Phase
+	LastTransitionTime time.Time `json:"lastTransitionTime"`
+	// LastError is the error the cluster entered Phase with, if any
+	LastError string `json:"lastError,omitempty"`
+}
+
+// Read reads the status file at path, returning nil if it does not exist
+func Read(path string) (*Status, error) {
+	contents, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to read cluster status")
+	}
+	status := &Status{}
+	if err := json.Unmarshal(contents, status); err != nil {
+		return nil, errors.Wrap(err, "failed to parse cluster status")
+	}
+	return status, nil
+}
+
+// Transition records that cluster entered phase in the status file at path,
+// cause is recorded as the last error, a nil cause clears it
+func Transition(path, cluster string, phase Phase, cause error) error {
+	now := time.Now().UTC()
+	status, err := Read(path)
+	// start over if the existing file is unusable or from a previous cluster
+	if err != nil || status == nil || phase == Creating {
+		status = &Status{
+			CreationTimestamp: now,
+		}
+	}
+	status.Cluster = cluster
+	status.Phase = phase
+	status.LastTransitionTime = now
+	status.LastError = ""
+	if cause != nil {
+		status.LastError = cause.Error()
+	}
+	return Write(path, status)
+}
+
+// Write atomically replaces the status file at path so readers never
+// observe partial content
+func Write(path string, status *Status) error {
+	contents, err := json.MarshalIndent(status, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to encode cluster status")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return errors.Wrap(err, "failed to create cluster status dir")
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(path), ".status-*.json")
+	if err != nil {
+		return errors.Wrap(err, "failed to write cluster status")
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(contents, '\n')); err != nil {
+		tmp.Close()
+		return errors.Wrap(err, "failed to write cluster status")
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Wrap(err, "failed to write cluster status")
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return errors.Wrap(err, "failed to write cluster status")
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lifecycle
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+func TestTransition(t *testing.T) {
+	cases := []struct {
+		Name          string
+		Transitions   []Phase
+		Causes        []error
+		ExpectedPhase Phase
+		ExpectedError string
+	}{
+		{
+			Name:          "failure is recorded",
+			Transitions:   []Phase{Creating, Degraded},
+			Causes:        []error{nil, errors.New("boom")},
+			ExpectedPhase: Degraded,
+			ExpectedError: "boom",
+		},
+		{
+			Name:          "error is cleared by a transition without cause",
+			Transitions:   []Phase{Creating, Degraded, Deleting},
+			Causes:        []error{nil, errors.New("boom"), nil},
+			ExpectedPhase: Deleting,
+		},
+		{
+			Name:          "latest failure replaces earlier ones",
+			Transitions:   []Phase{Creating, Deleting, Deleting},
+			Causes:        []error{nil, errors.New("boom"), errors.New("bang")},
+			ExpectedPhase: Deleting,
+			ExpectedError: "bang",
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			dir, err := ioutil.TempDir("", "lifecycle-test")
+			if err != nil {
+				t.Fatalf("failed to create temp dir: %v", err)
+			}
+			defer os.RemoveAll(dir)
+			path := filepath.Join(dir, "status.json")
+			for i, phase := range tc.Transitions {
+				if err := Transition(path, "kind", phase, tc.Causes[i]); err != nil {
+					t.Fatalf("Transition(%s) failed: %v", phase, err)
+				}
+			}
+			status, err := Read(path)
+			if err != nil {
+				t.Fatalf("Read() failed: %v", err)
+			}
+			if status.Phase != tc.ExpectedPhase {
+				t.Errorf("phase is %q, expected %q", status.Phase, tc.ExpectedPhase)
+			}
+			if status.LastError != tc.ExpectedError {
+				t.Errorf("last error is %q, expected %q", status.LastError, tc.ExpectedError)
+			}
+		})
+	}
+}
@@ -27,7 +27,11 @@ import (
 type ConfigData struct {
 	ControlPlanePort int
 	BackendServers   map[string]string
-	IPv6             bool
+	// KonnectivityPort is the konnectivity server agent port, balanced
+	// across KonnectivityServers for clusters with konnectivity
+	KonnectivityPort    int
+	KonnectivityServers map[string]string
+	IPv6                bool
 }
 
 // DefaultConfigTemplate is the loadbalancer config template
@@ -59,6 +63,18 @@ backend kube-apiservers
   {{range $server, $address := .BackendServers}}
   server {{ $server }} {{ $address }} check check-ssl verify none
   {{- end}}
+
+frontend konnectivity
+  bind *:{{ .KonnectivityPort }}
+  {{ if .IPv6 -}}
+  bind :::{{ .KonnectivityPort }};
+  {{- end }}
+  default_backend konnectivity-servers
+
+backend konnectivity-servers
+  {{range $server, $address := .KonnectivityServers}}
+  server {{ $server }} {{ $address }}
+  {{- end}}
 `
 
 // Config returns a kubeadm config generated from config data, in particular
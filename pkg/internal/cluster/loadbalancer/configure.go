@@ -0,0 +1,76 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadbalancer
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/internal/cluster/konnectivity"
+	"sigs.k8s.io/kind/pkg/internal/cluster/providers/provider/common"
+)
+
+// Configure writes the config balancing across the current addresses of
+// controlPlanes to the loadBalancer node, and reloads it
+func Configure(loadBalancer nodes.Node, controlPlanes []nodes.Node, ipv6 bool) error {
+	// collect info about the existing controlplane nodes
+	var backendServers = map[string]string{}
+	var konnectivityServers = map[string]string{}
+	for _, n := range controlPlanes {
+		controlPlaneIPv4, controlPlaneIPv6, err := n.IP()
+		if err != nil {
+			return errors.Wrapf(err, "failed to get IP for node %s", n.String())
+		}
+		if controlPlaneIPv4 != "" && !ipv6 {
+			backendServers[n.String()] = fmt.Sprintf("%s:%d", controlPlaneIPv4, common.APIServerInternalPort)
+			konnectivityServers[n.String()] = fmt.Sprintf("%s:%d", controlPlaneIPv4, konnectivity.AgentPort)
+		}
+		if controlPlaneIPv6 != "" && ipv6 {
+			backendServers[n.String()] = fmt.Sprintf("[%s]:%d", controlPlaneIPv6, common.APIServerInternalPort)
+			konnectivityServers[n.String()] = fmt.Sprintf("[%s]:%d", controlPlaneIPv6, konnectivity.AgentPort)
+		}
+	}
+
+	// create loadbalancer config data
+	loadbalancerConfig, err := Config(&ConfigData{
+		ControlPlanePort: common.APIServerInternalPort,
+		BackendServers:   backendServers,
+		// the konnectivity servers are only present if enabled, but the
+		// load balancer does not know the cluster config
+		KonnectivityPort:    konnectivity.AgentPort,
+		KonnectivityServers: konnectivityServers,
+		IPv6:                ipv6,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to generate loadbalancer config data")
+	}
+
+	// create loadbalancer config on the node
+	if err := nodeutils.WriteFile(loadBalancer, ConfigPath, loadbalancerConfig); err != nil {
+		// TODO: logging here
+		return errors.Wrap(err, "failed to copy loadbalancer config to node")
+	}
+
+	// reload the config. haproxy will reload on SIGHUP
+	if err := loadBalancer.Command("kill", "-s", "HUP", "1").Run(); err != nil {
+		return errors.Wrap(err, "failed to reload loadbalancer")
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logs
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+// CollectArchive is CollectToWriter compressing the stream with gzip, so
+// that a gzip compressed tarball is written to w as logs are collected
+func CollectArchive(ctx context.Context, nodes []nodes.Node, w io.Writer, opts Options) error {
+	gz := gzip.NewWriter(w)
+	// archive whatever was collected even if some collectors failed
+	err := CollectToWriter(ctx, nodes, gz, opts)
+	if closeErr := gz.Close(); closeErr != nil && err == nil {
+		err = errors.Wrap(closeErr, "failed to write log archive")
+	}
+	return err
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logs
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"testing"
+)
+
+func TestCollectArchive(t *testing.T) {
+	var buff bytes.Buffer
+	// without nodes only the manifest of the export is written
+	opts := Options{Collectors: []string{"version"}}
+	if err := CollectArchive(context.Background(), nil, &buff, opts); err != nil {
+		t.Fatalf("CollectArchive() error = %v", err)
+	}
+
+	gz, err := gzip.NewReader(&buff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gz)
+	names := []string{}
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, hdr.Name)
+		if _, err := ioutil.ReadAll(tr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expected := []string{ManifestFile}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("CollectArchive() entries = %v, expected %v", names, expected)
+	}
+}
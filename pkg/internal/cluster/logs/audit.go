@@ -0,0 +1,125 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logs
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+)
+
+// apiServerManifestPath is the kube-apiserver static pod on control planes
+const apiServerManifestPath = "/etc/kubernetes/manifests/kube-apiserver.yaml"
+
+// staticPod is the subset of a static pod manifest locating its files
+type staticPod struct {
+	Spec struct {
+		Containers []struct {
+			Command      []string `json:"command"`
+			Args         []string `json:"args"`
+			VolumeMounts []struct {
+				Name      string `json:"name"`
+				MountPath string `json:"mountPath"`
+			} `json:"volumeMounts"`
+		} `json:"containers"`
+		Volumes []struct {
+			Name     string `json:"name"`
+			HostPath *struct {
+				Path string `json:"path"`
+			} `json:"hostPath"`
+		} `json:"volumes"`
+	} `json:"spec"`
+}
+
+// auditLogPath returns the path on the node of the audit log the API server
+// in the static pod manifest writes, or "" if it does not write one, EG
+// because auditing is not configured or logs to stdout
+func auditLogPath(manifest []byte) (string, error) {
+	pod := &staticPod{}
+	if err := yaml.Unmarshal(manifest, pod); err != nil {
+		return "", errors.Wrap(err, "failed to parse kube-apiserver manifest")
+	}
+	for _, c := range pod.Spec.Containers {
+		logPath := ""
+		for _, arg := range append(append([]string{}, c.Command...), c.Args...) {
+			if strings.HasPrefix(arg, "--audit-log-path=") {
+				logPath = strings.TrimPrefix(arg, "--audit-log-path=")
+			}
+		}
+		if logPath == "" || logPath == "-" {
+			continue
+		}
+		// the log is only on the node if it is within a hostPath mount,
+		// the most specific mount wins
+		hostPath, mountPath := "", ""
+		for _, m := range c.VolumeMounts {
+			mount := path.Clean(m.MountPath)
+			if logPath != mount && !strings.HasPrefix(logPath, strings.TrimSuffix(mount, "/")+"/") {
+				continue
+			}
+			if len(mount) <= len(mountPath) {
+				continue
+			}
+			for _, v := range pod.Spec.Volumes {
+				if v.Name == m.Name && v.HostPath != nil {
+					hostPath, mountPath = v.HostPath.Path, mount
+				}
+			}
+		}
+		if mountPath == "" {
+			return "", errors.Errorf("audit log %s is not on a hostPath volume", logPath)
+		}
+		return path.Join(hostPath, strings.TrimPrefix(logPath, mountPath)), nil
+	}
+	return "", nil
+}
+
+// dumpAuditLogs dumps the API server audit log on node, a control plane, and
+// the backups rotated from it to the dir hostDir of the export s, if auditing
+// is configured, see untar for chown and filter
+func dumpAuditLogs(ctx context.Context, node nodes.Node, s sink, hostDir string, since time.Time, chown chownFunc, filter *fileFilter) error {
+	var manifest bytes.Buffer
+	if err := exec.CommandWithContext(ctx, node,
+		"sh", "-c", `cat "$1" 2>/dev/null || true`, "sh", apiServerManifestPath,
+	).SetStdout(&manifest).Run(); err != nil {
+		return errors.Wrap(err, "failed to read kube-apiserver manifest")
+	}
+	logPath, err := auditLogPath(manifest.Bytes())
+	if err != nil || logPath == "" {
+		return err
+	}
+	// backups are named <name>-<timestamp><ext> next to the log
+	ext := path.Ext(logPath)
+	stem := strings.TrimSuffix(path.Base(logPath), ext)
+	cmd := exec.CommandWithContext(ctx, node,
+		"sh", "-c",
+		`cd "$1" && find . -maxdepth 1 -type f \( -name "$2$3" -o -name "$2-*$3" \) | tar -cf - -T -`,
+		"sh", path.Dir(logPath), stem, ext,
+	)
+	return exec.RunWithStdoutReader(cmd, func(r io.Reader) error {
+		return errors.Wrap(s.extract(r, hostDir, since, chown, filter), "failed to copy audit logs")
+	})
+}
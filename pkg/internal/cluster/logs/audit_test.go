@@ -0,0 +1,103 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logs
+
+import (
+	"testing"
+)
+
+func TestAuditLogPath(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name        string
+		Manifest    string
+		Expected    string
+		ExpectError bool
+	}{
+		{
+			Name: "auditing not configured",
+			Manifest: `spec:
+  containers:
+  - command:
+    - kube-apiserver
+    - --advertise-address=172.18.0.2
+`,
+		},
+		{
+			Name: "logs to stdout",
+			Manifest: `spec:
+  containers:
+  - command:
+    - kube-apiserver
+    - --audit-log-path=-
+`,
+		},
+		{
+			Name: "hostPath mount",
+			Manifest: `spec:
+  containers:
+  - command:
+    - kube-apiserver
+    - --audit-policy-file=/etc/kubernetes/policies/audit-policy.yaml
+    - --audit-log-path=/var/log/apiserver/audit.log
+    volumeMounts:
+    - mountPath: /var/log
+      name: var-log
+    - mountPath: /var/log/apiserver
+      name: audit-logs
+  volumes:
+  - hostPath:
+      path: /var/log
+    name: var-log
+  - hostPath:
+      path: /var/log/kubernetes
+      type: DirectoryOrCreate
+    name: audit-logs
+`,
+			Expected: "/var/log/kubernetes/audit.log",
+		},
+		{
+			Name: "not on a hostPath",
+			Manifest: `spec:
+  containers:
+  - command:
+    - kube-apiserver
+    - --audit-log-path=/tmp/audit.log
+`,
+			ExpectError: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			result, err := auditLogPath([]byte(tc.Manifest))
+			if tc.ExpectError {
+				if err == nil {
+					t.Fatalf("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result != tc.Expected {
+				t.Errorf("expected %q but got %q", tc.Expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logs
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+	"regexp"
+	"sync"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+// Collector collects an additional artifact from each node, EG the state
+// of a CNI, see RegisterCollector
+type Collector interface {
+	// Name is the unique name of the collector, it selects the collector
+	// like the names in Collectors and names the file the artifact of each
+	// node is written to, <node>/<name>.log
+	Name() string
+	// Collect writes the artifact of node to w, it should stop once ctx is
+	// done, commands run on node are bounded by Options.MaxConcurrency
+	Collect(ctx context.Context, node nodes.Node, w io.Writer) error
+}
+
+// collectorNameRE matches valid collector names, they are used as file names
+var collectorNameRE = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+// registry is the collectors registered with RegisterCollector, in order
+var registry struct {
+	sync.Mutex
+	collectors []Collector
+}
+
+// RegisterCollector adds c to the collectors run by Collect, it is meant to
+// be called during initialization, EG from init
+func RegisterCollector(c Collector) error {
+	name := c.Name()
+	if !collectorNameRE.MatchString(name) {
+		return errors.Errorf("invalid log collector name %q, must be lowercase alphanumerics and dashes", name)
+	}
+	registry.Lock()
+	defer registry.Unlock()
+	for _, known := range allCollectors(registry.collectors) {
+		if name == known {
+			return errors.Errorf("log collector %q is already registered", name)
+		}
+	}
+	registry.collectors = append(registry.collectors, c)
+	return nil
+}
+
+// registered returns the collectors registered with RegisterCollector
+func registered() []Collector {
+	registry.Lock()
+	defer registry.Unlock()
+	return append([]Collector{}, registry.collectors...)
+}
+
+// AllCollectors returns the names of Collectors and of every collector
+// registered with RegisterCollector
+func AllCollectors() []string {
+	return allCollectors(registered())
+}
+
+func allCollectors(extra []Collector) []string {
+	names := append([]string{}, Collectors...)
+	for _, c := range extra {
+		names = append(names, c.Name())
+	}
+	return names
+}
+
+// collectorFn returns a func running c for node, writing the artifact to
+// its file in the export s filtered by filter, if not nil, and recording it
+// in the manifest m
+func collectorFn(ctx context.Context, m *manifest, s sink, c Collector, node nodes.Node, filter *fileFilter) func() error {
+	path := filepath.Join(node.String(), c.Name()+".log")
+	return m.collect(c.Name(), node.String(), path, "", func() error {
+		f, err := s.create(path, false)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w := filter.wrap(f)
+		err = c.Collect(ctx, node, w)
+		// flush what the filters buffered even if the collector failed
+		if closeErr := w.Close(); closeErr != nil && err == nil {
+			return closeErr
+		}
+		return errors.Wrapf(err, "log collector %q failed", c.Name())
+	})
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logs
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+)
+
+type fakeCollector struct {
+	name string
+}
+
+func (c *fakeCollector) Name() string { return c.name }
+
+func (c *fakeCollector) Collect(ctx context.Context, node nodes.Node, w io.Writer) error {
+	_, err := io.WriteString(w, "state of "+node.String())
+	return err
+}
+
+// namedNode is a node only implementing String
+type namedNode struct {
+	nodes.Node
+	name string
+}
+
+func (n *namedNode) String() string { return n.name }
+
+// NOTE: not parallel, the registry is shared with the other tests
+func TestRegisterCollector(t *testing.T) {
+	registry.Lock()
+	saved := registry.collectors
+	registry.collectors = nil
+	registry.Unlock()
+	defer func() {
+		registry.Lock()
+		registry.collectors = saved
+		registry.Unlock()
+	}()
+
+	if err := RegisterCollector(&fakeCollector{name: "cni-state"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, name := range []string{"cni-state", "kubelet", "CNI", "../escape", ""} {
+		if err := RegisterCollector(&fakeCollector{name: name}); err == nil {
+			t.Errorf("expected an error registering %q", name)
+		}
+	}
+	all := AllCollectors()
+	if len(all) != len(Collectors)+1 || all[len(all)-1] != "cni-state" {
+		t.Errorf("expected the builtin collectors and cni-state, got %v", all)
+	}
+	if _, err := collectorSet([]string{"cni-state"}); err != nil {
+		t.Errorf("unexpected error selecting a registered collector: %v", err)
+	}
+}
+
+func TestCollectorFn(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "kind-logs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	m := newManifest(time.Now())
+	fn := collectorFn(context.Background(), m, dirSink(dir), &fakeCollector{name: "cni-state"}, &namedNode{name: "kind-worker"}, nil)
+	if err := fn(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	contents, err := ioutil.ReadFile(filepath.Join(dir, "kind-worker", "cni-state.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(contents), "state of kind-worker") {
+		t.Errorf("unexpected artifact %q", contents)
+	}
+}
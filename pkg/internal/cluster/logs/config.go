@@ -0,0 +1,73 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logs
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+)
+
+// configPaths are the files and dirs on the nodes configuring the control
+// plane components and the kubelet, those missing on a node are skipped,
+// kubeconfigs and certificates are left out as they hold credentials
+var configPaths = []string{
+	"/etc/kubernetes/manifests",
+	"/kind/kubeadm.conf",
+	"/var/lib/kubelet/config.yaml",
+	"/var/lib/kubelet/kubeadm-flags.env",
+	"/etc/default/kubelet",
+	"/etc/systemd/system/kubelet.service.d",
+}
+
+// kubeadmConfigArgs are the kubectl arguments getting the kubeadm
+// ClusterConfiguration ConfigMap, run on a control plane
+var kubeadmConfigArgs = []string{
+	"--kubeconfig=/etc/kubernetes/admin.conf", "--request-timeout=30s",
+	"--namespace=kube-system", "get", "configmap", "kubeadm-config", "--output=yaml",
+}
+
+// dumpConfig dumps the configPaths present on node to the dir hostDir of
+// the export s, keeping their path on the node, see untar for chown and filter
+func dumpConfig(ctx context.Context, node nodes.Node, s sink, hostDir string, chown chownFunc, filter *fileFilter) (err error) {
+	tmp, err := mktemp(ctx, node)
+	if err != nil {
+		return err
+	}
+	// clean up even if ctx is done, so no temp dirs are left on the node
+	defer func() {
+		if rerr := node.Command("rm", "-rf", tmp).Run(); rerr != nil && err == nil {
+			err = rerr
+		}
+	}()
+	if err := exec.CommandWithContext(ctx, node,
+		"sh", append([]string{"-c",
+			`dest="$1"; shift; for p; do [ ! -e "$p" ] || cp -a --parents "$p" "$dest" || exit; done`,
+			"sh", tmp}, configPaths...)...,
+	).Run(); err != nil {
+		return errors.Wrap(err, "failed to copy node configuration")
+	}
+	// the configuration is current state, it is never windowed
+	cmd := exec.CommandWithContext(ctx, node, "tar", "-C", tmp, "-cf", "-", ".")
+	return exec.RunWithStdoutReader(cmd, func(outReader io.Reader) error {
+		return s.extract(outReader, hostDir, time.Time{}, chown, filter)
+	})
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logs
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+// etcdCommands snapshot the etcd member health, cluster membership and
+// raised alarms, Args are those of etcdctl
+var etcdCommands = []snapshotCommand{
+	{File: "endpoint-status.txt", Args: []string{"endpoint", "status", "--write-out=table"}},
+	{File: "member-list.txt", Args: []string{"member", "list", "--write-out=table"}},
+	{File: "alarm-list.txt", Args: []string{"alarm", "list"}},
+}
+
+// etcdFn returns a func dumping the etcdCommands run against the local etcd
+// member of the control plane node, recording each of them in the manifest
+// m of the export s, see untar for filter
+func etcdFn(ctx context.Context, m *manifest, s sink, node nodes.Node, filter *fileFilter) func() error {
+	hostDir := filepath.Join(node.String(), "etcd")
+	for _, c := range etcdCommands {
+		m.source("etcd", node.String(), filepath.Join(hostDir, c.File), "etcdctl "+strings.Join(c.Args, " "))
+	}
+	return m.collect("etcd", node.String(), hostDir, "", func() error {
+		return dumpEtcd(ctx, node, s, hostDir, filter)
+	})
+}
+
+// dumpEtcd runs etcdCommands in the etcd static pod on node writing their
+// output to the dir hostDir of the export s, see untar for filter
+func dumpEtcd(ctx context.Context, node nodes.Node, s sink, hostDir string, filter *fileFilter) error {
+	fns := []func() error{}
+	for _, c := range etcdCommands {
+		c := c // https://golang.org/doc/faq#closures_and_goroutines
+		fns = append(fns, func() error {
+			// etcdctl is run through crictl which is not bound to ctx, at
+			// least don't start once it is done
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			cmd, err := nodeutils.EtcdctlCommand(node, c.Args...)
+			if err != nil {
+				return err
+			}
+			f, err := s.create(filepath.Join(hostDir, c.File), false)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			w := filter.wrap(f)
+			cmd.SetStdout(w)
+			cmd.SetStderr(w)
+			err = cmd.Run()
+			// flush what the filters buffered even if etcdctl failed
+			if closeErr := w.Close(); closeErr != nil && err == nil {
+				return closeErr
+			}
+			return errors.Wrapf(err, "failed to run etcdctl %s", strings.Join(c.Args[:2], " "))
+		})
+	}
+	return errors.AggregateConcurrent(fns...)
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+)
+
+// etcdNode is a node running the etcd static pod if etcdRunning, which
+// answers etcdctl with the command it ran
+type etcdNode struct {
+	namedNode
+	etcdRunning bool
+}
+
+func (n *etcdNode) Command(command string, args ...string) exec.Cmd {
+	return &etcdCmd{node: n, args: append([]string{command}, args...), stdout: ioutil.Discard}
+}
+
+type etcdCmd struct {
+	node   *etcdNode
+	args   []string
+	stdout io.Writer
+}
+
+func (c *etcdCmd) Run() error {
+	line := strings.Join(c.args, " ")
+	var output string
+	switch {
+	case strings.HasPrefix(line, "crictl ps "):
+		if c.node.etcdRunning {
+			output = "etcd0\n"
+		}
+	case line == "crictl exec etcd0 etcdctl version":
+		output = "etcdctl version: 3.4.3\nAPI version: 3.4\n"
+	case strings.HasPrefix(line, "crictl exec -i etcd0 etcdctl "):
+		// echo the etcdctl command without the connection flags, the
+		// client key is the last of them
+		args := c.args
+		for len(args) > 1 && !strings.HasPrefix(args[0], "--key=") {
+			args = args[1:]
+		}
+		output = fmt.Sprintf("etcdctl %s on %s\n", strings.Join(args[1:], " "), c.node.name)
+	default:
+		return &exec.RunError{Command: c.args, Inner: errors.New("unexpected command")}
+	}
+	_, err := io.WriteString(c.stdout, output)
+	return err
+}
+
+func (c *etcdCmd) SetEnv(...string) exec.Cmd      { return c }
+func (c *etcdCmd) SetStdin(io.Reader) exec.Cmd    { return c }
+func (c *etcdCmd) SetStdout(w io.Writer) exec.Cmd { c.stdout = w; return c }
+func (c *etcdCmd) SetStderr(io.Writer) exec.Cmd   { return c }
+
+func TestEtcdFn(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "kind-logs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	m := newManifest(time.Now())
+	node := &etcdNode{namedNode: namedNode{name: "kind-control-plane"}, etcdRunning: true}
+	if err := etcdFn(context.Background(), m, dirSink(dir), node, nil)(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, c := range etcdCommands {
+		path := filepath.Join("kind-control-plane", "etcd", c.File)
+		contents, err := ioutil.ReadFile(filepath.Join(dir, path))
+		if err != nil {
+			t.Fatal(err)
+		}
+		command := "etcdctl " + strings.Join(c.Args, " ")
+		if expected := command + " on kind-control-plane\n"; string(contents) != expected {
+			t.Errorf("%s = %q, expected %q", c.File, contents, expected)
+		}
+		// each file is attributed to its own command rather than the dir
+		if s := m.sourceOf(filepath.ToSlash(path)); s == nil || s.collector != "etcd" || s.command != command {
+			t.Errorf("source of %s = %+v, expected the etcd collector running %q", path, s, command)
+		}
+	}
+	if len(m.Errors) != 0 {
+		t.Errorf("unexpected manifest errors: %v", m.Errors)
+	}
+}
+
+func TestEtcdFnNotRunning(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "kind-logs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	m := newManifest(time.Now())
+	node := &etcdNode{namedNode: namedNode{name: "kind-control-plane"}}
+	if err := etcdFn(context.Background(), m, dirSink(dir), node, nil)(); err == nil {
+		t.Fatalf("expected an error with etcd not running")
+	}
+	if len(m.Errors["etcd"]) == 0 {
+		t.Errorf("expected the error recorded in the manifest, got %v", m.Errors)
+	}
+}
+
+func TestDumpEtcdCancelled(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "kind-logs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	node := &etcdNode{namedNode: namedNode{name: "kind-control-plane"}, etcdRunning: true}
+	if err := dumpEtcd(ctx, node, dirSink(dir), "etcd", nil); err == nil {
+		t.Errorf("expected an error once the context is done")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "etcd")); !os.IsNotExist(err) {
+		t.Errorf("expected nothing dumped once the context is done, got %v", err)
+	}
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logs
+
+import (
+	"archive/tar"
+	"io"
+	"strings"
+)
+
+// compressedExts are the extensions of compressed files, which can neither
+// be redacted nor truncated
+var compressedExts = []string{".gz", ".tgz", ".xz", ".bz2", ".zst", ".zip"}
+
+// fileFilter filters the content of the files written to the export, a nil
+// fileFilter writes them as collected
+type fileFilter struct {
+	// redact scrubs secrets, see redactWriter
+	redact bool
+	// maxSize truncates files to about this many bytes if positive, see
+	// truncateWriter
+	maxSize int64
+}
+
+// newFileFilter returns the fileFilter configured by opts, nil if files are
+// written as collected
+func newFileFilter(opts Options) *fileFilter {
+	if !opts.Redact && opts.MaxFileSize <= 0 {
+		return nil
+	}
+	return &fileFilter{
+		redact:  opts.Redact,
+		maxSize: opts.MaxFileSize,
+	}
+}
+
+// skip returns true if the file extracted from hdr cannot be filtered and
+// is left out of the export: compressed files are not redacted, nor
+// truncated if they are too large
+func (f *fileFilter) skip(hdr *tar.Header) bool {
+	if f == nil || !compressed(hdr.Name) {
+		return false
+	}
+	return f.redact || (f.maxSize > 0 && hdr.Size > f.maxSize)
+}
+
+// changes returns true if the filter may change the content of the regular
+// file hdr, which is otherwise written as is
+func (f *fileFilter) changes(hdr *tar.Header) bool {
+	if f == nil {
+		return false
+	}
+	return f.redact || (f.maxSize > 0 && hdr.Size > f.maxSize)
+}
+
+// wrap returns w wrapped to filter what is written to it, closing the
+// returned writer flushes it but does not close w
+func (f *fileFilter) wrap(w io.Writer) io.WriteCloser {
+	if f == nil {
+		return nopWriteCloser{w}
+	}
+	closers := []io.WriteCloser{}
+	if f.maxSize > 0 {
+		t := newTruncateWriter(w, f.maxSize)
+		closers = append(closers, t)
+		w = t
+	}
+	if f.redact {
+		r := &redactWriter{w: w}
+		closers = append(closers, r)
+		w = r
+	}
+	return &filterWriter{Writer: w, closers: closers}
+}
+
+// compressed returns true if the file name is compressed
+func compressed(name string) bool {
+	for _, ext := range compressedExts {
+		if strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// filterWriter writes to a chain of filters, closing them from the
+// outermost so each flushes into the next
+type filterWriter struct {
+	io.Writer
+	closers []io.WriteCloser
+}
+
+func (f *filterWriter) Close() error {
+	for i := len(f.closers) - 1; i >= 0; i-- {
+		if err := f.closers[i].Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logs
+
+import (
+	"archive/tar"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestTruncateWriter(t *testing.T) {
+	cases := []struct {
+		Name     string
+		MaxSize  int64
+		Writes   []string
+		Expected string
+	}{
+		{
+			Name:     "within limit",
+			MaxSize:  10,
+			Writes:   []string{"0123", "4567"},
+			Expected: "01234567",
+		},
+		{
+			Name:     "at limit",
+			MaxSize:  10,
+			Writes:   []string{"0123456789"},
+			Expected: "0123456789",
+		},
+		{
+			Name:     "head and tail",
+			MaxSize:  6,
+			Writes:   []string{"abc", "defg", "hij", "k"},
+			Expected: "abc\n... [truncated 5 bytes] ...\nijk",
+		},
+		{
+			Name:     "large write",
+			MaxSize:  4,
+			Writes:   []string{strings.Repeat("x", 100) + "yz"},
+			Expected: "xx\n... [truncated 98 bytes] ...\nyz",
+		},
+	}
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			var out bytes.Buffer
+			w := newTruncateWriter(&out, tc.MaxSize)
+			for _, data := range tc.Writes {
+				if n, err := w.Write([]byte(data)); err != nil || n != len(data) {
+					t.Fatalf("unexpected write result: %d, %v", n, err)
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if out.String() != tc.Expected {
+				t.Errorf("expected %q but got %q", tc.Expected, out.String())
+			}
+		})
+	}
+}
+
+func TestTruncateWriterGrowsTail(t *testing.T) {
+	t.Parallel()
+	var out bytes.Buffer
+	w := newTruncateWriter(&out, 2<<20)
+	// fill the head, which is written through, then a little of the tail
+	if _, err := w.Write(make([]byte, 1<<20)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := w.Write([]byte("0123456789")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cap(w.tail) >= 1<<10 {
+		t.Errorf("expected the tail to grow as written, got %d bytes allocated", cap(w.tail))
+	}
+}
+
+func TestFileFilter(t *testing.T) {
+	t.Parallel()
+	var out bytes.Buffer
+	f := newFileFilter(Options{Redact: true, MaxFileSize: 30})
+	w := f.wrap(&out)
+	// secrets are redacted before the file is truncated
+	for _, line := range []string{"token: abcdefghijklmnopqrstuvwxyz\n", "filler line\n", "last line\n"} {
+		if _, err := w.Write([]byte(line)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "token: REDACTED\n... [truncated 8 bytes] ...\nline\nlast line\n"
+	if out.String() != expected {
+		t.Errorf("expected %q but got %q", expected, out.String())
+	}
+
+	if newFileFilter(Options{}) != nil {
+		t.Errorf("expected no filter by default")
+	}
+	for _, tc := range []struct {
+		Filter *fileFilter
+		Header tar.Header
+		Skip   bool
+	}{
+		{Filter: nil, Header: tar.Header{Name: "a.log.gz", Size: 100}},
+		{Filter: &fileFilter{redact: true}, Header: tar.Header{Name: "a.log", Size: 100}},
+		{Filter: &fileFilter{redact: true}, Header: tar.Header{Name: "a.log.gz", Size: 1}, Skip: true},
+		{Filter: &fileFilter{maxSize: 10}, Header: tar.Header{Name: "a.log.gz", Size: 10}},
+		{Filter: &fileFilter{maxSize: 10}, Header: tar.Header{Name: "a.log.gz", Size: 11}, Skip: true},
+	} {
+		if skip := tc.Filter.skip(&tc.Header); skip != tc.Skip {
+			t.Errorf("expected skip(%s, %d bytes) with %+v to be %v", tc.Header.Name, tc.Header.Size, tc.Filter, tc.Skip)
+		}
+	}
+}
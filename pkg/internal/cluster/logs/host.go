@@ -0,0 +1,98 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logs
+
+import (
+	"context"
+	"fmt"
+	osexec "os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/exec"
+)
+
+// recentEvents is how far back docker events are collected when the export
+// is not limited to a window
+const recentEvents = time.Hour
+
+// defaultNetworks are docker's own networks, which are not inspected
+var defaultNetworks = map[string]bool{"bridge": true, "host": true, "none": true}
+
+// hostCommand is a command collecting host diagnostics, and the file its
+// output is written to
+type hostCommand struct {
+	File string
+	Args []string
+}
+
+// hostCommands returns the commands collecting the host container runtime
+// diagnostics, events are limited to the window between since and until,
+// or the recentEvents before now if unset, podman is only queried if it is
+// installed
+func hostCommands(since, until, now time.Time) []hostCommand {
+	if since.IsZero() {
+		since = now.Add(-recentEvents)
+	}
+	if until.IsZero() {
+		until = now
+	}
+	commands := []hostCommand{
+		{File: "docker-info.txt", Args: []string{"docker", "info"}},
+		{File: "docker-version.txt", Args: []string{"docker", "version"}},
+		{File: "docker-system-df.txt", Args: []string{"docker", "system", "df"}},
+		{File: "docker-events.txt", Args: []string{
+			"docker", "events",
+			"--since", fmt.Sprintf("%d", since.Unix()),
+			"--until", fmt.Sprintf("%d", until.Unix()),
+		}},
+	}
+	if _, err := osexec.LookPath("podman"); err == nil {
+		commands = append(commands, hostCommand{File: "podman-info.txt", Args: []string{"podman", "info"}})
+	}
+	return commands
+}
+
+// nodeNetworks returns the docker networks the nodes are attached to, such
+// as the kind network, other than docker's own networks
+func nodeNetworks(ctx context.Context, host exec.Cmder, nodeList []nodes.Node) ([]string, error) {
+	if len(nodeList) == 0 {
+		return nil, nil
+	}
+	args := []string{"inspect", "--format", `{{range $name, $_ := .NetworkSettings.Networks}}{{$name}} {{end}}`}
+	for _, n := range nodeList {
+		args = append(args, n.String())
+	}
+	lines, err := exec.OutputLines(exec.CommandWithContext(ctx, host, "docker", args...))
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	networks := []string{}
+	for _, line := range lines {
+		for _, name := range strings.Fields(line) {
+			if !seen[name] && !defaultNetworks[name] {
+				networks = append(networks, name)
+			}
+			seen[name] = true
+		}
+	}
+	sort.Strings(networks)
+	return networks, nil
+}
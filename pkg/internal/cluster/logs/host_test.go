@@ -0,0 +1,60 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logs
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestHostCommandsEventsWindow(t *testing.T) {
+	t.Parallel()
+	now := time.Unix(1600000000, 0)
+	cases := []struct {
+		Name     string
+		Since    time.Time
+		Until    time.Time
+		Expected []string
+	}{
+		{
+			Name:     "recent events",
+			Expected: []string{"docker", "events", "--since", "1599996400", "--until", "1600000000"},
+		},
+		{
+			Name:     "window",
+			Since:    time.Unix(1500000000, 0),
+			Until:    time.Unix(1500000600, 0),
+			Expected: []string{"docker", "events", "--since", "1500000000", "--until", "1500000600"},
+		},
+	}
+	for _, tc := range cases {
+		found := false
+		for _, c := range hostCommands(tc.Since, tc.Until, now) {
+			if c.File != "docker-events.txt" {
+				continue
+			}
+			found = true
+			if !reflect.DeepEqual(c.Args, tc.Expected) {
+				t.Errorf("%s: expected %v but got %v", tc.Name, tc.Expected, c.Args)
+			}
+		}
+		if !found {
+			t.Errorf("%s: docker events are not collected", tc.Name)
+		}
+	}
+}
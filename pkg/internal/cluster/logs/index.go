@@ -0,0 +1,189 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logs
+
+import (
+	"fmt"
+	"html/template"
+	"sort"
+)
+
+// IndexFile is a self-contained HTML page linking the files of an export,
+// relative to its directory
+const IndexFile = "index.html"
+
+// indexNode groups the artifacts of a node, or the host, in the index
+type indexNode struct {
+	Name      string
+	Artifacts []artifact
+}
+
+// indexData is rendered by indexTemplate
+type indexData struct {
+	Created    string
+	Nodes      []indexNode
+	Collectors []string
+	Errors     map[string][]string
+}
+
+// writeIndex writes IndexFile to the export s, linking the artifacts of the manifest
+// grouped by node, with filtering by collector and a search of the paths
+// and commands, m must have been written first, see write
+func (m *manifest) writeIndex(s sink) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data := indexData{
+		Created: m.Created.Format("2006-01-02 15:04:05 MST"),
+		Errors:  m.Errors,
+	}
+	byNode := map[string]*indexNode{}
+	collectors := map[string]bool{}
+	for _, a := range m.Artifacts {
+		name := a.Node
+		if name == "" {
+			name = "host"
+		}
+		n, ok := byNode[name]
+		if !ok {
+			n = &indexNode{Name: name}
+			byNode[name] = n
+		}
+		n.Artifacts = append(n.Artifacts, a)
+		if a.Collector != "" {
+			collectors[a.Collector] = true
+		}
+	}
+	for _, n := range byNode {
+		data.Nodes = append(data.Nodes, *n)
+	}
+	// the host first, then the nodes by name
+	sort.Slice(data.Nodes, func(i, j int) bool {
+		if (data.Nodes[i].Name == "host") != (data.Nodes[j].Name == "host") {
+			return data.Nodes[i].Name == "host"
+		}
+		return data.Nodes[i].Name < data.Nodes[j].Name
+	})
+	for c := range collectors {
+		data.Collectors = append(data.Collectors, c)
+	}
+	sort.Strings(data.Collectors)
+
+	f, err := s.create(IndexFile, false)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := indexTemplate.Execute(f, data); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// formatSize formats a file size for the index
+func formatSize(b int64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%d B", b)
+	}
+	div, exp := int64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(b)/float64(div), "KMGTPE"[exp])
+}
+
+var indexTemplate = template.Must(template.New("index").Funcs(template.FuncMap{
+	"size": formatSize,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>kind logs {{.Created}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 2em; }
+th, td { text-align: left; padding: 0.2em 0.6em; border-bottom: 1px solid #ddd; }
+td.size { text-align: right; white-space: nowrap; }
+td.command { font-family: monospace; color: #555; }
+.errors { background: #fdd; padding: 0.5em 1em; }
+.hidden { display: none; }
+</style>
+</head>
+<body>
+<h1>kind logs</h1>
+<p>Exported {{.Created}}</p>
+{{- if .Errors}}
+<div class="errors">
+<h2>Errors</h2>
+<ul>
+{{- range $collector, $errs := .Errors}}{{range $errs}}
+<li><b>{{$collector}}</b>: {{.}}</li>
+{{- end}}{{end}}
+</ul>
+</div>
+{{- end}}
+<p>
+<input id="search" type="search" placeholder="Search paths and commands" size="40" autofocus>
+<select id="collector">
+<option value="">all collectors</option>
+{{- range .Collectors}}
+<option>{{.}}</option>
+{{- end}}
+</select>
+</p>
+{{- range .Nodes}}
+<section class="node">
+<h2>{{.Name}}</h2>
+<table>
+<tr><th>File</th><th>Collector</th><th>Command</th><th>Size</th></tr>
+{{- range .Artifacts}}
+<tr class="artifact" data-collector="{{.Collector}}">
+<td><a href="{{.Path}}">{{.Path}}</a></td>
+<td>{{.Collector}}</td>
+<td class="command">{{.Command}}</td>
+<td class="size">{{size .Size}}</td>
+</tr>
+{{- end}}
+</table>
+</section>
+{{- end}}
+<script>
+(function() {
+  var search = document.getElementById("search");
+  var collector = document.getElementById("collector");
+  function filter() {
+    var text = search.value.toLowerCase();
+    var c = collector.value;
+    document.querySelectorAll("section.node").forEach(function(section) {
+      var shown = 0;
+      section.querySelectorAll("tr.artifact").forEach(function(row) {
+        var match = (!c || row.dataset.collector === c) &&
+          row.textContent.toLowerCase().indexOf(text) !== -1;
+        row.classList.toggle("hidden", !match);
+        if (match) { shown++; }
+      });
+      section.classList.toggle("hidden", shown === 0);
+    });
+  }
+  search.addEventListener("input", filter);
+  collector.addEventListener("change", filter);
+})();
+</script>
+</body>
+</html>
+`))
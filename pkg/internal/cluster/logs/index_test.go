@@ -0,0 +1,97 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logs
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteIndex(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kind-logs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"docker-info.txt":                "info",
+		"kind-worker/journal.log":        "journal",
+		"kind-control-plane/<odd>.log":   "odd",
+		"kind-control-plane/kubelet.log": "kubelet",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m := newManifest(time.Time{})
+	m.source("host", "", "docker-info.txt", "docker info")
+	m.source("journal", "kind-worker", "kind-worker/journal.log", "journalctl")
+	m.source("kubelet", "kind-control-plane", "kind-control-plane/kubelet.log", "journalctl -u kubelet.service")
+	_ = m.collect("crictl", "kind-worker", "kind-worker/crictl", "crictl ps", func() error {
+		return errors.New("crictl not found")
+	})()
+	if err := m.write(dirSink(dir)); err != nil {
+		t.Fatalf("write() error = %v", err)
+	}
+	if err := m.writeIndex(dirSink(dir)); err != nil {
+		t.Fatalf("writeIndex() error = %v", err)
+	}
+	// the index is not an artifact of a later export into the same dir
+	if err := m.write(dirSink(dir)); err != nil {
+		t.Fatalf("write() error = %v", err)
+	}
+	for _, a := range m.Artifacts {
+		if a.Path == IndexFile {
+			t.Errorf("expected %s to not be listed as an artifact", IndexFile)
+		}
+	}
+
+	raw, err := ioutil.ReadFile(filepath.Join(dir, IndexFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	index := string(raw)
+	for _, expected := range []string{
+		`<a href="docker-info.txt">docker-info.txt</a>`,
+		`<a href="kind-worker/journal.log">kind-worker/journal.log</a>`,
+		`<a href="kind-control-plane/%3codd%3e.log">kind-control-plane/&lt;odd&gt;.log</a>`,
+		`<option>kubelet</option>`,
+		`<b>crictl</b>: crictl not found`,
+	} {
+		if !strings.Contains(index, expected) {
+			t.Errorf("expected the index to contain %q", expected)
+		}
+	}
+	// the host is listed first, then the nodes by name
+	host := strings.Index(index, "<h2>host</h2>")
+	controlPlane := strings.Index(index, "<h2>kind-control-plane</h2>")
+	worker := strings.Index(index, "<h2>kind-worker</h2>")
+	if host < 0 || !(host < controlPlane && controlPlane < worker) {
+		t.Errorf("unexpected node order: host %d, kind-control-plane %d, kind-worker %d", host, controlPlane, worker)
+	}
+}
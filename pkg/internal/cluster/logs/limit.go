@@ -0,0 +1,115 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logs
+
+import (
+	"context"
+	"io"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/exec"
+)
+
+// limiter bounds how many commands run at once, the nil limiter does not
+type limiter chan struct{}
+
+// newLimiter returns a limiter running at most n commands at once, or none
+// if n is not positive
+func newLimiter(n int) limiter {
+	if n <= 0 {
+		return nil
+	}
+	return make(limiter, n)
+}
+
+// cmder returns cmder with its commands limited by l
+func (l limiter) cmder(cmder exec.Cmder) exec.Cmder {
+	if l == nil {
+		return cmder
+	}
+	return &limitedCmder{Cmder: cmder, limiter: l}
+}
+
+// nodes returns n with their commands limited by l
+func (l limiter) nodes(n []nodes.Node) []nodes.Node {
+	if l == nil {
+		return n
+	}
+	limited := make([]nodes.Node, len(n))
+	for i := range n {
+		limited[i] = &limitedNode{Node: n[i], limiter: l}
+	}
+	return limited
+}
+
+type limitedCmder struct {
+	exec.Cmder
+	limiter limiter
+}
+
+func (c *limitedCmder) Command(command string, args ...string) exec.Cmd {
+	return &limitedCmd{Cmd: c.Cmder.Command(command, args...), limiter: c.limiter}
+}
+
+func (c *limitedCmder) CommandContext(ctx context.Context, command string, args ...string) exec.Cmd {
+	return &limitedCmd{Cmd: exec.CommandWithContext(ctx, c.Cmder, command, args...), limiter: c.limiter}
+}
+
+type limitedNode struct {
+	nodes.Node
+	limiter limiter
+}
+
+func (n *limitedNode) Command(command string, args ...string) exec.Cmd {
+	return &limitedCmd{Cmd: n.Node.Command(command, args...), limiter: n.limiter}
+}
+
+func (n *limitedNode) CommandContext(ctx context.Context, command string, args ...string) exec.Cmd {
+	return &limitedCmd{Cmd: exec.CommandWithContext(ctx, n.Node, command, args...), limiter: n.limiter}
+}
+
+// limitedCmd waits for the limiter to run
+type limitedCmd struct {
+	exec.Cmd
+	limiter limiter
+}
+
+func (c *limitedCmd) Run() error {
+	c.limiter <- struct{}{}
+	defer func() { <-c.limiter }()
+	return c.Cmd.Run()
+}
+
+func (c *limitedCmd) SetEnv(env ...string) exec.Cmd {
+	c.Cmd.SetEnv(env...)
+	return c
+}
+
+func (c *limitedCmd) SetStdin(r io.Reader) exec.Cmd {
+	c.Cmd.SetStdin(r)
+	return c
+}
+
+func (c *limitedCmd) SetStdout(w io.Writer) exec.Cmd {
+	c.Cmd.SetStdout(w)
+	return c
+}
+
+func (c *limitedCmd) SetStderr(w io.Writer) exec.Cmd {
+	c.Cmd.SetStderr(w)
+	return c
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logs
+
+import (
+	"io"
+	"io/ioutil"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+)
+
+// countingCmder records the most commands running at once
+type countingCmder struct {
+	running int32
+	max     int32
+	mu      sync.Mutex
+}
+
+func (c *countingCmder) Command(string, ...string) exec.Cmd {
+	return &countingCmd{c}
+}
+
+type countingCmd struct {
+	c *countingCmder
+}
+
+func (cmd *countingCmd) Run() error {
+	running := atomic.AddInt32(&cmd.c.running, 1)
+	cmd.c.mu.Lock()
+	if running > cmd.c.max {
+		cmd.c.max = running
+	}
+	cmd.c.mu.Unlock()
+	time.Sleep(10 * time.Millisecond)
+	atomic.AddInt32(&cmd.c.running, -1)
+	return nil
+}
+
+func (cmd *countingCmd) SetEnv(...string) exec.Cmd    { return cmd }
+func (cmd *countingCmd) SetStdin(io.Reader) exec.Cmd  { return cmd }
+func (cmd *countingCmd) SetStdout(io.Writer) exec.Cmd { return cmd }
+func (cmd *countingCmd) SetStderr(io.Writer) exec.Cmd { return cmd }
+
+func TestLimiter(t *testing.T) {
+	t.Parallel()
+	counting := &countingCmder{}
+	cmder := newLimiter(2).cmder(counting)
+	fns := []func() error{}
+	for i := 0; i < 8; i++ {
+		fns = append(fns, func() error {
+			// chained setters must keep the limit
+			return cmder.Command("true").SetStdout(ioutil.Discard).Run()
+		})
+	}
+	if err := errors.AggregateConcurrent(fns...); err != nil {
+		t.Fatal(err)
+	}
+	if counting.max > 2 {
+		t.Errorf("expected at most 2 commands at once but got %d", counting.max)
+	}
+}
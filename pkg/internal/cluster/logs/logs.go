@@ -18,131 +18,610 @@ package logs
 
 import (
 	"archive/tar"
+	"context"
+	"encoding/json"
+	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"path"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
 	"sigs.k8s.io/kind/pkg/errors"
 	"sigs.k8s.io/kind/pkg/exec"
 	"sigs.k8s.io/kind/pkg/globals"
+
+	"sigs.k8s.io/kind/pkg/internal/cluster/runtime"
 )
 
+// StateFile records previous exports into a directory, relative to it
+const StateFile = ".kind-export.json"
+
+// podLogDirs hold the pod and container logs on the nodes, they are collected
+// separately from the rest of /var/log as the kubelet rotates the files in
+// them while they are copied, and they may be symlinks to another filesystem
+var podLogDirs = []string{"/var/log/pods", "/var/log/containers"}
+
+// Options configures Collect
+type Options struct {
+	// Since and Until limit the collected logs to a time window if non-zero,
+	// each collector applies this as best it can: journal and container logs
+	// are filtered by entry, files are skipped if last modified before Since
+	Since time.Time
+	Until time.Time
+	// Incremental appends to a previous export into the same directory,
+	// only collecting logs since then, and skipping unchanged files
+	Incremental bool
+	// Collectors limits the export to these of Collectors if non-empty
+	Collectors []string
+	// MaxConcurrency limits how many commands run at once, on the host and
+	// in the nodes, if positive
+	MaxConcurrency int
+	// MapOwnership gives the files extracted from the nodes to the invoking
+	// user, the sudo user if run with sudo, instead of preserving their owner
+	// on the node, ownership is only restored when running as root
+	MapOwnership bool
+	// Redact scrubs bearer and bootstrap tokens, certificates, keys and
+	// kubeconfig credentials from the collected files before they are
+	// written, compressed files cannot be scrubbed and are left out, the
+	// content of other binary files, EG journals, is replaced by a note
+	Redact bool
+	// MaxFileSize truncates collected files larger than this many bytes to
+	// their head and tail if positive, compressed files cannot be truncated
+	// and are left out if larger
+	MaxFileSize int64
+	// SpoolDir is where exports written to a stream keep files larger than
+	// 1 MiB until they are complete, if set, otherwise they are kept in
+	// memory, which MaxFileSize bounds
+	SpoolDir string
+	// HTMLIndex writes IndexFile, a page linking the collected files by
+	// node with filtering and search, for browsing the export
+	HTMLIndex bool
+}
+
+// Collectors are the names of everything Collect gathers:
+//
+//	host          docker info, version, disk usage and recent events, the
+//	              node networks, and podman info if installed
+//	files         /var/log of each node
+//	pods          the pod and container logs of each node
+//	inspect       the node container inspection
+//	serial        the node container output
+//	version       the Kubernetes version of each node
+//	journal       the whole node journal, the list of boots and the
+//	              previous boot if the node container was restarted
+//	kubelet       the kubelet journal
+//	runtime       the container runtime journal, containerd or cri-o
+//	crictl        the runtime's containers, pods and images
+//	cluster-info  kubectl cluster-info dump, from a control plane node
+//	audit         the API server audit log and its backups, if configured
+//	network       iptables and nftables rules, addresses, routes and
+//	              conntrack entries of each node
+//	resources     disk, inode, memory and process usage of each node
+//	etcd          etcd endpoint status, members and alarms, from control
+//	              plane nodes
+//	config        the static pod manifests, kubeadm and kubelet config of
+//	              each node, and the kubeadm ClusterConfiguration
+//
+// More may be added with RegisterCollector
+var Collectors = []string{
+	"host", "files", "pods", "inspect", "serial", "version",
+	"journal", "kubelet", "runtime", "crictl", "cluster-info", "audit",
+	"network", "resources", "etcd", "config",
+}
+
+// collectorSet returns the set of the collectors to run, all of them if
+// collectors is empty, including those registered with RegisterCollector
+func collectorSet(collectors []string) (map[string]bool, error) {
+	set := map[string]bool{}
+	all := AllCollectors()
+	if len(collectors) == 0 {
+		collectors = all
+	}
+	for _, c := range collectors {
+		known := false
+		for _, k := range all {
+			known = known || c == k
+		}
+		if !known {
+			return nil, errors.Errorf("unknown log collector %q, must be one of %s", c, strings.Join(all, ", "))
+		}
+		set[c] = true
+	}
+	return set, nil
+}
+
+// state is the content of StateFile
+type state struct {
+	// Nodes maps node names to the time they were last exported
+	Nodes map[string]time.Time `json:"nodes"`
+}
+
 // Collect collects logs related to / from the cluster nodes and the host
 // system to the specified directory
-func Collect(nodes []nodes.Node, dir string) error {
-	prefixedPath := func(path string) string {
-		return filepath.Join(dir, path)
-	}
-	// helper to run a cmd and write the output to path
-	execToPath := func(cmd exec.Cmd, path string) error {
-		realPath := prefixedPath(path)
-		if err := os.MkdirAll(filepath.Dir(realPath), os.ModePerm); err != nil {
-			return err
-		}
-		f, err := os.Create(realPath)
+// If ctx is done before it completes the commands collecting logs are
+// killed, whatever was collected is kept
+func Collect(ctx context.Context, nodes []nodes.Node, dir string, opts Options) error {
+	return collect(ctx, nodes, dirSink(dir), opts)
+}
+
+// CollectToWriter is Collect writing the export to w as an uncompressed tar
+// stream instead of a directory. Files are held in memory until complete,
+// as their size precedes them in the stream, unless opts.SpoolDir is set,
+// nothing is written to the host filesystem then
+// Incremental exports continue a previous export and are not supported
+func CollectToWriter(ctx context.Context, nodes []nodes.Node, w io.Writer, opts Options) error {
+	if opts.Incremental {
+		return errors.New("incremental log collection requires a directory")
+	}
+	s := newTarSink(w, opts.SpoolDir)
+	err := collect(ctx, nodes, s, opts)
+	// terminate the stream even if collection failed, to keep what was
+	// collected readable
+	if closeErr := s.close(); closeErr != nil && err == nil {
+		err = errors.Wrap(closeErr, "failed to write log archive")
+	}
+	return err
+}
+
+// collect implements Collect, writing the export to s
+func collect(ctx context.Context, nodes []nodes.Node, s sink, opts Options) error {
+	extra := registered()
+	collectors, err := collectorSet(opts.Collectors)
+	if err != nil {
+		return err
+	}
+	// bound the commands collecting the logs, rather than the collectors,
+	// which run commands concurrently themselves
+	lim := newLimiter(opts.MaxConcurrency)
+	host := lim.cmder(exec.DefaultCmder)
+	nodes = lim.nodes(nodes)
+	// everything written is redacted or truncated if requested
+	filter := newFileFilter(opts)
+	// helper to run a cmd and write (or append) the output to path
+	execToPath := func(cmd exec.Cmd, path string, appendTo bool) error {
+		f, err := s.create(path, appendTo)
 		if err != nil {
 			return err
 		}
 		defer f.Close()
-		cmd.SetStdout(f)
-		cmd.SetStderr(f)
-		return cmd.Run()
+		w := filter.wrap(f)
+		cmd.SetStdout(w)
+		cmd.SetStderr(w)
+		// flush what the filters buffered even if the command failed
+		err = cmd.Run()
+		if closeErr := w.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+		return err
+	}
+	// files extracted from the nodes keep their timestamps, and owners if
+	// possible
+	chown := ownership(opts.MapOwnership)
+	// logs are only collected since the previous export when appending
+	exportTime := time.Now()
+	m := newManifest(exportTime)
+	// helpers to run a command with cmder for collector, writing the output
+	// to path, and recording it in the manifest
+	execToPathFn := func(collector, node string, cmder exec.Cmder, path, command string, args ...string) func() error {
+		return m.collect(collector, node, path, exec.PrettyCommand(command, args...), func() error {
+			return execToPath(exec.CommandWithContext(ctx, cmder, command, args...), path, false)
+		})
 	}
-	execToPathFn := func(cmd exec.Cmd, path string) func() error {
-		return func() error {
-			return execToPath(cmd, path)
+	// logs are appended to those of a previous export when incremental
+	logToPathFn := func(collector, node string, cmder exec.Cmder, path, command string, args ...string) func() error {
+		return m.collect(collector, node, path, exec.PrettyCommand(command, args...), func() error {
+			return execToPath(exec.CommandWithContext(ctx, cmder, command, args...), path, opts.Incremental)
+		})
+	}
+
+	// only exports into a directory can be continued
+	dir, isDir := s.(dirSink)
+	previous := &state{Nodes: map[string]time.Time{}}
+	if opts.Incremental && isDir {
+		if previous, err = readState(string(dir)); err != nil {
+			return err
 		}
 	}
 	// construct a slice of methods to collect logs
-	fns := []func() error{
+	fns := []func() error{}
+	if collectors["host"] {
 		// TODO(bentheelder): record the kind version here as well
-		// record info about the host docker
-		execToPathFn(
-			exec.Command("docker", "info"),
-			"docker-info.txt",
-		),
+		// record info about the host container runtime
+		for _, c := range hostCommands(opts.Since, opts.Until, exportTime) {
+			fns = append(fns, execToPathFn("host", "", host, c.File, c.Args[0], c.Args[1:]...))
+		}
+		fns = append(fns, m.collect("host", "", "docker-networks.json", "docker network inspect", func() error {
+			networks, err := nodeNetworks(ctx, host, nodes)
+			if err != nil || len(networks) == 0 {
+				return errors.Wrap(err, "failed to list node networks")
+			}
+			return execToPath(exec.CommandWithContext(ctx, host, "docker", append([]string{"network", "inspect"}, networks...)...), "docker-networks.json", false)
+		}))
 	}
 
-	// collect /var/log for each node and plan collecting more logs
+	// dump the API objects and kube-system pod logs from a control plane,
+	// there may be none among the nodes if they were selected
+	if collectors["cluster-info"] {
+		if controlPlanes, err := nodeutils.ControlPlaneNodes(nodes); err == nil && len(controlPlanes) > 0 {
+			node := controlPlanes[0]
+			fns = append(fns, m.collect("cluster-info", node.String(), "cluster-info", "kubectl cluster-info dump", func() error {
+				return dumpClusterInfo(ctx, node, s, "cluster-info", chown, filter)
+			}))
+		}
+	}
+
+	// the ClusterConfiguration kubeadm uploaded, from a control plane
+	if collectors["config"] {
+		if controlPlanes, err := nodeutils.ControlPlaneNodes(nodes); err == nil && len(controlPlanes) > 0 {
+			node := controlPlanes[0]
+			fns = append(fns, execToPathFn(
+				"config", node.String(), node,
+				"kubeadm-config.yaml",
+				"kubectl", kubeadmConfigArgs...,
+			))
+		}
+	}
+
+	// etcd only runs on control planes, without an external etcd
+	etcdNodes := map[string]bool{}
+	if collectors["etcd"] {
+		controlPlanes, _ := nodeutils.ControlPlaneNodes(nodes)
+		for _, n := range controlPlanes {
+			etcdNodes[n.String()] = true
+		}
+	}
+
+	// look up the runtime of all nodes concurrently, falling back to the
+	// default runtime for nodes where this fails
 	errs := []error{}
-	for _, n := range nodes {
+	runtimes := make([]runtime.Runtime, len(nodes))
+	runtimeFns := []func() error{}
+	for i, n := range nodes {
+		i, node := i, n // capture loop variables
+		runtimeFns = append(runtimeFns, func() error {
+			r, err := runtime.ForNode(node)
+			if err != nil {
+				r = runtime.Containerd
+			}
+			runtimes[i] = r
+			return err
+		})
+	}
+	if err := errors.AggregateConcurrent(runtimeFns...); err != nil {
+		errs = append(errs, err)
+	}
+
+	// collect /var/log for each node and plan collecting more logs
+	for i, n := range nodes {
 		node := n // https://golang.org/doc/faq#closures_and_goroutines
 		name := node.String()
-		if err := dumpDir(n, "/var/log", filepath.Join(dir, name)); err != nil {
-			errs = append(errs, err)
+		since := opts.Since
+		if last, ok := previous.Nodes[name]; ok && last.After(since) {
+			since = last
+		}
+		if collectors["files"] {
+			excludes := []string{}
+			for _, podLogDir := range podLogDirs {
+				excludes = append(excludes, "--exclude=/"+path.Base(podLogDir))
+			}
+			if err := m.collect("files", name, name, "rsync /var/log", func() error {
+				return dumpDir(ctx, n, "/var/log", s, name, since, chown, filter, excludes...)
+			})(); err != nil {
+				errs = append(errs, err)
+			}
 		}
+		if collectors["pods"] {
+			for _, podLogDir := range podLogDirs {
+				hostDir := filepath.Join(name, path.Base(podLogDir))
+				if err := m.collect("pods", name, hostDir, "rsync "+podLogDir, func() error {
+					return dumpPodLogs(ctx, n, podLogDir, s, hostDir, since, chown, filter)
+				})(); err != nil {
+					errs = append(errs, err)
+				}
+			}
+		}
+		r := runtimes[i]
 
-		fns = append(fns, func() error {
-			return errors.AggregateConcurrent(
-				// record info about the node container
-				execToPathFn(
-					exec.Command("docker", "inspect", name),
-					filepath.Join(name, "inspect.json"),
-				),
-				// grab all of the node logs
-				execToPathFn(
-					exec.Command("docker", "logs", name),
-					filepath.Join(name, "serial.log"),
+		nodeFns := []func() error{}
+		if collectors["inspect"] {
+			// record info about the node container
+			nodeFns = append(nodeFns, execToPathFn(
+				"inspect", name, host,
+				filepath.Join(name, "inspect.json"),
+				"docker", "inspect", name,
+			))
+		}
+		// grab all of the node logs
+		if collectors["serial"] {
+			nodeFns = append(nodeFns, logToPathFn(
+				"serial", name, host,
+				filepath.Join(name, "serial.log"),
+				"docker", append([]string{"logs"}, dockerLogsWindow(since, opts.Until, name)...)...,
+			))
+		}
+		if collectors["version"] {
+			nodeFns = append(nodeFns, execToPathFn(
+				"version", name, node,
+				filepath.Join(name, "kubernetes-version.txt"),
+				"cat", "/kind/version",
+			))
+		}
+		if collectors["journal"] {
+			nodeFns = append(nodeFns,
+				logToPathFn(
+					"journal", name, node,
+					filepath.Join(name, "journal.log"),
+					"journalctl", journalWindow(since, opts.Until)...,
 				),
 				execToPathFn(
-					node.Command("cat", "/kind/version"),
-					filepath.Join(name, "kubernetes-version.txt"),
+					"journal", name, node,
+					filepath.Join(name, "journal-boots.txt"),
+					"journalctl", "--list-boots", "--no-pager",
 				),
+				m.collect("journal", name, filepath.Join(name, "journal-previous-boot.log"), "journalctl --boot=-1", func() error {
+					return execToPath(exec.CommandWithContext(ctx, node,
+						"sh", append([]string{"-c", previousBootScript, "sh"}, journalWindow(since, opts.Until)...)...,
+					), filepath.Join(name, "journal-previous-boot.log"), false)
+				}),
+			)
+		}
+		if collectors["kubelet"] {
+			nodeFns = append(nodeFns, logToPathFn(
+				"kubelet", name, node,
+				filepath.Join(name, "kubelet.log"),
+				"journalctl", append(journalWindow(since, opts.Until), "-u", "kubelet.service")...,
+			))
+		}
+		if collectors["runtime"] {
+			nodeFns = append(nodeFns, logToPathFn(
+				"runtime", name, node,
+				filepath.Join(name, r.Service+".log"),
+				"journalctl", append(journalWindow(since, opts.Until), "-u", r.Service+".service")...,
+			))
+		}
+		// snapshot the runtime state
+		if collectors["crictl"] {
+			nodeFns = append(nodeFns,
 				execToPathFn(
-					node.Command("journalctl", "--no-pager"),
-					filepath.Join(name, "journal.log"),
+					"crictl", name, node,
+					filepath.Join(name, "crictl", "ps.txt"),
+					"crictl", "ps", "-a",
 				),
 				execToPathFn(
-					node.Command("journalctl", "--no-pager", "-u", "kubelet.service"),
-					filepath.Join(name, "kubelet.log"),
+					"crictl", name, node,
+					filepath.Join(name, "crictl", "pods.txt"),
+					"crictl", "pods",
 				),
 				execToPathFn(
-					node.Command("journalctl", "--no-pager", "-u", "containerd.service"),
-					filepath.Join(name, "containerd.log"),
+					"crictl", name, node,
+					filepath.Join(name, "crictl", "images.txt"),
+					"crictl", "images",
 				),
+				m.collect("crictl", name, filepath.Join(name, "crictl", "inspect"), "crictl inspect", func() error {
+					ids, err := exec.OutputLines(exec.CommandWithContext(ctx, node, "crictl", "ps", "-a", "--quiet"))
+					if err != nil {
+						return errors.Wrap(err, "failed to list containers")
+					}
+					fns := []func() error{}
+					for _, id := range ids {
+						fns = append(fns, execToPathFn(
+							"crictl", name, node,
+							filepath.Join(name, "crictl", "inspect", id+".json"),
+							"crictl", "inspect", id,
+						))
+					}
+					return errors.AggregateConcurrent(fns...)
+				}),
 			)
+		}
+		// only control planes run the API server, others have no manifest
+		if collectors["audit"] {
+			nodeFns = append(nodeFns, m.collect("audit", name, filepath.Join(name, "audit"), "tar --audit-log-path", func() error {
+				return dumpAuditLogs(ctx, node, s, filepath.Join(name, "audit"), since, chown, filter)
+			}))
+		}
+		if collectors["network"] {
+			nodeFns = append(nodeFns, snapshotFn(ctx, m, s, "network", node, networkCommands, filter))
+		}
+		if collectors["resources"] {
+			nodeFns = append(nodeFns, snapshotFn(ctx, m, s, "resources", node, resourceCommands, filter))
+		}
+		if collectors["etcd"] && etcdNodes[name] {
+			nodeFns = append(nodeFns, etcdFn(ctx, m, s, node, filter))
+		}
+		if collectors["config"] {
+			nodeFns = append(nodeFns, m.collect("config", name, filepath.Join(name, "config"), "cp "+strings.Join(configPaths, " "), func() error {
+				return dumpConfig(ctx, node, s, filepath.Join(name, "config"), chown, filter)
+			}))
+		}
+		for _, c := range extra {
+			if collectors[c.Name()] {
+				nodeFns = append(nodeFns, collectorFn(ctx, m, s, c, node, filter))
+			}
+		}
+		fns = append(fns, func() error {
+			return errors.AggregateConcurrent(nodeFns...)
 		})
 	}
 
-	// run and collect up all errors
+	// run and collect up all errors, summarizing whatever was collected
 	errs = append(errs, errors.AggregateConcurrent(fns...))
-	return errors.NewAggregate(errs)
+	if err := m.write(s); err != nil {
+		errs = append(errs, errors.Wrap(err, "failed to write export manifest"))
+	} else if opts.HTMLIndex {
+		if err := m.writeIndex(s); err != nil {
+			errs = append(errs, errors.Wrap(err, "failed to write export index"))
+		}
+	}
+	// the commands all fail once ctx is done, only report why
+	if ctx.Err() != nil {
+		return errors.Wrap(ctx.Err(), "log collection aborted")
+	}
+	if err := errors.NewAggregate(errs); err != nil {
+		return err
+	}
+
+	// record the export so the next incremental export continues from here,
+	// unless it was bounded or partial in which case later logs or some
+	// collectors were not collected
+	if isDir && opts.Until.IsZero() && len(collectors) == len(Collectors)+len(extra) {
+		for _, n := range nodes {
+			previous.Nodes[n.String()] = exportTime
+		}
+		return writeState(string(dir), previous)
+	}
+	return nil
 }
 
-// dumpDir dumps the dir nodeDir on the node to the dir hostDir on the host
-func dumpDir(node nodes.Node, nodeDir, hostDir string) (err error) {
+// snapshotFn returns a func dumping the output of commands on node for
+// collector, recording each of them in the manifest m of the export s, see
+// untar for filter
+func snapshotFn(ctx context.Context, m *manifest, s sink, collector string, node nodes.Node, commands []snapshotCommand, filter *fileFilter) func() error {
+	hostDir := filepath.Join(node.String(), collector)
+	for _, c := range commands {
+		m.source(collector, node.String(), filepath.Join(hostDir, c.File), exec.PrettyCommand(c.Args[0], c.Args[1:]...))
+	}
+	return m.collect(collector, node.String(), hostDir, "", func() error {
+		return dumpSnapshot(ctx, node, s, hostDir, commands, filter)
+	})
+}
+
+// previousBootScript runs journalctl for the previous boot with the
+// arguments passed to it, only if the node container was restarted as
+// journalctl fails otherwise, the crash causing the restart is in there
+const previousBootScript = `journalctl --list-boots --no-pager | grep -qE '^ *-1 ' || { echo "no previous boot"; exit 0; }; exec journalctl --boot=-1 "$@"`
+
+// journalWindow returns the journalctl arguments limiting it to the window
+func journalWindow(since, until time.Time) []string {
+	args := []string{"--no-pager"}
+	if !since.IsZero() {
+		args = append(args, fmt.Sprintf("--since=@%d", since.Unix()))
+	}
+	if !until.IsZero() {
+		args = append(args, fmt.Sprintf("--until=@%d", until.Unix()))
+	}
+	return args
+}
+
+// dockerLogsWindow returns the docker logs arguments for the container
+// name limited to the window
+func dockerLogsWindow(since, until time.Time, name string) []string {
+	args := []string{}
+	if !since.IsZero() {
+		args = append(args, "--since", since.Format(time.RFC3339Nano))
+	}
+	if !until.IsZero() {
+		args = append(args, "--until", until.Format(time.RFC3339Nano))
+	}
+	return append(args, name)
+}
+
+// readState reads the StateFile in dir, if any
+func readState(dir string) (*state, error) {
+	s := &state{Nodes: map[string]time.Time{}}
+	raw, err := ioutil.ReadFile(filepath.Join(dir, StateFile))
+	if os.IsNotExist(err) {
+		return s, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to read previous export state")
+	}
+	if err := json.Unmarshal(raw, s); err != nil {
+		return nil, errors.Wrap(err, "failed to parse previous export state")
+	}
+	if s.Nodes == nil {
+		s.Nodes = map[string]time.Time{}
+	}
+	return s, nil
+}
+
+// writeState writes s to the StateFile in dir
+func writeState(dir string, s *state) error {
+	raw, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, StateFile), raw, 0644)
+}
+
+// dumpPodLogs dumps the pod log dir nodeDir like dumpDir, following symlinks
+// to the log files and including the rotated files, nodes without the dir
+// are skipped
+func dumpPodLogs(ctx context.Context, node nodes.Node, nodeDir string, s sink, hostDir string, since time.Time, chown chownFunc, filter *fileFilter) error {
+	// the kubelet only creates these once it runs pods
+	if err := exec.CommandWithContext(ctx, node, "test", "-d", nodeDir).Run(); err != nil {
+		return nil
+	}
+	return dumpDir(ctx, node, nodeDir, s, hostDir, since, chown, filter, "--copy-links")
+}
+
+// dumpDir dumps the dir nodeDir on the node to the dir hostDir of the export
+// s, skipping files last modified before since and files unchanged on the host,
+// see untar for chown and filter, rsyncArgs are passed to the rsync
+// snapshotting nodeDir
+func dumpDir(ctx context.Context, node nodes.Node, nodeDir string, s sink, hostDir string, since time.Time, chown chownFunc, filter *fileFilter, rsyncArgs ...string) (err error) {
 	// make tempdir to rsync nodeDir into (rsync handles taking a snapshot better)
-	tmp, err := mktemp(node)
+	tmp, err := mktemp(ctx, node)
 	if err != nil {
 		return err
 	}
+	// clean up even if ctx is done, so no temp dirs are left on the node
 	defer func() {
 		if rerr := node.Command("rm", "-rf", tmp).Run(); rerr != nil && err == nil {
 			err = rerr
 		}
 	}()
 
-	// rsync into the temp dir
-	if err := node.Command("rsync", "--archive", path.Clean(nodeDir)+"/", tmp).Run(); err != nil {
-		return err
+	// rsync into the temp dir, files removed while copying, EG by log
+	// rotation, are not an error (exit code 24)
+	args := append([]string{"--archive"}, rsyncArgs...)
+	args = append(args, path.Clean(nodeDir)+"/", tmp)
+	if err := exec.CommandWithContext(ctx, node,
+		"sh", append([]string{"-c", `rsync "$@" || [ $? -eq 24 ]`, "rsync"}, args...)...,
+	).Run(); err != nil {
+		return errors.Wrapf(err, "failed to snapshot %s", nodeDir)
 	}
 
 	// tar out to the host
-	cmd := node.Command("tar", "--hard-dereference", "-C", tmp, "-chf", "-", ".")
+	cmd := exec.CommandWithContext(ctx, node, "tar", "--hard-dereference", "-C", tmp, "-cf", "-", ".")
 	return exec.RunWithStdoutReader(cmd, func(outReader io.Reader) error {
-		if err := untar(outReader, hostDir); err != nil {
+		if err := s.extract(outReader, hostDir, since, chown, filter); err != nil {
 			return errors.Wrapf(err, "Untarring %q: %v", nodeDir, err)
 		}
 		return nil
 	})
 }
 
+// dumpClusterInfo dumps `kubectl cluster-info dump` run on node, a control
+// plane, to the dir hostDir of the export s
+func dumpClusterInfo(ctx context.Context, node nodes.Node, s sink, hostDir string, chown chownFunc, filter *fileFilter) (err error) {
+	tmp, err := mktemp(ctx, node)
+	if err != nil {
+		return err
+	}
+	// clean up even if ctx is done, so no temp dirs are left on the node
+	defer func() {
+		if rerr := node.Command("rm", "-rf", tmp).Run(); rerr != nil && err == nil {
+			err = rerr
+		}
+	}()
+	if err := exec.CommandWithContext(ctx, node,
+		"kubectl", "--kubeconfig=/etc/kubernetes/admin.conf", "--request-timeout=30s",
+		"cluster-info", "dump", "--output-directory="+tmp,
+	).Run(); err != nil {
+		return errors.Wrap(err, "failed to dump cluster info")
+	}
+	// the dump reflects the current state, it is never windowed
+	return dumpDir(ctx, node, tmp, s, hostDir, time.Time{}, chown, filter)
+}
+
 // mktemp creates a tempdir on the node
-func mktemp(node nodes.Node) (string, error) {
-	lines, err := exec.OutputLines(node.Command("mktemp", "-d"))
+func mktemp(ctx context.Context, node nodes.Node) (string, error) {
+	lines, err := exec.OutputLines(exec.CommandWithContext(ctx, node, "mktemp", "-d"))
 	if err != nil {
 		return "", err
 	}
@@ -152,14 +631,27 @@ func mktemp(node nodes.Node) (string, error) {
 	return lines[0], nil
 }
 
-// untar reads the tar file from r and writes it into dir.
-func untar(r io.Reader, dir string) (err error) {
+// untar reads the tar file from r and writes it into dir, see skipFile,
+// preserving modification times and, if chown is not nil, setting the
+// owner of the files to that returned by chown
+// If filter is not nil the content of the files is filtered with it, those
+// which cannot be are skipped, see fileFilter.skip
+func untar(r io.Reader, dir string, since time.Time, chown chownFunc, filter *fileFilter) (err error) {
 	tr := tar.NewReader(r)
+	// writing into directories changes their modification time, so these
+	// are restored once everything is written
+	dirs := []*tar.Header{}
 	for {
 		f, err := tr.Next()
 
 		switch {
 		case err == io.EOF:
+			for i := len(dirs) - 1; i >= 0; i-- {
+				abs := filepath.Join(dir, filepath.FromSlash(dirs[i].Name))
+				if err := os.Chtimes(abs, dirs[i].ModTime, dirs[i].ModTime); err != nil {
+					return err
+				}
+			}
 			return nil
 		case err != nil:
 			return errors.Wrapf(err, "tar reading error: %v", err)
@@ -169,14 +661,40 @@ func untar(r io.Reader, dir string) (err error) {
 
 		rel := filepath.FromSlash(f.Name)
 		abs := filepath.Join(dir, rel)
+		if !withinDir(dir, abs) {
+			globals.GetLogger().Warnf("tar file entry %s escapes the destination, skipping", f.Name)
+			continue
+		}
+		if throughSymlink(dir, abs) {
+			globals.GetLogger().Warnf("tar file entry %s is beneath a symlink, skipping", f.Name)
+			continue
+		}
 
 		switch f.Typeflag {
 		case tar.TypeReg:
-			wf, err := os.OpenFile(abs, os.O_CREATE|os.O_RDWR, os.FileMode(f.Mode))
+			// replace a link from a previous export rather than writing
+			// through it
+			if isSymlink(abs) {
+				if err := os.Remove(abs); err != nil {
+					return err
+				}
+			}
+			if skipFile(abs, f, since) {
+				continue
+			}
+			if filter.skip(f) {
+				globals.GetLogger().V(1).Infof("tar file entry %s cannot be filtered, skipping", f.Name)
+				continue
+			}
+			wf, err := os.OpenFile(abs, os.O_CREATE|os.O_RDWR|os.O_TRUNC, os.FileMode(f.Mode))
 			if err != nil {
 				return err
 			}
-			n, err := io.Copy(wf, tr)
+			w := filter.wrap(wf)
+			n, err := io.Copy(w, tr)
+			if closeErr := w.Close(); closeErr != nil && err == nil {
+				err = closeErr
+			}
 			if closeErr := wf.Close(); closeErr != nil && err == nil {
 				err = closeErr
 			}
@@ -186,14 +704,126 @@ func untar(r io.Reader, dir string) (err error) {
 			if n != f.Size {
 				return errors.Errorf("only wrote %d bytes to %s; expected %d", n, abs, f.Size)
 			}
+			// preserve the modification time for later incremental exports
+			if err := os.Chtimes(abs, f.ModTime, f.ModTime); err != nil {
+				return err
+			}
+			if err := chownFile(abs, f, chown); err != nil {
+				return err
+			}
 		case tar.TypeDir:
+			if isSymlink(abs) {
+				globals.GetLogger().Warnf("tar file entry %s is a symlink, skipping", f.Name)
+				continue
+			}
 			if _, err := os.Stat(abs); err != nil {
 				if err := os.MkdirAll(abs, 0755); err != nil {
 					return err
 				}
 			}
+			if err := chownFile(abs, f, chown); err != nil {
+				return err
+			}
+			dirs = append(dirs, f)
+		case tar.TypeSymlink:
+			// the link is resolved relative to the entry, it may dangle but
+			// must not point outside of dir
+			if filepath.IsAbs(f.Linkname) || !withinDir(dir, filepath.Join(filepath.Dir(abs), f.Linkname)) {
+				globals.GetLogger().Warnf("tar file entry %s links outside the destination to %s, skipping", f.Name, f.Linkname)
+				continue
+			}
+			if err := replaceWith(abs, func() error { return os.Symlink(f.Linkname, abs) }); err != nil {
+				return err
+			}
+			if err := chownFile(abs, f, chown); err != nil {
+				return err
+			}
+		case tar.TypeLink:
+			// hard links name an earlier entry of the archive
+			target := filepath.Join(dir, filepath.FromSlash(f.Linkname))
+			if !withinDir(dir, target) {
+				globals.GetLogger().Warnf("tar file entry %s links outside the destination to %s, skipping", f.Name, f.Linkname)
+				continue
+			}
+			// the target is not written when it was skipped, see skipFile
+			if _, err := os.Lstat(target); err != nil {
+				continue
+			}
+			if err := replaceWith(abs, func() error { return os.Link(target, abs) }); err != nil {
+				return err
+			}
 		default:
 			globals.GetLogger().Warnf("tar file entry %s contained unsupported file type %v", f.Name, f.Typeflag)
 		}
 	}
 }
+
+// chownFile sets the owner of the file abs extracted from hdr with chown,
+// if not nil, symlinks themselves are changed rather than their target
+func chownFile(abs string, hdr *tar.Header, chown chownFunc) error {
+	if chown == nil {
+		return nil
+	}
+	uid, gid := chown(hdr)
+	return os.Lchown(abs, uid, gid)
+}
+
+// withinDir returns true if path is dir or lexically beneath it
+func withinDir(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// throughSymlink returns true if any of the directories between dir and
+// abs, which is within dir, is a symlink, links are only checked lexically
+// when extracted so a chain of them may lead anywhere, nothing is ever
+// written through them
+func throughSymlink(dir, abs string) bool {
+	rel, err := filepath.Rel(dir, filepath.Dir(abs))
+	if err != nil {
+		return true
+	}
+	if rel == "." {
+		return false
+	}
+	p := dir
+	for _, part := range strings.Split(rel, string(filepath.Separator)) {
+		p = filepath.Join(p, part)
+		if isSymlink(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSymlink returns true if path is a symlink
+func isSymlink(path string) bool {
+	info, err := os.Lstat(path)
+	return err == nil && info.Mode()&os.ModeSymlink != 0
+}
+
+// replaceWith removes any existing file at path, EG from a previous export,
+// before creating the link at path with link
+func replaceWith(path string, link func() error) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return link()
+}
+
+// skipFile returns true if the file described by hdr should not be written
+// to abs, because it was last modified before since or abs is unchanged
+func skipFile(abs string, hdr *tar.Header, since time.Time) bool {
+	if !since.IsZero() && hdr.ModTime.Before(since) {
+		return true
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return false
+	}
+	// tar only records modification times to the second
+	return info.Size() == hdr.Size && info.ModTime().Unix() == hdr.ModTime.Unix()
+}
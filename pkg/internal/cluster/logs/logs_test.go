@@ -0,0 +1,253 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logs
+
+import (
+	"archive/tar"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSkipFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kind-logs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	modTime := time.Date(2019, 11, 5, 10, 0, 0, 0, time.UTC)
+	existing := filepath.Join(dir, "existing.log")
+	if err := ioutil.WriteFile(existing, []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(existing, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		Name     string
+		Path     string
+		Header   tar.Header
+		Since    time.Time
+		Expected bool
+	}{
+		{
+			Name:     "new file",
+			Path:     filepath.Join(dir, "new.log"),
+			Header:   tar.Header{Size: 5, ModTime: modTime},
+			Expected: false,
+		},
+		{
+			Name:     "unchanged file",
+			Path:     existing,
+			Header:   tar.Header{Size: 5, ModTime: modTime},
+			Expected: true,
+		},
+		{
+			Name:     "grown file",
+			Path:     existing,
+			Header:   tar.Header{Size: 10, ModTime: modTime.Add(time.Minute)},
+			Expected: false,
+		},
+		{
+			Name:     "modified before since",
+			Path:     filepath.Join(dir, "new.log"),
+			Header:   tar.Header{Size: 5, ModTime: modTime},
+			Since:    modTime.Add(time.Hour),
+			Expected: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			if result := skipFile(tc.Path, &tc.Header, tc.Since); result != tc.Expected {
+				t.Errorf("skipFile() = %v, expected %v", result, tc.Expected)
+			}
+		})
+	}
+}
+
+func TestUntarLinks(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kind-logs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	dest := filepath.Join(dir, "dest")
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	entries := []tar.Header{
+		{Name: "journal/", Typeflag: tar.TypeDir, Mode: 0755},
+		{Name: "journal/system.journal", Typeflag: tar.TypeReg, Mode: 0644, Size: 5},
+		{Name: "current", Typeflag: tar.TypeSymlink, Linkname: "journal/system.journal"},
+		{Name: "dangling", Typeflag: tar.TypeSymlink, Linkname: "journal/rotated.journal"},
+		{Name: "hardlink", Typeflag: tar.TypeLink, Linkname: "journal/system.journal"},
+		{Name: "absolute", Typeflag: tar.TypeSymlink, Linkname: "/etc/passwd"},
+		{Name: "escaping", Typeflag: tar.TypeSymlink, Linkname: "journal/../../outside"},
+		{Name: "escaping-hardlink", Typeflag: tar.TypeLink, Linkname: "../outside"},
+		{Name: "../outside", Typeflag: tar.TypeReg, Mode: 0644, Size: 5},
+		// each link is within dest, but the second resolves through the
+		// first to the parent of dest
+		{Name: "d/", Typeflag: tar.TypeDir, Mode: 0755},
+		{Name: "d2/", Typeflag: tar.TypeDir, Mode: 0755},
+		{Name: "d/l", Typeflag: tar.TypeSymlink, Linkname: ".."},
+		{Name: "d2/l2", Typeflag: tar.TypeSymlink, Linkname: "../d/l/.."},
+		{Name: "d2/l2/evil", Typeflag: tar.TypeReg, Mode: 0644, Size: 5},
+	}
+	for i := range entries {
+		if err := tw.WriteHeader(&entries[i]); err != nil {
+			t.Fatal(err)
+		}
+		if entries[i].Size > 0 {
+			if _, err := tw.Write([]byte("hello")); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		t.Fatal(err)
+	}
+	// untar twice, as incremental exports do, links must be replaced
+	for i := 0; i < 2; i++ {
+		if err := untar(bytes.NewReader(buf.Bytes()), dest, time.Time{}, nil, nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	for _, name := range []string{"current", "hardlink"} {
+		contents, err := ioutil.ReadFile(filepath.Join(dest, name))
+		if err != nil || string(contents) != "hello" {
+			t.Errorf("expected %s to read the journal, got %q, %v", name, contents, err)
+		}
+	}
+	if target, err := os.Readlink(filepath.Join(dest, "dangling")); err != nil || target != "journal/rotated.journal" {
+		t.Errorf("expected the dangling symlink to be kept, got %q, %v", target, err)
+	}
+	for _, name := range []string{"absolute", "escaping", "escaping-hardlink", "../outside", "../evil"} {
+		if _, err := os.Lstat(filepath.Join(dest, name)); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be skipped, got %v", name, err)
+		}
+	}
+}
+
+func TestUntarTimes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kind-logs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	dirTime := time.Date(2019, 11, 5, 10, 0, 0, 0, time.UTC)
+	fileTime := dirTime.Add(time.Hour)
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	entries := []tar.Header{
+		{Name: "pods/", Typeflag: tar.TypeDir, Mode: 0755, ModTime: dirTime},
+		{Name: "pods/0.log", Typeflag: tar.TypeReg, Mode: 0644, Size: 5, ModTime: fileTime},
+	}
+	for i := range entries {
+		if err := tw.WriteHeader(&entries[i]); err != nil {
+			t.Fatal(err)
+		}
+		if entries[i].Size > 0 {
+			if _, err := tw.Write([]byte("hello")); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := untar(&buf, dir, time.Time{}, nil, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for name, expected := range map[string]time.Time{"pods": dirTime, "pods/0.log": fileTime} {
+		info, err := os.Stat(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !info.ModTime().Equal(expected) {
+			t.Errorf("expected %s to be last modified at %v, got %v", name, expected, info.ModTime())
+		}
+	}
+}
+
+func TestJournalWindow(t *testing.T) {
+	since := time.Date(2019, 11, 5, 10, 0, 0, 0, time.UTC)
+	until := since.Add(time.Hour)
+	cases := []struct {
+		Name     string
+		Since    time.Time
+		Until    time.Time
+		Expected []string
+	}{
+		{
+			Name:     "unbounded",
+			Expected: []string{"--no-pager"},
+		},
+		{
+			Name:     "since",
+			Since:    since,
+			Expected: []string{"--no-pager", "--since=@1572948000"},
+		},
+		{
+			Name:     "since and until",
+			Since:    since,
+			Until:    until,
+			Expected: []string{"--no-pager", "--since=@1572948000", "--until=@1572951600"},
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			if result := journalWindow(tc.Since, tc.Until); !reflect.DeepEqual(result, tc.Expected) {
+				t.Errorf("journalWindow() = %v, expected %v", result, tc.Expected)
+			}
+		})
+	}
+}
+
+func TestCollectorSet(t *testing.T) {
+	t.Parallel()
+	all, err := collectorSet(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(all) != len(Collectors) {
+		t.Errorf("expected all %d collectors but got %v", len(Collectors), all)
+	}
+	some, err := collectorSet([]string{"kubelet", "serial"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(some) != 2 || !some["kubelet"] || !some["serial"] {
+		t.Errorf("expected kubelet and serial but got %v", some)
+	}
+	if _, err := collectorSet([]string{"kubelet", "bogus"}); err == nil {
+		t.Errorf("expected an error for an unknown collector")
+	}
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logs
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ManifestFile summarizes an export, relative to its directory
+const ManifestFile = "manifest.json"
+
+// manifest is the content of ManifestFile
+type manifest struct {
+	// Created is when the export was started
+	Created time.Time `json:"created"`
+	// Artifacts are all of the files in the export
+	Artifacts []artifact `json:"artifacts"`
+	// Errors are the errors of each collector that failed, by collector
+	Errors map[string][]string `json:"errors,omitempty"`
+
+	mu      sync.Mutex
+	sources []source
+}
+
+// artifact is a file in the export
+type artifact struct {
+	// Path is relative to the export directory, with forward slashes
+	Path string `json:"path"`
+	// Size is in bytes
+	Size int64 `json:"size"`
+	// Collector produced the file, if it is known
+	Collector string `json:"collector,omitempty"`
+	// Node the file was collected from, empty for the host
+	Node string `json:"node,omitempty"`
+	// Command is the command that produced the file, if it is known
+	Command string `json:"command,omitempty"`
+}
+
+// source is where a file or dir in the export comes from
+type source struct {
+	path      string
+	collector string
+	node      string
+	command   string
+}
+
+func newManifest(created time.Time) *manifest {
+	return &manifest{
+		Created: created,
+		Errors:  map[string][]string{},
+	}
+}
+
+// source records that collector produces path, a file or dir relative to
+// the export dir, on node by running command
+func (m *manifest) source(collector, node, path, command string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sources = append(m.sources, source{
+		path:      filepath.ToSlash(path),
+		collector: collector,
+		node:      node,
+		command:   command,
+	})
+}
+
+// collect records that collector produces path on node by running command,
+// and returns fn recording its error
+func (m *manifest) collect(collector, node, path, command string, fn func() error) func() error {
+	m.source(collector, node, path, command)
+	return func() error {
+		err := fn()
+		if err != nil {
+			m.mu.Lock()
+			m.Errors[collector] = append(m.Errors[collector], err.Error())
+			m.mu.Unlock()
+		}
+		return err
+	}
+}
+
+// write lists the files of the export s as artifacts of the most specific
+// source containing them and writes the manifest to ManifestFile in it
+func (m *manifest) write(s sink) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	files, err := s.files()
+	if err != nil {
+		return err
+	}
+	m.Artifacts = []artifact{}
+	for rel, size := range files {
+		if rel == StateFile || rel == ManifestFile || rel == IndexFile {
+			continue
+		}
+		a := artifact{Path: rel, Size: size}
+		if src := m.sourceOf(rel); src != nil {
+			a.Collector, a.Node, a.Command = src.collector, src.node, src.command
+		}
+		m.Artifacts = append(m.Artifacts, a)
+	}
+	sort.Slice(m.Artifacts, func(i, j int) bool {
+		return m.Artifacts[i].Path < m.Artifacts[j].Path
+	})
+	raw, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	f, err := s.create(ManifestFile, false)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := f.Write(raw); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// sourceOf returns the most specific source of the file rel, if any
+func (m *manifest) sourceOf(rel string) *source {
+	var best *source
+	for i := range m.sources {
+		s := &m.sources[i]
+		if rel != s.path && !strings.HasPrefix(rel, s.path+"/") {
+			continue
+		}
+		if best == nil || len(s.path) > len(best.path) {
+			best = s
+		}
+	}
+	return best
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logs
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestManifest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kind-logs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"docker-info.txt":              "info",
+		"kind-worker/syslog":           "syslog",
+		"kind-worker/journal.log":      "journal",
+		"kind-worker/crictl/ps.txt":    "ps",
+		"kind-worker/crictl/inspect/a": "inspect",
+		StateFile:                      "{}",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m := newManifest(time.Time{})
+	m.source("host", "", "docker-info.txt", "docker info")
+	m.source("files", "kind-worker", "kind-worker", "rsync /var/log")
+	m.source("journal", "kind-worker", "kind-worker/journal.log", "journalctl")
+	m.source("crictl", "kind-worker", "kind-worker/crictl/ps.txt", "crictl ps -a")
+	_ = m.collect("crictl", "kind-worker", "kind-worker/crictl/inspect", "crictl inspect", func() error {
+		return errors.New("failed to list containers")
+	})()
+	if err := m.write(dirSink(dir)); err != nil {
+		t.Fatalf("write() error = %v", err)
+	}
+
+	raw, err := ioutil.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := struct {
+		Artifacts []artifact          `json:"artifacts"`
+		Errors    map[string][]string `json:"errors"`
+	}{}
+	if err := json.Unmarshal(raw, &result); err != nil {
+		t.Fatal(err)
+	}
+	expected := []artifact{
+		{Path: "docker-info.txt", Size: 4, Collector: "host", Command: "docker info"},
+		{Path: "kind-worker/crictl/inspect/a", Size: 7, Collector: "crictl", Node: "kind-worker", Command: "crictl inspect"},
+		{Path: "kind-worker/crictl/ps.txt", Size: 2, Collector: "crictl", Node: "kind-worker", Command: "crictl ps -a"},
+		{Path: "kind-worker/journal.log", Size: 7, Collector: "journal", Node: "kind-worker", Command: "journalctl"},
+		{Path: "kind-worker/syslog", Size: 6, Collector: "files", Node: "kind-worker", Command: "rsync /var/log"},
+	}
+	if !reflect.DeepEqual(result.Artifacts, expected) {
+		t.Errorf("expected artifacts %+v but got %+v", expected, result.Artifacts)
+	}
+	if errs := result.Errors["crictl"]; len(errs) != 1 {
+		t.Errorf("expected one crictl error but got %v", result.Errors)
+	}
+}
@@ -0,0 +1,169 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logs
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+)
+
+// ArchiveName is the name of the archive written to outputs naming a
+// directory, such as s3://bucket/run123/
+const ArchiveName = "logs.tar.gz"
+
+// Output is where an archived export is written, see Archive
+type Output interface {
+	// Open returns a writer for the archive, the archive is only complete
+	// once the writer is closed without error
+	Open(ctx context.Context) (io.WriteCloser, error)
+	// String returns the location of the archive
+	String() string
+}
+
+// ParseOutput returns the output at location, one of:
+//
+//	s3://<bucket>/<key> uploaded with the aws cli
+//	gs://<bucket>/<object> uploaded with gsutil
+//	http(s)://<url> uploaded with an HTTP PUT, EG a presigned URL
+//	a local file path
+//
+// Object storage and HTTP locations ending in / are a directory the
+// archive is written into as ArchiveName
+func ParseOutput(location string) Output {
+	remote := location
+	if strings.HasSuffix(remote, "/") {
+		remote += ArchiveName
+	}
+	switch {
+	case strings.HasPrefix(location, "s3://"):
+		return &commandOutput{location: remote, command: []string{"aws", "s3", "cp", "-", remote}}
+	case strings.HasPrefix(location, "gs://"):
+		return &commandOutput{location: remote, command: []string{"gsutil", "cp", "-", remote}}
+	case strings.HasPrefix(location, "http://"), strings.HasPrefix(location, "https://"):
+		return &httpOutput{url: remote}
+	}
+	return fileOutput(location)
+}
+
+// fileOutput is a local archive file
+type fileOutput string
+
+func (o fileOutput) Open(context.Context) (io.WriteCloser, error) {
+	f, err := os.Create(string(o))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create logs archive")
+	}
+	return f, nil
+}
+
+func (o fileOutput) String() string {
+	return string(o)
+}
+
+// commandOutput is uploaded by a command reading the archive from stdin
+type commandOutput struct {
+	location string
+	command  []string
+}
+
+func (o *commandOutput) Open(ctx context.Context) (io.WriteCloser, error) {
+	return startPipe(ctx, func(ctx context.Context, r io.Reader) error {
+		cmd := exec.CommandContext(ctx, o.command[0], o.command[1:]...)
+		if err := cmd.SetStdin(r).Run(); err != nil {
+			return errors.Wrapf(err, "failed to upload logs archive to %s", o.location)
+		}
+		return nil
+	}), nil
+}
+
+func (o *commandOutput) String() string {
+	return o.location
+}
+
+// httpOutput is uploaded with an HTTP PUT
+type httpOutput struct {
+	url string
+}
+
+func (o *httpOutput) Open(ctx context.Context) (io.WriteCloser, error) {
+	return startPipe(ctx, func(ctx context.Context, r io.Reader) error {
+		req, err := http.NewRequest(http.MethodPut, o.url, r)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/gzip")
+		resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+		if err != nil {
+			return errors.Wrap(err, "failed to upload logs archive")
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return errors.Errorf("failed to upload logs archive: unexpected status %s", resp.Status)
+		}
+		return nil
+	}), nil
+}
+
+func (o *httpOutput) String() string {
+	return o.url
+}
+
+// pipeWriter streams what is written to it to an upload reading the other
+// end of the pipe, closing it waits for the upload to complete
+type pipeWriter struct {
+	*io.PipeWriter
+	cancel context.CancelFunc
+	done   chan error
+}
+
+// startPipe runs upload with the reading end of a new pipeWriter, the
+// upload is canceled through its ctx if the pipeWriter is closed with an
+// error, so that it does not complete with a truncated archive
+func startPipe(ctx context.Context, upload func(context.Context, io.Reader) error) *pipeWriter {
+	ctx, cancel := context.WithCancel(ctx)
+	r, w := io.Pipe()
+	p := &pipeWriter{PipeWriter: w, cancel: cancel, done: make(chan error, 1)}
+	go func() {
+		err := upload(ctx, r)
+		// unblock writes if the upload stopped reading early
+		r.CloseWithError(errors.New("logs archive upload stopped"))
+		p.done <- err
+	}()
+	return p
+}
+
+func (p *pipeWriter) Close() error {
+	defer p.cancel()
+	if err := p.PipeWriter.Close(); err != nil {
+		return err
+	}
+	return <-p.done
+}
+
+// CloseWithError aborts the upload with err, waiting for it to stop
+func (p *pipeWriter) CloseWithError(err error) error {
+	p.cancel()
+	_ = p.PipeWriter.CloseWithError(err)
+	<-p.done
+	return nil
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logs
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestParseOutput(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Location string
+		Expected Output
+	}{
+		{
+			Location: "s3://bucket/run123/",
+			Expected: &commandOutput{
+				location: "s3://bucket/run123/logs.tar.gz",
+				command:  []string{"aws", "s3", "cp", "-", "s3://bucket/run123/logs.tar.gz"},
+			},
+		},
+		{
+			Location: "gs://bucket/run123.tar.gz",
+			Expected: &commandOutput{
+				location: "gs://bucket/run123.tar.gz",
+				command:  []string{"gsutil", "cp", "-", "gs://bucket/run123.tar.gz"},
+			},
+		},
+		{
+			Location: "https://example.com/upload?sig=abc",
+			Expected: &httpOutput{url: "https://example.com/upload?sig=abc"},
+		},
+		{
+			Location: "/tmp/logs.tar.gz",
+			Expected: fileOutput("/tmp/logs.tar.gz"),
+		},
+	}
+	for _, tc := range cases {
+		if actual := ParseOutput(tc.Location); !reflect.DeepEqual(actual, tc.Expected) {
+			t.Errorf("ParseOutput(%q) = %#v, expected %#v", tc.Location, actual, tc.Expected)
+		}
+	}
+}
+
+func TestHTTPOutput(t *testing.T) {
+	t.Parallel()
+	uploaded := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		uploaded <- string(body)
+	}))
+	defer server.Close()
+
+	w, err := ParseOutput(server.URL + "/run123/").Open(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := io.WriteString(w, "archive"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if body := <-uploaded; body != "archive" {
+		t.Errorf("expected the archive to be uploaded, got %q", body)
+	}
+}
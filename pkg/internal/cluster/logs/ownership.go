@@ -0,0 +1,59 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logs
+
+import (
+	"archive/tar"
+	"os"
+	"strconv"
+)
+
+// chownFunc returns the owner to give the file extracted from the tar entry
+// hdr
+type chownFunc func(hdr *tar.Header) (uid, gid int)
+
+// ownership returns how untar sets the owner of the files it extracts, nil
+// if it cannot: only root may change the owner of files, it then preserves
+// the owner on the node, or with mapToUser gives them to the invoking user,
+// the sudo user when run with sudo
+func ownership(mapToUser bool) chownFunc {
+	if os.Geteuid() != 0 {
+		return nil
+	}
+	if !mapToUser {
+		return func(hdr *tar.Header) (int, int) {
+			return hdr.Uid, hdr.Gid
+		}
+	}
+	uid, gid := invokingUser()
+	return func(*tar.Header) (int, int) {
+		return uid, gid
+	}
+}
+
+// invokingUser returns the user and group ids of the user invoking kind,
+// the user running sudo if it was run with sudo
+func invokingUser() (uid, gid int) {
+	uid, gid = os.Getuid(), os.Getgid()
+	if sudoUID, err := strconv.Atoi(os.Getenv("SUDO_UID")); err == nil {
+		uid = sudoUID
+	}
+	if sudoGID, err := strconv.Atoi(os.Getenv("SUDO_GID")); err == nil {
+		gid = sudoGID
+	}
+	return uid, gid
+}
@@ -0,0 +1,199 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logs
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+)
+
+// redacted replaces the secrets scrubbed from exported files
+const redacted = "REDACTED"
+
+// maxRedactLine bounds how much of a line is buffered before it is redacted,
+// longer lines are redacted in pieces
+const maxRedactLine = 1 << 20
+
+// binarySniffLen is how much of a file is checked for a NUL byte to detect
+// binary files, as git does
+const binarySniffLen = 8000
+
+// binaryRedacted replaces the content of binary files, which secrets cannot
+// reliably be scrubbed from without corrupting them
+const binaryRedacted = "binary file " + redacted + ", it cannot be scrubbed of secrets\n"
+
+// redactRule replaces the matches of re with replacement, see
+// regexp.Regexp.ReplaceAll
+type redactRule struct {
+	re          *regexp.Regexp
+	replacement []byte
+}
+
+// redactRules scrub secrets from a line, PEM blocks spanning lines are
+// handled by redactWriter
+var redactRules = []redactRule{
+	// PEM blocks on a single line, EG escaped in JSON
+	{
+		re:          regexp.MustCompile(`-----BEGIN ([A-Z0-9 ]+)-----.*?-----END [A-Z0-9 ]+-----`),
+		replacement: []byte("-----BEGIN $1-----" + redacted + "-----END $1-----"),
+	},
+	// base64 encoded PEM blocks, EG client-key-data in a kubeconfig
+	{
+		re:          regexp.MustCompile(`LS0tLS1CRUdJTi[A-Za-z0-9+/=]*`),
+		replacement: []byte(redacted),
+	},
+	// kubeconfig and other credentials as yaml, json or flags
+	{
+		re: regexp.MustCompile(
+			`(?i)("?(?:client-key-data|client-certificate-data|certificate-authority-data|` +
+				`token|id-token|refresh-token|client-secret|password)"?\s*[:=]\s*"?)[^"\s,}]+`,
+		),
+		replacement: []byte("${1}" + redacted),
+	},
+	{
+		re:          regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9\-._~+/]+=*`),
+		replacement: []byte("${1}" + redacted),
+	},
+	// service account and other JWTs
+	{
+		re:          regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`),
+		replacement: []byte(redacted),
+	},
+	// bootstrap tokens, see kubeadm token
+	{
+		re:          regexp.MustCompile(`\b[a-z0-9]{6}\.[a-z0-9]{16}\b`),
+		replacement: []byte(redacted),
+	},
+}
+
+var (
+	pemBeginRE = regexp.MustCompile(`-----BEGIN [A-Z0-9 ]+-----`)
+	pemEndRE   = regexp.MustCompile(`-----END [A-Z0-9 ]+-----`)
+)
+
+// redactWriter scrubs secrets from the lines written to w, see redactRules,
+// the content of PEM blocks is dropped, as is all of binary content
+type redactWriter struct {
+	w     io.Writer
+	buf   []byte
+	inPEM bool
+	// sniffed is set once enough was written to tell if it is binary
+	sniffed bool
+	binary  bool
+}
+
+func (r *redactWriter) Write(b []byte) (int, error) {
+	if r.binary {
+		return len(b), nil
+	}
+	r.buf = append(r.buf, b...)
+	if !r.sniffed {
+		if len(r.buf) < binarySniffLen && bytes.IndexByte(r.buf, 0) < 0 {
+			return len(b), nil
+		}
+		if err := r.sniff(); err != nil {
+			return 0, err
+		}
+		if r.binary {
+			return len(b), nil
+		}
+	}
+	if err := r.writeLines(); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// Close writes any trailing partial line
+func (r *redactWriter) Close() error {
+	if !r.sniffed {
+		if err := r.sniff(); err != nil {
+			return err
+		}
+	}
+	if r.binary {
+		return nil
+	}
+	if err := r.writeLines(); err != nil {
+		return err
+	}
+	if len(r.buf) == 0 {
+		return nil
+	}
+	err := r.writeLine(r.buf)
+	r.buf = nil
+	return err
+}
+
+// writeLines writes the complete lines buffered, and the start of any line
+// too long to buffer
+func (r *redactWriter) writeLines() error {
+	for {
+		i := bytes.IndexByte(r.buf, '\n')
+		if i < 0 {
+			if len(r.buf) < maxRedactLine {
+				return nil
+			}
+			i = len(r.buf) - 1
+		}
+		if err := r.writeLine(r.buf[:i+1]); err != nil {
+			return err
+		}
+		r.buf = r.buf[i+1:]
+	}
+}
+
+// sniff detects binary content by a NUL byte in its start, replacing it
+func (r *redactWriter) sniff() error {
+	r.sniffed = true
+	start := r.buf
+	if len(start) > binarySniffLen {
+		start = start[:binarySniffLen]
+	}
+	if bytes.IndexByte(start, 0) < 0 {
+		return nil
+	}
+	r.binary = true
+	r.buf = nil
+	_, err := io.WriteString(r.w, binaryRedacted)
+	return err
+}
+
+func (r *redactWriter) writeLine(line []byte) error {
+	if r.inPEM {
+		loc := pemEndRE.FindIndex(line)
+		if loc == nil {
+			return nil
+		}
+		r.inPEM = false
+		if _, err := r.w.Write([]byte(redacted + "\n")); err != nil {
+			return err
+		}
+		line = line[loc[0]:]
+	}
+	for _, rule := range redactRules {
+		line = rule.re.ReplaceAll(line, rule.replacement)
+	}
+	// the block continues on the following lines, only its header is kept
+	if loc := pemBeginRE.FindIndex(line); loc != nil && pemEndRE.FindIndex(line[loc[1]:]) == nil {
+		r.inPEM = true
+		line = append(line[:loc[1]:loc[1]], '\n')
+	}
+	_, err := r.w.Write(line)
+	return err
+}
@@ -0,0 +1,384 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logs
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/globals"
+)
+
+// sink receives the files of an export, paths are relative to the root of
+// the export
+type sink interface {
+	// create returns a writer for the file at path, appending to any
+	// previous content if appendTo, the file is complete once it is closed
+	create(path string, appendTo bool) (io.WriteCloser, error)
+	// extract writes the files of the tar stream r beneath the dir at path,
+	// see untar
+	extract(r io.Reader, path string, since time.Time, chown chownFunc, filter *fileFilter) error
+	// files returns the sizes of the regular files exported so far, by
+	// path with forward slashes
+	files() (map[string]int64, error)
+}
+
+// dirSink writes an export into a directory
+type dirSink string
+
+func (d dirSink) create(path string, appendTo bool) (io.WriteCloser, error) {
+	realPath := filepath.Join(string(d), path)
+	if err := os.MkdirAll(filepath.Dir(realPath), os.ModePerm); err != nil {
+		return nil, err
+	}
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if appendTo {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+	return os.OpenFile(realPath, flags, 0666)
+}
+
+func (d dirSink) extract(r io.Reader, path string, since time.Time, chown chownFunc, filter *fileFilter) error {
+	dir := filepath.Join(string(d), path)
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+	return untar(r, dir, since, chown, filter)
+}
+
+func (d dirSink) files() (map[string]int64, error) {
+	files := map[string]int64{}
+	err := filepath.Walk(string(d), func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(string(d), p)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = info.Size()
+		return nil
+	})
+	return files, err
+}
+
+// tarSink writes an export as a tar stream, one file at a time.
+// Files extracted unchanged are streamed straight through as their size is
+// known, other files are spooled until complete as tar headers precede the
+// content, see spool
+// An export streamed cannot be appended to, so appendTo is ignored
+type tarSink struct {
+	mu sync.Mutex
+	tw *tar.Writer
+	// spoolDir is where large files are spooled, see spool
+	spoolDir string
+	written  map[string]int64
+	// links are the symlinks written, entries beneath them are not, see
+	// throughSymlink
+	links map[string]bool
+}
+
+func newTarSink(w io.Writer, spoolDir string) *tarSink {
+	return &tarSink{
+		tw:       tar.NewWriter(w),
+		spoolDir: spoolDir,
+		written:  map[string]int64{},
+		links:    map[string]bool{},
+	}
+}
+
+// close finishes the tar stream, not the underlying writer
+func (t *tarSink) close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.tw.Close()
+}
+
+func (t *tarSink) create(path string, appendTo bool) (io.WriteCloser, error) {
+	return &tarFile{sink: t, name: filepath.ToSlash(path), spool: spool{dir: t.spoolDir}}, nil
+}
+
+func (t *tarSink) extract(r io.Reader, dir string, since time.Time, chown chownFunc, filter *fileFilter) error {
+	dir = filepath.ToSlash(dir)
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrapf(err, "tar reading error: %v", err)
+		}
+		rel := path.Clean(hdr.Name)
+		if path.IsAbs(rel) || rel == ".." || strings.HasPrefix(rel, "../") {
+			globals.GetLogger().Warnf("tar file entry %s escapes the destination, skipping", hdr.Name)
+			continue
+		}
+		if t.beneathLink(path.Join(dir, rel)) {
+			globals.GetLogger().Warnf("tar file entry %s is beneath a symlink, skipping", hdr.Name)
+			continue
+		}
+		out := &tar.Header{
+			Typeflag: hdr.Typeflag,
+			Name:     path.Join(dir, rel),
+			Mode:     hdr.Mode,
+			ModTime:  hdr.ModTime,
+			Uid:      hdr.Uid,
+			Gid:      hdr.Gid,
+			Uname:    hdr.Uname,
+			Gname:    hdr.Gname,
+		}
+		if chown != nil {
+			out.Uid, out.Gid = chown(hdr)
+			out.Uname, out.Gname = "", ""
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeReg:
+			if !since.IsZero() && hdr.ModTime.Before(since) {
+				continue
+			}
+			if filter.skip(hdr) {
+				globals.GetLogger().V(1).Infof("tar file entry %s cannot be filtered, skipping", hdr.Name)
+				continue
+			}
+			if !filter.changes(hdr) {
+				out.Size = hdr.Size
+				if err := t.add(out, tr); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := t.addFiltered(out, tr, hdr.Size, filter); err != nil {
+				return err
+			}
+		case tar.TypeDir:
+			out.Name += "/"
+			if err := t.add(out, nil); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			// the link is resolved relative to the entry, it may dangle but
+			// must not point outside of dir
+			target := path.Join(path.Dir(rel), hdr.Linkname)
+			if path.IsAbs(hdr.Linkname) || target == ".." || strings.HasPrefix(target, "../") {
+				globals.GetLogger().Warnf("tar file entry %s links outside the destination to %s, skipping", hdr.Name, hdr.Linkname)
+				continue
+			}
+			out.Linkname = hdr.Linkname
+			if err := t.add(out, nil); err != nil {
+				return err
+			}
+		case tar.TypeLink:
+			// hard links name an earlier entry of the archive, which is not
+			// written when it was skipped
+			target := path.Clean(hdr.Linkname)
+			if path.IsAbs(target) || target == ".." || strings.HasPrefix(target, "../") {
+				globals.GetLogger().Warnf("tar file entry %s links outside the destination to %s, skipping", hdr.Name, hdr.Linkname)
+				continue
+			}
+			out.Linkname = path.Join(dir, target)
+			if err := t.add(out, nil); err != nil {
+				return err
+			}
+		default:
+			globals.GetLogger().Warnf("tar file entry %s contained unsupported file type %v", hdr.Name, hdr.Typeflag)
+		}
+	}
+}
+
+func (t *tarSink) files() (map[string]int64, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	files := make(map[string]int64, len(t.written))
+	for name, size := range t.written {
+		files[name] = size
+	}
+	return files, nil
+}
+
+// addFiltered writes the entry hdr with the size bytes of content passed
+// through filter to the stream, spooling it as the filtered size is only
+// known once complete
+func (t *tarSink) addFiltered(hdr *tar.Header, content io.Reader, size int64, filter *fileFilter) error {
+	s := &spool{dir: t.spoolDir}
+	defer s.remove()
+	w := filter.wrap(s)
+	n, err := io.Copy(w, content)
+	if closeErr := w.Close(); closeErr != nil && err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return errors.Errorf("error writing %s: %v", hdr.Name, err)
+	}
+	if n != size {
+		return errors.Errorf("only wrote %d bytes to %s; expected %d", n, hdr.Name, size)
+	}
+	r, err := s.reader()
+	if err != nil {
+		return errors.Wrapf(err, "failed to read back %s", hdr.Name)
+	}
+	hdr.Size = s.size
+	return t.add(hdr, r)
+}
+
+// add writes the entry hdr with its hdr.Size bytes of content to the
+// stream, content is nil for entries without any. The stream is locked
+// while content is read, so other files wait for it.
+// Hard links to entries not written are left out
+func (t *tarSink) add(hdr *tar.Header, content io.Reader) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if hdr.Typeflag == tar.TypeLink {
+		size, ok := t.written[hdr.Linkname]
+		if !ok {
+			return nil
+		}
+		t.written[hdr.Name] = size
+	}
+	if content == nil {
+		hdr.Size = 0
+	}
+	if err := t.tw.WriteHeader(hdr); err != nil {
+		return errors.Wrapf(err, "failed to write %s", hdr.Name)
+	}
+	if content != nil {
+		if _, err := io.CopyN(t.tw, content, hdr.Size); err != nil {
+			return errors.Wrapf(err, "failed to write %s", hdr.Name)
+		}
+	}
+	switch hdr.Typeflag {
+	case tar.TypeReg:
+		t.written[hdr.Name] = hdr.Size
+	case tar.TypeSymlink:
+		t.links[hdr.Name] = true
+	}
+	return nil
+}
+
+// beneathLink returns true if any of the parents of the entry name is a
+// symlink written to the stream
+func (t *tarSink) beneathLink(name string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for p := path.Dir(name); p != "." && p != "/"; p = path.Dir(p) {
+		if t.links[p] {
+			return true
+		}
+	}
+	return false
+}
+
+// tarFile is a file created in a tarSink, spooled until it is closed and
+// then written to the stream
+type tarFile struct {
+	sink   *tarSink
+	name   string
+	spool  spool
+	closed bool
+}
+
+func (f *tarFile) Write(b []byte) (int, error) {
+	return f.spool.Write(b)
+}
+
+// Close writes the file, it may be called more than once
+func (f *tarFile) Close() error {
+	if f.closed {
+		return nil
+	}
+	f.closed = true
+	defer f.spool.remove()
+	r, err := f.spool.reader()
+	if err != nil {
+		return errors.Wrapf(err, "failed to read back %s", f.name)
+	}
+	return f.sink.add(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     f.name,
+		Mode:     0644,
+		ModTime:  time.Now(),
+		Size:     f.spool.size,
+	}, r)
+}
+
+// maxSpoolMemory is how much of a file spool buffers in memory if it has a
+// dir to move it to
+const maxSpoolMemory = 1 << 20
+
+// spool buffers what is written to it in memory. If dir is set it moves it
+// to a temporary file in dir once it outgrows maxSpoolMemory, so that
+// exporting large files does not exhaust memory, otherwise nothing is
+// written to disk
+type spool struct {
+	dir  string
+	buf  bytes.Buffer
+	file *os.File
+	size int64
+}
+
+func (s *spool) Write(b []byte) (int, error) {
+	if s.dir != "" && s.file == nil && s.buf.Len()+len(b) > maxSpoolMemory {
+		f, err := ioutil.TempFile(s.dir, "kind-logs-spool-")
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to create spool file")
+		}
+		s.file = f
+		if _, err := s.buf.WriteTo(f); err != nil {
+			return 0, errors.Wrap(err, "failed to write spool file")
+		}
+	}
+	var n int
+	var err error
+	if s.file != nil {
+		n, err = s.file.Write(b)
+	} else {
+		n, err = s.buf.Write(b)
+	}
+	s.size += int64(n)
+	return n, err
+}
+
+// reader returns a reader of everything written to the spool
+func (s *spool) reader() (io.Reader, error) {
+	if s.file == nil {
+		return &s.buf, nil
+	}
+	if _, err := s.file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return s.file, nil
+}
+
+// remove removes any temporary file, it may be called more than once
+func (s *spool) remove() {
+	if s.file != nil {
+		s.file.Close()
+		os.Remove(s.file.Name())
+		s.file = nil
+	}
+	s.buf.Reset()
+}
@@ -0,0 +1,241 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logs
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTarSink(t *testing.T) {
+	var in bytes.Buffer
+	tw := tar.NewWriter(&in)
+	modTime := time.Date(2019, 11, 5, 10, 0, 0, 0, time.UTC)
+	entries := []tar.Header{
+		{Name: "./", Typeflag: tar.TypeDir, Mode: 0755, ModTime: modTime},
+		{Name: "./current", Typeflag: tar.TypeReg, Mode: 0644, Size: 5, ModTime: modTime},
+		{Name: "./old", Typeflag: tar.TypeReg, Mode: 0644, Size: 5, ModTime: modTime.Add(-time.Hour)},
+		{Name: "./hardlink", Typeflag: tar.TypeLink, Linkname: "./current", ModTime: modTime},
+		{Name: "./old-hardlink", Typeflag: tar.TypeLink, Linkname: "./old", ModTime: modTime},
+		{Name: "./escaping", Typeflag: tar.TypeSymlink, Linkname: "../../etc/passwd", ModTime: modTime},
+		{Name: "../outside", Typeflag: tar.TypeReg, Mode: 0644, Size: 5, ModTime: modTime},
+		{Name: "./l", Typeflag: tar.TypeSymlink, Linkname: ".", ModTime: modTime},
+		{Name: "./l/evil", Typeflag: tar.TypeReg, Mode: 0644, Size: 5, ModTime: modTime},
+	}
+	for i := range entries {
+		if err := tw.WriteHeader(&entries[i]); err != nil {
+			t.Fatal(err)
+		}
+		if entries[i].Size > 0 {
+			if _, err := tw.Write([]byte("hello")); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	s := newTarSink(&out, "")
+	if err := s.extract(&in, "node/files", modTime.Add(-time.Minute), nil, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	f, err := s.create("manifest.json", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write([]byte("{}")); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.close(); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := s.files()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]int64{"node/files/current": 5, "node/files/hardlink": 5, "manifest.json": 2}
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("files() = %v, expected %v", files, expected)
+	}
+
+	names := []string{}
+	tr := tar.NewReader(&out)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, hdr.Name)
+		if hdr.Name == "node/files/current" {
+			if contents, err := ioutil.ReadAll(tr); err != nil || string(contents) != "hello" {
+				t.Errorf("expected current to read hello, got %q, %v", contents, err)
+			}
+			if !hdr.ModTime.Equal(modTime) {
+				t.Errorf("expected current to be last modified at %v, got %v", modTime, hdr.ModTime)
+			}
+		}
+		if hdr.Name == "node/files/hardlink" && hdr.Linkname != "node/files/current" {
+			t.Errorf("expected hardlink to link node/files/current, got %q", hdr.Linkname)
+		}
+	}
+	expectedNames := []string{"node/files/", "node/files/current", "node/files/hardlink", "node/files/l", "manifest.json"}
+	if !reflect.DeepEqual(names, expectedNames) {
+		t.Errorf("expected entries %v, got %v", expectedNames, names)
+	}
+}
+
+func TestTarSinkFiltered(t *testing.T) {
+	var in bytes.Buffer
+	tw := tar.NewWriter(&in)
+	content := bytes.Repeat([]byte("0123456789"), 10)
+	for _, name := range []string{"small", "large"} {
+		size := len(content)
+		if name == "small" {
+			size = 10
+		}
+		if err := tw.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(size)}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(content[:size]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	s := newTarSink(&out, "")
+	if err := s.extract(&in, "node", time.Time{}, nil, &fileFilter{maxSize: 20}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := s.close(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		// written through unchanged
+		"node/small": "0123456789",
+		"node/large": "0123456789\n... [truncated 80 bytes] ...\n0123456789",
+	}
+	tr := tar.NewReader(&out)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		contents, err := ioutil.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(contents) != expected[hdr.Name] {
+			t.Errorf("expected %s to read %q, got %q", hdr.Name, expected[hdr.Name], contents)
+		}
+		delete(expected, hdr.Name)
+	}
+	if len(expected) != 0 {
+		t.Errorf("expected entries %v to be written", expected)
+	}
+}
+
+func TestSpool(t *testing.T) {
+	cases := []struct {
+		Name       string
+		Size       int
+		Dir        string
+		ExpectFile bool
+	}{
+		{
+			Name: "empty",
+			Dir:  os.TempDir(),
+		},
+		{
+			Name: "in memory",
+			Size: maxSpoolMemory,
+			Dir:  os.TempDir(),
+		},
+		{
+			Name:       "spooled to a file",
+			Size:       maxSpoolMemory + 1,
+			Dir:        os.TempDir(),
+			ExpectFile: true,
+		},
+		{
+			Name: "in memory without a dir",
+			Size: maxSpoolMemory + 1,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			content := bytes.Repeat([]byte("x"), tc.Size)
+			s := &spool{dir: tc.Dir}
+			defer s.remove()
+			// write in chunks so that the spool moves to a file midway
+			for b := content; len(b) > 0; {
+				n := 4096
+				if n > len(b) {
+					n = len(b)
+				}
+				if _, err := s.Write(b[:n]); err != nil {
+					t.Fatal(err)
+				}
+				b = b[n:]
+			}
+			if (s.file != nil) != tc.ExpectFile {
+				t.Errorf("expected spooled to a file: %v", tc.ExpectFile)
+			}
+			if s.size != int64(tc.Size) {
+				t.Errorf("size = %d, expected %d", s.size, tc.Size)
+			}
+			r, err := s.reader()
+			if err != nil {
+				t.Fatal(err)
+			}
+			read, err := ioutil.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(read, content) {
+				t.Errorf("read %d bytes back, expected the %d written", len(read), len(content))
+			}
+			s.remove()
+			if s.file != nil {
+				t.Errorf("expected the spool file to be removed")
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logs
+
+import (
+	"context"
+	"path/filepath"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+)
+
+// snapshotCommand is a command snapshotting node state, and the file its
+// output is written to
+type snapshotCommand struct {
+	File string
+	Args []string
+}
+
+// networkCommands snapshot the node network state
+var networkCommands = []snapshotCommand{
+	{File: "iptables-save.txt", Args: []string{"iptables-save"}},
+	{File: "ip6tables-save.txt", Args: []string{"ip6tables-save"}},
+	{File: "nft-ruleset.txt", Args: []string{"nft", "list", "ruleset"}},
+	{File: "ip-addr.txt", Args: []string{"ip", "addr"}},
+	{File: "ip-route.txt", Args: []string{"ip", "route"}},
+	{File: "ip-6-route.txt", Args: []string{"ip", "-6", "route"}},
+	{File: "conntrack.txt", Args: []string{"conntrack", "-L"}},
+}
+
+// resourceCommands snapshot the node disk, inode, memory and process usage
+var resourceCommands = []snapshotCommand{
+	{File: "df.txt", Args: []string{"df", "-h"}},
+	{File: "df-inodes.txt", Args: []string{"df", "-i"}},
+	{File: "free.txt", Args: []string{"free", "-m"}},
+	{File: "ps.txt", Args: []string{"ps", "auxf"}},
+	{File: "top.txt", Args: []string{"top", "-bn1"}},
+}
+
+// dumpSnapshot runs commands on node writing their output to the dir
+// hostDir of the export s, commands missing from the node image are skipped,
+// see untar for filter
+func dumpSnapshot(ctx context.Context, node nodes.Node, s sink, hostDir string, commands []snapshotCommand, filter *fileFilter) error {
+	fns := []func() error{}
+	for _, c := range commands {
+		c := c // https://golang.org/doc/faq#closures_and_goroutines
+		fns = append(fns, func() error {
+			f, err := s.create(filepath.Join(hostDir, c.File), false)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			w := filter.wrap(f)
+			cmd := exec.CommandWithContext(ctx, node,
+				"sh", append([]string{"-c",
+					`command -v "$1" >/dev/null || { echo "$1 not found"; exit 0; }; exec "$@"`,
+					"sh"}, c.Args...)...,
+			)
+			cmd.SetStdout(w)
+			cmd.SetStderr(w)
+			err = cmd.Run()
+			// flush what the filters buffered even if the command failed
+			if closeErr := w.Close(); closeErr != nil && err == nil {
+				return closeErr
+			}
+			return errors.Wrapf(err, "failed to run %s", c.Args[0])
+		})
+	}
+	return errors.AggregateConcurrent(fns...)
+}
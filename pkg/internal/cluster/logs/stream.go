@@ -0,0 +1,118 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logs
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+)
+
+// StreamOptions configures Stream
+type StreamOptions struct {
+	// Follow keeps streaming new entries until interrupted
+	Follow bool
+	// Since limits the stream to entries written since then if non-zero
+	Since time.Time
+	// Units limits the journal to these systemd units, EG kubelet
+	Units []string
+	// Serial streams the node containers' output instead of their journal
+	Serial bool
+}
+
+// Stream writes the journal of each node to w, each line prefixed with the
+// name of the node it came from
+// If ctx is done before it completes the streaming commands are killed
+func Stream(ctx context.Context, nodes []nodes.Node, w io.Writer, opts StreamOptions) error {
+	// lines from concurrent nodes must not interleave
+	mu := &sync.Mutex{}
+	fns := []func() error{}
+	for _, n := range nodes {
+		node := n // https://golang.org/doc/faq#closures_and_goroutines
+		var cmd exec.Cmd
+		if opts.Serial {
+			args := []string{"logs"}
+			if opts.Follow {
+				args = append(args, "--follow")
+			}
+			cmd = exec.CommandContext(ctx, "docker", append(args, dockerLogsWindow(opts.Since, time.Time{}, node.String())...)...)
+		} else {
+			args := journalWindow(opts.Since, time.Time{})
+			if opts.Follow {
+				args = append(args, "--follow")
+			}
+			for _, unit := range opts.Units {
+				args = append(args, "-u", unit)
+			}
+			cmd = exec.CommandWithContext(ctx, node, "journalctl", args...)
+		}
+		fns = append(fns, func() error {
+			out := &prefixWriter{prefix: []byte(node.String() + " | "), w: w, mu: mu}
+			cmd.SetStdout(out)
+			cmd.SetStderr(out)
+			err := cmd.Run()
+			out.Flush()
+			return errors.Wrapf(err, "failed to stream logs from %s", node)
+		})
+	}
+	return errors.AggregateConcurrent(fns...)
+}
+
+// prefixWriter writes each complete line written to it to w with prefix,
+// holding mu for each line, partial lines are buffered until completed
+type prefixWriter struct {
+	prefix []byte
+	w      io.Writer
+	mu     *sync.Mutex
+	buf    []byte
+}
+
+func (p *prefixWriter) Write(b []byte) (int, error) {
+	p.buf = append(p.buf, b...)
+	for {
+		i := bytes.IndexByte(p.buf, '\n')
+		if i < 0 {
+			return len(b), nil
+		}
+		if err := p.writeLine(p.buf[:i+1]); err != nil {
+			return 0, err
+		}
+		p.buf = p.buf[i+1:]
+	}
+}
+
+// Flush writes any buffered partial line, terminated with a newline
+func (p *prefixWriter) Flush() {
+	if len(p.buf) == 0 {
+		return
+	}
+	_ = p.writeLine(append(p.buf, '\n'))
+	p.buf = nil
+}
+
+func (p *prefixWriter) writeLine(line []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, err := p.w.Write(append(append([]byte{}, p.prefix...), line...))
+	return err
+}
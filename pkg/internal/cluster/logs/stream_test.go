@@ -0,0 +1,51 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logs
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+)
+
+func TestPrefixWriter(t *testing.T) {
+	t.Parallel()
+	var out bytes.Buffer
+	mu := &sync.Mutex{}
+	a := &prefixWriter{prefix: []byte("a | "), w: &out, mu: mu}
+	b := &prefixWriter{prefix: []byte("b | "), w: &out, mu: mu}
+	// partial lines are held back until completed, so lines from concurrent
+	// writers never interleave
+	for _, write := range []struct {
+		w    *prefixWriter
+		data string
+	}{
+		{a, "one\ntw"},
+		{b, "three\n"},
+		{a, "o\nfour"},
+	} {
+		if _, err := write.w.Write([]byte(write.data)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	a.Flush()
+	b.Flush()
+	expected := "a | one\nb | three\na | two\na | four\n"
+	if out.String() != expected {
+		t.Errorf("expected %q but got %q", expected, out.String())
+	}
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logs
+
+import (
+	"fmt"
+	"io"
+)
+
+// truncateWriter writes at most maxSize bytes of what is written to it to
+// w, half from the head and half from the tail, separated by a marker
+// noting how much was left out
+// The head is written through, the tail is buffered until Close
+type truncateWriter struct {
+	w io.Writer
+	// head is how much of the head is left to write
+	head int64
+	// tail is a ring buffer of the last tailSize bytes written, grown as
+	// they are written, next is where the next byte goes once it is full
+	tail     []byte
+	tailSize int
+	next     int
+	full     bool
+	// dropped counts the bytes that did not fit the head or tail
+	dropped int64
+}
+
+func newTruncateWriter(w io.Writer, maxSize int64) *truncateWriter {
+	return &truncateWriter{
+		w:        w,
+		head:     maxSize - maxSize/2,
+		tailSize: int(maxSize / 2),
+	}
+}
+
+func (t *truncateWriter) Write(b []byte) (int, error) {
+	n := len(b)
+	if t.head > 0 {
+		h := b
+		if int64(len(h)) > t.head {
+			h = h[:t.head]
+		}
+		if _, err := t.w.Write(h); err != nil {
+			return 0, err
+		}
+		t.head -= int64(len(h))
+		b = b[len(h):]
+	}
+	size := t.tailSize
+	// only the last bytes can end up in the tail
+	if len(b) > size {
+		t.dropped += int64(len(b) - size)
+		b = b[len(b)-size:]
+	}
+	for len(b) > 0 {
+		if !t.full {
+			free := size - len(t.tail)
+			if free >= len(b) {
+				t.tail = append(t.tail, b...)
+				t.full = len(t.tail) == size
+				break
+			}
+			t.tail = append(t.tail, b[:free]...)
+			t.full = true
+			b = b[free:]
+			continue
+		}
+		// overwrite the oldest bytes
+		c := copy(t.tail[t.next:], b)
+		t.dropped += int64(c)
+		t.next = (t.next + c) % size
+		b = b[c:]
+	}
+	return n, nil
+}
+
+// Close writes the tail, after the truncation marker if anything was
+// dropped
+func (t *truncateWriter) Close() error {
+	if t.dropped > 0 {
+		if _, err := fmt.Fprintf(t.w, "\n... [truncated %d bytes] ...\n", t.dropped); err != nil {
+			return err
+		}
+	}
+	if _, err := t.w.Write(t.tail[t.next:]); err != nil {
+		return err
+	}
+	_, err := t.w.Write(t.tail[:t.next])
+	t.tail = t.tail[:0]
+	t.next = 0
+	return err
+}
@@ -0,0 +1,189 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/version"
+
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+// ClientUser is the user the client certificate authenticates as
+const ClientUser = "kind-metrics-reader"
+
+// CACertPath and CAKeyPath are the cluster CA kubeadm generates on the
+// control plane nodes, which the components trust for client certificates
+const (
+	CACertPath = "/etc/kubernetes/pki/ca.crt"
+	CAKeyPath  = "/etc/kubernetes/pki/ca.key"
+)
+
+// RBACManifest authorizes ClientUser to read the metrics and profiling
+// endpoints of the API server, kube-controller-manager and kube-scheduler
+const RBACManifest = `apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: kind-metrics-reader
+rules:
+- nonResourceURLs:
+  - /metrics
+  - /metrics/*
+  - /debug/pprof
+  - /debug/pprof/*
+  verbs:
+  - get
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: kind-metrics-reader
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: kind-metrics-reader
+subjects:
+- apiGroup: rbac.authorization.k8s.io
+  kind: User
+  name: kind-metrics-reader
+`
+
+// certValidity is how long the client certificate is valid for
+const certValidity = 365 * 24 * time.Hour
+
+// minimumVersion is the first version serving the kube-controller-manager
+// and kube-scheduler metrics on their secure ports
+var minimumVersion = version.MustParseSemantic("v1.13.0")
+
+// CheckVersion returns an error if publishing the metrics is not supported
+// by the kubernetes version
+func CheckVersion(kubeVersion string) error {
+	v, err := version.ParseSemantic(kubeVersion)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse kubernetes version %q", kubeVersion)
+	}
+	if v.LessThan(minimumVersion) {
+		return errors.Errorf("controlPlaneMetrics requires kubernetes %s or later, the node image is %s", minimumVersion, kubeVersion)
+	}
+	return nil
+}
+
+// GenerateClientCert generates a client certificate for ClientUser signed by
+// the PEM encoded CA, returning the PEM encoded certificate and key
+func GenerateClientCert(caCertPEM, caKeyPEM []byte) (cert, key []byte, err error) {
+	caCert, caKey, err := parseCA(caCertPEM, caKeyPEM)
+	if err != nil {
+		return nil, nil, err
+	}
+	clientKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to generate client key")
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to generate serial number")
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: ClientUser},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(certValidity),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, caCert, &clientKey.PublicKey, caKey)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to create client certificate")
+	}
+	keyDER, err := x509.MarshalECPrivateKey(clientKey)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to marshal client key")
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+		nil
+}
+
+// parseCA parses the PEM encoded CA certificate and its key
+func parseCA(caCertPEM, caKeyPEM []byte) (*x509.Certificate, crypto.Signer, error) {
+	certBlock, _ := pem.Decode(caCertPEM)
+	if certBlock == nil {
+		return nil, nil, errors.New("failed to decode CA certificate")
+	}
+	caCert, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to parse CA certificate")
+	}
+	keyBlock, _ := pem.Decode(caKeyPEM)
+	if keyBlock == nil {
+		return nil, nil, errors.New("failed to decode CA key")
+	}
+	// kubeadm writes PKCS #1 RSA keys, but external CAs may be anything
+	var caKey interface{}
+	switch keyBlock.Type {
+	case "RSA PRIVATE KEY":
+		caKey, err = x509.ParsePKCS1PrivateKey(keyBlock.Bytes)
+	case "EC PRIVATE KEY":
+		caKey, err = x509.ParseECPrivateKey(keyBlock.Bytes)
+	default:
+		caKey, err = x509.ParsePKCS8PrivateKey(keyBlock.Bytes)
+	}
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to parse CA key")
+	}
+	signer, ok := caKey.(crypto.Signer)
+	if !ok {
+		return nil, nil, errors.Errorf("unsupported CA key type %T", caKey)
+	}
+	return caCert, signer, nil
+}
+
+// Endpoint is a control plane component's endpoint published on the host
+type Endpoint struct {
+	// Component is the control plane component, EG kube-scheduler
+	Component string
+	// Node is the node the component runs on, empty for the API server
+	// which is reached through the cluster's API server endpoint
+	Node string
+	// Address is the host:port the endpoint is published on
+	Address string
+}
+
+// EndpointsFile formats endpoints as the lines of the endpoints file
+func EndpointsFile(endpoints []Endpoint) string {
+	var b strings.Builder
+	for _, e := range endpoints {
+		node := e.Node
+		if node == "" {
+			node = "-"
+		}
+		fmt.Fprintf(&b, "%s\t%s\thttps://%s\n", e.Component, node, e.Address)
+	}
+	return b.String()
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestGenerateClientCert(t *testing.T) {
+	t.Parallel()
+	// a CA like the one kubeadm generates
+	caKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate CA key: %v", err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "kubernetes"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("failed to create CA certificate: %v", err)
+	}
+	caCertPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER})
+	caKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(caKey)})
+
+	certPEM, keyPEM, err := GenerateClientCert(caCertPEM, caKeyPEM)
+	if err != nil {
+		t.Fatalf("GenerateClientCert() error = %v", err)
+	}
+	if block, _ := pem.Decode(keyPEM); block == nil || block.Type != "EC PRIVATE KEY" {
+		t.Errorf("GenerateClientCert() returned an invalid key")
+	}
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		t.Fatalf("GenerateClientCert() returned an invalid certificate")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatalf("failed to parse client certificate: %v", err)
+	}
+	if cert.Subject.CommonName != ClientUser {
+		t.Errorf("client certificate is for %q, expected %q", cert.Subject.CommonName, ClientUser)
+	}
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(caCertPEM)
+	if _, err := cert.Verify(x509.VerifyOptions{
+		Roots:     roots,
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}); err != nil {
+		t.Errorf("client certificate does not verify against the CA: %v", err)
+	}
+
+	if _, _, err := GenerateClientCert(caCertPEM, []byte("bogus")); err == nil {
+		t.Errorf("GenerateClientCert() with a bogus CA key should fail")
+	}
+}
+
+func TestEndpointsFile(t *testing.T) {
+	t.Parallel()
+	actual := EndpointsFile([]Endpoint{
+		{Component: "kube-apiserver", Address: "127.0.0.1:34567"},
+		{Component: "kube-scheduler", Node: "kind-control-plane", Address: "127.0.0.1:45678"},
+	})
+	expected := "kube-apiserver\t-\thttps://127.0.0.1:34567\n" +
+		"kube-scheduler\tkind-control-plane\thttps://127.0.0.1:45678\n"
+	if actual != expected {
+		t.Errorf("EndpointsFile() = %q, expected %q", actual, expected)
+	}
+}
+
+func TestCheckVersion(t *testing.T) {
+	cases := []struct {
+		Version   string
+		ExpectErr bool
+	}{
+		{Version: "v1.12.10", ExpectErr: true},
+		{Version: "v1.13.0"},
+		{Version: "v1.20.2"},
+		{Version: "bogus", ExpectErr: true},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Version, func(t *testing.T) {
+			t.Parallel()
+			if err := CheckVersion(tc.Version); (err != nil) != tc.ExpectErr {
+				t.Errorf("CheckVersion(%q) error = %v, ExpectErr %v", tc.Version, err, tc.ExpectErr)
+			}
+		})
+	}
+}
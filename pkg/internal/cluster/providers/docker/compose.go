@@ -0,0 +1,323 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docker
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+)
+
+// composeVersion is the compose file format version, 2.x rather than 3.x
+// supports all of the network and container settings the nodes use
+const composeVersion = "2.4"
+
+// composeFile is a docker compose file
+type composeFile struct {
+	Version  string                    `json:"version"`
+	Services map[string]composeService `json:"services"`
+	Networks map[string]composeNetwork `json:"networks,omitempty"`
+}
+
+type composeService struct {
+	ContainerName string                   `json:"container_name"`
+	Hostname      string                   `json:"hostname,omitempty"`
+	Image         string                   `json:"image"`
+	Privileged    bool                     `json:"privileged,omitempty"`
+	SecurityOpt   []string                 `json:"security_opt,omitempty"`
+	Tmpfs         []string                 `json:"tmpfs,omitempty"`
+	Volumes       []string                 `json:"volumes,omitempty"`
+	Ports         []string                 `json:"ports,omitempty"`
+	Restart       string                   `json:"restart,omitempty"`
+	Ulimits       map[string]composeUlimit `json:"ulimits,omitempty"`
+	Sysctls       map[string]string        `json:"sysctls,omitempty"`
+	Environment   []string                 `json:"environment,omitempty"`
+	Labels        map[string]string        `json:"labels,omitempty"`
+	NetworkMode   string                   `json:"network_mode,omitempty"`
+	Networks      map[string]composeAttach `json:"networks,omitempty"`
+}
+
+type composeUlimit struct {
+	Soft int64 `json:"soft"`
+	Hard int64 `json:"hard"`
+}
+
+type composeAttach struct {
+	IPv4Address string `json:"ipv4_address,omitempty"`
+	IPv6Address string `json:"ipv6_address,omitempty"`
+}
+
+type composeNetwork struct {
+	External   bool              `json:"external,omitempty"`
+	Driver     string            `json:"driver,omitempty"`
+	DriverOpts map[string]string `json:"driver_opts,omitempty"`
+	EnableIPv6 bool              `json:"enable_ipv6,omitempty"`
+	IPAM       *composeIPAM      `json:"ipam,omitempty"`
+	Labels     map[string]string `json:"labels,omitempty"`
+}
+
+type composeIPAM struct {
+	Config []composeSubnet `json:"config"`
+}
+
+type composeSubnet struct {
+	Subnet  string `json:"subnet"`
+	Gateway string `json:"gateway,omitempty"`
+}
+
+// containerInspect is the subset of `docker inspect` of a node container
+// describing how it was run
+type containerInspect struct {
+	Name   string
+	Config struct {
+		Hostname string
+		Image    string
+		Env      []string
+		Labels   map[string]string
+	}
+	HostConfig struct {
+		Privileged    bool
+		SecurityOpt   []string
+		Tmpfs         map[string]string
+		Binds         []string
+		NetworkMode   string
+		PortBindings  map[string][]struct{ HostIP, HostPort string }
+		Sysctls       map[string]string
+		RestartPolicy struct {
+			Name              string
+			MaximumRetryCount int
+		}
+		Ulimits []struct {
+			Name string
+			Soft int64
+			Hard int64
+		}
+	}
+	Mounts []struct {
+		Type        string
+		Name        string
+		Destination string
+	}
+	NetworkSettings struct {
+		Networks map[string]struct {
+			IPAMConfig *struct {
+				IPv4Address string
+				IPv6Address string
+			}
+		}
+	}
+}
+
+// networkInspect is the subset of `docker network inspect` describing
+// how a network was created
+type networkInspect struct {
+	Name       string
+	Driver     string
+	EnableIPv6 bool
+	IPAM       struct {
+		Config []struct {
+			Subnet  string
+			Gateway string
+		}
+	}
+	Options map[string]string
+	Labels  map[string]string
+}
+
+// defaultNetworks are created by docker itself
+var defaultNetworks = map[string]bool{"bridge": true, "host": true, "none": true}
+
+// anonymousVolumeRE matches the generated names of anonymous volumes
+var anonymousVolumeRE = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// Compose is part of the providers.Provider interface
+func (p *Provider) Compose(n []nodes.Node) ([]byte, error) {
+	if len(n) == 0 {
+		return nil, errors.New("no nodes to describe")
+	}
+	args := []string{"inspect", "--type=container"}
+	for _, node := range n {
+		args = append(args, node.String())
+	}
+	var out bytes.Buffer
+	if err := exec.Command("docker", args...).SetStdout(&out).Run(); err != nil {
+		return nil, errors.Wrap(err, "failed to inspect nodes")
+	}
+	containers := []containerInspect{}
+	if err := json.Unmarshal(out.Bytes(), &containers); err != nil {
+		return nil, errors.Wrap(err, "failed to parse node inspection")
+	}
+
+	// describe the networks the nodes are attached to, other than docker's
+	networkNames := []string{}
+	seen := map[string]bool{}
+	for _, c := range containers {
+		for name := range c.NetworkSettings.Networks {
+			if !seen[name] && !defaultNetworks[name] {
+				networkNames = append(networkNames, name)
+			}
+			seen[name] = true
+		}
+	}
+	networks := []networkInspect{}
+	if len(networkNames) > 0 {
+		sort.Strings(networkNames)
+		var out bytes.Buffer
+		if err := exec.Command("docker", append([]string{"network", "inspect"}, networkNames...)...).SetStdout(&out).Run(); err != nil {
+			return nil, errors.Wrap(err, "failed to inspect node networks")
+		}
+		if err := json.Unmarshal(out.Bytes(), &networks); err != nil {
+			return nil, errors.Wrap(err, "failed to parse node network inspection")
+		}
+	}
+
+	raw, err := yaml.Marshal(compose(containers, networks))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode compose file")
+	}
+	return raw, nil
+}
+
+// compose converts the inspected node containers and networks
+func compose(containers []containerInspect, networks []networkInspect) *composeFile {
+	file := &composeFile{
+		Version:  composeVersion,
+		Services: map[string]composeService{},
+	}
+	for _, c := range containers {
+		name := c.Name
+		if len(name) > 0 && name[0] == '/' {
+			name = name[1:]
+		}
+		s := composeService{
+			ContainerName: name,
+			Image:         c.Config.Image,
+			Privileged:    c.HostConfig.Privileged,
+			SecurityOpt:   c.HostConfig.SecurityOpt,
+			Volumes:       append([]string{}, c.HostConfig.Binds...),
+			Sysctls:       c.HostConfig.Sysctls,
+			Environment:   c.Config.Env,
+			Labels:        c.Config.Labels,
+		}
+		// hostnames of containers sharing the host network are the host's
+		if c.HostConfig.NetworkMode == "host" {
+			s.NetworkMode = "host"
+		} else {
+			s.Hostname = c.Config.Hostname
+		}
+		for path, options := range c.HostConfig.Tmpfs {
+			if options != "" {
+				path += ":" + options
+			}
+			s.Tmpfs = append(s.Tmpfs, path)
+		}
+		sort.Strings(s.Tmpfs)
+		// volumes other than binds, EG the anonymous /var volume
+		for _, m := range c.Mounts {
+			if m.Type != "volume" || bindsDestination(c.HostConfig.Binds, m.Destination) {
+				continue
+			}
+			if anonymousVolumeRE.MatchString(m.Name) {
+				s.Volumes = append(s.Volumes, m.Destination)
+			} else {
+				s.Volumes = append(s.Volumes, m.Name+":"+m.Destination)
+			}
+		}
+		for containerPort, bindings := range c.HostConfig.PortBindings {
+			for _, b := range bindings {
+				port := b.HostPort + ":" + containerPort
+				if b.HostIP != "" {
+					port = b.HostIP + ":" + port
+				}
+				s.Ports = append(s.Ports, port)
+			}
+		}
+		sort.Strings(s.Ports)
+		if policy := c.HostConfig.RestartPolicy; policy.Name != "" {
+			s.Restart = policy.Name
+			if policy.MaximumRetryCount > 0 {
+				s.Restart += ":" + strconv.Itoa(policy.MaximumRetryCount)
+			}
+		}
+		for _, u := range c.HostConfig.Ulimits {
+			if s.Ulimits == nil {
+				s.Ulimits = map[string]composeUlimit{}
+			}
+			s.Ulimits[u.Name] = composeUlimit{Soft: u.Soft, Hard: u.Hard}
+		}
+		for network, settings := range c.NetworkSettings.Networks {
+			if defaultNetworks[network] {
+				continue
+			}
+			if s.Networks == nil {
+				s.Networks = map[string]composeAttach{}
+			}
+			attach := composeAttach{}
+			// only addresses requested when the node was run, others are
+			// dynamically assigned
+			if settings.IPAMConfig != nil {
+				attach.IPv4Address = settings.IPAMConfig.IPv4Address
+				attach.IPv6Address = settings.IPAMConfig.IPv6Address
+			}
+			s.Networks[network] = attach
+		}
+		file.Services[name] = s
+	}
+
+	for _, n := range networks {
+		if file.Networks == nil {
+			file.Networks = map[string]composeNetwork{}
+		}
+		network := composeNetwork{
+			Driver:     n.Driver,
+			DriverOpts: n.Options,
+			EnableIPv6: n.EnableIPv6,
+			Labels:     n.Labels,
+		}
+		if len(network.DriverOpts) == 0 {
+			network.DriverOpts = nil
+		}
+		if len(n.IPAM.Config) > 0 {
+			network.IPAM = &composeIPAM{}
+			for _, c := range n.IPAM.Config {
+				network.IPAM.Config = append(network.IPAM.Config, composeSubnet{Subnet: c.Subnet, Gateway: c.Gateway})
+			}
+		}
+		file.Networks[n.Name] = network
+	}
+	return file
+}
+
+// bindsDestination returns true if one of binds, formatted as
+// source:target[:options], is mounted at destination
+func bindsDestination(binds []string, destination string) bool {
+	for _, b := range binds {
+		if parts := strings.Split(b, ":"); len(parts) >= 2 && parts[1] == destination {
+			return true
+		}
+	}
+	return false
+}
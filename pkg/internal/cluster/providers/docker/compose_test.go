@@ -0,0 +1,99 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docker
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestCompose(t *testing.T) {
+	t.Parallel()
+	// trimmed `docker inspect` output of a control plane node
+	rawContainers := `[{
+		"Name": "/kind-control-plane",
+		"Config": {
+			"Hostname": "kind-control-plane",
+			"Image": "kindest/node:v1.18.2",
+			"Env": ["container=docker"],
+			"Labels": {"io.x-k8s.kind.cluster": "kind", "io.x-k8s.kind.role": "control-plane"}
+		},
+		"HostConfig": {
+			"Privileged": true,
+			"SecurityOpt": ["seccomp=unconfined"],
+			"Tmpfs": {"/run": "", "/tmp": ""},
+			"Binds": ["/lib/modules:/lib/modules:ro"],
+			"NetworkMode": "kind-kind",
+			"PortBindings": {"6443/tcp": [{"HostIp": "127.0.0.1", "HostPort": "45000"}]},
+			"RestartPolicy": {"Name": "on-failure", "MaximumRetryCount": 1},
+			"Ulimits": [{"Name": "nofile", "Soft": 1048576, "Hard": 1048576}]
+		},
+		"Mounts": [
+			{"Type": "bind", "Source": "/lib/modules", "Destination": "/lib/modules"},
+			{"Type": "volume", "Name": "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef", "Destination": "/var"}
+		],
+		"NetworkSettings": {"Networks": {"kind-kind": {"IPAMConfig": null}}}
+	}]`
+	rawNetworks := `[{
+		"Name": "kind-kind",
+		"Driver": "bridge",
+		"EnableIPv6": false,
+		"IPAM": {"Config": [{"Subnet": "172.18.0.0/16", "Gateway": "172.18.0.1"}]},
+		"Options": {},
+		"Labels": {"io.x-k8s.kind.cluster": "kind"}
+	}]`
+	containers := []containerInspect{}
+	if err := json.Unmarshal([]byte(rawContainers), &containers); err != nil {
+		t.Fatalf("failed to parse containers: %v", err)
+	}
+	networks := []networkInspect{}
+	if err := json.Unmarshal([]byte(rawNetworks), &networks); err != nil {
+		t.Fatalf("failed to parse networks: %v", err)
+	}
+
+	expected := &composeFile{
+		Version: composeVersion,
+		Services: map[string]composeService{
+			"kind-control-plane": {
+				ContainerName: "kind-control-plane",
+				Hostname:      "kind-control-plane",
+				Image:         "kindest/node:v1.18.2",
+				Privileged:    true,
+				SecurityOpt:   []string{"seccomp=unconfined"},
+				Tmpfs:         []string{"/run", "/tmp"},
+				Volumes:       []string{"/lib/modules:/lib/modules:ro", "/var"},
+				Ports:         []string{"127.0.0.1:45000:6443/tcp"},
+				Restart:       "on-failure:1",
+				Ulimits:       map[string]composeUlimit{"nofile": {Soft: 1048576, Hard: 1048576}},
+				Environment:   []string{"container=docker"},
+				Labels:        map[string]string{"io.x-k8s.kind.cluster": "kind", "io.x-k8s.kind.role": "control-plane"},
+				Networks:      map[string]composeAttach{"kind-kind": {}},
+			},
+		},
+		Networks: map[string]composeNetwork{
+			"kind-kind": {
+				Driver: "bridge",
+				IPAM:   &composeIPAM{Config: []composeSubnet{{Subnet: "172.18.0.0/16", Gateway: "172.18.0.1"}}},
+				Labels: map[string]string{"io.x-k8s.kind.cluster": "kind"},
+			},
+		},
+	}
+	if result := compose(containers, networks); !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %+v but got %+v", expected, result)
+	}
+}
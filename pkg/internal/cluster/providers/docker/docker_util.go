@@ -19,6 +19,7 @@ package docker
 import (
 	"strings"
 
+	"sigs.k8s.io/kind/pkg/errors"
 	"sigs.k8s.io/kind/pkg/exec"
 )
 
@@ -36,3 +37,34 @@ func usernsRemap() bool {
 	}
 	return false
 }
+
+// checkExperimental returns an error if dockerd does not have experimental
+// features, such as checkpoints, enabled
+func checkExperimental() error {
+	cmd := exec.Command("docker", "version", "--format", "{{.Server.Experimental}}")
+	lines, err := exec.OutputLines(cmd)
+	if err != nil {
+		return errors.Wrap(err, "failed to get docker version")
+	}
+	if len(lines) != 1 || lines[0] != "true" {
+		return errors.New("docker experimental features must be enabled, and CRIU installed, to checkpoint nodes")
+	}
+	return nil
+}
+
+// checkSystemdCgroupDriver returns an error if dockerd does not manage
+// cgroups with systemd, which placing containers in a slice requires
+func checkSystemdCgroupDriver() error {
+	cmd := exec.Command("docker", "info", "--format", "{{.CgroupDriver}}")
+	lines, err := exec.OutputLines(cmd)
+	if err != nil {
+		return errors.Wrap(err, "failed to get docker cgroup driver")
+	}
+	if len(lines) != 1 {
+		return errors.Errorf("docker info should only be one line, got %d lines", len(lines))
+	}
+	if lines[0] != "systemd" {
+		return errors.Errorf("systemdSlice requires the docker systemd cgroup driver, not %q", lines[0])
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docker
+
+import (
+	"io/ioutil"
+	"os"
+	osexec "os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestExecKillScript runs the scripts killing commands in the node locally
+func TestExecKillScript(t *testing.T) {
+	if _, err := osexec.LookPath("pgrep"); err != nil {
+		t.Skip("pgrep is not installed")
+	}
+	dir, err := ioutil.TempDir("", "kind-exec")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	pidFile := filepath.Join(dir, "exec.pid")
+
+	// a command with a child of its own, like the scripts collecting logs
+	cmd := osexec.Command("sh", "-c", execWrapperScript, pidFile, "sh", "-c", "sleep 30; true")
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+
+	if err := osexec.Command("sh", "-c", execKillScript, "sh", pidFile).Run(); err != nil {
+		t.Fatalf("unexpected error killing the command: %v", err)
+	}
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Errorf("expected the command to fail once killed")
+		}
+	case <-time.After(10 * time.Second):
+		_ = cmd.Process.Kill()
+		t.Fatalf("expected the command to be killed")
+	}
+	if _, err := os.Stat(pidFile); !os.IsNotExist(err) {
+		t.Errorf("expected the pid file to be removed, got %v", err)
+	}
+
+	// commands which completed are left alone
+	if err := osexec.Command("sh", "-c", execWrapperScript, pidFile, "true").Run(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := osexec.Command("sh", "-c", execKillScript, "sh", pidFile).Run(); err != nil {
+		t.Errorf("unexpected error killing a completed command: %v", err)
+	}
+}
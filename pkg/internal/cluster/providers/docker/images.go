@@ -21,17 +21,25 @@ import (
 	"strings"
 	"time"
 
+	"sigs.k8s.io/kind/pkg/apis/config/defaults"
+	"sigs.k8s.io/kind/pkg/errors"
 	"sigs.k8s.io/kind/pkg/exec"
 	"sigs.k8s.io/kind/pkg/globals"
 
 	"sigs.k8s.io/kind/pkg/internal/apis/config"
 	"sigs.k8s.io/kind/pkg/internal/cluster/providers/provider/common"
 	"sigs.k8s.io/kind/pkg/internal/util/cli"
+	"sigs.k8s.io/kind/pkg/internal/util/ratelimit"
 )
 
 // ensureNodeImages ensures that the node images used by the create
-// configuration are present
-func ensureNodeImages(status *cli.Status, cfg *config.Cluster) {
+// configuration are present, it only fails if pulls were rate limited,
+// as running the nodes would be too
+func ensureNodeImages(status *cli.Status, cfg *config.Cluster) error {
+	limited := &ratelimit.Error{
+		Pulls:  map[string][]string{},
+		Advice: ratelimit.NodeImageAdvice,
+	}
 	// pull each required image
 	for _, image := range common.RequiredNodeImages(cfg).List() {
 		// prints user friendly message
@@ -42,8 +50,157 @@ func ensureNodeImages(status *cli.Status, cfg *config.Cluster) {
 
 		// attempt to explicitly pull the image if it doesn't exist locally
 		// we don't care if this errors, we'll still try to run which also pulls
-		_, _ = pullIfNotPresent(image, 4)
+		if _, err := pullIfNotPresent(image, 4); ratelimit.Is(err) {
+			limited.Pulls[image] = []string{""}
+		}
+	}
+	if len(limited.Pulls) > 0 {
+		return limited
+	}
+	return nil
+}
+
+// preferDebugImages returns a copy of cfg with each kubernetes node image
+// replaced by its debug variant, if that is present locally
+func preferDebugImages(cfg *config.Cluster) *config.Cluster {
+	cfg = cfg.DeepCopy()
+	for i := range cfg.Nodes {
+		node := &cfg.Nodes[i]
+		if node.Role == config.RegistryRole {
+			continue
+		}
+		debugImage := defaults.DebugImage(node.Image)
+		if err := exec.Command("docker", "inspect", "--type=image", debugImage).Run(); err != nil {
+			globals.GetLogger().V(1).Infof("Debug image %s not present locally, using %s", debugImage, node.Image)
+			continue
+		}
+		node.Image = debugImage
+	}
+	return cfg
+}
+
+// resolvePlatformImages returns a copy of cfg with each kubernetes node
+// image replaced by the ID of its variant for the node's platform, if any
+// node sets one
+// Docker only tags one variant of an image, so the variants are pulled one
+// at a time and every node runs its variant by ID, including those on the
+// default platform, the tag is restored to the variant it pointed at after
+// each pull
+func resolvePlatformImages(status *cli.Status, cfg *config.Cluster) (*config.Cluster, error) {
+	hasPlatform := false
+	for _, node := range cfg.Nodes {
+		hasPlatform = hasPlatform || node.Platform != ""
+	}
+	if !hasPlatform {
+		return cfg, nil
+	}
+	defaultPlatform, err := serverPlatform()
+	if err != nil {
+		return nil, err
+	}
+	cfg = cfg.DeepCopy()
+	ids := map[[2]string]string{}
+	for i := range cfg.Nodes {
+		node := &cfg.Nodes[i]
+		if node.Role == config.RegistryRole {
+			continue
+		}
+		platform := node.Platform
+		if platform == "" {
+			platform = defaultPlatform
+		}
+		key := [2]string{node.Image, platform}
+		if _, ok := ids[key]; !ok {
+			status.Start(fmt.Sprintf("Ensuring node image (%s) for %s 🖼", node.Image, platform))
+			id, err := pullPlatform(node.Image, platform, 4)
+			if err != nil {
+				return nil, err
+			}
+			ids[key] = id
+		}
+		node.Image = ids[key]
+	}
+	return cfg, nil
+}
+
+// serverPlatform returns the os/arch of the docker daemon
+func serverPlatform() (string, error) {
+	cmd := exec.Command("docker", "version", "--format", "{{.Server.Os}}/{{.Server.Arch}}")
+	lines, err := exec.OutputLines(cmd)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get docker server platform")
+	}
+	if len(lines) != 1 {
+		return "", errors.Errorf("docker version should only be one line, got %d lines", len(lines))
+	}
+	return lines[0], nil
+}
+
+// pullPlatform pulls the platform variant of image, retrying up to retries
+// times, and returns its ID
+// Pulling retags image to the variant, so if image was present locally its
+// tag is pointed back at the previous variant before returning
+func pullPlatform(image, platform string, retries int) (id string, err error) {
+	previousID, _ := imageID(image)
+	if previousID != "" {
+		defer func() {
+			if current, _ := imageID(image); current == previousID {
+				return
+			}
+			if tagErr := exec.Command("docker", "tag", previousID, image).Run(); tagErr != nil && err == nil {
+				err = errors.Wrapf(tagErr, "failed to restore tag of image %q", image)
+			}
+		}()
+	}
+	for i := 0; i <= retries; i++ {
+		if i > 0 && ratelimit.Is(err) {
+			time.Sleep(ratelimit.Backoff(i - 1))
+		} else if i > 0 {
+			time.Sleep(time.Second * time.Duration(i))
+			globals.GetLogger().V(1).Infof("Trying again to pull image: %q for %s ... %v", image, platform, err)
+		}
+		err = exec.Command("docker", "pull", "--platform", platform, image).Run()
+		if err == nil {
+			break
+		}
+	}
+	if ratelimit.Is(err) {
+		return "", &ratelimit.Error{
+			Pulls:  map[string][]string{image + " for " + platform: {""}},
+			Advice: ratelimit.NodeImageAdvice,
+		}
+	} else if err != nil {
+		return "", errors.Wrapf(err, "failed to pull image %q for %s", image, platform)
+	}
+	lines, err := exec.OutputLines(exec.Command(
+		"docker", "image", "inspect", "--format", "{{.Id}} {{.Os}}/{{.Architecture}}", image,
+	))
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to inspect image %q", image)
 	}
+	if len(lines) != 1 {
+		return "", errors.Errorf("docker image inspect should only be one line, got %d lines", len(lines))
+	}
+	parts := strings.Fields(lines[0])
+	// the variant is not reported, only check the os and architecture
+	if len(parts) != 2 || !strings.HasPrefix(platform+"/", parts[1]+"/") {
+		return "", errors.Errorf("image %q has no variant for %s, got %q", image, platform, lines[0])
+	}
+	return parts[0], nil
+}
+
+// imageID returns the ID of the local image, if present
+func imageID(image string) (string, error) {
+	lines, err := exec.OutputLines(exec.Command(
+		"docker", "image", "inspect", "--format", "{{.Id}}", image,
+	))
+	if err != nil {
+		return "", err
+	}
+	if len(lines) != 1 {
+		return "", errors.Errorf("docker image inspect should only be one line, got %d lines", len(lines))
+	}
+	return lines[0], nil
 }
 
 // pullIfNotPresent will pull an image if it is not present locally
@@ -69,7 +226,13 @@ func pull(image string, retries int) error {
 	// retry pulling up to retries times if necessary
 	if err != nil {
 		for i := 0; i < retries; i++ {
-			time.Sleep(time.Second * time.Duration(i+1))
+			// rate limits take much longer to lift than other failures
+			if ratelimit.Is(err) {
+				globals.GetLogger().Warnf("Pulling image %q was rate limited, retrying in %v", image, ratelimit.Backoff(i))
+				time.Sleep(ratelimit.Backoff(i))
+			} else {
+				time.Sleep(time.Second * time.Duration(i+1))
+			}
 			globals.GetLogger().V(1).Infof("Trying again to pull image: %q ... %v", image, err)
 			// TODO(bentheelder): add some backoff / sleep?
 			err = exec.Command("docker", "pull", image).Run()
@@ -0,0 +1,272 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docker
+
+import (
+	"fmt"
+	"hash/fnv"
+	"net"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/cluster/constants"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+// networkName returns the name of the dedicated network for cluster
+func networkName(cluster string) string {
+	return "kind-" + cluster
+}
+
+// ensureNetwork creates the dedicated network for cluster if it does not
+// already exist, networks left behind by a previous cluster of the same
+// name are reused
+func ensureNetwork(cluster string, cfg *config.Cluster) error {
+	name := networkName(cluster)
+	owner, exists, err := networkClusterLabel(name)
+	if err != nil {
+		return err
+	}
+	if exists {
+		if owner != cluster {
+			return errors.Errorf("docker network %q already exists and is not owned by cluster %q", name, cluster)
+		}
+		return nil
+	}
+	// docker requires an explicit subnet to enable IPv6, which must not
+	// overlap the networks of other clusters
+	var existing []*net.IPNet
+	if cfg.Networking.IPFamily == config.IPv6Family && cfg.Networking.NodeIPv6Subnet == "" {
+		if existing, err = networkSubnets(); err != nil {
+			return err
+		}
+	}
+	args, err := createNetworkArgs(cluster, cfg, existing)
+	if err != nil {
+		return err
+	}
+	if err := exec.Command("docker", args...).Run(); err != nil {
+		return errors.Wrapf(err, "failed to create docker network %q", name)
+	}
+	return nil
+}
+
+// createNetworkArgs computes the docker network create arguments for
+// the cluster's network, the subnets of the existing networks are avoided
+// when selecting an IPv6 subnet
+func createNetworkArgs(cluster string, cfg *config.Cluster, existing []*net.IPNet) ([]string, error) {
+	args := []string{
+		"network", "create",
+		"--driver", "bridge",
+		// label the network with the cluster ID
+		"--label", fmt.Sprintf("%s=%s", constants.ClusterLabelKey, cluster),
+	}
+	if cfg.Networking.NodeSubnet != "" {
+		args = append(args, "--subnet", cfg.Networking.NodeSubnet)
+		if cfg.Networking.NodeGateway != "" {
+			args = append(args, "--gateway", cfg.Networking.NodeGateway)
+		}
+	}
+	ipv6Subnet := cfg.Networking.NodeIPv6Subnet
+	if ipv6Subnet == "" && cfg.Networking.IPFamily == config.IPv6Family {
+		subnet, err := freeIPv6Subnet(cluster, existing)
+		if err != nil {
+			return nil, err
+		}
+		ipv6Subnet = subnet
+	}
+	if ipv6Subnet != "" {
+		args = append(args, "--ipv6", "--subnet", ipv6Subnet)
+	}
+	if cfg.Networking.MTU != 0 {
+		args = append(args, "--opt", fmt.Sprintf("com.docker.network.driver.mtu=%d", cfg.Networking.MTU))
+	}
+	return append(args, networkName(cluster)), nil
+}
+
+// freeIPv6Subnet returns a /64 within fc00:f853:ccd::/48 overlapping none of
+// the existing subnets, starting from one derived from the cluster name so
+// that recreated clusters usually keep their subnet
+func freeIPv6Subnet(cluster string, existing []*net.IPNet) (string, error) {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(cluster))
+	start := h.Sum32()
+	for i := uint32(0); i <= 0xffff; i++ {
+		subnet := fmt.Sprintf("fc00:f853:ccd:%x::/64", uint16(start+i))
+		_, candidate, err := net.ParseCIDR(subnet)
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+		overlaps := false
+		for _, n := range existing {
+			if n.Contains(candidate.IP) || candidate.Contains(n.IP) {
+				overlaps = true
+				break
+			}
+		}
+		if !overlaps {
+			return subnet, nil
+		}
+	}
+	return "", errors.New("no free IPv6 subnet for the node network, set nodeIPv6Subnet")
+}
+
+// networkSubnets returns the subnets of all of the docker networks
+func networkSubnets() ([]*net.IPNet, error) {
+	ids, err := exec.OutputLines(exec.Command("docker", "network", "ls", "--quiet"))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list docker networks")
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	lines, err := exec.OutputLines(exec.Command("docker", append([]string{
+		"network", "inspect",
+		"--format", `{{ range .IPAM.Config }}{{ .Subnet }}{{ "\n" }}{{ end }}`,
+	}, ids...)...))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to inspect docker networks")
+	}
+	subnets := []*net.IPNet{}
+	for _, line := range lines {
+		if _, subnet, err := net.ParseCIDR(strings.TrimSpace(line)); err == nil {
+			subnets = append(subnets, subnet)
+		}
+	}
+	return subnets, nil
+}
+
+// networkInterface returns the host bridge interface of the network name,
+// docker names these after the network ID
+func networkInterface(name string) (string, error) {
+	lines, err := exec.OutputLines(exec.Command(
+		"docker", "network", "inspect",
+		"--format", `{{.Id}} {{index .Options "com.docker.network.bridge.name"}}`,
+		name,
+	))
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to inspect docker network %q", name)
+	}
+	if len(lines) != 1 {
+		return "", errors.Errorf("invalid output inspecting docker network %q: %q", name, lines)
+	}
+	parts := strings.Fields(lines[0])
+	// an explicitly named bridge
+	if len(parts) == 2 {
+		return parts[1], nil
+	}
+	if len(parts) != 1 || len(parts[0]) < 12 {
+		return "", errors.Errorf("invalid docker network %q ID: %q", name, lines[0])
+	}
+	return "br-" + parts[0][:12], nil
+}
+
+// deleteNetwork deletes the dedicated network for cluster, if it exists
+func deleteNetwork(cluster string) error {
+	name := networkName(cluster)
+	owner, exists, err := networkClusterLabel(name)
+	if err != nil {
+		return err
+	}
+	// never delete networks kind did not create for this cluster
+	if !exists || owner != cluster {
+		return nil
+	}
+	// nodes of other clusters may still be connected to this network
+	attached, err := networkContainers(name)
+	if err != nil {
+		return err
+	}
+	for _, container := range attached {
+		if err := exec.Command("docker", "network", "disconnect", "--force", name, container).Run(); err != nil {
+			return errors.Wrapf(err, "failed to disconnect %q from docker network %q", container, name)
+		}
+	}
+	if err := exec.Command("docker", "network", "rm", name).Run(); err != nil {
+		return errors.Wrapf(err, "failed to delete docker network %q", name)
+	}
+	return nil
+}
+
+// connectNetwork attaches the named containers to the dedicated network
+// for cluster, containers already attached to it are skipped
+func connectNetwork(cluster string, containers []string) error {
+	name := networkName(cluster)
+	attached, err := networkContainers(name)
+	if err != nil {
+		return err
+	}
+	isAttached := map[string]bool{}
+	for _, container := range attached {
+		isAttached[container] = true
+	}
+	for _, container := range containers {
+		if isAttached[container] {
+			continue
+		}
+		if err := exec.Command("docker", "network", "connect", name, container).Run(); err != nil {
+			return errors.Wrapf(err, "failed to connect %q to docker network %q", container, name)
+		}
+	}
+	return nil
+}
+
+// networkContainers returns the names of the containers attached to the
+// network name
+func networkContainers(name string) ([]string, error) {
+	lines, err := exec.OutputLines(exec.Command(
+		"docker", "network", "inspect",
+		"--format", `{{ range .Containers }}{{ .Name }}{{ "\n" }}{{ end }}`,
+		name,
+	))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to inspect docker network %q", name)
+	}
+	containers := []string{}
+	for _, line := range lines {
+		if line != "" {
+			containers = append(containers, line)
+		}
+	}
+	return containers, nil
+}
+
+// networkClusterLabel returns the cluster label of the network name,
+// and whether the network exists at all
+func networkClusterLabel(name string) (cluster string, exists bool, err error) {
+	lines, err := exec.OutputLines(exec.Command(
+		"docker", "network", "ls",
+		"--filter", "name=^"+name+"$",
+		"--format", fmt.Sprintf(`{{.Name}}\t{{.Label %q}}`, constants.ClusterLabelKey),
+	))
+	if err != nil {
+		return "", false, errors.Wrap(err, "failed to list docker networks")
+	}
+	for _, line := range lines {
+		parts := strings.SplitN(line, "\t", 2)
+		if parts[0] != name {
+			continue
+		}
+		if len(parts) == 2 {
+			cluster = parts[1]
+		}
+		return cluster, true, nil
+	}
+	return "", false, nil
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docker
+
+import (
+	"net"
+	"reflect"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/cluster/constants"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+func TestCreateNetworkArgs(t *testing.T) {
+	cases := []struct {
+		Name       string
+		Networking config.Networking
+		Existing   []string
+		Expected   []string
+	}{
+		{
+			Name:     "docker defaults",
+			Expected: []string{},
+		},
+		{
+			Name: "subnet, gateway and mtu",
+			Networking: config.Networking{
+				NodeSubnet:  "172.30.0.0/16",
+				NodeGateway: "172.30.0.1",
+				MTU:         1400,
+			},
+			Expected: []string{
+				"--subnet", "172.30.0.0/16", "--gateway", "172.30.0.1",
+				"--opt", "com.docker.network.driver.mtu=1400",
+			},
+		},
+		{
+			Name: "explicit IPv6 subnet",
+			Networking: config.Networking{
+				IPFamily:       config.IPv6Family,
+				NodeIPv6Subnet: "fd00:1::/64",
+			},
+			Expected: []string{"--ipv6", "--subnet", "fd00:1::/64"},
+		},
+		{
+			Name:       "IPv6 subnet selected",
+			Networking: config.Networking{IPFamily: config.IPv6Family},
+			Existing:   []string{"172.18.0.0/16"},
+			Expected:   []string{"--ipv6", "--subnet", freeSubnet(t, "kind", nil)},
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			args, err := createNetworkArgs("kind", &config.Cluster{Networking: tc.Networking}, parseSubnets(t, tc.Existing))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			// the common arguments are always first, the name last
+			expected := append([]string{
+				"network", "create", "--driver", "bridge",
+				"--label", constants.ClusterLabelKey + "=kind",
+			}, tc.Expected...)
+			expected = append(expected, "kind-kind")
+			if !reflect.DeepEqual(args, expected) {
+				t.Errorf("createNetworkArgs() = %v, expected %v", args, expected)
+			}
+		})
+	}
+}
+
+func TestFreeIPv6Subnet(t *testing.T) {
+	first := freeSubnet(t, "kind", nil)
+	if again := freeSubnet(t, "kind", nil); again != first {
+		t.Errorf("expected the same subnet for the same cluster, got %s and %s", first, again)
+	}
+	// another cluster, or the same one after another took its subnet, must
+	// not overlap the networks already created
+	existing := parseSubnets(t, []string{first, "fc00:f853:ccd::/56"})
+	second := freeSubnet(t, "kind", existing)
+	_, subnet, err := net.ParseCIDR(second)
+	if err != nil {
+		t.Fatalf("invalid subnet %s: %v", second, err)
+	}
+	for _, n := range existing {
+		if n.Contains(subnet.IP) || subnet.Contains(n.IP) {
+			t.Errorf("selected subnet %s overlaps existing %s", second, n)
+		}
+	}
+	if ones, _ := subnet.Mask.Size(); ones != 64 {
+		t.Errorf("expected a /64, got %s", second)
+	}
+}
+
+func freeSubnet(t *testing.T, cluster string, existing []*net.IPNet) string {
+	subnet, err := freeIPv6Subnet(cluster, existing)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return subnet
+}
+
+func parseSubnets(t *testing.T, cidrs []string) []*net.IPNet {
+	subnets := []*net.IPNet{}
+	for _, cidr := range cidrs {
+		_, subnet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		subnets = append(subnets, subnet)
+	}
+	return subnets
+}
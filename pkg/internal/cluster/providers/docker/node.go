@@ -17,13 +17,18 @@ limitations under the License.
 package docker
 
 import (
+	"context"
+	"crypto/rand"
 	"fmt"
 	"io"
 	"strings"
 
 	"sigs.k8s.io/kind/pkg/cluster/constants"
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
 	"sigs.k8s.io/kind/pkg/errors"
 	"sigs.k8s.io/kind/pkg/exec"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
 )
 
 // nodes.Node implementation for the docker provider
@@ -52,22 +57,101 @@ func (n *node) Role() (string, error) {
 
 func (n *node) IP() (ipv4 string, ipv6 string, err error) {
 	// retrieve the IP address of the node using docker inspect
+	// nodes may be attached to other clusters' networks, so list every
+	// network after the node's cluster label
 	cmd := exec.Command("docker", "inspect",
-		"-f", "{{range .NetworkSettings.Networks}}{{.IPAddress}},{{.GlobalIPv6Address}}{{end}}",
+		"-f", fmt.Sprintf(
+			`{{ index .Config.Labels %q }}{{ range $name, $net := .NetworkSettings.Networks }}`+"\n"+
+				`{{ $name }},{{ $net.IPAddress }},{{ $net.GlobalIPv6Address }}{{ end }}`,
+			constants.ClusterLabelKey,
+		),
 		n.name, // ... against the "node" container
 	)
 	lines, err := exec.CombinedOutputLines(cmd)
 	if err != nil {
 		return "", "", errors.Wrap(err, "failed to get container details")
 	}
-	if len(lines) != 1 {
-		return "", "", errors.Errorf("file should only be one line, got %d lines", len(lines))
+	// nodes in the host's network namespace have no addresses of their own
+	if len(lines) == 2 && strings.HasPrefix(lines[1], "host,") {
+		return n.hostIPs()
+	}
+	return nodeIPs(lines)
+}
+
+// hostIPs returns the host's addresses used to reach other hosts, as seen
+// from a node in the host's network namespace
+func (n *node) hostIPs() (ipv4 string, ipv6 string, err error) {
+	lines, err := exec.OutputLines(n.Command("ip", "-4", "route", "get", "1.1.1.1"))
+	if err != nil {
+		return "", "", errors.Wrap(err, "failed to get host IPv4 route")
+	}
+	ipv4 = routeSource(lines)
+	if ipv4 == "" {
+		return "", "", errors.New("failed to get host IPv4 address")
+	}
+	// IPv6 is optional on the host
+	lines, err = exec.OutputLines(n.Command("ip", "-6", "route", "get", "2606:4700:4700::1111"))
+	if err == nil {
+		ipv6 = routeSource(lines)
+	}
+	return ipv4, ipv6, nil
+}
+
+// routeSource parses the source address from the output of `ip route get`
+func routeSource(lines []string) string {
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		for i := 0; i+1 < len(fields); i++ {
+			if fields[i] == "src" {
+				return fields[i+1]
+			}
+		}
+	}
+	return ""
+}
+
+// nodeIPs parses the output of the docker inspect in IP, preferring the
+// addresses on the node's cluster network
+func nodeIPs(lines []string) (ipv4 string, ipv6 string, err error) {
+	if len(lines) < 2 {
+		return "", "", errors.Errorf("container should be attached to a network, got %d lines", len(lines))
+	}
+	networks := map[string][]string{}
+	for _, line := range lines[1:] {
+		parts := strings.Split(line, ",")
+		if len(parts) != 3 {
+			return "", "", errors.Errorf("container network addresses should have 3 values, got %d values", len(parts))
+		}
+		networks[parts[0]] = parts[1:]
+	}
+	if ips, ok := networks[networkName(lines[0])]; ok {
+		return ips[0], ips[1], nil
+	}
+	// nodes created before the cluster had a dedicated network
+	if len(networks) != 1 {
+		return "", "", errors.Errorf("container is not attached to network %q", networkName(lines[0]))
+	}
+	for _, ips := range networks {
+		ipv4, ipv6 = ips[0], ips[1]
 	}
-	ips := strings.Split(lines[0], ",")
-	if len(ips) != 2 {
-		return "", "", errors.Errorf("container addresses should have 2 values, got %d values", len(ips))
+	return ipv4, ipv6, nil
+}
+
+func (n *node) PortMappings() ([]nodes.PortMapping, error) {
+	mappings, err := portMappings(n.name)
+	if err != nil {
+		return nil, err
 	}
-	return ips[0], ips[1], nil
+	out := make([]nodes.PortMapping, 0, len(mappings))
+	for _, m := range mappings {
+		out = append(out, nodes.PortMapping{
+			ContainerPort: m.ContainerPort,
+			HostPort:      m.HostPort,
+			ListenAddress: m.ListenAddress,
+			Protocol:      config.PortMappingProtocolValueToName[m.Protocol],
+		})
+	}
+	return out, nil
 }
 
 func (n *node) Command(command string, args ...string) exec.Cmd {
@@ -78,9 +162,19 @@ func (n *node) Command(command string, args ...string) exec.Cmd {
 	}
 }
 
+func (n *node) CommandContext(ctx context.Context, command string, args ...string) exec.Cmd {
+	return &nodeCmd{
+		nameOrID: n.name,
+		ctx:      ctx,
+		command:  command,
+		args:     args,
+	}
+}
+
 // nodeCmd implements exec.Cmd for docker nodes
 type nodeCmd struct {
 	nameOrID string // the container name or ID
+	ctx      context.Context
 	command  string
 	args     []string
 	env      []string
@@ -106,19 +200,30 @@ func (c *nodeCmd) Run() error {
 	for _, env := range c.env {
 		args = append(args, "-e", env)
 	}
+	command, commandArgs := c.command, c.args
+	// killing docker exec leaves the command running in the node, so it is
+	// run by a shell recording its pid to kill it with once ctx is done
+	pidFile := ""
+	if c.ctx != nil {
+		pidFile = execPIDFile()
+		command, commandArgs = "sh", append([]string{"-c", execWrapperScript, pidFile, c.command}, c.args...)
+	}
 	// specify the container and command, after this everything will be
 	// args the command in the container rather than to docker
 	args = append(
 		args,
 		c.nameOrID, // ... against the container
-		c.command,  // with the command specified
+		command,    // with the command specified
 	)
 	args = append(
 		args,
 		// finally, with the caller args
-		c.args...,
+		commandArgs...,
 	)
 	cmd := exec.Command("docker", args...)
+	if c.ctx != nil {
+		cmd = exec.CommandContext(c.ctx, "docker", args...)
+	}
 	if c.stdin != nil {
 		cmd.SetStdin(c.stdin)
 	}
@@ -128,7 +233,39 @@ func (c *nodeCmd) Run() error {
 	if c.stdout != nil {
 		cmd.SetStdout(c.stdout)
 	}
-	return cmd.Run()
+	err := cmd.Run()
+	if err != nil && c.ctx != nil && c.ctx.Err() != nil {
+		_ = exec.Command("docker", "exec", "--privileged", c.nameOrID, "sh", "-c", execKillScript, "sh", pidFile).Run()
+	}
+	return err
+}
+
+// execWrapperScript runs "$@" recording the pid of the shell in the file $0
+// while it runs, the exit status of the command is kept
+const execWrapperScript = `echo $$ > "$0"; "$@"; status=$?; rm -f "$0"; exit $status`
+
+// execKillScript terminates the descendants of the shell running
+// execWrapperScript with the pid file $1 until it exits, parents before
+// their children so none of them carries on, the file is waited for
+// briefly in case the command was only just started
+const execKillScript = `for i in 1 2 3 4 5; do [ -f "$1" ] && break; sleep 0.2; done
+[ -f "$1" ] || exit 0
+pid="$(cat "$1")"
+kill_tree() { set -- "$1" $(pgrep -P "$1"); kill -TERM "$1" 2>/dev/null; shift; for child; do kill_tree "${child}"; done; }
+i=0
+while [ -f "$1" ] && [ "${i}" -lt 25 ]; do
+	for child in $(pgrep -P "${pid}"); do kill_tree "${child}"; done
+	sleep 0.2
+	i=$((i+1))
+done
+exit 0`
+
+// execPIDFile returns a unique path for the pid file of a command run with
+// execWrapperScript
+func execPIDFile() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return fmt.Sprintf("/tmp/kind-exec-%x.pid", b)
 }
 
 func (c *nodeCmd) SetEnv(env ...string) exec.Cmd {
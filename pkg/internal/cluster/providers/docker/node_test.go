@@ -0,0 +1,103 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docker
+
+import (
+	"testing"
+)
+
+func TestNodeIPs(t *testing.T) {
+	cases := []struct {
+		Name         string
+		Lines        []string
+		ExpectedIPv4 string
+		ExpectedIPv6 string
+		ExpectErr    bool
+	}{
+		{
+			Name:         "cluster network",
+			Lines:        []string{"a", "kind-a,172.18.0.2,fc00::2"},
+			ExpectedIPv4: "172.18.0.2",
+			ExpectedIPv6: "fc00::2",
+		},
+		{
+			Name:         "connected to another cluster network",
+			Lines:        []string{"a", "kind-a,172.18.0.2,", "kind-b,172.19.0.5,"},
+			ExpectedIPv4: "172.18.0.2",
+		},
+		{
+			Name:         "default network",
+			Lines:        []string{"a", "bridge,172.17.0.2,"},
+			ExpectedIPv4: "172.17.0.2",
+		},
+		{
+			Name:      "ambiguous networks",
+			Lines:     []string{"a", "bridge,172.17.0.2,", "kind-b,172.19.0.5,"},
+			ExpectErr: true,
+		},
+		{
+			Name:      "no networks",
+			Lines:     []string{"a"},
+			ExpectErr: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			ipv4, ipv6, err := nodeIPs(tc.Lines)
+			if (err != nil) != tc.ExpectErr {
+				t.Fatalf("nodeIPs() error = %v, ExpectErr %v", err, tc.ExpectErr)
+			}
+			if ipv4 != tc.ExpectedIPv4 || ipv6 != tc.ExpectedIPv6 {
+				t.Errorf("nodeIPs() = %q, %q, expected %q, %q", ipv4, ipv6, tc.ExpectedIPv4, tc.ExpectedIPv6)
+			}
+		})
+	}
+}
+
+func TestRouteSource(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Lines    []string
+		Expected string
+	}{
+		{
+			Name:     "via gateway",
+			Lines:    []string{"1.1.1.1 via 192.168.1.1 dev eth0 src 192.168.1.20 uid 0", "    cache"},
+			Expected: "192.168.1.20",
+		},
+		{
+			Name:     "ipv6",
+			Lines:    []string{"2606:4700:4700::1111 from :: via fe80::1 dev eth0 proto ra src 2001:db8::20 metric 100 pref medium"},
+			Expected: "2001:db8::20",
+		},
+		{
+			Name:  "no source",
+			Lines: []string{"unreachable 1.1.1.1"},
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			if actual := routeSource(tc.Lines); actual != tc.Expected {
+				t.Errorf("routeSource() = %q, expected %q", actual, tc.Expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docker
+
+import (
+	"net"
+	"os"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+// resolvePortMappings returns the mappings with the listen address of each
+// set, mappings listening on an interface or on both IP families are
+// expanded to one mapping per address
+func resolvePortMappings(mappings []config.PortMapping, interfaceAddrs func(string) ([]net.IP, error)) ([]config.PortMapping, error) {
+	resolved := []config.PortMapping{}
+	for _, pm := range mappings {
+		addresses, err := listenAddresses(pm, interfaceAddrs)
+		if err != nil {
+			return nil, err
+		}
+		for _, address := range addresses {
+			pm := pm // copy
+			pm.ListenAddress = address
+			pm.ListenInterface = ""
+			resolved = append(resolved, pm)
+		}
+	}
+	return resolved, nil
+}
+
+// listenAddresses returns the host addresses pm listens on, "" is the
+// runtime's default
+func listenAddresses(pm config.PortMapping, interfaceAddrs func(string) ([]net.IP, error)) ([]string, error) {
+	if pm.ListenAddress != "" {
+		return []string{pm.ListenAddress}, nil
+	}
+	if pm.ListenInterface != "" {
+		ips, err := interfaceAddrs(pm.ListenInterface)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get addresses of interface %q", pm.ListenInterface)
+		}
+		addresses := []string{}
+		for _, ip := range ips {
+			// link local addresses can't be bound without a zone
+			if ip.IsLinkLocalUnicast() {
+				continue
+			}
+			isIPv4 := ip.To4() != nil
+			if (pm.ListenIPFamily == config.PortMappingIPv4 && !isIPv4) ||
+				(pm.ListenIPFamily == config.PortMappingIPv6 && isIPv4) {
+				continue
+			}
+			addresses = append(addresses, ip.String())
+		}
+		if len(addresses) == 0 {
+			return nil, errors.Errorf("interface %q has no usable %s addresses", pm.ListenInterface, pm.ListenIPFamily)
+		}
+		return addresses, nil
+	}
+	switch pm.ListenIPFamily {
+	case config.PortMappingIPv4:
+		return []string{"0.0.0.0"}, nil
+	case config.PortMappingIPv6:
+		return []string{"::"}, nil
+	case config.PortMappingDualStack:
+		return []string{"0.0.0.0", "::"}, nil
+	}
+	return []string{""}, nil
+}
+
+// hostInterfaceAddrs returns the addresses of the named host interface
+func hostInterfaceAddrs(name string) ([]net.IP, error) {
+	iface, err := net.InterfaceByName(name)
+	if err != nil {
+		return nil, err
+	}
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil, err
+	}
+	ips := []net.IP{}
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok {
+			ips = append(ips, ipNet.IP)
+		}
+	}
+	return ips, nil
+}
+
+// validateListenAddresses checks the host supports listening on the
+// mappings' addresses, the addresses must be assigned to the host and
+// IPv6 mappings require IPv6 on the host
+func validateListenAddresses(mappings []config.PortMapping, hostAddrs []net.IP) error {
+	hasIPv6 := false
+	assigned := map[string]bool{}
+	for _, ip := range hostAddrs {
+		if ip.To4() == nil {
+			hasIPv6 = true
+		}
+		assigned[ip.String()] = true
+	}
+	for _, pm := range mappings {
+		if pm.ListenAddress == "" {
+			continue
+		}
+		ip := net.ParseIP(pm.ListenAddress)
+		if ip == nil {
+			return errors.Errorf("invalid listen address %q", pm.ListenAddress)
+		}
+		if ip.To4() == nil && !hasIPv6 {
+			return errors.Errorf("cannot listen on %q, the host has no IPv6 addresses", pm.ListenAddress)
+		}
+		if !ip.IsUnspecified() && !assigned[ip.String()] {
+			return errors.Errorf("cannot listen on %q, the address is not assigned to the host", pm.ListenAddress)
+		}
+	}
+	return nil
+}
+
+// hostAddrs returns the addresses of all host interfaces, or nil if the
+// docker daemon is remote and its host's addresses are unknown
+func hostAddrs() ([]net.IP, error) {
+	if host := os.Getenv("DOCKER_HOST"); host != "" && !strings.HasPrefix(host, "unix://") {
+		return nil, nil
+	}
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list host addresses")
+	}
+	ips := []net.IP{}
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok {
+			ips = append(ips, ipNet.IP)
+		}
+	}
+	return ips, nil
+}
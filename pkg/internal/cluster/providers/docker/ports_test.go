@@ -0,0 +1,111 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docker
+
+import (
+	"net"
+	"reflect"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+func TestResolvePortMappings(t *testing.T) {
+	interfaceAddrs := func(name string) ([]net.IP, error) {
+		return []net.IP{
+			net.ParseIP("192.168.1.2"),
+			net.ParseIP("fe80::1"),
+			net.ParseIP("2001:db8::2"),
+		}, nil
+	}
+	cases := []struct {
+		Name     string
+		Mapping  config.PortMapping
+		Expected []string
+	}{
+		{
+			Name:     "runtime default",
+			Mapping:  config.PortMapping{},
+			Expected: []string{""},
+		},
+		{
+			Name:     "explicit address",
+			Mapping:  config.PortMapping{ListenAddress: "::1"},
+			Expected: []string{"::1"},
+		},
+		{
+			Name:     "dual stack",
+			Mapping:  config.PortMapping{ListenIPFamily: config.PortMappingDualStack},
+			Expected: []string{"0.0.0.0", "::"},
+		},
+		{
+			Name:     "interface",
+			Mapping:  config.PortMapping{ListenInterface: "eth1"},
+			Expected: []string{"192.168.1.2", "2001:db8::2"},
+		},
+		{
+			Name:     "interface ipv6",
+			Mapping:  config.PortMapping{ListenInterface: "eth1", ListenIPFamily: config.PortMappingIPv6},
+			Expected: []string{"2001:db8::2"},
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			resolved, err := resolvePortMappings([]config.PortMapping{tc.Mapping}, interfaceAddrs)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			addresses := []string{}
+			for _, pm := range resolved {
+				if pm.ListenInterface != "" {
+					t.Errorf("expected listenInterface to be resolved")
+				}
+				addresses = append(addresses, pm.ListenAddress)
+			}
+			if !reflect.DeepEqual(addresses, tc.Expected) {
+				t.Errorf("expected %v but got %v", tc.Expected, addresses)
+			}
+		})
+	}
+}
+
+func TestValidateListenAddresses(t *testing.T) {
+	ipv4Only := []net.IP{net.ParseIP("127.0.0.1"), net.ParseIP("192.168.1.2")}
+	cases := []struct {
+		Name        string
+		Address     string
+		ExpectError bool
+	}{
+		{Name: "runtime default", Address: ""},
+		{Name: "assigned", Address: "192.168.1.2"},
+		{Name: "unspecified", Address: "0.0.0.0"},
+		{Name: "not assigned", Address: "10.0.0.1", ExpectError: true},
+		{Name: "no ipv6", Address: "::", ExpectError: true},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			err := validateListenAddresses([]config.PortMapping{{ListenAddress: tc.Address}}, ipv4Only)
+			if (err != nil) != tc.ExpectError {
+				t.Errorf("expected error: %v but got: %v", tc.ExpectError, err)
+			}
+		})
+	}
+}
@@ -17,9 +17,16 @@ limitations under the License.
 package docker
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
 	"net"
+	"runtime"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"k8s.io/apimachinery/pkg/util/sets"
 
@@ -33,6 +40,7 @@ import (
 	"sigs.k8s.io/kind/pkg/internal/cluster/providers/provider"
 	"sigs.k8s.io/kind/pkg/internal/cluster/providers/provider/common"
 	"sigs.k8s.io/kind/pkg/internal/util/cli"
+	"sigs.k8s.io/kind/pkg/internal/util/units"
 )
 
 // NewProvider returns a new provider based on executing `docker ...`
@@ -45,23 +53,61 @@ func NewProvider() provider.Provider {
 type Provider struct{}
 
 // Provision is part of the providers.Provider interface
-func (p *Provider) Provision(status *cli.Status, cluster string, cfg *config.Cluster) (err error) {
+func (p *Provider) Provision(status *cli.Status, cluster string, cfg *config.Cluster, protect bool) (err error) {
 	// TODO: validate cfg
+	// docker on other platforms runs containers in a VM, whose network is
+	// not the host's
+	if cfg.Networking.HostNetwork && runtime.GOOS != "linux" {
+		return errors.Errorf("hostNetwork is only supported on linux, not %s", runtime.GOOS)
+	}
+	// the nodes are labeled with their images as declared, these are
+	// replaced below and the config nodes keep their order
+	declaredImages := []string{}
+	for _, n := range cfg.Nodes {
+		declaredImages = append(declaredImages, n.Image)
+	}
+	if cfg.PreferDebugImages {
+		cfg = preferDebugImages(cfg)
+	}
 	// ensure node images are pulled before actually provisioning
-	ensureNodeImages(status, cfg)
+	if err := ensureNodeImages(status, cfg); err != nil {
+		return err
+	}
+	// then pin nodes on other platforms to their image variant
+	cfg, err = resolvePlatformImages(status, cfg)
+	if err != nil {
+		return err
+	}
 
 	// actually provision the cluster
 	// TODO: strings.Repeat("📦", len(desiredNodes))
 	status.Start("Preparing nodes 📦")
 	defer func() { status.End(err == nil) }()
 
+	// create the cluster's network before any nodes to attach to it, unless
+	// the node runs in the host's network namespace
+	if !cfg.Networking.HostNetwork {
+		if err := ensureNetwork(cluster, cfg); err != nil {
+			return err
+		}
+	}
+
 	// plan creating the containers
-	createContainerFuncs, err := planCreation(cluster, cfg)
+	createContainerFuncs, err := planCreation(cluster, cfg, declaredImages, protect)
 	if err != nil {
 		return err
 	}
 
-	// actually create nodes
+	// actually create nodes, in order if seeded so the network assigns
+	// their addresses in order
+	if cfg.Seed != "" {
+		for _, createContainer := range createContainerFuncs {
+			if err := createContainer(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
 	return errors.UntilErrorConcurrent(createContainerFuncs)
 }
 
@@ -108,6 +154,39 @@ func (p *Provider) ListNodes(cluster string) ([]nodes.Node, error) {
 	return ret, nil
 }
 
+// ListNodesByLabel is part of the providers.Provider interface
+func (p *Provider) ListNodesByLabel(selector string) ([]nodes.Node, error) {
+	filters, err := labelFilters(selector)
+	if err != nil {
+		return nil, err
+	}
+	args := append([]string{"ps", "-a", "--no-trunc"}, filters...)
+	lines, err := exec.OutputLines(exec.Command("docker", append(args, "--format", `{{.Names}}`)...))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list containers")
+	}
+	ret := make([]nodes.Node, 0, len(lines))
+	for _, name := range lines {
+		ret = append(ret, p.node(name))
+	}
+	return ret, nil
+}
+
+// labelFilters returns the docker ps --filter args matching the label
+// selector, key or key=value joined by commas
+func labelFilters(selector string) ([]string, error) {
+	filters := []string{}
+	for _, label := range strings.Split(selector, ",") {
+		if label = strings.TrimSpace(label); label != "" {
+			filters = append(filters, "--filter", "label="+label)
+		}
+	}
+	if len(filters) == 0 {
+		return nil, errors.New("label selector must not be empty")
+	}
+	return filters, nil
+}
+
 // DeleteNodes is part of the providers.Provider interface
 func (p *Provider) DeleteNodes(n []nodes.Node) error {
 	if len(n) == 0 {
@@ -129,6 +208,213 @@ func (p *Provider) DeleteNodes(n []nodes.Node) error {
 	return nil
 }
 
+// StopNodes is part of the providers.Provider interface
+func (p *Provider) StopNodes(n []nodes.Node) error {
+	if len(n) == 0 {
+		return nil
+	}
+	args := []string{"stop"}
+	for _, node := range n {
+		args = append(args, node.String())
+	}
+	if err := exec.Command("docker", args...).Run(); err != nil {
+		return errors.Wrap(err, "failed to stop nodes")
+	}
+	return nil
+}
+
+// StartNodes is part of the providers.Provider interface
+func (p *Provider) StartNodes(n []nodes.Node) error {
+	if len(n) == 0 {
+		return nil
+	}
+	args := []string{"start"}
+	for _, node := range n {
+		args = append(args, node.String())
+	}
+	if err := exec.Command("docker", args...).Run(); err != nil {
+		return errors.Wrap(err, "failed to start nodes")
+	}
+	return nil
+}
+
+// CheckpointNodes is part of the providers.Provider interface
+func (p *Provider) CheckpointNodes(n []nodes.Node, checkpoint string) error {
+	if err := checkExperimental(); err != nil {
+		return err
+	}
+	for _, node := range n {
+		// replace any previous checkpoint of the same name, this fails if
+		// there is none
+		_ = exec.Command("docker", "checkpoint", "rm", node.String(), checkpoint).Run()
+		if err := exec.Command("docker", "checkpoint", "create", node.String(), checkpoint).Run(); err != nil {
+			return errors.Wrapf(err, "failed to checkpoint node %s", node.String())
+		}
+	}
+	return nil
+}
+
+// RestoreNodes is part of the providers.Provider interface
+func (p *Provider) RestoreNodes(n []nodes.Node, checkpoint string) error {
+	if err := checkExperimental(); err != nil {
+		return err
+	}
+	for _, node := range n {
+		if err := exec.Command("docker", "start", "--checkpoint", checkpoint, node.String()).Run(); err != nil {
+			return errors.Wrapf(err, "failed to restore node %s from checkpoint %q", node.String(), checkpoint)
+		}
+	}
+	return nil
+}
+
+// UpdateNodeResources is part of the providers.Provider interface
+func (p *Provider) UpdateNodeResources(n nodes.Node, r provider.NodeResources) error {
+	args := []string{"update"}
+	if r.CPUs > 0 {
+		args = append(args, "--cpus", strconv.FormatFloat(r.CPUs, 'f', -1, 64))
+	}
+	if r.MemoryBytes > 0 {
+		// swap is limited to the same amount, so no swap is used, otherwise
+		// docker rejects memory limits above a previous swap limit
+		memory := strconv.FormatUint(r.MemoryBytes, 10)
+		args = append(args, "--memory", memory, "--memory-swap", memory)
+	}
+	if err := exec.Command("docker", append(args, n.String())...).Run(); err != nil {
+		return errors.Wrapf(err, "failed to update resources of node %s", n.String())
+	}
+	return nil
+}
+
+// GetMemoryUsage is part of the providers.Provider interface
+func (p *Provider) GetMemoryUsage(n []nodes.Node) (uint64, error) {
+	if len(n) == 0 {
+		return 0, nil
+	}
+	args := []string{
+		"stats",
+		"--no-stream", // take a single sample
+		// memory usage is formatted as "<used> / <limit>"
+		"--format", "{{.MemUsage}}",
+	}
+	for _, node := range n {
+		args = append(args, node.String())
+	}
+	lines, err := exec.OutputLines(exec.Command("docker", args...))
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get node memory usage")
+	}
+	var total uint64
+	for _, line := range lines {
+		used, err := units.ParseBytes(strings.Split(line, "/")[0])
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to parse node memory usage")
+		}
+		total += used
+	}
+	return total, nil
+}
+
+// GetNodeStats is part of the providers.Provider interface
+func (p *Provider) GetNodeStats(n nodes.Node) (*provider.NodeStats, error) {
+	lines, err := exec.OutputLines(exec.Command(
+		"docker", "inspect",
+		"--size", // populates SizeRw, the size of the writable layer
+		// nodes created before they were labeled with their declared image
+		// only have the image they run
+		"--format", fmt.Sprintf(`{{.Created}} {{.SizeRw}} {{with index .Config.Labels %q}}{{.}}{{else}}{{.Config.Image}}{{end}}`, constants.ImageLabelKey),
+		n.String(),
+	))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to inspect node %s", n.String())
+	}
+	if len(lines) != 1 {
+		return nil, errors.Errorf("invalid output when inspecting node %s: %q", n.String(), lines)
+	}
+	return parseNodeStats(lines[0])
+}
+
+// parseNodeStats parses the output of inspecting a node for GetNodeStats
+func parseNodeStats(line string) (*provider.NodeStats, error) {
+	parts := strings.Fields(line)
+	if len(parts) != 3 {
+		return nil, errors.Errorf("invalid node stats: %q", line)
+	}
+	created, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse node creation time")
+	}
+	size, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse node disk usage")
+	}
+	stats := &provider.NodeStats{Created: created, Image: parts[2]}
+	// docker reports a negative size if it could not be computed
+	if size > 0 {
+		stats.DiskBytes = uint64(size)
+	}
+	return stats, nil
+}
+
+// GetNetworkInterface is part of the providers.Provider interface
+func (p *Provider) GetNetworkInterface(cluster string) (string, error) {
+	return networkInterface(networkName(cluster))
+}
+
+// IsProtected is part of the providers.Provider interface
+func (p *Provider) IsProtected(cluster string) (bool, error) {
+	cmd := exec.Command("docker",
+		"ps",
+		"-q", // quiet output for parsing
+		"-a", // show stopped nodes
+		// filter for protected nodes of this cluster
+		"--filter", fmt.Sprintf("label=%s=%s", constants.ClusterLabelKey, cluster),
+		"--filter", fmt.Sprintf("label=%s=true", constants.ProtectedLabelKey),
+	)
+	lines, err := exec.OutputLines(cmd)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to list protected nodes")
+	}
+	return len(lines) > 0, nil
+}
+
+// DeleteNetwork is part of the providers.Provider interface
+func (p *Provider) DeleteNetwork(cluster string) error {
+	return deleteNetwork(cluster)
+}
+
+// ConnectNodes is part of the providers.Provider interface
+func (p *Provider) ConnectNodes(cluster string, n []nodes.Node) error {
+	names := make([]string, 0, len(n))
+	for _, node := range n {
+		names = append(names, node.String())
+	}
+	return connectNetwork(cluster, names)
+}
+
+// CopyFromImage is part of the providers.Provider interface
+func (p *Provider) CopyFromImage(image, path string, readerFunc func(io.Reader) error) error {
+	// files can only be copied out of containers, so create one without
+	// ever starting it
+	lines, err := exec.OutputLines(exec.Command("docker", "create", image))
+	if err != nil {
+		return errors.Wrapf(err, "failed to create container from image %q", image)
+	}
+	if len(lines) == 0 {
+		return errors.Errorf("failed to create container from image %q", image)
+	}
+	container := lines[len(lines)-1]
+	defer func() {
+		_ = exec.Command("docker", "rm", "--force", container).Run()
+	}()
+	if err := exec.RunWithStdoutReader(
+		exec.Command("docker", "cp", container+":"+path, "-"),
+		readerFunc,
+	); err != nil {
+		return errors.Wrapf(err, "failed to copy %s from image %q", path, image)
+	}
+	return nil
+}
+
 func (p *Provider) GetAPIServerEndpoint(cluster string) (string, error) {
 	// locate the node that hosts this
 	allNodes, err := p.ListNodes(cluster)
@@ -140,6 +426,15 @@ func (p *Provider) GetAPIServerEndpoint(cluster string) (string, error) {
 		return "", errors.Wrap(err, "failed to get api server endpoint")
 	}
 
+	// nodes in the host's network namespace serve on the host directly
+	hostNetwork, err := isHostNetwork(n.String())
+	if err != nil {
+		return "", err
+	}
+	if hostNetwork {
+		return net.JoinHostPort("127.0.0.1", strconv.Itoa(common.APIServerInternalPort)), nil
+	}
+
 	// retrieve the specific port mapping using docker inspect
 	cmd := exec.Command(
 		"docker", "inspect",
@@ -164,6 +459,82 @@ func (p *Provider) GetAPIServerEndpoint(cluster string) (string, error) {
 	return net.JoinHostPort(parts[0], parts[1]), nil
 }
 
+// isHostNetwork returns true if the named container runs in the host's
+// network namespace
+func isHostNetwork(name string) (bool, error) {
+	cmd := exec.Command("docker", "inspect", "--format", "{{ .HostConfig.NetworkMode }}", name)
+	lines, err := exec.OutputLines(cmd)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get network mode")
+	}
+	if len(lines) != 1 {
+		return false, errors.Errorf("network mode should only be one line, got %d lines", len(lines))
+	}
+	return lines[0] == "host", nil
+}
+
+// portMappings returns the ports published on the host for the named node
+func portMappings(name string) ([]config.PortMapping, error) {
+	cmd := exec.Command(
+		"docker", "inspect",
+		"--format", "{{ json .NetworkSettings.Ports }}",
+		name,
+	)
+	var buff bytes.Buffer
+	if err := cmd.SetStdout(&buff).Run(); err != nil {
+		return nil, errors.Wrap(err, "failed to get port mappings")
+	}
+	return parsePortMappings(buff.Bytes())
+}
+
+// parsePortMappings parses the port mappings from the json encoded
+// .NetworkSettings.Ports field of docker inspect, EG:
+// {"6443/tcp":[{"HostIp":"127.0.0.1","HostPort":"36359"}]}
+func parsePortMappings(raw []byte) ([]config.PortMapping, error) {
+	ports := map[string][]struct {
+		HostIP   string `json:"HostIp"`
+		HostPort string `json:"HostPort"`
+	}{}
+	if err := json.Unmarshal(raw, &ports); err != nil {
+		return nil, errors.Wrap(err, "failed to parse port mappings")
+	}
+	mappings := []config.PortMapping{}
+	for containerPort, bindings := range ports {
+		parts := strings.Split(containerPort, "/")
+		port, err := strconv.ParseInt(parts[0], 10, 32)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid container port %q", containerPort)
+		}
+		protocol := config.PortMappingProtocolTCP
+		if len(parts) > 1 {
+			if v, ok := config.PortMappingProtocolNameToValue[strings.ToUpper(parts[1])]; ok {
+				protocol = v
+			}
+		}
+		// unpublished ports have no bindings
+		for _, binding := range bindings {
+			hostPort, err := strconv.ParseInt(binding.HostPort, 10, 32)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid host port %q", binding.HostPort)
+			}
+			mappings = append(mappings, config.PortMapping{
+				ContainerPort: int32(port),
+				HostPort:      int32(hostPort),
+				ListenAddress: binding.HostIP,
+				Protocol:      protocol,
+			})
+		}
+	}
+	// map iteration order is random, sort for stable output
+	sort.Slice(mappings, func(i, j int) bool {
+		if mappings[i].ContainerPort != mappings[j].ContainerPort {
+			return mappings[i].ContainerPort < mappings[j].ContainerPort
+		}
+		return mappings[i].HostPort < mappings[j].HostPort
+	})
+	return mappings, nil
+}
+
 // node returns a new node handle for this provider
 func (p *Provider) node(name string) nodes.Node {
 	return &node{
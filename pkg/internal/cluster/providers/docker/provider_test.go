@@ -0,0 +1,121 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docker
+
+import (
+	"reflect"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+func TestParsePortMappings(t *testing.T) {
+	cases := []struct {
+		Name      string
+		Raw       string
+		Expected  []config.PortMapping
+		ExpectErr bool
+	}{
+		{
+			Name:     "no ports",
+			Raw:      `{}`,
+			Expected: []config.PortMapping{},
+		},
+		{
+			Name:     "unpublished port",
+			Raw:      `{"80/tcp":null}`,
+			Expected: []config.PortMapping{},
+		},
+		{
+			Name: "published ports",
+			Raw:  `{"80/udp":[{"HostIp":"0.0.0.0","HostPort":"8080"}],"6443/tcp":[{"HostIp":"127.0.0.1","HostPort":"36359"}]}`,
+			Expected: []config.PortMapping{
+				{
+					ContainerPort: 80,
+					HostPort:      8080,
+					ListenAddress: "0.0.0.0",
+					Protocol:      config.PortMappingProtocolUDP,
+				},
+				{
+					ContainerPort: 6443,
+					HostPort:      36359,
+					ListenAddress: "127.0.0.1",
+					Protocol:      config.PortMappingProtocolTCP,
+				},
+			},
+		},
+		{
+			Name:      "invalid json",
+			Raw:       `[`,
+			ExpectErr: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			result, err := parsePortMappings([]byte(tc.Raw))
+			if (err != nil) != tc.ExpectErr {
+				t.Fatalf("parsePortMappings() error = %v, ExpectErr %v", err, tc.ExpectErr)
+			}
+			if !reflect.DeepEqual(result, tc.Expected) {
+				t.Errorf("parsePortMappings() = %v, expected %v", result, tc.Expected)
+			}
+		})
+	}
+}
+
+func TestLabelFilters(t *testing.T) {
+	cases := []struct {
+		Name      string
+		Selector  string
+		Expected  []string
+		ExpectErr bool
+	}{
+		{
+			Name:     "key",
+			Selector: "io.x-k8s.kind.cluster",
+			Expected: []string{"--filter", "label=io.x-k8s.kind.cluster"},
+		},
+		{
+			Name:     "keys and values",
+			Selector: "io.x-k8s.kind.cluster=ci, io.x-k8s.kind.role=worker,",
+			Expected: []string{
+				"--filter", "label=io.x-k8s.kind.cluster=ci",
+				"--filter", "label=io.x-k8s.kind.role=worker",
+			},
+		},
+		{
+			Name:      "empty",
+			Selector:  " , ",
+			ExpectErr: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			result, err := labelFilters(tc.Selector)
+			if (err != nil) != tc.ExpectErr {
+				t.Fatalf("labelFilters() error = %v, ExpectErr %v", err, tc.ExpectErr)
+			}
+			if !reflect.DeepEqual(result, tc.Expected) {
+				t.Errorf("labelFilters() = %v, expected %v", result, tc.Expected)
+			}
+		})
+	}
+}
@@ -27,15 +27,21 @@ import (
 	"sigs.k8s.io/kind/pkg/exec"
 
 	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/cluster/kubeadm"
 	"sigs.k8s.io/kind/pkg/internal/cluster/loadbalancer"
 	"sigs.k8s.io/kind/pkg/internal/cluster/providers/provider/common"
+	"sigs.k8s.io/kind/pkg/internal/cluster/registry"
 )
 
 // planCreation creates a slice of funcs that will create the containers
-func planCreation(cluster string, cfg *config.Cluster) (createContainerFuncs []func() error, err error) {
+func planCreation(cluster string, cfg *config.Cluster, declaredImages []string, protect bool) (createContainerFuncs []func() error, err error) {
 	// these apply to all container creation
 	nodeNamer := common.MakeNodeNamer(cluster)
-	genericArgs, err := commonArgs(cluster, cfg)
+	genericArgs, err := commonArgs(cluster, cfg, protect)
+	if err != nil {
+		return nil, err
+	}
+	addrs, err := hostAddrs()
 	if err != nil {
 		return nil, err
 	}
@@ -52,7 +58,7 @@ func planCreation(cluster string, cfg *config.Cluster) (createContainerFuncs []f
 		// plan loadbalancer node
 		name := nodeNamer(constants.ExternalLoadBalancerNodeRoleValue)
 		createContainerFuncs = append(createContainerFuncs, func() error {
-			args, err := runArgsForLoadBalancer(cfg, name, genericArgs)
+			args, err := runArgsForLoadBalancer(cfg, name, append(selinuxArgs(cfg), seededArgs(cfg, name, genericArgs)...))
 			if err != nil {
 				return err
 			}
@@ -61,24 +67,58 @@ func planCreation(cluster string, cfg *config.Cluster) (createContainerFuncs []f
 	}
 
 	// plan normal nodes
-	for _, node := range cfg.Nodes {
+	for i, node := range cfg.Nodes {
 		node := node.DeepCopy()              // copy so we can modify
 		name := nodeNamer(string(node.Role)) // name the node
+		nodeArgs := append(selinuxArgs(cfg), seededArgs(cfg, name, genericArgs)...)
+		nodeArgs = append(nodeArgs, "--label", fmt.Sprintf("%s=%s", constants.ImageLabelKey, declaredImages[i]))
+
+		// mount the kubelet image credential provider config and plugins
+		if cfg.KubeletCredentialProvider != nil && node.Role != config.RegistryRole {
+			node.ExtraMounts = append(node.ExtraMounts,
+				config.Mount{
+					HostPath:      cfg.KubeletCredentialProvider.Config,
+					ContainerPath: kubeadm.CredentialProviderConfigPath,
+					Readonly:      true,
+				},
+				config.Mount{
+					HostPath:      cfg.KubeletCredentialProvider.BinDir,
+					ContainerPath: kubeadm.CredentialProviderBinDir,
+					Readonly:      true,
+				},
+			)
+		}
+
+		// add the mounts shared by all nodes of a custom role
+		customRole := cfg.CustomRole(node.Role)
+		if customRole != nil {
+			node.ExtraMounts = append(node.ExtraMounts, customRole.ExtraMounts...)
+		}
 
 		// fixup relative paths, docker can only handle absolute paths
-		for i := range node.ExtraMounts {
-			hostPath := node.ExtraMounts[i].HostPath
-			absHostPath, err := filepath.Abs(hostPath)
-			if err != nil {
-				return nil, errors.Wrapf(err, "unable to resolve absolute path for hostPath: %q", hostPath)
+		if err := absMountPaths(node.ExtraMounts); err != nil {
+			return nil, err
+		}
+
+		// resolve the host addresses the port mappings listen on
+		node.ExtraPortMappings, err = resolvePortMappings(node.ExtraPortMappings, hostInterfaceAddrs)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid extraPortMappings for node %q", name)
+		}
+		if addrs != nil {
+			if err := validateListenAddresses(node.ExtraPortMappings, addrs); err != nil {
+				return nil, errors.Wrapf(err, "invalid extraPortMappings for node %q", name)
 			}
-			node.ExtraMounts[i].HostPath = absHostPath
 		}
 
 		// plan actual creation based on role
 		switch node.Role {
 		case config.ControlPlaneRole:
 			createContainerFuncs = append(createContainerFuncs, func() error {
+				// the API server listens on the host directly
+				if cfg.Networking.HostNetwork {
+					return createContainer(runArgsForHostNetworkNode(node, name, nodeArgs))
+				}
 				port, err := common.PortOrGetFreePort(apiServerPort, apiServerAddress)
 				if err != nil {
 					return errors.Wrap(err, "failed to get port for API server")
@@ -90,19 +130,118 @@ func planCreation(cluster string, cfg *config.Cluster) (createContainerFuncs []f
 						ContainerPort: common.APIServerInternalPort,
 					},
 				)
-				return createContainer(runArgsForNode(node, name, genericArgs))
+				if cfg.ControlPlaneMetrics != nil {
+					mappings, err := metricsPortMappings(cfg.ControlPlaneMetrics.ListenAddress)
+					if err != nil {
+						return err
+					}
+					node.ExtraPortMappings = append(node.ExtraPortMappings, mappings...)
+				}
+				return createContainer(runArgsForNode(node, name, nodeArgs))
 			})
 		case config.WorkerRole:
 			createContainerFuncs = append(createContainerFuncs, func() error {
-				return createContainer(runArgsForNode(node, name, genericArgs))
+				return createContainer(runArgsForNode(node, name, nodeArgs))
+			})
+		case config.RegistryRole:
+			createContainerFuncs = append(createContainerFuncs, func() error {
+				return createContainer(runArgsForRegistry(node, name, nodeArgs))
 			})
 		default:
+			// custom role nodes are workers
+			if customRole != nil {
+				createContainerFuncs = append(createContainerFuncs, func() error {
+					return createContainer(runArgsForNode(node, name, nodeArgs))
+				})
+				continue
+			}
 			return nil, errors.Errorf("unknown node role: %q", node.Role)
 		}
 	}
+
+	// plan auxiliary containers
+	for _, aux := range cfg.AuxiliaryContainers {
+		aux := aux.DeepCopy() // copy so we can modify
+		name := auxiliaryContainerName(cluster, aux.Name)
+		auxArgs := seededArgs(cfg, name, genericArgs)
+		if err := absMountPaths(aux.ExtraMounts); err != nil {
+			return nil, err
+		}
+		aux.ExtraPortMappings, err = resolvePortMappings(aux.ExtraPortMappings, hostInterfaceAddrs)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid extraPortMappings for auxiliary container %q", name)
+		}
+		if addrs != nil {
+			if err := validateListenAddresses(aux.ExtraPortMappings, addrs); err != nil {
+				return nil, errors.Wrapf(err, "invalid extraPortMappings for auxiliary container %q", name)
+			}
+		}
+		createContainerFuncs = append(createContainerFuncs, func() error {
+			return createContainer(runArgsForAuxiliary(aux, name, auxArgs))
+		})
+	}
 	return createContainerFuncs, nil
 }
 
+// selinuxArgs returns the args setting the SELinux label of node containers,
+// by default docker does not confine privileged containers
+func selinuxArgs(cfg *config.Cluster) []string {
+	if cfg.SELinuxLabel == "" {
+		return nil
+	}
+	return []string{"--security-opt", "label=" + cfg.SELinuxLabel}
+}
+
+// auxiliaryContainerName returns the container name of the cluster's
+// auxiliary container with name
+func auxiliaryContainerName(cluster, name string) string {
+	return fmt.Sprintf("%s-%s", cluster, name)
+}
+
+// absMountPaths makes the host paths of mounts absolute in place
+func absMountPaths(mounts []config.Mount) error {
+	for i := range mounts {
+		hostPath := mounts[i].HostPath
+		absHostPath, err := filepath.Abs(hostPath)
+		if err != nil {
+			return errors.Wrapf(err, "unable to resolve absolute path for hostPath: %q", hostPath)
+		}
+		mounts[i].HostPath = absHostPath
+	}
+	return nil
+}
+
+// metricsPortMappings returns the port mappings publishing the control plane
+// components' metrics endpoints on random ports of listenAddress
+func metricsPortMappings(listenAddress string) ([]config.PortMapping, error) {
+	mappings := []config.PortMapping{}
+	for _, containerPort := range []int32{common.ControllerManagerInternalPort, common.SchedulerInternalPort} {
+		port, err := common.PortOrGetFreePort(0, listenAddress)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get port for control plane metrics")
+		}
+		mappings = append(mappings, config.PortMapping{
+			ListenAddress: listenAddress,
+			HostPort:      port,
+			ContainerPort: containerPort,
+		})
+	}
+	return mappings, nil
+}
+
+// seededArgs prepends the node's identifiers derived from the config seed
+// to args, if there is a seed
+func seededArgs(cfg *config.Cluster, name string, args []string) []string {
+	if cfg.Seed == "" {
+		return args
+	}
+	return append([]string{
+		"--mac-address", common.SeededMAC(cfg.Seed, name),
+		// systemd uses this as the machine ID when run in a container
+		"--env", "container_uuid=" + common.SeededUUID(cfg.Seed, name),
+	}, args...)
+}
+
 func createContainer(args []string) error {
 	if err := exec.Command("docker", args...).Run(); err != nil {
 		return errors.Wrap(err, "docker run error")
@@ -126,7 +265,7 @@ func clusterHasImplicitLoadBalancer(cfg *config.Cluster) bool {
 }
 
 // commonArgs computes static arguments that apply to all containers
-func commonArgs(cluster string, cfg *config.Cluster) ([]string, error) {
+func commonArgs(cluster string, cfg *config.Cluster, protect bool) ([]string, error) {
 	// standard arguments all nodes containers need, computed once
 	args := []string{
 		"--detach", // run the container detached
@@ -135,13 +274,33 @@ func commonArgs(cluster string, cfg *config.Cluster) ([]string, error) {
 		"--label", fmt.Sprintf("%s=%s", constants.ClusterLabelKey, cluster),
 	}
 
-	// enable IPv6 if necessary
-	if clusterIsIPv6(cfg) {
+	// attach to the cluster's dedicated network, or share the host's
+	if cfg.Networking.HostNetwork {
+		args = append(args, "--net", "host")
+	} else {
+		args = append(args, "--net", networkName(cluster))
+	}
+
+	// mark the nodes as protected from deletion
+	if protect {
+		args = append(args, "--label", fmt.Sprintf("%s=true", constants.ProtectedLabelKey))
+	}
+
+	// run the containers in the cluster's systemd slice
+	if cfg.SystemdSlice != nil {
+		if err := checkSystemdCgroupDriver(); err != nil {
+			return nil, err
+		}
+		args = append(args, "--cgroup-parent", cfg.SystemdSlice.Name)
+	}
+
+	// enable IPv6 if necessary, the host's network is configured by the host
+	if clusterIsIPv6(cfg) && !cfg.Networking.HostNetwork {
 		args = append(args, "--sysctl=net.ipv6.conf.all.disable_ipv6=0", "--sysctl=net.ipv6.conf.all.forwarding=1")
 	}
 
 	// pass proxy environment variables
-	proxyEnv, err := getProxyEnv(cfg)
+	proxyEnv, err := getProxyEnv(cluster, cfg)
 	if err != nil {
 		return nil, errors.Wrap(err, "proxy setup error")
 	}
@@ -185,6 +344,11 @@ func runArgsForNode(node *config.Node, name string, args []string) []string {
 		args...,
 	)
 
+	if node.RestartPolicy != "" {
+		args = append(args, "--restart", node.RestartPolicy)
+	}
+	args = append(args, generateUlimits(node.Ulimits...)...)
+
 	// convert mounts and port mappings to container run args
 	args = append(args, generateMountBindings(node.ExtraMounts...)...)
 	args = append(args, generatePortMappings(node.ExtraPortMappings...)...)
@@ -193,6 +357,18 @@ func runArgsForNode(node *config.Node, name string, args []string) []string {
 	return append(args, node.Image)
 }
 
+// runArgsForHostNetworkNode is runArgsForNode for a node in the host's
+// network namespace, which shares the host's hostname
+func runArgsForHostNetworkNode(node *config.Node, name string, args []string) []string {
+	args = runArgsForNode(node, name, args)
+	for i := range args {
+		if args[i] == "--hostname" {
+			return append(args[:i:i], args[i+2:]...)
+		}
+	}
+	return args
+}
+
 func runArgsForLoadBalancer(cfg *config.Cluster, name string, args []string) ([]string, error) {
 	args = append([]string{
 		"run",
@@ -220,12 +396,77 @@ func runArgsForLoadBalancer(cfg *config.Cluster, name string, args []string) ([]
 	return append(args, loadbalancer.Image), nil
 }
 
-func getProxyEnv(cfg *config.Cluster) (map[string]string, error) {
+func runArgsForRegistry(node *config.Node, name string, args []string) []string {
+	args = append([]string{
+		"run",
+		"--hostname", name, // make hostname match container name
+		"--name", name, // ... and set the container name
+		// label the node with the role ID
+		"--label", fmt.Sprintf("%s=%s", constants.NodeRoleKey, node.Role),
+		// serve with the certificates kind generates, see the registry action
+		"--env", fmt.Sprintf("REGISTRY_HTTP_ADDR=0.0.0.0:%d", registry.Port),
+		"--env", "REGISTRY_HTTP_TLS_CERTIFICATE=" + registry.CertPath,
+		"--env", "REGISTRY_HTTP_TLS_KEY=" + registry.KeyPath,
+		// registry storage, may be overridden by extraMounts
+		"--volume", registry.StoragePath,
+		"--entrypoint", "/bin/sh",
+	},
+		args...,
+	)
+
+	if node.RestartPolicy != "" {
+		args = append(args, "--restart", node.RestartPolicy)
+	}
+	args = append(args, generateUlimits(node.Ulimits...)...)
+
+	// convert mounts and port mappings to container run args
+	args = append(args, generateMountBindings(node.ExtraMounts...)...)
+	args = append(args, generatePortMappings(node.ExtraPortMappings...)...)
+
+	// specify the image to run, and wait for the certificates to be written
+	// before actually starting the registry
+	return append(args,
+		node.Image,
+		"-c", fmt.Sprintf(
+			"while [ ! -f %s ]; do sleep 0.1; done; exec registry serve /etc/docker/registry/config.yml",
+			registry.KeyPath,
+		),
+	)
+}
+
+func runArgsForAuxiliary(aux *config.AuxiliaryContainer, name string, args []string) []string {
+	args = append([]string{
+		"run",
+		"--hostname", aux.Name, // nodes reach the container by this name
+		"--name", name,
+		// label the container with the role ID
+		"--label", fmt.Sprintf("%s=%s", constants.NodeRoleKey, constants.AuxiliaryNodeRoleValue),
+		"--network-alias", aux.Name,
+	},
+		args...,
+	)
+
+	if aux.Privileged {
+		args = append(args, "--privileged")
+	}
+	for _, env := range aux.Env {
+		args = append(args, "--env", env.Name+"="+env.Value)
+	}
+
+	// convert mounts and port mappings to container run args
+	args = append(args, generateMountBindings(aux.ExtraMounts...)...)
+	args = append(args, generatePortMappings(aux.ExtraPortMappings...)...)
+
+	// finally, specify the image and optionally command to run
+	args = append(args, aux.Image)
+	return append(args, aux.Command...)
+}
+
+func getProxyEnv(cluster string, cfg *config.Cluster) (map[string]string, error) {
 	envs := common.GetProxyEnvs(cfg)
 	// Specifically add the docker network subnets to NO_PROXY if we are using a proxy
-	if len(envs) > 0 {
-		// Docker default bridge network is named "bridge" (https://docs.docker.com/network/bridge/#use-the-default-bridge-network)
-		subnets, err := getSubnets("bridge")
+	if len(envs) > 0 && !cfg.Networking.HostNetwork {
+		subnets, err := getSubnets(networkName(cluster))
 		if err != nil {
 			return nil, err
 		}
@@ -251,6 +492,7 @@ func getSubnets(networkName string) ([]string, error) {
 // is a comma-separated list of the following strings:
 // 'ro', if the path is read only
 // 'Z', if the volume requires SELinux relabeling
+// 'z', if the volume requires SELinux relabeling shared with other containers
 func generateMountBindings(mounts ...config.Mount) []string {
 	args := make([]string, 0, len(mounts))
 	for _, m := range mounts {
@@ -262,8 +504,11 @@ func generateMountBindings(mounts ...config.Mount) []string {
 		// Only request relabeling if the pod provides an SELinux context. If the pod
 		// does not provide an SELinux context relabeling will label the volume with
 		// the container's randomly allocated MCS label. This would restrict access
-		// to the volume to the container which mounts it first.
-		if m.SelinuxRelabel {
+		// to the volume to the container which mounts it first, unless the
+		// shared label is requested.
+		if m.SelinuxRelabel && m.SelinuxRelabelShared {
+			attrs = append(attrs, "z")
+		} else if m.SelinuxRelabel {
 			attrs = append(attrs, "Z")
 		}
 		switch m.Propagation {
@@ -283,6 +528,19 @@ func generateMountBindings(mounts ...config.Mount) []string {
 	return args
 }
 
+// generateUlimits converts the ulimits to a list of args for docker
+func generateUlimits(ulimits ...config.Ulimit) []string {
+	args := make([]string, 0, len(ulimits))
+	for _, u := range ulimits {
+		if u.Hard == 0 {
+			args = append(args, fmt.Sprintf("--ulimit=%s=%d", u.Name, u.Soft))
+			continue
+		}
+		args = append(args, fmt.Sprintf("--ulimit=%s=%d:%d", u.Name, u.Soft, u.Hard))
+	}
+	return args
+}
+
 // generatePortMappings converts the portMappings list to a list of args for docker
 func generatePortMappings(portMappings ...config.PortMapping) []string {
 	args := make([]string, 0, len(portMappings))
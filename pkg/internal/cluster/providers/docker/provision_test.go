@@ -0,0 +1,43 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docker
+
+import (
+	"reflect"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+func TestGenerateMountBindings(t *testing.T) {
+	mounts := []config.Mount{
+		{HostPath: "/a", ContainerPath: "/a"},
+		{HostPath: "/b", ContainerPath: "/b", Readonly: true, SelinuxRelabel: true},
+		{HostPath: "/c", ContainerPath: "/c", SelinuxRelabel: true, SelinuxRelabelShared: true},
+		// the shared label is only used when relabeling
+		{HostPath: "/d", ContainerPath: "/d", SelinuxRelabelShared: true, Propagation: config.MountPropagationHostToContainer},
+	}
+	expected := []string{
+		"--volume=/a:/a",
+		"--volume=/b:/b:ro,Z",
+		"--volume=/c:/c:z",
+		"--volume=/d:/d:rslave",
+	}
+	if result := generateMountBindings(mounts...); !reflect.DeepEqual(result, expected) {
+		t.Errorf("generateMountBindings() = %v, expected %v", result, expected)
+	}
+}
@@ -19,3 +19,11 @@ package common
 // APIServerInternalPort defines the port where the control plane is listening
 // _inside_ the node network
 const APIServerInternalPort = 6443
+
+// ControllerManagerInternalPort and SchedulerInternalPort are the secure
+// ports the kube-controller-manager and kube-scheduler serve their metrics
+// and profiling endpoints on _inside_ the node network
+const (
+	ControllerManagerInternalPort = 10257
+	SchedulerInternalPort         = 10259
+)
@@ -0,0 +1,44 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"crypto/sha256"
+	"fmt"
+)
+
+// SeededMAC returns a locally administered unicast MAC address for the node
+// name derived from seed, the same inputs always return the same address
+func SeededMAC(seed, name string) string {
+	sum := seeded(seed, name)
+	// set the locally administered bit and clear the multicast bit
+	sum[0] = (sum[0] | 0x02) &^ 0x01
+	return fmt.Sprintf("%02x:%02x:%02x:%02x:%02x:%02x", sum[0], sum[1], sum[2], sum[3], sum[4], sum[5])
+}
+
+// SeededUUID returns a version 4 format UUID for the node name derived from
+// seed, the same inputs always return the same UUID
+func SeededUUID(seed, name string) string {
+	sum := seeded(seed, name)
+	sum[6] = (sum[6] & 0x0f) | 0x40 // version 4
+	sum[8] = (sum[8] & 0x3f) | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
+
+func seeded(seed, name string) [sha256.Size]byte {
+	return sha256.Sum256([]byte(seed + "/" + name))
+}
@@ -0,0 +1,45 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestSeeded(t *testing.T) {
+	mac := regexp.MustCompile(`^[0-9a-f][26ae](:[0-9a-f]{2}){5}$`)
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	for _, name := range []string{"kind-control-plane", "kind-worker", "kind-worker2"} {
+		if got := SeededMAC("seed", name); !mac.MatchString(got) {
+			t.Errorf("SeededMAC(%q) = %q, not a locally administered unicast MAC", name, got)
+		} else if again := SeededMAC("seed", name); again != got {
+			t.Errorf("SeededMAC(%q) is not stable: %q != %q", name, got, again)
+		}
+		if got := SeededUUID("seed", name); !uuid.MatchString(got) {
+			t.Errorf("SeededUUID(%q) = %q, not a version 4 UUID", name, got)
+		} else if again := SeededUUID("seed", name); again != got {
+			t.Errorf("SeededUUID(%q) is not stable: %q != %q", name, got, again)
+		}
+	}
+	if SeededMAC("seed", "kind-worker") == SeededMAC("other", "kind-worker") {
+		t.Errorf("SeededMAC ignores the seed")
+	}
+	if SeededUUID("seed", "kind-worker") == SeededUUID("seed", "kind-worker2") {
+		t.Errorf("SeededUUID ignores the node name")
+	}
+}
@@ -17,6 +17,9 @@ limitations under the License.
 package provider
 
 import (
+	"io"
+	"time"
+
 	"sigs.k8s.io/kind/pkg/cluster/nodes"
 
 	"sigs.k8s.io/kind/pkg/internal/apis/config"
@@ -28,17 +31,80 @@ import (
 type Provider interface {
 	// Provision should create and start the nodes, just short of
 	// actually starting up Kubernetes, based on the given cluster config
-	Provision(status *cli.Status, cluster string, cfg *config.Cluster) error
+	// If protect is set the nodes should be marked as protected from deletion
+	Provision(status *cli.Status, cluster string, cfg *config.Cluster, protect bool) error
 	// ListClusters discovers the clusters that currently have resources
 	// under this providers
 	ListClusters() ([]string, error)
 	// ListNodes returns the nodes under this provider for the given
 	// cluster name, they may or may not be running correctly
 	ListNodes(cluster string) ([]nodes.Node, error)
+	// ListNodesByLabel returns the containers under this provider matching
+	// the label selector, key or key=value joined by commas, regardless of
+	// which cluster they belong to, EG nodes of clusters created by a fork
+	// of kind with its own labels
+	ListNodesByLabel(selector string) ([]nodes.Node, error)
 	// DeleteNodes deletes the provided list of nodes
 	// These should be from results previously returned by this provider
 	// E.G. by ListNodes()
 	DeleteNodes([]nodes.Node) error
+	// StopNodes stops the provided list of nodes without deleting them
+	StopNodes([]nodes.Node) error
+	// StartNodes starts the provided list of previously stopped nodes
+	StartNodes([]nodes.Node) error
+	// UpdateNodeResources changes the resource limits of the running node,
+	// zero values leave that limit unchanged
+	UpdateNodeResources(n nodes.Node, r NodeResources) error
+	// CheckpointNodes saves the process state of the provided list of running
+	// nodes under the checkpoint name and stops them, this is experimental
+	CheckpointNodes(n []nodes.Node, checkpoint string) error
+	// RestoreNodes starts the provided list of nodes from the checkpoint name
+	// previously created by CheckpointNodes, this is experimental
+	RestoreNodes(n []nodes.Node, checkpoint string) error
+	// GetMemoryUsage returns the total memory in bytes currently used by the
+	// provided list of nodes
+	GetMemoryUsage([]nodes.Node) (uint64, error)
+	// GetNodeStats returns the creation time, disk usage and image of the
+	// node
+	GetNodeStats(n nodes.Node) (*NodeStats, error)
+	// IsProtected returns true if the cluster's nodes were provisioned as
+	// protected from deletion
+	IsProtected(cluster string) (bool, error)
+	// DeleteNetwork deletes any network created for the cluster,
+	// this should be called after deleting all of the cluster's nodes
+	DeleteNetwork(cluster string) error
+	// GetNetworkInterface returns the name of the host network interface
+	// of the cluster's network, if any
+	GetNetworkInterface(cluster string) (string, error)
+	// ConnectNodes attaches the provided nodes, typically of another
+	// cluster, to the cluster's network so they can reach its nodes directly
+	ConnectNodes(cluster string, n []nodes.Node) error
+	// CopyFromImage runs readerFunc with a tar archive of path within image,
+	// the image is pulled if it is not present
+	CopyFromImage(image, path string, readerFunc func(io.Reader) error) error
 	// GetAPIServerEndpoint returns the host endpoint for the cluster's API server
 	GetAPIServerEndpoint(cluster string) (string, error)
+	// Compose returns a docker compose file describing the containers of
+	// the provided nodes and the networks they are attached to
+	Compose(n []nodes.Node) ([]byte, error)
+}
+
+// NodeResources are resource limits of a node
+type NodeResources struct {
+	// CPUs is the number of CPUs the node may use, it may be fractional
+	CPUs float64
+	// MemoryBytes is the memory the node may use
+	MemoryBytes uint64
+}
+
+// NodeStats are provider level statistics about a node
+type NodeStats struct {
+	// Created is when the node was created
+	Created time.Time
+	// DiskBytes is the disk space consumed by the node, not including
+	// the node image it was created from
+	DiskBytes uint64
+	// Image is the image the node was created from, as it was declared in
+	// the config rather than the debug or platform variant it runs
+	Image string
 }
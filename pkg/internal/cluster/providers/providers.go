@@ -23,6 +23,7 @@ import (
 	"sigs.k8s.io/kind/pkg/internal/cluster/providers/provider"
 )
 
+// Docker is the name of the docker provider, the only one implemented
 const Docker string = "docker"
 
 // New creates a new instance of the named provider
@@ -0,0 +1,112 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+// certValidity is how long generated certificates are valid for
+const certValidity = 365 * 24 * time.Hour
+
+// Certs contains PEM encoded registry TLS material
+type Certs struct {
+	// CACert is the CA certificate, to be trusted by clients
+	CACert []byte
+	// Cert is the serving certificate, signed by the CA
+	Cert []byte
+	// Key is the serving certificate's private key
+	Key []byte
+}
+
+// GenerateCerts generates a new CA and a serving certificate signed by it,
+// valid for the hosts (DNS names or IP addresses) given
+func GenerateCerts(hosts ...string) (*Certs, error) {
+	now := time.Now()
+
+	// generate the CA
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate CA key")
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "kind-registry-ca"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(certValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create CA certificate")
+	}
+
+	// generate the serving certificate
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate serving key")
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "kind-registry"},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(certValidity),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, caTemplate, &key.PublicKey, caKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create serving certificate")
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal serving key")
+	}
+
+	return &Certs{
+		CACert: encodePEM("CERTIFICATE", caDER),
+		Cert:   encodePEM("CERTIFICATE", der),
+		Key:    encodePEM("EC PRIVATE KEY", keyDER),
+	}, nil
+}
+
+func encodePEM(blockType string, der []byte) []byte {
+	var buff bytes.Buffer
+	// writing to a bytes.Buffer cannot fail
+	_ = pem.Encode(&buff, &pem.Block{Type: blockType, Bytes: der})
+	return buff.Bytes()
+}
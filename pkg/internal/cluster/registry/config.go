@@ -0,0 +1,92 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"bytes"
+	"text/template"
+
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+// ContainerdConfigPath is the path to the containerd config on the nodes
+const ContainerdConfigPath = "/etc/containerd/config.toml"
+
+// CRIOConfigPath is the path to the registries config used by cri-o on the nodes
+const CRIOConfigPath = "/etc/containers/registries.conf"
+
+// CRIOCAPath returns the path cri-o loads the CA for endpoint from
+func CRIOCAPath(endpoint string) string {
+	return "/etc/containers/certs.d/" + endpoint + "/ca.crt"
+}
+
+// MirrorConfigData is supplied to the mirror config template
+type MirrorConfigData struct {
+	// Hosts are the registry hosts (host:port) to mirror to the registry
+	Hosts []string
+	// Endpoint is the address (host:port) the registry is reachable at
+	Endpoint string
+	// CAPath is the path to the registry CA certificate on the node
+	CAPath string
+}
+
+// MirrorConfigTemplate is appended to the containerd config on every
+// kubernetes node to use the registry node as a mirror
+const MirrorConfigTemplate = `
+# registry node mirror, generated by kind
+{{- range .Hosts }}
+[plugins."io.containerd.grpc.v1.cri".registry.mirrors."{{ . }}"]
+  endpoint = ["https://{{ $.Endpoint }}"]
+{{- end }}
+[plugins."io.containerd.grpc.v1.cri".registry.configs."{{ .Endpoint }}".tls]
+  ca_file = "{{ .CAPath }}"
+`
+
+// CRIOMirrorConfigTemplate is appended to the cri-o registries config on
+// every kubernetes node to use the registry node as a mirror, cri-o loads
+// the registry CA from CRIOCAPath so CAPath is unused
+const CRIOMirrorConfigTemplate = `
+# registry node mirror, generated by kind
+{{- range .Hosts }}
+[[registry]]
+location = "{{ . }}"
+[[registry.mirror]]
+location = "{{ $.Endpoint }}"
+{{- end }}
+`
+
+// MirrorConfig returns the containerd config to append to each node
+func MirrorConfig(data *MirrorConfigData) (string, error) {
+	return executeTemplate(MirrorConfigTemplate, data)
+}
+
+// CRIOMirrorConfig returns the cri-o registries config to append to each node
+func CRIOMirrorConfig(data *MirrorConfigData) (string, error) {
+	return executeTemplate(CRIOMirrorConfigTemplate, data)
+}
+
+func executeTemplate(configTemplate string, data *MirrorConfigData) (string, error) {
+	t, err := template.New("registry-mirror-config").Parse(configTemplate)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to parse config template")
+	}
+	var buff bytes.Buffer
+	if err := t.Execute(&buff, data); err != nil {
+		return "", errors.Wrap(err, "error executing config template")
+	}
+	return buff.String(), nil
+}
@@ -0,0 +1,37 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+// Port is the port the registry listens on within the cluster network
+const Port = 5000
+
+// CertDir is the directory containing the serving certificate and key
+// on the registry node
+const CertDir = "/certs"
+
+// CertPath is the path to the serving certificate on the registry node
+const CertPath = CertDir + "/tls.crt"
+
+// KeyPath is the path to the serving key on the registry node
+// The registry will not start serving until this file exists
+const KeyPath = CertDir + "/tls.key"
+
+// CAPath is the path to the registry CA certificate on the other nodes
+const CAPath = "/kind/registry/ca.crt"
+
+// StoragePath is the path registry storage is kept at on the registry node
+const StoragePath = "/var/lib/registry"
@@ -0,0 +1,90 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package runtime contains the details of the container runtimes (CRI
+// implementations) that may be run inside the nodes
+package runtime
+
+import (
+	"bytes"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+// Runtime describes how to manage and talk to a container runtime on a node
+type Runtime struct {
+	// Name is the name of the runtime as used in the cluster config
+	Name string
+	// Service is the name of the runtime's systemd unit
+	Service string
+	// Socket is the path to the runtime's CRI socket
+	Socket string
+	// Root is the directory the runtime stores images and container
+	// writable layers in, the kubelet's image filesystem
+	Root string
+	// ImportCommand reads an image archive from stdin into the runtime's
+	// image store
+	ImportCommand []string
+}
+
+// Containerd is the default runtime
+var Containerd = Runtime{
+	Name:          "containerd",
+	Service:       "containerd",
+	Socket:        "/run/containerd/containerd.sock",
+	Root:          "/var/lib/containerd",
+	ImportCommand: []string{"ctr", "--namespace=k8s.io", "images", "import", "-"},
+}
+
+// CRIO is the cri-o runtime, which is only present in node images built
+// from a cri-o base image
+var CRIO = Runtime{
+	Name:    "cri-o",
+	Service: "crio",
+	Socket:  "/var/run/crio/crio.sock",
+	Root:    "/var/lib/containers/storage",
+	// cri-o and podman share the same image store
+	ImportCommand: []string{"podman", "load"},
+}
+
+// MarkerPath is the file recording the runtime selected for a node,
+// nodes without it run the default runtime
+const MarkerPath = "/kind/container-runtime"
+
+// ForName returns the Runtime with name
+func ForName(name string) (Runtime, error) {
+	switch name {
+	case "", Containerd.Name:
+		return Containerd, nil
+	case CRIO.Name:
+		return CRIO, nil
+	}
+	return Runtime{}, errors.Errorf("unknown container runtime %q", name)
+}
+
+// ForNode returns the Runtime selected for node
+func ForNode(node nodes.Node) (Runtime, error) {
+	var buff bytes.Buffer
+	// a missing marker means the default runtime
+	if err := node.Command(
+		"sh", "-c", "cat "+MarkerPath+" 2>/dev/null || true",
+	).SetStdout(&buff).Run(); err != nil {
+		return Runtime{}, errors.Wrap(err, "failed to get node container runtime")
+	}
+	return ForName(strings.TrimSpace(buff.String()))
+}
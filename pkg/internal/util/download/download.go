@@ -0,0 +1,291 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package download fetches external artifacts, such as addon manifests, on
+// the host with retries and proxy support, verifying their checksum and
+// caching them if one is given, and rewriting their URLs to configured
+// mirrors so that air-gapped hosts are configured the same way for all of
+// them
+package download
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/globals"
+	"sigs.k8s.io/kind/pkg/internal/util/env"
+)
+
+// MirrorsEnv configures mirrors for all downloads as comma separated
+// <prefix>=<replacement> URL prefix rewrites, EG
+// https://github.com/=https://mirror.example.com/github/
+const MirrorsEnv = "KIND_DOWNLOAD_MIRRORS"
+
+// checksumPrefix prefixes the expected sha256 in the fragment of a URL, EG
+// https://example.com/manifest.yaml#sha256=<hex>
+const checksumPrefix = "sha256="
+
+// Artifact is a file to download
+type Artifact struct {
+	// URL is the http or https URL of the file, without the checksum
+	URL string
+	// SHA256 is the expected hex encoded sha256 of the file, if known
+	SHA256 string
+}
+
+// IsURL returns true if source is an http or https URL rather than a path
+func IsURL(source string) bool {
+	return strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://")
+}
+
+// Parse returns the artifact at the URL source, which may carry its
+// expected checksum as a #sha256=<hex> fragment
+func Parse(source string) (*Artifact, error) {
+	if !IsURL(source) {
+		return nil, errors.Errorf("%q is not an http or https URL", source)
+	}
+	u, err := url.Parse(source)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid URL %q", source)
+	}
+	a := &Artifact{}
+	if strings.HasPrefix(u.Fragment, checksumPrefix) {
+		a.SHA256 = strings.ToLower(strings.TrimPrefix(u.Fragment, checksumPrefix))
+		if b, err := hex.DecodeString(a.SHA256); err != nil || len(b) != sha256.Size {
+			return nil, errors.Errorf("invalid sha256 %q in URL %q", a.SHA256, source)
+		}
+		u.Fragment = ""
+	}
+	a.URL = u.String()
+	return a, nil
+}
+
+// DefaultCacheDir returns the default directory verified downloads are
+// cached in
+func DefaultCacheDir() string {
+	return filepath.Join(env.HomeDir(), ".kind", "cache", "downloads")
+}
+
+// mirror rewrites URLs starting with prefix to start with replacement
+type mirror struct {
+	prefix      string
+	replacement string
+}
+
+// parseMirrors parses the MirrorsEnv format
+func parseMirrors(value string) ([]mirror, error) {
+	mirrors := []mirror{}
+	for _, m := range strings.Split(value, ",") {
+		m = strings.TrimSpace(m)
+		if m == "" {
+			continue
+		}
+		parts := strings.SplitN(m, "=", 2)
+		if len(parts) != 2 || !IsURL(parts[0]) || !IsURL(parts[1]) {
+			return nil, errors.Errorf("invalid mirror %q, must be <prefix>=<replacement> URLs", m)
+		}
+		mirrors = append(mirrors, mirror{prefix: parts[0], replacement: parts[1]})
+	}
+	return mirrors, nil
+}
+
+// Downloader downloads artifacts
+type Downloader struct {
+	client   *http.Client
+	cacheDir string
+	mirrors  []mirror
+	attempts int
+	backoff  time.Duration
+}
+
+// Option is a Downloader configuration option supplied to New
+type Option func(*Downloader)
+
+// WithCacheDir sets the directory artifacts with a known checksum are
+// cached in, if empty nothing is cached
+func WithCacheDir(dir string) Option {
+	return func(d *Downloader) {
+		d.cacheDir = dir
+	}
+}
+
+// WithAttempts sets how many times each download is attempted, waiting
+// backoff before the first retry and doubling it for each retry after
+func WithAttempts(attempts int, backoff time.Duration) Option {
+	return func(d *Downloader) {
+		d.attempts = attempts
+		d.backoff = backoff
+	}
+}
+
+// New returns a Downloader with the default configuration, the mirrors in
+// MirrorsEnv and the proxy configured by the environment, overridden by
+// the options supplied in the order that they are supplied
+func New(options ...Option) (*Downloader, error) {
+	mirrors, err := parseMirrors(os.Getenv(MirrorsEnv))
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid %s", MirrorsEnv)
+	}
+	d := &Downloader{
+		client: &http.Client{
+			Transport: &http.Transport{Proxy: http.ProxyFromEnvironment},
+			Timeout:   2 * time.Minute,
+		},
+		cacheDir: DefaultCacheDir(),
+		mirrors:  mirrors,
+		attempts: 3,
+		backoff:  time.Second,
+	}
+	for _, option := range options {
+		option(d)
+	}
+	return d, nil
+}
+
+// Fetch returns the contents of the artifact at the URL source, see Parse,
+// using the default Downloader
+func Fetch(ctx context.Context, source string) ([]byte, error) {
+	a, err := Parse(source)
+	if err != nil {
+		return nil, err
+	}
+	d, err := New()
+	if err != nil {
+		return nil, err
+	}
+	return d.Fetch(ctx, a)
+}
+
+// Fetch returns the contents of the artifact, from the cache if its
+// checksum is known and it was downloaded before
+func (d *Downloader) Fetch(ctx context.Context, a *Artifact) ([]byte, error) {
+	cached := ""
+	if a.SHA256 != "" && d.cacheDir != "" {
+		cached = filepath.Join(d.cacheDir, a.SHA256)
+		if contents, err := ioutil.ReadFile(cached); err == nil && verify(contents, a.SHA256) == nil {
+			return contents, nil
+		}
+	}
+
+	location := d.mirror(a.URL)
+	var contents []byte
+	var err error
+	backoff := d.backoff
+	for attempt := 1; ; attempt++ {
+		contents, err = d.get(ctx, location)
+		if err == nil || attempt >= d.attempts || !retryable(err) {
+			break
+		}
+		globals.GetLogger().V(1).Infof("Retrying download of %s in %v: %v", location, backoff, err)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to download %s", location)
+	}
+	if a.SHA256 != "" {
+		if err := verify(contents, a.SHA256); err != nil {
+			return nil, errors.Wrapf(err, "failed to verify %s", location)
+		}
+	}
+
+	if cached != "" {
+		// write to a temporary file first so interrupted writes are not cached
+		if err := os.MkdirAll(d.cacheDir, 0755); err != nil {
+			return nil, errors.Wrap(err, "failed to create download cache dir")
+		}
+		tmp := cached + ".partial"
+		if err := ioutil.WriteFile(tmp, contents, 0644); err != nil {
+			return nil, errors.Wrap(err, "failed to cache download")
+		}
+		if err := os.Rename(tmp, cached); err != nil {
+			return nil, errors.Wrap(err, "failed to cache download")
+		}
+	}
+	return contents, nil
+}
+
+// mirror returns u rewritten by the mirror with the longest matching prefix
+func (d *Downloader) mirror(u string) string {
+	best := mirror{}
+	for _, m := range d.mirrors {
+		if strings.HasPrefix(u, m.prefix) && len(m.prefix) > len(best.prefix) {
+			best = m
+		}
+	}
+	if best.prefix == "" {
+		return u
+	}
+	return best.replacement + strings.TrimPrefix(u, best.prefix)
+}
+
+// statusError is a download failing with an unexpected HTTP status
+type statusError struct {
+	code int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("unexpected status %d %s", e.code, http.StatusText(e.code))
+}
+
+// retryable returns true unless err is a status that will not change on
+// retrying, EG not found
+func retryable(err error) bool {
+	if s, ok := err.(*statusError); ok {
+		return s.code >= 500 || s.code == http.StatusTooManyRequests || s.code == http.StatusRequestTimeout
+	}
+	return true
+}
+
+// get downloads u
+func (d *Downloader) get(ctx context.Context, u string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := d.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, &statusError{code: resp.StatusCode}
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+// verify returns an error unless contents have the hex encoded sha256
+func verify(contents []byte, expected string) error {
+	sum := sha256.Sum256(contents)
+	if actual := hex.EncodeToString(sum[:]); actual != expected {
+		return errors.Errorf("sha256 mismatch, expected %s but got %s", expected, actual)
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package download
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const manifest = "kind: ConfigMap\n"
+
+func manifestSHA256() string {
+	sum := sha256.Sum256([]byte(manifest))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name        string
+		Source      string
+		Expected    Artifact
+		ExpectError bool
+	}{
+		{
+			Name:     "plain URL",
+			Source:   "https://example.com/manifest.yaml",
+			Expected: Artifact{URL: "https://example.com/manifest.yaml"},
+		},
+		{
+			Name:     "checksum",
+			Source:   "https://example.com/manifest.yaml#sha256=" + manifestSHA256(),
+			Expected: Artifact{URL: "https://example.com/manifest.yaml", SHA256: manifestSHA256()},
+		},
+		{
+			Name:        "invalid checksum",
+			Source:      "https://example.com/manifest.yaml#sha256=abc",
+			ExpectError: true,
+		},
+		{
+			Name:        "path",
+			Source:      "./manifest.yaml",
+			ExpectError: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			a, err := Parse(tc.Source)
+			if tc.ExpectError {
+				if err == nil {
+					t.Fatalf("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if *a != tc.Expected {
+				t.Errorf("Parse() = %+v, expected %+v", *a, tc.Expected)
+			}
+		})
+	}
+}
+
+func TestFetch(t *testing.T) {
+	t.Parallel()
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// fail the first request to each path
+		if atomic.AddInt32(&requests, 1)%2 == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.URL.Path == "/mirror/manifest.yaml" {
+			_, _ = w.Write([]byte(manifest))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "kind-download")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	d, err := New(WithCacheDir(dir), WithAttempts(2, time.Millisecond))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	d.mirrors = []mirror{{prefix: "https://example.com/", replacement: server.URL + "/mirror/"}}
+
+	a := &Artifact{URL: "https://example.com/manifest.yaml", SHA256: manifestSHA256()}
+	contents, err := d.Fetch(context.Background(), a)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(contents) != manifest {
+		t.Errorf("Fetch() = %q, expected %q", contents, manifest)
+	}
+
+	// verified downloads are served from the cache
+	before := atomic.LoadInt32(&requests)
+	if _, err := d.Fetch(context.Background(), a); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if after := atomic.LoadInt32(&requests); after != before {
+		t.Errorf("expected no requests for a cached download, got %d", after-before)
+	}
+
+	// checksum mismatches fail
+	bad := &Artifact{URL: a.URL, SHA256: hex.EncodeToString(make([]byte, sha256.Size))}
+	if _, err := d.Fetch(context.Background(), bad); err == nil {
+		t.Errorf("expected an error for a checksum mismatch")
+	}
+}
+
+func TestParseMirrors(t *testing.T) {
+	t.Parallel()
+	mirrors, err := parseMirrors("https://github.com/=https://mirror.local/github/, https://example.com/=http://mirror.local/")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(mirrors) != 2 {
+		t.Fatalf("expected 2 mirrors, got %v", mirrors)
+	}
+	if _, err := parseMirrors("github.com"); err == nil {
+		t.Errorf("expected an error for an invalid mirror")
+	}
+}
@@ -0,0 +1,187 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package firewall detects host firewall configurations known to break
+// traffic between the host and kind nodes, and manages the rules kind adds
+// to allow it
+package firewall
+
+import (
+	"fmt"
+	"io/ioutil"
+	osexec "os/exec"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/globals"
+)
+
+// these are well known host paths
+const (
+	firewalldConfPath = "/etc/firewalld/firewalld.conf"
+	ufwDefaultsPath   = "/etc/default/ufw"
+)
+
+// Detect returns a description of each problem found with the host
+// firewall, most checks require root and are skipped otherwise
+func Detect() []string {
+	problems := []string{}
+	legacy, nft := countRules("iptables-legacy-save"), countRules("iptables-nft-save")
+	if legacy > 0 && nft > 0 {
+		problems = append(problems, fmt.Sprintf(
+			"the host has iptables rules in both the legacy (%d) and nftables (%d) backends, "+
+				"docker and the host firewall may not see each other's rules",
+			legacy, nft,
+		))
+	}
+	if firewalldRunning() && confValue(readFile(firewalldConfPath), "FirewallBackend") == "nftables" {
+		problems = append(problems,
+			"firewalld is running with the nftables backend, which may drop traffic on the cluster network",
+		)
+	}
+	if ufwActive() && confValue(readFile(ufwDefaultsPath), "DEFAULT_FORWARD_POLICY") == "DROP" {
+		problems = append(problems,
+			"ufw is active and drops forwarded traffic, which may drop traffic on the cluster network",
+		)
+	}
+	return problems
+}
+
+// Allow adds rules allowing all traffic on the network interface iface to
+// each active host firewall, they should be removed with Remove
+func Allow(iface string) error {
+	errs := []error{}
+	if firewalldRunning() {
+		errs = appendErr(errs, run("firewall-cmd", "--zone=trusted", "--add-interface="+iface))
+	}
+	if ufwActive() {
+		errs = appendErr(errs, run("ufw", "route", "allow", "in", "on", iface))
+		errs = appendErr(errs, run("ufw", "route", "allow", "out", "on", iface))
+	}
+	// docker evaluates the DOCKER-USER chain before its own forwarding rules
+	if hasDockerUserChain() {
+		errs = appendErr(errs, run("iptables", "-I", "DOCKER-USER", "-i", iface, "-j", "ACCEPT"))
+		errs = appendErr(errs, run("iptables", "-I", "DOCKER-USER", "-o", iface, "-j", "ACCEPT"))
+	}
+	if len(errs) > 0 {
+		return errors.NewAggregate(errs)
+	}
+	return nil
+}
+
+// Remove removes the rules added by Allow for iface
+func Remove(iface string) error {
+	errs := []error{}
+	if firewalldRunning() {
+		errs = appendErr(errs, run("firewall-cmd", "--zone=trusted", "--remove-interface="+iface))
+	}
+	if ufwActive() {
+		errs = appendErr(errs, run("ufw", "route", "delete", "allow", "in", "on", iface))
+		errs = appendErr(errs, run("ufw", "route", "delete", "allow", "out", "on", iface))
+	}
+	if hasDockerUserChain() {
+		errs = appendErr(errs, run("iptables", "-D", "DOCKER-USER", "-i", iface, "-j", "ACCEPT"))
+		errs = appendErr(errs, run("iptables", "-D", "DOCKER-USER", "-o", iface, "-j", "ACCEPT"))
+	}
+	if len(errs) > 0 {
+		return errors.NewAggregate(errs)
+	}
+	return nil
+}
+
+func appendErr(errs []error, err error) []error {
+	if err != nil {
+		return append(errs, err)
+	}
+	return errs
+}
+
+func run(command string, args ...string) error {
+	lines, err := exec.CombinedOutputLines(exec.Command(command, args...))
+	if err != nil {
+		return errors.Wrapf(err, "failed to run %s %s: %s",
+			command, strings.Join(args, " "), strings.Join(lines, "\n"),
+		)
+	}
+	return nil
+}
+
+// output returns the output of command or nil if it is not installed or fails
+func output(command string, args ...string) []string {
+	if _, err := osexec.LookPath(command); err != nil {
+		return nil
+	}
+	lines, err := exec.OutputLines(exec.Command(command, args...))
+	if err != nil {
+		globals.GetLogger().V(1).Infof("%s failed: %v", command, err)
+		return nil
+	}
+	return lines
+}
+
+func readFile(path string) string {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return string(contents)
+}
+
+// countRules returns the number of rules listed by the iptables-save
+// variant command
+func countRules(command string) int {
+	return countSavedRules(output(command))
+}
+
+func countSavedRules(lines []string) int {
+	rules := 0
+	for _, line := range lines {
+		if strings.HasPrefix(line, "-A ") {
+			rules++
+		}
+	}
+	return rules
+}
+
+func firewalldRunning() bool {
+	lines := output("firewall-cmd", "--state")
+	return len(lines) > 0 && lines[0] == "running"
+}
+
+func ufwActive() bool {
+	lines := output("ufw", "status")
+	return len(lines) > 0 && lines[0] == "Status: active"
+}
+
+func hasDockerUserChain() bool {
+	return output("iptables", "-n", "-L", "DOCKER-USER") != nil
+}
+
+// confValue returns the value of key in shell style KEY=value contents,
+// with any quotes removed
+func confValue(contents, key string) string {
+	value := ""
+	for _, line := range strings.Split(contents, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, key+"=") {
+			continue
+		}
+		// later assignments win
+		value = strings.Trim(strings.TrimPrefix(line, key+"="), `"'`)
+	}
+	return value
+}
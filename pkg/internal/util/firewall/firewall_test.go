@@ -0,0 +1,79 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package firewall
+
+import (
+	"testing"
+)
+
+func TestConfValue(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Contents string
+		Key      string
+		Expected string
+	}{
+		{
+			Name:     "quoted",
+			Contents: "IPV6=yes\nDEFAULT_FORWARD_POLICY=\"DROP\"\n",
+			Key:      "DEFAULT_FORWARD_POLICY",
+			Expected: "DROP",
+		},
+		{
+			Name:     "unquoted",
+			Contents: "# FirewallBackend=iptables\nFirewallBackend=nftables\n",
+			Key:      "FirewallBackend",
+			Expected: "nftables",
+		},
+		{
+			Name:     "last assignment wins",
+			Contents: "FirewallBackend=nftables\nFirewallBackend=iptables\n",
+			Key:      "FirewallBackend",
+			Expected: "iptables",
+		},
+		{
+			Name:     "missing",
+			Contents: "IPV6=yes\n",
+			Key:      "DEFAULT_FORWARD_POLICY",
+			Expected: "",
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			if result := confValue(tc.Contents, tc.Key); result != tc.Expected {
+				t.Errorf("confValue() = %q, expected %q", result, tc.Expected)
+			}
+		})
+	}
+}
+
+func TestCountSavedRules(t *testing.T) {
+	lines := []string{
+		"# Generated by iptables-save v1.8.4 on Tue Nov  5 10:00:00 2019",
+		"*filter",
+		":INPUT ACCEPT [0:0]",
+		":DOCKER-USER - [0:0]",
+		"-A FORWARD -j DOCKER-USER",
+		"-A DOCKER-USER -j RETURN",
+		"COMMIT",
+	}
+	if result := countSavedRules(lines); result != 2 {
+		t.Errorf("countSavedRules() = %d, expected 2", result)
+	}
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package hostlimits detects host kernel limits too low for running kind
+// clusters, and raises and restores them
+package hostlimits
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+)
+
+// Setting is the value of a sysctl
+type Setting struct {
+	// Name is the sysctl name, EG fs.inotify.max_user_watches
+	Name string
+	// Value is the sysctl value
+	Value int64
+}
+
+// Minimums are the values below which nodes are known to fail, typically
+// with "too many open files" from the kubelet or pods watching files
+var Minimums = []Setting{
+	{Name: "fs.inotify.max_user_watches", Value: 524288},
+	{Name: "fs.inotify.max_user_instances", Value: 512},
+	{Name: "fs.file-max", Value: 1048576},
+}
+
+// procSys is where the sysctls are read from, overridden in tests
+var procSys = "/proc/sys"
+
+// Detect returns the current value of each of the Minimums the host is
+// below
+func Detect() ([]Setting, error) {
+	low := []Setting{}
+	for _, minimum := range Minimums {
+		value, err := read(minimum.Name)
+		if err != nil {
+			return nil, err
+		}
+		if value < minimum.Value {
+			low = append(low, Setting{Name: minimum.Name, Value: value})
+		}
+	}
+	return low, nil
+}
+
+// Raise raises each of the settings to its minimum with sysctl, which
+// typically requires root, the change lasts until the host reboots
+func Raise(settings []Setting) error {
+	for _, s := range settings {
+		if err := write(s.Name, minimum(s.Name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Restore sets each of the settings back to its recorded value, unless it
+// was changed since Raise
+func Restore(settings []Setting) error {
+	errs := []error{}
+	for _, s := range settings {
+		value, err := read(s.Name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if value != minimum(s.Name) {
+			continue
+		}
+		if err := write(s.Name, s.Value); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return errors.NewAggregate(errs)
+	}
+	return nil
+}
+
+// Format formats settings as lines of name=value, as read by Parse
+func Format(settings []Setting) string {
+	var b strings.Builder
+	for _, s := range settings {
+		fmt.Fprintf(&b, "%s=%d\n", s.Name, s.Value)
+	}
+	return b.String()
+}
+
+// Parse parses settings from lines of name=value, as written by Format
+func Parse(raw string) ([]Setting, error) {
+	settings := []Setting{}
+	for _, line := range strings.Split(raw, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			return nil, errors.Errorf("invalid setting %q, expected name=value", line)
+		}
+		value, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid value for %s", parts[0])
+		}
+		settings = append(settings, Setting{Name: parts[0], Value: value})
+	}
+	return settings, nil
+}
+
+// Command returns the sysctl command raising settings, for users to run
+// themselves
+func Command(settings []Setting) string {
+	args := []string{"sysctl", "-w"}
+	for _, s := range settings {
+		args = append(args, fmt.Sprintf("%s=%d", s.Name, minimum(s.Name)))
+	}
+	return strings.Join(args, " ")
+}
+
+// minimum returns the minimum value of the sysctl name
+func minimum(name string) int64 {
+	for _, m := range Minimums {
+		if m.Name == name {
+			return m.Value
+		}
+	}
+	return 0
+}
+
+func read(name string) (int64, error) {
+	path := filepath.Join(procSys, strings.Replace(name, ".", "/", -1))
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to read %s", name)
+	}
+	value, err := strconv.ParseInt(strings.TrimSpace(string(raw)), 10, 64)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to parse %s", name)
+	}
+	return value, nil
+}
+
+func write(name string, value int64) error {
+	lines, err := exec.CombinedOutputLines(exec.Command("sysctl", "-w", fmt.Sprintf("%s=%d", name, value)))
+	if err != nil {
+		return errors.Wrapf(err, "failed to set %s: %s", name, strings.Join(lines, "\n"))
+	}
+	return nil
+}
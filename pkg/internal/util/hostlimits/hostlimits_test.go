@@ -0,0 +1,82 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hostlimits
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kind-hostlimits")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for path, value := range map[string]string{
+		"fs/inotify/max_user_watches":   "8192\n",
+		"fs/inotify/max_user_instances": "512\n",
+		"fs/file-max":                   "9223372036854775807\n",
+	} {
+		path = filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(value), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	defer func(original string) { procSys = original }(procSys)
+	procSys = dir
+
+	low, err := Detect()
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	expected := []Setting{{Name: "fs.inotify.max_user_watches", Value: 8192}}
+	if !reflect.DeepEqual(low, expected) {
+		t.Errorf("Detect() = %v, expected %v", low, expected)
+	}
+	if command := Command(low); command != "sysctl -w fs.inotify.max_user_watches=524288" {
+		t.Errorf("Command() = %q", command)
+	}
+}
+
+func TestFormatParse(t *testing.T) {
+	t.Parallel()
+	settings := []Setting{
+		{Name: "fs.inotify.max_user_watches", Value: 8192},
+		{Name: "fs.inotify.max_user_instances", Value: 128},
+	}
+	raw := Format(settings)
+	if raw != "fs.inotify.max_user_watches=8192\nfs.inotify.max_user_instances=128\n" {
+		t.Errorf("Format() = %q", raw)
+	}
+	parsed, err := Parse(raw)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if !reflect.DeepEqual(parsed, settings) {
+		t.Errorf("Parse() = %v, expected %v", parsed, settings)
+	}
+	if _, err := Parse("fs.file-max"); err == nil {
+		t.Errorf("Parse() without a value should fail")
+	}
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package hostsfile manages the entries kind adds to the host's hosts file
+package hostsfile
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+// Path returns the path to the host's hosts file
+func Path() string {
+	if runtime.GOOS == "windows" {
+		return filepath.Join(os.Getenv("SystemRoot"), "System32", "drivers", "etc", "hosts")
+	}
+	return "/etc/hosts"
+}
+
+// Add adds an entry to the hosts file at path resolving hostname to address,
+// replacing any entries previously added for cluster
+func Add(path, cluster, address, hostname string) error {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return errors.Wrap(err, "failed to read hosts file")
+	}
+	if err := ioutil.WriteFile(path, []byte(addEntry(string(contents), cluster, address, hostname)), 0644); err != nil {
+		return errors.Wrap(err, "failed to write hosts file")
+	}
+	return nil
+}
+
+// Remove removes the entries added for cluster from the hosts file at path,
+// the file is not written if there are none or it does not exist
+func Remove(path, cluster string) error {
+	contents, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to read hosts file")
+	}
+	removed, changed := removeEntries(string(contents), cluster)
+	if !changed {
+		return nil
+	}
+	if err := ioutil.WriteFile(path, []byte(removed), 0644); err != nil {
+		return errors.Wrap(err, "failed to write hosts file")
+	}
+	return nil
+}
+
+// marker identifies the entries added for cluster
+func marker(cluster string) string {
+	return "# kind cluster: " + cluster
+}
+
+func addEntry(contents, cluster, address, hostname string) string {
+	contents, _ = removeEntries(contents, cluster)
+	if contents != "" && !strings.HasSuffix(contents, "\n") {
+		contents += "\n"
+	}
+	return contents + fmt.Sprintf("%s %s %s\n", address, hostname, marker(cluster))
+}
+
+func removeEntries(contents, cluster string) (string, bool) {
+	lines := strings.SplitAfter(contents, "\n")
+	kept := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if strings.HasSuffix(strings.TrimRight(line, "\r\n"), marker(cluster)) {
+			continue
+		}
+		kept = append(kept, line)
+	}
+	return strings.Join(kept, ""), len(kept) != len(lines)
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hostsfile
+
+import (
+	"testing"
+)
+
+func TestAddEntry(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Contents string
+		Expected string
+	}{
+		{
+			Name:     "empty",
+			Contents: "",
+			Expected: "127.0.0.1 kind.test # kind cluster: kind\n",
+		},
+		{
+			Name:     "no trailing newline",
+			Contents: "127.0.0.1 localhost",
+			Expected: "127.0.0.1 localhost\n127.0.0.1 kind.test # kind cluster: kind\n",
+		},
+		{
+			Name:     "replaces previous entry",
+			Contents: "127.0.0.1 localhost\n127.0.0.1 old.test # kind cluster: kind\n",
+			Expected: "127.0.0.1 localhost\n127.0.0.1 kind.test # kind cluster: kind\n",
+		},
+		{
+			Name:     "keeps other clusters",
+			Contents: "127.0.0.1 other.test # kind cluster: kind-other\n",
+			Expected: "127.0.0.1 other.test # kind cluster: kind-other\n127.0.0.1 kind.test # kind cluster: kind\n",
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			result := addEntry(tc.Contents, "kind", "127.0.0.1", "kind.test")
+			if result != tc.Expected {
+				t.Errorf("addEntry() = %q, expected %q", result, tc.Expected)
+			}
+		})
+	}
+}
+
+func TestRemoveEntries(t *testing.T) {
+	cases := []struct {
+		Name            string
+		Contents        string
+		Expected        string
+		ExpectedChanged bool
+	}{
+		{
+			Name:     "no entries",
+			Contents: "127.0.0.1 localhost\n",
+			Expected: "127.0.0.1 localhost\n",
+		},
+		{
+			Name:            "windows line endings",
+			Contents:        "127.0.0.1 localhost\r\n127.0.0.1 kind.test # kind cluster: kind\r\n",
+			Expected:        "127.0.0.1 localhost\r\n",
+			ExpectedChanged: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			result, changed := removeEntries(tc.Contents, "kind")
+			if result != tc.Expected || changed != tc.ExpectedChanged {
+				t.Errorf("removeEntries() = %q, %v, expected %q, %v", result, changed, tc.Expected, tc.ExpectedChanged)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package probe implements polling readiness checks with exponential
+// backoff until they succeed or a deadline passes, keeping the last error
+package probe
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+)
+
+// Func is a single readiness check, it returns nil once ready, and must
+// return once ctx is done
+type Func func(ctx context.Context) error
+
+// Backoff configures the delays between failed probes
+type Backoff struct {
+	// Initial is the delay after the first failed probe
+	Initial time.Duration
+	// Max caps the delay between probes
+	Max time.Duration
+	// Factor multiplies the delay after each failed probe
+	Factor float64
+	// Jitter randomizes each delay by up to this fraction of it
+	Jitter float64
+}
+
+// DefaultBackoff is suitable for waiting on cluster components
+var DefaultBackoff = Backoff{
+	Initial: 250 * time.Millisecond,
+	Max:     5 * time.Second,
+	Factor:  2,
+	Jitter:  0.2,
+}
+
+// Constant returns a Backoff probing every interval, EG when measuring
+// how long something takes
+func Constant(interval time.Duration) Backoff {
+	return Backoff{
+		Initial: interval,
+		Max:     interval,
+		Factor:  1,
+	}
+}
+
+// delay returns the delay after attempt failed probes
+func (b Backoff) delay(attempt int) time.Duration {
+	d := float64(b.Initial)
+	for i := 1; i < attempt && d < float64(b.Max); i++ {
+		d *= b.Factor
+	}
+	if b.Max > 0 && d > float64(b.Max) {
+		d = float64(b.Max)
+	}
+	if b.Jitter > 0 {
+		d += d * b.Jitter * (2*rand.Float64() - 1)
+	}
+	return time.Duration(d)
+}
+
+// Result reports on the probes run by Until
+type Result struct {
+	// Attempts is the number of probes run
+	Attempts int
+	// Elapsed is the time from the first probe until success or timeout
+	Elapsed time.Duration
+	// LastErr is the error of the last failed probe
+	LastErr error
+}
+
+// TimeoutError is returned by Until if the deadline passes before a probe
+// succeeds
+type TimeoutError struct {
+	Result
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf(
+		"timed out after %s and %d attempts, last error: %v",
+		e.Elapsed.Round(time.Millisecond), e.Attempts, e.LastErr,
+	)
+}
+
+// Cause returns the last probe error
+func (e *TimeoutError) Cause() error {
+	return e.LastErr
+}
+
+// Until runs probe until it returns nil or deadline passes, waiting between
+// attempts according to backoff. Each probe is run with a context that is
+// done at the deadline, so a probe still running then is cancelled.
+//
+// If the deadline passes the error is a *TimeoutError recording the last
+// probe error
+func Until(deadline time.Time, backoff Backoff, probe Func) (Result, error) {
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+	result := Result{}
+	start := time.Now()
+	for {
+		result.Attempts++
+		err := probe(ctx)
+		result.Elapsed = time.Since(start)
+		if err == nil {
+			return result, nil
+		}
+		// a cancelled probe tells us less than the last one that finished
+		if ctx.Err() == nil || result.LastErr == nil {
+			result.LastErr = err
+		}
+		if ctx.Err() != nil {
+			return result, &TimeoutError{Result: result}
+		}
+		timer := time.NewTimer(backoff.delay(result.Attempts))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			result.Elapsed = time.Since(start)
+			return result, &TimeoutError{Result: result}
+		case <-timer.C:
+		}
+	}
+}
+
+// All returns a Func that is ready once all of probes are, they are run in
+// order until the first that is not ready
+func All(probes ...Func) Func {
+	return func(ctx context.Context) error {
+		for _, probe := range probes {
+			if err := probe(ctx); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// Command returns a Func running the command returned by newCmd, a new
+// command bound to ctx is needed for each attempt, EG from
+// exec.CommandWithContext. The combined output is captured and passed to
+// check, if set, and included in the error of failed probes.
+func Command(newCmd func(ctx context.Context) exec.Cmd, check func(lines []string) error) Func {
+	return func(ctx context.Context) error {
+		var buff bytes.Buffer
+		cmd := newCmd(ctx)
+		cmd.SetStdout(&buff)
+		cmd.SetStderr(&buff)
+		err := cmd.Run()
+		output := strings.TrimSpace(buff.String())
+		if err == nil && check != nil {
+			lines := []string{}
+			if output != "" {
+				lines = strings.Split(output, "\n")
+			}
+			err = check(lines)
+		}
+		if err != nil && output != "" {
+			return errors.Wrapf(err, "output: %s", output)
+		}
+		return err
+	}
+}
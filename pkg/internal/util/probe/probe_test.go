@@ -0,0 +1,143 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package probe
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+)
+
+func TestBackoffDelay(t *testing.T) {
+	backoff := Backoff{
+		Initial: time.Second,
+		Max:     5 * time.Second,
+		Factor:  2,
+	}
+	cases := []struct {
+		Attempt  int
+		Expected time.Duration
+	}{
+		{Attempt: 1, Expected: time.Second},
+		{Attempt: 2, Expected: 2 * time.Second},
+		{Attempt: 3, Expected: 4 * time.Second},
+		{Attempt: 4, Expected: 5 * time.Second},
+		{Attempt: 100, Expected: 5 * time.Second},
+	}
+	for _, tc := range cases {
+		if result := backoff.delay(tc.Attempt); result != tc.Expected {
+			t.Errorf("delay(%d) = %s, expected %s", tc.Attempt, result, tc.Expected)
+		}
+	}
+}
+
+func TestUntil(t *testing.T) {
+	t.Parallel()
+	attempts := 0
+	result, err := Until(time.Now().Add(time.Second), Constant(time.Millisecond), func(context.Context) error {
+		attempts++
+		if attempts < 3 {
+			return errors.New("not ready")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Until() error = %v", err)
+	}
+	if result.Attempts != 3 || result.LastErr == nil {
+		t.Errorf("Until() = %+v, expected 3 attempts with the last error", result)
+	}
+}
+
+func TestUntilTimeout(t *testing.T) {
+	t.Parallel()
+	_, err := Until(time.Now().Add(10*time.Millisecond), Constant(time.Millisecond), func(context.Context) error {
+		return errors.New("not ready")
+	})
+	timeoutErr, ok := err.(*TimeoutError)
+	if !ok {
+		t.Fatalf("Until() error = %v, expected a *TimeoutError", err)
+	}
+	if timeoutErr.LastErr == nil || timeoutErr.LastErr.Error() != "not ready" {
+		t.Errorf("Until() last error = %v, expected not ready", timeoutErr.LastErr)
+	}
+}
+
+func TestUntilCancelsSlowProbe(t *testing.T) {
+	t.Parallel()
+	start := time.Now()
+	returned := false
+	_, err := Until(start.Add(10*time.Millisecond), DefaultBackoff, func(ctx context.Context) error {
+		defer func() { returned = true }()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+			return nil
+		}
+	})
+	if _, ok := err.(*TimeoutError); !ok {
+		t.Fatalf("Until() error = %v, expected a *TimeoutError", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Until() returned after %s, expected the probe to be cancelled", elapsed)
+	}
+	if !returned {
+		t.Errorf("Until() returned before the probe did")
+	}
+}
+
+func TestAll(t *testing.T) {
+	ran := []string{}
+	ready := func(name string, err error) Func {
+		return func(context.Context) error {
+			ran = append(ran, name)
+			return err
+		}
+	}
+	if err := All(ready("a", nil), ready("b", nil))(context.Background()); err != nil {
+		t.Errorf("All() error = %v, expected nil", err)
+	}
+	ran = ran[:0]
+	err := All(ready("a", errors.New("not ready")), ready("b", nil))(context.Background())
+	if err == nil || err.Error() != "not ready" {
+		t.Errorf("All() error = %v, expected not ready", err)
+	}
+	if len(ran) != 1 {
+		t.Errorf("All() ran %v, expected to stop after the first probe that is not ready", ran)
+	}
+}
+
+func TestCommandKeepsCause(t *testing.T) {
+	failing := Command(func(ctx context.Context) exec.Cmd {
+		return exec.CommandContext(ctx, "sh", "-c", "echo not ready; exit 3")
+	}, nil)
+	err := failing(context.Background())
+	if err == nil {
+		t.Fatal("expected the failing command to fail the probe")
+	}
+	if !strings.Contains(err.Error(), "output: not ready") {
+		t.Errorf("error %q does not include the command output", err)
+	}
+	if exec.RunErrorForError(err) == nil {
+		t.Errorf("error %v does not keep the *RunError cause", err)
+	}
+}
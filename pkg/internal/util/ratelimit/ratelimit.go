@@ -0,0 +1,108 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package ratelimit detects image pulls failing on registry rate limits,
+// such as Docker Hub's anonymous pull limit
+package ratelimit
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/exec"
+)
+
+// markers are found in the output of pulls failing on a rate limit
+var markers = []string{
+	"toomanyrequests",
+	"too many requests",
+	"pull rate limit",
+}
+
+// Is returns true if err is a pull that failed on a registry rate limit,
+// judging by the error and the output of the pull command
+func Is(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	if runErr := exec.RunErrorForError(err); runErr != nil {
+		msg += "\n" + string(runErr.Output)
+	}
+	msg = strings.ToLower(msg)
+	for _, marker := range markers {
+		if strings.Contains(msg, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// Backoff returns how long to wait before retrying a rate limited pull for
+// the attempt'th time, starting at 0
+// Rate limits are enforced over minutes or hours, so this backs off much
+// longer than retrying other pull failures
+func Backoff(attempt int) time.Duration {
+	d := 15 * time.Second
+	for i := 0; i < attempt && d < 2*time.Minute; i++ {
+		d *= 2
+	}
+	if d > 2*time.Minute {
+		d = 2 * time.Minute
+	}
+	return d
+}
+
+// Error is the pulls that failed on a rate limit, with advice on avoiding it
+type Error struct {
+	// Pulls are the images that were rate limited, by the node pulling
+	// them, the host's nodes are ""
+	Pulls map[string][]string
+	// Advice is how to avoid the rate limit where these pulls happen
+	Advice string
+}
+
+// NodeImageAdvice is the advice for node images pulled by the host
+const NodeImageAdvice = "log in with `docker login` or configure registry-mirrors in the docker daemon.json, " +
+	"or pull the node image ahead of time"
+
+// WorkloadImageAdvice is the advice for images pulled by the nodes
+const WorkloadImageAdvice = "configure a mirror for docker.io with containerdConfigPatches, " +
+	"EG a pull-through cache registry, or load the images with `kind load docker-image` instead"
+
+func (e *Error) Error() string {
+	images := []string{}
+	for image := range e.Pulls {
+		images = append(images, image)
+	}
+	sort.Strings(images)
+	pulls := []string{}
+	for _, image := range images {
+		nodes := append([]string{}, e.Pulls[image]...)
+		sort.Strings(nodes)
+		if len(nodes) == 1 && nodes[0] == "" {
+			pulls = append(pulls, image)
+		} else {
+			pulls = append(pulls, fmt.Sprintf("%s (on %s)", image, strings.Join(nodes, ", ")))
+		}
+	}
+	return fmt.Sprintf(
+		"image pulls were rate limited by the registry: %s; to avoid this %s",
+		strings.Join(pulls, ", "), e.Advice,
+	)
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+)
+
+func TestIs(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name     string
+		Err      error
+		Expected bool
+	}{
+		{
+			Name:     "nil",
+			Expected: false,
+		},
+		{
+			Name: "docker hub",
+			Err: errors.Wrap(&exec.RunError{
+				Command: []string{"docker", "pull", "kindest/node"},
+				Output:  []byte("Error response from daemon: toomanyrequests: You have reached your pull rate limit."),
+				Inner:   errors.New("exit status 1"),
+			}, "failed to pull"),
+			Expected: true,
+		},
+		{
+			Name: "not found",
+			Err: &exec.RunError{
+				Command: []string{"crictl", "pull", "nginx:nope"},
+				Output:  []byte("not found"),
+				Inner:   errors.New("exit status 1"),
+			},
+			Expected: false,
+		},
+	}
+	for _, tc := range cases {
+		if result := Is(tc.Err); result != tc.Expected {
+			t.Errorf("%s: expected %v but got %v", tc.Name, tc.Expected, result)
+		}
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	t.Parallel()
+	expected := []time.Duration{15 * time.Second, 30 * time.Second, time.Minute, 2 * time.Minute, 2 * time.Minute}
+	for i, d := range expected {
+		if result := Backoff(i); result != d {
+			t.Errorf("Backoff(%d) = %v, expected %v", i, result, d)
+		}
+	}
+}
+
+func TestError(t *testing.T) {
+	t.Parallel()
+	err := &Error{
+		Pulls: map[string][]string{
+			"nginx":   {"kind-worker2", "kind-worker"},
+			"busybox": {"kind-worker"},
+		},
+		Advice: "wait",
+	}
+	expected := "image pulls were rate limited by the registry: busybox (on kind-worker), nginx (on kind-worker, kind-worker2); to avoid this wait"
+	if err.Error() != expected {
+		t.Errorf("expected %q but got %q", expected, err.Error())
+	}
+}
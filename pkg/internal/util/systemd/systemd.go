@@ -0,0 +1,89 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package systemd manages the host systemd slices kind clusters run in
+package systemd
+
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+)
+
+// DefaultSliceName returns the default slice for the cluster named
+// cluster, kind-<cluster>.slice, escaping dashes in the cluster name so the
+// slice is nested in kind.slice only
+func DefaultSliceName(cluster string) string {
+	return "kind-" + escape(cluster) + ".slice"
+}
+
+// escape escapes s for use as a single part of a unit name, like
+// systemd-escape
+func escape(s string) string {
+	var b strings.Builder
+	for i, c := range []byte(s) {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9',
+			c == ':', c == '_', c == '.' && i > 0:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, `\x%02x`, c)
+		}
+	}
+	return b.String()
+}
+
+// Limits are the resource limits of a slice, empty fields are unlimited
+type Limits struct {
+	// CPUQuota is a percentage of one CPU, EG 200%
+	CPUQuota string
+	// MemoryMax is bytes with an optional K, M, G or T suffix
+	MemoryMax string
+}
+
+// SetupSlice starts the slice name with resource accounting and limits,
+// these only last until the host reboots
+func SetupSlice(name string, limits Limits) error {
+	if err := exec.Command("systemctl", "start", name).Run(); err != nil {
+		return errors.Wrapf(err, "failed to start systemd slice %s", name)
+	}
+	if err := exec.Command("systemctl", setPropertyArgs(name, limits)...).Run(); err != nil {
+		return errors.Wrapf(err, "failed to configure systemd slice %s", name)
+	}
+	return nil
+}
+
+// setPropertyArgs returns the systemctl arguments configuring the slice
+func setPropertyArgs(name string, limits Limits) []string {
+	args := []string{
+		"set-property", "--runtime", name,
+		"CPUAccounting=yes", "MemoryAccounting=yes", "TasksAccounting=yes",
+	}
+	if limits.CPUQuota != "" {
+		args = append(args, "CPUQuota="+limits.CPUQuota)
+	}
+	if limits.MemoryMax != "" {
+		args = append(args, "MemoryMax="+limits.MemoryMax)
+	}
+	return args
+}
+
+// StopSlice stops the slice name, it should be empty by then
+func StopSlice(name string) error {
+	return errors.Wrapf(exec.Command("systemctl", "stop", name).Run(), "failed to stop systemd slice %s", name)
+}
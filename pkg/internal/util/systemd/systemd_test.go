@@ -0,0 +1,51 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package systemd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDefaultSliceName(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Cluster  string
+		Expected string
+	}{
+		{Cluster: "kind", Expected: "kind-kind.slice"},
+		{Cluster: "my-cluster", Expected: `kind-my\x2dcluster.slice`},
+		{Cluster: "v1.18", Expected: "kind-v1.18.slice"},
+	}
+	for _, tc := range cases {
+		if result := DefaultSliceName(tc.Cluster); result != tc.Expected {
+			t.Errorf("DefaultSliceName(%q) = %q, expected %q", tc.Cluster, result, tc.Expected)
+		}
+	}
+}
+
+func TestSetPropertyArgs(t *testing.T) {
+	t.Parallel()
+	expected := []string{
+		"set-property", "--runtime", "kind-kind.slice",
+		"CPUAccounting=yes", "MemoryAccounting=yes", "TasksAccounting=yes",
+		"MemoryMax=8G",
+	}
+	if result := setPropertyArgs("kind-kind.slice", Limits{MemoryMax: "8G"}); !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %v but got %v", expected, result)
+	}
+}
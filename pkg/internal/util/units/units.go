@@ -0,0 +1,60 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package units implements parsing human readable byte sizes
+package units
+
+import (
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+// suffixes maps size suffixes to their multiplier, both decimal (EG G)
+// and binary (EG Gi) suffixes are supported, optionally followed by B
+var suffixes = map[string]float64{
+	"":   1,
+	"k":  1e3,
+	"m":  1e6,
+	"g":  1e9,
+	"t":  1e12,
+	"ki": 1 << 10,
+	"mi": 1 << 20,
+	"gi": 1 << 30,
+	"ti": 1 << 40,
+}
+
+// ParseBytes parses a size such as 512Mi, 1.5GiB or 2G into bytes
+func ParseBytes(s string) (uint64, error) {
+	trimmed := strings.TrimSpace(s)
+	i := strings.IndexFunc(trimmed, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if i == -1 {
+		i = len(trimmed)
+	}
+	value, err := strconv.ParseFloat(trimmed[:i], 64)
+	if err != nil || value < 0 {
+		return 0, errors.Errorf("invalid size %q", s)
+	}
+	suffix := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(trimmed[i:])), "b")
+	multiplier, ok := suffixes[suffix]
+	if !ok {
+		return 0, errors.Errorf("invalid size %q, unknown unit %q", s, trimmed[i:])
+	}
+	return uint64(value * multiplier), nil
+}
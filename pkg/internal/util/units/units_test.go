@@ -0,0 +1,84 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package units
+
+import (
+	"testing"
+)
+
+func TestParseBytes(t *testing.T) {
+	cases := []struct {
+		Name      string
+		Size      string
+		Expected  uint64
+		ExpectErr bool
+	}{
+		{
+			Name:     "plain bytes",
+			Size:     "1024",
+			Expected: 1024,
+		},
+		{
+			Name:     "bytes suffix",
+			Size:     "0B",
+			Expected: 0,
+		},
+		{
+			Name:     "binary suffix",
+			Size:     "512Mi",
+			Expected: 512 << 20,
+		},
+		{
+			Name:     "docker stats style",
+			Size:     "1.5GiB",
+			Expected: 3 << 29,
+		},
+		{
+			Name:     "decimal suffix",
+			Size:     "2G",
+			Expected: 2000000000,
+		},
+		{
+			Name:     "lower case",
+			Size:     "4kb",
+			Expected: 4000,
+		},
+		{
+			Name:      "unknown unit",
+			Size:      "3 bananas",
+			ExpectErr: true,
+		},
+		{
+			Name:      "no number",
+			Size:      "Gi",
+			ExpectErr: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			result, err := ParseBytes(tc.Size)
+			if (err != nil) != tc.ExpectErr {
+				t.Fatalf("ParseBytes(%q) error = %v, ExpectErr %v", tc.Size, err, tc.ExpectErr)
+			}
+			if result != tc.Expected {
+				t.Errorf("ParseBytes(%q) = %d, expected %d", tc.Size, result, tc.Expected)
+			}
+		})
+	}
+}